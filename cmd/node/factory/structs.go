@@ -12,6 +12,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/config"
 	"github.com/ElrondNetwork/elrond-go/consensus"
 	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/dblookupext"
 	"github.com/ElrondNetwork/elrond-go/core/indexer"
 	"github.com/ElrondNetwork/elrond-go/core/parsers"
@@ -19,10 +20,13 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/statistics"
 	"github.com/ElrondNetwork/elrond-go/core/statistics/softwareVersion"
 	factorySoftwareVersion "github.com/ElrondNetwork/elrond-go/core/statistics/softwareVersion/factory"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
 	"github.com/ElrondNetwork/elrond-go/data"
 	dataBlock "github.com/ElrondNetwork/elrond-go/data/block"
 	"github.com/ElrondNetwork/elrond-go/data/endProcess"
 	"github.com/ElrondNetwork/elrond-go/data/state"
+	stateFactory "github.com/ElrondNetwork/elrond-go/data/state/factory"
+	triesFactory "github.com/ElrondNetwork/elrond-go/data/trie/factory"
 	"github.com/ElrondNetwork/elrond-go/data/typeConverters"
 	"github.com/ElrondNetwork/elrond-go/dataRetriever"
 	"github.com/ElrondNetwork/elrond-go/dataRetriever/factory/containers"
@@ -301,6 +305,7 @@ func ProcessComponentsFactory(args *processComponentsFactoryArgs) (*Process, err
 		args.mainConfig.Versions.VersionsByEpochs,
 		args.mainConfig.Versions.DefaultVersion,
 		versionsCache,
+		args.mainConfig.GeneralSettings.BodyCompositionEnableEpoch,
 	)
 	if err != nil {
 		return nil, err
@@ -470,6 +475,8 @@ func ProcessComponentsFactory(args *processComponentsFactoryArgs) (*Process, err
 		args.data.Datapool,
 		args.rounder,
 		args.shardCoordinator,
+		args.mainConfig.GeneralSettings.CleanTxsPoolsMinFill,
+		time.Duration(args.mainConfig.GeneralSettings.CleanTxsPoolsIntervalInSec)*time.Second,
 	)
 	if err != nil {
 		return nil, err
@@ -547,6 +554,14 @@ func ProcessComponentsFactory(args *processComponentsFactoryArgs) (*Process, err
 		return nil, err
 	}
 
+	txsPoolsCleanerSetter, ok := blockProcessor.(process.TxsPoolsCleanerSetter)
+	if ok {
+		err = txsPoolsCleanerSetter.SetTxsPoolsCleaner(txsPoolsCleaner)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	conversionBase := 10
 	genesisNodePrice, ok := big.NewInt(0).SetString(args.systemSCConfig.StakingSystemSCConfig.GenesisNodePrice, conversionBase)
 	if !ok {
@@ -1425,10 +1440,12 @@ func newBlockProcessor(
 			processArgs.nodesCoordinator,
 			processArgs.data,
 			processArgs.coreData,
+			processArgs.crypto,
 			processArgs.state,
 			forkDetector,
 			processArgs.economicsData,
 			processArgs.rounder,
+			time.Unix(processArgs.nodesConfig.StartTime, 0),
 			epochStartTrigger,
 			bootStorer,
 			processArgs.gasSchedule,
@@ -1447,6 +1464,7 @@ func newBlockProcessor(
 			txSimulatorProcessorArgs,
 			processArgs.mainConfig,
 			workingDir,
+			processArgs.tries.TriesContainer.Get([]byte(triesFactory.UserAccountTrie)),
 		)
 	}
 	if shardCoordinator.SelfId() == core.MetachainShardId {
@@ -1498,10 +1516,12 @@ func newShardBlockProcessor(
 	nodesCoordinator sharding.NodesCoordinator,
 	data *mainFactory.DataComponents,
 	core *mainFactory.CoreComponents,
+	crypto *mainFactory.CryptoComponents,
 	stateComponents *mainFactory.StateComponents,
 	forkDetector process.ForkDetector,
 	economics process.EconomicsDataHandler,
 	rounder consensus.Rounder,
+	genesisTime time.Time,
 	epochStartTrigger epochStart.TriggerHandler,
 	bootStorer process.BootStorer,
 	gasSchedule core.GasScheduleNotifier,
@@ -1520,9 +1540,15 @@ func newShardBlockProcessor(
 	txSimulatorProcessorArgs *txsimulator.ArgsTxSimulator,
 	generalConfig config.Config,
 	workingDir string,
+	stateTrie data.Trie,
 ) (process.BlockProcessor, error) {
 	argsParser := smartContract.NewArgumentParser()
 
+	processingAccounts, err := createValidationAccounts(stateComponents.AccountsAdapter, stateTrie, core)
+	if err != nil {
+		return nil, err
+	}
+
 	mapDNSAddresses, err := smartContractParser.GetDeployedSCAddresses(genesis.DNSType)
 	if err != nil {
 		return nil, err
@@ -1532,7 +1558,7 @@ func newShardBlockProcessor(
 		GasSchedule:     gasSchedule,
 		MapDNSAddresses: mapDNSAddresses,
 		Marshalizer:     core.InternalMarshalizer,
-		Accounts:        stateComponents.AccountsAdapter,
+		Accounts:        processingAccounts,
 	}
 	builtInFuncFactory, err := builtInFunctions.NewBuiltInFunctionsFactory(argsBuiltIn)
 	if err != nil {
@@ -1544,7 +1570,7 @@ func newShardBlockProcessor(
 	}
 
 	argsHook := hooks.ArgBlockChainHook{
-		Accounts:           stateComponents.AccountsAdapter,
+		Accounts:           processingAccounts,
 		PubkeyConv:         stateComponents.AddressPubkeyConverter,
 		StorageService:     data.Store,
 		BlockChain:         data.Blkc,
@@ -1643,7 +1669,7 @@ func newShardBlockProcessor(
 		ArgsParser:                     argsParser,
 		Hasher:                         core.Hasher,
 		Marshalizer:                    core.InternalMarshalizer,
-		AccountsDB:                     stateComponents.AccountsAdapter,
+		AccountsDB:                     processingAccounts,
 		BlockChainHook:                 vmFactory.BlockChainHookImpl(),
 		PubkeyConv:                     stateComponents.AddressPubkeyConverter,
 		ShardCoordinator:               shardCoordinator,
@@ -1669,7 +1695,7 @@ func newShardBlockProcessor(
 	}
 
 	rewardsTxProcessor, err := rewardTransaction.NewRewardTxProcessor(
-		stateComponents.AccountsAdapter,
+		processingAccounts,
 		stateComponents.AddressPubkeyConverter,
 		shardCoordinator,
 	)
@@ -1678,24 +1704,31 @@ func newShardBlockProcessor(
 	}
 
 	argsNewTxProcessor := transaction.ArgsNewTxProcessor{
-		Accounts:                       stateComponents.AccountsAdapter,
-		Hasher:                         core.Hasher,
-		PubkeyConv:                     stateComponents.AddressPubkeyConverter,
-		Marshalizer:                    core.InternalMarshalizer,
-		SignMarshalizer:                core.TxSignMarshalizer,
-		ShardCoordinator:               shardCoordinator,
-		ScProcessor:                    scProcessor,
-		TxFeeHandler:                   txFeeHandler,
-		TxTypeHandler:                  txTypeHandler,
-		EconomicsFee:                   economics,
-		ReceiptForwarder:               receiptTxInterim,
-		BadTxForwarder:                 badTxInterim,
-		ArgsParser:                     argsParser,
-		ScrForwarder:                   scForwarder,
-		RelayedTxEnableEpoch:           config.GeneralSettings.RelayedTransactionsEnableEpoch,
-		PenalizedTooMuchGasEnableEpoch: config.GeneralSettings.PenalizedTooMuchGasEnableEpoch,
-		MetaProtectionEnableEpoch:      config.GeneralSettings.MetaProtectionEnableEpoch,
-		EpochNotifier:                  epochNotifier,
+		Accounts:                         processingAccounts,
+		Hasher:                           core.Hasher,
+		PubkeyConv:                       stateComponents.AddressPubkeyConverter,
+		Marshalizer:                      core.InternalMarshalizer,
+		SignMarshalizer:                  core.TxSignMarshalizer,
+		ShardCoordinator:                 shardCoordinator,
+		ScProcessor:                      scProcessor,
+		TxFeeHandler:                     txFeeHandler,
+		TxTypeHandler:                    txTypeHandler,
+		EconomicsFee:                     economics,
+		ReceiptForwarder:                 receiptTxInterim,
+		BadTxForwarder:                   badTxInterim,
+		ArgsParser:                       argsParser,
+		ScrForwarder:                     scForwarder,
+		KeyGen:                           crypto.TxSignKeyGen,
+		SingleSigner:                     crypto.TxSingleSigner,
+		TxSignHasher:                     core.TxSignHasher,
+		TxVersionChecker:                 versioning.NewTxVersionChecker(core.MinTransactionVersion),
+		RelayedTxEnableEpoch:             config.GeneralSettings.RelayedTransactionsEnableEpoch,
+		PenalizedTooMuchGasEnableEpoch:   config.GeneralSettings.PenalizedTooMuchGasEnableEpoch,
+		MetaProtectionEnableEpoch:        config.GeneralSettings.MetaProtectionEnableEpoch,
+		InnerTxSignatureCheckEnableEpoch: config.GeneralSettings.InnerTxSignatureCheckEnableEpoch,
+		RelayedGasPriceCheckEnableEpoch:  config.GeneralSettings.RelayedGasPriceCheckEnableEpoch,
+		RelayerFundsCheckEnableEpoch:     config.GeneralSettings.RelayerFundsCheckEnableEpoch,
+		EpochNotifier:                    epochNotifier,
 	}
 	transactionProcessor, err := transaction.NewTxProcessor(argsNewTxProcessor)
 	if err != nil {
@@ -1707,6 +1740,23 @@ func newShardBlockProcessor(
 		return nil, err
 	}
 
+	argsRelayedTxSimulator := txsimulator.ArgsShardRelayedTxSimulator{
+		ScProcArgs:       argsNewScProcessor,
+		TxProcArgs:       argsNewTxProcessor,
+		StateTrie:        stateTrie,
+		AccountFactory:   stateFactory.NewAccountCreator(),
+		BlockChain:       data.Blkc,
+		ShardCoordinator: shardCoordinator,
+		Marshalizer:      core.InternalMarshalizer,
+		Hasher:           core.Hasher,
+		PubkeyConverter:  stateComponents.AddressPubkeyConverter,
+		DataPool:         data.Datapool,
+	}
+	txSimulatorProcessorArgs.RelayedTxSimulator, err = txsimulator.CreateShardRelayedTxSimulator(argsRelayedTxSimulator)
+	if err != nil {
+		return nil, err
+	}
+
 	blockSizeThrottler, err := throttle.NewBlockSizeThrottle(minSizeInBytes, maxSizeInBytes)
 	if err != nil {
 		return nil, err
@@ -1729,7 +1779,7 @@ func newShardBlockProcessor(
 		core.Hasher,
 		data.Datapool,
 		stateComponents.AddressPubkeyConverter,
-		stateComponents.AccountsAdapter,
+		processingAccounts,
 		requestHandler,
 		transactionProcessor,
 		scProcessor,
@@ -1754,7 +1804,7 @@ func newShardBlockProcessor(
 		core.Hasher,
 		core.InternalMarshalizer,
 		shardCoordinator,
-		stateComponents.AccountsAdapter,
+		processingAccounts,
 		data.Datapool.MiniBlocks(),
 		requestHandler,
 		preProcContainer,
@@ -1772,7 +1822,7 @@ func newShardBlockProcessor(
 	}
 
 	accountsDb := make(map[state.AccountsDbIdentifier]state.AccountsAdapter)
-	accountsDb[state.UserAccountsState] = stateComponents.AccountsAdapter
+	accountsDb[state.UserAccountsState] = processingAccounts
 
 	argumentsBaseProcessor := block.ArgBaseProcessor{
 		AccountsDB:              accountsDb,
@@ -1801,9 +1851,48 @@ func newShardBlockProcessor(
 		HistoryRepository:       historyRepository,
 		EpochNotifier:           epochNotifier,
 		HeaderIntegrityVerifier: headerIntegrityVerifier,
+
+		MiniBlockReservedCheckEnableEpoch: config.GeneralSettings.MiniBlockReservedCheckEnableEpoch,
 	}
+	blockCreationPolicy, err := block.NewBlockCreationPolicy(block.ArgsBlockCreationPolicy{
+		MinGasPriceForInclusion: config.GeneralSettings.MinGasPriceForInclusion,
+		MaxTxDataSize:           config.GeneralSettings.MaxTxDataSize,
+		MaxTxsPerDestShard:      config.GeneralSettings.MaxTxsPerDestShard,
+		MaxBlockBodyBytes:       config.GeneralSettings.MaxBlockBodyBytes,
+		BodyComposition:         process.BodyComposition(config.GeneralSettings.BodyComposition),
+		ProduceEmptyBlocks:      config.GeneralSettings.ProduceEmptyBlocks,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	arguments := block.ArgShardProcessor{
-		ArgBaseProcessor: argumentsBaseProcessor,
+		ArgBaseProcessor:                   argumentsBaseProcessor,
+		BlockCreationPolicy:                blockCreationPolicy,
+		IncludeEmptyAttestedMetaBlocks:     config.GeneralSettings.IncludeEmptyAttestedMetaBlocks,
+		WeightedMetaBlockSelectionMaxTxs:   config.GeneralSettings.WeightedMetaBlockSelectionMaxTxs,
+		PoolLogThreshold:                   config.Logs.PoolLogThreshold,
+		MaxShardHeaderRequestsPerMetaBlock: config.GeneralSettings.MaxShardHeaderRequestsPerMetaBlock,
+		StrictHeaderValidation:             config.GeneralSettings.StrictHeaderValidation,
+		HeaderTxCountCheckEnableEpoch:      config.GeneralSettings.HeaderTxCountCheckEnableEpoch,
+		BodyShardIdsCheckEnableEpoch:       config.GeneralSettings.BodyShardIdsCheckEnableEpoch,
+		HeaderEpochCheckEnableEpoch:        config.GeneralSettings.HeaderEpochCheckEnableEpoch,
+		GenesisRandSeedCheckEnableEpoch:    config.GeneralSettings.GenesisRandSeedCheckEnableEpoch,
+		MetaBlocksCountCheckEnableEpoch:    config.GeneralSettings.MetaBlocksCountCheckEnableEpoch,
+		GenesisTime:                        genesisTime,
+		MaxRoundClockSkew:                  time.Duration(config.GeneralSettings.MaxRoundClockSkewInSeconds) * time.Second,
+		MinTimeForTxProcessing:             time.Duration(config.GeneralSettings.MinTimeForTxProcessingInMilliseconds) * time.Millisecond,
+		MaxAttestedMetaBlocksPerBlock:      config.GeneralSettings.MaxAttestedMetaBlocksPerBlock,
+		BodyCompositionEnableEpoch:         config.GeneralSettings.BodyCompositionEnableEpoch,
+		TxExportDir:                        config.GeneralSettings.TxExportDir,
+		DecodedHeadersCacheSize:            config.GeneralSettings.DecodedHeadersCacheSize,
+		CheckAttestedShardInfo:             config.GeneralSettings.CheckAttestedShardInfo,
+		PrevHeaderRequestGracePeriod:       time.Duration(config.GeneralSettings.PrevHeaderRequestGracePeriodInMillisec) * time.Millisecond,
+		StorageUnitsWriteMode:              config.GeneralSettings.StorageUnitsWriteMode,
+		MaxReorgDepth:                      config.GeneralSettings.MaxReorgDepth,
+		MetaBlocksPoolHighFillRatio:        config.GeneralSettings.MetaBlocksPoolHighFillRatio,
+		MetaBlockFinality:                  config.GeneralSettings.MetaBlockFinality,
+		ProcessedMbsCompactionInterval:     time.Duration(config.GeneralSettings.CompactProcessedMbsIntervalInSec) * time.Second,
 	}
 
 	blockProcessor, err := block.NewShardProcessor(arguments)
@@ -2223,6 +2312,8 @@ func newMetaBlockProcessor(
 		TpsBenchmark:            tpsBenchmark,
 		HistoryRepository:       historyRepository,
 		EpochNotifier:           epochNotifier,
+
+		MiniBlockReservedCheckEnableEpoch: generalConfig.GeneralSettings.MiniBlockReservedCheckEnableEpoch,
 	}
 
 	argsEpochSystemSC := metachainEpochStart.ArgsNewEpochStartSystemSCProcessing{
@@ -2277,6 +2368,30 @@ func newMetaBlockProcessor(
 	return metaProcessor, nil
 }
 
+// createValidationAccounts creates the accounts adapter of the blocks processing. It works on the live accounts and it
+// is switched, while a block is validated, to scratch accounts over a trie recreated from the storage of the state trie
+func createValidationAccounts(
+	liveAccounts state.AccountsAdapter,
+	stateTrie data.Trie,
+	core *mainFactory.CoreComponents,
+) (state.ValidationAccountsAdapter, error) {
+	if check.IfNil(stateTrie) {
+		return nil, state.ErrNilTrie
+	}
+
+	scratchTrie, err := stateTrie.Recreate(make([]byte, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	scratchAccounts, err := state.NewAccountsDB(scratchTrie, core.Hasher, core.InternalMarshalizer, stateFactory.NewAccountCreator())
+	if err != nil {
+		return nil, err
+	}
+
+	return state.NewValidationAccountsDB(liveAccounts, scratchAccounts)
+}
+
 func createShardTxSimulatorProcessor(
 	scProcArgs smartContract.ArgsNewSmartContractProcessor,
 	txProcArgs transaction.ArgsNewTxProcessor,
@@ -2420,6 +2535,7 @@ func createMetaTxSimulatorProcessor(
 	}
 
 	txSimulatorProcessorArgs.IntermmediateProcContainer = interimProcContainer
+	txSimulatorProcessorArgs.RelayedTxSimulator = txsimulator.NewDisabledRelayedTxSimulator()
 
 	return nil
 }
@@ -951,6 +951,7 @@ func startNode(ctx *cli.Context, log logger.Logger, version string) error {
 		generalConfig.Versions.VersionsByEpochs,
 		generalConfig.Versions.DefaultVersion,
 		versionsCache,
+		generalConfig.GeneralSettings.BodyCompositionEnableEpoch,
 	)
 	if err != nil {
 		return err
@@ -1506,7 +1507,7 @@ func startNode(ctx *cli.Context, log logger.Logger, version string) error {
 
 	chanCloseComponents := make(chan struct{})
 	go func() {
-		closeAllComponents(log, healthService, dataComponents, triesComponents, networkComponents, chanCloseComponents)
+		closeAllComponents(log, healthService, processComponents, dataComponents, triesComponents, networkComponents, chanCloseComponents)
 	}()
 
 	select {
@@ -1568,6 +1569,7 @@ func changeStorageConfigForDBImport(storageConfig *config.StorageConfig) {
 func closeAllComponents(
 	log logger.Logger,
 	healthService io.Closer,
+	processComponents *factory.Process,
 	dataComponents *mainFactory.DataComponents,
 	triesComponents *mainFactory.TriesComponents,
 	networkComponents *mainFactory.NetworkComponents,
@@ -1577,6 +1579,13 @@ func closeAllComponents(
 	err := healthService.Close()
 	log.LogIfError(err)
 
+	blockProcessor, ok := processComponents.BlockProcessor.(io.Closer)
+	if ok {
+		log.Debug("closing the block processor...")
+		err = blockProcessor.Close()
+		log.LogIfError(err)
+	}
+
 	log.Debug("closing all store units....")
 	err = dataComponents.Store.CloseAll()
 	log.LogIfError(err)
@@ -2034,6 +2043,8 @@ func createElasticIndexer(
 	indexerFactoryArgs := &indexerFactory.ArgsIndexerFactory{
 		Enabled:                  elasticSearchConfig.Enabled,
 		IndexerCacheSize:         elasticSearchConfig.IndexerCacheSize,
+		IndexerRetryInterval:     time.Duration(elasticSearchConfig.IndexerRetryIntervalInSec) * time.Second,
+		DropOldestWhenCacheFull:  elasticSearchConfig.DropOldestWhenCacheFull,
 		ShardCoordinator:         shardCoordinator,
 		Url:                      elasticSearchConfig.URL,
 		UserName:                 elasticSearchConfig.Username,
@@ -72,8 +72,13 @@ func InitMetrics(
 	appStatusHandler.SetUInt64Value(core.MetricCurrentRoundTimestamp, initUint)
 	appStatusHandler.SetUInt64Value(core.MetricHeaderSize, initUint)
 	appStatusHandler.SetUInt64Value(core.MetricMiniBlocksSize, initUint)
+	appStatusHandler.SetUInt64Value(core.MetricCommittedBlockSizeBytes, initUint)
 	appStatusHandler.SetUInt64Value(core.MetricNumShardHeadersFromPool, initUint)
 	appStatusHandler.SetUInt64Value(core.MetricNumShardHeadersProcessed, initUint)
+	appStatusHandler.SetUInt64Value(core.MetricNumShardHeadersRequestedFromMeta, initUint)
+	appStatusHandler.SetUInt64Value(core.MetricNotarizedHeadersPruned, initUint)
+	appStatusHandler.SetUInt64Value(core.MetricBlockProductionSuccessRatePercent, initUint)
+	appStatusHandler.SetStringValue(core.MetricMetaBlockAttestationEfficiency, initString)
 	appStatusHandler.SetUInt64Value(core.MetricNumTimesInForkChoice, initUint)
 	appStatusHandler.SetUInt64Value(core.MetricHighestFinalBlock, initUint)
 	appStatusHandler.SetUInt64Value(core.MetricCountConsensusAcceptedBlocks, initUint)
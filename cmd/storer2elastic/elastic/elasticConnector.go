@@ -2,6 +2,7 @@ package elastic
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ElrondNetwork/elrond-go/cmd/storer2elastic/config"
 	"github.com/ElrondNetwork/elrond-go/cmd/storer2elastic/databasereader/disabled"
@@ -14,6 +15,10 @@ import (
 	"github.com/ElrondNetwork/elrond-go/marshal"
 )
 
+// indexerRetryInterval is the time between two consecutive attempts of saving an item which failed to be indexed. The
+// importing is not time bound, so the items are never dropped, the import waiting for the database instead
+const indexerRetryInterval = time.Second * 3
+
 // ConnectorFactoryArgs holds the data needed for creating a new elastic search connector factory
 type ConnectorFactoryArgs struct {
 	ElasticConfig            config.ElasticSearchConfig
@@ -60,6 +65,7 @@ func (escf *elasticSearchConnectorFactory) Create() (indexer.Indexer, error) {
 	indexerFactoryArgs := &factory.ArgsIndexerFactory{
 		Url:                      escf.elasticConfig.URL,
 		IndexerCacheSize:         100,
+		IndexerRetryInterval:     indexerRetryInterval,
 		UserName:                 escf.elasticConfig.Username,
 		Password:                 escf.elasticConfig.Password,
 		Marshalizer:              escf.marshalizer,
@@ -174,6 +174,7 @@ type Config struct {
 // LogsConfig will hold settings related to the logging sub-system
 type LogsConfig struct {
 	LogFileLifeSpanInSec int
+	PoolLogThreshold     uint64
 }
 
 // StoragePruningConfig will hold settings related to storage pruning
@@ -216,6 +217,30 @@ type MaxNodesChangeConfig struct {
 type GeneralSettingsConfig struct {
 	StatusPollingIntervalSec               int
 	MaxComputableRounds                    uint64
+	IncludeEmptyAttestedMetaBlocks         bool
+	WeightedMetaBlockSelectionMaxTxs       uint32
+	MaxShardHeaderRequestsPerMetaBlock     uint32
+	StrictHeaderValidation                 bool
+	MaxRoundClockSkewInSeconds             uint32
+	ProduceEmptyBlocks                     bool
+	MinTimeForTxProcessingInMilliseconds   uint32
+	MaxBlockBodyBytes                      uint32
+	MaxAttestedMetaBlocksPerBlock          uint32
+	BodyComposition                        string
+	TxExportDir                            string
+	DecodedHeadersCacheSize                uint32
+	CheckAttestedShardInfo                 bool
+	PrevHeaderRequestGracePeriodInMillisec uint32
+	MinGasPriceForInclusion                uint64
+	MaxTxsPerDestShard                     uint32
+	StorageUnitsWriteMode                  map[string]string
+	MaxReorgDepth                          uint32
+	MaxTxDataSize                          uint64
+	MetaBlocksPoolHighFillRatio            float64
+	MetaBlockFinality                      int
+	CleanTxsPoolsMinFill                   uint64
+	CleanTxsPoolsIntervalInSec             uint32
+	CompactProcessedMbsIntervalInSec       uint32
 	StartInEpochEnabled                    bool
 	SCDeployEnableEpoch                    uint32
 	BuiltInFunctionsEnableEpoch            uint32
@@ -233,6 +258,16 @@ type GeneralSettingsConfig struct {
 	GenesisString                          string
 	GenesisMaxNumberOfShards               uint32
 	BlockGasAndFeesReCheckEnableEpoch      uint32
+	InnerTxSignatureCheckEnableEpoch       uint32
+	RelayedGasPriceCheckEnableEpoch        uint32
+	RelayerFundsCheckEnableEpoch           uint32
+	BodyCompositionEnableEpoch             uint32
+	HeaderTxCountCheckEnableEpoch          uint32
+	BodyShardIdsCheckEnableEpoch           uint32
+	HeaderEpochCheckEnableEpoch            uint32
+	MiniBlockReservedCheckEnableEpoch      uint32
+	GenesisRandSeedCheckEnableEpoch        uint32
+	MetaBlocksCountCheckEnableEpoch        uint32
 }
 
 // FacadeConfig will hold different configuration option that will be passed to the main ElrondFacade
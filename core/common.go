@@ -43,3 +43,24 @@ func GetPBFTThreshold(consensusSize int) int {
 func GetPBFTFallbackThreshold(consensusSize int) int {
 	return consensusSize*1/2 + 1
 }
+
+// SetMultipleStatusValues sets the given metric values on the status handler, at once if the handler supports it or
+// one by one otherwise. The values should be of type uint64, int64 or string, the others being ignored
+func SetMultipleStatusValues(handler AppStatusHandler, values map[string]interface{}) {
+	batchHandler, ok := handler.(AppStatusBatchHandler)
+	if ok {
+		batchHandler.SetMultiple(values)
+		return
+	}
+
+	for key, value := range values {
+		switch v := value.(type) {
+		case uint64:
+			handler.SetUInt64Value(key, v)
+		case int64:
+			handler.SetInt64Value(key, v)
+		case string:
+			handler.SetStringValue(key, v)
+		}
+	}
+}
@@ -222,12 +222,62 @@ const MetricHeaderSize = "erd_current_block_size"
 // MetricMiniBlocksSize is the metric that stores the current block size
 const MetricMiniBlocksSize = "erd_mini_blocks_size"
 
+// MetricCommittedBlockSizeBytes is the metric that stores the size in bytes of the last committed block, computed as
+// the marshalized header size plus the marshalized miniblocks sizes
+const MetricCommittedBlockSizeBytes = "erd_committed_block_size_bytes"
+
 // MetricNumShardHeadersFromPool is the metric that stores number of shard header from pool
 const MetricNumShardHeadersFromPool = "erd_num_shard_headers_from_pool"
 
 // MetricNumShardHeadersProcessed is the metric that stores number of shard header processed
 const MetricNumShardHeadersProcessed = "erd_num_shard_headers_processed"
 
+// MetricNumShardHeadersRequestedFromMeta is the metric that counts the missing shard headers requested while
+// searching the highest shard headers notarized by metachain
+const MetricNumShardHeadersRequestedFromMeta = "erd_num_shard_headers_requested_from_meta"
+
+// MetricNotarizedHeadersPruned is the metric that counts the headers removed from pools, behind the final ones,
+// when blocks are committed
+const MetricNotarizedHeadersPruned = "erd_notarized_headers_pruned"
+
+// MetricMetaHdrConstructionRejections is the metric that counts the meta headers, attested by the shard blocks being
+// processed, rejected because they are not correctly constructed on top of the previous ones. A spike could signal a
+// metachain fork
+const MetricMetaHdrConstructionRejections = "erd_meta_hdr_construction_rejections"
+
+// MetricMetaBlocksPoolNearCapacity is the metric that counts how many times the meta blocks from the headers pool
+// exceeded the configured fill ratio, whatever the body composition used by the node
+const MetricMetaBlocksPoolNearCapacity = "erd_meta_blocks_pool_near_capacity"
+
+// MetricCommitBlockDurationMs is the metric that stores the duration, in milliseconds, of the last successful block commit
+const MetricCommitBlockDurationMs = "erd_commit_block_duration_ms"
+
+// MetricGasRefundedPerBlock is the metric that stores the total gas refunded by the transactions of the last committed block
+const MetricGasRefundedPerBlock = "erd_gas_refunded_per_block"
+
+// MetricRequestedMetaHeaders is the metric that stores the number of meta headers requested while processing the last block
+const MetricRequestedMetaHeaders = "erd_requested_meta_headers"
+
+// MetricRequestedFinalMetaHeaders is the metric that stores the number of finality attesting meta headers requested
+// while processing the last block
+const MetricRequestedFinalMetaHeaders = "erd_requested_final_meta_headers"
+
+// MetricMissingMetaHeaders is the metric that stores the number of requested meta headers which were still missing
+// after waiting for them while processing the last block
+const MetricMissingMetaHeaders = "erd_missing_meta_headers"
+
+// MetricNumBlocksWithMetaHeadersRequests is the metric that counts the processed blocks which required at least one
+// meta header request. A steady increase signals a node which is chronically behind on meta headers
+const MetricNumBlocksWithMetaHeadersRequests = "erd_num_blocks_with_meta_headers_requests"
+
+// MetricBlockProductionSuccessRatePercent is the metric that stores the percentage of the recent rounds in which the
+// node created a block that was also committed
+const MetricBlockProductionSuccessRatePercent = "erd_block_production_success_rate_percent"
+
+// MetricMetaBlockAttestationEfficiency is the metric that stores, for the last proposed block, the ratio between the
+// number of attested metablocks and the number of metablocks available for attestation
+const MetricMetaBlockAttestationEfficiency = "erd_meta_block_attestation_efficiency"
+
 // MetricNumTimesInForkChoice is the metric that counts how many time a node was in fork choice
 const MetricNumTimesInForkChoice = "erd_fork_choice_count"
 
@@ -3,6 +3,7 @@ package indexer
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"time"
 
 	logger "github.com/ElrondNetwork/elrond-go-logger"
@@ -12,8 +13,6 @@ import (
 
 var log = logger.GetOrCreate("core/indexer")
 
-const durationBetweenErrorRetry = time.Second * 3
-
 // Options structure holds the indexer's configuration options
 type Options struct {
 	IndexerCacheSize int
@@ -26,19 +25,29 @@ const (
 )
 
 type dataDispatcher struct {
-	backOffTime   time.Duration
-	chanWorkItems chan workItems.WorkItemHandler
-	cancelFunc    func()
+	backOffTime        time.Duration
+	retryInterval      time.Duration
+	dropOldestWhenFull bool
+	numDroppedItems    uint64
+	chanWorkItems      chan workItems.WorkItemHandler
+	cancelFunc         func()
 }
 
-// NewDataDispatcher creates a new dataDispatcher instance, capable of saving sequentially data in elasticsearch database
-func NewDataDispatcher(cacheSize int) (*dataDispatcher, error) {
+// NewDataDispatcher creates a new dataDispatcher instance, capable of saving sequentially data in elasticsearch database.
+// An item which failed to be saved is retried once every retryInterval. When the cache is full, the oldest cached item
+// is dropped in favour of the new one if dropOldestWhenFull is set, otherwise the caller is blocked until there is room
+func NewDataDispatcher(cacheSize int, retryInterval time.Duration, dropOldestWhenFull bool) (*dataDispatcher, error) {
 	if cacheSize < 0 {
 		return nil, ErrNegativeCacheSize
 	}
+	if retryInterval <= 0 {
+		return nil, ErrInvalidRetryInterval
+	}
 
 	dd := &dataDispatcher{
-		chanWorkItems: make(chan workItems.WorkItemHandler, cacheSize),
+		retryInterval:      retryInterval,
+		dropOldestWhenFull: dropOldestWhenFull,
+		chanWorkItems:      make(chan workItems.WorkItemHandler, cacheSize),
 	}
 
 	return dd, nil
@@ -80,7 +89,31 @@ func (d *dataDispatcher) Add(item workItems.WorkItemHandler) {
 		return
 	}
 
-	d.chanWorkItems <- item
+	if !d.dropOldestWhenFull || cap(d.chanWorkItems) == 0 {
+		d.chanWorkItems <- item
+		return
+	}
+
+	for {
+		select {
+		case d.chanWorkItems <- item:
+			return
+		default:
+		}
+
+		select {
+		case <-d.chanWorkItems:
+			numDroppedItems := atomic.AddUint64(&d.numDroppedItems, 1)
+			log.Warn("dataDispatcher.Add: cache is full, dropped the oldest item",
+				"num dropped items", numDroppedItems)
+		default:
+		}
+	}
+}
+
+// NumDroppedItems returns the number of items dropped because the cache was full
+func (d *dataDispatcher) NumDroppedItems() uint64 {
+	return atomic.LoadUint64(&d.numDroppedItems)
 }
 
 func (d *dataDispatcher) doWork(wi workItems.WorkItemHandler) {
@@ -99,7 +132,7 @@ func (d *dataDispatcher) doWork(wi workItems.WorkItemHandler) {
 		d.backOffTime = 0
 		if err != nil {
 			log.Warn("dataDispatcher.doWork could not index item (will retry)", "error", err.Error())
-			time.Sleep(durationBetweenErrorRetry)
+			time.Sleep(d.retryInterval)
 
 			continue
 		}
@@ -10,22 +10,35 @@ import (
 
 	"github.com/ElrondNetwork/elrond-go/core/indexer/workItems"
 	"github.com/ElrondNetwork/elrond-go/core/mock"
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/block"
 	"github.com/stretchr/testify/require"
 )
 
+const testRetryInterval = time.Millisecond * 100
+
 func TestNewDataDispatcher_InvalidCacheSize(t *testing.T) {
 	t.Parallel()
 
-	dataDist, err := NewDataDispatcher(-1)
+	dataDist, err := NewDataDispatcher(-1, testRetryInterval, false)
 
 	require.Nil(t, dataDist)
 	require.Equal(t, ErrNegativeCacheSize, err)
 }
 
+func TestNewDataDispatcher_InvalidRetryInterval(t *testing.T) {
+	t.Parallel()
+
+	dataDist, err := NewDataDispatcher(100, 0, false)
+
+	require.Nil(t, dataDist)
+	require.Equal(t, ErrInvalidRetryInterval, err)
+}
+
 func TestNewDataDispatcher(t *testing.T) {
 	t.Parallel()
 
-	dispatcher, err := NewDataDispatcher(100)
+	dispatcher, err := NewDataDispatcher(100, testRetryInterval, false)
 	require.NoError(t, err)
 	require.NotNil(t, dispatcher)
 }
@@ -33,7 +46,7 @@ func TestNewDataDispatcher(t *testing.T) {
 func TestDataDispatcher_StartIndexDataClose(t *testing.T) {
 	t.Parallel()
 
-	dispatcher, err := NewDataDispatcher(100)
+	dispatcher, err := NewDataDispatcher(100, testRetryInterval, false)
 	require.NoError(t, err)
 	dispatcher.StartIndexData()
 
@@ -59,7 +72,7 @@ func TestDataDispatcher_StartIndexDataClose(t *testing.T) {
 func TestDataDispatcher_Add(t *testing.T) {
 	t.Parallel()
 
-	dispatcher, err := NewDataDispatcher(100)
+	dispatcher, err := NewDataDispatcher(100, testRetryInterval, false)
 	require.NoError(t, err)
 	dispatcher.StartIndexData()
 
@@ -95,7 +108,7 @@ func TestDataDispatcher_Add(t *testing.T) {
 func TestDataDispatcher_AddWithErrorShouldRetryTheReprocessing(t *testing.T) {
 	t.Parallel()
 
-	dispatcher, err := NewDataDispatcher(100)
+	dispatcher, err := NewDataDispatcher(100, testRetryInterval, false)
 	require.NoError(t, err)
 	dispatcher.StartIndexData()
 
@@ -120,10 +133,82 @@ func TestDataDispatcher_AddWithErrorShouldRetryTheReprocessing(t *testing.T) {
 	wg.Wait()
 
 	timePassed := time.Since(start)
-	require.Greater(t, int64(timePassed), int64(2*durationBetweenErrorRetry))
+	require.Greater(t, int64(timePassed), int64(2*testRetryInterval))
 
 	require.Equal(t, uint32(3), atomic.LoadUint32(&calledCount))
 
 	err = dispatcher.Close()
 	require.NoError(t, err)
 }
+
+func TestDataDispatcher_AddBlockWithIndexerFailingTwiceShouldEventuallyIndexIt(t *testing.T) {
+	t.Parallel()
+
+	dispatcher, err := NewDataDispatcher(100, testRetryInterval, true)
+	require.NoError(t, err)
+	dispatcher.StartIndexData()
+
+	numCalls := uint32(0)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	header := &block.Header{Nonce: 1}
+	elasticProc := &mock.ElasticProcessorStub{
+		SaveHeaderCalled: func(h data.HeaderHandler, _ []uint64, _ *block.Body, _ []string, _ int) error {
+			if atomic.AddUint32(&numCalls, 1) <= 2 {
+				return errors.New("indexer is not available")
+			}
+
+			require.Equal(t, header, h)
+			wg.Done()
+			return nil
+		},
+	}
+
+	dispatcher.Add(workItems.NewItemBlock(elasticProc, &mock.MarshalizerMock{}, &block.Body{}, header, nil, nil, nil, []byte("hash")))
+	wg.Wait()
+
+	require.Equal(t, uint32(3), atomic.LoadUint32(&numCalls))
+	require.Equal(t, uint64(0), dispatcher.NumDroppedItems())
+
+	err = dispatcher.Close()
+	require.NoError(t, err)
+}
+
+func TestDataDispatcher_AddWithFullCacheShouldDropTheOldestItem(t *testing.T) {
+	t.Parallel()
+
+	cacheSize := 2
+	dispatcher, err := NewDataDispatcher(cacheSize, testRetryInterval, true)
+	require.NoError(t, err)
+
+	mutSavedRounds := sync.Mutex{}
+	savedRounds := make([]uint64, 0)
+	wg := sync.WaitGroup{}
+	wg.Add(cacheSize)
+	elasticProc := &mock.ElasticProcessorStub{
+		SaveRoundsInfoCalled: func(infos []workItems.RoundInfo) error {
+			mutSavedRounds.Lock()
+			savedRounds = append(savedRounds, infos[0].Index)
+			mutSavedRounds.Unlock()
+			wg.Done()
+			return nil
+		},
+	}
+
+	// the dispatcher is not started, so the items are only cached
+	for round := uint64(1); round <= 3; round++ {
+		dispatcher.Add(workItems.NewItemRounds(elasticProc, []workItems.RoundInfo{{Index: round}}))
+	}
+	require.Equal(t, uint64(1), dispatcher.NumDroppedItems())
+
+	dispatcher.StartIndexData()
+	wg.Wait()
+
+	mutSavedRounds.Lock()
+	require.Equal(t, []uint64{2, 3}, savedRounds)
+	mutSavedRounds.Unlock()
+
+	err = dispatcher.Close()
+	require.NoError(t, err)
+}
+
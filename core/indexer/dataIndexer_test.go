@@ -348,7 +348,7 @@ func TestDataIndexer(t *testing.T) {
 func testCreateIndexer(t *testing.T) {
 	indexTemplates, indexPolicies := getIndexTemplateAndPolicies()
 
-	dispatcher, _ := NewDataDispatcher(100)
+	dispatcher, _ := NewDataDispatcher(100, time.Second, false)
 	dbClient, _ := NewElasticClient(elasticsearch.Config{
 		Addresses: []string{"http://localhost:9200"},
 		Username:  "",
@@ -31,6 +31,9 @@ var ErrNilOptions = errors.New("nil options")
 // ErrNegativeCacheSize signals that a invalid cache size has been provided
 var ErrNegativeCacheSize = errors.New("negative cache size")
 
+// ErrInvalidRetryInterval signals that a retry interval which is not positive has been provided
+var ErrInvalidRetryInterval = errors.New("invalid retry interval")
+
 // ErrNilAccountsDB signals that a nil accounts database has been provided
 var ErrNilAccountsDB = errors.New("nil accounts db")
 
@@ -3,6 +3,7 @@ package factory
 import (
 	"fmt"
 	"path"
+	"time"
 
 	"github.com/ElrondNetwork/elrond-go/core"
 	"github.com/ElrondNetwork/elrond-go/core/check"
@@ -25,6 +26,8 @@ const (
 type ArgsIndexerFactory struct {
 	Enabled                  bool
 	IndexerCacheSize         int
+	IndexerRetryInterval     time.Duration
+	DropOldestWhenCacheFull  bool
 	ShardCoordinator         sharding.Coordinator
 	Url                      string
 	UserName                 string
@@ -60,7 +63,7 @@ func NewIndexer(args *ArgsIndexerFactory) (indexer.Indexer, error) {
 		return nil, err
 	}
 
-	dispatcher, err := indexer.NewDataDispatcher(args.IndexerCacheSize)
+	dispatcher, err := indexer.NewDataDispatcher(args.IndexerCacheSize, args.IndexerRetryInterval, args.DropOldestWhenCacheFull)
 	if err != nil {
 		return nil, err
 	}
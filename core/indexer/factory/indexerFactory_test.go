@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ElrondNetwork/elrond-go/core"
 	"github.com/ElrondNetwork/elrond-go/core/indexer"
@@ -19,6 +20,7 @@ func createMockIndexerFactoryArgs() *ArgsIndexerFactory {
 	return &ArgsIndexerFactory{
 		Enabled:                  true,
 		IndexerCacheSize:         100,
+		IndexerRetryInterval:     time.Second,
 		Url:                      ts.URL,
 		UserName:                 "",
 		Password:                 "",
@@ -134,6 +136,15 @@ func TestNewIndexerFactory(t *testing.T) {
 			},
 			exError: core.ErrNilTransactionFeeCalculator,
 		},
+		{
+			name: "InvalidRetryInterval",
+			argsFunc: func() *ArgsIndexerFactory {
+				args := createMockIndexerFactoryArgs()
+				args.IndexerRetryInterval = 0
+				return args
+			},
+			exError: indexer.ErrInvalidRetryInterval,
+		},
 		{
 			name: "All arguments ok",
 			argsFunc: func() *ArgsIndexerFactory {
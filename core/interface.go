@@ -14,6 +14,11 @@ type AppStatusHandler interface {
 	Close()
 }
 
+// AppStatusBatchHandler defines the status handlers able to set several metric values at once
+type AppStatusBatchHandler interface {
+	SetMultiple(values map[string]interface{})
+}
+
 // ConnectedAddressesHandler interface will be used for passing the network component to AppStatusPolling
 type ConnectedAddressesHandler interface {
 	ConnectedAddresses() []string
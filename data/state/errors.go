@@ -115,3 +115,6 @@ var ErrNilMapOfHashes = errors.New("nil map of hashes")
 
 // ErrInvalidRootHash signals that the provided root hash is invalid
 var ErrInvalidRootHash = errors.New("invalid root hash")
+
+// ErrValidationInProgress signals that a block validation is in progress on the validation accounts
+var ErrValidationInProgress = errors.New("validation in progress")
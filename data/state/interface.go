@@ -144,6 +144,14 @@ type AccountsAdapter interface {
 	IsInterfaceNil() bool
 }
 
+// ValidationAccountsAdapter is an accounts adapter which can be switched to scratch accounts, never committed, while
+// a block is validated
+type ValidationAccountsAdapter interface {
+	AccountsAdapter
+	StartValidation(rootHash []byte) error
+	EndValidation()
+}
+
 // JournalEntry will be used to implement different state changes to be able to easily revert them
 type JournalEntry interface {
 	Revert() (AccountHandler, error)
@@ -0,0 +1,177 @@
+package state
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data"
+)
+
+// ValidationAccountsDB is the accounts adapter used by the blocks processing when the blocks can also be validated
+// without being applied. It works on the live accounts, except while a block is validated, when it works on the
+// scratch accounts, recreated from the last committed state and never committed. The readers of the state which do
+// not use this adapter never observe the changes done by a validation
+type ValidationAccountsDB struct {
+	liveAccounts    AccountsAdapter
+	scratchAccounts AccountsAdapter
+	mutAccounts     sync.RWMutex
+	accounts        AccountsAdapter
+	isValidating    bool
+}
+
+// NewValidationAccountsDB creates a new validation accounts adapter working on the live accounts
+func NewValidationAccountsDB(liveAccounts AccountsAdapter, scratchAccounts AccountsAdapter) (*ValidationAccountsDB, error) {
+	if check.IfNil(liveAccounts) {
+		return nil, ErrNilAccountsAdapter
+	}
+	if check.IfNil(scratchAccounts) {
+		return nil, ErrNilAccountsAdapter
+	}
+
+	return &ValidationAccountsDB{
+		liveAccounts:    liveAccounts,
+		scratchAccounts: scratchAccounts,
+		accounts:        liveAccounts,
+	}, nil
+}
+
+// StartValidation recreates the scratch accounts from the given committed root hash and switches to them
+func (vadb *ValidationAccountsDB) StartValidation(rootHash []byte) error {
+	vadb.mutAccounts.Lock()
+	defer vadb.mutAccounts.Unlock()
+
+	if vadb.isValidating {
+		return ErrValidationInProgress
+	}
+
+	err := vadb.scratchAccounts.RecreateTrie(rootHash)
+	if err != nil {
+		return err
+	}
+
+	vadb.accounts = vadb.scratchAccounts
+	vadb.isValidating = true
+
+	return nil
+}
+
+// EndValidation switches back to the live accounts. The changes done on the scratch accounts are dropped on the next
+// validation start
+func (vadb *ValidationAccountsDB) EndValidation() {
+	vadb.mutAccounts.Lock()
+	vadb.accounts = vadb.liveAccounts
+	vadb.isValidating = false
+	vadb.mutAccounts.Unlock()
+}
+
+func (vadb *ValidationAccountsDB) getAccounts() AccountsAdapter {
+	vadb.mutAccounts.RLock()
+	defer vadb.mutAccounts.RUnlock()
+
+	return vadb.accounts
+}
+
+// GetExistingAccount will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) GetExistingAccount(address []byte) (AccountHandler, error) {
+	return vadb.getAccounts().GetExistingAccount(address)
+}
+
+// LoadAccount will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) LoadAccount(address []byte) (AccountHandler, error) {
+	return vadb.getAccounts().LoadAccount(address)
+}
+
+// SaveAccount will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) SaveAccount(account AccountHandler) error {
+	return vadb.getAccounts().SaveAccount(account)
+}
+
+// RemoveAccount will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) RemoveAccount(address []byte) error {
+	return vadb.getAccounts().RemoveAccount(address)
+}
+
+// Commit will call the live accounts' function with the same name. The scratch accounts are never committed, so an
+// error is returned while a block is validated
+func (vadb *ValidationAccountsDB) Commit() ([]byte, error) {
+	vadb.mutAccounts.RLock()
+	defer vadb.mutAccounts.RUnlock()
+
+	if vadb.isValidating {
+		return nil, ErrValidationInProgress
+	}
+
+	return vadb.liveAccounts.Commit()
+}
+
+// JournalLen will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) JournalLen() int {
+	return vadb.getAccounts().JournalLen()
+}
+
+// RevertToSnapshot will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) RevertToSnapshot(snapshot int) error {
+	return vadb.getAccounts().RevertToSnapshot(snapshot)
+}
+
+// GetNumCheckpoints will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) GetNumCheckpoints() uint32 {
+	return vadb.liveAccounts.GetNumCheckpoints()
+}
+
+// GetCode will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) GetCode(codeHash []byte) []byte {
+	return vadb.getAccounts().GetCode(codeHash)
+}
+
+// RootHash will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) RootHash() ([]byte, error) {
+	return vadb.getAccounts().RootHash()
+}
+
+// RecreateTrie will call the current accounts' function with the same name
+func (vadb *ValidationAccountsDB) RecreateTrie(rootHash []byte) error {
+	return vadb.getAccounts().RecreateTrie(rootHash)
+}
+
+// PruneTrie will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) PruneTrie(rootHash []byte, identifier data.TriePruningIdentifier) {
+	vadb.liveAccounts.PruneTrie(rootHash, identifier)
+}
+
+// CancelPrune will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) CancelPrune(rootHash []byte, identifier data.TriePruningIdentifier) {
+	vadb.liveAccounts.CancelPrune(rootHash, identifier)
+}
+
+// SnapshotState will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) SnapshotState(rootHash []byte, ctx context.Context) {
+	vadb.liveAccounts.SnapshotState(rootHash, ctx)
+}
+
+// SetStateCheckpoint will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) SetStateCheckpoint(rootHash []byte, ctx context.Context) {
+	vadb.liveAccounts.SetStateCheckpoint(rootHash, ctx)
+}
+
+// IsPruningEnabled will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) IsPruningEnabled() bool {
+	return vadb.liveAccounts.IsPruningEnabled()
+}
+
+// GetAllLeaves will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) GetAllLeaves(rootHash []byte, ctx context.Context) (chan core.KeyValueHolder, error) {
+	return vadb.liveAccounts.GetAllLeaves(rootHash, ctx)
+}
+
+// RecreateAllTries will call the live accounts' function with the same name
+func (vadb *ValidationAccountsDB) RecreateAllTries(rootHash []byte, ctx context.Context) (map[string]data.Trie, error) {
+	return vadb.liveAccounts.RecreateAllTries(rootHash, ctx)
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (vadb *ValidationAccountsDB) IsInterfaceNil() bool {
+	return vadb == nil
+}
@@ -0,0 +1,140 @@
+package state_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data/mock"
+	"github.com/ElrondNetwork/elrond-go/data/state"
+	"github.com/ElrondNetwork/elrond-go/data/state/factory"
+	"github.com/ElrondNetwork/elrond-go/data/trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createLiveAndScratchAccounts() (*state.AccountsDB, *state.AccountsDB) {
+	marshalizer := &mock.MarshalizerMock{}
+	hsh := mock.HasherMock{}
+	storageManager, _ := trie.NewTrieStorageManagerWithoutPruning(mock.NewMemDbMock())
+	maxTrieLevelInMemory := uint(5)
+	tr, _ := trie.NewTrie(storageManager, marshalizer, hsh, maxTrieLevelInMemory)
+	scratchTrie, _ := tr.Recreate(make([]byte, 0))
+
+	liveAccounts, _ := state.NewAccountsDB(tr, hsh, marshalizer, factory.NewAccountCreator())
+	scratchAccounts, _ := state.NewAccountsDB(scratchTrie, hsh, marshalizer, factory.NewAccountCreator())
+
+	return liveAccounts, scratchAccounts
+}
+
+func addBalance(t *testing.T, accounts state.AccountsAdapter, address []byte, value int64) {
+	acc, err := accounts.LoadAccount(address)
+	require.Nil(t, err)
+	err = acc.(state.UserAccountHandler).AddToBalance(big.NewInt(value))
+	require.Nil(t, err)
+	err = accounts.SaveAccount(acc)
+	require.Nil(t, err)
+}
+
+func getBalance(t *testing.T, accounts state.AccountsAdapter, address []byte) *big.Int {
+	acc, err := accounts.GetExistingAccount(address)
+	require.Nil(t, err)
+
+	return acc.(state.UserAccountHandler).GetBalance()
+}
+
+func TestNewValidationAccountsDB_NilLiveAccountsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	_, scratchAccounts := createLiveAndScratchAccounts()
+	vadb, err := state.NewValidationAccountsDB(nil, scratchAccounts)
+
+	assert.True(t, check.IfNil(vadb))
+	assert.Equal(t, state.ErrNilAccountsAdapter, err)
+}
+
+func TestNewValidationAccountsDB_NilScratchAccountsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	liveAccounts, _ := createLiveAndScratchAccounts()
+	vadb, err := state.NewValidationAccountsDB(liveAccounts, nil)
+
+	assert.True(t, check.IfNil(vadb))
+	assert.Equal(t, state.ErrNilAccountsAdapter, err)
+}
+
+func TestValidationAccountsDB_ShouldWorkOnTheLiveAccounts(t *testing.T) {
+	t.Parallel()
+
+	address := make([]byte, 32)
+	liveAccounts, scratchAccounts := createLiveAndScratchAccounts()
+	vadb, err := state.NewValidationAccountsDB(liveAccounts, scratchAccounts)
+	require.Nil(t, err)
+
+	addBalance(t, vadb, address, 10)
+	rootHash, err := vadb.Commit()
+	require.Nil(t, err)
+
+	liveRootHash, _ := liveAccounts.RootHash()
+	assert.Equal(t, liveRootHash, rootHash)
+	assert.Equal(t, big.NewInt(10), getBalance(t, liveAccounts, address))
+	assert.Equal(t, 0, scratchAccounts.JournalLen())
+}
+
+func TestValidationAccountsDB_ValidationShouldNotTouchTheLiveAccounts(t *testing.T) {
+	t.Parallel()
+
+	address := make([]byte, 32)
+	liveAccounts, scratchAccounts := createLiveAndScratchAccounts()
+	vadb, _ := state.NewValidationAccountsDB(liveAccounts, scratchAccounts)
+	addBalance(t, vadb, address, 10)
+	committedRootHash, _ := vadb.Commit()
+
+	err := vadb.StartValidation(committedRootHash)
+	require.Nil(t, err)
+
+	assert.Equal(t, big.NewInt(10), getBalance(t, vadb, address))
+	addBalance(t, vadb, address, 5)
+	assert.Equal(t, big.NewInt(15), getBalance(t, vadb, address))
+	validationRootHash, _ := vadb.RootHash()
+	assert.NotEqual(t, committedRootHash, validationRootHash)
+
+	liveRootHash, _ := liveAccounts.RootHash()
+	assert.Equal(t, committedRootHash, liveRootHash)
+	assert.Equal(t, 0, liveAccounts.JournalLen())
+	assert.Equal(t, big.NewInt(10), getBalance(t, liveAccounts, address))
+
+	_, err = vadb.Commit()
+	assert.Equal(t, state.ErrValidationInProgress, err)
+	err = vadb.StartValidation(committedRootHash)
+	assert.Equal(t, state.ErrValidationInProgress, err)
+
+	vadb.EndValidation()
+	rootHash, _ := vadb.RootHash()
+	assert.Equal(t, committedRootHash, rootHash)
+	assert.Equal(t, big.NewInt(10), getBalance(t, vadb, address))
+
+	// the next validation starts again from the committed state
+	err = vadb.StartValidation(committedRootHash)
+	require.Nil(t, err)
+	assert.Equal(t, big.NewInt(10), getBalance(t, vadb, address))
+	assert.Equal(t, 0, vadb.JournalLen())
+}
+
+func TestValidationAccountsDB_StartValidationWithRecreateErrorShouldNotSwitch(t *testing.T) {
+	t.Parallel()
+
+	liveAccounts, scratchAccounts := createLiveAndScratchAccounts()
+	vadb, _ := state.NewValidationAccountsDB(liveAccounts, scratchAccounts)
+	liveRootHash, _ := liveAccounts.RootHash()
+
+	err := vadb.StartValidation([]byte("missing root hash"))
+	assert.NotNil(t, err)
+
+	addBalance(t, vadb, make([]byte, 32), 10)
+	assert.Equal(t, 1, liveAccounts.JournalLen())
+	_, err = vadb.Commit()
+	assert.Nil(t, err)
+	rootHash, _ := liveAccounts.RootHash()
+	assert.NotEqual(t, liveRootHash, rootHash)
+}
@@ -79,6 +79,7 @@ type NodeHandler interface {
 // TransactionSimulatorProcessor defines the actions which a transaction simulator processor has to implement
 type TransactionSimulatorProcessor interface {
 	ProcessTx(tx *transaction.Transaction) (*transaction.SimulationResults, error)
+	SimulateRelayedTransaction(rtx *transaction.Transaction) (process.RelayedSimResult, error)
 	IsInterfaceNil() bool
 }
 
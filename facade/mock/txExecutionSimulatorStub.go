@@ -2,11 +2,13 @@ package mock
 
 import (
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/process"
 )
 
 // TxExecutionSimulatorStub -
 type TxExecutionSimulatorStub struct {
-	ProcessTxCalled func(tx *transaction.Transaction) (*transaction.SimulationResults, error)
+	ProcessTxCalled                  func(tx *transaction.Transaction) (*transaction.SimulationResults, error)
+	SimulateRelayedTransactionCalled func(rtx *transaction.Transaction) (process.RelayedSimResult, error)
 }
 
 // ProcessTx -
@@ -18,6 +20,15 @@ func (t *TxExecutionSimulatorStub) ProcessTx(tx *transaction.Transaction) (*tran
 	return &transaction.SimulationResults{}, nil
 }
 
+// SimulateRelayedTransaction -
+func (t *TxExecutionSimulatorStub) SimulateRelayedTransaction(rtx *transaction.Transaction) (process.RelayedSimResult, error) {
+	if t.SimulateRelayedTransactionCalled != nil {
+		return t.SimulateRelayedTransactionCalled(rtx)
+	}
+
+	return process.RelayedSimResult{}, nil
+}
+
 // IsInterfaceNil -
 func (t *TxExecutionSimulatorStub) IsInterfaceNil() bool {
 	return t == nil
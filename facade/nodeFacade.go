@@ -325,6 +325,12 @@ func (nf *nodeFacade) SimulateTransactionExecution(tx *transaction.Transaction)
 	return nf.txSimulatorProc.ProcessTx(tx)
 }
 
+// SimulateRelayedTransaction will simulate a relayed transaction and will return the fees charged upfront, the return
+// code and the processing error, without keeping any of its effects
+func (nf *nodeFacade) SimulateRelayedTransaction(rtx *transaction.Transaction) (process.RelayedSimResult, error) {
+	return nf.txSimulatorProc.SimulateRelayedTransaction(rtx)
+}
+
 // GetTransaction gets the transaction with a specified hash
 func (nf *nodeFacade) GetTransaction(hash string, withResults bool) (*transaction.ApiTransactionResult, error) {
 	return nf.node.GetTransaction(hash, withResults)
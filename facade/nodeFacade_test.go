@@ -654,3 +654,27 @@ func TestNodeFacade_GetThrottlerForEndpointShouldFindAndReturn(t *testing.T) {
 	assert.NotNil(t, thr)
 	assert.True(t, ok)
 }
+
+func TestNodeFacade_SimulateRelayedTransactionShouldUseTheTxSimulator(t *testing.T) {
+	t.Parallel()
+
+	rtx := &transaction.Transaction{Nonce: 37}
+	expectedResult := process.RelayedSimResult{
+		ReturnCode: vmcommon.Ok,
+		RelayerFee: big.NewInt(10),
+		InnerTxFee: big.NewInt(20),
+	}
+	arg := createMockArguments()
+	arg.TxSimulatorProcessor = &mock.TxExecutionSimulatorStub{
+		SimulateRelayedTransactionCalled: func(tx *transaction.Transaction) (process.RelayedSimResult, error) {
+			assert.True(t, tx == rtx)
+			return expectedResult, nil
+		},
+	}
+	nf, _ := NewNodeFacade(arg)
+
+	result, err := nf.SimulateRelayedTransaction(rtx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResult, result)
+}
@@ -11,6 +11,10 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/forking"
 	"github.com/ElrondNetwork/elrond-go/core/parsers"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
+	"github.com/ElrondNetwork/elrond-go/crypto/signing"
+	disabledSigning "github.com/ElrondNetwork/elrond-go/crypto/signing/disabled"
+	disabledSingleSig "github.com/ElrondNetwork/elrond-go/crypto/signing/disabled/singlesig"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/block"
 	dataBlock "github.com/ElrondNetwork/elrond-go/data/block"
@@ -54,6 +58,9 @@ func createGenesisConfig() config.GeneralSettingsConfig {
 		SwitchHysteresisForMinNodesEnableEpoch: unreachableEpoch,
 		SwitchJailWaitingEnableEpoch:           unreachableEpoch,
 		BlockGasAndFeesReCheckEnableEpoch:      unreachableEpoch,
+		InnerTxSignatureCheckEnableEpoch:       unreachableEpoch,
+		RelayedGasPriceCheckEnableEpoch:        unreachableEpoch,
+		RelayerFundsCheckEnableEpoch:           unreachableEpoch,
 	}
 }
 
@@ -398,24 +405,31 @@ func createProcessorsForShardGenesisBlock(arg ArgsGenesisBlockCreator, generalCo
 	}
 
 	argsNewTxProcessor := transaction.ArgsNewTxProcessor{
-		Accounts:                       arg.Accounts,
-		Hasher:                         arg.Hasher,
-		PubkeyConv:                     arg.PubkeyConv,
-		Marshalizer:                    arg.Marshalizer,
-		SignMarshalizer:                arg.SignMarshalizer,
-		ShardCoordinator:               arg.ShardCoordinator,
-		ScProcessor:                    scProcessor,
-		TxFeeHandler:                   genesisFeeHandler,
-		TxTypeHandler:                  txTypeHandler,
-		EconomicsFee:                   genesisFeeHandler,
-		ReceiptForwarder:               receiptTxInterim,
-		BadTxForwarder:                 badTxInterim,
-		ArgsParser:                     smartContract.NewArgumentParser(),
-		ScrForwarder:                   scForwarder,
-		EpochNotifier:                  epochNotifier,
-		RelayedTxEnableEpoch:           generalConfig.RelayedTransactionsEnableEpoch,
-		PenalizedTooMuchGasEnableEpoch: generalConfig.PenalizedTooMuchGasEnableEpoch,
-		MetaProtectionEnableEpoch:      generalConfig.MetaProtectionEnableEpoch,
+		Accounts:                         arg.Accounts,
+		Hasher:                           arg.Hasher,
+		PubkeyConv:                       arg.PubkeyConv,
+		Marshalizer:                      arg.Marshalizer,
+		SignMarshalizer:                  arg.SignMarshalizer,
+		ShardCoordinator:                 arg.ShardCoordinator,
+		ScProcessor:                      scProcessor,
+		TxFeeHandler:                     genesisFeeHandler,
+		TxTypeHandler:                    txTypeHandler,
+		EconomicsFee:                     genesisFeeHandler,
+		ReceiptForwarder:                 receiptTxInterim,
+		BadTxForwarder:                   badTxInterim,
+		ArgsParser:                       smartContract.NewArgumentParser(),
+		ScrForwarder:                     scForwarder,
+		KeyGen:                           signing.NewKeyGenerator(disabledSigning.NewDisabledSuite()),
+		SingleSigner:                     &disabledSingleSig.DisabledSingleSig{},
+		TxSignHasher:                     arg.Hasher,
+		TxVersionChecker:                 versioning.NewTxVersionChecker(0),
+		EpochNotifier:                    epochNotifier,
+		RelayedTxEnableEpoch:             generalConfig.RelayedTransactionsEnableEpoch,
+		PenalizedTooMuchGasEnableEpoch:   generalConfig.PenalizedTooMuchGasEnableEpoch,
+		MetaProtectionEnableEpoch:        generalConfig.MetaProtectionEnableEpoch,
+		InnerTxSignatureCheckEnableEpoch: generalConfig.InnerTxSignatureCheckEnableEpoch,
+		RelayedGasPriceCheckEnableEpoch:  generalConfig.RelayedGasPriceCheckEnableEpoch,
+		RelayerFundsCheckEnableEpoch:     generalConfig.RelayerFundsCheckEnableEpoch,
 	}
 	transactionProcessor, err := transaction.NewTxProcessor(argsNewTxProcessor)
 	if err != nil {
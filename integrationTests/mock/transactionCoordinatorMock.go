@@ -19,19 +19,23 @@ type TransactionCoordinatorMock struct {
 	RemoveBlockDataFromPoolCalled                        func(body *block.Body) error
 	RemoveTxsFromPoolCalled                              func(body *block.Body) error
 	ProcessBlockTransactionCalled                        func(body *block.Body, haveTime func() time.Duration) error
+	ProcessBlockTransactionFromMeFirstCalled             func(body *block.Body, haveTime func() time.Duration) error
 	CreateBlockStartedCalled                             func()
 	CreateMbsAndProcessCrossShardTransactionsDstMeCalled func(header data.HeaderHandler,
 		processedMiniBlocksHashes map[string]struct{},
 
 		haveTime func() bool,
 	) (block.MiniBlockSlice, uint32, bool, error)
-	CreateMbsAndProcessTransactionsFromMeCalled func(haveTime func() bool) block.MiniBlockSlice
-	CreateMarshalizedDataCalled                 func(body *block.Body) map[string][][]byte
-	GetAllCurrentUsedTxsCalled                  func(blockType block.Type) map[string]data.TransactionHandler
-	VerifyCreatedBlockTransactionsCalled        func(hdr data.HeaderHandler, body *block.Body) error
-	CreatePostProcessMiniBlocksCalled           func() block.MiniBlockSlice
-	CreateMarshalizedReceiptsCalled             func() ([]byte, error)
-	VerifyCreatedMiniBlocksCalled               func(hdr data.HeaderHandler, body *block.Body) error
+	CreateMbsAndProcessTransactionsFromMeCalled                   func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice
+	CreateMbsAndProcessTransactionsFromMeWithoutPostProcessCalled func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice
+	CreateMarshalizedDataCalled                                   func(body *block.Body) map[string][][]byte
+	GetAllCurrentUsedTxsCalled                                    func(blockType block.Type) map[string]data.TransactionHandler
+	VerifyCreatedBlockTransactionsCalled                          func(hdr data.HeaderHandler, body *block.Body) error
+	CreatePostProcessMiniBlocksCalled                             func() block.MiniBlockSlice
+	CreateMarshalizedReceiptsCalled                               func() ([]byte, error)
+	VerifyCreatedMiniBlocksCalled                                 func(hdr data.HeaderHandler, body *block.Body) error
+	GetTransactionsExecutionResultsCalled                         func() []*process.TransactionExecutionResult
+	GetTotalGasRefundedCalled                                     func() uint64
 }
 
 // CreatePostProcessMiniBlocks -
@@ -128,6 +132,15 @@ func (tcm *TransactionCoordinatorMock) ProcessBlockTransaction(body *block.Body,
 	return tcm.ProcessBlockTransactionCalled(body, haveTime)
 }
 
+// ProcessBlockTransactionFromMeFirst -
+func (tcm *TransactionCoordinatorMock) ProcessBlockTransactionFromMeFirst(body *block.Body, haveTime func() time.Duration) error {
+	if tcm.ProcessBlockTransactionFromMeFirstCalled == nil {
+		return nil
+	}
+
+	return tcm.ProcessBlockTransactionFromMeFirstCalled(body, haveTime)
+}
+
 // CreateBlockStarted -
 func (tcm *TransactionCoordinatorMock) CreateBlockStarted() {
 	if tcm.CreateBlockStartedCalled == nil {
@@ -152,12 +165,31 @@ func (tcm *TransactionCoordinatorMock) CreateMbsAndProcessCrossShardTransactions
 }
 
 // CreateMbsAndProcessTransactionsFromMe -
-func (tcm *TransactionCoordinatorMock) CreateMbsAndProcessTransactionsFromMe(haveTime func() bool) block.MiniBlockSlice {
+func (tcm *TransactionCoordinatorMock) CreateMbsAndProcessTransactionsFromMe(
+	haveTime func() bool,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
+) block.MiniBlockSlice {
 	if tcm.CreateMbsAndProcessTransactionsFromMeCalled == nil {
 		return nil
 	}
 
-	return tcm.CreateMbsAndProcessTransactionsFromMeCalled(haveTime)
+	return tcm.CreateMbsAndProcessTransactionsFromMeCalled(haveTime, minGasPriceForInclusion, maxTxDataSize, maxTxsPerDestShard)
+}
+
+// CreateMbsAndProcessTransactionsFromMeWithoutPostProcess -
+func (tcm *TransactionCoordinatorMock) CreateMbsAndProcessTransactionsFromMeWithoutPostProcess(
+	haveTime func() bool,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
+) block.MiniBlockSlice {
+	if tcm.CreateMbsAndProcessTransactionsFromMeWithoutPostProcessCalled == nil {
+		return nil
+	}
+
+	return tcm.CreateMbsAndProcessTransactionsFromMeWithoutPostProcessCalled(haveTime, minGasPriceForInclusion, maxTxDataSize, maxTxsPerDestShard)
 }
 
 // CreateMarshalizedData -
@@ -205,6 +237,24 @@ func (tcm *TransactionCoordinatorMock) VerifyCreatedMiniBlocks(hdr data.HeaderHa
 	return tcm.VerifyCreatedMiniBlocksCalled(hdr, body)
 }
 
+// GetTransactionsExecutionResults -
+func (tcm *TransactionCoordinatorMock) GetTransactionsExecutionResults() []*process.TransactionExecutionResult {
+	if tcm.GetTransactionsExecutionResultsCalled == nil {
+		return nil
+	}
+
+	return tcm.GetTransactionsExecutionResultsCalled()
+}
+
+// GetTotalGasRefunded -
+func (tcm *TransactionCoordinatorMock) GetTotalGasRefunded() uint64 {
+	if tcm.GetTotalGasRefundedCalled == nil {
+		return 0
+	}
+
+	return tcm.GetTotalGasRefundedCalled()
+}
+
 // IsInterfaceNil returns true if there is no value under the interface
 func (tcm *TransactionCoordinatorMock) IsInterfaceNil() bool {
 	return tcm == nil
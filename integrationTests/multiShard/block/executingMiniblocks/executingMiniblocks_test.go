@@ -1,6 +1,7 @@
 package executingMiniblocks
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
 	"github.com/ElrondNetwork/elrond-go/dataRetriever"
 	"github.com/ElrondNetwork/elrond-go/integrationTests"
+	"github.com/ElrondNetwork/elrond-go/process"
 	"github.com/ElrondNetwork/elrond-go/process/factory"
 	"github.com/ElrondNetwork/elrond-go/sharding"
 	"github.com/stretchr/testify/assert"
@@ -587,3 +589,241 @@ func printContainingTxs(tpn *integrationTests.TestProcessorNode, hdr *block.Head
 		}
 	}
 }
+
+func TestShardBlockAttestationCoverageShouldBeReportedAfterMetachainNotarizesIt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	numOfShards := 2
+	nodesPerShard := 1
+	numMetachainNodes := 1
+
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+
+	nodes := integrationTests.CreateNodes(
+		numOfShards,
+		nodesPerShard,
+		numMetachainNodes,
+		integrationTests.GetConnectableAddress(advertiser),
+	)
+	integrationTests.DisplayAndStartNodes(nodes)
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	idxProposers := []int{0, 1, 2}
+	round := uint64(0)
+	nonce := uint64(0)
+	round = integrationTests.IncrementAndPrintRound(round)
+	nonce++
+
+	round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+
+	shardNode := nodes[0]
+	committedHeaderHash := shardNode.BlockChain.GetCurrentBlockHeaderHash()
+	require.NotNil(t, committedHeaderHash)
+
+	roundsToWait := 4
+	for i := 0; i < roundsToWait; i++ {
+		round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+	}
+
+	coverageHandler, ok := shardNode.BlockProcessor.(process.AttestationCoverageHandler)
+	require.True(t, ok)
+
+	attestations, err := coverageHandler.AttestationCoverage(committedHeaderHash)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(attestations))
+	assert.True(t, attestations[0].MetaNonce > 0)
+
+	metaNode := nodes[len(nodes)-1]
+	metaBlock, err := process.GetMetaHeaderFromStorage(attestations[0].MetaHash, integrationTests.TestMarshalizer, metaNode.Storage)
+	require.Nil(t, err)
+	assert.Equal(t, attestations[0].MetaNonce, metaBlock.GetNonce())
+}
+
+func TestShardBlockFinalityProofShouldBeProducedAfterMetachainFinalizesIt(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	numOfShards := 2
+	nodesPerShard := 1
+	numMetachainNodes := 1
+
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+
+	nodes := integrationTests.CreateNodes(
+		numOfShards,
+		nodesPerShard,
+		numMetachainNodes,
+		integrationTests.GetConnectableAddress(advertiser),
+	)
+	integrationTests.DisplayAndStartNodes(nodes)
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	idxProposers := []int{0, 1, 2}
+	round := uint64(0)
+	nonce := uint64(0)
+	round = integrationTests.IncrementAndPrintRound(round)
+	nonce++
+
+	round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+
+	shardNode := nodes[0]
+	committedHeaderHash := shardNode.BlockChain.GetCurrentBlockHeaderHash()
+	require.NotNil(t, committedHeaderHash)
+
+	roundsToWait := 4
+	for i := 0; i < roundsToWait; i++ {
+		round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+	}
+
+	finalityProofHandler, ok := shardNode.BlockProcessor.(process.FinalityProofHandler)
+	require.True(t, ok)
+
+	proof, err := finalityProofHandler.FinalityProof(committedHeaderHash)
+	require.Nil(t, err)
+	assert.Equal(t, committedHeaderHash, proof.ShardHeaderHash)
+
+	isNotarizedByAttestingMetaBlock := false
+	for _, shardInfo := range proof.AttestingMetaBlock.ShardInfo {
+		if bytes.Equal(shardInfo.HeaderHash, committedHeaderHash) {
+			isNotarizedByAttestingMetaBlock = true
+		}
+	}
+	assert.True(t, isNotarizedByAttestingMetaBlock)
+
+	require.True(t, len(proof.FinalityMetaBlocks) > 0)
+	require.Equal(t, len(proof.FinalityMetaBlocks), len(proof.FinalityMetaHashes))
+	prevMetaHash := proof.AttestingMetaHash
+	prevMetaNonce := proof.AttestingMetaBlock.GetNonce()
+	for i, finalityMetaBlock := range proof.FinalityMetaBlocks {
+		assert.Equal(t, prevMetaHash, finalityMetaBlock.GetPrevHash())
+		assert.Equal(t, prevMetaNonce+1, finalityMetaBlock.GetNonce())
+
+		prevMetaHash = proof.FinalityMetaHashes[i]
+		prevMetaNonce = finalityMetaBlock.GetNonce()
+	}
+}
+
+func TestCrossShardSettlementMapShouldReportTheMetaBlocksWhichConfirmedTheCrossShardMiniBlocks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	numOfShards := 2
+	nodesPerShard := 1
+	numMetachainNodes := 1
+
+	senderShard := uint32(0)
+	receiverShard := uint32(1)
+	valMinting := big.NewInt(10000000)
+	valToTransferPerTx := big.NewInt(2)
+
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+
+	nodes := integrationTests.CreateNodes(
+		numOfShards,
+		nodesPerShard,
+		numMetachainNodes,
+		integrationTests.GetConnectableAddress(advertiser),
+	)
+	integrationTests.DisplayAndStartNodes(nodes)
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	generateCoordinator, _ := sharding.NewMultiShardCoordinator(uint32(numOfShards), 0)
+	numTxs := 3
+	sendersPrivateKeys := make([]crypto.PrivateKey, numTxs)
+	receiversPublicKeys := make(map[uint32][]crypto.PublicKey)
+	for i := 0; i < numTxs; i++ {
+		sendersPrivateKeys[i], _, _ = integrationTests.GenerateSkAndPkInShard(generateCoordinator, senderShard)
+		_, pk, _ := integrationTests.GenerateSkAndPkInShard(generateCoordinator, receiverShard)
+		receiversPublicKeys[receiverShard] = append(receiversPublicKeys[receiverShard], pk)
+	}
+
+	integrationTests.CreateMintingForSenders(nodes, senderShard, sendersPrivateKeys, valMinting)
+	integrationTests.GenerateAndDisseminateTxs(
+		nodes[senderShard],
+		sendersPrivateKeys,
+		receiversPublicKeys,
+		valToTransferPerTx,
+		integrationTests.MinTxGasPrice,
+		integrationTests.MinTxGasLimit,
+		integrationTests.ChainID,
+		integrationTests.MinTransactionVersion,
+	)
+	time.Sleep(time.Second * 5)
+
+	idxProposers := []int{0, 1, 2}
+	round := uint64(0)
+	nonce := uint64(0)
+	round = integrationTests.IncrementAndPrintRound(round)
+	nonce++
+
+	roundsToWait := 6
+	for i := 0; i < roundsToWait; i++ {
+		round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+	}
+
+	for _, pk := range receiversPublicKeys[receiverShard] {
+		integrationTests.TestPublicKeyHasBalance(t, nodes[receiverShard], pk, valToTransferPerTx)
+	}
+
+	receiverNode := nodes[receiverShard]
+	metaNode := nodes[len(nodes)-1]
+	settlementHandler, ok := receiverNode.BlockProcessor.(process.CrossShardSettlementHandler)
+	require.True(t, ok)
+
+	numCheckedMiniBlocks := 0
+	headerHash := receiverNode.BlockChain.GetCurrentBlockHeaderHash()
+	for len(headerHash) > 0 {
+		header, err := process.GetShardHeaderFromStorage(headerHash, integrationTests.TestMarshalizer, receiverNode.Storage)
+		if err != nil {
+			break
+		}
+
+		settlementMap, err := settlementHandler.CrossShardSettlementMap(headerHash)
+		require.Nil(t, err)
+
+		for _, miniBlockHeader := range header.MiniBlockHeaders {
+			if miniBlockHeader.SenderShardID != senderShard {
+				continue
+			}
+
+			metaHash, found := settlementMap[string(miniBlockHeader.Hash)]
+			require.True(t, found)
+			assert.Contains(t, header.MetaBlockHashes, []byte(metaHash))
+
+			metaBlock, errGet := process.GetMetaHeaderFromStorage([]byte(metaHash), integrationTests.TestMarshalizer, metaNode.Storage)
+			require.Nil(t, errGet)
+			assert.Contains(t, metaBlock.GetMiniBlockHeadersWithDst(receiverShard), string(miniBlockHeader.Hash))
+
+			numCheckedMiniBlocks++
+		}
+
+		headerHash = header.GetPrevHash()
+	}
+
+	assert.True(t, numCheckedMiniBlocks > 0)
+}
@@ -78,6 +78,50 @@ func CreateAndSendRelayedAndUserTx(
 	return relayedTx
 }
 
+func createAndSendRelayedAndTamperedUserTx(
+	nodes []*integrationTests.TestProcessorNode,
+	relayer *integrationTests.TestWalletAccount,
+	player *integrationTests.TestWalletAccount,
+	rcvAddr []byte,
+	value *big.Int,
+	gasLimit uint64,
+	txData []byte,
+) {
+	txDispatcherNode := getNodeWithinSameShardAsPlayer(nodes, relayer.Address)
+
+	userTx := createUserTx(player, rcvAddr, value, gasLimit, txData)
+	userTx.Signature[0] ^= 0xFF
+	relayedTx := createRelayedTx(txDispatcherNode.EconomicsData, relayer, userTx)
+
+	_, err := txDispatcherNode.SendTransaction(relayedTx)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+func createAndSendRelayedAndOverpricedUserTx(
+	nodes []*integrationTests.TestProcessorNode,
+	relayer *integrationTests.TestWalletAccount,
+	player *integrationTests.TestWalletAccount,
+	rcvAddr []byte,
+	value *big.Int,
+	gasLimit uint64,
+	txData []byte,
+) {
+	txDispatcherNode := getNodeWithinSameShardAsPlayer(nodes, relayer.Address)
+
+	userTx := createUserTx(player, rcvAddr, value, gasLimit, txData)
+	userTx.GasPrice = integrationTests.MinTxGasPrice * 2
+	txBuff, _ := userTx.GetDataForSigning(integrationTests.TestAddressPubkeyConverter, integrationTests.TestTxSignMarshalizer)
+	userTx.Signature, _ = player.SingleSigner.Sign(player.SkTxSign, txBuff)
+	relayedTx := createRelayedTx(txDispatcherNode.EconomicsData, relayer, userTx)
+
+	_, err := txDispatcherNode.SendTransaction(relayedTx)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
 func createUserTx(
 	player *integrationTests.TestWalletAccount,
 	rcvAddr []byte,
@@ -75,6 +75,118 @@ func TestRelayedTransactionInMultiShardEnvironmentWithNormalTx(t *testing.T) {
 	checkPlayerBalances(t, nodes, players)
 }
 
+func TestRelayedTransactionInMultiShardEnvironmentWithTamperedInnerTxSignature(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	nodes, idxProposers, players, relayer, advertiser := CreateGeneralSetupForRelayTxTest()
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	sendValue := big.NewInt(5)
+	round := uint64(0)
+	nonce := uint64(0)
+	round = integrationTests.IncrementAndPrintRound(round)
+	nonce++
+
+	receiverAddress1 := []byte("12345678901234567890123456789012")
+	receiverAddress2 := []byte("12345678901234567890123456789011")
+
+	signedPlayer := players[0]
+	tamperedPlayer := players[1]
+
+	_ = CreateAndSendRelayedAndUserTx(nodes, relayer, signedPlayer, receiverAddress1, sendValue, integrationTests.MinTxGasLimit, []byte(""))
+
+	relayerNonce := relayer.Nonce
+	relayerBalance := big.NewInt(0).Set(relayer.Balance)
+	createAndSendRelayedAndTamperedUserTx(nodes, relayer, tamperedPlayer, receiverAddress2, sendValue, integrationTests.MinTxGasLimit, []byte(""))
+
+	// the relayed transaction with the tampered inner transaction is dropped by the interceptors
+	relayer.Nonce = relayerNonce
+	relayer.Balance = relayerBalance
+	tamperedPlayer.Nonce--
+
+	roundToPropagateMultiShard := int64(20)
+	for i := int64(0); i <= roundToPropagateMultiShard; i++ {
+		round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+		integrationTests.AddSelfNotarizedHeaderByMetachain(nodes)
+	}
+
+	time.Sleep(time.Second)
+	receiver1 := GetUserAccount(nodes, receiverAddress1)
+	require.NotNil(t, receiver1)
+	assert.Equal(t, 0, receiver1.GetBalance().Cmp(sendValue))
+
+	receiver2 := GetUserAccount(nodes, receiverAddress2)
+	assert.Nil(t, receiver2)
+
+	tamperedPlayerAccount := GetUserAccount(nodes, tamperedPlayer.Address)
+	if tamperedPlayerAccount != nil {
+		assert.Equal(t, tamperedPlayer.Nonce, tamperedPlayerAccount.GetNonce())
+	}
+
+	checkPlayerBalances(t, nodes, []*integrationTests.TestWalletAccount{signedPlayer, relayer})
+}
+
+func TestRelayedTransactionInMultiShardEnvironmentWithRelayerUnderpricingTheInnerTx(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	nodes, idxProposers, players, relayer, advertiser := CreateGeneralSetupForRelayTxTest()
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	sendValue := big.NewInt(5)
+	round := uint64(0)
+	nonce := uint64(0)
+	round = integrationTests.IncrementAndPrintRound(round)
+	nonce++
+
+	receiverAddress1 := []byte("12345678901234567890123456789012")
+	receiverAddress2 := []byte("12345678901234567890123456789011")
+
+	pricedPlayer := players[0]
+	overpricedPlayer := players[1]
+
+	_ = CreateAndSendRelayedAndUserTx(nodes, relayer, pricedPlayer, receiverAddress1, sendValue, integrationTests.MinTxGasLimit, []byte(""))
+	createAndSendRelayedAndOverpricedUserTx(nodes, relayer, overpricedPlayer, receiverAddress2, sendValue, integrationTests.MinTxGasLimit, []byte(""))
+
+	// the relayer pays the fee of the relayed transaction with the overpriced inner transaction but keeps its value
+	relayer.Balance.Add(relayer.Balance, sendValue)
+	overpricedPlayer.Nonce--
+
+	roundToPropagateMultiShard := int64(20)
+	for i := int64(0); i <= roundToPropagateMultiShard; i++ {
+		round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, idxProposers, round, nonce)
+		integrationTests.AddSelfNotarizedHeaderByMetachain(nodes)
+	}
+
+	time.Sleep(time.Second)
+	receiver1 := GetUserAccount(nodes, receiverAddress1)
+	require.NotNil(t, receiver1)
+	assert.Equal(t, 0, receiver1.GetBalance().Cmp(sendValue))
+
+	receiver2 := GetUserAccount(nodes, receiverAddress2)
+	assert.Nil(t, receiver2)
+
+	overpricedPlayerAccount := GetUserAccount(nodes, overpricedPlayer.Address)
+	if overpricedPlayerAccount != nil {
+		assert.Equal(t, overpricedPlayer.Nonce, overpricedPlayerAccount.GetNonce())
+	}
+
+	checkPlayerBalances(t, nodes, []*integrationTests.TestWalletAccount{pricedPlayer, relayer})
+}
+
 func TestRelayedTransactionInMultiShardEnvironmentWithSmartContractTX(t *testing.T) {
 	if testing.Short() {
 		t.Skip("this is not a short test")
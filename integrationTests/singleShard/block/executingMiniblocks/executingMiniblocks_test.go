@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 	"time"
 
+	arwenConfig "github.com/ElrondNetwork/arwen-wasm-vm/config"
 	logger "github.com/ElrondNetwork/elrond-go-logger"
 	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
 	"github.com/ElrondNetwork/elrond-go/crypto"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/block"
@@ -17,6 +20,7 @@ import (
 	testBlock "github.com/ElrondNetwork/elrond-go/integrationTests/singleShard/block"
 	"github.com/ElrondNetwork/elrond-go/process"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestShardShouldNotProposeAndExecuteTwoBlocksInSameRound tests that a shard can not continue building on a
@@ -138,6 +142,358 @@ func TestShardShouldProposeBlockContainingInvalidTransactions(t *testing.T) {
 	testStateOnNodes(t, nodes, idxProposer, hashes)
 }
 
+func TestShardShouldReportTransactionsExecutionResultsWhenProcessingBlock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	maxShards := uint32(1)
+	numOfNodes := 2
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+	advertiserAddr := integrationTests.GetConnectableAddress(advertiser)
+
+	nodes := make([]*integrationTests.TestProcessorNode, numOfNodes)
+	for i := 0; i < numOfNodes; i++ {
+		nodes[i] = integrationTests.NewTestProcessorNode(maxShards, 0, 0, advertiserAddr)
+	}
+
+	idxProposer := 0
+	proposer := nodes[idxProposer]
+	validator := nodes[1]
+
+	mutResults := sync.Mutex{}
+	reportedResults := make(map[string]*process.TransactionExecutionResult)
+	validator.OnTransactionsProcessed = func(results []*process.TransactionExecutionResult) {
+		mutResults.Lock()
+		for _, result := range results {
+			reportedResults[string(result.TxHash)] = result
+		}
+		mutResults.Unlock()
+	}
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	for _, n := range nodes {
+		_ = n.Messenger.Bootstrap()
+	}
+
+	fmt.Println("Delaying for nodes p2p bootstrap...")
+	time.Sleep(integrationTests.P2pBootstrapDelay)
+
+	round := uint64(0)
+	nonce := uint64(1)
+	round = integrationTests.IncrementAndPrintRound(round)
+
+	transferValue := uint64(1000000)
+	mintAllNodes(nodes, transferValue)
+
+	txs, hashes := generateTransferTxs(transferValue, proposer.OwnAccount.SkTxSign, validator.OwnAccount.PkTxSign)
+	addTxsInDataPool(proposer, txs, hashes)
+
+	_, _ = integrationTests.ProposeAndSyncOneBlock(t, nodes, []int{idxProposer}, round, nonce)
+
+	testSameBlockHeight(t, nodes, idxProposer, nonce)
+
+	txValidIdx := 0
+	txInvalidIdx := 1
+
+	mutResults.Lock()
+	defer mutResults.Unlock()
+
+	assert.Equal(t, 2, len(reportedResults))
+
+	validTxResult, ok := reportedResults[string(hashes[txValidIdx])]
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, vmcommon.Ok, validTxResult.ReturnCode)
+		assert.True(t, validTxResult.GasUsed > 0)
+	}
+
+	invalidTxResult, ok := reportedResults[string(hashes[txInvalidIdx])]
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, vmcommon.UserError, invalidTxResult.ReturnCode)
+	}
+}
+
+func TestShardShouldNotProposeTransactionsBelowMinGasPriceForInclusion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	maxShards := uint32(1)
+	numOfNodes := 2
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+	advertiserAddr := integrationTests.GetConnectableAddress(advertiser)
+
+	nodes := make([]*integrationTests.TestProcessorNode, numOfNodes)
+	for i := 0; i < numOfNodes; i++ {
+		nodes[i] = integrationTests.NewTestProcessorNode(maxShards, 0, 0, advertiserAddr)
+	}
+
+	idxProposer := 0
+	proposer := nodes[idxProposer]
+	otherNode := nodes[1]
+
+	minGasPriceForInclusion := integrationTests.MinTxGasPrice + 1
+	proposer.MinGasPriceForInclusion = minGasPriceForInclusion
+	proposer.InitializeProcessors(arwenConfig.MakeGasMapForTests())
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	for _, n := range nodes {
+		_ = n.Messenger.Bootstrap()
+	}
+
+	fmt.Println("Delaying for nodes p2p bootstrap...")
+	time.Sleep(integrationTests.P2pBootstrapDelay)
+
+	round := uint64(0)
+	nonce := uint64(1)
+	round = integrationTests.IncrementAndPrintRound(round)
+
+	transferValue := uint64(1000000)
+	integrationTests.MintAllNodes(nodes, big.NewInt(0).SetUint64(transferValue+integrationTests.MinTxGasLimit*minGasPriceForInclusion))
+
+	txBelowMinGasPrice := integrationTests.GenerateTransferTx(
+		0,
+		proposer.OwnAccount.SkTxSign,
+		otherNode.OwnAccount.PkTxSign,
+		big.NewInt(0).SetUint64(transferValue),
+		integrationTests.MinTxGasPrice,
+		integrationTests.MinTxGasLimit,
+		integrationTests.ChainID,
+		integrationTests.MinTransactionVersion,
+	)
+	txAtMinGasPrice := integrationTests.GenerateTransferTx(
+		0,
+		otherNode.OwnAccount.SkTxSign,
+		proposer.OwnAccount.PkTxSign,
+		big.NewInt(0).SetUint64(transferValue),
+		minGasPriceForInclusion,
+		integrationTests.MinTxGasLimit,
+		integrationTests.ChainID,
+		integrationTests.MinTransactionVersion,
+	)
+	txs := []data.TransactionHandler{txBelowMinGasPrice, txAtMinGasPrice}
+	hashes := make([][]byte, len(txs))
+	for i := range txs {
+		hashes[i], _ = core.CalculateHash(integrationTests.TestMarshalizer, integrationTests.TestHasher, txs[i])
+	}
+	addTxsInDataPool(proposer, txs, hashes)
+
+	bodyHandler, _, _ := proposer.ProposeBlock(round, nonce)
+	body, ok := bodyHandler.(*block.Body)
+	require.True(t, ok)
+
+	includedTxs := make(map[string]struct{})
+	for _, miniBlock := range body.MiniBlocks {
+		for _, txHash := range miniBlock.TxHashes {
+			includedTxs[string(txHash)] = struct{}{}
+		}
+	}
+
+	_, isBelowMinGasPriceIncluded := includedTxs[string(hashes[0])]
+	assert.False(t, isBelowMinGasPriceIncluded)
+	_, isAtMinGasPriceIncluded := includedTxs[string(hashes[1])]
+	assert.True(t, isAtMinGasPriceIncluded)
+}
+
+func TestShardShouldNotProposeTransactionsWithDataLargerThanMaxTxDataSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	maxShards := uint32(1)
+	numOfNodes := 2
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+	advertiserAddr := integrationTests.GetConnectableAddress(advertiser)
+
+	nodes := make([]*integrationTests.TestProcessorNode, numOfNodes)
+	for i := 0; i < numOfNodes; i++ {
+		nodes[i] = integrationTests.NewTestProcessorNode(maxShards, 0, 0, advertiserAddr)
+	}
+
+	idxProposer := 0
+	proposer := nodes[idxProposer]
+	otherNode := nodes[1]
+
+	maxTxDataSize := uint64(10)
+	proposer.MaxTxDataSize = maxTxDataSize
+	proposer.InitializeProcessors(arwenConfig.MakeGasMapForTests())
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	for _, n := range nodes {
+		_ = n.Messenger.Bootstrap()
+	}
+
+	fmt.Println("Delaying for nodes p2p bootstrap...")
+	time.Sleep(integrationTests.P2pBootstrapDelay)
+
+	round := uint64(0)
+	nonce := uint64(1)
+	round = integrationTests.IncrementAndPrintRound(round)
+
+	transferValue := uint64(1000000)
+	oversizedData := bytes.Repeat([]byte("a"), int(maxTxDataSize)+1)
+	gasLimitWithData := integrationTests.MinTxGasLimit + uint64(len(oversizedData))
+	integrationTests.MintAllNodes(nodes, big.NewInt(0).SetUint64(transferValue+gasLimitWithData*integrationTests.MinTxGasPrice))
+
+	txWithOversizedData := integrationTests.GenerateTransferTx(
+		0,
+		proposer.OwnAccount.SkTxSign,
+		otherNode.OwnAccount.PkTxSign,
+		big.NewInt(0).SetUint64(transferValue),
+		integrationTests.MinTxGasPrice,
+		gasLimitWithData,
+		integrationTests.ChainID,
+		integrationTests.MinTransactionVersion,
+	)
+	txWithOversizedData.Data = oversizedData
+	txWithoutData := integrationTests.GenerateTransferTx(
+		0,
+		otherNode.OwnAccount.SkTxSign,
+		proposer.OwnAccount.PkTxSign,
+		big.NewInt(0).SetUint64(transferValue),
+		integrationTests.MinTxGasPrice,
+		integrationTests.MinTxGasLimit,
+		integrationTests.ChainID,
+		integrationTests.MinTransactionVersion,
+	)
+	txs := []data.TransactionHandler{txWithOversizedData, txWithoutData}
+	hashes := make([][]byte, len(txs))
+	for i := range txs {
+		hashes[i], _ = core.CalculateHash(integrationTests.TestMarshalizer, integrationTests.TestHasher, txs[i])
+	}
+	addTxsInDataPool(proposer, txs, hashes)
+
+	bodyHandler, _, _ := proposer.ProposeBlock(round, nonce)
+	body, ok := bodyHandler.(*block.Body)
+	require.True(t, ok)
+
+	includedTxs := make(map[string]struct{})
+	for _, miniBlock := range body.MiniBlocks {
+		for _, txHash := range miniBlock.TxHashes {
+			includedTxs[string(txHash)] = struct{}{}
+		}
+	}
+
+	_, isOversizedDataIncluded := includedTxs[string(hashes[0])]
+	assert.False(t, isOversizedDataIncluded)
+	_, isWithoutDataIncluded := includedTxs[string(hashes[1])]
+	assert.True(t, isWithoutDataIncluded)
+
+	_, isOversizedDataInPool := proposer.DataPool.Transactions().SearchFirstData(hashes[0])
+	assert.True(t, isOversizedDataInPool)
+}
+
+func TestShardShouldReportTheAccountsTouchedByACommittedBlock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	maxShards := uint32(1)
+	numOfNodes := 3
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+	advertiserAddr := integrationTests.GetConnectableAddress(advertiser)
+
+	nodes := make([]*integrationTests.TestProcessorNode, numOfNodes)
+	for i := 0; i < numOfNodes; i++ {
+		nodes[i] = integrationTests.NewTestProcessorNode(maxShards, 0, 0, advertiserAddr)
+	}
+
+	idxProposer := 0
+	proposer := nodes[idxProposer]
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	for _, n := range nodes {
+		_ = n.Messenger.Bootstrap()
+	}
+
+	fmt.Println("Delaying for nodes p2p bootstrap...")
+	time.Sleep(integrationTests.P2pBootstrapDelay)
+
+	round := uint64(0)
+	nonce := uint64(1)
+	round = integrationTests.IncrementAndPrintRound(round)
+
+	transferValue := uint64(1000000)
+	integrationTests.MintAllNodes(nodes, big.NewInt(0).SetUint64(2*(transferValue+integrationTests.MinTxGasLimit*integrationTests.MinTxGasPrice)))
+
+	generateTransfer := func(txNonce uint64, sender *integrationTests.TestProcessorNode, receiver *integrationTests.TestProcessorNode) data.TransactionHandler {
+		return integrationTests.GenerateTransferTx(
+			txNonce,
+			sender.OwnAccount.SkTxSign,
+			receiver.OwnAccount.PkTxSign,
+			big.NewInt(0).SetUint64(transferValue),
+			integrationTests.MinTxGasPrice,
+			integrationTests.MinTxGasLimit,
+			integrationTests.ChainID,
+			integrationTests.MinTransactionVersion,
+		)
+	}
+	txs := []data.TransactionHandler{
+		generateTransfer(0, nodes[0], nodes[1]),
+		generateTransfer(1, nodes[0], nodes[2]),
+		generateTransfer(0, nodes[1], nodes[2]),
+	}
+	hashes := make([][]byte, len(txs))
+	for i := range txs {
+		hashes[i], _ = core.CalculateHash(integrationTests.TestMarshalizer, integrationTests.TestHasher, txs[i])
+	}
+	addTxsInDataPool(proposer, txs, hashes)
+
+	integrationTests.UpdateRound(nodes, round)
+	bodyHandler, header, _ := proposer.ProposeBlock(round, nonce)
+	body, ok := bodyHandler.(*block.Body)
+	require.True(t, ok)
+	proposer.CommitBlock(body, header)
+	require.Equal(t, nonce, proposer.BlockChain.GetCurrentBlockHeader().GetNonce())
+
+	reader, ok := proposer.BlockProcessor.(process.ShardProcessorReader)
+	require.True(t, ok)
+
+	touchedAccounts, err := reader.TouchedAccounts(body)
+	require.Nil(t, err)
+
+	expectedTouchedAccounts := make(map[string]struct{})
+	for _, n := range nodes {
+		expectedTouchedAccounts[string(n.OwnAccount.Address)] = struct{}{}
+	}
+	actualTouchedAccounts := make(map[string]struct{})
+	for _, address := range touchedAccounts {
+		actualTouchedAccounts[string(address)] = struct{}{}
+	}
+	assert.Equal(t, len(expectedTouchedAccounts), len(touchedAccounts))
+	assert.Equal(t, expectedTouchedAccounts, actualTouchedAccounts)
+}
+
 func mintAllNodes(nodes []*integrationTests.TestProcessorNode, transferValue uint64) {
 	balanceFirstTransaction := transferValue + integrationTests.MinTxGasLimit*integrationTests.MinTxGasPrice
 	balanceSecondTransaction := integrationTests.MinTxGasLimit * integrationTests.MinTxGasPrice
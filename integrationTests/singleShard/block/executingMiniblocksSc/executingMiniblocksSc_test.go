@@ -8,11 +8,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/data/state"
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
 	"github.com/ElrondNetwork/elrond-go/integrationTests"
 	"github.com/ElrondNetwork/elrond-go/integrationTests/singleShard/block"
 	"github.com/ElrondNetwork/elrond-go/process/factory"
+	"github.com/ElrondNetwork/elrond-go/statusHandler"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShouldProcessMultipleERC20ContractsInSingleShard(t *testing.T) {
@@ -94,6 +98,87 @@ func TestShouldProcessMultipleERC20ContractsInSingleShard(t *testing.T) {
 	time.Sleep(1 * time.Second)
 }
 
+func TestShouldSetTheGasRefundedPerBlockMetricOnCommittingASmartContractCall(t *testing.T) {
+	if testing.Short() {
+		t.Skip("this is not a short test")
+	}
+
+	scCode, err := ioutil.ReadFile("../../../vm/arwen/testdata/erc20-c-03/wrc20_arwen.wasm")
+	assert.Nil(t, err)
+
+	maxShards := uint32(1)
+	numOfNodes := 2
+	advertiser := integrationTests.CreateMessengerWithKadDht("")
+	_ = advertiser.Bootstrap()
+	advertiserAddr := integrationTests.GetConnectableAddress(advertiser)
+
+	nodes := make([]*integrationTests.TestProcessorNode, numOfNodes)
+	for i := 0; i < numOfNodes; i++ {
+		nodes[i] = integrationTests.NewTestProcessorNode(
+			maxShards,
+			0,
+			0,
+			advertiserAddr,
+		)
+	}
+
+	idxProposer := 0
+	player := integrationTests.CreateTestWalletAccount(nodes[idxProposer].ShardCoordinator, 0)
+
+	defer func() {
+		_ = advertiser.Close()
+		for _, n := range nodes {
+			_ = n.Messenger.Close()
+		}
+	}()
+
+	for _, n := range nodes {
+		_ = n.Messenger.Bootstrap()
+	}
+
+	fmt.Println("Delaying for nodes p2p bootstrap...")
+	time.Sleep(integrationTests.P2pBootstrapDelay)
+
+	round := uint64(0)
+	nonce := uint64(0)
+	round = integrationTests.IncrementAndPrintRound(round)
+	nonce++
+
+	proposer := nodes[idxProposer]
+	scAddress, _ := proposer.BlockchainHook.NewAddress(proposer.OwnAccount.Address, proposer.OwnAccount.Nonce, factory.ArwenVirtualMachine)
+
+	initialVal := big.NewInt(100000000000)
+	integrationTests.MintAllNodes(nodes, initialVal)
+
+	integrationTests.DeployScTx(nodes, idxProposer, hex.EncodeToString(scCode), factory.ArwenVirtualMachine, "001000000000")
+	time.Sleep(block.StepDelay)
+	round, nonce = integrationTests.ProposeAndSyncOneBlock(t, nodes, []int{idxProposer}, round, nonce)
+
+	statusMetrics := statusHandler.NewStatusMetrics()
+	blockProcessor, ok := proposer.BlockProcessor.(interface {
+		SetAppStatusHandler(ash core.AppStatusHandler) error
+	})
+	require.True(t, ok)
+	err = blockProcessor.SetAppStatusHandler(statusMetrics)
+	require.Nil(t, err)
+
+	owner := proposer.OwnAccount
+	balanceBefore := getBalance(proposer, owner.Address)
+	gasLimit := uint64(30000)
+	tx := createAndSendTx(proposer, owner, big.NewInt(0), gasLimit, scAddress,
+		[]byte("transferToken@"+hex.EncodeToString(player.Address)+"@"+hex.EncodeToString(big.NewInt(100).Bytes())))
+	time.Sleep(block.StepDelay)
+	_, _ = integrationTests.ProposeAndSyncOneBlock(t, nodes, []int{idxProposer}, round, nonce)
+
+	gasRefunded, ok := statusMetrics.StatusMetricsMapWithoutP2P()[core.MetricGasRefundedPerBlock].(uint64)
+	require.True(t, ok)
+	assert.True(t, gasRefunded > 0)
+
+	balanceAfter := getBalance(proposer, owner.Address)
+	expectedFee := proposer.EconomicsData.ComputeTxFeeBasedOnGasUsed(tx, gasLimit-gasRefunded)
+	assert.Equal(t, expectedFee, big.NewInt(0).Sub(balanceBefore, balanceAfter))
+}
+
 func playersDoTopUp(
 	node *integrationTests.TestProcessorNode,
 	players []*integrationTests.TestWalletAccount,
@@ -126,7 +211,7 @@ func createAndSendTx(
 	gasLimit uint64,
 	rcvAddress []byte,
 	txData []byte,
-) {
+) *transaction.Transaction {
 	tx := &transaction.Transaction{
 		Nonce:    player.Nonce,
 		Value:    txValue,
@@ -135,11 +220,21 @@ func createAndSendTx(
 		Data:     txData,
 		GasPrice: node.EconomicsData.GetMinGasPrice(),
 		GasLimit: gasLimit,
+		ChainID:  integrationTests.ChainID,
+		Version:  integrationTests.MinTransactionVersion,
 	}
 
-	txBuff, _ := integrationTests.TestMarshalizer.Marshal(tx)
+	txBuff, _ := tx.GetDataForSigning(integrationTests.TestAddressPubkeyConverter, integrationTests.TestTxSignMarshalizer)
 	tx.Signature, _ = player.SingleSigner.Sign(player.SkTxSign, txBuff)
 
 	_, _ = node.SendTransaction(tx)
 	player.Nonce++
+
+	return tx
+}
+
+func getBalance(node *integrationTests.TestProcessorNode, address []byte) *big.Int {
+	account, _ := node.AccntState.GetExistingAccount(address)
+
+	return account.(state.UserAccountHandler).GetBalance()
 }
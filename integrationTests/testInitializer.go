@@ -21,6 +21,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/accumulator"
 	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/forking"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
 	"github.com/ElrondNetwork/elrond-go/crypto"
 	"github.com/ElrondNetwork/elrond-go/crypto/signing"
 	"github.com/ElrondNetwork/elrond-go/crypto/signing/ed25519"
@@ -895,6 +896,10 @@ func CreateSimpleTxProcessor(accnts state.AccountsAdapter) process.TransactionPr
 		BadTxForwarder:   &mock.IntermediateTransactionHandlerMock{},
 		ArgsParser:       smartContract.NewArgumentParser(),
 		ScrForwarder:     &mock.IntermediateTransactionHandlerMock{},
+		KeyGen:           TestKeyGenForAccounts,
+		SingleSigner:     TestSingleSigner,
+		TxSignHasher:     TestTxSignHasher,
+		TxVersionChecker: versioning.NewTxVersionChecker(MinTransactionVersion),
 		EpochNotifier:    forking.NewGenericEpochNotifier(),
 	}
 	txProcessor, _ := txProc.NewTxProcessor(argsNewTxProcessor)
@@ -1148,6 +1153,7 @@ func CreateHeaderIntegrityVerifier() process.HeaderIntegrityVerifier {
 		},
 		"default",
 		testscommon.NewCacherMock(),
+		0,
 	)
 
 	return headerVersioning
@@ -28,6 +28,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/crypto/peerSignatureHandler"
 	"github.com/ElrondNetwork/elrond-go/crypto/signing"
 	"github.com/ElrondNetwork/elrond-go/crypto/signing/ed25519"
+	ed25519SingleSig "github.com/ElrondNetwork/elrond-go/crypto/signing/ed25519/singlesig"
 	"github.com/ElrondNetwork/elrond-go/crypto/signing/mcl"
 	mclsig "github.com/ElrondNetwork/elrond-go/crypto/signing/mcl/singlesig"
 	"github.com/ElrondNetwork/elrond-go/data"
@@ -119,6 +120,9 @@ var TestMultiSig = mock.NewMultiSigner(1)
 // TestKeyGenForAccounts represents a mock key generator for balances
 var TestKeyGenForAccounts = signing.NewKeyGenerator(ed25519.NewEd25519())
 
+// TestSingleSigner represents the single signer used for transactions
+var TestSingleSigner = &ed25519SingleSig.Ed25519Signer{}
+
 // TestUint64Converter represents an uint64 to byte slice converter
 var TestUint64Converter = uint64ByteSlice.NewBigEndianConverter()
 
@@ -287,6 +291,10 @@ type TestProcessorNode struct {
 	PenalizedTooMuchGasEnableEpoch    uint32
 	BlockGasAndFeesReCheckEnableEpoch uint32
 	UseValidVmBlsSigVerifier          bool
+	OnTransactionsProcessed           func(results []*process.TransactionExecutionResult)
+	MinGasPriceForInclusion           uint64
+	MaxTxDataSize                     uint64
+	MetaBlockFinality                 int
 }
 
 // CreatePkBytes creates 'numShards' public key-like byte slices
@@ -1330,6 +1338,10 @@ func (tpn *TestProcessorNode) initInnerProcessors(gasMap map[string]map[string]u
 		BadTxForwarder:                 badBlocksHandler,
 		ArgsParser:                     tpn.ArgsParser,
 		ScrForwarder:                   tpn.ScrForwarder,
+		KeyGen:                         TestKeyGenForAccounts,
+		SingleSigner:                   TestSingleSigner,
+		TxSignHasher:                   TestTxSignHasher,
+		TxVersionChecker:               versioning.NewTxVersionChecker(tpn.MinTransactionVersion),
 		EpochNotifier:                  tpn.EpochNotifier,
 		RelayedTxEnableEpoch:           tpn.RelayedTxEnableEpoch,
 		PenalizedTooMuchGasEnableEpoch: tpn.PenalizedTooMuchGasEnableEpoch,
@@ -1898,8 +1910,17 @@ func (tpn *TestProcessorNode) initBlockProcessor(stateCheckpointModulus uint) {
 		argumentsBase.EpochStartTrigger = tpn.EpochStartTrigger
 		argumentsBase.BlockChainHook = tpn.BlockchainHook
 		argumentsBase.TxCoordinator = tpn.TxCoordinator
+		blockCreationPolicy, _ := block.NewBlockCreationPolicy(block.ArgsBlockCreationPolicy{
+			MinGasPriceForInclusion: tpn.MinGasPriceForInclusion,
+			MaxTxDataSize:           tpn.MaxTxDataSize,
+			ProduceEmptyBlocks:      true,
+		})
 		arguments := block.ArgShardProcessor{
-			ArgBaseProcessor: argumentsBase,
+			ArgBaseProcessor:               argumentsBase,
+			IncludeEmptyAttestedMetaBlocks: true,
+			OnTransactionsProcessed:        tpn.notifyTransactionsProcessed,
+			BlockCreationPolicy:            blockCreationPolicy,
+			MetaBlockFinality:              tpn.MetaBlockFinality,
 		}
 
 		tpn.BlockProcessor, err = block.NewShardProcessor(arguments)
@@ -1910,6 +1931,14 @@ func (tpn *TestProcessorNode) initBlockProcessor(stateCheckpointModulus uint) {
 	}
 }
 
+func (tpn *TestProcessorNode) notifyTransactionsProcessed(results []*process.TransactionExecutionResult) {
+	if tpn.OnTransactionsProcessed == nil {
+		return
+	}
+
+	tpn.OnTransactionsProcessed(results)
+}
+
 func (tpn *TestProcessorNode) setGenesisBlock() {
 	genesisBlock := tpn.GenesisBlocks[tpn.ShardCoordinator.SelfId()]
 	_ = tpn.BlockChain.SetGenesisHeader(genesisBlock)
@@ -447,6 +447,7 @@ func createHeaderIntegrityVerifier() process.HeaderIntegrityVerifier {
 		},
 		"default",
 		testscommon.NewCacherMock(),
+		0,
 	)
 
 	return headerVersioning
@@ -166,6 +166,7 @@ func createFacadeComponents(tpn *TestProcessorNode) (nodeFacade.ApiResolver, nod
 		IntermmediateProcContainer: tpn.InterimProcContainer,
 		AddressPubKeyConverter:     TestAddressPubkeyConverter,
 		ShardCoordinator:           tpn.ShardCoordinator,
+		RelayedTxSimulator:         txsimulator.NewDisabledRelayedTxSimulator(),
 	}
 
 	txSimulator, err := txsimulator.NewTransactionSimulator(argSimulator)
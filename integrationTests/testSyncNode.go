@@ -214,8 +214,13 @@ func (tpn *TestProcessorNode) initBlockProcessorWithSync() {
 		argumentsBase.ForkDetector = tpn.ForkDetector
 		argumentsBase.BlockChainHook = tpn.BlockchainHook
 		argumentsBase.TxCoordinator = tpn.TxCoordinator
+		blockCreationPolicy, _ := block.NewBlockCreationPolicy(block.ArgsBlockCreationPolicy{
+			ProduceEmptyBlocks: true,
+		})
 		arguments := block.ArgShardProcessor{
-			ArgBaseProcessor: argumentsBase,
+			ArgBaseProcessor:               argumentsBase,
+			IncludeEmptyAttestedMetaBlocks: true,
+			BlockCreationPolicy:            blockCreationPolicy,
 		}
 
 		tpn.BlockProcessor, err = block.NewShardProcessor(arguments)
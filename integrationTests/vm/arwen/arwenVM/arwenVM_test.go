@@ -12,6 +12,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/forking"
 	"github.com/ElrondNetwork/elrond-go/core/parsers"
 	"github.com/ElrondNetwork/elrond-go/core/pubkeyConverter"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
 	"github.com/ElrondNetwork/elrond-go/hashing/sha256"
@@ -470,6 +471,10 @@ func TestExecuteTransactionAndTimeToProcessChange(t *testing.T) {
 		BadTxForwarder:   &mock.IntermediateTransactionHandlerMock{},
 		ArgsParser:       smartContract.NewArgumentParser(),
 		ScrForwarder:     &mock.IntermediateTransactionHandlerMock{},
+		KeyGen:           &mock.KeyGenMock{},
+		SingleSigner:     &mock.SignerMock{},
+		TxSignHasher:     testHasher,
+		TxVersionChecker: versioning.NewTxVersionChecker(0),
 		EpochNotifier:    forking.NewGenericEpochNotifier(),
 	}
 	txProc, _ := processTransaction.NewTxProcessor(argsNewTxProcessor)
@@ -18,6 +18,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/forking"
 	"github.com/ElrondNetwork/elrond-go/core/parsers"
 	"github.com/ElrondNetwork/elrond-go/core/pubkeyConverter"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
 	"github.com/ElrondNetwork/elrond-go/data/block"
 	"github.com/ElrondNetwork/elrond-go/data/rewardTx"
@@ -297,6 +298,10 @@ func (context *TestContext) initTxProcessorWithOneSCExecutorWithVMs() {
 		BadTxForwarder:                 &mock.IntermediateTransactionHandlerMock{},
 		ArgsParser:                     smartContract.NewArgumentParser(),
 		ScrForwarder:                   &mock.IntermediateTransactionHandlerMock{},
+		KeyGen:                         &mock.KeyGenMock{},
+		SingleSigner:                   &mock.SignerMock{},
+		TxSignHasher:                   hasher,
+		TxVersionChecker:               versioning.NewTxVersionChecker(0),
 		RelayedTxEnableEpoch:           0,
 		PenalizedTooMuchGasEnableEpoch: 0,
 		EpochNotifier:                  forking.NewGenericEpochNotifier(),
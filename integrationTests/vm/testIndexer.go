@@ -44,7 +44,7 @@ func CreateTestIndexer(
 		mutex:       sync.RWMutex{},
 	}
 
-	dispatcher, err := indexer.NewDataDispatcher(100)
+	dispatcher, err := indexer.NewDataDispatcher(100, time.Second, false)
 	require.Nil(t, err)
 
 	dispatcher.StartIndexData()
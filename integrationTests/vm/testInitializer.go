@@ -3,6 +3,7 @@
 package vm
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -17,7 +18,9 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/forking"
 	"github.com/ElrondNetwork/elrond-go/core/parsers"
 	"github.com/ElrondNetwork/elrond-go/core/pubkeyConverter"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
+	"github.com/ElrondNetwork/elrond-go/crypto"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/state"
 	dataTransaction "github.com/ElrondNetwork/elrond-go/data/transaction"
@@ -55,11 +58,19 @@ var testMarshalizer = &marshal.GogoProtoMarshalizer{}
 var testHasher = sha256.Sha256{}
 var oneShardCoordinator = mock.NewMultiShardsCoordinatorMock(2)
 var pubkeyConv, _ = pubkeyConverter.NewHexPubkeyConverter(32)
+var testSignMarshalizer = &marshal.JsonMarshalizer{}
+var testSingleSigner = &mock.SignerMock{VerifyStub: verifyTestSignature}
+
+var errInvalidTestSignature = errors.New("invalid test signature")
 
 var log = logger.GetOrCreate("integrationtests")
 
 const maxTrieLevelInMemory = uint(5)
 
+// the inner transactions of the relayed transactions used by the VM tests are not signed, so the test contexts keep
+// the inner transaction signature check disabled, unless created with CreatePreparedTxProcessorWithVMsAndInnerTxSignatureCheck
+const unreachableEpoch = uint32(math.MaxUint32)
+
 // ArgEnableEpoch will specify the enable epoch values for certain flags
 type ArgEnableEpoch struct {
 	PenalizedTooMuchGasEnableEpoch uint32
@@ -80,6 +91,9 @@ type VMTestContext struct {
 	ShardCoordinator sharding.Coordinator
 	ScForwarder      process.IntermediateTransactionHandler
 	EconomicsData    process.EconomicsDataHandler
+	StateTrie        data.Trie
+	ScProcArgs       smartContract.ArgsNewSmartContractProcessor
+	TxProcArgs       transaction.ArgsNewTxProcessor
 }
 
 // Close -
@@ -146,6 +160,13 @@ func CreateMemUnit() storage.Storer {
 
 // CreateInMemoryShardAccountsDB -
 func CreateInMemoryShardAccountsDB() *state.AccountsDB {
+	adb, _ := state.NewAccountsDB(CreateInMemoryShardTrie(), testHasher, &marshal.GogoProtoMarshalizer{}, &accountFactory{})
+
+	return adb
+}
+
+// CreateInMemoryShardTrie -
+func CreateInMemoryShardTrie() data.Trie {
 	marsh := &marshal.GogoProtoMarshalizer{}
 	store := CreateMemUnit()
 	ewl, _ := evictionWaitingList.NewEvictionWaitingList(100, memorydb.New(), marsh)
@@ -170,9 +191,8 @@ func CreateInMemoryShardAccountsDB() *state.AccountsDB {
 	)
 
 	tr, _ := trie.NewTrie(trieStorage, marsh, testHasher, maxTrieLevelInMemory)
-	adb, _ := state.NewAccountsDB(tr, testHasher, marsh, &accountFactory{})
 
-	return adb
+	return tr
 }
 
 // CreateAccount -
@@ -312,24 +332,29 @@ func CreateTxProcessorWithOneSCExecutorMockVM(
 	scProcessor, _ := smartContract.NewSmartContractProcessor(argsNewSCProcessor)
 
 	argsNewTxProcessor := transaction.ArgsNewTxProcessor{
-		Accounts:                       accnts,
-		Hasher:                         testHasher,
-		PubkeyConv:                     pubkeyConv,
-		Marshalizer:                    testMarshalizer,
-		SignMarshalizer:                testMarshalizer,
-		ShardCoordinator:               oneShardCoordinator,
-		ScProcessor:                    scProcessor,
-		TxFeeHandler:                   &mock.UnsignedTxHandlerMock{},
-		TxTypeHandler:                  txTypeHandler,
-		EconomicsFee:                   economicsData,
-		ReceiptForwarder:               &mock.IntermediateTransactionHandlerMock{},
-		BadTxForwarder:                 &mock.IntermediateTransactionHandlerMock{},
-		ArgsParser:                     smartContract.NewArgumentParser(),
-		ScrForwarder:                   &mock.IntermediateTransactionHandlerMock{},
-		EpochNotifier:                  forking.NewGenericEpochNotifier(),
-		PenalizedTooMuchGasEnableEpoch: argEnableEpoch.PenalizedTooMuchGasEnableEpoch,
-		MetaProtectionEnableEpoch:      argEnableEpoch.MetaProtectionEnableEpoch,
-		RelayedTxEnableEpoch:           argEnableEpoch.RelayedTxEnableEpoch,
+		Accounts:                         accnts,
+		Hasher:                           testHasher,
+		PubkeyConv:                       pubkeyConv,
+		Marshalizer:                      testMarshalizer,
+		SignMarshalizer:                  testMarshalizer,
+		ShardCoordinator:                 oneShardCoordinator,
+		ScProcessor:                      scProcessor,
+		TxFeeHandler:                     &mock.UnsignedTxHandlerMock{},
+		TxTypeHandler:                    txTypeHandler,
+		EconomicsFee:                     economicsData,
+		ReceiptForwarder:                 &mock.IntermediateTransactionHandlerMock{},
+		BadTxForwarder:                   &mock.IntermediateTransactionHandlerMock{},
+		ArgsParser:                       smartContract.NewArgumentParser(),
+		ScrForwarder:                     &mock.IntermediateTransactionHandlerMock{},
+		KeyGen:                           &mock.KeyGenMock{},
+		SingleSigner:                     testSingleSigner,
+		TxSignHasher:                     testHasher,
+		TxVersionChecker:                 versioning.NewTxVersionChecker(0),
+		EpochNotifier:                    forking.NewGenericEpochNotifier(),
+		PenalizedTooMuchGasEnableEpoch:   argEnableEpoch.PenalizedTooMuchGasEnableEpoch,
+		MetaProtectionEnableEpoch:        argEnableEpoch.MetaProtectionEnableEpoch,
+		RelayedTxEnableEpoch:             argEnableEpoch.RelayedTxEnableEpoch,
+		InnerTxSignatureCheckEnableEpoch: unreachableEpoch,
 	}
 
 	return transaction.NewTxProcessor(argsNewTxProcessor)
@@ -431,7 +456,44 @@ func CreateTxProcessorWithOneSCExecutorWithVMs(
 	feeAccumulator process.TransactionFeeHandler,
 	shardCoordinator sharding.Coordinator,
 	argEnableEpoch ArgEnableEpoch,
+	signMarshalizer marshal.Marshalizer,
+	innerTxSignatureCheckEnableEpoch uint32,
 ) (process.TransactionProcessor, *smartContract.TestScProcessor, process.IntermediateTransactionHandler, process.EconomicsDataHandler, error) {
+	argsNewSCProcessor, argsNewTxProcessor, economicsData, err := CreateTxProcessorArgsWithOneSCExecutorWithVMs(
+		accnts,
+		vmContainer,
+		blockChainHook,
+		feeAccumulator,
+		shardCoordinator,
+		argEnableEpoch,
+		signMarshalizer,
+		innerTxSignatureCheckEnableEpoch,
+	)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	txProcessor, testScProcessor, err := createTxProcessorFromArgs(argsNewSCProcessor, argsNewTxProcessor)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	return txProcessor, testScProcessor, argsNewTxProcessor.ScrForwarder, economicsData, nil
+}
+
+// CreateTxProcessorArgsWithOneSCExecutorWithVMs returns the arguments used by CreateTxProcessorWithOneSCExecutorWithVMs
+// to create the smart contract and the transaction processors. The smart contract processor of the returned
+// transaction processor arguments is not set
+func CreateTxProcessorArgsWithOneSCExecutorWithVMs(
+	accnts state.AccountsAdapter,
+	vmContainer process.VirtualMachinesContainer,
+	blockChainHook *hooks.BlockChainHookImpl,
+	feeAccumulator process.TransactionFeeHandler,
+	shardCoordinator sharding.Coordinator,
+	argEnableEpoch ArgEnableEpoch,
+	signMarshalizer marshal.Marshalizer,
+	innerTxSignatureCheckEnableEpoch uint32,
+) (smartContract.ArgsNewSmartContractProcessor, transaction.ArgsNewTxProcessor, process.EconomicsDataHandler, error) {
 	argsTxTypeHandler := coordinator.ArgNewTxTypeHandler{
 		PubkeyConverter:  pubkeyConv,
 		ShardCoordinator: shardCoordinator,
@@ -444,12 +506,12 @@ func CreateTxProcessorWithOneSCExecutorWithVMs(
 	defaults.FillGasMapInternal(gasSchedule, 1)
 	economicsData, err := createEconomicsData(argEnableEpoch.PenalizedTooMuchGasEnableEpoch)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return smartContract.ArgsNewSmartContractProcessor{}, transaction.ArgsNewTxProcessor{}, nil, err
 	}
 
 	gasComp, err := preprocess.NewGasComputation(economicsData, txTypeHandler, forking.NewGenericEpochNotifier(), argEnableEpoch.DeployEnableEpoch)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return smartContract.ArgsNewSmartContractProcessor{}, transaction.ArgsNewTxProcessor{}, nil, err
 	}
 
 	intermediateTxHandler := &mock.IntermediateTransactionHandlerMock{}
@@ -477,38 +539,51 @@ func CreateTxProcessorWithOneSCExecutorWithVMs(
 		BuiltinEnableEpoch:             argEnableEpoch.BuiltinEnableEpoch,
 	}
 
+	argsNewTxProcessor := transaction.ArgsNewTxProcessor{
+		Accounts:                         accnts,
+		Hasher:                           testHasher,
+		PubkeyConv:                       pubkeyConv,
+		Marshalizer:                      testMarshalizer,
+		SignMarshalizer:                  signMarshalizer,
+		ShardCoordinator:                 shardCoordinator,
+		TxFeeHandler:                     feeAccumulator,
+		TxTypeHandler:                    txTypeHandler,
+		EconomicsFee:                     economicsData,
+		ReceiptForwarder:                 intermediateTxHandler,
+		BadTxForwarder:                   intermediateTxHandler,
+		ArgsParser:                       smartContract.NewArgumentParser(),
+		ScrForwarder:                     intermediateTxHandler,
+		KeyGen:                           &mock.KeyGenMock{},
+		SingleSigner:                     testSingleSigner,
+		TxSignHasher:                     testHasher,
+		TxVersionChecker:                 versioning.NewTxVersionChecker(0),
+		EpochNotifier:                    forking.NewGenericEpochNotifier(),
+		PenalizedTooMuchGasEnableEpoch:   argEnableEpoch.PenalizedTooMuchGasEnableEpoch,
+		RelayedTxEnableEpoch:             argEnableEpoch.RelayedTxEnableEpoch,
+		MetaProtectionEnableEpoch:        argEnableEpoch.MetaProtectionEnableEpoch,
+		InnerTxSignatureCheckEnableEpoch: innerTxSignatureCheckEnableEpoch,
+	}
+
+	return argsNewSCProcessor, argsNewTxProcessor, economicsData, nil
+}
+
+func createTxProcessorFromArgs(
+	argsNewSCProcessor smartContract.ArgsNewSmartContractProcessor,
+	argsNewTxProcessor transaction.ArgsNewTxProcessor,
+) (process.TransactionProcessor, *smartContract.TestScProcessor, error) {
 	scProcessor, err := smartContract.NewSmartContractProcessor(argsNewSCProcessor)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, err
 	}
 	testScProcessor := smartContract.NewTestScProcessor(scProcessor)
 
-	argsNewTxProcessor := transaction.ArgsNewTxProcessor{
-		Accounts:                       accnts,
-		Hasher:                         testHasher,
-		PubkeyConv:                     pubkeyConv,
-		Marshalizer:                    testMarshalizer,
-		SignMarshalizer:                testMarshalizer,
-		ShardCoordinator:               shardCoordinator,
-		ScProcessor:                    scProcessor,
-		TxFeeHandler:                   feeAccumulator,
-		TxTypeHandler:                  txTypeHandler,
-		EconomicsFee:                   economicsData,
-		ReceiptForwarder:               intermediateTxHandler,
-		BadTxForwarder:                 intermediateTxHandler,
-		ArgsParser:                     smartContract.NewArgumentParser(),
-		ScrForwarder:                   intermediateTxHandler,
-		EpochNotifier:                  forking.NewGenericEpochNotifier(),
-		PenalizedTooMuchGasEnableEpoch: argEnableEpoch.PenalizedTooMuchGasEnableEpoch,
-		RelayedTxEnableEpoch:           argEnableEpoch.RelayedTxEnableEpoch,
-		MetaProtectionEnableEpoch:      argEnableEpoch.MetaProtectionEnableEpoch,
-	}
+	argsNewTxProcessor.ScProcessor = scProcessor
 	txProcessor, err := transaction.NewTxProcessor(argsNewTxProcessor)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, err
 	}
 
-	return txProcessor, testScProcessor, intermediateTxHandler, economicsData, nil
+	return txProcessor, testScProcessor, nil
 }
 
 // TestDeployedContractContents -
@@ -572,6 +647,8 @@ func CreatePreparedTxProcessorAndAccountsWithVMs(
 		feeAccumulator,
 		oneShardCoordinator,
 		argEnableEpoch,
+		testMarshalizer,
+		unreachableEpoch,
 	)
 	if err != nil {
 		return nil, err
@@ -590,21 +667,45 @@ func CreatePreparedTxProcessorAndAccountsWithVMs(
 
 // CreatePreparedTxProcessorWithVMs -
 func CreatePreparedTxProcessorWithVMs(argEnableEpoch ArgEnableEpoch) (*VMTestContext, error) {
+	return createPreparedTxProcessorWithVMs(argEnableEpoch, testMarshalizer, unreachableEpoch)
+}
+
+// CreatePreparedTxProcessorWithVMsAndInnerTxSignatureCheck creates a test context which verifies the inner
+// transaction signature of the relayed transactions. The inner transactions are marshalled with the sign marshalizer,
+// as on a node, so they should be signed with SignTransaction and
+// marshalled with MarshalInnerTx
+func CreatePreparedTxProcessorWithVMsAndInnerTxSignatureCheck(argEnableEpoch ArgEnableEpoch) (*VMTestContext, error) {
+	return createPreparedTxProcessorWithVMs(argEnableEpoch, testSignMarshalizer, 0)
+}
+
+func createPreparedTxProcessorWithVMs(
+	argEnableEpoch ArgEnableEpoch,
+	signMarshalizer marshal.Marshalizer,
+	innerTxSignatureCheckEnableEpoch uint32,
+) (*VMTestContext, error) {
 	feeAccumulator, _ := postprocess.NewFeeAccumulator()
-	accounts := CreateInMemoryShardAccountsDB()
+	stateTrie := CreateInMemoryShardTrie()
+	accounts, _ := state.NewAccountsDB(stateTrie, testHasher, &marshal.GogoProtoMarshalizer{}, &accountFactory{})
 	vmContainer, blockchainHook := CreateVMAndBlockchainHook(accounts, nil, false, oneShardCoordinator)
-	txProcessor, scProcessor, scForwarder, economicsData, err := CreateTxProcessorWithOneSCExecutorWithVMs(
+	scProcArgs, txProcArgs, economicsData, err := CreateTxProcessorArgsWithOneSCExecutorWithVMs(
 		accounts,
 		vmContainer,
 		blockchainHook,
 		feeAccumulator,
 		oneShardCoordinator,
 		argEnableEpoch,
+		signMarshalizer,
+		innerTxSignatureCheckEnableEpoch,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	txProcessor, scProcessor, err := createTxProcessorFromArgs(scProcArgs, txProcArgs)
+	if err != nil {
+		return nil, err
+	}
+
 	return &VMTestContext{
 		TxProcessor:      txProcessor,
 		ScProcessor:      scProcessor,
@@ -612,9 +713,12 @@ func CreatePreparedTxProcessorWithVMs(argEnableEpoch ArgEnableEpoch) (*VMTestCon
 		BlockchainHook:   blockchainHook,
 		VMContainer:      vmContainer,
 		TxFeeHandler:     feeAccumulator,
-		ScForwarder:      scForwarder,
+		ScForwarder:      txProcArgs.ScrForwarder,
 		ShardCoordinator: oneShardCoordinator,
 		EconomicsData:    economicsData,
+		StateTrie:        stateTrie,
+		ScProcArgs:       scProcArgs,
+		TxProcArgs:       txProcArgs,
 	}, nil
 }
 
@@ -638,6 +742,8 @@ func CreateTxProcessorArwenVMWithGasSchedule(
 		feeAccumulator,
 		oneShardCoordinator,
 		argEnableEpoch,
+		testMarshalizer,
+		unreachableEpoch,
 	)
 	if err != nil {
 		return nil, err
@@ -834,6 +940,37 @@ func CreateTransaction(
 	}
 }
 
+// SignTransaction sets on the given transaction the signature accepted by the single signer of the VM test contexts
+func SignTransaction(tx *dataTransaction.Transaction) {
+	buff, _ := tx.GetDataForSigning(pubkeyConv, testSignMarshalizer)
+	tx.Signature = computeTestSignature(tx.SndAddr, buff)
+}
+
+// MarshalInnerTx marshals the given inner transaction with the sign marshalizer of the VM test contexts which verify
+// the inner transaction signature
+func MarshalInnerTx(tx *dataTransaction.Transaction) []byte {
+	txBytes, _ := testSignMarshalizer.Marshal(tx)
+	return txBytes
+}
+
+// the VM tests use arbitrary addresses instead of generated keys, so the signature is a hash over the sender address
+// and the signed message
+func computeTestSignature(pubKey []byte, msg []byte) []byte {
+	return testHasher.Compute(string(pubKey) + string(msg))
+}
+
+func verifyTestSignature(public crypto.PublicKey, msg []byte, sig []byte) error {
+	pubKey, err := public.ToByteArray()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig, computeTestSignature(pubKey, msg)) {
+		return errInvalidTestSignature
+	}
+
+	return nil
+}
+
 // GetNodeIndex -
 func GetNodeIndex(nodeList []*integrationTests.TestProcessorNode, node *integrationTests.TestProcessorNode) (int, error) {
 	for i := range nodeList {
@@ -859,6 +996,8 @@ func CreatePreparedTxProcessorWithVMsMultiShard(selfShardID uint32, argEnableEpo
 		feeAccumulator,
 		shardCoordinator,
 		argEnableEpoch,
+		testMarshalizer,
+		unreachableEpoch,
 	)
 	if err != nil {
 		return nil, err
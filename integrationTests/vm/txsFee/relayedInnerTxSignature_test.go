@@ -0,0 +1,88 @@
+package txsFee
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
+	"github.com/ElrondNetwork/elrond-go/integrationTests/vm"
+	"github.com/ElrondNetwork/elrond-go/integrationTests/vm/txsFee/utils"
+	"github.com/ElrondNetwork/elrond-go/process"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayedMoveBalanceSignedInnerTxShouldWork(t *testing.T) {
+	testContext, err := vm.CreatePreparedTxProcessorWithVMsAndInnerTxSignatureCheck(vm.ArgEnableEpoch{})
+	require.Nil(t, err)
+	defer testContext.Close()
+
+	relayerAddr := []byte("12345678901234567890123456789033")
+	sndAddr := []byte("12345678901234567890123456789012")
+	rcvAddr := []byte("12345678901234567890123456789022")
+
+	_, _ = vm.CreateAccount(testContext.Accounts, sndAddr, 0, big.NewInt(0))
+	userTx := vm.CreateTransaction(0, big.NewInt(100), sndAddr, rcvAddr, 1, 100, []byte("aaaa"))
+	vm.SignTransaction(userTx)
+
+	_, _ = vm.CreateAccount(testContext.Accounts, relayerAddr, 0, big.NewInt(3000))
+
+	rtxData := utils.PrepareSignedRelayerTxData(userTx)
+	rTxGasLimit := 1 + userTx.GasLimit + uint64(len(rtxData))
+	rtx := vm.CreateTransaction(0, userTx.Value, relayerAddr, sndAddr, 1, rTxGasLimit, rtxData)
+
+	retCode, err := testContext.TxProcessor.ProcessTransaction(rtx)
+	require.Equal(t, vmcommon.Ok, retCode)
+	require.Nil(t, err)
+
+	_, err = testContext.Accounts.Commit()
+	require.Nil(t, err)
+
+	// the relayer pays the value and the fee, gasLimit * gasPrice(1)
+	expectedBalanceRelayer := big.NewInt(0).Sub(big.NewInt(2900), big.NewInt(int64(rTxGasLimit)))
+	vm.TestAccount(t, testContext.Accounts, relayerAddr, 1, expectedBalanceRelayer)
+
+	// the inner tx is executed
+	vm.TestAccount(t, testContext.Accounts, sndAddr, 1, big.NewInt(0))
+	vm.TestAccount(t, testContext.Accounts, rcvAddr, 0, big.NewInt(100))
+
+	accumulatedFees := testContext.TxFeeHandler.GetAccumulatedFees()
+	require.Equal(t, big.NewInt(int64(rTxGasLimit)), accumulatedFees)
+}
+
+func TestRelayedMoveBalanceTamperedInnerTxSignatureShouldConsumeGas(t *testing.T) {
+	testContext, err := vm.CreatePreparedTxProcessorWithVMsAndInnerTxSignatureCheck(vm.ArgEnableEpoch{})
+	require.Nil(t, err)
+	defer testContext.Close()
+
+	relayerAddr := []byte("12345678901234567890123456789033")
+	sndAddr := []byte("12345678901234567890123456789012")
+	rcvAddr := []byte("12345678901234567890123456789022")
+
+	_, _ = vm.CreateAccount(testContext.Accounts, sndAddr, 0, big.NewInt(0))
+	userTx := vm.CreateTransaction(0, big.NewInt(100), sndAddr, rcvAddr, 1, 100, []byte("aaaa"))
+	vm.SignTransaction(userTx)
+	userTx.Value = big.NewInt(200)
+
+	_, _ = vm.CreateAccount(testContext.Accounts, relayerAddr, 0, big.NewInt(3000))
+
+	rtxData := utils.PrepareSignedRelayerTxData(userTx)
+	rTxGasLimit := 1 + userTx.GasLimit + uint64(len(rtxData))
+	rtx := vm.CreateTransaction(0, userTx.Value, relayerAddr, sndAddr, 1, rTxGasLimit, rtxData)
+
+	retCode, err := testContext.TxProcessor.ProcessTransaction(rtx)
+	require.Equal(t, vmcommon.UserError, retCode)
+	require.Equal(t, process.ErrFailedTransaction, err)
+
+	_, err = testContext.Accounts.Commit()
+	require.Nil(t, err)
+
+	// the relayer pays the relayed tx fee, gasLimit * gasPrice(1), without moving any value
+	expectedBalanceRelayer := big.NewInt(0).Sub(big.NewInt(3000), big.NewInt(int64(rTxGasLimit)))
+	vm.TestAccount(t, testContext.Accounts, relayerAddr, 1, expectedBalanceRelayer)
+
+	// the inner tx is not executed
+	vm.TestAccount(t, testContext.Accounts, sndAddr, 0, big.NewInt(0))
+
+	accumulatedFees := testContext.TxFeeHandler.GetAccumulatedFees()
+	require.Equal(t, big.NewInt(int64(rTxGasLimit)), accumulatedFees)
+}
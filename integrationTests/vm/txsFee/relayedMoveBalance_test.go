@@ -1,14 +1,19 @@
 package txsFee
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
 	"github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/data/blockchain"
+	"github.com/ElrondNetwork/elrond-go/data/state/factory"
 	"github.com/ElrondNetwork/elrond-go/integrationTests/vm"
 	"github.com/ElrondNetwork/elrond-go/integrationTests/vm/txsFee/utils"
+	"github.com/ElrondNetwork/elrond-go/node/txsimulator"
 	"github.com/ElrondNetwork/elrond-go/process"
+	"github.com/ElrondNetwork/elrond-go/testscommon"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,6 +72,120 @@ func TestRelayedMoveBalanceShouldWork(t *testing.T) {
 	require.Equal(t, "2750", indexerTx.Fee)
 }
 
+func TestRelayedMoveBalanceSimulationShouldMatchTheActualOutcome(t *testing.T) {
+	testContext, err := vm.CreatePreparedTxProcessorWithVMs(vm.ArgEnableEpoch{})
+	require.Nil(t, err)
+	defer testContext.Close()
+
+	relayerAddr := []byte("12345678901234567890123456789033")
+	sndAddr := []byte("12345678901234567890123456789012")
+	rcvAddr := []byte("12345678901234567890123456789022")
+
+	gasPrice := uint64(10)
+	gasLimit := uint64(100)
+
+	_, _ = vm.CreateAccount(testContext.Accounts, sndAddr, 0, big.NewInt(0))
+	_, _ = vm.CreateAccount(testContext.Accounts, relayerAddr, 0, big.NewInt(3000))
+	rootHashBefore, err := testContext.Accounts.Commit()
+	require.Nil(t, err)
+
+	userTx := vm.CreateTransaction(0, big.NewInt(100), sndAddr, rcvAddr, gasPrice, gasLimit, []byte("aaaa"))
+
+	rtxData := utils.PrepareRelayerTxData(userTx)
+	rTxGasLimit := 1 + gasLimit + uint64(len(rtxData))
+	rtx := vm.CreateTransaction(0, userTx.Value, relayerAddr, sndAddr, gasPrice, rTxGasLimit, rtxData)
+
+	simulator := createRelayedTxSimulator(t, testContext, rootHashBefore)
+	simResult, err := simulator.SimulateRelayedTransaction(rtx)
+	require.Nil(t, err)
+
+	// the simulation should not touch the accounts, the fees and the results of the block processing
+	rootHashAfterSimulation, err := testContext.Accounts.RootHash()
+	require.Nil(t, err)
+	require.Equal(t, rootHashBefore, rootHashAfterSimulation)
+	require.Equal(t, 0, testContext.Accounts.JournalLen())
+	vm.TestAccount(t, testContext.Accounts, relayerAddr, 0, big.NewInt(3000))
+	vm.TestAccount(t, testContext.Accounts, sndAddr, 0, big.NewInt(0))
+	require.Equal(t, big.NewInt(0), testContext.TxFeeHandler.GetAccumulatedFees())
+	require.Equal(t, 0, len(testContext.GetIntermediateTransactions(t)))
+
+	retCode, err := testContext.TxProcessor.ProcessTransaction(rtx)
+	require.Nil(t, err)
+
+	_, err = testContext.Accounts.Commit()
+	require.Nil(t, err)
+
+	require.Equal(t, retCode, simResult.ReturnCode)
+	require.Nil(t, simResult.ProcessingError)
+	simulatedFee := big.NewInt(0).Add(simResult.RelayerFee, simResult.InnerTxFee)
+	require.Equal(t, testContext.TxFeeHandler.GetAccumulatedFees(), simulatedFee)
+
+	// 3000 - value(100) - fee(2750) = 150
+	vm.TestAccount(t, testContext.Accounts, relayerAddr, 1, big.NewInt(150))
+	vm.TestAccount(t, testContext.Accounts, rcvAddr, 0, big.NewInt(100))
+}
+
+func createRelayedTxSimulator(t *testing.T, testContext *vm.VMTestContext, committedRootHash []byte) process.RelayedTxSimulator {
+	blkc := blockchain.NewBlockChain()
+	err := blkc.SetCurrentBlockHeader(&block.Header{RootHash: committedRootHash})
+	require.Nil(t, err)
+
+	simulator, err := txsimulator.CreateShardRelayedTxSimulator(txsimulator.ArgsShardRelayedTxSimulator{
+		ScProcArgs:       testContext.ScProcArgs,
+		TxProcArgs:       testContext.TxProcArgs,
+		StateTrie:        testContext.StateTrie,
+		AccountFactory:   factory.NewAccountCreator(),
+		BlockChain:       blkc,
+		ShardCoordinator: testContext.ShardCoordinator,
+		Marshalizer:      testContext.TxProcArgs.Marshalizer,
+		Hasher:           testContext.TxProcArgs.Hasher,
+		PubkeyConverter:  testContext.TxProcArgs.PubkeyConv,
+		DataPool:         testscommon.NewPoolsHolderMock(),
+	})
+	require.Nil(t, err)
+
+	return simulator
+}
+
+func TestRelayedMoveBalanceRelayerInsufficientFundsShouldErrWithoutChangingTheState(t *testing.T) {
+	testContext, err := vm.CreatePreparedTxProcessorWithVMs(vm.ArgEnableEpoch{})
+	require.Nil(t, err)
+	defer testContext.Close()
+
+	relayerAddr := []byte("12345678901234567890123456789033")
+	sndAddr := []byte("12345678901234567890123456789012")
+	rcvAddr := []byte("12345678901234567890123456789022")
+
+	gasPrice := uint64(10)
+	gasLimit := uint64(100)
+
+	_, _ = vm.CreateAccount(testContext.Accounts, sndAddr, 0, big.NewInt(0))
+	// the relayer covers the fee(2750) but not the value(100) on top of it
+	_, _ = vm.CreateAccount(testContext.Accounts, relayerAddr, 0, big.NewInt(2800))
+	rootHashBefore, err := testContext.Accounts.Commit()
+	require.Nil(t, err)
+
+	userTx := vm.CreateTransaction(0, big.NewInt(100), sndAddr, rcvAddr, gasPrice, gasLimit, []byte("aaaa"))
+
+	rtxData := utils.PrepareRelayerTxData(userTx)
+	rTxGasLimit := 1 + gasLimit + uint64(len(rtxData))
+	rtx := vm.CreateTransaction(0, userTx.Value, relayerAddr, sndAddr, gasPrice, rTxGasLimit, rtxData)
+
+	retCode, err := testContext.TxProcessor.ProcessTransaction(rtx)
+	require.Equal(t, vmcommon.UserError, retCode)
+	require.True(t, errors.Is(err, process.ErrRelayerInsufficientFunds))
+
+	rootHashAfter, err := testContext.Accounts.Commit()
+	require.Nil(t, err)
+	require.Equal(t, rootHashBefore, rootHashAfter)
+
+	vm.TestAccount(t, testContext.Accounts, relayerAddr, 0, big.NewInt(2800))
+	vm.TestAccount(t, testContext.Accounts, sndAddr, 0, big.NewInt(0))
+
+	accumulatedFees := testContext.TxFeeHandler.GetAccumulatedFees()
+	require.Equal(t, big.NewInt(0), accumulatedFees)
+}
+
 func TestRelayedMoveBalanceInvalidGasLimitShouldConsumeGas(t *testing.T) {
 	testContext, err := vm.CreatePreparedTxProcessorWithVMs(vm.ArgEnableEpoch{})
 	require.Nil(t, err)
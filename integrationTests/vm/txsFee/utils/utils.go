@@ -136,6 +136,13 @@ func PrepareRelayerTxData(innerTx *transaction.Transaction) []byte {
 	return []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxBytes))
 }
 
+// PrepareSignedRelayerTxData returns the data field of a relayed transaction for the test contexts which verify the
+// inner transaction signature
+func PrepareSignedRelayerTxData(innerTx *transaction.Transaction) []byte {
+	userTxBytes := vm.MarshalInnerTx(innerTx)
+	return []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxBytes))
+}
+
 // CheckOwnerAddr -
 func CheckOwnerAddr(t *testing.T, testContext *vm.VMTestContext, scAddr []byte, owner []byte) {
 	acc, err := testContext.Accounts.GetExistingAccount(scAddr)
@@ -217,6 +217,12 @@ var ErrNilTxSimulatorProcessor = errors.New("nil transaction simulator processor
 // ErrNilIntermediateProcessorContainer signals that intermediate processors container is nil
 var ErrNilIntermediateProcessorContainer = errors.New("intermediate processor container is nil")
 
+// ErrNilRelayedTxSimulator signals that a nil relayed transactions simulator has been provided
+var ErrNilRelayedTxSimulator = errors.New("nil relayed transactions simulator")
+
+// ErrRelayedTxSimulationNotSupported signals that the relayed transactions can not be simulated on the current shard
+var ErrRelayedTxSimulationNotSupported = errors.New("relayed transactions simulation is not supported")
+
 // ErrTransactionNotFound signals that a transaction was not found
 var ErrTransactionNotFound = errors.New("transaction not found")
 
@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+// RelayedTxSimulatorStub -
+type RelayedTxSimulatorStub struct {
+	SimulateRelayedTransactionCalled func(rtx *transaction.Transaction) (process.RelayedSimResult, error)
+}
+
+// SimulateRelayedTransaction -
+func (rtss *RelayedTxSimulatorStub) SimulateRelayedTransaction(rtx *transaction.Transaction) (process.RelayedSimResult, error) {
+	if rtss.SimulateRelayedTransactionCalled != nil {
+		return rtss.SimulateRelayedTransactionCalled(rtx)
+	}
+
+	return process.RelayedSimResult{}, nil
+}
+
+// IsInterfaceNil -
+func (rtss *RelayedTxSimulatorStub) IsInterfaceNil() bool {
+	return rtss == nil
+}
@@ -0,0 +1,26 @@
+package txsimulator
+
+import (
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/node"
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+type disabledRelayedTxSimulator struct {
+}
+
+// NewDisabledRelayedTxSimulator returns a relayed transactions simulator which rejects all the simulations. It is used
+// on the metachain, where the relayed transactions are not processed
+func NewDisabledRelayedTxSimulator() *disabledRelayedTxSimulator {
+	return &disabledRelayedTxSimulator{}
+}
+
+// SimulateRelayedTransaction returns ErrRelayedTxSimulationNotSupported
+func (drts *disabledRelayedTxSimulator) SimulateRelayedTransaction(_ *transaction.Transaction) (process.RelayedSimResult, error) {
+	return process.RelayedSimResult{}, node.ErrRelayedTxSimulationNotSupported
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (drts *disabledRelayedTxSimulator) IsInterfaceNil() bool {
+	return drts == nil
+}
@@ -0,0 +1,121 @@
+package txsimulator
+
+import (
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data"
+	dataBlock "github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/data/state"
+	"github.com/ElrondNetwork/elrond-go/dataRetriever"
+	"github.com/ElrondNetwork/elrond-go/epochStart/bootstrap/disabled"
+	processDisabled "github.com/ElrondNetwork/elrond-go/genesis/process/disabled"
+	"github.com/ElrondNetwork/elrond-go/hashing"
+	"github.com/ElrondNetwork/elrond-go/marshal"
+	"github.com/ElrondNetwork/elrond-go/process"
+	"github.com/ElrondNetwork/elrond-go/process/factory/shard"
+	"github.com/ElrondNetwork/elrond-go/process/smartContract"
+	"github.com/ElrondNetwork/elrond-go/process/transaction"
+	"github.com/ElrondNetwork/elrond-go/sharding"
+)
+
+// ArgsShardRelayedTxSimulator holds the arguments needed to create the relayed transactions simulator of a shard node.
+// The smart contract and the transaction processor arguments are the ones used for the block processing, the
+// simulator replacing their accounts, forwarders and fee handler with its own ones
+type ArgsShardRelayedTxSimulator struct {
+	ScProcArgs       smartContract.ArgsNewSmartContractProcessor
+	TxProcArgs       transaction.ArgsNewTxProcessor
+	StateTrie        data.Trie
+	AccountFactory   state.AccountFactory
+	BlockChain       data.ChainHandler
+	ShardCoordinator sharding.Coordinator
+	Marshalizer      marshal.Marshalizer
+	Hasher           hashing.Hasher
+	PubkeyConverter  core.PubkeyConverter
+	DataPool         dataRetriever.PoolsHolder
+}
+
+// CreateShardRelayedTxSimulator creates a relayed transactions simulator which does not share anything written with
+// the block processing: it has its own transaction and smart contract processors, its own intermediate processors and
+// its own writable accounts, working on a trie recreated from the storage of the given state trie, which is never
+// committed
+func CreateShardRelayedTxSimulator(args ArgsShardRelayedTxSimulator) (process.RelayedTxSimulator, error) {
+	if check.IfNil(args.StateTrie) {
+		return nil, state.ErrNilTrie
+	}
+
+	scratchTrie, err := args.StateTrie.Recreate(make([]byte, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	scratchAccounts, err := state.NewAccountsDB(scratchTrie, args.Hasher, args.Marshalizer, args.AccountFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	interimProcFactory, err := shard.NewIntermediateProcessorsContainerFactory(
+		args.ShardCoordinator,
+		args.Marshalizer,
+		args.Hasher,
+		args.PubkeyConverter,
+		disabled.NewChainStorer(),
+		args.DataPool,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	interimProcContainer, err := interimProcFactory.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	scForwarder, err := interimProcContainer.Get(dataBlock.SmartContractResultBlock)
+	if err != nil {
+		return nil, err
+	}
+	receiptTxInterim, err := interimProcContainer.Get(dataBlock.ReceiptBlock)
+	if err != nil {
+		return nil, err
+	}
+	badTxInterim, err := interimProcContainer.Get(dataBlock.InvalidBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	txFeeHandler := &processDisabled.FeeHandler{}
+
+	scProcArgs := args.ScProcArgs
+	scProcArgs.AccountsDB = scratchAccounts
+	scProcArgs.ScrForwarder = scForwarder
+	scProcArgs.BadTxForwarder = badTxInterim
+	scProcArgs.TxFeeHandler = txFeeHandler
+	scProcessor, err := smartContract.NewSmartContractProcessor(scProcArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	txProcArgs := args.TxProcArgs
+	txProcArgs.Accounts = scratchAccounts
+	txProcArgs.ScProcessor = scProcessor
+	txProcArgs.ScrForwarder = scForwarder
+	txProcArgs.ReceiptForwarder = receiptTxInterim
+	txProcArgs.BadTxForwarder = badTxInterim
+	txProcArgs.TxFeeHandler = txFeeHandler
+	txProcessor, err := transaction.NewTxProcessor(txProcArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	argsRelayedTxSimulator := transaction.ArgsRelayedTxSimulator{
+		TxProcessor:               txProcessor,
+		Accounts:                  scratchAccounts,
+		BlockChain:                args.BlockChain,
+		IntermediateProcContainer: interimProcContainer,
+		TxFeeHandler:              txFeeHandler,
+		TxTypeHandler:             txProcArgs.TxTypeHandler,
+		EconomicsFee:              txProcArgs.EconomicsFee,
+	}
+
+	return transaction.NewRelayedTxSimulator(argsRelayedTxSimulator)
+}
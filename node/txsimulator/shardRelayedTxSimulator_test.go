@@ -0,0 +1,17 @@
+package txsimulator
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data/state"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateShardRelayedTxSimulator_NilStateTrieShouldErr(t *testing.T) {
+	t.Parallel()
+
+	simulator, err := CreateShardRelayedTxSimulator(ArgsShardRelayedTxSimulator{})
+	require.Equal(t, state.ErrNilTrie, err)
+	require.True(t, check.IfNil(simulator))
+}
@@ -21,6 +21,7 @@ type ArgsTxSimulator struct {
 	IntermmediateProcContainer process.IntermediateProcessorContainer
 	AddressPubKeyConverter     core.PubkeyConverter
 	ShardCoordinator           sharding.Coordinator
+	RelayedTxSimulator         process.RelayedTxSimulator
 }
 
 type transactionSimulator struct {
@@ -28,6 +29,7 @@ type transactionSimulator struct {
 	intermProcContainer    process.IntermediateProcessorContainer
 	addressPubKeyConverter core.PubkeyConverter
 	shardCoordinator       sharding.Coordinator
+	relayedTxSimulator     process.RelayedTxSimulator
 }
 
 // NewTransactionSimulator returns a new instance of a transactionSimulator
@@ -44,12 +46,16 @@ func NewTransactionSimulator(args ArgsTxSimulator) (*transactionSimulator, error
 	if check.IfNil(args.ShardCoordinator) {
 		return nil, node.ErrNilShardCoordinator
 	}
+	if check.IfNil(args.RelayedTxSimulator) {
+		return nil, node.ErrNilRelayedTxSimulator
+	}
 
 	return &transactionSimulator{
 		txProcessor:            args.TransactionProcessor,
 		intermProcContainer:    args.IntermmediateProcContainer,
 		addressPubKeyConverter: args.AddressPubKeyConverter,
 		shardCoordinator:       args.ShardCoordinator,
+		relayedTxSimulator:     args.RelayedTxSimulator,
 	}, nil
 }
 
@@ -81,6 +87,12 @@ func (ts *transactionSimulator) ProcessTx(tx *transaction.Transaction) (*transac
 	return results, nil
 }
 
+// SimulateRelayedTransaction will simulate the given relayed transaction and will return the fees charged upfront, the
+// return code and the processing error, without keeping any of its effects
+func (ts *transactionSimulator) SimulateRelayedTransaction(rtx *transaction.Transaction) (process.RelayedSimResult, error) {
+	return ts.relayedTxSimulator.SimulateRelayedTransaction(rtx)
+}
+
 func (ts *transactionSimulator) addIntermediateTxsToResult(result *transaction.SimulationResults) error {
 	defer func() {
 		processorsKeys := ts.intermProcContainer.Keys()
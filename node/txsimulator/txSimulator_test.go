@@ -3,8 +3,10 @@ package txsimulator
 import (
 	"encoding/hex"
 	"errors"
+	"math/big"
 	"testing"
 
+	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/block"
@@ -50,6 +52,15 @@ func TestNewTransactionSimulator(t *testing.T) {
 			},
 			exError: node.ErrNilIntermediateProcessorContainer,
 		},
+		{
+			name: "NilRelayedTxSimulator",
+			argsFunc: func() ArgsTxSimulator {
+				args := getTxSimulatorArgs()
+				args.RelayedTxSimulator = nil
+				return args
+			},
+			exError: node.ErrNilRelayedTxSimulator,
+		},
 		{
 			name: "Ok",
 			argsFunc: func() ArgsTxSimulator {
@@ -126,11 +137,45 @@ func TestTransactionSimulator_ProcessTxShouldIncludeScrsAndReceipts(t *testing.T
 	)
 }
 
+func TestTransactionSimulator_SimulateRelayedTransactionShouldUseTheRelayedTxSimulator(t *testing.T) {
+	t.Parallel()
+
+	rtx := &transaction.Transaction{Nonce: 37}
+	expectedResult := process.RelayedSimResult{
+		ReturnCode: vmcommon.UserError,
+		RelayerFee: big.NewInt(10),
+		InnerTxFee: big.NewInt(20),
+	}
+	args := getTxSimulatorArgs()
+	args.RelayedTxSimulator = &mock.RelayedTxSimulatorStub{
+		SimulateRelayedTransactionCalled: func(tx *transaction.Transaction) (process.RelayedSimResult, error) {
+			require.True(t, tx == rtx)
+			return expectedResult, nil
+		},
+	}
+	ts, _ := NewTransactionSimulator(args)
+
+	result, err := ts.SimulateRelayedTransaction(rtx)
+	require.Nil(t, err)
+	require.Equal(t, expectedResult, result)
+}
+
+func TestDisabledRelayedTxSimulator_SimulateRelayedTransactionShouldErr(t *testing.T) {
+	t.Parallel()
+
+	drts := NewDisabledRelayedTxSimulator()
+	require.False(t, check.IfNil(drts))
+
+	_, err := drts.SimulateRelayedTransaction(&transaction.Transaction{})
+	require.Equal(t, node.ErrRelayedTxSimulationNotSupported, err)
+}
+
 func getTxSimulatorArgs() ArgsTxSimulator {
 	return ArgsTxSimulator{
 		TransactionProcessor:       &mock.TxProcessorStub{},
 		IntermmediateProcContainer: &mock.IntermProcessorContainerStub{},
 		AddressPubKeyConverter:     &mock.PubkeyConverterMock{},
 		ShardCoordinator:           mock.NewMultiShardsCoordinatorMock(2),
+		RelayedTxSimulator:         &mock.RelayedTxSimulatorStub{},
 	}
 }
@@ -1,6 +1,8 @@
 package block
 
 import (
+	"time"
+
 	"github.com/ElrondNetwork/elrond-go/consensus"
 	"github.com/ElrondNetwork/elrond-go/core/dblookupext"
 	"github.com/ElrondNetwork/elrond-go/core/indexer"
@@ -44,12 +46,44 @@ type ArgBaseProcessor struct {
 	HistoryRepository       dblookupext.HistoryRepository
 	EpochNotifier           process.EpochNotifier
 	HeaderIntegrityVerifier process.HeaderIntegrityVerifier
+
+	MiniBlockReservedCheckEnableEpoch uint32
 }
 
 // ArgShardProcessor holds all dependencies required by the process data factory in order to create
 // new instances of shard processor
 type ArgShardProcessor struct {
 	ArgBaseProcessor
+	IncludeEmptyAttestedMetaBlocks     bool
+	WeightedMetaBlockSelectionMaxTxs   uint32
+	PoolLogThreshold                   uint64
+	OnTransactionsProcessed            func(results []*process.TransactionExecutionResult)
+	OnNotarizedHeadersPruned           func(numPrunedHeaders uint64)
+	MaxShardHeaderRequestsPerMetaBlock uint32
+	StrictHeaderValidation             bool
+	HeaderTxCountCheckEnableEpoch      uint32
+	BodyShardIdsCheckEnableEpoch       uint32
+	HeaderEpochCheckEnableEpoch        uint32
+	GenesisRandSeedCheckEnableEpoch    uint32
+	MetaBlocksCountCheckEnableEpoch    uint32
+	MetaFinalityVerifier               process.MetaFinalityVerifier
+	BlockCreationPolicy                process.BlockCreationPolicyHandler
+	GenesisTime                        time.Time
+	MaxRoundClockSkew                  time.Duration
+	IndexedTxTransformer               process.IndexedTxTransformer
+	MinTimeForTxProcessing             time.Duration
+	BodyCompositionEnableEpoch         uint32
+	TxExportDir                        string
+	DecodedHeadersCacheSize            uint32
+	CheckAttestedShardInfo             bool
+	PrevHeaderRequestGracePeriod       time.Duration
+	StorageUnitsWriteMode              map[string]string
+	MaxReorgDepth                      uint32
+	MetaBlocksPoolHighFillRatio        float64
+	MetaBlockFinality                  int
+	ProcessedMbsCompactionInterval     time.Duration
+	OnBlockProcessingError             func(header data.HeaderHandler, err error)
+	MaxAttestedMetaBlocksPerBlock      uint32
 }
 
 // ArgMetaProcessor holds all dependencies required by the process data factory in order to create
@@ -26,6 +26,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/process"
 	"github.com/ElrondNetwork/elrond-go/process/block/bootstrapStorage"
 	"github.com/ElrondNetwork/elrond-go/sharding"
+	"github.com/ElrondNetwork/elrond-go/storage"
 )
 
 var log = logger.GetOrCreate("process/block")
@@ -68,6 +69,7 @@ type baseProcessor struct {
 	feeHandler              process.TransactionFeeHandler
 	blockChain              data.ChainHandler
 	hdrsForCurrBlock        *hdrForBlock
+	decodedHeadersCache     *decodedHeadersCache
 	genesisNonce            uint64
 	headerIntegrityVerifier process.HeaderIntegrityVerifier
 
@@ -80,6 +82,13 @@ type baseProcessor struct {
 	tpsBenchmark  statistics.TPSBenchmark
 	historyRepo   dblookupext.HistoryRepository
 	epochNotifier process.EpochNotifier
+
+	asyncStorageUnits map[dataRetriever.UnitType]struct{}
+	startAsyncWrite   func(handler func())
+
+	onBlockProcessingError func(header data.HeaderHandler, err error)
+
+	miniBlockReservedCheckEnableEpoch uint32
 }
 
 type bootStorerDataArgs struct {
@@ -106,6 +115,15 @@ func checkForNils(
 	return nil
 }
 
+// notifyBlockProcessingError calls the block processing error handler, if set, with the failing header and the error
+func (bp *baseProcessor) notifyBlockProcessingError(header data.HeaderHandler, err error) {
+	if bp.onBlockProcessingError == nil {
+		return
+	}
+
+	bp.onBlockProcessingError(header, err)
+}
+
 // SetAppStatusHandler method is used to set appStatusHandler
 func (bp *baseProcessor) SetAppStatusHandler(ash core.AppStatusHandler) error {
 	if check.IfNil(ash) {
@@ -431,6 +449,15 @@ func checkProcessorNilParameters(arguments ArgBaseProcessor) error {
 	return nil
 }
 
+// MissingFinalHeadersCount returns the number of finality attesting headers which are still missing for the block
+// currently in process
+func (bp *baseProcessor) MissingFinalHeadersCount() uint32 {
+	bp.hdrsForCurrBlock.mutHdrsForBlock.RLock()
+	defer bp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
+
+	return bp.hdrsForCurrBlock.missingFinalityAttestingHdrs
+}
+
 func (bp *baseProcessor) createBlockStarted() {
 	bp.hdrsForCurrBlock.resetMissingHdrs()
 	bp.hdrsForCurrBlock.initMaps()
@@ -526,14 +553,55 @@ func (bp *baseProcessor) createMiniBlockHeaders(body *block.Body) (int, []block.
 			ReceiverShardID: body.MiniBlocks[i].ReceiverShardID,
 			TxCount:         uint32(txCount),
 			Type:            body.MiniBlocks[i].Type,
+			Reserved:        body.MiniBlocks[i].Reserved,
 		}
 	}
 
 	return totalTxCount, miniBlockHeaders, nil
 }
 
-// check if header has the same miniblocks as presented in body
-func (bp *baseProcessor) checkHeaderBodyCorrelation(miniBlockHeaders []block.MiniBlockHeader, body *block.Body) error {
+// check if header has the same miniblocks as presented in body. The miniblocks reserved fields are checked starting with
+// the miniblock reserved check enable epoch
+func (bp *baseProcessor) checkHeaderBodyCorrelation(
+	miniBlockHeaders []block.MiniBlockHeader,
+	body *block.Body,
+	epoch uint32,
+) error {
+	shouldCheckReserved := epoch >= bp.miniBlockReservedCheckEnableEpoch
+	return checkMiniBlockHeadersBodyCorrelation(bp.hasher, bp.marshalizer, miniBlockHeaders, body, shouldCheckReserved)
+}
+
+// ValidateHeaderBodyCorrelation checks if the given shard header has the same miniblocks as presented in body.
+// It does not need a block processor instance, so it can be used by any component which needs this validation
+func ValidateHeaderBodyCorrelation(
+	hasher hashing.Hasher,
+	marshalizer marshal.Marshalizer,
+	hdr *block.Header,
+	body *block.Body,
+) error {
+	if check.IfNil(hasher) {
+		return process.ErrNilHasher
+	}
+	if check.IfNil(marshalizer) {
+		return process.ErrNilMarshalizer
+	}
+	if hdr == nil {
+		return process.ErrNilBlockHeader
+	}
+	if body == nil {
+		return process.ErrNilBlockBody
+	}
+
+	return checkMiniBlockHeadersBodyCorrelation(hasher, marshalizer, hdr.MiniBlockHeaders, body, true)
+}
+
+func checkMiniBlockHeadersBodyCorrelation(
+	hasher hashing.Hasher,
+	marshalizer marshal.Marshalizer,
+	miniBlockHeaders []block.MiniBlockHeader,
+	body *block.Body,
+	shouldCheckReserved bool,
+) error {
 	mbHashesFromHdr := make(map[string]*block.MiniBlockHeader, len(miniBlockHeaders))
 	for i := 0; i < len(miniBlockHeaders); i++ {
 		mbHashesFromHdr[string(miniBlockHeaders[i].Hash)] = &miniBlockHeaders[i]
@@ -543,17 +611,24 @@ func (bp *baseProcessor) checkHeaderBodyCorrelation(miniBlockHeaders []block.Min
 		return process.ErrHeaderBodyMismatch
 	}
 
+	mbHashesFromBody := make(map[string]struct{}, len(body.MiniBlocks))
 	for i := 0; i < len(body.MiniBlocks); i++ {
 		miniBlock := body.MiniBlocks[i]
 		if miniBlock == nil {
 			return process.ErrNilMiniBlock
 		}
 
-		mbHash, err := core.CalculateHash(bp.marshalizer, bp.hasher, miniBlock)
+		mbHash, err := core.CalculateHash(marshalizer, hasher, miniBlock)
 		if err != nil {
 			return err
 		}
 
+		_, isDuplicate := mbHashesFromBody[string(mbHash)]
+		if isDuplicate {
+			return process.ErrDuplicateMiniBlockInBody
+		}
+		mbHashesFromBody[string(mbHash)] = struct{}{}
+
 		mbHdr, ok := mbHashesFromHdr[string(mbHash)]
 		if !ok {
 			return process.ErrHeaderBodyMismatch
@@ -570,6 +645,10 @@ func (bp *baseProcessor) checkHeaderBodyCorrelation(miniBlockHeaders []block.Min
 		if mbHdr.SenderShardID != miniBlock.SenderShardID {
 			return process.ErrHeaderBodyMismatch
 		}
+
+		if shouldCheckReserved && !bytes.Equal(mbHdr.Reserved, miniBlock.Reserved) {
+			return process.ErrMiniBlockReservedFieldMismatch
+		}
 	}
 
 	return nil
@@ -627,39 +706,42 @@ func (bp *baseProcessor) requestHeaderByShardAndNonce(shardID uint32, nonce uint
 	}
 }
 
-func (bp *baseProcessor) cleanupPools(headerHandler data.HeaderHandler) {
+// cleanupPools removes from pools the headers behind the final ones and returns the number of removed headers
+func (bp *baseProcessor) cleanupPools(headerHandler data.HeaderHandler) uint64 {
 	bp.cleanupBlockTrackerPools(headerHandler)
 
 	noncesToFinal := bp.getNoncesToFinal(headerHandler)
 
-	bp.removeHeadersBehindNonceFromPools(
+	numRemovedHeaders := bp.removeHeadersBehindNonceFromPools(
 		true,
 		bp.shardCoordinator.SelfId(),
 		bp.forkDetector.GetHighestFinalBlockNonce())
 
 	if bp.shardCoordinator.SelfId() == core.MetachainShardId {
 		for shardID := uint32(0); shardID < bp.shardCoordinator.NumberOfShards(); shardID++ {
-			bp.cleanupPoolsForCrossShard(shardID, noncesToFinal)
+			numRemovedHeaders += bp.cleanupPoolsForCrossShard(shardID, noncesToFinal)
 		}
 	} else {
-		bp.cleanupPoolsForCrossShard(core.MetachainShardId, noncesToFinal)
+		numRemovedHeaders += bp.cleanupPoolsForCrossShard(core.MetachainShardId, noncesToFinal)
 	}
+
+	return numRemovedHeaders
 }
 
 func (bp *baseProcessor) cleanupPoolsForCrossShard(
 	shardID uint32,
 	noncesToFinal uint64,
-) {
+) uint64 {
 	crossNotarizedHeader, _, err := bp.blockTracker.GetCrossNotarizedHeader(shardID, noncesToFinal)
 	if err != nil {
 		log.Warn("cleanupPoolsForCrossShard",
 			"shard", shardID,
 			"nonces to final", noncesToFinal,
 			"error", err.Error())
-		return
+		return 0
 	}
 
-	bp.removeHeadersBehindNonceFromPools(
+	return bp.removeHeadersBehindNonceFromPools(
 		false,
 		shardID,
 		crossNotarizedHeader.GetNonce(),
@@ -670,11 +752,12 @@ func (bp *baseProcessor) removeHeadersBehindNonceFromPools(
 	shouldRemoveBlockBody bool,
 	shardId uint32,
 	nonce uint64,
-) {
+) uint64 {
 	if nonce <= 1 {
-		return
+		return 0
 	}
 
+	numRemovedHeaders := uint64(0)
 	headersPool := bp.dataPool.Headers()
 	nonces := headersPool.Nonces(shardId)
 	for _, nonceFromCache := range nonces {
@@ -682,21 +765,21 @@ func (bp *baseProcessor) removeHeadersBehindNonceFromPools(
 			continue
 		}
 
-		if shouldRemoveBlockBody {
-			bp.removeBlocksBody(nonceFromCache, shardId)
+		headers, _, err := headersPool.GetHeadersByNonceAndShardId(nonceFromCache, shardId)
+		if err == nil {
+			numRemovedHeaders += uint64(len(headers))
+			if shouldRemoveBlockBody {
+				bp.removeBlocksBody(headers)
+			}
 		}
 
 		headersPool.RemoveHeaderByNonceAndShardId(nonceFromCache, shardId)
 	}
-}
 
-func (bp *baseProcessor) removeBlocksBody(nonce uint64, shardId uint32) {
-	headersPool := bp.dataPool.Headers()
-	headers, _, err := headersPool.GetHeadersByNonceAndShardId(nonce, shardId)
-	if err != nil {
-		return
-	}
+	return numRemovedHeaders
+}
 
+func (bp *baseProcessor) removeBlocksBody(headers []data.HeaderHandler) {
 	for _, header := range headers {
 		errNotCritical := bp.removeBlockBodyOfHeader(header)
 		if errNotCritical != nil {
@@ -963,6 +1046,13 @@ func (bp *baseProcessor) DecodeBlockHeader(dta []byte) data.HeaderHandler {
 		return nil
 	}
 
+	if bp.decodedHeadersCache != nil {
+		header, ok := bp.decodedHeadersCache.get(dta)
+		if ok {
+			return header
+		}
+	}
+
 	header := bp.blockChain.CreateNewHeader()
 
 	err := bp.marshalizer.Unmarshal(header, dta)
@@ -971,10 +1061,15 @@ func (bp *baseProcessor) DecodeBlockHeader(dta []byte) data.HeaderHandler {
 		return nil
 	}
 
+	if bp.decodedHeadersCache != nil {
+		bp.decodedHeadersCache.put(dta, header)
+	}
+
 	return header
 }
 
-func (bp *baseProcessor) saveBody(body *block.Body, header data.HeaderHandler) {
+// saveBody saves the given body in storage and returns the total size of the marshalized miniblocks
+func (bp *baseProcessor) saveBody(body *block.Body, header data.HeaderHandler) int {
 	startTime := time.Now()
 
 	errNotCritical := bp.txCoordinator.SaveTxsToStorage(body)
@@ -983,31 +1078,30 @@ func (bp *baseProcessor) saveBody(body *block.Body, header data.HeaderHandler) {
 	}
 	log.Trace("saveBody.SaveTxsToStorage", "time", time.Since(startTime))
 
-	var marshalizedMiniBlock []byte
+	miniBlocksSize := 0
+	miniBlocksHashes := make([][]byte, 0, len(body.MiniBlocks))
+	marshalizedMiniBlocks := make([][]byte, 0, len(body.MiniBlocks))
 	for i := 0; i < len(body.MiniBlocks); i++ {
-		marshalizedMiniBlock, errNotCritical = bp.marshalizer.Marshal(body.MiniBlocks[i])
-		if errNotCritical != nil {
-			log.Warn("saveBody.Marshal", "error", errNotCritical.Error())
+		marshalizedMiniBlock, errMarshal := bp.marshalizer.Marshal(body.MiniBlocks[i])
+		if errMarshal != nil {
+			log.Warn("saveBody.Marshal", "error", errMarshal.Error())
 			continue
 		}
+		miniBlocksSize += len(marshalizedMiniBlock)
 
-		miniBlockHash := bp.hasher.Compute(string(marshalizedMiniBlock))
-		errNotCritical = bp.store.Put(dataRetriever.MiniBlockUnit, miniBlockHash, marshalizedMiniBlock)
-		if errNotCritical != nil {
-			log.Warn("saveBody.Put -> MiniBlockUnit", "error", errNotCritical.Error())
-		}
-		log.Trace("saveBody.Put -> MiniBlockUnit", "time", time.Since(startTime))
+		miniBlocksHashes = append(miniBlocksHashes, bp.hasher.Compute(string(marshalizedMiniBlock)))
+		marshalizedMiniBlocks = append(marshalizedMiniBlocks, marshalizedMiniBlock)
 	}
 
+	bp.putBatchInStorer(dataRetriever.MiniBlockUnit, miniBlocksHashes, marshalizedMiniBlocks, "saveBody.Put -> MiniBlockUnit")
+	log.Trace("saveBody.Put -> MiniBlockUnit", "time", time.Since(startTime))
+
 	marshalizedReceipts, errNotCritical := bp.txCoordinator.CreateMarshalizedReceipts()
 	if errNotCritical != nil {
 		log.Warn("saveBody.CreateMarshalizedReceipts", "error", errNotCritical.Error())
 	} else {
 		if len(marshalizedReceipts) > 0 {
-			errNotCritical = bp.store.Put(dataRetriever.ReceiptsUnit, header.GetReceiptsHash(), marshalizedReceipts)
-			if errNotCritical != nil {
-				log.Warn("saveBody.Put -> ReceiptsUnit", "error", errNotCritical.Error())
-			}
+			bp.putInStorer(dataRetriever.ReceiptsUnit, header.GetReceiptsHash(), marshalizedReceipts, "saveBody.Put -> ReceiptsUnit")
 		}
 	}
 
@@ -1015,6 +1109,37 @@ func (bp *baseProcessor) saveBody(body *block.Body, header data.HeaderHandler) {
 	if elapsedTime >= core.PutInStorerMaxTime {
 		log.Warn("saveBody", "elapsed time", elapsedTime)
 	}
+
+	return miniBlocksSize
+}
+
+// LoadBodyFromStorage reconstructs a block body from the miniblocks persisted in the miniblocks storage unit,
+// keeping the order of the provided miniblock hashes
+func (bp *baseProcessor) LoadBodyFromStorage(miniBlockHashes [][]byte) (*block.Body, error) {
+	body := &block.Body{
+		MiniBlocks: make([]*block.MiniBlock, 0, len(miniBlockHashes)),
+	}
+
+	for _, miniBlockHash := range miniBlockHashes {
+		marshalizedMiniBlock, err := bp.store.Get(dataRetriever.MiniBlockUnit, miniBlockHash)
+		if err != nil {
+			return nil, fmt.Errorf("%w, hash: %s, error: %s",
+				process.ErrMissingMiniBlockInStorage,
+				logger.DisplayByteSlice(miniBlockHash),
+				err.Error(),
+			)
+		}
+
+		miniBlock := &block.MiniBlock{}
+		err = bp.marshalizer.Unmarshal(miniBlock, marshalizedMiniBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		body.MiniBlocks = append(body.MiniBlocks, miniBlock)
+	}
+
+	return body, nil
 }
 
 func (bp *baseProcessor) saveShardHeader(header data.HeaderHandler, headerHash []byte, marshalizedHeader []byte) {
@@ -1023,17 +1148,9 @@ func (bp *baseProcessor) saveShardHeader(header data.HeaderHandler, headerHash [
 	nonceToByteSlice := bp.uint64Converter.ToByteSlice(header.GetNonce())
 	hdrNonceHashDataUnit := dataRetriever.ShardHdrNonceHashDataUnit + dataRetriever.UnitType(header.GetShardID())
 
-	errNotCritical := bp.store.Put(hdrNonceHashDataUnit, nonceToByteSlice, headerHash)
-	if errNotCritical != nil {
-		log.Warn(fmt.Sprintf("saveHeader.Put -> ShardHdrNonceHashDataUnit_%d", header.GetShardID()),
-			"error", errNotCritical.Error(),
-		)
-	}
-
-	errNotCritical = bp.store.Put(dataRetriever.BlockHeaderUnit, headerHash, marshalizedHeader)
-	if errNotCritical != nil {
-		log.Warn("saveHeader.Put -> BlockHeaderUnit", "error", errNotCritical.Error())
-	}
+	bp.putInStorer(hdrNonceHashDataUnit, nonceToByteSlice, headerHash,
+		fmt.Sprintf("saveHeader.Put -> ShardHdrNonceHashDataUnit_%d", header.GetShardID()))
+	bp.putInStorer(dataRetriever.BlockHeaderUnit, headerHash, marshalizedHeader, "saveHeader.Put -> BlockHeaderUnit")
 
 	elapsedTime := time.Since(startTime)
 	if elapsedTime >= core.PutInStorerMaxTime {
@@ -1041,6 +1158,56 @@ func (bp *baseProcessor) saveShardHeader(header data.HeaderHandler, headerHash [
 	}
 }
 
+// putInStorer saves the given key-value pair in the given storage unit. The write is done on a background go routine
+// if the unit was configured to be written asynchronously, otherwise the call waits for the write to finish
+func (bp *baseProcessor) putInStorer(unit dataRetriever.UnitType, key []byte, value []byte, logMessage string) {
+	put := func() {
+		errNotCritical := bp.store.Put(unit, key, value)
+		if errNotCritical != nil {
+			log.Warn(logMessage, "error", errNotCritical.Error())
+		}
+	}
+
+	_, isAsync := bp.asyncStorageUnits[unit]
+	if isAsync && bp.startAsyncWrite != nil {
+		bp.startAsyncWrite(put)
+		return
+	}
+
+	put()
+}
+
+// putBatchInStorer saves the given (key, value) pairs with a single batched write, if the storer of the given unit
+// supports it, otherwise with one write for each pair
+func (bp *baseProcessor) putBatchInStorer(unit dataRetriever.UnitType, keys [][]byte, values [][]byte, logMessage string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	batchStorer, ok := bp.store.GetStorer(unit).(storage.StorerWithPutBatch)
+	if !ok || check.IfNil(batchStorer) {
+		for i := range keys {
+			bp.putInStorer(unit, keys[i], values[i], logMessage)
+		}
+		return
+	}
+
+	putBatch := func() {
+		errNotCritical := batchStorer.PutBatch(keys, values)
+		if errNotCritical != nil {
+			log.Warn(logMessage, "error", errNotCritical.Error())
+		}
+	}
+
+	_, isAsync := bp.asyncStorageUnits[unit]
+	if isAsync && bp.startAsyncWrite != nil {
+		bp.startAsyncWrite(putBatch)
+		return
+	}
+
+	putBatch()
+}
+
 func (bp *baseProcessor) saveMetaHeader(header data.HeaderHandler, headerHash []byte, marshalizedHeader []byte) {
 	startTime := time.Now()
 
@@ -3,6 +3,7 @@ package block_test
 import (
 	"bytes"
 	"errors"
+	"math"
 	"math/big"
 	"reflect"
 	"sort"
@@ -29,6 +30,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/storage/storageUnit"
 	"github.com/ElrondNetwork/elrond-go/testscommon"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func haveTime() time.Duration {
@@ -286,6 +288,7 @@ func CreateMockArguments() blproc.ArgShardProcessor {
 
 	blkc := blockchain.NewBlockChain()
 	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	blockCreationPolicy, _ := blproc.NewBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: true})
 	arguments := blproc.ArgShardProcessor{
 		ArgBaseProcessor: blproc.ArgBaseProcessor{
 			AccountsDB:        accountsDb,
@@ -317,7 +320,15 @@ func CreateMockArguments() blproc.ArgShardProcessor {
 			HeaderIntegrityVerifier: &mock.HeaderIntegrityVerifierStub{},
 			HistoryRepository:       &testscommon.HistoryRepositoryStub{},
 			EpochNotifier:           &mock.EpochNotifierStub{},
+
+			MiniBlockReservedCheckEnableEpoch: math.MaxUint32,
 		},
+		IncludeEmptyAttestedMetaBlocks:  true,
+		BlockCreationPolicy:             blockCreationPolicy,
+		HeaderTxCountCheckEnableEpoch:   math.MaxUint32,
+		BodyShardIdsCheckEnableEpoch:    math.MaxUint32,
+		HeaderEpochCheckEnableEpoch:     math.MaxUint32,
+		GenesisRandSeedCheckEnableEpoch: math.MaxUint32,
 	}
 
 	return arguments
@@ -482,9 +493,10 @@ func TestBaseProcessor_RemoveHeadersBehindNonceFromPools(t *testing.T) {
 	arguments.DataPool = dataPool
 	bp, _ := blproc.NewShardProcessor(arguments)
 
-	bp.RemoveHeadersBehindNonceFromPools(true, 0, 4)
+	numRemovedHeaders := bp.RemoveHeadersBehindNonceFromPools(true, 0, 4)
 
 	assert.True(t, removeFromDataPoolWasCalled)
+	assert.Equal(t, uint64(3), numRemovedHeaders)
 }
 
 //------- ComputeNewNoncePrevHash
@@ -1205,3 +1217,80 @@ func TestAddHeaderIntoTrackerPool_ShouldWork(t *testing.T) {
 	sp.AddHeaderIntoTrackerPool(nonce, shardID)
 	assert.True(t, wasCalled)
 }
+
+func TestValidateHeaderBodyCorrelation_NilHasherShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hdr, body := createOneHeaderOneBody()
+	err := blproc.ValidateHeaderBodyCorrelation(nil, &mock.MarshalizerMock{}, hdr, body)
+	assert.Equal(t, process.ErrNilHasher, err)
+}
+
+func TestValidateHeaderBodyCorrelation_NilMarshalizerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hdr, body := createOneHeaderOneBody()
+	err := blproc.ValidateHeaderBodyCorrelation(&mock.HasherStub{}, nil, hdr, body)
+	assert.Equal(t, process.ErrNilMarshalizer, err)
+}
+
+func TestValidateHeaderBodyCorrelation_MismatchedBodiesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherStub{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	hdr, body := createOneHeaderOneBody()
+	body.MiniBlocks = append(body.MiniBlocks, &block.MiniBlock{SenderShardID: 1})
+	err := blproc.ValidateHeaderBodyCorrelation(hasher, marshalizer, hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+
+	hdr, body = createOneHeaderOneBody()
+	body.MiniBlocks[0].TxHashes = append(body.MiniBlocks[0].TxHashes, []byte("tx_hash2"))
+	err = blproc.ValidateHeaderBodyCorrelation(hasher, marshalizer, hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+
+	hdr, body = createOneHeaderOneBody()
+	hdr.MiniBlockHeaders[0].ReceiverShardID = body.MiniBlocks[0].ReceiverShardID + 1
+	err = blproc.ValidateHeaderBodyCorrelation(hasher, marshalizer, hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+
+	hdr, body = createOneHeaderOneBody()
+	body.MiniBlocks[0] = nil
+	err = blproc.ValidateHeaderBodyCorrelation(hasher, marshalizer, hdr, body)
+	assert.Equal(t, process.ErrNilMiniBlock, err)
+}
+
+func TestValidateHeaderBodyCorrelation_ShouldWork(t *testing.T) {
+	t.Parallel()
+
+	hdr, body := createOneHeaderOneBody()
+	err := blproc.ValidateHeaderBodyCorrelation(&mock.HasherStub{}, &mock.MarshalizerMock{}, hdr, body)
+	assert.Nil(t, err)
+}
+
+func TestBaseProcessor_LoadBodyFromStorage(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArguments()
+	marshalizer := arguments.Marshalizer
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	miniBlocks := []*block.MiniBlock{
+		{SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("tx1")}},
+		{SenderShardID: 0, ReceiverShardID: 0, TxHashes: [][]byte{[]byte("tx2"), []byte("tx3")}},
+	}
+	miniBlockHashes := [][]byte{[]byte("mb hash 1"), []byte("mb hash 2")}
+	for i, miniBlock := range miniBlocks {
+		marshalizedMiniBlock, _ := marshalizer.Marshal(miniBlock)
+		_ = arguments.Store.Put(dataRetriever.MiniBlockUnit, miniBlockHashes[i], marshalizedMiniBlock)
+	}
+
+	body, err := sp.LoadBodyFromStorage(miniBlockHashes)
+	require.Nil(t, err)
+	assert.Equal(t, &block.Body{MiniBlocks: miniBlocks}, body)
+
+	body, err = sp.LoadBodyFromStorage([][]byte{miniBlockHashes[0], []byte("missing mb hash")})
+	assert.True(t, errors.Is(err, process.ErrMissingMiniBlockInStorage))
+	assert.Nil(t, body)
+}
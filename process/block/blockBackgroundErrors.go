@@ -0,0 +1,56 @@
+package block
+
+import "sync"
+
+// blockBackgroundErrors keeps, for a bounded number of blocks, the errors returned by the background go routines
+// launched while processing each of them
+type blockBackgroundErrors struct {
+	maxBlocks         int
+	maxErrorsPerBlock int
+	errors            map[string][]error
+	orderedHashes     []string
+	mutErrors         sync.RWMutex
+}
+
+func newBlockBackgroundErrors(maxBlocks int, maxErrorsPerBlock int) *blockBackgroundErrors {
+	return &blockBackgroundErrors{
+		maxBlocks:         maxBlocks,
+		maxErrorsPerBlock: maxErrorsPerBlock,
+		errors:            make(map[string][]error),
+		orderedHashes:     make([]string, 0, maxBlocks),
+	}
+}
+
+// add appends the given error to the ones of the given block. When the number of tracked blocks exceeds the limit,
+// the oldest tracked block is dropped, and when the number of errors of a block reaches its limit, the new ones are ignored
+func (bbe *blockBackgroundErrors) add(headerHash []byte, err error) {
+	bbe.mutErrors.Lock()
+	defer bbe.mutErrors.Unlock()
+
+	blockErrors, exists := bbe.errors[string(headerHash)]
+	if !exists {
+		bbe.orderedHashes = append(bbe.orderedHashes, string(headerHash))
+	}
+	if len(blockErrors) >= bbe.maxErrorsPerBlock {
+		return
+	}
+
+	bbe.errors[string(headerHash)] = append(blockErrors, err)
+
+	if len(bbe.orderedHashes) > bbe.maxBlocks {
+		delete(bbe.errors, bbe.orderedHashes[0])
+		bbe.orderedHashes = bbe.orderedHashes[1:]
+	}
+}
+
+// get returns a copy of the errors saved for the given block
+func (bbe *blockBackgroundErrors) get(headerHash []byte) []error {
+	bbe.mutErrors.RLock()
+	defer bbe.mutErrors.RUnlock()
+
+	blockErrors := bbe.errors[string(headerHash)]
+	errorsCopy := make([]error, len(blockErrors))
+	copy(errorsCopy, blockErrors)
+
+	return errorsCopy
+}
@@ -0,0 +1,90 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+var _ process.BlockCreationPolicyHandler = (*blockCreationPolicy)(nil)
+
+// ArgsBlockCreationPolicy are the arguments needed to create a new block creation policy
+type ArgsBlockCreationPolicy struct {
+	MinGasPriceForInclusion uint64
+	MaxTxDataSize           uint64
+	MaxTxsPerDestShard      uint32
+	MaxBlockBodyBytes       uint32
+	BodyComposition         process.BodyComposition
+	ProduceEmptyBlocks      bool
+}
+
+type blockCreationPolicy struct {
+	minGasPriceForInclusion uint64
+	maxTxDataSize           uint64
+	maxTxsPerDestShard      uint32
+	maxBlockBodyBytes       uint32
+	bodyComposition         process.BodyComposition
+	produceEmptyBlocks      bool
+}
+
+// NewBlockCreationPolicy returns the policies applied only by the proposer, when a shard block is created. They are
+// never used when a received block is processed. An empty body composition defaults to CrossShardFirst
+func NewBlockCreationPolicy(args ArgsBlockCreationPolicy) (*blockCreationPolicy, error) {
+	bodyComposition := args.BodyComposition
+	if len(bodyComposition) == 0 {
+		bodyComposition = process.CrossShardFirst
+	}
+	if bodyComposition != process.CrossShardFirst && bodyComposition != process.FromMeFirst {
+		return nil, fmt.Errorf("%w: %s", process.ErrInvalidBodyComposition, bodyComposition)
+	}
+
+	return &blockCreationPolicy{
+		minGasPriceForInclusion: args.MinGasPriceForInclusion,
+		maxTxDataSize:           args.MaxTxDataSize,
+		maxTxsPerDestShard:      args.MaxTxsPerDestShard,
+		maxBlockBodyBytes:       args.MaxBlockBodyBytes,
+		bodyComposition:         bodyComposition,
+		produceEmptyBlocks:      args.ProduceEmptyBlocks,
+	}, nil
+}
+
+// MinGasPriceForInclusion returns the min gas price a transaction should have to be included in a created block
+func (bcp *blockCreationPolicy) MinGasPriceForInclusion() uint64 {
+	return bcp.minGasPriceForInclusion
+}
+
+// MaxTxDataSize returns the max data size of a transaction included in a created block (0 means no limit)
+func (bcp *blockCreationPolicy) MaxTxDataSize() uint64 {
+	return bcp.maxTxDataSize
+}
+
+// MaxTxsPerDestShard returns the max number of transactions included in a created block for each destination shard
+// (0 means no limit)
+func (bcp *blockCreationPolicy) MaxTxsPerDestShard() uint32 {
+	return bcp.maxTxsPerDestShard
+}
+
+// MaxBlockBodyBytes returns the max size in bytes of a created block body (0 means no limit)
+func (bcp *blockCreationPolicy) MaxBlockBodyBytes() uint32 {
+	return bcp.maxBlockBodyBytes
+}
+
+// BodyComposition returns the order in which the miniblocks are added in a created block body
+func (bcp *blockCreationPolicy) BodyComposition() process.BodyComposition {
+	return bcp.bodyComposition
+}
+
+// ProduceEmptyBlocks returns true if a block should be created even when there is nothing to propose
+func (bcp *blockCreationPolicy) ProduceEmptyBlocks() bool {
+	return bcp.produceEmptyBlocks
+}
+
+// IsMaxBlockBodyBytesReached returns true if the max block body size in bytes is set and the given size reached it
+func (bcp *blockCreationPolicy) IsMaxBlockBodyBytesReached(size int) bool {
+	return bcp.maxBlockBodyBytes > 0 && size >= int(bcp.maxBlockBodyBytes)
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (bcp *blockCreationPolicy) IsInterfaceNil() bool {
+	return bcp == nil
+}
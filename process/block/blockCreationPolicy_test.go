@@ -0,0 +1,75 @@
+package block_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/process"
+	blproc "github.com/ElrondNetwork/elrond-go/process/block"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockArgsBlockCreationPolicy() blproc.ArgsBlockCreationPolicy {
+	return blproc.ArgsBlockCreationPolicy{
+		MinGasPriceForInclusion: 1000,
+		MaxTxDataSize:           100,
+		MaxTxsPerDestShard:      10,
+		MaxBlockBodyBytes:       2000,
+		BodyComposition:         process.FromMeFirst,
+		ProduceEmptyBlocks:      true,
+	}
+}
+
+func TestNewBlockCreationPolicy_InvalidBodyCompositionShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsBlockCreationPolicy()
+	args.BodyComposition = "invalid"
+	bcp, err := blproc.NewBlockCreationPolicy(args)
+
+	assert.True(t, check.IfNil(bcp))
+	assert.True(t, errors.Is(err, process.ErrInvalidBodyComposition))
+}
+
+func TestNewBlockCreationPolicy_EmptyBodyCompositionShouldDefaultToCrossShardFirst(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsBlockCreationPolicy()
+	args.BodyComposition = ""
+	bcp, err := blproc.NewBlockCreationPolicy(args)
+
+	assert.Nil(t, err)
+	assert.Equal(t, process.CrossShardFirst, bcp.BodyComposition())
+}
+
+func TestNewBlockCreationPolicy_ShouldWork(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsBlockCreationPolicy()
+	bcp, err := blproc.NewBlockCreationPolicy(args)
+
+	assert.Nil(t, err)
+	assert.False(t, check.IfNil(bcp))
+	assert.Equal(t, args.MinGasPriceForInclusion, bcp.MinGasPriceForInclusion())
+	assert.Equal(t, args.MaxTxDataSize, bcp.MaxTxDataSize())
+	assert.Equal(t, args.MaxTxsPerDestShard, bcp.MaxTxsPerDestShard())
+	assert.Equal(t, args.MaxBlockBodyBytes, bcp.MaxBlockBodyBytes())
+	assert.Equal(t, args.BodyComposition, bcp.BodyComposition())
+	assert.Equal(t, args.ProduceEmptyBlocks, bcp.ProduceEmptyBlocks())
+}
+
+func TestBlockCreationPolicy_IsMaxBlockBodyBytesReached(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsBlockCreationPolicy()
+	bcp, _ := blproc.NewBlockCreationPolicy(args)
+
+	assert.False(t, bcp.IsMaxBlockBodyBytesReached(1999))
+	assert.True(t, bcp.IsMaxBlockBodyBytesReached(2000))
+
+	args.MaxBlockBodyBytes = 0
+	bcp, _ = blproc.NewBlockCreationPolicy(args)
+
+	assert.False(t, bcp.IsMaxBlockBodyBytesReached(1000000))
+}
@@ -0,0 +1,80 @@
+package block
+
+import "sync"
+
+// blockProductionTracker keeps, for a sliding window of rounds, the rounds in which the node attempted to produce a
+// block and which of those attempts ended up committed
+type blockProductionTracker struct {
+	windowSize         uint64
+	highestRound       uint64
+	attemptedRounds    map[uint64]bool
+	mutAttemptedRounds sync.RWMutex
+}
+
+func newBlockProductionTracker(windowSize uint64) *blockProductionTracker {
+	return &blockProductionTracker{
+		windowSize:      windowSize,
+		attemptedRounds: make(map[uint64]bool),
+	}
+}
+
+// addAttempt marks the given round as attempted
+func (bpt *blockProductionTracker) addAttempt(round uint64) {
+	bpt.mutAttemptedRounds.Lock()
+	defer bpt.mutAttemptedRounds.Unlock()
+
+	_, exists := bpt.attemptedRounds[round]
+	if !exists {
+		bpt.attemptedRounds[round] = false
+	}
+	if round > bpt.highestRound {
+		bpt.highestRound = round
+	}
+
+	bpt.removeRoundsOutsideWindow()
+}
+
+// addCommit marks the given round as committed, if it was previously attempted
+func (bpt *blockProductionTracker) addCommit(round uint64) {
+	bpt.mutAttemptedRounds.Lock()
+	defer bpt.mutAttemptedRounds.Unlock()
+
+	_, exists := bpt.attemptedRounds[round]
+	if !exists {
+		return
+	}
+
+	bpt.attemptedRounds[round] = true
+}
+
+// successRate returns the fraction of the attempted rounds from the window which resulted in a committed block
+func (bpt *blockProductionTracker) successRate() float64 {
+	bpt.mutAttemptedRounds.RLock()
+	defer bpt.mutAttemptedRounds.RUnlock()
+
+	if len(bpt.attemptedRounds) == 0 {
+		return 0
+	}
+
+	numCommitted := 0
+	for _, committed := range bpt.attemptedRounds {
+		if committed {
+			numCommitted++
+		}
+	}
+
+	return float64(numCommitted) / float64(len(bpt.attemptedRounds))
+}
+
+func (bpt *blockProductionTracker) removeRoundsOutsideWindow() {
+	if bpt.highestRound < bpt.windowSize {
+		return
+	}
+
+	minRound := bpt.highestRound - bpt.windowSize
+	for round := range bpt.attemptedRounds {
+		if round <= minRound {
+			delete(bpt.attemptedRounds, round)
+		}
+	}
+}
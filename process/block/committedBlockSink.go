@@ -0,0 +1,68 @@
+package block
+
+import (
+	"sync/atomic"
+
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/block"
+)
+
+const committedBlockSinkChanSize = 100
+
+type committedBlock struct {
+	header data.HeaderHandler
+	body   block.Body
+	hash   []byte
+}
+
+// committedBlockSink delivers the committed blocks to an external handler on its own go routine, through a bounded
+// buffer, so that a slow handler does not block the commit. Blocks which do not fit in the buffer are dropped
+type committedBlockSink struct {
+	handler    func(header data.HeaderHandler, body block.Body, hash []byte)
+	chBlocks   chan *committedBlock
+	numDropped uint64
+	chStop     chan struct{}
+}
+
+func newCommittedBlockSink(
+	handler func(header data.HeaderHandler, body block.Body, hash []byte),
+	chStop chan struct{},
+) *committedBlockSink {
+	cbs := &committedBlockSink{
+		handler:  handler,
+		chBlocks: make(chan *committedBlock, committedBlockSinkChanSize),
+		chStop:   chStop,
+	}
+
+	go cbs.deliverBlocks()
+
+	return cbs
+}
+
+func (cbs *committedBlockSink) deliverBlocks() {
+	for {
+		select {
+		case <-cbs.chStop:
+			return
+		case cb := <-cbs.chBlocks:
+			cbs.handler(cb.header, cb.body, cb.hash)
+		}
+	}
+}
+
+// push adds the committed block in the sink buffer, without blocking. If the buffer is full, the block is dropped
+func (cbs *committedBlockSink) push(cb *committedBlock) {
+	select {
+	case cbs.chBlocks <- cb:
+	default:
+		atomic.AddUint64(&cbs.numDropped, 1)
+		log.Debug("committed block sink is not ready, block dropped",
+			"round", cb.header.GetRound(),
+			"nonce", cb.header.GetNonce(),
+		)
+	}
+}
+
+func (cbs *committedBlockSink) getNumDropped() uint64 {
+	return atomic.LoadUint64(&cbs.numDropped)
+}
@@ -0,0 +1,120 @@
+package block
+
+import (
+	"math/big"
+
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/hashing"
+	"github.com/ElrondNetwork/elrond-go/storage"
+	"github.com/ElrondNetwork/elrond-go/storage/lrucache"
+)
+
+// decodedHeadersCache keeps a bounded number of decoded headers, keyed by the hash of their full marshalized content,
+// so that decoding the same header bytes again does not unmarshal them from scratch. The cached headers are never
+// handed out directly: each hit returns an independent copy
+type decodedHeadersCache struct {
+	cache  storage.Cacher
+	hasher hashing.Hasher
+}
+
+func newDecodedHeadersCache(size int, hasher hashing.Hasher) (*decodedHeadersCache, error) {
+	cache, err := lrucache.NewCache(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decodedHeadersCache{
+		cache:  cache,
+		hasher: hasher,
+	}, nil
+}
+
+// get returns a copy of the header previously decoded from the given bytes, if it is still cached
+func (dhc *decodedHeadersCache) get(headerBytes []byte) (data.HeaderHandler, bool) {
+	value, ok := dhc.cache.Get(dhc.hasher.Compute(string(headerBytes)))
+	if !ok {
+		return nil, false
+	}
+
+	header, ok := value.(data.HeaderHandler)
+	if !ok {
+		return nil, false
+	}
+
+	return cloneDecodedHeader(header)
+}
+
+// put keeps a copy of the header decoded from the given bytes. Headers which can not be deeply copied are not cached
+func (dhc *decodedHeadersCache) put(headerBytes []byte, header data.HeaderHandler) {
+	headerCopy, ok := cloneDecodedHeader(header)
+	if !ok {
+		return
+	}
+
+	dhc.cache.Put(dhc.hasher.Compute(string(headerBytes)), headerCopy, len(headerBytes))
+}
+
+// cloneDecodedHeader returns a deep copy of the given header, which shares no slices or pointers with it
+func cloneDecodedHeader(header data.HeaderHandler) (data.HeaderHandler, bool) {
+	shardHeader, ok := header.(*block.Header)
+	if !ok || shardHeader == nil {
+		return nil, false
+	}
+
+	headerCopy := *shardHeader
+	headerCopy.PrevHash = cloneBytes(shardHeader.PrevHash)
+	headerCopy.PrevRandSeed = cloneBytes(shardHeader.PrevRandSeed)
+	headerCopy.RandSeed = cloneBytes(shardHeader.RandSeed)
+	headerCopy.PubKeysBitmap = cloneBytes(shardHeader.PubKeysBitmap)
+	headerCopy.Signature = cloneBytes(shardHeader.Signature)
+	headerCopy.LeaderSignature = cloneBytes(shardHeader.LeaderSignature)
+	headerCopy.RootHash = cloneBytes(shardHeader.RootHash)
+	headerCopy.EpochStartMetaHash = cloneBytes(shardHeader.EpochStartMetaHash)
+	headerCopy.ReceiptsHash = cloneBytes(shardHeader.ReceiptsHash)
+	headerCopy.ChainID = cloneBytes(shardHeader.ChainID)
+	headerCopy.SoftwareVersion = cloneBytes(shardHeader.SoftwareVersion)
+	headerCopy.Reserved = cloneBytes(shardHeader.Reserved)
+	headerCopy.AccumulatedFees = cloneBigInt(shardHeader.AccumulatedFees)
+	headerCopy.DeveloperFees = cloneBigInt(shardHeader.DeveloperFees)
+
+	if shardHeader.MiniBlockHeaders != nil {
+		headerCopy.MiniBlockHeaders = make([]block.MiniBlockHeader, len(shardHeader.MiniBlockHeaders))
+		for i, mbHeader := range shardHeader.MiniBlockHeaders {
+			headerCopy.MiniBlockHeaders[i] = mbHeader
+			headerCopy.MiniBlockHeaders[i].Hash = cloneBytes(mbHeader.Hash)
+			headerCopy.MiniBlockHeaders[i].Reserved = cloneBytes(mbHeader.Reserved)
+		}
+	}
+	if shardHeader.PeerChanges != nil {
+		headerCopy.PeerChanges = make([]block.PeerChange, len(shardHeader.PeerChanges))
+		for i, peerChange := range shardHeader.PeerChanges {
+			headerCopy.PeerChanges[i] = peerChange
+			headerCopy.PeerChanges[i].PubKey = cloneBytes(peerChange.PubKey)
+		}
+	}
+	if shardHeader.MetaBlockHashes != nil {
+		headerCopy.MetaBlockHashes = make([][]byte, len(shardHeader.MetaBlockHashes))
+		for i, metaBlockHash := range shardHeader.MetaBlockHashes {
+			headerCopy.MetaBlockHashes[i] = cloneBytes(metaBlockHash)
+		}
+	}
+
+	return &headerCopy, true
+}
+
+func cloneBytes(buff []byte) []byte {
+	if buff == nil {
+		return nil
+	}
+
+	return append(make([]byte, 0, len(buff)), buff...)
+}
+
+func cloneBigInt(value *big.Int) *big.Int {
+	if value == nil {
+		return nil
+	}
+
+	return big.NewInt(0).Set(value)
+}
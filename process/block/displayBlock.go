@@ -52,6 +52,25 @@ func (txc *transactionCounter) subtractRestoredTxs(txsNr int) {
 }
 
 // displayLogInfo writes to the output information about the block and transactions
+// logPoolCounts displays the pools counts. The transactions pool counts are displayed at info level only when the total
+// number of transactions in pool exceeds the given threshold, otherwise they are displayed at debug level
+func logPoolCounts(
+	log logger.Logger,
+	poolLogThreshold uint64,
+	txCounts counting.Counts,
+	rewardCounts counting.Counts,
+	unsignedCounts counting.Counts,
+) {
+	logTxCounts := log.Debug
+	if uint64(txCounts.GetTotal()) > poolLogThreshold {
+		logTxCounts = log.Info
+	}
+
+	logTxCounts("total txs in pool", "counts", txCounts.String())
+	log.Debug("total txs in rewards pool", "counts", rewardCounts.String())
+	log.Debug("total txs in unsigned pool", "counts", unsignedCounts.String())
+}
+
 func (txc *transactionCounter) displayLogInfo(
 	header *block.Header,
 	body *block.Body,
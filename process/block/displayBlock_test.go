@@ -3,8 +3,10 @@ package block
 import (
 	"testing"
 
+	"github.com/ElrondNetwork/elrond-go/core/counting"
 	"github.com/ElrondNetwork/elrond-go/data/block"
 	"github.com/ElrondNetwork/elrond-go/display"
+	"github.com/ElrondNetwork/elrond-go/process/mock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,3 +61,44 @@ func TestDisplayBlock_DisplayTxBlockBody(t *testing.T) {
 	assert.NotNil(t, lines)
 	assert.Equal(t, len(miniblock.TxHashes), len(lines))
 }
+
+func createCountsWithTotal(total int64) counting.Counts {
+	counts := counting.NewConcurrentShardedCounts()
+	counts.PutCounts("0", total)
+
+	return counts
+}
+
+func TestLogPoolCounts_BelowThresholdShouldLogAtDebugLevel(t *testing.T) {
+	t.Parallel()
+
+	loggedLevels := make(map[string]string)
+	log := &mock.LoggerStub{
+		LogCalled: func(level string, message string, args ...interface{}) {
+			loggedLevels[message] = level
+		},
+	}
+
+	logPoolCounts(log, 100, createCountsWithTotal(100), createCountsWithTotal(0), createCountsWithTotal(0))
+
+	assert.Equal(t, "DEBUG", loggedLevels["total txs in pool"])
+	assert.Equal(t, "DEBUG", loggedLevels["total txs in rewards pool"])
+	assert.Equal(t, "DEBUG", loggedLevels["total txs in unsigned pool"])
+}
+
+func TestLogPoolCounts_AboveThresholdShouldLogAtInfoLevel(t *testing.T) {
+	t.Parallel()
+
+	loggedLevels := make(map[string]string)
+	log := &mock.LoggerStub{
+		LogCalled: func(level string, message string, args ...interface{}) {
+			loggedLevels[message] = level
+		},
+	}
+
+	logPoolCounts(log, 100, createCountsWithTotal(101), createCountsWithTotal(0), createCountsWithTotal(0))
+
+	assert.Equal(t, "INFO", loggedLevels["total txs in pool"])
+	assert.Equal(t, "DEBUG", loggedLevels["total txs in rewards pool"])
+	assert.Equal(t, "DEBUG", loggedLevels["total txs in unsigned pool"])
+}
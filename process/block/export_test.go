@@ -1,7 +1,9 @@
 package block
 
 import (
+	"math"
 	"sync"
+	"time"
 
 	"github.com/ElrondNetwork/elrond-go/core"
 	"github.com/ElrondNetwork/elrond-go/core/check"
@@ -36,8 +38,8 @@ func (bp *baseProcessor) RemoveHeadersBehindNonceFromPools(
 	shouldRemoveBlockBody bool,
 	shardId uint32,
 	nonce uint64,
-) {
-	bp.removeHeadersBehindNonceFromPools(shouldRemoveBlockBody, shardId, nonce)
+) uint64 {
+	return bp.removeHeadersBehindNonceFromPools(shouldRemoveBlockBody, shardId, nonce)
 }
 
 func (sp *shardProcessor) ReceivedMetaBlock(header data.HeaderHandler, metaBlockHash []byte) {
@@ -45,13 +47,22 @@ func (sp *shardProcessor) ReceivedMetaBlock(header data.HeaderHandler, metaBlock
 }
 
 func (sp *shardProcessor) CreateMiniBlocks(haveTime func() bool) (*block.Body, error) {
-	return sp.createMiniBlocks(haveTime)
+	body, _, err := sp.createMiniBlocks(haveTime, 0)
+	return body, err
+}
+
+func (sp *shardProcessor) CreateMiniBlocksWithBodyComposition(haveTime func() bool, epoch uint32) (*block.Body, process.BodyComposition, error) {
+	return sp.createMiniBlocks(haveTime, epoch)
 }
 
 func (sp *shardProcessor) GetOrderedProcessedMetaBlocksFromHeader(header *block.Header) ([]data.HeaderHandler, error) {
 	return sp.getOrderedProcessedMetaBlocksFromHeader(header)
 }
 
+func (sp *shardProcessor) GetOrderedProcessedMetaBlocksAndHashesFromHeader(header *block.Header) ([]data.HeaderHandler, [][]byte, error) {
+	return sp.getOrderedProcessedMetaBlocksAndHashesFromHeader(header)
+}
+
 func (sp *shardProcessor) UpdateCrossShardInfo(processedMetaHdrs []data.HeaderHandler) error {
 	return sp.updateCrossShardInfo(processedMetaHdrs)
 }
@@ -77,6 +88,7 @@ func NewShardProcessorEmptyWith3shards(
 	accountsDb := make(map[state.AccountsDbIdentifier]state.AccountsAdapter)
 	accountsDb[state.UserAccountsState] = &mock.AccountsStub{}
 
+	blockCreationPolicy, _ := NewBlockCreationPolicy(ArgsBlockCreationPolicy{ProduceEmptyBlocks: true})
 	arguments := ArgShardProcessor{
 		ArgBaseProcessor: ArgBaseProcessor{
 			AccountsDB:        accountsDb,
@@ -108,7 +120,15 @@ func NewShardProcessorEmptyWith3shards(
 			HeaderIntegrityVerifier: &mock.HeaderIntegrityVerifierStub{},
 			HistoryRepository:       &testscommon.HistoryRepositoryStub{},
 			EpochNotifier:           &mock.EpochNotifierStub{},
+
+			MiniBlockReservedCheckEnableEpoch: math.MaxUint32,
 		},
+		IncludeEmptyAttestedMetaBlocks:  true,
+		BlockCreationPolicy:             blockCreationPolicy,
+		HeaderTxCountCheckEnableEpoch:   math.MaxUint32,
+		BodyShardIdsCheckEnableEpoch:    math.MaxUint32,
+		HeaderEpochCheckEnableEpoch:     math.MaxUint32,
+		GenesisRandSeedCheckEnableEpoch: math.MaxUint32,
 	}
 	shardProc, err := NewShardProcessor(arguments)
 	return shardProc, err
@@ -222,7 +242,7 @@ func (mp *metaProcessor) CheckShardHeadersFinality(highestNonceHdrs map[uint32]d
 }
 
 func (mp *metaProcessor) CheckHeaderBodyCorrelation(hdr *block.Header, body *block.Body) error {
-	return mp.checkHeaderBodyCorrelation(hdr.MiniBlockHeaders, body)
+	return mp.checkHeaderBodyCorrelation(hdr.MiniBlockHeaders, body, hdr.GetEpoch())
 }
 
 func (bp *baseProcessor) IsHdrConstructionValid(currHdr, prevHdr data.HeaderHandler) error {
@@ -246,11 +266,23 @@ func (sp *shardProcessor) SaveLastNotarizedHeader(shardId uint32, processedHdrs
 }
 
 func (sp *shardProcessor) CheckHeaderBodyCorrelation(hdr *block.Header, body *block.Body) error {
-	return sp.checkHeaderBodyCorrelation(hdr.MiniBlockHeaders, body)
+	return sp.checkHeaderBodyCorrelation(hdr.MiniBlockHeaders, body, hdr.GetEpoch())
+}
+
+func (sp *shardProcessor) SetSelfProposedBody(body *block.Body, round uint64) {
+	sp.setSelfProposedBody(body, round)
 }
 
-func (sp *shardProcessor) CheckAndRequestIfMetaHeadersMissing() {
-	sp.checkAndRequestIfMetaHeadersMissing()
+func (sp *shardProcessor) IsSelfProposedBody(header data.HeaderHandler, body *block.Body) bool {
+	return sp.isSelfProposedBody(header, body)
+}
+
+func (sp *shardProcessor) CheckAndRequestIfMetaHeadersMissing() error {
+	return sp.checkAndRequestIfMetaHeadersMissing()
+}
+
+func (sp *shardProcessor) WaitForMetaHdrHashes(waitTime time.Duration) error {
+	return sp.waitForMetaHdrHashes(waitTime)
 }
 
 func (sp *shardProcessor) GetHashAndHdrStruct(header data.HeaderHandler, hash []byte) *hashAndHdr {
@@ -268,7 +300,7 @@ func (sp *shardProcessor) RequestMissingFinalityAttestingHeaders() uint32 {
 }
 
 func (sp *shardProcessor) CheckMetaHeadersValidityAndFinality() error {
-	return sp.checkMetaHeadersValidityAndFinality()
+	return sp.checkMetaHeadersValidityAndFinality(true)
 }
 
 func (sp *shardProcessor) CreateAndProcessMiniBlocksDstMe(
@@ -297,7 +329,7 @@ func (sp *shardProcessor) GetHighestHdrForOwnShardFromMetachain(processedHdrs []
 func (sp *shardProcessor) RestoreMetaBlockIntoPool(
 	miniBlockHashes map[string]uint32,
 	metaBlockHashes [][]byte,
-) error {
+) ([][]byte, error) {
 	return sp.restoreMetaBlockIntoPool(miniBlockHashes, metaBlockHashes)
 }
 
@@ -335,6 +367,14 @@ func (mp *metaProcessor) ApplyBodyToHeader(metaHdr *block.MetaBlock, body *block
 	return mp.applyBodyToHeader(metaHdr, body)
 }
 
+func (sp *shardProcessor) IndexBlockIfNeeded(body data.BodyHandler, headerHash []byte, header data.HeaderHandler, lastBlockHeader data.HeaderHandler) {
+	sp.indexBlockIfNeeded(body, headerHash, header, lastBlockHeader)
+}
+
+func (sp *shardProcessor) CheckMiniBlocksReceiverShardIsSelf(body *block.Body, miniBlockHashesDstMe map[string]uint32) error {
+	return sp.checkMiniBlocksReceiverShardIsSelf(body, miniBlockHashesDstMe)
+}
+
 func (sp *shardProcessor) ApplyBodyToHeader(shardHdr *block.Header, body *block.Body) (*block.Body, error) {
 	return sp.applyBodyToHeader(shardHdr, body)
 }
@@ -369,3 +409,27 @@ func (mp *metaProcessor) RequestShardHeadersIfNeeded(hdrsAddedForShard map[uint3
 func (bp *baseProcessor) AddHeaderIntoTrackerPool(nonce uint64, shardID uint32) {
 	bp.addHeaderIntoTrackerPool(nonce, shardID)
 }
+
+func (sp *shardProcessor) StartBackgroundRoutine(handler func()) {
+	sp.startBackgroundRoutine(handler)
+}
+
+func (sp *shardProcessor) GetOrderedMetaBlocks() ([]data.HeaderHandler, [][]byte, error) {
+	return sp.getOrderedMetaBlocks()
+}
+
+func (sp *shardProcessor) RequestMetaHeaders(shardHeader *block.Header) (uint32, uint32) {
+	return sp.requestMetaHeaders(shardHeader)
+}
+
+func (sp *shardProcessor) CheckHeaderEpochAgainstAttestedMetaBlocks(header *block.Header) error {
+	return sp.checkHeaderEpochAgainstAttestedMetaBlocks(header)
+}
+
+const CommittedBlockSinkChanSize = committedBlockSinkChanSize
+
+const MaxPausedMetaBlocks = maxPausedMetaBlocks
+
+func (sp *shardProcessor) MetaBlockFinality() uint32 {
+	return sp.metaBlockFinality
+}
@@ -0,0 +1,65 @@
+package block
+
+import "sync"
+
+// metaBlocksFirstSeen keeps, for a bounded number of meta blocks, the round in which each of them was first seen
+type metaBlocksFirstSeen struct {
+	maxMetaBlocks int
+	rounds        map[string]uint64
+	orderedHashes []string
+	mutRounds     sync.RWMutex
+}
+
+func newMetaBlocksFirstSeen(maxMetaBlocks int) *metaBlocksFirstSeen {
+	return &metaBlocksFirstSeen{
+		maxMetaBlocks: maxMetaBlocks,
+		rounds:        make(map[string]uint64),
+		orderedHashes: make([]string, 0, maxMetaBlocks),
+	}
+}
+
+// add saves the given round for the given meta block hash, only if the meta block was not already seen
+func (mbfs *metaBlocksFirstSeen) add(metaBlockHash []byte, round uint64) {
+	mbfs.mutRounds.Lock()
+	defer mbfs.mutRounds.Unlock()
+
+	_, exists := mbfs.rounds[string(metaBlockHash)]
+	if exists {
+		return
+	}
+
+	mbfs.rounds[string(metaBlockHash)] = round
+	mbfs.orderedHashes = append(mbfs.orderedHashes, string(metaBlockHash))
+
+	if len(mbfs.orderedHashes) > mbfs.maxMetaBlocks {
+		delete(mbfs.rounds, mbfs.orderedHashes[0])
+		mbfs.orderedHashes = mbfs.orderedHashes[1:]
+	}
+}
+
+// get returns the round in which the given meta block was first seen and true, if the meta block is tracked
+func (mbfs *metaBlocksFirstSeen) get(metaBlockHash []byte) (uint64, bool) {
+	mbfs.mutRounds.RLock()
+	round, found := mbfs.rounds[string(metaBlockHash)]
+	mbfs.mutRounds.RUnlock()
+
+	return round, found
+}
+
+func (mbfs *metaBlocksFirstSeen) remove(metaBlockHash []byte) {
+	mbfs.mutRounds.Lock()
+	defer mbfs.mutRounds.Unlock()
+
+	_, exists := mbfs.rounds[string(metaBlockHash)]
+	if !exists {
+		return
+	}
+
+	delete(mbfs.rounds, string(metaBlockHash))
+	for i, hash := range mbfs.orderedHashes {
+		if hash == string(metaBlockHash) {
+			mbfs.orderedHashes = append(mbfs.orderedHashes[:i], mbfs.orderedHashes[i+1:]...)
+			break
+		}
+	}
+}
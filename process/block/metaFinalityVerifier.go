@@ -0,0 +1,75 @@
+package block
+
+import (
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+var _ process.MetaFinalityVerifier = (*metaFinalityVerifier)(nil)
+
+// ArgsMetaFinalityVerifier are the arguments needed to create a new meta finality verifier
+type ArgsMetaFinalityVerifier struct {
+	HeaderValidator process.HeaderConstructionValidator
+	Finality        uint32
+}
+
+type metaFinalityVerifier struct {
+	headerValidator process.HeaderConstructionValidator
+	finality        uint32
+}
+
+// NewMetaFinalityVerifier returns a meta finality verifier which considers a meta header final if there are
+// "K" valid meta headers constructed on top of it
+func NewMetaFinalityVerifier(args ArgsMetaFinalityVerifier) (*metaFinalityVerifier, error) {
+	if check.IfNil(args.HeaderValidator) {
+		return nil, process.ErrNilHeaderValidator
+	}
+	if args.Finality == 0 {
+		return nil, process.ErrZeroBlockFinality
+	}
+
+	return &metaFinalityVerifier{
+		headerValidator: args.HeaderValidator,
+		finality:        args.Finality,
+	}, nil
+}
+
+// IsMetaHeaderFinal returns true if there are "K" consecutive meta headers correctly constructed on top of the given one
+func (mfv *metaFinalityVerifier) IsMetaHeaderFinal(
+	metaHdr data.HeaderHandler,
+	getMetaHeadersWithNonce func(nonce uint64) []data.HeaderHandler,
+) bool {
+	if check.IfNil(metaHdr) || getMetaHeadersWithNonce == nil {
+		return false
+	}
+
+	lastVerifiedHdr := metaHdr
+	for nextBlocksVerified := uint32(0); nextBlocksVerified < mfv.finality; nextBlocksVerified++ {
+		foundNextHdr := false
+		nextMetaHdrs := getMetaHeadersWithNonce(lastVerifiedHdr.GetNonce() + 1)
+		for _, nextMetaHdr := range nextMetaHdrs {
+			err := mfv.headerValidator.IsHeaderConstructionValid(nextMetaHdr, lastVerifiedHdr)
+			if err != nil {
+				log.Trace("IsMetaHeaderFinal -> IsHeaderConstructionValid",
+					"error", err.Error())
+				continue
+			}
+
+			lastVerifiedHdr = nextMetaHdr
+			foundNextHdr = true
+			break
+		}
+
+		if !foundNextHdr {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (mfv *metaFinalityVerifier) IsInterfaceNil() bool {
+	return mfv == nil
+}
@@ -0,0 +1,115 @@
+package block_test
+
+import (
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/process"
+	blproc "github.com/ElrondNetwork/elrond-go/process/block"
+	"github.com/ElrondNetwork/elrond-go/process/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMockArgsMetaFinalityVerifier() blproc.ArgsMetaFinalityVerifier {
+	headerValidator, _ := blproc.NewHeaderValidator(blproc.ArgsHeaderValidator{
+		Hasher:      &mock.HasherMock{},
+		Marshalizer: &mock.MarshalizerMock{},
+	})
+
+	return blproc.ArgsMetaFinalityVerifier{
+		HeaderValidator: headerValidator,
+		Finality:        1,
+	}
+}
+
+func createNextMetaBlock(prevMetaBlock *block.MetaBlock) *block.MetaBlock {
+	prevHash, _ := core.CalculateHash(&mock.MarshalizerMock{}, &mock.HasherMock{}, prevMetaBlock)
+
+	return &block.MetaBlock{
+		Nonce:    prevMetaBlock.Nonce + 1,
+		Round:    prevMetaBlock.Round + 1,
+		PrevHash: prevHash,
+	}
+}
+
+func TestNewMetaFinalityVerifier_NilHeaderValidatorShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsMetaFinalityVerifier()
+	args.HeaderValidator = nil
+	mfv, err := blproc.NewMetaFinalityVerifier(args)
+
+	assert.Nil(t, mfv)
+	assert.Equal(t, process.ErrNilHeaderValidator, err)
+}
+
+func TestNewMetaFinalityVerifier_ZeroFinalityShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsMetaFinalityVerifier()
+	args.Finality = 0
+	mfv, err := blproc.NewMetaFinalityVerifier(args)
+
+	assert.Nil(t, mfv)
+	assert.Equal(t, process.ErrZeroBlockFinality, err)
+}
+
+func TestNewMetaFinalityVerifier_ShouldWork(t *testing.T) {
+	t.Parallel()
+
+	mfv, err := blproc.NewMetaFinalityVerifier(createMockArgsMetaFinalityVerifier())
+
+	assert.Nil(t, err)
+	assert.False(t, mfv.IsInterfaceNil())
+}
+
+func TestMetaFinalityVerifier_IsMetaHeaderFinalWithoutNextHeaderShouldReturnFalse(t *testing.T) {
+	t.Parallel()
+
+	mfv, _ := blproc.NewMetaFinalityVerifier(createMockArgsMetaFinalityVerifier())
+	getMetaHeadersWithNonce := func(nonce uint64) []data.HeaderHandler {
+		return nil
+	}
+
+	assert.False(t, mfv.IsMetaHeaderFinal(&block.MetaBlock{Nonce: 1, Round: 1}, getMetaHeadersWithNonce))
+}
+
+func TestMetaFinalityVerifier_IsMetaHeaderFinalWithWrongConstructedNextHeaderShouldReturnFalse(t *testing.T) {
+	t.Parallel()
+
+	mfv, _ := blproc.NewMetaFinalityVerifier(createMockArgsMetaFinalityVerifier())
+	metaBlock := &block.MetaBlock{Nonce: 1, Round: 1}
+	nextMetaBlock := createNextMetaBlock(metaBlock)
+	nextMetaBlock.PrevHash = []byte("wrong prev hash")
+	getMetaHeadersWithNonce := func(nonce uint64) []data.HeaderHandler {
+		return []data.HeaderHandler{nextMetaBlock}
+	}
+
+	assert.False(t, mfv.IsMetaHeaderFinal(metaBlock, getMetaHeadersWithNonce))
+}
+
+func TestMetaFinalityVerifier_IsMetaHeaderFinalShouldWork(t *testing.T) {
+	t.Parallel()
+
+	args := createMockArgsMetaFinalityVerifier()
+	args.Finality = 2
+	mfv, _ := blproc.NewMetaFinalityVerifier(args)
+
+	metaBlock := &block.MetaBlock{Nonce: 1, Round: 1}
+	metaBlocksByNonce := make(map[uint64][]data.HeaderHandler)
+	lastMetaBlock := metaBlock
+	for i := 0; i < 2; i++ {
+		lastMetaBlock = createNextMetaBlock(lastMetaBlock)
+		metaBlocksByNonce[lastMetaBlock.Nonce] = []data.HeaderHandler{lastMetaBlock}
+	}
+	getMetaHeadersWithNonce := func(nonce uint64) []data.HeaderHandler {
+		return metaBlocksByNonce[nonce]
+	}
+
+	assert.True(t, mfv.IsMetaHeaderFinal(metaBlock, getMetaHeadersWithNonce))
+
+	delete(metaBlocksByNonce, lastMetaBlock.Nonce)
+	assert.False(t, mfv.IsMetaHeaderFinal(metaBlock, getMetaHeadersWithNonce))
+}
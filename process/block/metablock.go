@@ -109,6 +109,8 @@ func NewMetaProcessor(arguments ArgMetaProcessor) (*metaProcessor, error) {
 		headerIntegrityVerifier: arguments.HeaderIntegrityVerifier,
 		historyRepo:             arguments.HistoryRepository,
 		epochNotifier:           arguments.EpochNotifier,
+
+		miniBlockReservedCheckEnableEpoch: arguments.MiniBlockReservedCheckEnableEpoch,
 	}
 
 	mp := metaProcessor{
@@ -190,7 +192,7 @@ func (mp *metaProcessor) ProcessBlock(
 		return process.ErrWrongTypeAssertion
 	}
 
-	err = mp.checkHeaderBodyCorrelation(header.MiniBlockHeaders, body)
+	err = mp.checkHeaderBodyCorrelation(header.MiniBlockHeaders, body, header.GetEpoch())
 	if err != nil {
 		return err
 	}
@@ -914,7 +916,7 @@ func (mp *metaProcessor) createMiniBlocks(
 		)
 	}
 
-	mbsFromMe := mp.txCoordinator.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbsFromMe := mp.txCoordinator.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 	if len(mbsFromMe) > 0 {
 		miniBlocks = append(miniBlocks, mbsFromMe...)
 
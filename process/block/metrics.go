@@ -48,7 +48,8 @@ func getMetricsFromBlockBody(
 	body *block.Body,
 	marshalizer marshal.Marshalizer,
 	appStatusHandler core.AppStatusHandler,
-) {
+) error {
+	var lastErr error
 	mbLen := len(body.MiniBlocks)
 	miniblocksSize := uint64(0)
 	totalTxCount := 0
@@ -56,13 +57,18 @@ func getMetricsFromBlockBody(
 		totalTxCount += len(body.MiniBlocks[i].TxHashes)
 
 		marshalizedBlock, err := marshalizer.Marshal(body.MiniBlocks[i])
-		if err == nil {
-			miniblocksSize += uint64(len(marshalizedBlock))
+		if err != nil {
+			lastErr = err
+			continue
 		}
+
+		miniblocksSize += uint64(len(marshalizedBlock))
 	}
 	appStatusHandler.SetUInt64Value(core.MetricNumTxInBlock, uint64(totalTxCount))
 	appStatusHandler.SetUInt64Value(core.MetricNumMiniBlocks, uint64(mbLen))
 	appStatusHandler.SetUInt64Value(core.MetricMiniBlocksSize, miniblocksSize)
+
+	return lastErr
 }
 
 func getMetricsFromHeader(
@@ -70,7 +76,7 @@ func getMetricsFromHeader(
 	numTxWithDst uint64,
 	marshalizer marshal.Marshalizer,
 	appStatusHandler core.AppStatusHandler,
-) {
+) error {
 	headerSize := uint64(0)
 	marshalizedHeader, err := marshalizer.Marshal(header)
 	if err == nil {
@@ -79,6 +85,8 @@ func getMetricsFromHeader(
 
 	appStatusHandler.SetUInt64Value(core.MetricHeaderSize, headerSize)
 	appStatusHandler.SetUInt64Value(core.MetricTxPoolLoad, numTxWithDst)
+
+	return err
 }
 
 func saveMetricsForCommittedShardBlock(
@@ -0,0 +1,72 @@
+package block
+
+import (
+	"sync"
+
+	"github.com/ElrondNetwork/elrond-go/data"
+)
+
+type pausedMetaBlock struct {
+	header data.HeaderHandler
+	hash   []byte
+}
+
+// pausedMetaBlocks keeps, while the meta blocks processing is paused, a bounded number of the received meta blocks so
+// that they could be processed on resume. The meta blocks received when the buffer is full are dropped
+type pausedMetaBlocks struct {
+	maxMetaBlocks int
+	isPaused      bool
+	metaBlocks    []*pausedMetaBlock
+	numDropped    uint64
+	mutMetaBlocks sync.Mutex
+}
+
+func newPausedMetaBlocks(maxMetaBlocks int) *pausedMetaBlocks {
+	return &pausedMetaBlocks{
+		maxMetaBlocks: maxMetaBlocks,
+		metaBlocks:    make([]*pausedMetaBlock, 0),
+	}
+}
+
+// setPaused pauses or resumes the meta blocks processing. On resume, it returns the meta blocks buffered while paused
+func (pmb *pausedMetaBlocks) setPaused(isPaused bool) []*pausedMetaBlock {
+	pmb.mutMetaBlocks.Lock()
+	defer pmb.mutMetaBlocks.Unlock()
+
+	pmb.isPaused = isPaused
+	if isPaused {
+		return nil
+	}
+
+	bufferedMetaBlocks := pmb.metaBlocks
+	pmb.metaBlocks = make([]*pausedMetaBlock, 0)
+
+	return bufferedMetaBlocks
+}
+
+// addIfPaused buffers, or drops if the buffer is full, the given meta block and returns true if the meta blocks
+// processing is paused. It returns false, without buffering the meta block, otherwise
+func (pmb *pausedMetaBlocks) addIfPaused(header data.HeaderHandler, hash []byte) bool {
+	pmb.mutMetaBlocks.Lock()
+	defer pmb.mutMetaBlocks.Unlock()
+
+	if !pmb.isPaused {
+		return false
+	}
+
+	if len(pmb.metaBlocks) >= pmb.maxMetaBlocks {
+		pmb.numDropped++
+		return true
+	}
+
+	pmb.metaBlocks = append(pmb.metaBlocks, &pausedMetaBlock{header: header, hash: hash})
+
+	return true
+}
+
+func (pmb *pausedMetaBlocks) getNumDropped() uint64 {
+	pmb.mutMetaBlocks.Lock()
+	defer pmb.mutMetaBlocks.Unlock()
+
+	return pmb.numDropped
+}
@@ -45,6 +45,8 @@ type txsPoolsCleaner struct {
 	unsignedTransactionsPool dataRetriever.ShardedDataCacherNotifier
 	rounder                  process.Rounder
 	shardCoordinator         sharding.Coordinator
+	cleanTxsPoolsMinFill     uint64
+	cleanInterval            time.Duration
 
 	mutMapTxsRounds sync.RWMutex
 	mapTxsRounds    map[string]*txInfo
@@ -52,12 +54,15 @@ type txsPoolsCleaner struct {
 	cancelFunc      func()
 }
 
-// NewTxsPoolsCleaner will return a new txs pools cleaner
+// NewTxsPoolsCleaner will return a new txs pools cleaner. The cleaning is done once every cleanInterval, only when the
+// total number of txs from the pools exceeds cleanTxsPoolsMinFill (0 means that the cleaning is always done)
 func NewTxsPoolsCleaner(
 	addressPubkeyConverter core.PubkeyConverter,
 	dataPool dataRetriever.PoolsHolder,
 	rounder process.Rounder,
 	shardCoordinator sharding.Coordinator,
+	cleanTxsPoolsMinFill uint64,
+	cleanInterval time.Duration,
 ) (*txsPoolsCleaner, error) {
 
 	if check.IfNil(addressPubkeyConverter) {
@@ -81,6 +86,9 @@ func NewTxsPoolsCleaner(
 	if check.IfNil(shardCoordinator) {
 		return nil, process.ErrNilShardCoordinator
 	}
+	if cleanInterval <= 0 {
+		return nil, fmt.Errorf("%w: %v", process.ErrInvalidTxsPoolsCleanInterval, cleanInterval)
+	}
 
 	tpc := txsPoolsCleaner{
 		addressPubkeyConverter:   addressPubkeyConverter,
@@ -89,6 +97,8 @@ func NewTxsPoolsCleaner(
 		unsignedTransactionsPool: dataPool.UnsignedTransactions(),
 		rounder:                  rounder,
 		shardCoordinator:         shardCoordinator,
+		cleanTxsPoolsMinFill:     cleanTxsPoolsMinFill,
+		cleanInterval:            cleanInterval,
 	}
 
 	tpc.mapTxsRounds = make(map[string]*txInfo)
@@ -115,19 +125,61 @@ func (tpc *txsPoolsCleaner) cleanTxsPools(ctx context.Context) {
 		case <-ctx.Done():
 			log.Debug("txsPoolsCleaner's go routine is stopping...")
 			return
-		case <-time.After(sleepTime):
+		case <-time.After(tpc.cleanInterval):
 		}
 
 		startTime := time.Now()
-		numTxsInMap := tpc.cleanTxsPoolsIfNeeded()
+		numTxsInMap, numTxsCleaned, wasCleaned := tpc.cleanTxsPoolsIfFillExceeded()
 		elapsedTime := time.Since(startTime)
 
 		log.Debug("txsPoolsCleaner.cleanTxsPools",
 			"num txs in map", numTxsInMap,
+			"num txs cleaned", numTxsCleaned,
+			"was cleaned", wasCleaned,
 			"elapsed time", elapsedTime)
 	}
 }
 
+// cleanTxsPoolsIfFillExceeded returns the number of txs left in the map, the number of cleaned txs and whether the
+// cleaning was done
+func (tpc *txsPoolsCleaner) cleanTxsPoolsIfFillExceeded() (int, int, bool) {
+	numTxsFromPools := tpc.getNumTxsFromPools()
+	if numTxsFromPools <= tpc.cleanTxsPoolsMinFill && tpc.cleanTxsPoolsMinFill > 0 {
+		tpc.mutMapTxsRounds.RLock()
+		numTxsInMap := len(tpc.mapTxsRounds)
+		tpc.mutMapTxsRounds.RUnlock()
+
+		log.Trace("txsPoolsCleaner.cleanTxsPoolsIfFillExceeded: skipped",
+			"num txs from pools", numTxsFromPools,
+			"min fill", tpc.cleanTxsPoolsMinFill)
+
+		return numTxsInMap, 0, false
+	}
+
+	numTxsInMap, numTxsCleaned := tpc.cleanTxsPoolsIfNeeded()
+
+	return numTxsInMap, numTxsCleaned, true
+}
+
+func (tpc *txsPoolsCleaner) getNumTxsFromPools() uint64 {
+	numTxs := uint64(0)
+	numTxs += getNumTxsFromPool(tpc.blockTransactionsPool)
+	numTxs += getNumTxsFromPool(tpc.rewardTransactionsPool)
+	numTxs += getNumTxsFromPool(tpc.unsignedTransactionsPool)
+
+	return numTxs
+}
+
+func getNumTxsFromPool(txsPool dataRetriever.ShardedDataCacherNotifier) uint64 {
+	// the null counts implementation reports a negative total, which should not lower the total of the other pools
+	numTxs := txsPool.GetCounts().GetTotal()
+	if numTxs < 0 {
+		return 0
+	}
+
+	return uint64(numTxs)
+}
+
 func (tpc *txsPoolsCleaner) receivedBlockTx(key []byte, value interface{}) {
 	if key == nil {
 		return
@@ -241,7 +293,8 @@ func (tpc *txsPoolsCleaner) processReceivedTx(
 	}
 }
 
-func (tpc *txsPoolsCleaner) cleanTxsPoolsIfNeeded() int {
+// cleanTxsPoolsIfNeeded returns the number of txs left in the map and the number of txs cleaned from the pools
+func (tpc *txsPoolsCleaner) cleanTxsPoolsIfNeeded() (int, int) {
 	numTxsCleaned := 0
 	hashesToRemove := make(map[string]storage.Cacher)
 
@@ -299,7 +352,7 @@ func (tpc *txsPoolsCleaner) cleanTxsPoolsIfNeeded() int {
 			"elapsed time to remove txs from cacher", elapsedTime)
 	}
 
-	return numTxsRounds
+	return numTxsRounds, numTxsCleaned
 }
 
 func (tpc *txsPoolsCleaner) getTransactionPool(txType int8) dataRetriever.ShardedDataCacherNotifier {
@@ -1,8 +1,11 @@
 package poolsCleaner
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/ElrondNetwork/elrond-go/core/counting"
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
 	"github.com/ElrondNetwork/elrond-go/dataRetriever"
 	"github.com/ElrondNetwork/elrond-go/process"
@@ -17,7 +20,7 @@ func TestNewTxsPoolsCleaner_NilAddrConverterErr(t *testing.T) {
 	t.Parallel()
 
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		nil, testscommon.NewPoolsHolderMock(), &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(),
+		nil, testscommon.NewPoolsHolderMock(), &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilPubkeyConverter, err)
@@ -27,7 +30,7 @@ func TestNewTxsPoolsCleaner_NilDataPoolHolderErr(t *testing.T) {
 	t.Parallel()
 
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, nil, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(),
+		&mock.PubkeyConverterStub{}, nil, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilPoolsHolder, err)
@@ -42,7 +45,7 @@ func TestNewTxsPoolsCleaner_NilTxsPoolErr(t *testing.T) {
 		},
 	}
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(),
+		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilTransactionPool, err)
@@ -60,7 +63,7 @@ func TestNewTxsPoolsCleaner_NilRewardTxsPoolErr(t *testing.T) {
 		},
 	}
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(),
+		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilRewardTxDataPool, err)
@@ -81,7 +84,7 @@ func TestNewTxsPoolsCleaner_NilUnsignedTxsPoolErr(t *testing.T) {
 		},
 	}
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(),
+		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilUnsignedTxDataPool, err)
@@ -102,7 +105,7 @@ func TestNewTxsPoolsCleaner_NilRounderErr(t *testing.T) {
 		},
 	}
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, dataPool, nil, mock.NewMultipleShardsCoordinatorMock(),
+		&mock.PubkeyConverterStub{}, dataPool, nil, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilRounder, err)
@@ -123,7 +126,7 @@ func TestNewTxsPoolsCleaner_NilShardCoordinatorErr(t *testing.T) {
 		},
 	}
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, nil,
+		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, nil, 0, time.Minute,
 	)
 	assert.Nil(t, txsPoolsCleaner)
 	assert.Equal(t, process.ErrNilShardCoordinator, err)
@@ -145,12 +148,28 @@ func TestNewTxsPoolsCleaner_ShouldWork(t *testing.T) {
 	}
 
 	txsPoolsCleaner, err := NewTxsPoolsCleaner(
-		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(),
+		&mock.PubkeyConverterStub{}, dataPool, &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, time.Minute,
 	)
 	assert.Nil(t, err)
 	assert.NotNil(t, txsPoolsCleaner)
 }
 
+func TestNewTxsPoolsCleaner_InvalidCleanIntervalErr(t *testing.T) {
+	t.Parallel()
+
+	txsPoolsCleaner, err := NewTxsPoolsCleaner(
+		&mock.PubkeyConverterStub{}, testscommon.NewPoolsHolderMock(), &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, 0,
+	)
+	assert.Nil(t, txsPoolsCleaner)
+	assert.True(t, errors.Is(err, process.ErrInvalidTxsPoolsCleanInterval))
+
+	txsPoolsCleaner, err = NewTxsPoolsCleaner(
+		&mock.PubkeyConverterStub{}, testscommon.NewPoolsHolderMock(), &mock.RounderMock{}, mock.NewMultipleShardsCoordinatorMock(), 0, -time.Second,
+	)
+	assert.Nil(t, txsPoolsCleaner)
+	assert.True(t, errors.Is(err, process.ErrInvalidTxsPoolsCleanInterval))
+}
+
 func TestGetShardFromAddress(t *testing.T) {
 	t.Parallel()
 
@@ -170,6 +189,8 @@ func TestGetShardFromAddress(t *testing.T) {
 				return expectedShard
 			},
 		},
+		0,
+		time.Minute,
 	)
 
 	emptyAddr := make([]byte, addrLen)
@@ -198,6 +219,8 @@ func TestReceivedBlockTx_ShouldBeAddedInMapTxsRounds(t *testing.T) {
 		},
 		&mock.RounderMock{},
 		&mock.CoordinatorStub{},
+		0,
+		time.Minute,
 	)
 
 	txWrap := &txcache.WrappedTransaction{
@@ -226,6 +249,8 @@ func TestReceivedRewardTx_ShouldBeAddedInMapTxsRounds(t *testing.T) {
 		},
 		&mock.RounderMock{},
 		&mock.CoordinatorStub{},
+		0,
+		time.Minute,
 	)
 
 	txKey := []byte("key")
@@ -257,6 +282,8 @@ func TestReceivedUnsignedTx_ShouldBeAddedInMapTxsRounds(t *testing.T) {
 				return 2
 			},
 		},
+		0,
+		time.Minute,
 	)
 
 	txKey := []byte("key")
@@ -288,6 +315,8 @@ func TestCleanTxsPoolsIfNeeded_CannotFindTxInPoolShouldBeRemovedFromMap(t *testi
 				return 2
 			},
 		},
+		0,
+		time.Minute,
 	)
 
 	txKey := []byte("key")
@@ -296,7 +325,7 @@ func TestCleanTxsPoolsIfNeeded_CannotFindTxInPoolShouldBeRemovedFromMap(t *testi
 	}
 	txsPoolsCleaner.receivedUnsignedTx(txKey, tx)
 
-	numTxsInMap := txsPoolsCleaner.cleanTxsPoolsIfNeeded()
+	numTxsInMap, _ := txsPoolsCleaner.cleanTxsPoolsIfNeeded()
 	assert.Equal(t, 0, numTxsInMap)
 }
 
@@ -325,6 +354,8 @@ func TestCleanTxsPoolsIfNeeded_RoundDiffTooSmallShouldNotBeRemoved(t *testing.T)
 				return 2
 			},
 		},
+		0,
+		time.Minute,
 	)
 
 	txKey := []byte("key")
@@ -333,7 +364,7 @@ func TestCleanTxsPoolsIfNeeded_RoundDiffTooSmallShouldNotBeRemoved(t *testing.T)
 	}
 	txsPoolsCleaner.receivedUnsignedTx(txKey, tx)
 
-	numTxsInMap := txsPoolsCleaner.cleanTxsPoolsIfNeeded()
+	numTxsInMap, _ := txsPoolsCleaner.cleanTxsPoolsIfNeeded()
 	assert.Equal(t, 1, numTxsInMap)
 }
 
@@ -369,6 +400,8 @@ func TestCleanTxsPoolsIfNeeded_RoundDiffTooBigShouldBeRemoved(t *testing.T) {
 				return 2
 			},
 		},
+		0,
+		time.Minute,
 	)
 
 	txKey := []byte("key")
@@ -380,8 +413,78 @@ func TestCleanTxsPoolsIfNeeded_RoundDiffTooBigShouldBeRemoved(t *testing.T) {
 	rounder.IndexCalled = func() int64 {
 		return process.MaxRoundsToKeepUnprocessedTransactions + 1
 	}
-	numTxsInMap := txsPoolsCleaner.cleanTxsPoolsIfNeeded()
+	numTxsInMap, numTxsCleaned := txsPoolsCleaner.cleanTxsPoolsIfNeeded()
 	assert.Equal(t, 0, numTxsInMap)
+	assert.Equal(t, 1, numTxsCleaned)
 	assert.Nil(t, txsPoolsCleaner.mapTxsRounds[string(txKey)])
 	assert.True(t, called)
 }
+
+func TestCleanTxsPoolsIfFillExceeded_ShouldCleanOnlyAboveMinFill(t *testing.T) {
+	t.Parallel()
+
+	rounder := &mock.RoundStub{IndexCalled: func() int64 {
+		return 0
+	}}
+	numTxsInPool := int64(0)
+	numRemoveCalls := 0
+	sndAddr := []byte("sndAddr")
+	cleanTxsPoolsMinFill := uint64(10)
+	txsPoolsCleaner, _ := NewTxsPoolsCleaner(
+		&mock.PubkeyConverterStub{},
+		&testscommon.PoolsHolderStub{
+			UnsignedTransactionsCalled: func() dataRetriever.ShardedDataCacherNotifier {
+				return &testscommon.ShardedDataStub{
+					ShardDataStoreCalled: func(cacheId string) (c storage.Cacher) {
+						return &testscommon.CacherStub{
+							GetCalled: func(key []byte) (value interface{}, ok bool) {
+								return nil, true
+							},
+							RemoveCalled: func(key []byte) {
+								numRemoveCalls++
+							},
+						}
+					},
+					GetCountsCalled: func() counting.CountsWithSize {
+						counts := counting.NewConcurrentShardedCountsWithSize()
+						counts.PutCounts("0", numTxsInPool, 0)
+
+						return counts
+					},
+				}
+			},
+		},
+		rounder,
+		&mock.CoordinatorStub{
+			ComputeIdCalled: func(address []byte) uint32 {
+				return 2
+			},
+		},
+		cleanTxsPoolsMinFill,
+		time.Minute,
+	)
+
+	txKey := []byte("key")
+	tx := &transaction.Transaction{
+		SndAddr: sndAddr,
+	}
+	txsPoolsCleaner.receivedUnsignedTx(txKey, tx)
+
+	rounder.IndexCalled = func() int64 {
+		return process.MaxRoundsToKeepUnprocessedTransactions + 1
+	}
+
+	numTxsInPool = int64(cleanTxsPoolsMinFill)
+	numTxsInMap, numTxsCleaned, wasCleaned := txsPoolsCleaner.cleanTxsPoolsIfFillExceeded()
+	assert.False(t, wasCleaned)
+	assert.Equal(t, 1, numTxsInMap)
+	assert.Equal(t, 0, numTxsCleaned)
+	assert.Equal(t, 0, numRemoveCalls)
+
+	numTxsInPool = int64(cleanTxsPoolsMinFill + 1)
+	numTxsInMap, numTxsCleaned, wasCleaned = txsPoolsCleaner.cleanTxsPoolsIfFillExceeded()
+	assert.True(t, wasCleaned)
+	assert.Equal(t, 0, numTxsInMap)
+	assert.Equal(t, 1, numTxsCleaned)
+	assert.Equal(t, 1, numRemoveCalls)
+}
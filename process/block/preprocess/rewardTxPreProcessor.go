@@ -415,6 +415,9 @@ func (rtp *rewardTxPreprocessor) getAllRewardTxsFromMiniBlock(
 // as long as it has time
 func (rtp *rewardTxPreprocessor) CreateAndProcessMiniBlocks(
 	_ func() bool,
+	_ uint64,
+	_ uint64,
+	_ uint32,
 ) (block.MiniBlockSlice, error) {
 	// rewards are created only by meta
 	return make(block.MiniBlockSlice, 0), nil
@@ -13,6 +13,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core"
 	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/sliceUtil"
+	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/block"
 	"github.com/ElrondNetwork/elrond-go/data/state"
@@ -49,6 +50,8 @@ type transactions struct {
 	accountsInfo         map[string]*txShardInfo
 	mutAccountsInfo      sync.RWMutex
 	emptyAddress         []byte
+	txsExecutionResults  []*process.TransactionExecutionResult
+	mutTxsExecResults    sync.RWMutex
 }
 
 // NewTransactionPreprocessor creates a new transaction preprocessor object
@@ -452,6 +455,9 @@ func (txs *transactions) processTxsFromMe(
 		isShardStuckFalse,
 		isMaxBlockSizeReachedFalse,
 		txsFromMe,
+		0,
+		0,
+		0,
 	)
 	if err != nil {
 		return err
@@ -552,6 +558,10 @@ func (txs *transactions) CreateBlockStarted() {
 	txs.mutAccountsInfo.Lock()
 	txs.accountsInfo = make(map[string]*txShardInfo)
 	txs.mutAccountsInfo.Unlock()
+
+	txs.mutTxsExecResults.Lock()
+	txs.txsExecutionResults = make([]*process.TransactionExecutionResult, 0)
+	txs.mutTxsExecResults.Unlock()
 }
 
 // RequestBlockTransactions request for transactions if missing from a block.Body
@@ -586,7 +596,7 @@ func (txs *transactions) processAndRemoveBadTransaction(
 	dstShardId uint32,
 ) error {
 
-	_, err := txs.txProcessor.ProcessTransaction(tx)
+	returnCode, err := txs.txProcessor.ProcessTransaction(tx)
 	isTxTargetedForDeletion := errors.Is(err, process.ErrLowerNonceInTransaction) || errors.Is(err, process.ErrInsufficientFee)
 	if isTxTargetedForDeletion {
 		strCache := process.ShardCacherIdentifier(sndShardId, dstShardId)
@@ -602,9 +612,26 @@ func (txs *transactions) processAndRemoveBadTransaction(
 	txs.txsForCurrBlock.txHashAndInfo[string(txHash)] = &txInfo{tx: tx, txShardInfo: txShardInfoToSet}
 	txs.txsForCurrBlock.mutTxsForBlock.Unlock()
 
+	// a transaction which failed but was still included (e.g. with insufficient funds) is reported as a user error,
+	// no matter the return code given by the txs processor
+	if err != nil && returnCode == vmcommon.Ok {
+		returnCode = vmcommon.UserError
+	}
+
+	txs.addTxsExecutionResults(&process.TransactionExecutionResult{
+		TxHash:     txHash,
+		ReturnCode: returnCode,
+	})
+
 	return err
 }
 
+func (txs *transactions) addTxsExecutionResults(results ...*process.TransactionExecutionResult) {
+	txs.mutTxsExecResults.Lock()
+	txs.txsExecutionResults = append(txs.txsExecutionResults, results...)
+	txs.mutTxsExecResults.Unlock()
+}
+
 func (txs *transactions) notifyTransactionProviderIfNeeded() {
 	txs.mutAccountsInfo.RLock()
 	for senderAddress, txShardInfoValue := range txs.accountsInfo {
@@ -716,8 +743,15 @@ func (txs *transactions) getAllTxsFromMiniBlock(
 }
 
 // CreateAndProcessMiniBlocks creates miniblocks from storage and processes the transactions added into the miniblocks
-// as long as it has time
-func (txs *transactions) CreateAndProcessMiniBlocks(haveTime func() bool) (block.MiniBlockSlice, error) {
+// as long as it has time. The transactions with a gas price lower than the given min gas price for inclusion or with
+// a data field larger than the given max tx data size (if set) are skipped, but they are kept in the pool. The same
+// happens with the cross shard transactions over the given max txs per dest shard (if set)
+func (txs *transactions) CreateAndProcessMiniBlocks(
+	haveTime func() bool,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
+) (block.MiniBlockSlice, error) {
 	startTime := time.Now()
 	sortedTxs, err := txs.computeSortedTxs(txs.shardCoordinator.SelfId(), txs.shardCoordinator.SelfId())
 	elapsedTime := time.Since(startTime)
@@ -752,6 +786,9 @@ func (txs *transactions) CreateAndProcessMiniBlocks(haveTime func() bool) (block
 		txs.blockTracker.IsShardStuck,
 		txs.blockSizeComputation.IsMaxBlockSizeReached,
 		sortedTxs,
+		minGasPriceForInclusion,
+		maxTxDataSize,
+		maxTxsPerDestShard,
 	)
 	elapsedTime = time.Since(startTime)
 	log.Debug("elapsed time to createAndProcessMiniBlocksFromMe",
@@ -771,6 +808,9 @@ func (txs *transactions) createAndProcessMiniBlocksFromMe(
 	isShardStuck func(uint32) bool,
 	isMaxBlockSizeReached func(int, int) bool,
 	sortedTxs []*txcache.WrappedTransaction,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
 ) (block.MiniBlockSlice, error) {
 	log.Debug("createAndProcessMiniBlocksFromMe has been started")
 
@@ -779,6 +819,9 @@ func (txs *transactions) createAndProcessMiniBlocksFromMe(
 	numTxsAdded := 0
 	numTxsBad := 0
 	numTxsSkipped := 0
+	numTxsBelowMinGasPrice := 0
+	numTxsWithDataTooLarge := 0
+	numTxsOverMaxPerDestShard := 0
 	numTxsFailed := 0
 	numTxsWithInitialBalanceConsumed := 0
 	numCrossShardScCallsOrSpecialTxs := 0
@@ -859,6 +902,16 @@ func (txs *transactions) createAndProcessMiniBlocksFromMe(
 			continue
 		}
 
+		// the next transactions of the same sender are skipped too, as they could not be executed without this one
+		isDestShardFull := maxTxsPerDestShard > 0 &&
+			receiverShardID != txs.shardCoordinator.SelfId() &&
+			uint32(len(miniBlock.TxHashes)) >= maxTxsPerDestShard
+		if isDestShardFull {
+			senderAddressToSkip = tx.GetSndAddr()
+			numTxsOverMaxPerDestShard++
+			continue
+		}
+
 		if len(senderAddressToSkip) > 0 {
 			if bytes.Equal(senderAddressToSkip, tx.GetSndAddr()) {
 				numTxsSkipped++
@@ -866,6 +919,30 @@ func (txs *transactions) createAndProcessMiniBlocksFromMe(
 			}
 		}
 
+		// the next transactions of the same sender could not be executed without this one, so they are skipped too
+		if tx.GetGasPrice() < minGasPriceForInclusion {
+			log.Trace("tx gas price is lower than the min gas price for inclusion",
+				"hash", txHash,
+				"gas price", tx.GetGasPrice(),
+				"min gas price for inclusion", minGasPriceForInclusion,
+			)
+			senderAddressToSkip = tx.GetSndAddr()
+			numTxsBelowMinGasPrice++
+			continue
+		}
+
+		// same as above, the next transactions of the same sender could not be executed without this one
+		if maxTxDataSize > 0 && uint64(len(tx.GetData())) > maxTxDataSize {
+			log.Trace("tx data size is larger than the max tx data size",
+				"hash", txHash,
+				"data size", len(tx.GetData()),
+				"max tx data size", maxTxDataSize,
+			)
+			senderAddressToSkip = tx.GetSndAddr()
+			numTxsWithDataTooLarge++
+			continue
+		}
+
 		txMaxTotalCost := big.NewInt(0)
 		isAddressSet := txs.balanceComputation.IsAddressSet(tx.GetSndAddr())
 		if isAddressSet {
@@ -1014,6 +1091,9 @@ func (txs *transactions) createAndProcessMiniBlocksFromMe(
 		"num txs bad", numTxsBad,
 		"num txs failed", numTxsFailed,
 		"num txs skipped", numTxsSkipped,
+		"num txs below min gas price", numTxsBelowMinGasPrice,
+		"num txs with data too large", numTxsWithDataTooLarge,
+		"num txs over max per dest shard", numTxsOverMaxPerDestShard,
 		"num txs with initial balance consumed", numTxsWithInitialBalanceConsumed,
 		"num cross shard sc calls or special txs", numCrossShardScCallsOrSpecialTxs,
 		"used time for computeGasConsumed", totalTimeUsedForComputeGasConsumed,
@@ -1135,6 +1215,7 @@ func (txs *transactions) ProcessMiniBlock(
 
 	numOfOldCrossInterMbs, numOfOldCrossInterTxs := getNumOfCrossInterMbsAndTxs()
 
+	txsExecutionResults := make([]*process.TransactionExecutionResult, 0, len(miniBlockTxs))
 	for index := range miniBlockTxs {
 		if !haveTime() {
 			err = process.ErrTimeIsOut
@@ -1143,10 +1224,16 @@ func (txs *transactions) ProcessMiniBlock(
 
 		txs.saveAccountBalanceForAddress(miniBlockTxs[index].GetRcvAddr())
 
-		_, err = txs.txProcessor.ProcessTransaction(miniBlockTxs[index])
+		var returnCode vmcommon.ReturnCode
+		returnCode, err = txs.txProcessor.ProcessTransaction(miniBlockTxs[index])
 		if err != nil {
 			return processedTxHashes, index, err
 		}
+
+		txsExecutionResults = append(txsExecutionResults, &process.TransactionExecutionResult{
+			TxHash:     miniBlockTxHashes[index],
+			ReturnCode: returnCode,
+		})
 	}
 
 	numOfCrtCrossInterMbs, numOfCrtCrossInterTxs := getNumOfCrossInterMbsAndTxs()
@@ -1176,6 +1263,8 @@ func (txs *transactions) ProcessMiniBlock(
 	txs.blockSizeComputation.AddNumMiniBlocks(numMiniBlocks)
 	txs.blockSizeComputation.AddNumTxs(numTxs)
 
+	txs.addTxsExecutionResults(txsExecutionResults...)
+
 	return nil, len(processedTxHashes), nil
 }
 
@@ -1202,6 +1291,30 @@ func (txs *transactions) GetAllCurrentUsedTxs() map[string]data.TransactionHandl
 	return txPool
 }
 
+// GetTransactionsExecutionResults returns the execution results of the transactions processed at current creation / processing
+func (txs *transactions) GetTransactionsExecutionResults() []*process.TransactionExecutionResult {
+	txs.mutTxsExecResults.RLock()
+	defer txs.mutTxsExecResults.RUnlock()
+
+	results := make([]*process.TransactionExecutionResult, 0, len(txs.txsExecutionResults))
+	for _, result := range txs.txsExecutionResults {
+		gasConsumed := txs.gasHandler.GasConsumed(result.TxHash)
+		gasRefunded := txs.gasHandler.GasRefunded(result.TxHash)
+		gasUsed := uint64(0)
+		if gasConsumed > gasRefunded {
+			gasUsed = gasConsumed - gasRefunded
+		}
+
+		results = append(results, &process.TransactionExecutionResult{
+			TxHash:     result.TxHash,
+			ReturnCode: result.ReturnCode,
+			GasUsed:    gasUsed,
+		})
+	}
+
+	return results
+}
+
 // IsInterfaceNil returns true if there is no value under the interface
 func (txs *transactions) IsInterfaceNil() bool {
 	return txs == nil
@@ -807,7 +807,7 @@ func TestTransactions_CreateAndProcessMiniBlockCrossShardGasLimitAddAll(t *testi
 	}
 
 	sortedTxsAndHashes, _ := txs.computeSortedTxs(sndShardId, dstShardId)
-	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes)
+	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes, 0, 0, 0)
 	assert.Nil(t, err)
 
 	txHashes := 0
@@ -818,6 +818,183 @@ func TestTransactions_CreateAndProcessMiniBlockCrossShardGasLimitAddAll(t *testi
 	assert.Equal(t, len(addedTxs), txHashes)
 }
 
+func TestTransactions_CreateAndProcessMiniBlocksShouldCapTheTxsPerDestShard(t *testing.T) {
+	t.Parallel()
+
+	txPool, _ := testscommon.CreateTxPool(3, 0)
+	requestTransaction := func(shardID uint32, txHashes [][]byte) {}
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	processedTxs := make([]*transaction.Transaction, 0)
+	txs, _ := NewTransactionPreprocessor(
+		txPool,
+		&mock.ChainStorerMock{},
+		hasher,
+		marshalizer,
+		&mock.TxProcessorMock{ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			processedTxs = append(processedTxs, transaction)
+			return 0, nil
+		}},
+		mock.NewMultiShardsCoordinatorMock(3),
+		&mock.AccountsStub{},
+		requestTransaction,
+		feeHandlerMock(),
+		&mock.GasHandlerMock{
+			ComputeGasConsumedByTxCalled: func(txSenderShardId uint32, txReceiverShardId uint32, txHandler data.TransactionHandler) (uint64, uint64, error) {
+				return 0, 0, nil
+			},
+			SetGasRefundedCalled: func(gasRefunded uint64, hash []byte) {},
+		},
+		&mock.BlockTrackerMock{},
+		block.TxBlock,
+		createMockPubkeyConverter(),
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+	)
+	assert.NotNil(t, txs)
+
+	// the transactions are heavily skewed towards shard 1, while the intra shard ones are never capped
+	numTxsPerDstShard := map[uint32]int{0: 10, 1: 20, 2: 2}
+	for dstShardID, numTxs := range numTxsPerDstShard {
+		strCache := process.ShardCacherIdentifier(0, dstShardID)
+		for i := 0; i < numTxs; i++ {
+			newTx := &transaction.Transaction{Nonce: uint64(i), SndAddr: []byte(fmt.Sprintf("sender %d", dstShardID))}
+
+			txHash, _ := core.CalculateHash(marshalizer, hasher, newTx)
+			txPool.AddData(txHash, newTx, newTx.Size(), strCache)
+		}
+	}
+
+	// the next transaction of the sender which reached the limit should be skipped, even if it has another destination
+	nextTx := &transaction.Transaction{Nonce: uint64(numTxsPerDstShard[1]), SndAddr: []byte("sender 1")}
+	nextTxHash, _ := core.CalculateHash(marshalizer, hasher, nextTx)
+	txPool.AddData(nextTxHash, nextTx, nextTx.Size(), process.ShardCacherIdentifier(0, 2))
+
+	maxTxsPerDestShard := uint32(5)
+	miniBlocks, err := txs.CreateAndProcessMiniBlocks(haveTimeTrue, 0, 0, maxTxsPerDestShard)
+	assert.Nil(t, err)
+
+	numMiniBlocks := make(map[uint32]int)
+	numTxsInMiniBlocks := make(map[uint32]int)
+	for _, miniBlock := range miniBlocks {
+		numMiniBlocks[miniBlock.ReceiverShardID]++
+		numTxsInMiniBlocks[miniBlock.ReceiverShardID] += len(miniBlock.TxHashes)
+	}
+	assert.Equal(t, map[uint32]int{0: 1, 1: 1, 2: 1}, numMiniBlocks)
+	expectedNumTxsInMiniBlocks := map[uint32]int{0: 10, 1: int(maxTxsPerDestShard), 2: 2}
+	assert.Equal(t, expectedNumTxsInMiniBlocks, numTxsInMiniBlocks)
+	assert.Equal(t, 10+int(maxTxsPerDestShard)+2, len(processedTxs))
+}
+
+func createTransactionsPreprocessorWithRelayerInsufficientFunds(
+	txPool dataRetriever.ShardedDataCacherNotifier,
+	relayedTxNonce uint64,
+) *transactions {
+	totalGasConsumed := uint64(0)
+	txs, _ := NewTransactionPreprocessor(
+		txPool,
+		&mock.ChainStorerMock{},
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		&mock.TxProcessorMock{ProcessTransactionCalled: func(tx *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			if tx.Nonce == relayedTxNonce {
+				return vmcommon.UserError, fmt.Errorf("%w, has: 1, wanted: 2", process.ErrRelayerInsufficientFunds)
+			}
+			return vmcommon.Ok, nil
+		}},
+		mock.NewMultiShardsCoordinatorMock(3),
+		&mock.AccountsStub{
+			RevertToSnapshotCalled: func(snapshot int) error {
+				return nil
+			},
+		},
+		func(shardID uint32, txHashes [][]byte) {},
+		feeHandlerMock(),
+		&mock.GasHandlerMock{
+			SetGasConsumedCalled: func(gasConsumed uint64, hash []byte) {
+				totalGasConsumed += gasConsumed
+			},
+			TotalGasConsumedCalled: func() uint64 {
+				return totalGasConsumed
+			},
+			ComputeGasConsumedByTxCalled: func(txSenderShardId uint32, txReceiverShardId uint32, txHandler data.TransactionHandler) (uint64, uint64, error) {
+				return 0, 0, nil
+			},
+			SetGasRefundedCalled:    func(gasRefunded uint64, hash []byte) {},
+			RemoveGasConsumedCalled: func(hashes [][]byte) {},
+			RemoveGasRefundedCalled: func(hashes [][]byte) {},
+			TotalGasRefundedCalled: func() uint64 {
+				return 0
+			},
+		},
+		&mock.BlockTrackerMock{},
+		block.TxBlock,
+		createMockPubkeyConverter(),
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+	)
+
+	return txs
+}
+
+func TestTransactions_CreateAndProcessMiniBlocksShouldSkipTheRelayedTxWithRelayerInsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	txPool, _ := testscommon.CreateTxPool(3, 0)
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	relayedTx := &transaction.Transaction{Nonce: 1, SndAddr: []byte("relayer")}
+	txs := createTransactionsPreprocessorWithRelayerInsufficientFunds(txPool, relayedTx.Nonce)
+
+	sndShardId := uint32(0)
+	dstShardId := uint32(1)
+	strCache := process.ShardCacherIdentifier(sndShardId, dstShardId)
+
+	otherTx := &transaction.Transaction{Nonce: 2, SndAddr: []byte("sender")}
+	relayedTxHash, _ := core.CalculateHash(marshalizer, hasher, relayedTx)
+	otherTxHash, _ := core.CalculateHash(marshalizer, hasher, otherTx)
+	txPool.AddData(relayedTxHash, relayedTx, relayedTx.Size(), strCache)
+	txPool.AddData(otherTxHash, otherTx, otherTx.Size(), strCache)
+
+	sortedTxsAndHashes, _ := txs.computeSortedTxs(sndShardId, dstShardId)
+	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes, 0, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(miniBlocks))
+	assert.Equal(t, [][]byte{otherTxHash}, miniBlocks[0].TxHashes)
+
+	// the relayed tx is neither included nor removed from pool, as no receipt was produced for it
+	_, ok := txPool.ShardDataStore(strCache).Get(relayedTxHash)
+	assert.True(t, ok)
+}
+
+func TestTransactions_ProcessBlockTransactionsWithRelayerInsufficientFundsShouldRejectTheBlock(t *testing.T) {
+	t.Parallel()
+
+	txPool, _ := testscommon.CreateTxPool(3, 0)
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	relayedTx := &transaction.Transaction{Nonce: 1, SndAddr: []byte("relayer")}
+	txs := createTransactionsPreprocessorWithRelayerInsufficientFunds(txPool, relayedTx.Nonce)
+
+	relayedTxHash, _ := core.CalculateHash(marshalizer, hasher, relayedTx)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{
+				TxHashes:        [][]byte{relayedTxHash},
+				SenderShardID:   0,
+				ReceiverShardID: 1,
+				Type:            block.TxBlock,
+			},
+		},
+	}
+	txs.AddTxForCurrentBlock(relayedTxHash, relayedTx, 0, 1)
+
+	// the relayed tx is skipped when the miniblocks are computed again, so the whole received block is rejected
+	err := txs.ProcessBlockTransactions(body, haveTimeTrue)
+	assert.Equal(t, process.ErrBlockBodyHashMismatch, err)
+}
+
 func TestTransactions_CreateAndProcessMiniBlockCrossShardGasLimitAddAllAsNoSCCalls(t *testing.T) {
 	t.Parallel()
 
@@ -879,7 +1056,7 @@ func TestTransactions_CreateAndProcessMiniBlockCrossShardGasLimitAddAllAsNoSCCal
 	}
 
 	sortedTxsAndHashes, _ := txs.computeSortedTxs(sndShardId, dstShardId)
-	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes)
+	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes, 0, 0, 0)
 	assert.Nil(t, err)
 
 	txHashes := 0
@@ -955,7 +1132,7 @@ func TestTransactions_CreateAndProcessMiniBlockCrossShardGasLimitAddOnly5asSCCal
 	}
 
 	sortedTxsAndHashes, _ := txs.computeSortedTxs(sndShardId, dstShardId)
-	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes)
+	miniBlocks, err := txs.createAndProcessMiniBlocksFromMe(haveTimeTrue, isShardStuckFalse, isMaxBlockSizeReachedFalse, sortedTxsAndHashes, 0, 0, 0)
 	assert.Nil(t, err)
 
 	txHashes := 0
@@ -1234,3 +1411,151 @@ func TestTransactionsPreprocessor_ProcessMiniBlockShouldWork(t *testing.T) {
 	assert.Equal(t, 0, len(txsToBeReverted))
 	assert.Equal(t, 3, numTxsProcessed)
 }
+
+func TestTransactions_ProcessMiniBlockShouldReportTransactionsExecutionResults(t *testing.T) {
+	t.Parallel()
+
+	txPool := &testscommon.ShardedDataStub{
+		ShardDataStoreCalled: func(id string) (c storage.Cacher) {
+			return &testscommon.CacherStub{
+				PeekCalled: func(key []byte) (value interface{}, ok bool) {
+					if reflect.DeepEqual(key, []byte("tx_hash1")) {
+						return &transaction.Transaction{Nonce: 10}, true
+					}
+					if reflect.DeepEqual(key, []byte("tx_hash2")) {
+						return &transaction.Transaction{Nonce: 11}, true
+					}
+					return nil, false
+				},
+			}
+		},
+	}
+	requestTransaction := func(shardID uint32, txHashes [][]byte) {}
+	isMaxBlockSizeReached := true
+	txs, _ := NewTransactionPreprocessor(
+		txPool,
+		&mock.ChainStorerMock{},
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		&mock.TxProcessorMock{
+			ProcessTransactionCalled: func(tx *transaction.Transaction) (vmcommon.ReturnCode, error) {
+				if tx.Nonce == 10 {
+					return vmcommon.Ok, nil
+				}
+				return vmcommon.UserError, nil
+			},
+		},
+		mock.NewMultiShardsCoordinatorMock(3),
+		&mock.AccountsStub{},
+		requestTransaction,
+		feeHandlerMock(),
+		&mock.GasHandlerMock{
+			GasConsumedCalled: func(hash []byte) uint64 {
+				return 50
+			},
+		},
+		&mock.BlockTrackerMock{},
+		block.TxBlock,
+		createMockPubkeyConverter(),
+		&mock.BlockSizeComputationStub{
+			IsMaxBlockSizeWithoutThrottleReachedCalled: func(mbs int, txs int) bool {
+				return mbs > 1 && isMaxBlockSizeReached
+			},
+		},
+		&mock.BalanceComputationStub{},
+	)
+	txs.CreateBlockStarted()
+
+	miniBlock := &block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        [][]byte{[]byte("tx_hash1"), []byte("tx_hash2")},
+		Type:            block.TxBlock,
+	}
+	numOfCrossInterMbs := 0
+	getNumOfCrossInterMbsAndTxs := func() (int, int) {
+		numOfCrossInterMbs++
+		return numOfCrossInterMbs, 0
+	}
+
+	_, _, err := txs.ProcessMiniBlock(miniBlock, haveTimeTrue, getNumOfCrossInterMbsAndTxs)
+	assert.Equal(t, process.ErrMaxBlockSizeReached, err)
+	assert.Equal(t, 0, len(txs.GetTransactionsExecutionResults()))
+
+	isMaxBlockSizeReached = false
+	_, _, err = txs.ProcessMiniBlock(miniBlock, haveTimeTrue, getNumOfCrossInterMbsAndTxs)
+	assert.Nil(t, err)
+
+	expectedResults := []*process.TransactionExecutionResult{
+		{TxHash: []byte("tx_hash1"), ReturnCode: vmcommon.Ok, GasUsed: 50},
+		{TxHash: []byte("tx_hash2"), ReturnCode: vmcommon.UserError, GasUsed: 50},
+	}
+	assert.Equal(t, expectedResults, txs.GetTransactionsExecutionResults())
+}
+
+func TestTransactions_GetTransactionsExecutionResultsShouldReportProcessedTransactions(t *testing.T) {
+	t.Parallel()
+
+	txPool := initDataPool().Transactions().(*testscommon.ShardedDataStub)
+	txPool.RemoveDataCalled = func(key []byte, cacheID string) {}
+	requestTransaction := func(shardID uint32, txHashes [][]byte) {}
+	txProcessor := &mock.TxProcessorMock{
+		ProcessTransactionCalled: func(tx *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			switch tx.Nonce {
+			case 0:
+				return vmcommon.Ok, nil
+			case 1:
+				return vmcommon.UserError, process.ErrFailedTransaction
+			case 2:
+				return vmcommon.Ok, process.ErrFailedTransaction
+			default:
+				return vmcommon.UserError, process.ErrLowerNonceInTransaction
+			}
+		},
+	}
+	gasHandler := &mock.GasHandlerMock{
+		GasConsumedCalled: func(hash []byte) uint64 {
+			return 100
+		},
+		GasRefundedCalled: func(hash []byte) uint64 {
+			return 30
+		},
+	}
+	preprocessor, _ := NewTransactionPreprocessor(
+		txPool,
+		&mock.ChainStorerMock{},
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		txProcessor,
+		mock.NewMultiShardsCoordinatorMock(3),
+		&mock.AccountsStub{},
+		requestTransaction,
+		feeHandlerMock(),
+		gasHandler,
+		&mock.BlockTrackerMock{},
+		block.TxBlock,
+		createMockPubkeyConverter(),
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+	)
+	preprocessor.CreateBlockStarted()
+
+	err := preprocessor.processAndRemoveBadTransaction([]byte("tx_hash0"), &transaction.Transaction{Nonce: 0}, 0, 0)
+	assert.Nil(t, err)
+	err = preprocessor.processAndRemoveBadTransaction([]byte("tx_hash1"), &transaction.Transaction{Nonce: 1}, 0, 0)
+	assert.Equal(t, process.ErrFailedTransaction, err)
+	err = preprocessor.processAndRemoveBadTransaction([]byte("tx_hash2"), &transaction.Transaction{Nonce: 2}, 0, 0)
+	assert.Equal(t, process.ErrFailedTransaction, err)
+	err = preprocessor.processAndRemoveBadTransaction([]byte("tx_hash3"), &transaction.Transaction{Nonce: 3}, 0, 0)
+	assert.Equal(t, process.ErrLowerNonceInTransaction, err)
+
+	expectedResults := []*process.TransactionExecutionResult{
+		{TxHash: []byte("tx_hash0"), ReturnCode: vmcommon.Ok, GasUsed: 70},
+		{TxHash: []byte("tx_hash1"), ReturnCode: vmcommon.UserError, GasUsed: 70},
+		{TxHash: []byte("tx_hash2"), ReturnCode: vmcommon.UserError, GasUsed: 70},
+	}
+	assert.Equal(t, expectedResults, preprocessor.GetTransactionsExecutionResults())
+
+	preprocessor.CreateBlockStarted()
+	assert.Equal(t, 0, len(preprocessor.GetTransactionsExecutionResults()))
+}
@@ -142,7 +142,12 @@ func (vip *validatorInfoPreprocessor) RequestTransactionsForMiniBlock(_ *block.M
 }
 
 // CreateAndProcessMiniBlocks does nothing
-func (vip *validatorInfoPreprocessor) CreateAndProcessMiniBlocks(_ func() bool) (block.MiniBlockSlice, error) {
+func (vip *validatorInfoPreprocessor) CreateAndProcessMiniBlocks(
+	_ func() bool,
+	_ uint64,
+	_ uint64,
+	_ uint32,
+) (block.MiniBlockSlice, error) {
 	// validatorsInfo are created only by meta
 	return make(block.MiniBlockSlice, 0), nil
 }
@@ -0,0 +1,28 @@
+package block
+
+import "github.com/ElrondNetwork/elrond-go/process"
+
+// processBlockOptions holds the settings of a block processing, together with the stages passed while processing it
+type processBlockOptions struct {
+	shouldVerifyStateRoot             bool
+	shouldNotifyTransactionsProcessed bool
+	shouldReportProcessingErrors      bool
+	passedStages                      []process.ProcessBlockStage
+}
+
+func newProcessBlockOptions(
+	shouldVerifyStateRoot bool,
+	shouldNotifyTransactionsProcessed bool,
+	shouldReportProcessingErrors bool,
+) *processBlockOptions {
+	return &processBlockOptions{
+		shouldVerifyStateRoot:             shouldVerifyStateRoot,
+		shouldNotifyTransactionsProcessed: shouldNotifyTransactionsProcessed,
+		shouldReportProcessingErrors:      shouldReportProcessingErrors,
+		passedStages:                      make([]process.ProcessBlockStage, 0),
+	}
+}
+
+func (pbo *processBlockOptions) markStagePassed(stage process.ProcessBlockStage) {
+	pbo.passedStages = append(pbo.passedStages, stage)
+}
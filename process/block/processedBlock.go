@@ -0,0 +1,41 @@
+package block
+
+import "sync"
+
+// processedBlock keeps the identity, given by the header hash and the body hash, of the last block successfully
+// processed on the current accounts state. It has to be reset each time the accounts state changes in any other way
+type processedBlock struct {
+	key    string
+	isSet  bool
+	mutKey sync.RWMutex
+}
+
+func newProcessedBlock() *processedBlock {
+	return &processedBlock{}
+}
+
+func processedBlockKey(headerHash []byte, bodyHash []byte) string {
+	return string(headerHash) + string(bodyHash)
+}
+
+func (pb *processedBlock) set(headerHash []byte, bodyHash []byte) {
+	pb.mutKey.Lock()
+	pb.key = processedBlockKey(headerHash, bodyHash)
+	pb.isSet = true
+	pb.mutKey.Unlock()
+}
+
+// is returns true if the given header and body are the ones of the last successfully processed block
+func (pb *processedBlock) is(headerHash []byte, bodyHash []byte) bool {
+	pb.mutKey.RLock()
+	defer pb.mutKey.RUnlock()
+
+	return pb.isSet && pb.key == processedBlockKey(headerHash, bodyHash)
+}
+
+func (pb *processedBlock) reset() {
+	pb.mutKey.Lock()
+	pb.key = ""
+	pb.isSet = false
+	pb.mutKey.Unlock()
+}
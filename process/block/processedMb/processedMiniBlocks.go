@@ -1,6 +1,7 @@
 package processedMb
 
 import (
+	"encoding/hex"
 	"sync"
 
 	"github.com/ElrondNetwork/elrond-go-logger"
@@ -71,6 +72,25 @@ func (pmb *ProcessedMiniBlockTracker) GetProcessedMiniBlocksHashes(metaBlockHash
 	return processedMiniBlocksHashes
 }
 
+// GetProcessedMiniBlocksInfo returns a copy of all processed miniblocks hashes grouped by their meta block hash, with
+// every hash hex encoded
+func (pmb *ProcessedMiniBlockTracker) GetProcessedMiniBlocksInfo() map[string][]string {
+	pmb.mutProcessedMiniBlocks.RLock()
+	defer pmb.mutProcessedMiniBlocks.RUnlock()
+
+	processedMiniBlocksInfo := make(map[string][]string, len(pmb.processedMiniBlocks))
+	for metaBlockHash, miniBlocksHashes := range pmb.processedMiniBlocks {
+		miniBlocksHashesHex := make([]string, 0, len(miniBlocksHashes))
+		for miniBlockHash := range miniBlocksHashes {
+			miniBlocksHashesHex = append(miniBlocksHashesHex, hex.EncodeToString([]byte(miniBlockHash)))
+		}
+
+		processedMiniBlocksInfo[hex.EncodeToString([]byte(metaBlockHash))] = miniBlocksHashesHex
+	}
+
+	return processedMiniBlocksInfo
+}
+
 // IsMiniBlockProcessed will return true if a mini block is processed
 func (pmb *ProcessedMiniBlockTracker) IsMiniBlockProcessed(metaBlockHash string, miniBlockHash string) bool {
 	pmb.mutProcessedMiniBlocks.RLock()
@@ -85,6 +105,33 @@ func (pmb *ProcessedMiniBlockTracker) IsMiniBlockProcessed(metaBlockHash string,
 	return isProcessed
 }
 
+// CompactProcessedMiniBlocks rebuilds the processed miniblocks map, removing the meta block hashes without any processed
+// miniblock left and releasing the memory still held by the deleted entries. It returns the number of removed meta
+// block hashes. The miniblocks hashes previously returned by GetProcessedMiniBlocksHashes are not updated afterwards
+func (pmb *ProcessedMiniBlockTracker) CompactProcessedMiniBlocks() int {
+	pmb.mutProcessedMiniBlocks.Lock()
+	defer pmb.mutProcessedMiniBlocks.Unlock()
+
+	numRemovedMetaBlocksHashes := 0
+	compactedProcessedMiniBlocks := make(map[string]MiniBlockHashes, len(pmb.processedMiniBlocks))
+	for metaBlockHash, miniBlocksHashes := range pmb.processedMiniBlocks {
+		if len(miniBlocksHashes) == 0 {
+			numRemovedMetaBlocksHashes++
+			continue
+		}
+
+		compactedMiniBlocksHashes := make(MiniBlockHashes, len(miniBlocksHashes))
+		for miniBlockHash := range miniBlocksHashes {
+			compactedMiniBlocksHashes[miniBlockHash] = struct{}{}
+		}
+		compactedProcessedMiniBlocks[metaBlockHash] = compactedMiniBlocksHashes
+	}
+
+	pmb.processedMiniBlocks = compactedProcessedMiniBlocks
+
+	return numRemovedMetaBlocksHashes
+}
+
 // ConvertProcessedMiniBlocksMapToSlice will convert a map[string]map[string]struct{} in a slice of MiniBlocksInMeta
 func (pmb *ProcessedMiniBlockTracker) ConvertProcessedMiniBlocksMapToSlice() []bootstrapStorage.MiniBlocksInMeta {
 	pmb.mutProcessedMiniBlocks.RLock()
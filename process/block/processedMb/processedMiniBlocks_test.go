@@ -1,6 +1,8 @@
 package processedMb_test
 
 import (
+	"encoding/hex"
+	"sort"
 	"testing"
 
 	"github.com/ElrondNetwork/elrond-go/process/block/bootstrapStorage"
@@ -59,6 +61,69 @@ func TestProcessedMiniBlocks_GetProcessedMiniBlocksHashes(t *testing.T) {
 	assert.NotNil(t, mapData[mbHash1])
 }
 
+func TestProcessedMiniBlocks_GetProcessedMiniBlocksInfoShouldReturnADeepCopy(t *testing.T) {
+	t.Parallel()
+
+	pmb := processedMb.NewProcessedMiniBlocks()
+
+	mbHash1 := "hash1"
+	mbHash2 := "hash2"
+	mtbHash1 := "meta1"
+	mtbHash2 := "meta2"
+
+	pmb.AddMiniBlockHash(mtbHash1, mbHash1)
+	pmb.AddMiniBlockHash(mtbHash1, mbHash2)
+	pmb.AddMiniBlockHash(mtbHash2, mbHash2)
+
+	processedMiniBlocksInfo := pmb.GetProcessedMiniBlocksInfo()
+	assert.Equal(t, 2, len(processedMiniBlocksInfo))
+
+	miniBlocksHashes := processedMiniBlocksInfo[hex.EncodeToString([]byte(mtbHash1))]
+	sort.Strings(miniBlocksHashes)
+	assert.Equal(t, []string{hex.EncodeToString([]byte(mbHash1)), hex.EncodeToString([]byte(mbHash2))}, miniBlocksHashes)
+	assert.Equal(t, []string{hex.EncodeToString([]byte(mbHash2))}, processedMiniBlocksInfo[hex.EncodeToString([]byte(mtbHash2))])
+
+	processedMiniBlocksInfo[hex.EncodeToString([]byte(mtbHash2))][0] = "changed"
+	delete(processedMiniBlocksInfo, hex.EncodeToString([]byte(mtbHash1)))
+
+	assert.True(t, pmb.IsMiniBlockProcessed(mtbHash1, mbHash1))
+	assert.True(t, pmb.IsMiniBlockProcessed(mtbHash2, mbHash2))
+	assert.Equal(t, 2, len(pmb.GetProcessedMiniBlocksInfo()))
+}
+
+func TestProcessedMiniBlocks_CompactProcessedMiniBlocksShouldRemoveTheEmptyInnerSets(t *testing.T) {
+	t.Parallel()
+
+	pmb := processedMb.NewProcessedMiniBlocks()
+
+	mbHash1 := "hash1"
+	mbHash2 := "hash2"
+	mbHash3 := "hash3"
+	mtbHash1 := "meta1"
+	mtbHash2 := "meta2"
+	mtbHash3 := "meta3"
+
+	pmb.AddMiniBlockHash(mtbHash1, mbHash1)
+	pmb.AddMiniBlockHash(mtbHash2, mbHash2)
+	pmb.AddMiniBlockHash(mtbHash3, mbHash3)
+	pmb.AddMiniBlockHash(mtbHash3, mbHash1)
+
+	delete(pmb.GetProcessedMiniBlocksHashes(mtbHash1), mbHash1)
+	delete(pmb.GetProcessedMiniBlocksHashes(mtbHash2), mbHash2)
+	delete(pmb.GetProcessedMiniBlocksHashes(mtbHash3), mbHash1)
+	assert.Equal(t, 3, len(pmb.GetProcessedMiniBlocksInfo()))
+
+	numRemoved := pmb.CompactProcessedMiniBlocks()
+	assert.Equal(t, 2, numRemoved)
+	assert.Equal(t, 1, len(pmb.GetProcessedMiniBlocksInfo()))
+	assert.True(t, pmb.IsMiniBlockProcessed(mtbHash3, mbHash3))
+	assert.False(t, pmb.IsMiniBlockProcessed(mtbHash3, mbHash1))
+
+	numRemoved = pmb.CompactProcessedMiniBlocks()
+	assert.Equal(t, 0, numRemoved)
+	assert.Equal(t, 1, len(pmb.GetProcessedMiniBlocksInfo()))
+}
+
 func TestProcessedMiniBlocks_ConvertSliceToProcessedMiniBlocksMap(t *testing.T) {
 	t.Parallel()
 
@@ -5,10 +5,13 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
+	"sync"
 	"time"
 
 	logger "github.com/ElrondNetwork/elrond-go-logger"
 	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/core/atomic"
 	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/block"
@@ -21,16 +24,129 @@ import (
 )
 
 var _ process.BlockProcessor = (*shardProcessor)(nil)
+var _ process.ShardProcessorReader = (*shardProcessor)(nil)
+var _ process.FinalityProofHandler = (*shardProcessor)(nil)
+var _ process.CrossShardSettlementHandler = (*shardProcessor)(nil)
+
+const (
+	timeBetweenCheckForEpochStart        = 100 * time.Millisecond
+	maxTimeToWaitForBackgroundRoutines   = 5 * time.Second
+	finalBlockAdvanceChanSize            = 100
+	blockProductionWindowSize            = 100
+	minMetaBlockFinality                 = 1
+	maxMetaBlocksFirstSeenTracked        = 1000
+	maxBlocksWithBackgroundErrorsTracked = 100
+	maxBackgroundErrorsPerBlock          = 10
+	maxPausedMetaBlocks                  = 100
+)
+
+// usedTxsBlockTypes holds the block types of the transactions the transaction coordinator can use in a block
+var usedTxsBlockTypes = []block.Type{
+	block.TxBlock,
+	block.SmartContractResultBlock,
+	block.RewardsBlock,
+	block.InvalidBlock,
+	block.ReceiptBlock,
+}
 
-const timeBetweenCheckForEpochStart = 100 * time.Millisecond
+// precomputedMetaBlocks holds the ordered candidate meta blocks computed in advance for a round, together with the
+// last cross notarized meta header hash they were computed on top of
+type precomputedMetaBlocks struct {
+	round                  uint64
+	lastCrossNotarizedHash []byte
+	metaBlocks             []data.HeaderHandler
+	metaBlocksHashes       [][]byte
+}
 
 // shardProcessor implements shardProcessor interface and actually it tries to execute block
 type shardProcessor struct {
 	*baseProcessor
-	metaBlockFinality uint32
-	chRcvAllMetaHdrs  chan bool
-
-	processedMiniBlocks *processedMb.ProcessedMiniBlockTracker
+	metaBlockFinality         uint32
+	metaFinalityVerifier      process.MetaFinalityVerifier
+	assertedFinalMetaNonce    uint64
+	mutAssertedFinalMetaNonce sync.RWMutex
+	chRcvAllMetaHdrs          chan bool
+
+	includeEmptyAttestedMetaBlocks   bool
+	weightedMetaBlockSelectionMaxTxs uint32
+	poolLogThreshold                 uint64
+	onTransactionsProcessed          func(results []*process.TransactionExecutionResult)
+	onNotarizedHeadersPruned         func(numPrunedHeaders uint64)
+	maxShardHeaderRequestsPerMeta    uint32
+	strictHeaderValidation           bool
+	headerTxCountCheckEnableEpoch    uint32
+	bodyShardIdsCheckEnableEpoch     uint32
+	headerEpochCheckEnableEpoch      uint32
+	genesisRandSeedCheckEnableEpoch  uint32
+	metaBlocksCountCheckEnableEpoch  uint32
+	genesisTime                      time.Time
+	maxRoundClockSkew                time.Duration
+	indexedTxTransformer             process.IndexedTxTransformer
+	blockCreationPolicy              process.BlockCreationPolicyHandler
+	minTimeForTxProcessing           time.Duration
+	maxAttestedMetaBlocksPerBlock    uint32
+	bodyCompositionEnableEpoch       uint32
+	txExportDir                      string
+	checkAttestedShardInfo           bool
+	prevHeaderRequestGracePeriod     time.Duration
+	maxReorgDepth                    uint32
+	metaBlocksPoolHighFillRatio      float64
+	numConsecutiveRestores           atomic.Counter
+	numTxExportErrors                atomic.Counter
+	numRemovedEmptyProcessedMbs      atomic.Counter
+	processedMbsCompactionInterval   time.Duration
+	lastProcessedMbsCompaction       time.Time
+	createdBodyRound                 uint64
+	createdBodyHash                  []byte
+	selfProposedBodyHash             []byte
+	selfProposedBodyRound            uint64
+
+	mutPendingPrevHeaderRequests sync.Mutex
+	pendingPrevHeaderRequests    map[string]struct{}
+
+	processedMiniBlocks   *processedMb.ProcessedMiniBlockTracker
+	lastProcessedBlock    *processedBlock
+	metaBlocksFirstSeen   *metaBlocksFirstSeen
+	blockBackgroundErrors *blockBackgroundErrors
+	blockProduction       *blockProductionTracker
+	pausedMetaBlocks      *pausedMetaBlocks
+
+	pendingCrossShardMiniBlocks    map[uint32][][]byte
+	mutPendingCrossShardMiniBlocks sync.RWMutex
+
+	precomputedMetaBlocks    *precomputedMetaBlocks
+	mutPrecomputedMetaBlocks sync.Mutex
+
+	finalBlockAdvanceSubscribers []chan uint64
+	lastNotifiedFinalNonce       uint64
+	mutFinalBlockAdvance         sync.Mutex
+
+	committedBlockSinks    []*committedBlockSink
+	mutCommittedBlockSinks sync.RWMutex
+
+	attestationDecisions    map[string]string
+	mutAttestationDecisions sync.RWMutex
+
+	txsPoolsCleaner    process.PoolsCleaner
+	mutTxsPoolsCleaner sync.Mutex
+
+	lastThrottleSuccessRound    uint64
+	lastThrottleSuccessMaxItems uint32
+	mutLastThrottleSuccess      sync.RWMutex
+
+	chStop                chan struct{}
+	isClosed              atomic.Flag
+	mutBackgroundRoutines sync.RWMutex
+	wgBackgroundRoutines  sync.WaitGroup
+
+	isMetaBlockHandlerRegistered atomic.Flag
+	shouldPrioritizeMetaBlocks   atomic.Flag
+	isMetaBlocksPoolNearCapacity atomic.Flag
+
+	// mutBlockProcessing serializes the block validation with the processing and the creation of the blocks, while
+	// isBlockInProgress is set from the processing or the creation of a block until it is committed or reverted
+	mutBlockProcessing sync.Mutex
+	isBlockInProgress  atomic.Flag
 }
 
 // NewShardProcessor creates a new shardProcessor object
@@ -50,6 +166,22 @@ func NewShardProcessor(arguments ArgShardProcessor) (*shardProcessor, error) {
 		return nil, process.ErrNilTransactionPool
 	}
 
+	if check.IfNil(arguments.BlockCreationPolicy) {
+		return nil, process.ErrNilBlockCreationPolicy
+	}
+
+	if arguments.MetaBlocksPoolHighFillRatio < 0 || arguments.MetaBlocksPoolHighFillRatio > 1 {
+		return nil, fmt.Errorf("%w: %v", process.ErrInvalidMetaBlocksPoolHighFillRatio, arguments.MetaBlocksPoolHighFillRatio)
+	}
+	if arguments.MetaBlockFinality < 0 {
+		return nil, fmt.Errorf("%w: %d", process.ErrInvalidMetaBlockFinality, arguments.MetaBlockFinality)
+	}
+
+	asyncStorageUnits, err := createAsyncStorageUnits(arguments.StorageUnitsWriteMode, arguments.ShardCoordinator.SelfId())
+	if err != nil {
+		return nil, err
+	}
+
 	genesisHdr := arguments.BlockChain.GetGenesisHeader()
 	base := &baseProcessor{
 		accountsDB:              arguments.AccountsDB,
@@ -80,54 +212,367 @@ func NewShardProcessor(arguments ArgShardProcessor) (*shardProcessor, error) {
 		headerIntegrityVerifier: arguments.HeaderIntegrityVerifier,
 		historyRepo:             arguments.HistoryRepository,
 		epochNotifier:           arguments.EpochNotifier,
+		asyncStorageUnits:       asyncStorageUnits,
+		onBlockProcessingError:  arguments.OnBlockProcessingError,
+
+		miniBlockReservedCheckEnableEpoch: arguments.MiniBlockReservedCheckEnableEpoch,
 	}
 
 	sp := shardProcessor{
-		baseProcessor: base,
+		baseProcessor:                    base,
+		includeEmptyAttestedMetaBlocks:   arguments.IncludeEmptyAttestedMetaBlocks,
+		weightedMetaBlockSelectionMaxTxs: arguments.WeightedMetaBlockSelectionMaxTxs,
+		poolLogThreshold:                 arguments.PoolLogThreshold,
+		onTransactionsProcessed:          arguments.OnTransactionsProcessed,
+		onNotarizedHeadersPruned:         arguments.OnNotarizedHeadersPruned,
+		maxShardHeaderRequestsPerMeta:    arguments.MaxShardHeaderRequestsPerMetaBlock,
+		strictHeaderValidation:           arguments.StrictHeaderValidation,
+		headerTxCountCheckEnableEpoch:    arguments.HeaderTxCountCheckEnableEpoch,
+		bodyShardIdsCheckEnableEpoch:     arguments.BodyShardIdsCheckEnableEpoch,
+		headerEpochCheckEnableEpoch:      arguments.HeaderEpochCheckEnableEpoch,
+		genesisRandSeedCheckEnableEpoch:  arguments.GenesisRandSeedCheckEnableEpoch,
+		metaBlocksCountCheckEnableEpoch:  arguments.MetaBlocksCountCheckEnableEpoch,
+		genesisTime:                      arguments.GenesisTime,
+		maxRoundClockSkew:                arguments.MaxRoundClockSkew,
+		indexedTxTransformer:             arguments.IndexedTxTransformer,
+		blockCreationPolicy:              arguments.BlockCreationPolicy,
+		minTimeForTxProcessing:           arguments.MinTimeForTxProcessing,
+		maxAttestedMetaBlocksPerBlock:    arguments.MaxAttestedMetaBlocksPerBlock,
+		bodyCompositionEnableEpoch:       arguments.BodyCompositionEnableEpoch,
+		txExportDir:                      arguments.TxExportDir,
+		checkAttestedShardInfo:           arguments.CheckAttestedShardInfo,
+		prevHeaderRequestGracePeriod:     arguments.PrevHeaderRequestGracePeriod,
+		maxReorgDepth:                    arguments.MaxReorgDepth,
+		metaBlocksPoolHighFillRatio:      arguments.MetaBlocksPoolHighFillRatio,
+		processedMbsCompactionInterval:   arguments.ProcessedMbsCompactionInterval,
+		lastProcessedMbsCompaction:       time.Now(),
+	}
+
+	if arguments.DecodedHeadersCacheSize > 0 {
+		sp.decodedHeadersCache, err = newDecodedHeadersCache(int(arguments.DecodedHeadersCacheSize), arguments.Hasher)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	sp.txCounter = NewTransactionCounter()
+	sp.lastProcessedBlock = newProcessedBlock()
+	sp.metaBlocksFirstSeen = newMetaBlocksFirstSeen(maxMetaBlocksFirstSeenTracked)
+	sp.blockBackgroundErrors = newBlockBackgroundErrors(maxBlocksWithBackgroundErrorsTracked, maxBackgroundErrorsPerBlock)
+	sp.blockProduction = newBlockProductionTracker(blockProductionWindowSize)
+	sp.pausedMetaBlocks = newPausedMetaBlocks(maxPausedMetaBlocks)
 	sp.requestBlockBodyHandler = &sp
 	sp.blockProcessor = &sp
+	sp.startAsyncWrite = sp.startBackgroundRoutine
 
 	sp.chRcvAllMetaHdrs = make(chan bool)
+	sp.chStop = make(chan struct{})
+	sp.pendingPrevHeaderRequests = make(map[string]struct{})
 
 	sp.hdrsForCurrBlock = newHdrForBlock()
 	sp.processedMiniBlocks = processedMb.NewProcessedMiniBlocks()
 
-	headersPool := sp.dataPool.Headers()
-	headersPool.RegisterHandler(sp.receivedMetaBlock)
+	// a zero finality would make every meta block be considered final without any verification
+	sp.metaBlockFinality = core.MaxUint32(minMetaBlockFinality, process.BlockFinality)
+	if arguments.MetaBlockFinality > 0 {
+		sp.metaBlockFinality = uint32(arguments.MetaBlockFinality)
+	}
+
+	sp.metaFinalityVerifier = arguments.MetaFinalityVerifier
+	if check.IfNil(sp.metaFinalityVerifier) {
+		sp.metaFinalityVerifier, err = NewMetaFinalityVerifier(ArgsMetaFinalityVerifier{
+			HeaderValidator: sp.headerValidator,
+			Finality:        sp.metaBlockFinality,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	sp.metaBlockFinality = process.BlockFinality
+	sp.registerMetaBlockHandler()
 
 	return &sp, nil
 }
 
-// ProcessBlock processes a block. It returns nil if all ok or the specific error
+// checkTimeForTxProcessing returns ErrTimeIsOut if the remaining time is below the configured min time needed
+// for processing the block transactions, so that the expensive processing is not started when it cannot finish
+func (sp *shardProcessor) checkTimeForTxProcessing(haveTime func() time.Duration) error {
+	if sp.minTimeForTxProcessing <= 0 {
+		return nil
+	}
+
+	remainingTime := haveTime()
+	if remainingTime < sp.minTimeForTxProcessing {
+		return fmt.Errorf("%w, remaining time: %v, min time for tx processing: %v",
+			process.ErrTimeIsOut,
+			remainingTime,
+			sp.minTimeForTxProcessing,
+		)
+	}
+
+	return nil
+}
+
+// ProcessBlock processes a block. It returns nil if all ok or the specific error. A block identical to the last one
+// successfully processed on the current accounts state is not processed again
 func (sp *shardProcessor) ProcessBlock(
 	headerHandler data.HeaderHandler,
 	bodyHandler data.BodyHandler,
 	haveTime func() time.Duration,
 ) error {
-
 	if haveTime == nil {
 		return process.ErrNilHaveTimeHandler
 	}
+	if check.IfNil(headerHandler) {
+		return process.ErrNilBlockHeader
+	}
+
+	sp.mutBlockProcessing.Lock()
+	defer sp.mutBlockProcessing.Unlock()
+
+	var err error
+	sp.isBlockInProgress.Set()
+	defer func() {
+		if err != nil {
+			sp.isBlockInProgress.Unset()
+		}
+	}()
+
+	headerHash, errHeaderHash := core.CalculateHash(sp.marshalizer, sp.hasher, headerHandler)
+	bodyHash, errBodyHash := core.CalculateHash(sp.marshalizer, sp.hasher, bodyHandler)
+	isCacheable := errHeaderHash == nil && errBodyHash == nil
+	if isCacheable && sp.lastProcessedBlock.is(headerHash, bodyHash) {
+		log.Debug("block has been already processed",
+			"round", headerHandler.GetRound(),
+			"nonce", headerHandler.GetNonce(),
+			"hash", headerHash,
+		)
+		return nil
+	}
+
+	sp.lastProcessedBlock.reset()
+	err = sp.processBlock(headerHandler, bodyHandler, haveTime, newProcessBlockOptions(true, true, true))
+	if isCacheable && err == nil {
+		sp.lastProcessedBlock.set(headerHash, bodyHash)
+	}
+
+	return err
+}
+
+// ProcessBlockSkippingStateRootCheck processes a block in the same way as ProcessBlock, executing and verifying the
+// block transactions, but without checking the resulted state root against the header root hash. It is meant for the
+// nodes which trust the proposer's state root. The returned flag tells if the state root was verified, which is always
+// false for this variant. The processed block is not remembered as the last processed one, as it was not fully verified
+func (sp *shardProcessor) ProcessBlockSkippingStateRootCheck(
+	headerHandler data.HeaderHandler,
+	bodyHandler data.BodyHandler,
+	haveTime func() time.Duration,
+) (bool, error) {
+	if haveTime == nil {
+		return false, process.ErrNilHaveTimeHandler
+	}
+	if check.IfNil(headerHandler) {
+		return false, process.ErrNilBlockHeader
+	}
+
+	sp.mutBlockProcessing.Lock()
+	defer sp.mutBlockProcessing.Unlock()
+
+	var err error
+	sp.isBlockInProgress.Set()
+	defer func() {
+		if err != nil {
+			sp.isBlockInProgress.Unset()
+		}
+	}()
+
+	sp.lastProcessedBlock.reset()
+	err = sp.processBlock(headerHandler, bodyHandler, haveTime, newProcessBlockOptions(false, true, true))
+
+	return false, err
+}
+
+// ValidateBlock runs all the verifications done by ProcessBlock, including the block transactions processing, without
+// applying the block. It is meant for the nodes which only monitor the proposed blocks, without participating in
+// consensus. The validation waits for the processing or the creation of a block already started, and it is rejected
+// with ErrBlockProcessingInProgress while a processed or created block is not yet committed or reverted. The blocks
+// processing and creation wait for the validation in progress. The returned result tells which stage failed, if any,
+// and which stages were passed.
+// The block is processed on scratch accounts, recreated from the last committed state and never committed: the user
+// accounts adapter of the blocks processing should be a state.ValidationAccountsAdapter, which is switched to the
+// scratch accounts for the duration of the validation. The live accounts are never touched, so their readers can not
+// observe the validated block. At the end, the epoch notifier and the request handler are set back to the epoch they
+// had before, and the per block data (headers for current block, transaction coordinator and fee handler) is cleared.
+// The side effects which remain are: the requests for the missing data (previous header, transactions, meta headers
+// and epoch start info), which will also fill the pools, the current header set on the blockchain hook, which is
+// replaced on the next block processing or creation, and the block metrics. The processed transactions are never
+// notified and a failed validation is not reported as a block processing error, neither through the block processing
+// error handler nor through the rejections metrics
+func (sp *shardProcessor) ValidateBlock(
+	headerHandler data.HeaderHandler,
+	bodyHandler data.BodyHandler,
+	haveTime func() time.Duration,
+) *process.BlockValidationResult {
+	if haveTime == nil {
+		return process.NewBlockValidationResult(process.ErrNilHaveTimeHandler, nil)
+	}
+	if check.IfNil(headerHandler) {
+		return process.NewBlockValidationResult(process.ErrNilBlockHeader, nil)
+	}
+
+	sp.mutBlockProcessing.Lock()
+	defer sp.mutBlockProcessing.Unlock()
+
+	if sp.isBlockInProgress.IsSet() {
+		return process.NewBlockValidationResult(process.ErrBlockProcessingInProgress, nil)
+	}
+	validationAccounts, ok := sp.accountsDB[state.UserAccountsState].(state.ValidationAccountsAdapter)
+	if !ok {
+		return process.NewBlockValidationResult(process.ErrBlockValidationNotSupported, nil)
+	}
+	// the scratch accounts are recreated from the live root hash, so it should not contain any changes not yet committed
+	if validationAccounts.JournalLen() != 0 {
+		return process.NewBlockValidationResult(process.ErrAccountStateDirty, nil)
+	}
+	rootHash, err := validationAccounts.RootHash()
+	if err != nil {
+		return process.NewBlockValidationResult(err, nil)
+	}
+	err = validationAccounts.StartValidation(rootHash)
+	if err != nil {
+		return process.NewBlockValidationResult(err, nil)
+	}
+
+	epochBeforeValidation := sp.epochNotifier.CurrentEpoch()
+
+	options := newProcessBlockOptions(true, false, false)
+	err = sp.processBlock(headerHandler, bodyHandler, haveTime, options)
+
+	validationAccounts.EndValidation()
+	sp.restoreAfterValidation(epochBeforeValidation)
+
+	return process.NewBlockValidationResult(err, options.passedStages)
+}
+
+// restoreAfterValidation undoes the changes done by a block validation which are not related to the accounts state
+func (sp *shardProcessor) restoreAfterValidation(epochBeforeValidation uint32) {
+	sp.epochNotifier.CheckEpoch(epochBeforeValidation)
+	sp.requestHandler.SetEpoch(epochBeforeValidation)
+	sp.createBlockStarted()
+	sp.lastProcessedBlock.reset()
+	sp.resetSelfProposedBody()
+}
+
+// BlockProductionSuccessRate returns the fraction of the rounds, from the recent rounds window, in which the node
+// created a block that was also committed
+func (sp *shardProcessor) BlockProductionSuccessRate() float64 {
+	return sp.blockProduction.successRate()
+}
+
+func (sp *shardProcessor) saveMetaHeadersRequestsMetrics(requestedMetaHdrs uint32, requestedFinalMetaHdrs uint32, missingMetaHdrs uint32) {
+	metrics := newStatusMetricsBatch(sp.appStatusHandler)
+	defer metrics.flush()
+
+	metrics.SetUInt64Value(core.MetricRequestedMetaHeaders, uint64(requestedMetaHdrs))
+	metrics.SetUInt64Value(core.MetricRequestedFinalMetaHeaders, uint64(requestedFinalMetaHdrs))
+	metrics.SetUInt64Value(core.MetricMissingMetaHeaders, uint64(missingMetaHdrs))
+
+	if requestedMetaHdrs > 0 || requestedFinalMetaHdrs > 0 {
+		metrics.Increment(core.MetricNumBlocksWithMetaHeadersRequests)
+	}
+}
+
+func (sp *shardProcessor) saveBlockProductionSuccessRateMetric(statusHandler core.AppStatusHandler) {
+	successRatePercent := uint64(sp.blockProduction.successRate() * 100)
+	statusHandler.SetUInt64Value(core.MetricBlockProductionSuccessRatePercent, successRatePercent)
+}
+
+// requestMissingPrevHeader requests the given missing previous header. When a grace period is set, the request is
+// delayed and done only if the header is still missing after that period, as it could be just received out of order
+func (sp *shardProcessor) requestMissingPrevHeader(shardID uint32, prevHash []byte) {
+	if sp.prevHeaderRequestGracePeriod == 0 {
+		log.Debug("requested missing shard header",
+			"hash", prevHash,
+			"for shard", shardID,
+		)
+
+		go sp.requestHandler.RequestShardHeader(shardID, prevHash)
+		return
+	}
+
+	sp.mutPendingPrevHeaderRequests.Lock()
+	_, isPending := sp.pendingPrevHeaderRequests[string(prevHash)]
+	if !isPending {
+		sp.pendingPrevHeaderRequests[string(prevHash)] = struct{}{}
+	}
+	sp.mutPendingPrevHeaderRequests.Unlock()
+
+	if isPending {
+		return
+	}
+
+	sp.startBackgroundRoutine(func() {
+		sp.requestMissingPrevHeaderAfterGracePeriod(shardID, prevHash)
+	})
+}
+
+func (sp *shardProcessor) requestMissingPrevHeaderAfterGracePeriod(shardID uint32, prevHash []byte) {
+	defer func() {
+		sp.mutPendingPrevHeaderRequests.Lock()
+		delete(sp.pendingPrevHeaderRequests, string(prevHash))
+		sp.mutPendingPrevHeaderRequests.Unlock()
+	}()
+
+	select {
+	case <-time.After(sp.prevHeaderRequestGracePeriod):
+	case <-sp.chStop:
+		return
+	}
+
+	_, err := process.GetShardHeader(prevHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+	if err == nil {
+		log.Debug("missing shard header has been received during the grace period",
+			"hash", prevHash,
+			"for shard", shardID,
+		)
+		return
+	}
+
+	log.Debug("requested missing shard header",
+		"hash", prevHash,
+		"for shard", shardID,
+		"grace period", sp.prevHeaderRequestGracePeriod,
+	)
+
+	sp.requestHandler.RequestShardHeader(shardID, prevHash)
+}
+
+func (sp *shardProcessor) processBlock(
+	headerHandler data.HeaderHandler,
+	bodyHandler data.BodyHandler,
+	haveTime func() time.Duration,
+	options *processBlockOptions,
+) error {
 
 	err := sp.checkBlockValidity(headerHandler, bodyHandler)
 	if err != nil {
 		if err == process.ErrBlockHashDoesNotMatch {
-			log.Debug("requested missing shard header",
-				"hash", headerHandler.GetPrevHash(),
-				"for shard", headerHandler.GetShardID(),
-			)
-
-			go sp.requestHandler.RequestShardHeader(headerHandler.GetShardID(), headerHandler.GetPrevHash())
+			sp.requestMissingPrevHeader(headerHandler.GetShardID(), headerHandler.GetPrevHash())
 		}
 
 		return err
 	}
 
+	err = sp.checkPrevRandSeedOfFirstBlock(headerHandler)
+	if err != nil {
+		return err
+	}
+
+	err = sp.checkHeaderRoundClockSkew(headerHandler)
+	if err != nil {
+		return err
+	}
+
 	sp.epochNotifier.CheckEpoch(headerHandler.GetEpoch())
 	sp.requestHandler.SetEpoch(headerHandler.GetEpoch())
 
@@ -147,19 +592,48 @@ func (sp *shardProcessor) ProcessBlock(
 		return process.ErrWrongTypeAssertion
 	}
 
-	go getMetricsFromBlockBody(body, sp.marshalizer, sp.appStatusHandler)
+	sp.startBlockBackgroundRoutine(headerHandler, "getMetricsFromBlockBody", func() error {
+		metrics := newStatusMetricsBatch(sp.appStatusHandler)
+		defer metrics.flush()
 
-	err = sp.checkHeaderBodyCorrelation(header.MiniBlockHeaders, body)
+		return getMetricsFromBlockBody(body, sp.marshalizer, metrics)
+	})
+
+	err = sp.validateBodyShardIdsIfEnabled(header, body)
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageHeaderBodyCorrelation, err)
+	}
+
+	err = sp.checkHeaderBodyCorrelation(header.MiniBlockHeaders, body, header.GetEpoch())
+	if err != nil {
+		return process.NewProcessBlockError(process.StageHeaderBodyCorrelation, err)
+	}
+
+	err = sp.checkHeaderTxCountIfEnabled(header, body)
+	if err != nil {
+		return process.NewProcessBlockError(process.StageHeaderBodyCorrelation, err)
+	}
+
+	bodyComposition, err := sp.getBodyComposition(header)
+	if err != nil {
+		return process.NewProcessBlockError(process.StageHeaderBodyCorrelation, err)
+	}
+	options.markStagePassed(process.StageHeaderBodyCorrelation)
+
+	err = sp.checkAttestedMetaBlocksCount(header)
+	if err != nil {
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
 	}
 
 	txCounts, rewardCounts, unsignedCounts := sp.txCounter.getPoolCounts(sp.dataPool)
-	log.Debug("total txs in pool", "counts", txCounts.String())
-	log.Debug("total txs in rewards pool", "counts", rewardCounts.String())
-	log.Debug("total txs in unsigned pool", "counts", unsignedCounts.String())
+	logPoolCounts(log, sp.poolLogThreshold, txCounts, rewardCounts, unsignedCounts)
 
-	go getMetricsFromHeader(header, uint64(txCounts.GetTotal()), sp.marshalizer, sp.appStatusHandler)
+	sp.startBlockBackgroundRoutine(headerHandler, "getMetricsFromHeader", func() error {
+		metrics := newStatusMetricsBatch(sp.appStatusHandler)
+		defer metrics.flush()
+
+		return getMetricsFromHeader(header, uint64(txCounts.GetTotal()), sp.marshalizer, metrics)
+	})
 
 	sp.createBlockStarted()
 	sp.blockChainHook.SetCurrentHeader(headerHandler)
@@ -168,14 +642,15 @@ func (sp *shardProcessor) ProcessBlock(
 	requestedMetaHdrs, requestedFinalityAttestingMetaHdrs := sp.requestMetaHeaders(header)
 
 	if haveTime() < 0 {
-		return process.ErrTimeIsOut
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, process.ErrTimeIsOut)
 	}
 
 	err = sp.txCoordinator.IsDataPreparedForProcessing(haveTime)
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageTxProcessing, err)
 	}
 
+	missingMetaHdrs := uint32(0)
 	haveMissingMetaHeaders := requestedMetaHdrs > 0 || requestedFinalityAttestingMetaHdrs > 0
 	if haveMissingMetaHeaders {
 		if requestedMetaHdrs > 0 {
@@ -192,7 +667,7 @@ func (sp *shardProcessor) ProcessBlock(
 		err = sp.waitForMetaHdrHashes(haveTime())
 
 		sp.hdrsForCurrBlock.mutHdrsForBlock.RLock()
-		missingMetaHdrs := sp.hdrsForCurrBlock.missingHdrs
+		missingMetaHdrs = sp.hdrsForCurrBlock.missingHdrs
 		sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
 
 		sp.hdrsForCurrBlock.resetMissingHdrs()
@@ -202,84 +677,168 @@ func (sp *shardProcessor) ProcessBlock(
 				"num headers", requestedMetaHdrs-missingMetaHdrs,
 			)
 		}
+	}
 
-		if err != nil {
-			return err
-		}
+	sp.saveMetaHeadersRequestsMetrics(requestedMetaHdrs, requestedFinalityAttestingMetaHdrs, missingMetaHdrs)
+	if err != nil {
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
 	}
 
 	err = sp.requestEpochStartInfo(header, haveTime)
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
 	}
 
 	if sp.accountsDB[state.UserAccountsState].JournalLen() != 0 {
-		return process.ErrAccountStateDirty
+		return process.NewProcessBlockError(process.StageTxProcessing, process.ErrAccountStateDirty)
 	}
 
 	defer func() {
-		go sp.checkAndRequestIfMetaHeadersMissing()
+		sp.startBlockBackgroundRoutine(headerHandler, "checkAndRequestIfMetaHeadersMissing", sp.checkAndRequestIfMetaHeadersMissing)
 	}()
 
 	err = sp.checkEpochCorrectnessCrossChain()
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
 	}
 
 	err = sp.checkEpochCorrectness(header)
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
 	}
 
-	err = sp.checkMetaHeadersValidityAndFinality()
+	err = sp.checkMetaHeadersValidityAndFinality(options.shouldReportProcessingErrors)
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
 	}
 
-	err = sp.verifyCrossShardMiniBlockDstMe(header)
+	err = sp.checkHeaderEpochAgainstAttestedMetaBlocks(header)
 	if err != nil {
-		return err
+		return process.NewProcessBlockError(process.StageMetaHeadersValidity, err)
+	}
+	options.markStagePassed(process.StageMetaHeadersValidity)
+
+	err = sp.verifyCrossShardMiniBlockDstMe(header, body)
+	if err != nil {
+		return process.NewProcessBlockError(process.StageCrossShardMiniBlocks, err)
+	}
+	options.markStagePassed(process.StageCrossShardMiniBlocks)
+
+	err = sp.checkTimeForTxProcessing(haveTime)
+	if err != nil {
+		return process.NewProcessBlockError(process.StageTxProcessing, err)
 	}
 
 	defer func() {
 		if err != nil {
+			if options.shouldReportProcessingErrors {
+				sp.notifyBlockProcessingError(header, err)
+			}
 			sp.RevertAccountState(header)
 		}
 	}()
 
 	startTime := time.Now()
-	err = sp.txCoordinator.ProcessBlockTransaction(body, haveTime)
+	err = sp.processBlockTransactions(body, bodyComposition, haveTime)
 	elapsedTime := time.Since(startTime)
 	log.Debug("elapsed time to process block transaction",
 		"time [s]", elapsedTime,
 	)
 	if err != nil {
+		err = process.NewProcessBlockError(process.StageTxProcessing, err)
 		return err
 	}
 
-	err = sp.txCoordinator.VerifyCreatedBlockTransactions(header, body)
-	if err != nil {
-		return err
+	if sp.isSelfProposedBody(header, body) {
+		log.Debug("skipped created block transactions verification for self proposed body",
+			"round", header.GetRound(),
+			"nonce", header.GetNonce(),
+		)
+	} else {
+		err = sp.txCoordinator.VerifyCreatedBlockTransactions(header, body)
+		if err != nil {
+			err = process.NewProcessBlockError(process.StageTxProcessing, err)
+			return err
+		}
 	}
 
 	err = sp.txCoordinator.VerifyCreatedMiniBlocks(header, body)
 	if err != nil {
+		err = process.NewProcessBlockError(process.StageTxProcessing, err)
 		return err
 	}
 
 	err = sp.verifyFees(header)
 	if err != nil {
+		err = process.NewProcessBlockError(process.StageTxProcessing, err)
 		return err
 	}
+	options.markStagePassed(process.StageTxProcessing)
 
-	if !sp.verifyStateRoot(header.GetRootHash()) {
-		err = process.ErrRootStateDoesNotMatch
-		return err
+	if !options.shouldVerifyStateRoot {
+		log.Debug("skipped state root verification",
+			"round", header.GetRound(),
+			"nonce", header.GetNonce(),
+		)
+	}
+	if options.shouldVerifyStateRoot {
+		if !sp.verifyStateRoot(header.GetRootHash()) {
+			err = process.NewProcessBlockError(process.StageStateRoot, process.ErrRootStateDoesNotMatch)
+			return err
+		}
+		options.markStagePassed(process.StageStateRoot)
+	}
+
+	if options.shouldNotifyTransactionsProcessed {
+		sp.notifyTransactionsProcessed()
 	}
 
 	return nil
 }
 
+// NextExpectedRound returns the round expected for the next block, computed from the genesis time and the round
+// duration for the given current time. The returned round is never lower than the one following the last committed block
+func (sp *shardProcessor) NextExpectedRound(currentTime time.Time) uint64 {
+	nextRoundAfterLastCommitted := uint64(1)
+	lastCommittedHeader := sp.blockChain.GetCurrentBlockHeader()
+	if !check.IfNil(lastCommittedHeader) {
+		nextRoundAfterLastCommitted = lastCommittedHeader.GetRound() + 1
+	}
+
+	roundDuration := sp.rounder.TimeDuration()
+	elapsed := currentTime.Sub(sp.genesisTime)
+	if roundDuration <= 0 || elapsed < 0 {
+		return nextRoundAfterLastCommitted
+	}
+
+	nextRound := uint64(elapsed/roundDuration) + 1
+	if nextRound < nextRoundAfterLastCommitted {
+		return nextRoundAfterLastCommitted
+	}
+
+	return nextRound
+}
+
+func (sp *shardProcessor) notifyTransactionsProcessed() {
+	if sp.onTransactionsProcessed == nil {
+		return
+	}
+
+	sp.onTransactionsProcessed(sp.txCoordinator.GetTransactionsExecutionResults())
+}
+
+func (sp *shardProcessor) notifyNotarizedHeadersPruned(numPrunedHeaders uint64) {
+	if numPrunedHeaders > 0 {
+		sp.appStatusHandler.AddUint64(core.MetricNotarizedHeadersPruned, numPrunedHeaders)
+	}
+
+	if sp.onNotarizedHeadersPruned == nil {
+		return
+	}
+
+	sp.onNotarizedHeadersPruned(numPrunedHeaders)
+}
+
 func (sp *shardProcessor) requestEpochStartInfo(header *block.Header, haveTime func() time.Duration) error {
 	if !header.IsStartOfEpochBlock() {
 		return nil
@@ -321,8 +880,23 @@ func (sp *shardProcessor) requestEpochStartInfo(header *block.Header, haveTime f
 	return process.ErrTimeIsOut
 }
 
+// RevertAccountState reverts the account state for cleanup failed process and forgets the last processed block and
+// the self proposed body, as their processing results are no longer valid on the reverted state
+func (sp *shardProcessor) RevertAccountState(header data.HeaderHandler) {
+	sp.isBlockInProgress.Unset()
+	sp.lastProcessedBlock.reset()
+	sp.resetSelfProposedBody()
+	sp.baseProcessor.RevertAccountState(header)
+}
+
 // RevertStateToBlock recreates the state tries to the root hashes indicated by the provided header
 func (sp *shardProcessor) RevertStateToBlock(header data.HeaderHandler) error {
+	sp.mutBlockProcessing.Lock()
+	defer sp.mutBlockProcessing.Unlock()
+
+	sp.isBlockInProgress.Unset()
+	sp.lastProcessedBlock.reset()
+	sp.resetSelfProposedBody()
 
 	err := sp.accountsDB[state.UserAccountsState].RecreateTrie(header.GetRootHash())
 	if err != nil {
@@ -431,7 +1005,7 @@ func (sp *shardProcessor) SetNumProcessedObj(numObj uint64) {
 }
 
 // checkMetaHeadersValidity - checks if listed metaheaders are valid as construction
-func (sp *shardProcessor) checkMetaHeadersValidityAndFinality() error {
+func (sp *shardProcessor) checkMetaHeadersValidityAndFinality(shouldReportRejections bool) error {
 	lastCrossNotarizedHeader, _, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
 	if err != nil {
 		return err
@@ -445,6 +1019,9 @@ func (sp *shardProcessor) checkMetaHeadersValidityAndFinality() error {
 	for _, metaHdr := range usedMetaHdrs[core.MetachainShardId] {
 		err = sp.headerValidator.IsHeaderConstructionValid(metaHdr, lastCrossNotarizedHeader)
 		if err != nil {
+			if shouldReportRejections {
+				sp.appStatusHandler.Increment(core.MetricMetaHdrConstructionRejections)
+			}
 			return fmt.Errorf("%w : checkMetaHeadersValidityAndFinality -> isHdrConstructionValid", err)
 		}
 
@@ -466,45 +1043,138 @@ func (sp *shardProcessor) checkMetaHdrFinality(header data.HeaderHandler) error
 	}
 
 	finalityAttestingMetaHdrs := sp.sortHeadersForCurrentBlockByNonce(false)
-
-	lastVerifiedHdr := header
-	// verify if there are "K" block after current to make this one final
-	nextBlocksVerified := uint32(0)
+	metaHdrsByNonce := make(map[uint64][]data.HeaderHandler)
 	for _, metaHdr := range finalityAttestingMetaHdrs[core.MetachainShardId] {
-		if nextBlocksVerified >= sp.metaBlockFinality {
-			break
+		metaHdrsByNonce[metaHdr.GetNonce()] = append(metaHdrsByNonce[metaHdr.GetNonce()], metaHdr)
+	}
+
+	getMetaHeadersWithNonce := func(nonce uint64) []data.HeaderHandler {
+		return metaHdrsByNonce[nonce]
+	}
+
+	return sp.checkMetaHdrFinalityWithHeaders(header, getMetaHeadersWithNonce)
+}
+
+func (sp *shardProcessor) checkMetaHdrFinalityWithHeaders(
+	header data.HeaderHandler,
+	getMetaHeadersWithNonce func(nonce uint64) []data.HeaderHandler,
+) error {
+	if !sp.isMetaHeaderAssertedFinal(header) && !sp.metaFinalityVerifier.IsMetaHeaderFinal(header, getMetaHeadersWithNonce) {
+		for nonce := header.GetNonce() + 1; nonce <= header.GetNonce()+uint64(sp.metaBlockFinality); nonce++ {
+			go sp.requestHandler.RequestMetaHeaderByNonce(nonce)
 		}
+		return process.ErrHeaderNotFinal
+	}
 
-		// found a header with the next nonce
-		if metaHdr.GetNonce() == lastVerifiedHdr.GetNonce()+1 {
-			err := sp.headerValidator.IsHeaderConstructionValid(metaHdr, lastVerifiedHdr)
-			if err != nil {
-				log.Debug("checkMetaHdrFinality -> isHdrConstructionValid",
-					"error", err.Error())
-				continue
-			}
+	return nil
+}
+
+// checkTopAttestedMetaHdrFinalityFromPool verifies again, before the block is committed, the finality of the highest
+// meta header attested by the current block, this time against the meta headers currently found in pool, which could
+// have changed since the block was processed
+func (sp *shardProcessor) checkTopAttestedMetaHdrFinalityFromPool() error {
+	usedMetaHdrHashes := sp.sortHeaderHashesForCurrentBlockByNonce(true)
+	numUsedMetaHdrs := len(usedMetaHdrHashes[core.MetachainShardId])
+	if numUsedMetaHdrs == 0 {
+		return nil
+	}
+
+	topMetaHdrHash := usedMetaHdrHashes[core.MetachainShardId][numUsedMetaHdrs-1]
+	topMetaHdr, err := sp.dataPool.Headers().GetHeaderByHash(topMetaHdrHash)
+	if err != nil {
+		return fmt.Errorf("%w, meta header hash: %s, error: %s",
+			process.ErrFinalityChangedBeforeCommit, logger.DisplayByteSlice(topMetaHdrHash), err.Error())
+	}
 
-			lastVerifiedHdr = metaHdr
-			nextBlocksVerified += 1
+	getMetaHeadersWithNonce := func(nonce uint64) []data.HeaderHandler {
+		metaHdrs, _, errGet := sp.dataPool.Headers().GetHeadersByNonceAndShardId(nonce, core.MetachainShardId)
+		if errGet != nil {
+			return nil
 		}
+
+		return metaHdrs
 	}
 
-	if nextBlocksVerified < sp.metaBlockFinality {
-		go sp.requestHandler.RequestMetaHeaderByNonce(lastVerifiedHdr.GetNonce())
-		go sp.requestHandler.RequestMetaHeaderByNonce(lastVerifiedHdr.GetNonce() + 1)
-		return process.ErrHeaderNotFinal
+	err = sp.checkMetaHdrFinalityWithHeaders(topMetaHdr, getMetaHeadersWithNonce)
+	if err != nil {
+		return fmt.Errorf("%w, meta header nonce: %d, error: %s",
+			process.ErrFinalityChangedBeforeCommit, topMetaHdr.GetNonce(), err.Error())
 	}
 
 	return nil
 }
 
-func (sp *shardProcessor) checkAndRequestIfMetaHeadersMissing() {
+func (sp *shardProcessor) checkAndRequestIfMetaHeadersMissing() error {
 	orderedMetaBlocks, _ := sp.blockTracker.GetTrackedHeaders(core.MetachainShardId)
 
-	err := sp.requestHeadersIfMissing(orderedMetaBlocks, core.MetachainShardId)
-	if err != nil {
-		log.Debug("checkAndRequestIfMetaHeadersMissing", "error", err.Error())
+	return sp.requestHeadersIfMissing(orderedMetaBlocks, core.MetachainShardId)
+}
+
+// CurrentUsedTxCounts returns, for each block type, the number of transactions currently used by the transaction
+// coordinator
+func (sp *shardProcessor) CurrentUsedTxCounts() map[block.Type]int {
+	usedTxCounts := make(map[block.Type]int, len(usedTxsBlockTypes))
+	for _, blockType := range usedTxsBlockTypes {
+		usedTxCounts[blockType] = len(sp.txCoordinator.GetAllCurrentUsedTxs(blockType))
 	}
+
+	return usedTxCounts
+}
+
+// getAllCurrentUsedTxsForBlock returns all the transactions, of all types, currently used by the transaction coordinator
+func (sp *shardProcessor) getAllCurrentUsedTxsForBlock() map[string]data.TransactionHandler {
+	txPool := make(map[string]data.TransactionHandler)
+	for _, blockType := range usedTxsBlockTypes {
+		for hash, tx := range sp.txCoordinator.GetAllCurrentUsedTxs(blockType) {
+			txPool[hash] = tx
+		}
+	}
+
+	return txPool
+}
+
+// exportBlockTxsIfNeeded writes, if the txs export directory is set, the transactions of the committed block in a
+// per block file. The export is best effort: the errors are only logged and counted
+func (sp *shardProcessor) exportBlockTxsIfNeeded(header data.HeaderHandler, headerHash []byte) {
+	if len(sp.txExportDir) == 0 {
+		return
+	}
+
+	fileName := createTxsExportFileName(header, headerHash)
+	err := exportTxsToFile(sp.txExportDir, fileName, sp.getAllCurrentUsedTxsForBlock())
+	if err != nil {
+		sp.numTxExportErrors.Increment()
+		log.Debug("exportBlockTxsIfNeeded",
+			"hash", headerHash,
+			"nonce", header.GetNonce(),
+			"error", err.Error(),
+		)
+	}
+}
+
+// NumTxExportErrors returns the number of committed blocks whose transactions could not be exported
+func (sp *shardProcessor) NumTxExportErrors() uint64 {
+	return sp.numTxExportErrors.GetUint64()
+}
+
+// compactAndConvertProcessedMiniBlocks compacts, if the compaction interval has elapsed since the last compaction, the
+// processed miniblocks and returns them in the form saved by the boot storer
+func (sp *shardProcessor) compactAndConvertProcessedMiniBlocks() []bootstrapStorage.MiniBlocksInMeta {
+	if sp.processedMbsCompactionInterval > 0 && time.Since(sp.lastProcessedMbsCompaction) >= sp.processedMbsCompactionInterval {
+		sp.lastProcessedMbsCompaction = time.Now()
+
+		numRemoved := sp.processedMiniBlocks.CompactProcessedMiniBlocks()
+		sp.numRemovedEmptyProcessedMbs.Add(int64(numRemoved))
+		log.Debug("compacted processed mini blocks", "num removed meta blocks without processed mini blocks", numRemoved)
+	}
+
+	return sp.processedMiniBlocks.ConvertProcessedMiniBlocksMapToSlice()
+}
+
+// NumRemovedEmptyProcessedMiniBlocks returns the number of meta block hashes without any processed miniblock left,
+// which were removed by the periodic processed miniblocks compaction
+func (sp *shardProcessor) NumRemovedEmptyProcessedMiniBlocks() uint64 {
+	return sp.numRemovedEmptyProcessedMbs.GetUint64()
 }
 
 func (sp *shardProcessor) indexBlockIfNeeded(
@@ -524,24 +1194,7 @@ func (sp *shardProcessor) indexBlockIfNeeded(
 	}
 
 	log.Debug("preparing to index block", "hash", headerHash, "nonce", header.GetNonce(), "round", header.GetRound())
-	txPool := sp.txCoordinator.GetAllCurrentUsedTxs(block.TxBlock)
-	scPool := sp.txCoordinator.GetAllCurrentUsedTxs(block.SmartContractResultBlock)
-	rewardPool := sp.txCoordinator.GetAllCurrentUsedTxs(block.RewardsBlock)
-	invalidPool := sp.txCoordinator.GetAllCurrentUsedTxs(block.InvalidBlock)
-	receiptPool := sp.txCoordinator.GetAllCurrentUsedTxs(block.ReceiptBlock)
-
-	for hash, tx := range scPool {
-		txPool[hash] = tx
-	}
-	for hash, tx := range rewardPool {
-		txPool[hash] = tx
-	}
-	for hash, tx := range invalidPool {
-		txPool[hash] = tx
-	}
-	for hash, tx := range receiptPool {
-		txPool[hash] = tx
-	}
+	txPool := sp.getAllCurrentUsedTxsForBlock()
 
 	shardId := sp.shardCoordinator.SelfId()
 
@@ -593,13 +1246,47 @@ func (sp *shardProcessor) indexBlockIfNeeded(
 		return
 	}
 
+	txPool = sp.transformIndexedTxs(txPool)
+
 	sp.indexer.SaveBlock(body, header, txPool, signersIndexes, nil, headerHash)
 	log.Debug("indexed block", "hash", headerHash, "nonce", header.GetNonce(), "round", header.GetRound())
 
 	indexRoundInfo(sp.indexer, sp.nodesCoordinator, shardId, header, lastBlockHeader, signersIndexes)
 }
 
-// RestoreBlockIntoPools restores the TxBlock and MetaBlock into associated pools
+func (sp *shardProcessor) transformIndexedTxs(txPool map[string]data.TransactionHandler) map[string]data.TransactionHandler {
+	if sp.indexedTxTransformer == nil {
+		return txPool
+	}
+
+	transformedTxs := sp.indexedTxTransformer(txPool)
+	indexedTxs := make(map[string]data.TransactionHandler, len(txPool))
+	for hash, transformedTx := range transformedTxs {
+		tx, ok := transformedTx.(data.TransactionHandler)
+		if !ok || check.IfNil(tx) {
+			log.Debug("indexBlockIfNeeded: transformed tx is not a transaction handler, skipping", "hash", []byte(hash))
+			continue
+		}
+
+		indexedTxs[hash] = tx
+	}
+
+	for hash, tx := range txPool {
+		_, isTransformed := indexedTxs[hash]
+		if isTransformed {
+			continue
+		}
+
+		log.Debug("indexBlockIfNeeded: missing or unusable transformed tx, indexing the original one", "hash", []byte(hash))
+		indexedTxs[hash] = tx
+	}
+
+	return indexedTxs
+}
+
+// RestoreBlockIntoPools restores the TxBlock and MetaBlock into associated pools. If some of the attested meta blocks
+// could not be fetched from storage, the rest of the block is still restored and a PartialRestoreError holding their
+// hashes is returned
 func (sp *shardProcessor) RestoreBlockIntoPools(headerHandler data.HeaderHandler, bodyHandler data.BodyHandler) error {
 	if check.IfNil(headerHandler) {
 		return process.ErrNilBlockHeader
@@ -610,8 +1297,17 @@ func (sp *shardProcessor) RestoreBlockIntoPools(headerHandler data.HeaderHandler
 		return process.ErrWrongTypeAssertion
 	}
 
+	sp.lastProcessedBlock.reset()
+	sp.resetSelfProposedBody()
+
+	numConsecutiveRestores := sp.numConsecutiveRestores.GetUint64()
+	if sp.maxReorgDepth > 0 && numConsecutiveRestores >= uint64(sp.maxReorgDepth) {
+		return fmt.Errorf("%w, num consecutive restored blocks: %d, max reorg depth: %d",
+			process.ErrReorgTooDeep, numConsecutiveRestores, sp.maxReorgDepth)
+	}
+
 	miniBlockHashes := header.MapMiniBlockHashesToShards()
-	err := sp.restoreMetaBlockIntoPool(miniBlockHashes, header.MetaBlockHashes)
+	failedMetaBlockHashes, err := sp.restoreMetaBlockIntoPool(miniBlockHashes, header.MetaBlockHashes)
 	if err != nil {
 		return err
 	}
@@ -620,19 +1316,30 @@ func (sp *shardProcessor) RestoreBlockIntoPools(headerHandler data.HeaderHandler
 
 	sp.blockTracker.RemoveLastNotarizedHeaders()
 
+	sp.numConsecutiveRestores.Increment()
+
+	if len(failedMetaBlockHashes) > 0 {
+		return process.NewPartialRestoreError(failedMetaBlockHashes)
+	}
+
 	return nil
 }
 
-func (sp *shardProcessor) restoreMetaBlockIntoPool(mapMiniBlockHashes map[string]uint32, metaBlockHashes [][]byte) error {
+// restoreMetaBlockIntoPool puts back into pool the given meta blocks, returning the hashes of the ones which could not
+// be fetched from storage
+func (sp *shardProcessor) restoreMetaBlockIntoPool(mapMiniBlockHashes map[string]uint32, metaBlockHashes [][]byte) ([][]byte, error) {
 	headersPool := sp.dataPool.Headers()
 
 	mapMetaHashMiniBlockHashes := make(map[string][][]byte, len(metaBlockHashes))
+	failedMetaBlockHashes := make([][]byte, 0)
 
 	for _, metaBlockHash := range metaBlockHashes {
 		metaBlock, errNotCritical := process.GetMetaHeaderFromStorage(metaBlockHash, sp.marshalizer, sp.store)
 		if errNotCritical != nil {
 			log.Debug("meta block is not fully processed yet and not committed in MetaBlockUnit",
-				"hash", metaBlockHash)
+				"hash", metaBlockHash,
+				"error", errNotCritical.Error())
+			failedMetaBlockHashes = append(failedMetaBlockHashes, metaBlockHash)
 			continue
 		}
 
@@ -647,7 +1354,7 @@ func (sp *shardProcessor) restoreMetaBlockIntoPool(mapMiniBlockHashes map[string
 		if err != nil {
 			log.Debug("unable to remove hash from MetaBlockUnit",
 				"hash", metaBlockHash)
-			return err
+			return nil, err
 		}
 
 		nonceToByteSlice := sp.uint64Converter.ToByteSlice(metaBlock.GetNonce())
@@ -673,7 +1380,7 @@ func (sp *shardProcessor) restoreMetaBlockIntoPool(mapMiniBlockHashes map[string
 		sp.processedMiniBlocks.RemoveMiniBlockHash(miniBlockHash)
 	}
 
-	return nil
+	return failedMetaBlockHashes, nil
 }
 
 // CreateBlock creates the final block and header for the current round
@@ -689,7 +1396,21 @@ func (sp *shardProcessor) CreateBlock(
 		return nil, nil, process.ErrWrongTypeAssertion
 	}
 
+	sp.mutBlockProcessing.Lock()
+	defer sp.mutBlockProcessing.Unlock()
+
+	var err error
+	sp.isBlockInProgress.Set()
+	defer func() {
+		if err != nil {
+			sp.isBlockInProgress.Unset()
+		}
+	}()
+
 	sp.createBlockStarted()
+	sp.resetAttestationDecisions()
+	sp.lastProcessedBlock.reset()
+	sp.resetSelfProposedBody()
 
 	if sp.epochStartTrigger.IsEpochStart() {
 		log.Debug("CreateBlock", "IsEpochStart", sp.epochStartTrigger.IsEpochStart(),
@@ -711,6 +1432,18 @@ func (sp *shardProcessor) CreateBlock(
 		return nil, nil, err
 	}
 
+	err = checkMiniBlockHeadersAreUnique(shardHdr.MiniBlockHeaders)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = sp.checkRootHashLength(shardHdr.GetRootHash())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sp.setSelfProposedBody(finalBody, shardHdr.GetRound())
+
 	for _, miniBlock := range finalBody.MiniBlocks {
 		log.Trace("CreateBlock: miniblock",
 			"sender shard", miniBlock.SenderShardID,
@@ -722,6 +1455,51 @@ func (sp *shardProcessor) CreateBlock(
 	return shardHdr, finalBody, nil
 }
 
+// setSelfProposedBody remembers the hash of the body created by this node, through CreateBlock, for the given round
+func (sp *shardProcessor) setSelfProposedBody(body *block.Body, round uint64) {
+	bodyHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, body)
+	if err != nil {
+		log.Debug("setSelfProposedBody.CalculateHash", "error", err.Error())
+		sp.resetSelfProposedBody()
+		return
+	}
+
+	sp.selfProposedBodyHash = bodyHash
+	sp.selfProposedBodyRound = round
+}
+
+func (sp *shardProcessor) resetSelfProposedBody() {
+	sp.selfProposedBodyHash = nil
+	sp.selfProposedBodyRound = 0
+}
+
+// isSelfProposedBody returns true if the given body has the same hash as the one created by this node, through
+// CreateBlock, for the round of the given header. The intermediate results of such a body were already created by
+// this node, so their verification would be redundant
+func (sp *shardProcessor) isSelfProposedBody(header data.HeaderHandler, body *block.Body) bool {
+	if len(sp.selfProposedBodyHash) == 0 || sp.selfProposedBodyRound != header.GetRound() {
+		return false
+	}
+
+	bodyHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, body)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(bodyHash, sp.selfProposedBodyHash)
+}
+
+// checkRootHashLength checks that the root hash provided by the accounts adapter has the length of the configured
+// hasher output, as otherwise the created header would be rejected by the other nodes
+func (sp *shardProcessor) checkRootHashLength(rootHash []byte) error {
+	if len(rootHash) != sp.hasher.Size() {
+		return fmt.Errorf("%w, expected: %d, actual: %d",
+			process.ErrInvalidRootHashLength, sp.hasher.Size(), len(rootHash))
+	}
+
+	return nil
+}
+
 // createBlockBody creates a a list of miniblocks by filling them with transactions out of the transactions pools
 // as long as the transactions limit for the block has not been reached and there is still time to add transactions
 func (sp *shardProcessor) createBlockBody(shardHdr *block.Header, haveTime func() bool) (*block.Body, error) {
@@ -733,21 +1511,86 @@ func (sp *shardProcessor) createBlockBody(shardHdr *block.Header, haveTime func(
 		"nonce", shardHdr.GetNonce(),
 	)
 
-	miniBlocks, err := sp.createMiniBlocks(haveTime)
+	sp.blockProduction.addAttempt(shardHdr.GetRound())
+	sp.saveBlockProductionSuccessRateMetric(sp.appStatusHandler)
+
+	miniBlocks, bodyComposition, err := sp.createMiniBlocks(haveTime, shardHdr.GetEpoch())
 	if err != nil {
 		return nil, err
 	}
+	setBodyComposition(shardHdr, bodyComposition)
 
 	sp.requestHandler.SetEpoch(shardHdr.GetEpoch())
 
+	if !sp.blockCreationPolicy.ProduceEmptyBlocks() && !sp.hasWorkToPropose(miniBlocks) {
+		log.Debug("skipped proposing an empty block",
+			"round", shardHdr.GetRound(),
+			"nonce", shardHdr.GetNonce(),
+		)
+		return nil, process.ErrNoWorkToPropose
+	}
+
+	sp.setCreatedBody(shardHdr.GetRound(), miniBlocks)
+
 	return miniBlocks, nil
 }
 
+// setCreatedBody remembers the round for which the given block body was created
+func (sp *shardProcessor) setCreatedBody(round uint64, body *block.Body) {
+	bodyHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, body)
+	if err != nil {
+		log.Debug("setCreatedBody.CalculateHash", "error", err.Error())
+		sp.createdBodyHash = nil
+		return
+	}
+
+	sp.createdBodyRound = round
+	sp.createdBodyHash = bodyHash
+}
+
+// hasWorkToPropose returns true if the created body has miniblocks, if there are meta blocks to be attested in the
+// current block or if an epoch start block should be created
+func (sp *shardProcessor) hasWorkToPropose(body *block.Body) bool {
+	if len(body.MiniBlocks) > 0 {
+		return true
+	}
+	if sp.epochStartTrigger.IsEpochStart() {
+		return true
+	}
+
+	metaBlockHashes := sp.sortHeaderHashesForCurrentBlockByNonce(true)
+	return len(metaBlockHashes[core.MetachainShardId]) > 0
+}
+
+// ComputeHeaderHash returns the hash of the given header, computed in the same way as when the block is committed
+func (sp *shardProcessor) ComputeHeaderHash(header data.HeaderHandler) ([]byte, error) {
+	if check.IfNil(header) {
+		return nil, process.ErrNilBlockHeader
+	}
+
+	headerHash, _, err := sp.computeHeaderHashAndBytes(header)
+	return headerHash, err
+}
+
+func (sp *shardProcessor) computeHeaderHashAndBytes(header data.HeaderHandler) ([]byte, []byte, error) {
+	marshalizedHeader, err := sp.marshalizer.Marshal(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sp.hasher.Compute(string(marshalizedHeader)), marshalizedHeader, nil
+}
+
 // CommitBlock commits the block in the blockchain if everything was checked successfully
 func (sp *shardProcessor) CommitBlock(
 	headerHandler data.HeaderHandler,
 	bodyHandler data.BodyHandler,
 ) error {
+	startTime := time.Now()
+	metrics := newStatusMetricsBatch(sp.appStatusHandler)
+	defer metrics.flush()
+	defer sp.isBlockInProgress.Unset()
+
 	var err error
 	defer func() {
 		if err != nil {
@@ -768,6 +1611,11 @@ func (sp *shardProcessor) CommitBlock(
 		"nonce", headerHandler.GetNonce(),
 	)
 
+	err = sp.checkTopAttestedMetaHdrFinalityFromPool()
+	if err != nil {
+		return err
+	}
+
 	err = sp.checkBlockValidity(headerHandler, bodyHandler)
 	if err != nil {
 		return err
@@ -779,6 +1627,11 @@ func (sp *shardProcessor) CommitBlock(
 		return err
 	}
 
+	err = sp.checkParentHeaderExists(header)
+	if err != nil {
+		return err
+	}
+
 	err = sp.checkEpochCorrectnessCrossChain()
 	if err != nil {
 		return err
@@ -788,13 +1641,11 @@ func (sp *shardProcessor) CommitBlock(
 		sp.epochStartTrigger.SetProcessed(header, bodyHandler)
 	}
 
-	marshalizedHeader, err := sp.marshalizer.Marshal(header)
+	headerHash, marshalizedHeader, err := sp.computeHeaderHashAndBytes(header)
 	if err != nil {
 		return err
 	}
 
-	headerHash := sp.hasher.Compute(string(marshalizedHeader))
-
 	sp.saveShardHeader(header, headerHash, marshalizedHeader)
 
 	body, ok := bodyHandler.(*block.Body)
@@ -803,9 +1654,10 @@ func (sp *shardProcessor) CommitBlock(
 		return err
 	}
 
-	sp.saveBody(body, header)
+	miniBlocksSize := sp.saveBody(body, header)
+	metrics.SetUInt64Value(core.MetricCommittedBlockSizeBytes, uint64(len(marshalizedHeader)+miniBlocksSize))
 
-	processedMetaHdrs, err := sp.getOrderedProcessedMetaBlocksFromHeader(header)
+	processedMetaHdrs, processedMetaHdrsHashes, err := sp.getOrderedProcessedMetaBlocksAndHashesFromHeader(header)
 	if err != nil {
 		return err
 	}
@@ -838,6 +1690,12 @@ func (sp *shardProcessor) CommitBlock(
 		"hash", headerHash,
 	)
 
+	sp.blockProduction.addCommit(header.GetRound())
+	sp.saveBlockProductionSuccessRateMetric(metrics)
+
+	// a committed block ends any reorg in progress
+	sp.numConsecutiveRestores.Reset()
+
 	errNotCritical := sp.updateCrossShardInfo(processedMetaHdrs)
 	if errNotCritical != nil {
 		log.Debug("updateCrossShardInfo", "error", errNotCritical.Error())
@@ -854,7 +1712,7 @@ func (sp *shardProcessor) CommitBlock(
 	lastSelfNotarizedHeader, lastSelfNotarizedHeaderHash := sp.getLastSelfNotarizedHeaderByMetachain()
 	sp.blockTracker.AddSelfNotarizedHeader(core.MetachainShardId, lastSelfNotarizedHeader, lastSelfNotarizedHeaderHash)
 
-	sp.notifyFinalMetaHdrs(processedMetaHdrs)
+	sp.notifyFinalMetaHdrs(processedMetaHdrs, processedMetaHdrsHashes)
 
 	sp.updateState(selfNotarizedHeaders, header)
 
@@ -863,6 +1721,7 @@ func (sp *shardProcessor) CommitBlock(
 		"nonce", highestFinalBlockNonce,
 		"shard", sp.shardCoordinator.SelfId(),
 	)
+	sp.notifyFinalBlockAdvance(highestFinalBlockNonce)
 
 	lastBlockHeader := sp.blockChain.GetCurrentBlockHeader()
 
@@ -872,7 +1731,10 @@ func (sp *shardProcessor) CommitBlock(
 	}
 
 	sp.blockChain.SetCurrentBlockHeaderHash(headerHash)
+	sp.lastProcessedBlock.reset()
+	sp.resetSelfProposedBody()
 	sp.indexBlockIfNeeded(bodyHandler, headerHash, headerHandler, lastBlockHeader)
+	sp.exportBlockTxsIfNeeded(headerHandler, headerHash)
 	sp.recordBlockInHistory(headerHash, headerHandler, bodyHandler)
 
 	lastCrossNotarizedHeader, _, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
@@ -882,7 +1744,7 @@ func (sp *shardProcessor) CommitBlock(
 
 	saveMetricsForCommittedShardBlock(
 		sp.nodesCoordinator,
-		sp.appStatusHandler,
+		metrics,
 		logger.DisplayByteSlice(headerHash),
 		highestFinalBlockNonce,
 		lastCrossNotarizedHeader,
@@ -904,7 +1766,7 @@ func (sp *shardProcessor) CommitBlock(
 		round:                      header.Round,
 		lastSelfNotarizedHeaders:   sp.getBootstrapHeadersInfo(selfNotarizedHeaders, selfNotarizedHeadersHashes),
 		highestFinalBlockNonce:     sp.forkDetector.GetHighestFinalBlockNonce(),
-		processedMiniBlocks:        sp.processedMiniBlocks.ConvertProcessedMiniBlocksMapToSlice(),
+		processedMiniBlocks:        sp.compactAndConvertProcessedMiniBlocks(),
 		nodesCoordinatorConfigKey:  nodesCoordinatorKey,
 		epochStartTriggerConfigKey: epochStartKey,
 	}
@@ -924,6 +1786,7 @@ func (sp *shardProcessor) CommitBlock(
 	)
 
 	sp.blockSizeThrottler.Succeed(header.Round)
+	sp.setLastThrottleSuccess(header.Round)
 
 	sp.displayPoolsInfo()
 
@@ -932,28 +1795,22 @@ func (sp *shardProcessor) CommitBlock(
 		log.Debug("removeTxsFromPools", "error", errNotCritical.Error())
 	}
 
-	sp.cleanupPools(headerHandler)
+	numPrunedHeaders := sp.cleanupPools(headerHandler)
+	sp.notifyNotarizedHeadersPruned(numPrunedHeaders)
 
-	return nil
-}
+	sp.notifyCommittedBlockSinks(header, body, headerHash)
 
-func (sp *shardProcessor) notifyFinalMetaHdrs(processedMetaHeaders []data.HeaderHandler) {
-	metaHeaders := make([]data.HeaderHandler, 0)
-	metaHeadersHashes := make([][]byte, 0)
+	sp.startBackgroundRoutine(sp.PrecomputeNextRoundMetaBlocks)
 
-	for _, metaHeader := range processedMetaHeaders {
-		metaHeaderHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, metaHeader)
-		if err != nil {
-			log.Debug("shardProcessor.notifyFinalMetaHdrs", "error", err.Error())
-			continue
-		}
+	metrics.SetUInt64Value(core.MetricGasRefundedPerBlock, sp.txCoordinator.GetTotalGasRefunded())
+	metrics.SetUInt64Value(core.MetricCommitBlockDurationMs, uint64(time.Since(startTime).Milliseconds()))
 
-		metaHeaders = append(metaHeaders, metaHeader)
-		metaHeadersHashes = append(metaHeadersHashes, metaHeaderHash)
-	}
+	return nil
+}
 
-	if len(metaHeaders) > 0 {
-		go sp.historyRepo.OnNotarizedBlocks(core.MetachainShardId, metaHeaders, metaHeadersHashes)
+func (sp *shardProcessor) notifyFinalMetaHdrs(processedMetaHeaders []data.HeaderHandler, processedMetaHeadersHashes [][]byte) {
+	if len(processedMetaHeaders) > 0 {
+		go sp.historyRepo.OnNotarizedBlocks(core.MetachainShardId, processedMetaHeaders, processedMetaHeadersHashes)
 	}
 }
 
@@ -1176,16 +2033,25 @@ func (sp *shardProcessor) getHighestHdrForOwnShardFromMetachain(
 
 	ownShIdHdrs := make([]data.HeaderHandler, 0, len(processedHdrs))
 
+	numWrongTypeHdrs := 0
 	for i := 0; i < len(processedHdrs); i++ {
 		hdr, ok := processedHdrs[i].(*block.MetaBlock)
 		if !ok {
-			return nil, nil, process.ErrWrongTypeAssertion
+			numWrongTypeHdrs++
+			continue
 		}
 
 		hdrs := sp.getHighestHdrForShardFromMetachain(sp.shardCoordinator.SelfId(), hdr)
 		ownShIdHdrs = append(ownShIdHdrs, hdrs...)
 	}
 
+	if numWrongTypeHdrs > 0 {
+		log.Warn("skipped processed headers which are not meta blocks",
+			"num skipped", numWrongTypeHdrs,
+			"num processed", len(processedHdrs),
+		)
+	}
+
 	process.SortHeadersByNonce(ownShIdHdrs)
 
 	ownShIdHdrsHashes := make([][]byte, len(ownShIdHdrs))
@@ -1200,13 +2066,26 @@ func (sp *shardProcessor) getHighestHdrForOwnShardFromMetachain(
 func (sp *shardProcessor) getHighestHdrForShardFromMetachain(shardId uint32, hdr *block.MetaBlock) []data.HeaderHandler {
 	ownShIdHdr := make([]data.HeaderHandler, 0, len(hdr.ShardInfo))
 
+	numRequests := uint32(0)
+	numSkippedRequests := 0
+	numEmptyHeaderHashes := 0
 	for _, shardInfo := range hdr.ShardInfo {
 		if shardInfo.ShardID != shardId {
 			continue
 		}
+		if len(shardInfo.HeaderHash) == 0 {
+			numEmptyHeaderHashes++
+			continue
+		}
 
 		ownHdr, err := process.GetShardHeader(shardInfo.HeaderHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
 		if err != nil {
+			if !sp.canRequestShardHeader(numRequests) {
+				numSkippedRequests++
+				continue
+			}
+
+			numRequests++
 			go sp.requestHandler.RequestShardHeader(shardInfo.ShardID, shardInfo.HeaderHash)
 
 			log.Debug("requested missing shard header",
@@ -1219,94 +2098,364 @@ func (sp *shardProcessor) getHighestHdrForShardFromMetachain(shardId uint32, hdr
 		ownShIdHdr = append(ownShIdHdr, ownHdr)
 	}
 
+	if numRequests > 0 {
+		sp.appStatusHandler.AddUint64(core.MetricNumShardHeadersRequestedFromMeta, uint64(numRequests))
+	}
+	if numSkippedRequests > 0 {
+		log.Debug("skipped requesting missing shard headers",
+			"meta nonce", hdr.Nonce,
+			"num skipped", numSkippedRequests,
+			"max requests", sp.maxShardHeaderRequestsPerMeta,
+		)
+	}
+	if numEmptyHeaderHashes > 0 {
+		log.Warn("skipped shard info entries with empty header hash",
+			"meta nonce", hdr.Nonce,
+			"shard", shardId,
+			"num skipped", numEmptyHeaderHashes,
+		)
+	}
+
 	return data.TrimHeaderHandlerSlice(ownShIdHdr)
 }
 
-// getOrderedProcessedMetaBlocksFromHeader returns all the meta blocks fully processed
-func (sp *shardProcessor) getOrderedProcessedMetaBlocksFromHeader(header *block.Header) ([]data.HeaderHandler, error) {
-	if header == nil {
-		return nil, process.ErrNilBlockHeader
+// areSelfShardInfoHeadersAvailable returns true if all the self shard headers referenced by the given meta block can be
+// found in pool or storage. The missing ones are requested, so that the meta block could be attested in a later round
+func (sp *shardProcessor) areSelfShardInfoHeadersAvailable(metaHdr data.HeaderHandler) bool {
+	metaBlock, ok := metaHdr.(*block.MetaBlock)
+	if !ok {
+		return false
 	}
 
-	miniBlockHashes := make(map[int][]byte, len(header.MiniBlockHeaders))
-	for i := 0; i < len(header.MiniBlockHeaders); i++ {
-		miniBlockHashes[i] = header.MiniBlockHeaders[i].Hash
+	numRequests := uint32(0)
+	numMissing := 0
+	for _, shardInfo := range metaBlock.ShardInfo {
+		if shardInfo.ShardID != sp.shardCoordinator.SelfId() {
+			continue
+		}
+		if len(shardInfo.HeaderHash) == 0 {
+			continue
+		}
+
+		_, err := process.GetShardHeader(shardInfo.HeaderHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+		if err == nil {
+			continue
+		}
+
+		numMissing++
+		if !sp.canRequestShardHeader(numRequests) {
+			continue
+		}
+
+		numRequests++
+		go sp.requestHandler.RequestShardHeader(shardInfo.ShardID, shardInfo.HeaderHash)
 	}
 
-	log.Trace("cross mini blocks in body",
-		"num miniblocks", len(miniBlockHashes),
-	)
+	if numMissing > 0 {
+		log.Debug("self shard headers referenced by meta block are missing",
+			"meta nonce", metaBlock.Nonce,
+			"num missing", numMissing,
+			"num requested", numRequests,
+		)
+	}
+
+	return numMissing == 0
+}
 
-	processedMetaBlocks, err := sp.getOrderedProcessedMetaBlocksFromMiniBlockHashes(miniBlockHashes)
+// AttestationCoverage returns the metablocks which notarized the shard header with the given hash. It walks back the
+// metachain starting from the last cross notarized metablock, until it reaches the round of the given shard header
+func (sp *shardProcessor) AttestationCoverage(headerHash []byte) ([]process.MetaAttestation, error) {
+	shardHeader, err := process.GetShardHeader(headerHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
 	if err != nil {
 		return nil, err
 	}
 
-	return processedMetaBlocks, nil
-}
-
-func (sp *shardProcessor) addProcessedCrossMiniBlocksFromHeader(header *block.Header) error {
-	if header == nil {
-		return process.ErrNilBlockHeader
+	lastCrossNotarizedMeta, lastCrossNotarizedMetaHash, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
+	if err != nil {
+		return nil, err
 	}
 
-	miniBlockHashes := make(map[int][]byte, len(header.MiniBlockHeaders))
-	for i := 0; i < len(header.MiniBlockHeaders); i++ {
-		miniBlockHashes[i] = header.MiniBlockHeaders[i].Hash
+	metaBlock, ok := lastCrossNotarizedMeta.(*block.MetaBlock)
+	if !ok {
+		return nil, process.ErrWrongTypeAssertion
 	}
 
-	sp.hdrsForCurrBlock.mutHdrsForBlock.RLock()
-	for _, metaBlockHash := range header.MetaBlockHashes {
-		headerInfo, ok := sp.hdrsForCurrBlock.hdrHashAndInfo[string(metaBlockHash)]
-		if !ok {
-			sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
-			return fmt.Errorf("%w : addProcessedCrossMiniBlocksFromHeader metaBlockHash = %s",
-				process.ErrMissingHeader, logger.DisplayByteSlice(metaBlockHash))
+	attestations := make([]process.MetaAttestation, 0)
+	metaHash := lastCrossNotarizedMetaHash
+	for metaBlock.GetNonce() > 0 && metaBlock.GetRound() > shardHeader.GetRound() {
+		for _, shardInfo := range metaBlock.ShardInfo {
+			if shardInfo.ShardID != shardHeader.GetShardID() || !bytes.Equal(shardInfo.HeaderHash, headerHash) {
+				continue
+			}
+
+			attestations = append(attestations, process.MetaAttestation{
+				MetaHash:  metaHash,
+				MetaNonce: metaBlock.GetNonce(),
+			})
 		}
 
-		metaBlock, ok := headerInfo.hdr.(*block.MetaBlock)
-		if !ok {
-			sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
-			return process.ErrWrongTypeAssertion
+		metaHash = metaBlock.GetPrevHash()
+		metaBlock, err = process.GetMetaHeader(metaHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+		if err != nil {
+			log.Debug("shardProcessor.AttestationCoverage: stopped walking back the metachain",
+				"meta hash", metaHash,
+				"error", err.Error(),
+			)
+			break
 		}
+	}
 
-		crossMiniBlockHashes := metaBlock.GetMiniBlockHeadersWithDst(sp.shardCoordinator.SelfId())
-		for key, miniBlockHash := range miniBlockHashes {
-			_, ok = crossMiniBlockHashes[string(miniBlockHash)]
-			if !ok {
-				continue
-			}
+	return attestations, nil
+}
 
-			sp.processedMiniBlocks.AddMiniBlockHash(string(metaBlockHash), string(miniBlockHash))
+// FinalityProof returns the proof that the committed shard header with the given hash is final: the metablock which
+// notarized it, together with the metablocks correctly constructed on top of it, up to the meta block finality
+func (sp *shardProcessor) FinalityProof(headerHash []byte) (*process.FinalityProofData, error) {
+	attestations, err := sp.AttestationCoverage(headerHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(attestations) == 0 {
+		return nil, fmt.Errorf("%w, header hash %s is not notarized by metachain",
+			process.ErrBlockNotFinal, logger.DisplayByteSlice(headerHash))
+	}
 
-			delete(miniBlockHashes, key)
+	// the attestations are collected walking back the metachain, so the first notarization is the last one
+	attestingMetaHash := attestations[len(attestations)-1].MetaHash
+	attestingMetaBlock, err := process.GetMetaHeader(attestingMetaHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &process.FinalityProofData{
+		ShardHeaderHash:    headerHash,
+		AttestingMetaBlock: attestingMetaBlock,
+		AttestingMetaHash:  attestingMetaHash,
+		FinalityMetaBlocks: make([]*block.MetaBlock, 0, sp.metaBlockFinality),
+		FinalityMetaHashes: make([][]byte, 0, sp.metaBlockFinality),
+	}
+
+	lastMetaBlock := attestingMetaBlock
+	for i := uint32(0); i < sp.metaBlockFinality; i++ {
+		nextMetaBlock, nextMetaHash, found := sp.getMetaBlockConstructedOnTop(lastMetaBlock)
+		if !found {
+			return nil, fmt.Errorf("%w, header hash %s, missing meta block with nonce %d on top of the attesting one",
+				process.ErrBlockNotFinal, logger.DisplayByteSlice(headerHash), lastMetaBlock.GetNonce()+1)
 		}
+
+		proof.FinalityMetaBlocks = append(proof.FinalityMetaBlocks, nextMetaBlock)
+		proof.FinalityMetaHashes = append(proof.FinalityMetaHashes, nextMetaHash)
+		lastMetaBlock = nextMetaBlock
 	}
-	sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
 
-	return nil
+	return proof, nil
 }
 
-func (sp *shardProcessor) getOrderedProcessedMetaBlocksFromMiniBlockHashes(
-	miniBlockHashes map[int][]byte,
-) ([]data.HeaderHandler, error) {
-
-	processedMetaHdrs := make([]data.HeaderHandler, 0, len(sp.hdrsForCurrBlock.hdrHashAndInfo))
-	processedCrossMiniBlocksHashes := make(map[string]bool, len(sp.hdrsForCurrBlock.hdrHashAndInfo))
+// CrossShardSettlementMap returns, for the shard header with the given hash, the metablock which confirmed each of the
+// cross shard miniblocks processed in it. The map is keyed by the miniblock hash and holds the metablock hash. The
+// metablocks attested by the header are checked in their nonce order, so a miniblock is mapped to the first of them
+func (sp *shardProcessor) CrossShardSettlementMap(headerHash []byte) (map[string]string, error) {
+	shardHeader, err := process.GetShardHeader(headerHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+	if err != nil {
+		return nil, err
+	}
 
-	sp.hdrsForCurrBlock.mutHdrsForBlock.RLock()
-	for metaBlockHash, headerInfo := range sp.hdrsForCurrBlock.hdrHashAndInfo {
-		if !headerInfo.usedInBlock {
+	crossMiniBlockHashes := make(map[string]struct{})
+	for _, miniBlockHeader := range shardHeader.MiniBlockHeaders {
+		if miniBlockHeader.SenderShardID == shardHeader.GetShardID() {
 			continue
 		}
 
-		metaBlock, ok := headerInfo.hdr.(*block.MetaBlock)
-		if !ok {
-			sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
-			return nil, process.ErrWrongTypeAssertion
+		crossMiniBlockHashes[string(miniBlockHeader.Hash)] = struct{}{}
+	}
+
+	settlementMap := make(map[string]string, len(crossMiniBlockHashes))
+	for _, metaBlockHash := range shardHeader.MetaBlockHashes {
+		metaBlock, errGet := process.GetMetaHeader(metaBlockHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+		if errGet != nil {
+			return nil, errGet
 		}
 
-		log.Trace("meta header",
+		miniBlockHashesWithDst := metaBlock.GetMiniBlockHeadersWithDst(shardHeader.GetShardID())
+		for miniBlockHash := range miniBlockHashesWithDst {
+			_, isCrossMiniBlock := crossMiniBlockHashes[miniBlockHash]
+			_, isAlreadySettled := settlementMap[miniBlockHash]
+			if !isCrossMiniBlock || isAlreadySettled {
+				continue
+			}
+
+			settlementMap[miniBlockHash] = string(metaBlockHash)
+		}
+	}
+
+	return settlementMap, nil
+}
+
+// getMetaBlockConstructedOnTop returns the metablock, from pool or storage, correctly constructed on top of the given one
+func (sp *shardProcessor) getMetaBlockConstructedOnTop(metaBlock *block.MetaBlock) (*block.MetaBlock, []byte, bool) {
+	nextNonce := metaBlock.GetNonce() + 1
+
+	candidates, candidatesHashes, _ := sp.dataPool.Headers().GetHeadersByNonceAndShardId(nextNonce, core.MetachainShardId)
+	storedMetaBlock, storedMetaHash, err := process.GetMetaHeaderFromStorageWithNonce(
+		nextNonce,
+		sp.store,
+		sp.uint64Converter,
+		sp.marshalizer,
+	)
+	if err == nil {
+		candidates = append(candidates, storedMetaBlock)
+		candidatesHashes = append(candidatesHashes, storedMetaHash)
+	}
+
+	for index, candidate := range candidates {
+		candidateMetaBlock, ok := candidate.(*block.MetaBlock)
+		if !ok {
+			continue
+		}
+
+		err = sp.headerValidator.IsHeaderConstructionValid(candidateMetaBlock, metaBlock)
+		if err != nil {
+			continue
+		}
+
+		return candidateMetaBlock, candidatesHashes[index], true
+	}
+
+	return nil, nil, false
+}
+
+func (sp *shardProcessor) canRequestShardHeader(numRequests uint32) bool {
+	if sp.maxShardHeaderRequestsPerMeta == 0 {
+		return true
+	}
+
+	return numRequests < sp.maxShardHeaderRequestsPerMeta
+}
+
+// checkParentHeaderExists verifies that the previous header of the given header was already committed, so that
+// committing it will not create an orphan. The parent should be the current block header of the chain, whose hash is
+// computed again, or it should be found in storage. The first block after genesis and the blocks built on top of the
+// genesis header, as after an import, are exempted
+func (sp *shardProcessor) checkParentHeaderExists(header *block.Header) error {
+	if header.GetNonce() == sp.genesisNonce+1 {
+		return nil
+	}
+	if bytes.Equal(header.GetPrevHash(), sp.blockChain.GetGenesisHeaderHash()) {
+		return nil
+	}
+
+	currentHeader := sp.blockChain.GetCurrentBlockHeader()
+	if !check.IfNil(currentHeader) {
+		currentHeaderHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, currentHeader)
+		if err == nil && bytes.Equal(header.GetPrevHash(), currentHeaderHash) {
+			return nil
+		}
+	}
+
+	headersStorer := sp.store.GetStorer(dataRetriever.BlockHeaderUnit)
+	if check.IfNil(headersStorer) {
+		return process.ErrNilHeadersStorage
+	}
+
+	err := headersStorer.Has(header.GetPrevHash())
+	if err != nil {
+		return fmt.Errorf("%w, nonce: %d, prev hash: %s",
+			process.ErrMissingParentHeader,
+			header.GetNonce(),
+			logger.DisplayByteSlice(header.GetPrevHash()),
+		)
+	}
+
+	return nil
+}
+
+// getOrderedProcessedMetaBlocksFromHeader returns all the meta blocks fully processed
+func (sp *shardProcessor) getOrderedProcessedMetaBlocksFromHeader(header *block.Header) ([]data.HeaderHandler, error) {
+	processedMetaBlocks, _, err := sp.getOrderedProcessedMetaBlocksAndHashesFromHeader(header)
+	return processedMetaBlocks, err
+}
+
+// getOrderedProcessedMetaBlocksAndHashesFromHeader returns all the meta blocks fully processed, together with their
+// hashes, aligned with them
+func (sp *shardProcessor) getOrderedProcessedMetaBlocksAndHashesFromHeader(header *block.Header) ([]data.HeaderHandler, [][]byte, error) {
+	if header == nil {
+		return nil, nil, process.ErrNilBlockHeader
+	}
+
+	miniBlockHashes := make(map[int][]byte, len(header.MiniBlockHeaders))
+	for i := 0; i < len(header.MiniBlockHeaders); i++ {
+		miniBlockHashes[i] = header.MiniBlockHeaders[i].Hash
+	}
+
+	log.Trace("cross mini blocks in body",
+		"num miniblocks", len(miniBlockHashes),
+	)
+
+	return sp.getOrderedProcessedMetaBlocksFromMiniBlockHashes(miniBlockHashes)
+}
+
+func (sp *shardProcessor) addProcessedCrossMiniBlocksFromHeader(header *block.Header) error {
+	if header == nil {
+		return process.ErrNilBlockHeader
+	}
+
+	miniBlockHashes := make(map[int][]byte, len(header.MiniBlockHeaders))
+	for i := 0; i < len(header.MiniBlockHeaders); i++ {
+		miniBlockHashes[i] = header.MiniBlockHeaders[i].Hash
+	}
+
+	sp.hdrsForCurrBlock.mutHdrsForBlock.RLock()
+	for _, metaBlockHash := range header.MetaBlockHashes {
+		headerInfo, ok := sp.hdrsForCurrBlock.hdrHashAndInfo[string(metaBlockHash)]
+		if !ok {
+			sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
+			return fmt.Errorf("%w : addProcessedCrossMiniBlocksFromHeader metaBlockHash = %s",
+				process.ErrMissingHeader, logger.DisplayByteSlice(metaBlockHash))
+		}
+
+		metaBlock, ok := headerInfo.hdr.(*block.MetaBlock)
+		if !ok {
+			sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
+			return process.ErrWrongTypeAssertion
+		}
+
+		crossMiniBlockHashes := metaBlock.GetMiniBlockHeadersWithDst(sp.shardCoordinator.SelfId())
+		for key, miniBlockHash := range miniBlockHashes {
+			_, ok = crossMiniBlockHashes[string(miniBlockHash)]
+			if !ok {
+				continue
+			}
+
+			sp.processedMiniBlocks.AddMiniBlockHash(string(metaBlockHash), string(miniBlockHash))
+
+			delete(miniBlockHashes, key)
+		}
+	}
+	sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
+
+	return nil
+}
+
+func (sp *shardProcessor) getOrderedProcessedMetaBlocksFromMiniBlockHashes(
+	miniBlockHashes map[int][]byte,
+) ([]data.HeaderHandler, [][]byte, error) {
+
+	processedMetaHdrs := make([]*hashAndHdr, 0, len(sp.hdrsForCurrBlock.hdrHashAndInfo))
+	processedCrossMiniBlocksHashes := make(map[string]bool, len(sp.hdrsForCurrBlock.hdrHashAndInfo))
+
+	sp.hdrsForCurrBlock.mutHdrsForBlock.RLock()
+	for metaBlockHash, headerInfo := range sp.hdrsForCurrBlock.hdrHashAndInfo {
+		if !headerInfo.usedInBlock {
+			continue
+		}
+
+		metaBlock, ok := headerInfo.hdr.(*block.MetaBlock)
+		if !ok {
+			sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
+			return nil, nil, process.ErrWrongTypeAssertion
+		}
+
+		log.Trace("meta header",
 			"nonce", metaBlock.Nonce,
 		)
 
@@ -1339,14 +2488,23 @@ func (sp *shardProcessor) getOrderedProcessedMetaBlocksFromMiniBlockHashes(
 		}
 
 		if processedAll {
-			processedMetaHdrs = append(processedMetaHdrs, metaBlock)
+			processedMetaHdrs = append(processedMetaHdrs, &hashAndHdr{hdr: metaBlock, hash: []byte(metaBlockHash)})
 		}
 	}
 	sp.hdrsForCurrBlock.mutHdrsForBlock.RUnlock()
 
-	process.SortHeadersByNonce(processedMetaHdrs)
+	sort.Slice(processedMetaHdrs, func(i, j int) bool {
+		return processedMetaHdrs[i].hdr.GetNonce() < processedMetaHdrs[j].hdr.GetNonce()
+	})
+
+	headers := make([]data.HeaderHandler, 0, len(processedMetaHdrs))
+	hashes := make([][]byte, 0, len(processedMetaHdrs))
+	for _, processedMetaHdr := range processedMetaHdrs {
+		headers = append(headers, processedMetaHdr.hdr)
+		hashes = append(hashes, processedMetaHdr.hash)
+	}
 
-	return processedMetaHdrs, nil
+	return headers, hashes, nil
 }
 
 func (sp *shardProcessor) updateCrossShardInfo(processedMetaHdrs []data.HeaderHandler) error {
@@ -1376,20 +2534,84 @@ func (sp *shardProcessor) updateCrossShardInfo(processedMetaHdrs []data.HeaderHa
 		sp.saveMetaHeader(hdr, headerHash, marshalizedHeader)
 
 		sp.processedMiniBlocks.RemoveMetaBlockHash(string(headerHash))
+		sp.metaBlocksFirstSeen.remove(headerHash)
 	}
 
 	return nil
 }
 
+// MetaBlockPoolAge returns the number of rounds passed, until the given current round, since the meta block with the
+// given hash was first seen in pool. Only the meta blocks which were not yet attested and finalized are tracked
+func (sp *shardProcessor) MetaBlockPoolAge(hash []byte, currentRound uint64) (uint64, error) {
+	firstSeenRound, found := sp.metaBlocksFirstSeen.get(hash)
+	if !found {
+		return 0, fmt.Errorf("%w for meta block hash %s", process.ErrMissingHeader, logger.DisplayByteSlice(hash))
+	}
+
+	if currentRound < firstSeenRound {
+		return 0, nil
+	}
+
+	return currentRound - firstSeenRound, nil
+}
+
+// LastCrossNotarizedMetaBlock returns the last meta block notarized by this shard, together with its hash
+func (sp *shardProcessor) LastCrossNotarizedMetaBlock() (data.HeaderHandler, []byte, error) {
+	return sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
+}
+
+// LastCommittedHeader returns the last committed self shard header, together with its hash
+func (sp *shardProcessor) LastCommittedHeader() (data.HeaderHandler, []byte) {
+	return sp.blockChain.GetCurrentBlockHeader(), sp.blockChain.GetCurrentBlockHeaderHash()
+}
+
+func (sp *shardProcessor) currentRound() uint64 {
+	roundIndex := sp.rounder.Index()
+	if roundIndex < 0 {
+		return 0
+	}
+
+	return uint64(roundIndex)
+}
+
+// registerMetaBlockHandler registers the received meta block handler on the headers pool. As the pool does not allow
+// removing handlers, the registration is tracked here and the handler becomes a no-op once it is deregistered, so
+// that another shard processor could be safely constructed on the same pool
+func (sp *shardProcessor) registerMetaBlockHandler() {
+	sp.dataPool.Headers().RegisterHandler(sp.receivedMetaBlock)
+	sp.isMetaBlockHandlerRegistered.Set()
+}
+
+func (sp *shardProcessor) deregisterMetaBlockHandler() {
+	sp.isMetaBlockHandlerRegistered.Unset()
+}
+
 // receivedMetaBlock is a callback function when a new metablock was received
 // upon receiving, it parses the new metablock and requests miniblocks and transactions
 // which destination is the current shard
 func (sp *shardProcessor) receivedMetaBlock(headerHandler data.HeaderHandler, metaBlockHash []byte) {
+	if !sp.isMetaBlockHandlerRegistered.IsSet() {
+		return
+	}
+
 	metaBlock, ok := headerHandler.(*block.MetaBlock)
 	if !ok {
 		return
 	}
 
+	if sp.pausedMetaBlocks.addIfPaused(headerHandler, metaBlockHash) {
+		log.Trace("meta block processing is paused, received meta block is postponed",
+			"round", metaBlock.Round,
+			"nonce", metaBlock.Nonce,
+			"hash", metaBlockHash,
+		)
+		return
+	}
+
+	sp.invalidatePrecomputedMetaBlocksIfNeeded(metaBlockHash)
+	sp.metaBlocksFirstSeen.add(metaBlockHash, sp.currentRound())
+	sp.checkMetaBlocksPoolFill()
+
 	log.Trace("received meta block from network",
 		"round", metaBlock.Round,
 		"nonce", metaBlock.Nonce,
@@ -1429,7 +2651,10 @@ func (sp *shardProcessor) receivedMetaBlock(headerHandler data.HeaderHandler, me
 
 		allMissingMetaHeadersReceived := missingMetaHdrs == 0 && missingFinalityAttestingMetaHdrs == 0
 		if allMissingMetaHeadersReceived {
-			sp.chRcvAllMetaHdrs <- true
+			select {
+			case sp.chRcvAllMetaHdrs <- true:
+			case <-sp.chStop:
+			}
 		}
 	} else {
 		sp.hdrsForCurrBlock.mutHdrsForBlock.Unlock()
@@ -1438,6 +2663,68 @@ func (sp *shardProcessor) receivedMetaBlock(headerHandler data.HeaderHandler, me
 	go sp.requestMiniBlocksIfNeeded(headerHandler)
 }
 
+// checkMetaBlocksPoolFill reports, through the near capacity metric, each time the meta blocks from the headers pool
+// exceed the configured fill ratio, as they could be evicted before being attested. For the FromMeFirst body composition,
+// it also hints the next block creation to prioritize the attestation of the pending meta blocks. No hint is needed for
+// the default composition, as it always processes first the miniblocks with destination in self shard
+func (sp *shardProcessor) checkMetaBlocksPoolFill() {
+	if sp.metaBlocksPoolHighFillRatio == 0 {
+		return
+	}
+
+	headersPool := sp.dataPool.Headers()
+	maxMetaBlocks := headersPool.MaxSize()
+	if maxMetaBlocks <= 0 {
+		return
+	}
+
+	numMetaBlocks := headersPool.GetNumHeaders(core.MetachainShardId)
+	fillRatio := float64(numMetaBlocks) / float64(maxMetaBlocks)
+	if fillRatio < sp.metaBlocksPoolHighFillRatio {
+		sp.isMetaBlocksPoolNearCapacity.Unset()
+		return
+	}
+
+	if sp.blockCreationPolicy.BodyComposition() == process.FromMeFirst {
+		sp.shouldPrioritizeMetaBlocks.Set()
+	}
+
+	wasAlreadyNearCapacity := sp.isMetaBlocksPoolNearCapacity.Set()
+	if wasAlreadyNearCapacity {
+		return
+	}
+
+	sp.appStatusHandler.Increment(core.MetricMetaBlocksPoolNearCapacity)
+	log.Debug("meta blocks pool is near capacity",
+		"num meta blocks", numMetaBlocks,
+		"max meta blocks", maxMetaBlocks,
+		"high fill ratio", sp.metaBlocksPoolHighFillRatio,
+		"body composition", sp.blockCreationPolicy.BodyComposition(),
+	)
+}
+
+// SetMetaBlockProcessingPaused pauses or resumes the processing of the meta blocks received in pool, without
+// deregistering the handler. While paused, the received meta blocks are buffered, up to a limit, and they are
+// processed on resume. The ones received when the buffer is full are dropped
+func (sp *shardProcessor) SetMetaBlockProcessingPaused(isPaused bool) {
+	bufferedMetaBlocks := sp.pausedMetaBlocks.setPaused(isPaused)
+
+	log.Debug("shardProcessor.SetMetaBlockProcessingPaused",
+		"is paused", isPaused,
+		"num buffered meta blocks", len(bufferedMetaBlocks),
+	)
+
+	for _, bufferedMetaBlock := range bufferedMetaBlocks {
+		sp.receivedMetaBlock(bufferedMetaBlock.header, bufferedMetaBlock.hash)
+	}
+}
+
+// NumDroppedPausedMetaBlocks returns the number of meta blocks dropped because they were received while the meta
+// blocks processing was paused and the buffer was full
+func (sp *shardProcessor) NumDroppedPausedMetaBlocks() uint64 {
+	return sp.pausedMetaBlocks.getNumDropped()
+}
+
 func (sp *shardProcessor) requestMetaHeaders(shardHeader *block.Header) (uint32, uint32) {
 	_ = core.EmptyChannel(sp.chRcvAllMetaHdrs)
 
@@ -1487,13 +2774,18 @@ func (sp *shardProcessor) computeExistingAndRequestMissingMetaHeaders(header *bl
 	return sp.hdrsForCurrBlock.missingHdrs, sp.hdrsForCurrBlock.missingFinalityAttestingHdrs
 }
 
-func (sp *shardProcessor) verifyCrossShardMiniBlockDstMe(header *block.Header) error {
+func (sp *shardProcessor) verifyCrossShardMiniBlockDstMe(header *block.Header, body *block.Body) error {
 	miniBlockMetaHashes, err := sp.getAllMiniBlockDstMeFromMeta(header)
 	if err != nil {
 		return err
 	}
 
 	crossMiniBlockHashes := header.GetMiniBlockHeadersWithDst(sp.shardCoordinator.SelfId())
+	err = sp.checkMiniBlocksReceiverShardIsSelf(body, crossMiniBlockHashes)
+	if err != nil {
+		return err
+	}
+
 	for hash := range crossMiniBlockHashes {
 		if _, ok := miniBlockMetaHashes[hash]; !ok {
 			return process.ErrCrossShardMBWithoutConfirmationFromMeta
@@ -1541,98 +2833,430 @@ func (sp *shardProcessor) getAllMiniBlockDstMeFromMeta(header *block.Header) (ma
 	return miniBlockMetaHashes, nil
 }
 
-// full verification through metachain header
-func (sp *shardProcessor) createAndProcessMiniBlocksDstMe(
-	haveTime func() bool,
-) (block.MiniBlockSlice, uint32, uint32, error) {
-	log.Debug("createAndProcessMiniBlocksDstMe has been started")
+// SubscribeFinalBlockAdvance returns a channel on which the new highest final block nonce is sent each time
+// it advances while committing blocks, together with the function which removes the subscription and closes the
+// channel. A slow subscriber misses the notifications sent while its channel is full. All the channels are closed
+// when the shard processor is closed
+func (sp *shardProcessor) SubscribeFinalBlockAdvance() (<-chan uint64, func()) {
+	ch := make(chan uint64, finalBlockAdvanceChanSize)
 
-	miniBlocks := make(block.MiniBlockSlice, 0)
-	txsAdded := uint32(0)
-	hdrsAdded := uint32(0)
+	sp.mutFinalBlockAdvance.Lock()
+	defer sp.mutFinalBlockAdvance.Unlock()
 
-	sw := core.NewStopWatch()
-	sw.Start("ComputeLongestMetaChainFromLastNotarized")
-	orderedMetaBlocks, orderedMetaBlocksHashes, err := sp.blockTracker.ComputeLongestMetaChainFromLastNotarized()
-	sw.Stop("ComputeLongestMetaChainFromLastNotarized")
-	log.Debug("measurements", sw.GetMeasurements()...)
-	if err != nil {
-		return nil, 0, 0, err
+	if sp.isClosed.IsSet() {
+		close(ch)
+		return ch, func() {}
 	}
 
-	log.Debug("metablocks ordered",
-		"num metablocks", len(orderedMetaBlocks),
-	)
+	sp.finalBlockAdvanceSubscribers = append(sp.finalBlockAdvanceSubscribers, ch)
 
-	lastMetaHdr, _, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
-	if err != nil {
-		return nil, 0, 0, err
+	unsubscribe := func() {
+		sp.unsubscribeFinalBlockAdvance(ch)
 	}
 
-	// do processing in order
-	sp.hdrsForCurrBlock.mutHdrsForBlock.Lock()
-	for i := 0; i < len(orderedMetaBlocks); i++ {
-		if !haveTime() {
-			log.Debug("time is up after putting cross txs with destination to current shard",
-				"num txs added", txsAdded,
-			)
-			break
-		}
-
-		if hdrsAdded >= process.MaxMetaHeadersAllowedInOneShardBlock {
-			log.Debug("maximum meta headers allowed to be included in one shard block has been reached",
-				"meta headers added", hdrsAdded,
-			)
-			break
-		}
+	return ch, unsubscribe
+}
 
-		currMetaHdr := orderedMetaBlocks[i]
-		if currMetaHdr.GetNonce() > lastMetaHdr.GetNonce()+1 {
-			log.Debug("skip searching",
-				"last meta hdr nonce", lastMetaHdr.GetNonce(),
-				"curr meta hdr nonce", currMetaHdr.GetNonce())
-			break
-		}
+func (sp *shardProcessor) unsubscribeFinalBlockAdvance(ch chan uint64) {
+	sp.mutFinalBlockAdvance.Lock()
+	defer sp.mutFinalBlockAdvance.Unlock()
 
-		if len(currMetaHdr.GetMiniBlockHeadersWithDst(sp.shardCoordinator.SelfId())) == 0 {
-			sp.hdrsForCurrBlock.hdrHashAndInfo[string(orderedMetaBlocksHashes[i])] = &hdrInfo{hdr: currMetaHdr, usedInBlock: true}
-			hdrsAdded++
-			lastMetaHdr = currMetaHdr
+	for i, subscriber := range sp.finalBlockAdvanceSubscribers {
+		if subscriber != ch {
 			continue
 		}
 
-		processedMiniBlocksHashes := sp.processedMiniBlocks.GetProcessedMiniBlocksHashes(string(orderedMetaBlocksHashes[i]))
-		currMBProcessed, currTxsAdded, hdrProcessFinished, errCreated := sp.txCoordinator.CreateMbsAndProcessCrossShardTransactionsDstMe(
-			currMetaHdr,
-			processedMiniBlocksHashes,
-			haveTime)
+		sp.finalBlockAdvanceSubscribers = append(sp.finalBlockAdvanceSubscribers[:i], sp.finalBlockAdvanceSubscribers[i+1:]...)
+		close(ch)
+		return
+	}
+}
 
-		if errCreated != nil {
-			return nil, 0, 0, errCreated
-		}
+func (sp *shardProcessor) closeFinalBlockAdvanceSubscribers() {
+	sp.mutFinalBlockAdvance.Lock()
+	defer sp.mutFinalBlockAdvance.Unlock()
 
-		// all txs processed, add to processed miniblocks
-		miniBlocks = append(miniBlocks, currMBProcessed...)
-		txsAdded += currTxsAdded
+	for _, ch := range sp.finalBlockAdvanceSubscribers {
+		close(ch)
+	}
+	sp.finalBlockAdvanceSubscribers = nil
+}
 
-		if currTxsAdded > 0 {
-			sp.hdrsForCurrBlock.hdrHashAndInfo[string(orderedMetaBlocksHashes[i])] = &hdrInfo{hdr: currMetaHdr, usedInBlock: true}
-			hdrsAdded++
-		}
+// notifyFinalBlockAdvance sends the given highest final block nonce to all the subscribers, if it advanced since
+// the last notification
+func (sp *shardProcessor) notifyFinalBlockAdvance(highestFinalBlockNonce uint64) {
+	sp.mutFinalBlockAdvance.Lock()
+	defer sp.mutFinalBlockAdvance.Unlock()
 
-		if !hdrProcessFinished {
-			log.Debug("meta block cannot be fully processed",
-				"round", currMetaHdr.GetRound(),
+	if highestFinalBlockNonce <= sp.lastNotifiedFinalNonce {
+		return
+	}
+	sp.lastNotifiedFinalNonce = highestFinalBlockNonce
+
+	for _, ch := range sp.finalBlockAdvanceSubscribers {
+		select {
+		case ch <- highestFinalBlockNonce:
+		default:
+			log.Debug("final block advance subscriber is not ready, notification dropped",
+				"nonce", highestFinalBlockNonce,
+			)
+		}
+	}
+}
+
+// RegisterCommittedBlockSink registers a handler which will receive each successfully committed block. The handler is
+// called on its own go routine, through a bounded buffer, so a slow handler does not block the commit. The blocks
+// which do not fit in the buffer are dropped and counted
+func (sp *shardProcessor) RegisterCommittedBlockSink(sink func(header data.HeaderHandler, body block.Body, hash []byte)) error {
+	if sink == nil {
+		return process.ErrNilCommittedBlockSink
+	}
+
+	sp.mutCommittedBlockSinks.Lock()
+	sp.committedBlockSinks = append(sp.committedBlockSinks, newCommittedBlockSink(sink, sp.chStop))
+	sp.mutCommittedBlockSinks.Unlock()
+
+	return nil
+}
+
+// NumDroppedCommittedBlocks returns the total number of committed blocks which were dropped, for all the registered
+// sinks, because the sinks buffers were full
+func (sp *shardProcessor) NumDroppedCommittedBlocks() uint64 {
+	sp.mutCommittedBlockSinks.RLock()
+	defer sp.mutCommittedBlockSinks.RUnlock()
+
+	numDropped := uint64(0)
+	for _, sink := range sp.committedBlockSinks {
+		numDropped += sink.getNumDropped()
+	}
+
+	return numDropped
+}
+
+func (sp *shardProcessor) notifyCommittedBlockSinks(header data.HeaderHandler, body *block.Body, headerHash []byte) {
+	sp.mutCommittedBlockSinks.RLock()
+	defer sp.mutCommittedBlockSinks.RUnlock()
+
+	for _, sink := range sp.committedBlockSinks {
+		sink.push(&committedBlock{
+			header: header,
+			body:   *body,
+			hash:   headerHash,
+		})
+	}
+}
+
+// PrecomputeNextRoundMetaBlocks computes in advance the ordered candidate meta blocks for the next round, so that
+// the next block body creation could use them as a warm start, if they are still valid at that time
+func (sp *shardProcessor) PrecomputeNextRoundMetaBlocks() {
+	lastCrossNotarizedHeader, lastCrossNotarizedHash, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
+	if err != nil {
+		log.Debug("PrecomputeNextRoundMetaBlocks.GetLastCrossNotarizedHeader", "error", err.Error())
+		return
+	}
+
+	orderedMetaBlocks, orderedMetaBlocksHashes, err := sp.blockTracker.ComputeLongestMetaChainFromLastNotarized()
+	if err != nil {
+		log.Debug("PrecomputeNextRoundMetaBlocks.ComputeLongestMetaChainFromLastNotarized", "error", err.Error())
+		return
+	}
+
+	orderedMetaBlocks, orderedMetaBlocksHashes = removeRoundInconsistentMetaBlocks(
+		lastCrossNotarizedHeader,
+		orderedMetaBlocks,
+		orderedMetaBlocksHashes,
+	)
+
+	nextRound := uint64(sp.rounder.Index() + 1)
+
+	sp.mutPrecomputedMetaBlocks.Lock()
+	sp.precomputedMetaBlocks = &precomputedMetaBlocks{
+		round:                  nextRound,
+		lastCrossNotarizedHash: lastCrossNotarizedHash,
+		metaBlocks:             orderedMetaBlocks,
+		metaBlocksHashes:       orderedMetaBlocksHashes,
+	}
+	sp.mutPrecomputedMetaBlocks.Unlock()
+
+	log.Debug("precomputed meta blocks for next round",
+		"round", nextRound,
+		"num metablocks", len(orderedMetaBlocks),
+	)
+}
+
+// getOrderedMetaBlocks returns the precomputed ordered meta blocks if they are still valid for the current round,
+// otherwise it computes them again
+func (sp *shardProcessor) getOrderedMetaBlocks() ([]data.HeaderHandler, [][]byte, error) {
+	precomputed := sp.consumePrecomputedMetaBlocks()
+	if precomputed != nil {
+		_, lastCrossNotarizedHash, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
+		isPrecomputedValid := err == nil &&
+			precomputed.round == uint64(sp.rounder.Index()) &&
+			bytes.Equal(precomputed.lastCrossNotarizedHash, lastCrossNotarizedHash)
+		if isPrecomputedValid {
+			log.Debug("using precomputed meta blocks",
+				"round", precomputed.round,
+				"num metablocks", len(precomputed.metaBlocks),
+			)
+			return precomputed.metaBlocks, precomputed.metaBlocksHashes, nil
+		}
+	}
+
+	sw := core.NewStopWatch()
+	sw.Start("ComputeLongestMetaChainFromLastNotarized")
+	orderedMetaBlocks, orderedMetaBlocksHashes, err := sp.blockTracker.ComputeLongestMetaChainFromLastNotarized()
+	sw.Stop("ComputeLongestMetaChainFromLastNotarized")
+	log.Debug("measurements", sw.GetMeasurements()...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lastCrossNotarizedHeader, _, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	orderedMetaBlocks, orderedMetaBlocksHashes = removeRoundInconsistentMetaBlocks(
+		lastCrossNotarizedHeader,
+		orderedMetaBlocks,
+		orderedMetaBlocksHashes,
+	)
+
+	return orderedMetaBlocks, orderedMetaBlocksHashes, nil
+}
+
+// removeRoundInconsistentMetaBlocks filters out, from the given ordered meta blocks, the ones which have a round
+// lower than or equal to the round of the previous kept meta block, starting from the given last notarized one
+func removeRoundInconsistentMetaBlocks(
+	lastNotarizedMetaBlock data.HeaderHandler,
+	orderedMetaBlocks []data.HeaderHandler,
+	orderedMetaBlocksHashes [][]byte,
+) ([]data.HeaderHandler, [][]byte) {
+	if check.IfNil(lastNotarizedMetaBlock) {
+		return orderedMetaBlocks, orderedMetaBlocksHashes
+	}
+
+	metaBlocks := make([]data.HeaderHandler, 0, len(orderedMetaBlocks))
+	metaBlocksHashes := make([][]byte, 0, len(orderedMetaBlocksHashes))
+	numRemoved := 0
+	previousRound := lastNotarizedMetaBlock.GetRound()
+	for i := 0; i < len(orderedMetaBlocks) && i < len(orderedMetaBlocksHashes); i++ {
+		if orderedMetaBlocks[i].GetRound() <= previousRound {
+			log.Debug("round inconsistent meta block removed",
+				"round", orderedMetaBlocks[i].GetRound(),
+				"nonce", orderedMetaBlocks[i].GetNonce(),
+				"previous round", previousRound,
+				"hash", orderedMetaBlocksHashes[i],
+			)
+			numRemoved++
+			continue
+		}
+
+		previousRound = orderedMetaBlocks[i].GetRound()
+		metaBlocks = append(metaBlocks, orderedMetaBlocks[i])
+		metaBlocksHashes = append(metaBlocksHashes, orderedMetaBlocksHashes[i])
+	}
+
+	if numRemoved > 0 {
+		log.Debug("removed round inconsistent meta blocks",
+			"num removed", numRemoved,
+		)
+	}
+
+	return metaBlocks, metaBlocksHashes
+}
+
+// consumePrecomputedMetaBlocks returns the precomputed meta blocks, if any, and clears them, as they are used only once
+func (sp *shardProcessor) consumePrecomputedMetaBlocks() *precomputedMetaBlocks {
+	sp.mutPrecomputedMetaBlocks.Lock()
+	defer sp.mutPrecomputedMetaBlocks.Unlock()
+
+	precomputed := sp.precomputedMetaBlocks
+	sp.precomputedMetaBlocks = nil
+
+	return precomputed
+}
+
+// invalidatePrecomputedMetaBlocksIfNeeded clears the precomputed meta blocks when a new meta block, which is not
+// already part of them, is received, as it could change the candidates for the next round
+func (sp *shardProcessor) invalidatePrecomputedMetaBlocksIfNeeded(metaBlockHash []byte) {
+	sp.mutPrecomputedMetaBlocks.Lock()
+	defer sp.mutPrecomputedMetaBlocks.Unlock()
+
+	if sp.precomputedMetaBlocks == nil {
+		return
+	}
+
+	for _, hash := range sp.precomputedMetaBlocks.metaBlocksHashes {
+		if bytes.Equal(hash, metaBlockHash) {
+			return
+		}
+	}
+
+	sp.precomputedMetaBlocks = nil
+}
+
+// full verification through metachain header
+func (sp *shardProcessor) createAndProcessMiniBlocksDstMe(
+	haveTime func() bool,
+) (block.MiniBlockSlice, uint32, uint32, error) {
+	log.Debug("createAndProcessMiniBlocksDstMe has been started")
+
+	miniBlocks := make(block.MiniBlockSlice, 0)
+	miniBlocksSize := 0
+	txsAdded := uint32(0)
+	hdrsAdded := uint32(0)
+
+	orderedMetaBlocks, orderedMetaBlocksHashes, err := sp.getOrderedMetaBlocks()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	log.Debug("metablocks ordered",
+		"num metablocks", len(orderedMetaBlocks),
+	)
+
+	lastMetaHdr, _, err := sp.blockTracker.GetLastCrossNotarizedHeader(core.MetachainShardId)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	sp.recordNotFinalMetaBlocks(lastMetaHdr, orderedMetaBlocksHashes)
+
+	// empty meta blocks which are not included directly, are kept aside and added only if a following meta block,
+	// with miniblocks with destination in self shard, is attested, so the attested chain has no gaps
+	pendingEmptyMetaBlocks := make([]*hashAndHdr, 0)
+
+	// do processing in order
+	sp.hdrsForCurrBlock.mutHdrsForBlock.Lock()
+	for i := 0; i < len(orderedMetaBlocks); i++ {
+		if !haveTime() {
+			log.Debug("time is up after putting cross txs with destination to current shard",
+				"num txs added", txsAdded,
+			)
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i:], "time is up")
+			break
+		}
+
+		if sp.blockCreationPolicy.IsMaxBlockBodyBytesReached(miniBlocksSize) {
+			log.Debug("maximum block body size in bytes has been reached after putting cross txs with destination to current shard",
+				"size", miniBlocksSize,
+				"num txs added", txsAdded,
+			)
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i:], "maximum block body size reached")
+			break
+		}
+
+		if hdrsAdded+uint32(len(pendingEmptyMetaBlocks)) >= process.MaxMetaHeadersAllowedInOneShardBlock {
+			log.Debug("maximum meta headers allowed to be included in one shard block has been reached",
+				"meta headers added", hdrsAdded,
+			)
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i:], "maximum meta headers allowed in one shard block reached")
+			break
+		}
+
+		if sp.isMaxAttestedMetaBlocksReached(hdrsAdded + uint32(len(pendingEmptyMetaBlocks))) {
+			log.Debug("maximum attested meta blocks per block has been reached",
+				"meta headers added", hdrsAdded,
+				"max attested meta blocks", sp.maxAttestedMetaBlocksPerBlock,
+			)
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i:], "maximum attested meta blocks per block reached")
+			break
+		}
+
+		currMetaHdr := orderedMetaBlocks[i]
+		currMetaHdrHash := orderedMetaBlocksHashes[i]
+		if currMetaHdr.GetNonce() > lastMetaHdr.GetNonce()+1 {
+			log.Debug("skip searching",
+				"last meta hdr nonce", lastMetaHdr.GetNonce(),
+				"curr meta hdr nonce", currMetaHdr.GetNonce())
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i:], "nonce gap after the last attested meta block")
+			break
+		}
+
+		if sp.checkAttestedShardInfo && !sp.areSelfShardInfoHeadersAvailable(currMetaHdr) {
+			log.Debug("meta block references self shard headers which are not available",
+				"round", currMetaHdr.GetRound(),
+				"nonce", currMetaHdr.GetNonce(),
+				"hash", currMetaHdrHash)
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i:], "self shard headers referenced by meta block are not available")
+			break
+		}
+
+		if len(currMetaHdr.GetMiniBlockHeadersWithDst(sp.shardCoordinator.SelfId())) == 0 {
+			if sp.includeEmptyAttestedMetaBlocks {
+				sp.hdrsForCurrBlock.hdrHashAndInfo[string(currMetaHdrHash)] = &hdrInfo{hdr: currMetaHdr, usedInBlock: true}
+				hdrsAdded++
+			} else {
+				pendingEmptyMetaBlocks = append(pendingEmptyMetaBlocks, &hashAndHdr{hdr: currMetaHdr, hash: currMetaHdrHash})
+			}
+			lastMetaHdr = currMetaHdr
+			continue
+		}
+
+		processedMiniBlocksHashes := sp.processedMiniBlocks.GetProcessedMiniBlocksHashes(string(currMetaHdrHash))
+		isUnderCapacityPressure := sp.isUnderCapacityPressure(currMetaHdr, processedMiniBlocksHashes, txsAdded)
+		if isUnderCapacityPressure {
+			currMetaHdr, currMetaHdrHash = sp.selectBestFillingMetaBlock(lastMetaHdr, currMetaHdr, currMetaHdrHash, txsAdded)
+			processedMiniBlocksHashes = sp.processedMiniBlocks.GetProcessedMiniBlocksHashes(string(currMetaHdrHash))
+		}
+
+		currMBProcessed, currTxsAdded, hdrProcessFinished, errCreated := sp.txCoordinator.CreateMbsAndProcessCrossShardTransactionsDstMe(
+			currMetaHdr,
+			processedMiniBlocksHashes,
+			haveTime)
+
+		if errCreated != nil {
+			return nil, 0, 0, errCreated
+		}
+
+		// all txs processed, add to processed miniblocks
+		miniBlocks = append(miniBlocks, currMBProcessed...)
+		txsAdded += currTxsAdded
+		if sp.blockCreationPolicy.MaxBlockBodyBytes() > 0 {
+			miniBlocksSize += sp.computeMiniBlocksSize(currMBProcessed)
+		}
+
+		if currTxsAdded > 0 {
+			for _, pendingEmptyMetaBlock := range pendingEmptyMetaBlocks {
+				sp.hdrsForCurrBlock.hdrHashAndInfo[string(pendingEmptyMetaBlock.hash)] = &hdrInfo{hdr: pendingEmptyMetaBlock.hdr, usedInBlock: true}
+				hdrsAdded++
+			}
+			pendingEmptyMetaBlocks = pendingEmptyMetaBlocks[:0]
+
+			sp.hdrsForCurrBlock.hdrHashAndInfo[string(currMetaHdrHash)] = &hdrInfo{hdr: currMetaHdr, usedInBlock: true}
+			hdrsAdded++
+		}
+
+		if currTxsAdded == 0 {
+			sp.recordAttestationDecisions([][]byte{currMetaHdrHash}, "no cross shard transactions could be added")
+		}
+
+		if !hdrProcessFinished {
+			log.Debug("meta block cannot be fully processed",
+				"round", currMetaHdr.GetRound(),
 				"nonce", currMetaHdr.GetNonce(),
-				"hash", orderedMetaBlocksHashes[i])
+				"hash", currMetaHdrHash)
 
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i+1:], "previous meta block could not be fully processed")
 			break
 		}
 
 		lastMetaHdr = currMetaHdr
+
+		// the next meta blocks from the ordered list could have been built on top of another meta block than the one
+		// selected under capacity pressure, so the search stops here
+		if isUnderCapacityPressure {
+			sp.recordAttestationDecisions(orderedMetaBlocksHashes[i+1:], "search stopped after a selection under capacity pressure")
+			break
+		}
 	}
 	sp.hdrsForCurrBlock.mutHdrsForBlock.Unlock()
 
+	for _, pendingEmptyMetaBlock := range pendingEmptyMetaBlocks {
+		sp.recordAttestationDecisions([][]byte{pendingEmptyMetaBlock.hash}, "empty meta block not followed by an attested meta block")
+	}
+
 	go sp.requestMetaHeadersIfNeeded(hdrsAdded, lastMetaHdr)
 
 	for _, miniBlock := range miniBlocks {
@@ -1647,7 +3271,225 @@ func (sp *shardProcessor) createAndProcessMiniBlocksDstMe(
 		"num txs added", txsAdded,
 		"num hdrs added", hdrsAdded)
 
-	return miniBlocks, txsAdded, hdrsAdded, nil
+	sp.saveMetaBlockAttestationEfficiencyMetric(hdrsAdded, len(orderedMetaBlocks))
+
+	return miniBlocks, txsAdded, hdrsAdded, nil
+}
+
+// recordNotFinalMetaBlocks records, as not attested, the tracked meta blocks built after the given last cross
+// notarized one which are not part of the ordered meta blocks, as they are not final yet
+func (sp *shardProcessor) recordNotFinalMetaBlocks(lastCrossNotarizedMetaHdr data.HeaderHandler, orderedMetaBlocksHashes [][]byte) {
+	orderedHashes := make(map[string]struct{}, len(orderedMetaBlocksHashes))
+	for _, hash := range orderedMetaBlocksHashes {
+		orderedHashes[string(hash)] = struct{}{}
+	}
+
+	trackedMetaBlocks, trackedMetaBlocksHashes := sp.blockTracker.GetTrackedHeaders(core.MetachainShardId)
+	notFinalHashes := make([][]byte, 0)
+	for i := 0; i < len(trackedMetaBlocks) && i < len(trackedMetaBlocksHashes); i++ {
+		if trackedMetaBlocks[i].GetNonce() <= lastCrossNotarizedMetaHdr.GetNonce() {
+			continue
+		}
+
+		_, isOrdered := orderedHashes[string(trackedMetaBlocksHashes[i])]
+		if !isOrdered {
+			notFinalHashes = append(notFinalHashes, trackedMetaBlocksHashes[i])
+		}
+	}
+
+	sp.recordAttestationDecisions(notFinalHashes, "meta block is not final")
+}
+
+// recordAttestationDecisions records the reason for which the given meta blocks were not attested
+func (sp *shardProcessor) recordAttestationDecisions(metaBlocksHashes [][]byte, reason string) {
+	if len(metaBlocksHashes) == 0 {
+		return
+	}
+
+	sp.mutAttestationDecisions.Lock()
+	if sp.attestationDecisions == nil {
+		sp.attestationDecisions = make(map[string]string)
+	}
+	for _, hash := range metaBlocksHashes {
+		sp.attestationDecisions[string(hash)] = reason
+	}
+	sp.mutAttestationDecisions.Unlock()
+}
+
+func (sp *shardProcessor) resetAttestationDecisions() {
+	sp.mutAttestationDecisions.Lock()
+	sp.attestationDecisions = make(map[string]string)
+	sp.mutAttestationDecisions.Unlock()
+}
+
+// LastAttestationDecisions returns, for each meta block which could have been attested in the last created block but
+// was not, the reason for which it was skipped
+func (sp *shardProcessor) LastAttestationDecisions() map[string]string {
+	sp.mutAttestationDecisions.RLock()
+	defer sp.mutAttestationDecisions.RUnlock()
+
+	decisions := make(map[string]string, len(sp.attestationDecisions))
+	for hash, reason := range sp.attestationDecisions {
+		decisions[hash] = reason
+	}
+
+	return decisions
+}
+
+// saveMetaBlockAttestationEfficiencyMetric sets the ratio between the number of attested meta blocks and the number
+// of meta blocks which were available for attestation
+func (sp *shardProcessor) saveMetaBlockAttestationEfficiencyMetric(numAttested uint32, numAvailable int) {
+	if numAvailable == 0 {
+		return
+	}
+
+	efficiency := float64(numAttested) / float64(numAvailable)
+	sp.appStatusHandler.SetStringValue(core.MetricMetaBlockAttestationEfficiency, fmt.Sprintf("%.2f", efficiency))
+}
+
+// isUnderCapacityPressure returns true if the weighted meta block selection is enabled and the remaining space for
+// cross transactions in the current block is not enough to hold all the unprocessed transactions of the given meta block
+func (sp *shardProcessor) isUnderCapacityPressure(
+	metaHdr data.HeaderHandler,
+	processedMiniBlocksHashes map[string]struct{},
+	txsAdded uint32,
+) bool {
+	if sp.weightedMetaBlockSelectionMaxTxs == 0 {
+		return false
+	}
+	if txsAdded >= sp.weightedMetaBlockSelectionMaxTxs {
+		return false
+	}
+
+	remainingSpace := sp.weightedMetaBlockSelectionMaxTxs - txsAdded
+	return sp.computeNumUnprocessedTxsWithDstMe(metaHdr, processedMiniBlocksHashes) > remainingSpace
+}
+
+// selectBestFillingMetaBlock chooses, from all the tracked meta blocks with the same nonce as the given one, which are
+// valid as construction and final, the one which fills best the remaining space for cross transactions in the current
+// block. If no better candidate exists, the given meta block is returned
+// this method should be called only under the mutex protection: hdrsForCurrBlock.mutHdrsForBlock
+func (sp *shardProcessor) selectBestFillingMetaBlock(
+	lastMetaHdr data.HeaderHandler,
+	metaHdr data.HeaderHandler,
+	metaHdrHash []byte,
+	txsAdded uint32,
+) (data.HeaderHandler, []byte) {
+	remainingSpace := sp.weightedMetaBlockSelectionMaxTxs - txsAdded
+
+	selectedMetaHdr := metaHdr
+	selectedMetaHdrHash := metaHdrHash
+	selectedNumTxs := uint32(0)
+	selectedFitsRemainingSpace := false
+
+	metaHdrs, metaHdrsHashes := sp.blockTracker.GetTrackedHeadersWithNonce(core.MetachainShardId, metaHdr.GetNonce())
+	for index, candidate := range metaHdrs {
+		err := sp.headerValidator.IsHeaderConstructionValid(candidate, lastMetaHdr)
+		if err != nil {
+			continue
+		}
+		if !sp.isMetaHeaderFinal(candidate) {
+			continue
+		}
+
+		processedMiniBlocksHashes := sp.processedMiniBlocks.GetProcessedMiniBlocksHashes(string(metaHdrsHashes[index]))
+		numTxs := sp.computeNumUnprocessedTxsWithDstMe(candidate, processedMiniBlocksHashes)
+		if numTxs == 0 || numTxs > remainingSpace {
+			continue
+		}
+
+		if !selectedFitsRemainingSpace || numTxs > selectedNumTxs {
+			selectedMetaHdr = candidate
+			selectedMetaHdrHash = metaHdrsHashes[index]
+			selectedNumTxs = numTxs
+			selectedFitsRemainingSpace = true
+		}
+	}
+
+	if selectedFitsRemainingSpace {
+		log.Debug("meta block selected under capacity pressure",
+			"round", selectedMetaHdr.GetRound(),
+			"nonce", selectedMetaHdr.GetNonce(),
+			"hash", selectedMetaHdrHash,
+			"num txs", selectedNumTxs,
+			"remaining space", remainingSpace)
+	}
+
+	return selectedMetaHdr, selectedMetaHdrHash
+}
+
+// isMetaHeaderFinal returns true if the given meta block is final with respect to the tracked meta blocks
+func (sp *shardProcessor) isMetaHeaderFinal(metaHdr data.HeaderHandler) bool {
+	getMetaHeadersWithNonce := func(nonce uint64) []data.HeaderHandler {
+		nextMetaHdrs, _ := sp.blockTracker.GetTrackedHeadersWithNonce(core.MetachainShardId, nonce)
+		return nextMetaHdrs
+	}
+
+	if sp.isMetaHeaderAssertedFinal(metaHdr) {
+		return true
+	}
+
+	return sp.metaFinalityVerifier.IsMetaHeaderFinal(metaHdr, getMetaHeadersWithNonce)
+}
+
+// AssertMetaFinality marks all the meta blocks with nonces up to the given one as final for the finality checks.
+// It should be called only with information from a trusted source, and it can only advance the asserted nonce
+func (sp *shardProcessor) AssertMetaFinality(upToNonce uint64) {
+	sp.mutAssertedFinalMetaNonce.Lock()
+	defer sp.mutAssertedFinalMetaNonce.Unlock()
+
+	if upToNonce <= sp.assertedFinalMetaNonce {
+		return
+	}
+
+	sp.assertedFinalMetaNonce = upToNonce
+	log.Debug("shardProcessor.AssertMetaFinality", "asserted final meta nonce", upToNonce)
+}
+
+func (sp *shardProcessor) isMetaHeaderAssertedFinal(metaHdr data.HeaderHandler) bool {
+	sp.mutAssertedFinalMetaNonce.RLock()
+	defer sp.mutAssertedFinalMetaNonce.RUnlock()
+
+	return metaHdr.GetNonce() <= sp.assertedFinalMetaNonce
+}
+
+// computeNumUnprocessedTxsWithDstMe returns the number of transactions from the not yet processed miniblocks,
+// with destination in self shard, of the given meta block
+func (sp *shardProcessor) computeNumUnprocessedTxsWithDstMe(
+	metaHdr data.HeaderHandler,
+	processedMiniBlocksHashes map[string]struct{},
+) uint32 {
+	metaBlock, ok := metaHdr.(*block.MetaBlock)
+	if !ok {
+		return 0
+	}
+
+	crossMiniBlockHashes := metaBlock.GetMiniBlockHeadersWithDst(sp.shardCoordinator.SelfId())
+	for miniBlockHash := range processedMiniBlocksHashes {
+		delete(crossMiniBlockHashes, miniBlockHash)
+	}
+
+	numTxs := uint32(0)
+	countTxs := func(miniBlockHeader *block.MiniBlockHeader) {
+		_, isCrossMiniBlock := crossMiniBlockHashes[string(miniBlockHeader.Hash)]
+		if !isCrossMiniBlock {
+			return
+		}
+
+		numTxs += miniBlockHeader.TxCount
+		delete(crossMiniBlockHashes, string(miniBlockHeader.Hash))
+	}
+
+	for i := range metaBlock.ShardInfo {
+		for j := range metaBlock.ShardInfo[i].ShardMiniBlockHeaders {
+			countTxs(&metaBlock.ShardInfo[i].ShardMiniBlockHeaders[j])
+		}
+	}
+	for i := range metaBlock.MiniBlockHeaders {
+		countTxs(&metaBlock.MiniBlockHeaders[i])
+	}
+
+	return numTxs
 }
 
 func (sp *shardProcessor) requestMetaHeadersIfNeeded(hdrsAdded uint32, lastMetaHdr data.HeaderHandler) {
@@ -1668,17 +3510,29 @@ func (sp *shardProcessor) requestMetaHeadersIfNeeded(hdrsAdded uint32, lastMetaH
 	}
 }
 
-func (sp *shardProcessor) createMiniBlocks(haveTime func() bool) (*block.Body, error) {
+// createMiniBlocks creates the block body miniblocks and returns them together with the body composition they were
+// created with. The configured from me first composition is used only starting with its enable epoch
+func (sp *shardProcessor) createMiniBlocks(haveTime func() bool, epoch uint32) (*block.Body, process.BodyComposition, error) {
 	var miniBlocks block.MiniBlockSlice
 
 	if sp.accountsDB[state.UserAccountsState].JournalLen() != 0 {
 		log.Error("shardProcessor.createMiniBlocks", "error", process.ErrAccountStateDirty)
-		return &block.Body{MiniBlocks: miniBlocks}, nil
+		return &block.Body{MiniBlocks: miniBlocks}, process.CrossShardFirst, nil
 	}
 
 	if !haveTime() {
 		log.Debug("shardProcessor.createMiniBlocks", "error", process.ErrTimeIsOut)
-		return &block.Body{MiniBlocks: miniBlocks}, nil
+		return &block.Body{MiniBlocks: miniBlocks}, process.CrossShardFirst, nil
+	}
+
+	shouldPrioritizeMetaBlocks := sp.shouldPrioritizeMetaBlocks.IsSet()
+	sp.shouldPrioritizeMetaBlocks.Unset()
+
+	isFromMeFirst := sp.blockCreationPolicy.BodyComposition() == process.FromMeFirst &&
+		sp.isBodyCompositionEnabled(epoch) &&
+		!shouldPrioritizeMetaBlocks
+	if isFromMeFirst && !sp.blockTracker.IsShardStuck(core.MetachainShardId) {
+		return sp.createMiniBlocksFromMeFirst(haveTime), process.FromMeFirst, nil
 	}
 
 	startTime := time.Now()
@@ -1701,6 +3555,16 @@ func (sp *shardProcessor) createMiniBlocks(haveTime func() bool) (*block.Body, e
 		)
 	}
 
+	if sp.blockCreationPolicy.IsMaxBlockBodyBytesReached(sp.computeMiniBlocksSize(miniBlocks)) {
+		log.Debug("shardProcessor.createMiniBlocks: maximum block body size in bytes has been reached",
+			"max block body bytes", sp.blockCreationPolicy.MaxBlockBodyBytes(),
+			"num miniblocks", len(miniBlocks),
+		)
+
+		miniBlocks = append(miniBlocks, sp.txCoordinator.CreatePostProcessMiniBlocks()...)
+		return &block.Body{MiniBlocks: miniBlocks}, process.CrossShardFirst, nil
+	}
+
 	if sp.blockTracker.IsShardStuck(core.MetachainShardId) {
 		log.Warn("shardProcessor.createMiniBlocks", "error", process.ErrShardIsStuck, "shard", core.MetachainShardId)
 
@@ -1709,11 +3573,16 @@ func (sp *shardProcessor) createMiniBlocks(haveTime func() bool) (*block.Body, e
 			miniBlocks = append(miniBlocks, interMBs...)
 		}
 
-		return &block.Body{MiniBlocks: miniBlocks}, nil
+		return &block.Body{MiniBlocks: miniBlocks}, process.CrossShardFirst, nil
 	}
 
 	startTime = time.Now()
-	mbsFromMe := sp.txCoordinator.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbsFromMe := sp.txCoordinator.CreateMbsAndProcessTransactionsFromMe(
+		haveTime,
+		sp.blockCreationPolicy.MinGasPriceForInclusion(),
+		sp.blockCreationPolicy.MaxTxDataSize(),
+		sp.blockCreationPolicy.MaxTxsPerDestShard(),
+	)
 	elapsedTime = time.Since(startTime)
 	log.Debug("elapsed time to create mbs from me",
 		"time [s]", elapsedTime,
@@ -1736,7 +3605,181 @@ func (sp *shardProcessor) createMiniBlocks(haveTime func() bool) (*block.Body, e
 	log.Debug("creating mini blocks has been finished",
 		"num miniblocks", len(miniBlocks),
 	)
-	return &block.Body{MiniBlocks: miniBlocks}, nil
+	return &block.Body{MiniBlocks: miniBlocks}, process.CrossShardFirst, nil
+}
+
+// createMiniBlocksFromMeFirst creates the block body miniblocks starting with the ones from self shard, followed by the
+// ones with destination in self shard. The post process miniblocks are created at the end, after all the
+// transactions have been processed, so they also contain the results of the cross shard transactions
+func (sp *shardProcessor) createMiniBlocksFromMeFirst(haveTime func() bool) *block.Body {
+	startTime := time.Now()
+	mbsFromMe := sp.txCoordinator.CreateMbsAndProcessTransactionsFromMeWithoutPostProcess(
+		haveTime,
+		sp.blockCreationPolicy.MinGasPriceForInclusion(),
+		sp.blockCreationPolicy.MaxTxDataSize(),
+		sp.blockCreationPolicy.MaxTxsPerDestShard(),
+	)
+	elapsedTime := time.Since(startTime)
+	log.Debug("elapsed time to create mbs from me",
+		"time [s]", elapsedTime,
+	)
+
+	miniBlocks := make(block.MiniBlockSlice, 0, len(mbsFromMe))
+	miniBlocks = append(miniBlocks, mbsFromMe...)
+	if len(mbsFromMe) > 0 {
+		numTxs := 0
+		for _, mb := range mbsFromMe {
+			numTxs += len(mb.TxHashes)
+		}
+
+		log.Debug("processed miniblocks and txs from self shard",
+			"num miniblocks", len(mbsFromMe),
+			"num txs", numTxs,
+		)
+	}
+
+	if sp.blockCreationPolicy.IsMaxBlockBodyBytesReached(sp.computeMiniBlocksSize(miniBlocks)) {
+		log.Debug("shardProcessor.createMiniBlocksFromMeFirst: maximum block body size in bytes has been reached",
+			"max block body bytes", sp.blockCreationPolicy.MaxBlockBodyBytes(),
+			"num miniblocks", len(miniBlocks),
+		)
+
+		miniBlocks = append(miniBlocks, sp.txCoordinator.CreatePostProcessMiniBlocks()...)
+		return &block.Body{MiniBlocks: miniBlocks}
+	}
+
+	startTime = time.Now()
+	mbsToMe, numTxs, numMetaHeaders, err := sp.createAndProcessMiniBlocksDstMe(haveTime)
+	elapsedTime = time.Since(startTime)
+	log.Debug("elapsed time to create mbs to me",
+		"time [s]", elapsedTime,
+	)
+	if err != nil {
+		log.Debug("createAndProcessCrossMiniBlocksDstMe", "error", err.Error())
+	}
+
+	if len(mbsToMe) > 0 {
+		miniBlocks = append(miniBlocks, mbsToMe...)
+
+		log.Debug("processed miniblocks and txs with destination in self shard",
+			"num miniblocks", len(mbsToMe),
+			"num txs", numTxs,
+			"num meta headers", numMetaHeaders,
+		)
+	}
+
+	miniBlocks = append(miniBlocks, sp.txCoordinator.CreatePostProcessMiniBlocks()...)
+
+	log.Debug("creating mini blocks has been finished",
+		"num miniblocks", len(miniBlocks),
+	)
+	return &block.Body{MiniBlocks: miniBlocks}
+}
+
+// setBodyComposition records in the reserved field of the given header the body composition its body was created
+// with. The default cross shard first composition is recorded as an empty reserved field
+func setBodyComposition(header *block.Header, bodyComposition process.BodyComposition) {
+	if bodyComposition == process.FromMeFirst {
+		header.Reserved = []byte(process.FromMeFirst)
+		return
+	}
+
+	header.Reserved = nil
+}
+
+// getBodyComposition returns the body composition recorded in the reserved field of the given header. Before the
+// body composition enable epoch, the reserved field should be empty
+func (sp *shardProcessor) getBodyComposition(header *block.Header) (process.BodyComposition, error) {
+	if len(header.Reserved) == 0 {
+		return process.CrossShardFirst, nil
+	}
+	isFromMeFirst := bytes.Equal(header.Reserved, []byte(process.FromMeFirst))
+	if isFromMeFirst && sp.isBodyCompositionEnabled(header.GetEpoch()) {
+		return process.FromMeFirst, nil
+	}
+
+	return "", fmt.Errorf("%w in header reserved field", process.ErrInvalidBodyComposition)
+}
+
+// isBodyCompositionEnabled returns true if the blocks from the given epoch could be created with a body composition
+// other than the default cross shard first one
+func (sp *shardProcessor) isBodyCompositionEnabled(epoch uint32) bool {
+	return epoch >= sp.bodyCompositionEnableEpoch
+}
+
+// processBlockTransactions processes the block transactions in the order given by the body composition
+func (sp *shardProcessor) processBlockTransactions(
+	body *block.Body,
+	bodyComposition process.BodyComposition,
+	haveTime func() time.Duration,
+) error {
+	if bodyComposition == process.FromMeFirst {
+		return sp.txCoordinator.ProcessBlockTransactionFromMeFirst(body, haveTime)
+	}
+
+	return sp.txCoordinator.ProcessBlockTransaction(body, haveTime)
+}
+
+// isMaxAttestedMetaBlocksReached returns true if the max attested meta blocks per block is set and the given number
+// of meta blocks reached it
+func (sp *shardProcessor) isMaxAttestedMetaBlocksReached(numMetaBlocks uint32) bool {
+	return sp.maxAttestedMetaBlocksPerBlock > 0 && numMetaBlocks >= sp.maxAttestedMetaBlocksPerBlock
+}
+
+// checkAttestedMetaBlocksCount verifies that the given header does not attest more meta blocks than allowed, starting
+// with the attested meta blocks check enable epoch
+func (sp *shardProcessor) checkAttestedMetaBlocksCount(header *block.Header) error {
+	if header.GetEpoch() < sp.metaBlocksCountCheckEnableEpoch {
+		return nil
+	}
+
+	numMetaBlocks := uint32(len(header.MetaBlockHashes))
+	if sp.maxAttestedMetaBlocksPerBlock > 0 && numMetaBlocks > sp.maxAttestedMetaBlocksPerBlock {
+		return fmt.Errorf("%w, num attested meta blocks: %d, max attested meta blocks: %d",
+			process.ErrTooManyAttestedMetaBlocks, numMetaBlocks, sp.maxAttestedMetaBlocksPerBlock)
+	}
+
+	return nil
+}
+
+// computeMiniBlocksSize returns the total size in bytes of the given marshalized miniblocks
+func (sp *shardProcessor) computeMiniBlocksSize(miniBlocks block.MiniBlockSlice) int {
+	size := 0
+	for _, miniBlock := range miniBlocks {
+		marshalizedMiniBlock, err := sp.marshalizer.Marshal(miniBlock)
+		if err != nil {
+			log.Debug("computeMiniBlocksSize.Marshal", "error", err.Error())
+			continue
+		}
+
+		size += len(marshalizedMiniBlock)
+	}
+
+	return size
+}
+
+// checkRoundMatchesCreatedBody verifies that, if the given block body is the last one created by this processor, the
+// header round is the same as the round for which the body was created. The created body is forgotten afterwards.
+func (sp *shardProcessor) checkRoundMatchesCreatedBody(round uint64, body *block.Body) error {
+	createdBodyHash := sp.createdBodyHash
+	sp.createdBodyHash = nil
+	if len(createdBodyHash) == 0 {
+		return nil
+	}
+
+	bodyHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, body)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(bodyHash, createdBodyHash) {
+		return nil
+	}
+	if sp.createdBodyRound != round {
+		return fmt.Errorf("%w, body round: %d, header round: %d",
+			process.ErrRoundMismatchBetweenBodyAndHeader, sp.createdBodyRound, round)
+	}
+
+	return nil
 }
 
 // applyBodyToHeader creates a miniblock header list given a block body
@@ -1752,14 +3795,19 @@ func (sp *shardProcessor) applyBodyToHeader(shardHeader *block.Header, body *blo
 	shardHeader.RootHash = sp.getRootHash()
 
 	defer func() {
-		go sp.checkAndRequestIfMetaHeadersMissing()
+		// the header is not yet signed, so its final hash is not known and the errors are only logged
+		sp.startBlockBackgroundRoutine(nil, "checkAndRequestIfMetaHeadersMissing", sp.checkAndRequestIfMetaHeadersMissing)
 	}()
 
 	if check.IfNil(body) {
 		return nil, process.ErrNilBlockBody
 	}
 
-	var err error
+	err := sp.checkRoundMatchesCreatedBody(shardHeader.GetRound(), body)
+	if err != nil {
+		return nil, err
+	}
+
 	sw.Start("CreateReceiptsHash")
 	shardHeader.ReceiptsHash, err = sp.txCoordinator.CreateReceiptsHash()
 	sw.Stop("CreateReceiptsHash")
@@ -1776,6 +3824,13 @@ func (sp *shardProcessor) applyBodyToHeader(shardHeader *block.Header, body *blo
 		return nil, err
 	}
 
+	if sp.strictHeaderValidation {
+		err = sp.checkBodyTxsAreUsed(newBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	shardHeader.MiniBlockHeaders = miniBlockHeaders
 	shardHeader.TxCount = uint32(totalTxCount)
 	shardHeader.AccumulatedFees = sp.feeHandler.GetAccumulatedFees()
@@ -1800,15 +3855,192 @@ func (sp *shardProcessor) applyBodyToHeader(shardHeader *block.Header, body *blo
 	}
 	sp.blockSizeThrottler.Add(shardHeader.GetRound(), uint32(len(marshalizedBody)))
 
+	sp.setPendingCrossShardMiniBlocks(miniBlockHeaders)
+
 	return newBody, nil
 }
 
+// TouchedAccounts returns the unique sender and receiver addresses of the transactions included in the provided block
+// body, in the order they first appear. The transactions are fetched through the transaction coordinator, so the body
+// has to be the one of the last processed or committed block
+func (sp *shardProcessor) TouchedAccounts(body *block.Body) ([][]byte, error) {
+	if check.IfNil(body) {
+		return nil, process.ErrNilBlockBody
+	}
+
+	usedTxsByType := make(map[block.Type]map[string]data.TransactionHandler)
+	touchedAccounts := make([][]byte, 0)
+	seenAccounts := make(map[string]struct{})
+	addAccount := func(address []byte) {
+		if len(address) == 0 {
+			return
+		}
+		if _, ok := seenAccounts[string(address)]; ok {
+			return
+		}
+
+		seenAccounts[string(address)] = struct{}{}
+		touchedAccounts = append(touchedAccounts, address)
+	}
+
+	for _, miniBlock := range body.MiniBlocks {
+		if miniBlock.Type == block.PeerBlock {
+			continue
+		}
+
+		usedTxs, ok := usedTxsByType[miniBlock.Type]
+		if !ok {
+			usedTxs = sp.txCoordinator.GetAllCurrentUsedTxs(miniBlock.Type)
+			usedTxsByType[miniBlock.Type] = usedTxs
+		}
+
+		for _, txHash := range miniBlock.TxHashes {
+			tx, found := usedTxs[string(txHash)]
+			if !found {
+				return nil, fmt.Errorf("%w, miniblock type: %s, tx hash: %s",
+					process.ErrMissingTransaction, miniBlock.Type.String(), logger.DisplayByteSlice(txHash))
+			}
+
+			addAccount(tx.GetSndAddr())
+			addAccount(tx.GetRcvAddr())
+		}
+	}
+
+	return touchedAccounts, nil
+}
+
+func (sp *shardProcessor) setPendingCrossShardMiniBlocks(miniBlockHeaders []block.MiniBlockHeader) {
+	selfShardID := sp.shardCoordinator.SelfId()
+	pendingCrossShardMiniBlocks := make(map[uint32][][]byte)
+	for _, miniBlockHeader := range miniBlockHeaders {
+		isCrossShardFromMe := miniBlockHeader.SenderShardID == selfShardID && miniBlockHeader.ReceiverShardID != selfShardID
+		if !isCrossShardFromMe {
+			continue
+		}
+
+		pendingCrossShardMiniBlocks[miniBlockHeader.ReceiverShardID] = append(
+			pendingCrossShardMiniBlocks[miniBlockHeader.ReceiverShardID],
+			miniBlockHeader.Hash,
+		)
+	}
+
+	sp.mutPendingCrossShardMiniBlocks.Lock()
+	sp.pendingCrossShardMiniBlocks = pendingCrossShardMiniBlocks
+	sp.mutPendingCrossShardMiniBlocks.Unlock()
+}
+
+// GetStoredHeaderBytesByNonce returns the marshalized self shard header, as it was persisted when committed, for the
+// given nonce. It resolves the header hash from the nonce to hash storage unit and then reads the header bytes
+func (sp *shardProcessor) GetStoredHeaderBytesByNonce(nonce uint64) ([]byte, error) {
+	nonceToByteSlice := sp.uint64Converter.ToByteSlice(nonce)
+	hdrNonceHashDataUnit := dataRetriever.ShardHdrNonceHashDataUnit + dataRetriever.UnitType(sp.shardCoordinator.SelfId())
+
+	headerHash, err := sp.store.Get(hdrNonceHashDataUnit, nonceToByteSlice)
+	if err != nil {
+		return nil, fmt.Errorf("%w, nonce: %d, error: %s", process.ErrBlockNotInStorage, nonce, err.Error())
+	}
+
+	headerBytes, err := sp.store.Get(dataRetriever.BlockHeaderUnit, headerHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w, nonce: %d, hash: %s, error: %s",
+			process.ErrBlockNotInStorage,
+			nonce,
+			logger.DisplayByteSlice(headerHash),
+			err.Error(),
+		)
+	}
+
+	return headerBytes, nil
+}
+
+// FindOrphanedMiniBlocks returns the hashes of the miniblocks found in the miniblocks storage unit which are not
+// referenced by any of the self shard headers committed with nonces in the given inclusive range. It is a read only
+// audit which does not remove anything. The storers which are not able to iterate over their keys report nothing
+func (sp *shardProcessor) FindOrphanedMiniBlocks(fromNonce uint64, toNonce uint64) ([][]byte, error) {
+	if fromNonce > toNonce {
+		return nil, fmt.Errorf("%w, from nonce: %d, to nonce: %d", process.ErrInvalidNonceRange, fromNonce, toNonce)
+	}
+
+	referencedMiniBlocks := make(map[string]struct{})
+	for nonce := fromNonce; nonce <= toNonce; nonce++ {
+		headerBytes, err := sp.GetStoredHeaderBytesByNonce(nonce)
+		if err != nil {
+			return nil, err
+		}
+
+		header := &block.Header{}
+		err = sp.marshalizer.Unmarshal(header, headerBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w while unmarshaling the header with nonce %d", err, nonce)
+		}
+
+		for _, miniBlockHeader := range header.MiniBlockHeaders {
+			referencedMiniBlocks[string(miniBlockHeader.Hash)] = struct{}{}
+		}
+	}
+
+	orphanedMiniBlocks := make([][]byte, 0)
+	sp.store.GetStorer(dataRetriever.MiniBlockUnit).RangeKeys(func(key []byte, _ []byte) bool {
+		_, isReferenced := referencedMiniBlocks[string(key)]
+		if !isReferenced {
+			// the key buffer could be reused by the storer during the iteration
+			miniBlockHash := make([]byte, len(key))
+			copy(miniBlockHash, key)
+			orphanedMiniBlocks = append(orphanedMiniBlocks, miniBlockHash)
+		}
+
+		return true
+	})
+
+	return orphanedMiniBlocks, nil
+}
+
+func (sp *shardProcessor) setLastThrottleSuccess(round uint64) {
+	sp.mutLastThrottleSuccess.Lock()
+	sp.lastThrottleSuccessRound = round
+	sp.lastThrottleSuccessMaxItems = sp.blockSizeThrottler.GetCurrentMaxSize()
+	sp.mutLastThrottleSuccess.Unlock()
+}
+
+// LastThrottleSuccess returns the last round for which the block size throttler was notified about a successfully
+// committed block, together with the max size reported by the throttler at that moment
+func (sp *shardProcessor) LastThrottleSuccess() (round uint64, maxItems uint32) {
+	sp.mutLastThrottleSuccess.RLock()
+	defer sp.mutLastThrottleSuccess.RUnlock()
+
+	return sp.lastThrottleSuccessRound, sp.lastThrottleSuccessMaxItems
+}
+
+// GetProcessedMiniBlocksInfo returns, for each hex encoded meta block hash, the hex encoded hashes of the cross shard
+// miniblocks already processed from it. The returned map is a copy, so it can be freely modified by the caller
+func (sp *shardProcessor) GetProcessedMiniBlocksInfo() map[string][]string {
+	return sp.processedMiniBlocks.GetProcessedMiniBlocksInfo()
+}
+
+// PendingCrossShardMiniBlocks returns, for each destination shard, the hashes of the cross shard miniblocks created
+// by this node in the last created block body
+func (sp *shardProcessor) PendingCrossShardMiniBlocks() map[uint32][][]byte {
+	sp.mutPendingCrossShardMiniBlocks.RLock()
+	defer sp.mutPendingCrossShardMiniBlocks.RUnlock()
+
+	pendingCrossShardMiniBlocks := make(map[uint32][][]byte, len(sp.pendingCrossShardMiniBlocks))
+	for shardID, miniBlockHashes := range sp.pendingCrossShardMiniBlocks {
+		pendingCrossShardMiniBlocks[shardID] = append(make([][]byte, 0, len(miniBlockHashes)), miniBlockHashes...)
+	}
+
+	return pendingCrossShardMiniBlocks
+}
+
+// waitForMetaHdrHashes waits for the missing meta headers until the given time elapses, returning early when the
+// processor is closed
 func (sp *shardProcessor) waitForMetaHdrHashes(waitTime time.Duration) error {
 	select {
 	case <-sp.chRcvAllMetaHdrs:
 		return nil
 	case <-time.After(waitTime):
 		return process.ErrTimeIsOut
+	case <-sp.chStop:
+		return process.ErrProcessClosing
 	}
 }
 
@@ -1840,7 +4072,13 @@ func (sp *shardProcessor) MarshalizedDataToBroadcast(
 
 	mrsData := make(map[uint32][]byte, len(bodies))
 	for shardId, subsetBlockBody := range bodies {
-		bodyForShard := block.Body{MiniBlocks: subsetBlockBody}
+		sortedMiniBlocks, err := sp.sortMiniBlocksForBroadcast(subsetBlockBody)
+		if err != nil {
+			log.Error("shardProcessor.MarshalizedDataToBroadcast.sortMiniBlocksForBroadcast", "error", err.Error())
+			continue
+		}
+
+		bodyForShard := block.Body{MiniBlocks: sortedMiniBlocks}
 		buff, err := sp.marshalizer.Marshal(&bodyForShard)
 		if err != nil {
 			log.Error("shardProcessor.MarshalizedDataToBroadcast.Marshal", "error", err.Error())
@@ -1852,6 +4090,146 @@ func (sp *shardProcessor) MarshalizedDataToBroadcast(
 	return mrsData, mrsTxs, nil
 }
 
+// sortMiniBlocksForBroadcast returns a copy of the given miniblocks sorted by type, sender shard, receiver shard and
+// hash, so that the same body always produces the same broadcast data
+func (sp *shardProcessor) sortMiniBlocksForBroadcast(miniBlocks block.MiniBlockSlice) (block.MiniBlockSlice, error) {
+	sortedMiniBlocks := make(block.MiniBlockSlice, len(miniBlocks))
+	copy(sortedMiniBlocks, miniBlocks)
+
+	miniBlocksHashes := make(map[*block.MiniBlock][]byte, len(miniBlocks))
+	for _, miniBlock := range miniBlocks {
+		miniBlockHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, miniBlock)
+		if err != nil {
+			return nil, err
+		}
+
+		miniBlocksHashes[miniBlock] = miniBlockHash
+	}
+
+	sort.SliceStable(sortedMiniBlocks, func(i, j int) bool {
+		first, second := sortedMiniBlocks[i], sortedMiniBlocks[j]
+		if first.Type != second.Type {
+			return first.Type < second.Type
+		}
+		if first.SenderShardID != second.SenderShardID {
+			return first.SenderShardID < second.SenderShardID
+		}
+		if first.ReceiverShardID != second.ReceiverShardID {
+			return first.ReceiverShardID < second.ReceiverShardID
+		}
+
+		return bytes.Compare(miniBlocksHashes[first], miniBlocksHashes[second]) < 0
+	})
+
+	return sortedMiniBlocks, nil
+}
+
+// startBackgroundRoutine launches the given handler on a new go routine which is tracked until its completion,
+// so that Close could wait for it. Nothing is launched after the processor has been closed.
+func (sp *shardProcessor) startBackgroundRoutine(handler func()) {
+	sp.mutBackgroundRoutines.RLock()
+	defer sp.mutBackgroundRoutines.RUnlock()
+
+	if sp.isClosed.IsSet() {
+		return
+	}
+
+	sp.wgBackgroundRoutines.Add(1)
+	go func() {
+		defer sp.wgBackgroundRoutines.Done()
+		handler()
+	}()
+}
+
+// startBlockBackgroundRoutine launches the given handler as a tracked background go routine. The error returned by
+// the handler, if any, is logged and saved against the hash of the given header, so that it could be later fetched
+// through GetBlockBackgroundErrors. A nil header means that the error is only logged
+func (sp *shardProcessor) startBlockBackgroundRoutine(header data.HeaderHandler, taskName string, handler func() error) {
+	sp.startBackgroundRoutine(func() {
+		err := handler()
+		if err != nil {
+			log.Debug(taskName, "error", err.Error())
+			sp.addBlockBackgroundError(header, fmt.Errorf("%s: %w", taskName, err))
+		}
+	})
+}
+
+func (sp *shardProcessor) addBlockBackgroundError(header data.HeaderHandler, err error) {
+	if check.IfNil(header) {
+		return
+	}
+
+	headerHash, errHash := sp.ComputeHeaderHash(header)
+	if errHash != nil {
+		log.Debug("addBlockBackgroundError.ComputeHeaderHash", "error", errHash.Error())
+		return
+	}
+
+	sp.blockBackgroundErrors.add(headerHash, err)
+}
+
+// GetBlockBackgroundErrors returns the errors encountered by the background go routines launched while processing
+// the block with the given header hash. Only the errors of a limited number of recent blocks are kept
+func (sp *shardProcessor) GetBlockBackgroundErrors(headerHash []byte) []error {
+	return sp.blockBackgroundErrors.get(headerHash)
+}
+
+// SetTxsPoolsCleaner replaces the txs pools cleaner used by the shard processor. The shard processor never starts a
+// cleaner: the provided one should already be running, as the node's cleaner is, and the replaced one, if any, is
+// closed so that a single cleaner works on the txs pools at any time
+func (sp *shardProcessor) SetTxsPoolsCleaner(cleaner process.PoolsCleaner) error {
+	if check.IfNil(cleaner) {
+		return process.ErrNilTxsPoolsCleaner
+	}
+
+	sp.mutTxsPoolsCleaner.Lock()
+	previousCleaner := sp.txsPoolsCleaner
+	sp.txsPoolsCleaner = cleaner
+	sp.mutTxsPoolsCleaner.Unlock()
+
+	if check.IfNil(previousCleaner) || previousCleaner == cleaner {
+		return nil
+	}
+
+	err := previousCleaner.Close()
+	if err != nil {
+		log.Debug("SetTxsPoolsCleaner: previous cleaner Close", "error", err.Error())
+	}
+
+	return nil
+}
+
+// Close signals the stop of the shard processor, detaches it from the metablocks pool notifications and waits,
+// for a limited time, for all the in-flight background go routines to finish
+func (sp *shardProcessor) Close() error {
+	sp.mutBackgroundRoutines.Lock()
+	wasAlreadyClosed := sp.isClosed.Set()
+	if !wasAlreadyClosed {
+		close(sp.chStop)
+	}
+	sp.mutBackgroundRoutines.Unlock()
+
+	if wasAlreadyClosed {
+		return nil
+	}
+
+	sp.deregisterMetaBlockHandler()
+	sp.closeFinalBlockAdvanceSubscribers()
+
+	chDone := make(chan struct{})
+	go func() {
+		sp.wgBackgroundRoutines.Wait()
+		close(chDone)
+	}()
+
+	select {
+	case <-chDone:
+		return nil
+	case <-time.After(maxTimeToWaitForBackgroundRoutines):
+		return fmt.Errorf("%w while waiting for the shard processor background go routines to finish", process.ErrTimeIsOut)
+	}
+}
+
 // IsInterfaceNil returns true if there is no value under the interface
 func (sp *shardProcessor) IsInterfaceNil() bool {
 	return sp == nil
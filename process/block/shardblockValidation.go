@@ -0,0 +1,342 @@
+package block
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	logger "github.com/ElrondNetwork/elrond-go-logger"
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/dataRetriever"
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+// checkPrevRandSeedOfFirstBlock checks that the first block after genesis is built on the random seed of the genesis
+// header, as the next blocks are checked against the random seed of the current block header. The check is done
+// starting with the genesis rand seed check enable epoch
+func (sp *shardProcessor) checkPrevRandSeedOfFirstBlock(headerHandler data.HeaderHandler) error {
+	isFirstBlockAfterGenesis := headerHandler.GetNonce() == sp.genesisNonce+1 &&
+		check.IfNil(sp.blockChain.GetCurrentBlockHeader())
+	if !isFirstBlockAfterGenesis || headerHandler.GetEpoch() < sp.genesisRandSeedCheckEnableEpoch {
+		return nil
+	}
+
+	genesisHeader := sp.blockChain.GetGenesisHeader()
+	if check.IfNil(genesisHeader) {
+		return nil
+	}
+
+	if !bytes.Equal(headerHandler.GetPrevRandSeed(), genesisHeader.GetRandSeed()) {
+		log.Debug("random seed does not match",
+			"genesis random seed", genesisHeader.GetRandSeed(),
+			"received previous random seed", headerHandler.GetPrevRandSeed())
+
+		return process.ErrRandSeedDoesNotMatch
+	}
+
+	return nil
+}
+
+// checkHeaderRoundClockSkew checks that the timestamp implied by the header round, computed from the genesis time and
+// the round duration, is not too far ahead of the local time. Rounds from the past are expected while syncing
+func (sp *shardProcessor) checkHeaderRoundClockSkew(header data.HeaderHandler) error {
+	if sp.maxRoundClockSkew == 0 {
+		return nil
+	}
+
+	roundDuration := sp.rounder.TimeDuration()
+	expectedTimeStamp := sp.genesisTime.Add(time.Duration(header.GetRound()) * roundDuration)
+	skew := time.Until(expectedTimeStamp)
+	if skew > sp.maxRoundClockSkew {
+		return fmt.Errorf("%w, round: %d, expected time stamp: %s, skew: %s",
+			process.ErrHeaderRoundClockSkew, header.GetRound(), expectedTimeStamp.String(), skew.String())
+	}
+
+	return nil
+}
+
+// checkHeaderTxCountIfEnabled checks the header tx count against the body, starting with the header tx count check
+// enable epoch
+func (sp *shardProcessor) checkHeaderTxCountIfEnabled(header *block.Header, body *block.Body) error {
+	if header.GetEpoch() < sp.headerTxCountCheckEnableEpoch {
+		return nil
+	}
+
+	return checkHeaderTxCount(header, body)
+}
+
+// checkHeaderTxCount recomputes the total number of transactions from the body and compares it with the header tx count
+func checkHeaderTxCount(header *block.Header, body *block.Body) error {
+	totalTxCount := 0
+	for _, miniBlock := range body.MiniBlocks {
+		totalTxCount += len(miniBlock.TxHashes)
+	}
+
+	if uint32(totalTxCount) != header.TxCount {
+		return fmt.Errorf("%w, header tx count: %d, body tx count: %d",
+			process.ErrHeaderTxCountMismatch, header.TxCount, totalTxCount)
+	}
+
+	return nil
+}
+
+// checkHeaderEpochAgainstAttestedMetaBlocks verifies that the header epoch matches the highest epoch of the attested
+// meta blocks. Only an epoch start block is allowed to be one epoch ahead, while the header could be one epoch behind
+// when it attests the meta block which starts the new epoch. The check is done starting with the header epoch check
+// enable epoch
+func (sp *shardProcessor) checkHeaderEpochAgainstAttestedMetaBlocks(header *block.Header) error {
+	if header.GetEpoch() < sp.headerEpochCheckEnableEpoch {
+		return nil
+	}
+
+	usedMetaHdrs := sp.sortHeadersForCurrentBlockByNonce(true)
+	if len(usedMetaHdrs[core.MetachainShardId]) == 0 {
+		return nil
+	}
+
+	maxMetaEpoch := uint32(0)
+	for _, metaHdr := range usedMetaHdrs[core.MetachainShardId] {
+		if metaHdr.GetEpoch() > maxMetaEpoch {
+			maxMetaEpoch = metaHdr.GetEpoch()
+		}
+	}
+
+	isEpochMatching := header.GetEpoch() == maxMetaEpoch
+	isOneEpochBehind := header.GetEpoch()+1 == maxMetaEpoch
+	isEpochStartOneEpochAhead := header.GetEpoch() == maxMetaEpoch+1 && header.IsStartOfEpochBlock()
+	if isEpochMatching || isOneEpochBehind || isEpochStartOneEpochAhead {
+		return nil
+	}
+
+	return fmt.Errorf("%w, header epoch: %d, max attested meta block epoch: %d",
+		process.ErrHeaderEpochMismatch, header.GetEpoch(), maxMetaEpoch)
+}
+
+// PreSignValidate verifies, before signing, that the given header created by this node is consistent with its body:
+// the root hash has the hasher output length, the tx count matches the body, the miniblock headers match the body
+// miniblocks and all the attested meta blocks could be resolved from pool or storage
+func (sp *shardProcessor) PreSignValidate(header data.HeaderHandler, body block.Body) error {
+	if check.IfNil(header) {
+		return process.ErrNilBlockHeader
+	}
+	shardHeader, ok := header.(*block.Header)
+	if !ok {
+		return process.ErrWrongTypeAssertion
+	}
+
+	err := sp.checkRootHashLength(shardHeader.GetRootHash())
+	if err != nil {
+		return err
+	}
+
+	err = checkHeaderTxCount(shardHeader, &body)
+	if err != nil {
+		return err
+	}
+
+	err = sp.checkHeaderBodyCorrelation(shardHeader.MiniBlockHeaders, &body, shardHeader.GetEpoch())
+	if err != nil {
+		return err
+	}
+
+	for _, metaBlockHash := range shardHeader.MetaBlockHashes {
+		_, err = process.GetMetaHeader(metaBlockHash, sp.dataPool.Headers(), sp.marshalizer, sp.store)
+		if err != nil {
+			return fmt.Errorf("%w for attested meta block hash %s, error: %s",
+				process.ErrMissingHeader, logger.DisplayByteSlice(metaBlockHash), err.Error())
+		}
+	}
+
+	return nil
+}
+
+// validateBodyShardIdsIfEnabled validates the shard ids of the body miniblocks, starting with the body shard ids check
+// enable epoch
+func (sp *shardProcessor) validateBodyShardIdsIfEnabled(header *block.Header, body *block.Body) error {
+	if header.GetEpoch() < sp.bodyShardIdsCheckEnableEpoch {
+		return nil
+	}
+
+	return sp.ValidateBodyShardIds(*body)
+}
+
+// ValidateBodyShardIds checks that the sender and the receiver shard ids of each miniblock from the given body are
+// valid miniblock shard ids
+func (sp *shardProcessor) ValidateBodyShardIds(body block.Body) error {
+	for index, miniBlock := range body.MiniBlocks {
+		if miniBlock == nil {
+			return process.ErrNilMiniBlock
+		}
+
+		if !sp.isValidMiniBlockShardIds(miniBlock) {
+			return fmt.Errorf("%w, miniblock index: %d, sender shard: %d, receiver shard: %d",
+				process.ErrMiniBlockInvalidShardId, index, miniBlock.SenderShardID, miniBlock.ReceiverShardID)
+		}
+	}
+
+	return nil
+}
+
+// checkMiniBlockHeadersAreUnique verifies that the created header does not reference the same miniblock more than once,
+// which would happen if the body contains identical miniblocks
+func checkMiniBlockHeadersAreUnique(miniBlockHeaders []block.MiniBlockHeader) error {
+	miniBlockHashes := make(map[string]struct{}, len(miniBlockHeaders))
+	for _, miniBlockHeader := range miniBlockHeaders {
+		_, isDuplicate := miniBlockHashes[string(miniBlockHeader.Hash)]
+		if isDuplicate {
+			return process.ErrDuplicateMiniBlockInBody
+		}
+		miniBlockHashes[string(miniBlockHeader.Hash)] = struct{}{}
+	}
+
+	return nil
+}
+
+// checkMiniBlocksReceiverShardIsSelf verifies that all the given miniblock hashes, considered by the header as having
+// the destination in self shard, correspond to body miniblocks which are really destined to self shard
+func (sp *shardProcessor) checkMiniBlocksReceiverShardIsSelf(
+	body *block.Body,
+	miniBlockHashesDstMe map[string]uint32,
+) error {
+	receiverShardIDs := make(map[string]uint32, len(body.MiniBlocks))
+	for _, miniBlock := range body.MiniBlocks {
+		miniBlockHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, miniBlock)
+		if err != nil {
+			return err
+		}
+
+		receiverShardIDs[string(miniBlockHash)] = miniBlock.ReceiverShardID
+	}
+
+	selfShardID := sp.shardCoordinator.SelfId()
+	for hash := range miniBlockHashesDstMe {
+		receiverShardID, ok := receiverShardIDs[hash]
+		if !ok || receiverShardID != selfShardID {
+			return fmt.Errorf("%w, hash: %s", process.ErrMiniBlockWrongReceiverShard, logger.DisplayByteSlice([]byte(hash)))
+		}
+	}
+
+	return nil
+}
+
+// ValidateMiniBlock checks a standalone miniblock, without its block: the miniblock hash should be computable, its
+// sender and receiver shards should be known by the shard coordinator and all its tx hashes should be resolvable
+// from the data pool of the miniblock type, falling back to the storage
+func (sp *shardProcessor) ValidateMiniBlock(mb *block.MiniBlock) error {
+	if mb == nil {
+		return process.ErrNilMiniBlock
+	}
+
+	miniBlockHash, err := core.CalculateHash(sp.marshalizer, sp.hasher, mb)
+	if err != nil {
+		return err
+	}
+
+	if !sp.isValidMiniBlockShardIds(mb) {
+		return fmt.Errorf("%w, miniblock hash: %s, sender shard: %d, receiver shard: %d",
+			process.ErrMiniBlockInvalidShardId, logger.DisplayByteSlice(miniBlockHash), mb.SenderShardID, mb.ReceiverShardID)
+	}
+
+	if mb.Type == block.PeerBlock {
+		return nil
+	}
+
+	txsPool, txsUnit, err := sp.getTxsPoolAndStorageUnit(mb.Type)
+	if err != nil {
+		return fmt.Errorf("%w, miniblock hash: %s, miniblock type: %s",
+			err, logger.DisplayByteSlice(miniBlockHash), mb.Type.String())
+	}
+
+	for _, txHash := range mb.TxHashes {
+		if len(txHash) == 0 {
+			return fmt.Errorf("%w, miniblock hash: %s", process.ErrNilTxHash, logger.DisplayByteSlice(miniBlockHash))
+		}
+
+		if !sp.isTxInPoolOrStorage(mb, txHash, txsPool, txsUnit) {
+			return fmt.Errorf("%w, miniblock hash: %s, miniblock type: %s, tx hash: %s",
+				process.ErrMiniBlockReferencesMissingTxs, logger.DisplayByteSlice(miniBlockHash),
+				mb.Type.String(), logger.DisplayByteSlice(txHash))
+		}
+	}
+
+	return nil
+}
+
+// getTxsPoolAndStorageUnit returns the data pool and the storage unit which hold the transactions of the given
+// miniblock type. The receipts are not kept in a data pool, so only the storage is returned for them
+func (sp *shardProcessor) getTxsPoolAndStorageUnit(
+	blockType block.Type,
+) (dataRetriever.ShardedDataCacherNotifier, dataRetriever.UnitType, error) {
+	switch blockType {
+	case block.TxBlock, block.InvalidBlock:
+		return sp.dataPool.Transactions(), dataRetriever.TransactionUnit, nil
+	case block.SmartContractResultBlock:
+		return sp.dataPool.UnsignedTransactions(), dataRetriever.UnsignedTransactionUnit, nil
+	case block.RewardsBlock:
+		return sp.dataPool.RewardTransactions(), dataRetriever.RewardTransactionUnit, nil
+	case block.ReceiptBlock:
+		return nil, dataRetriever.ReceiptsUnit, nil
+	default:
+		return nil, 0, process.ErrUnknownBlockType
+	}
+}
+
+func (sp *shardProcessor) isTxInPoolOrStorage(
+	mb *block.MiniBlock,
+	txHash []byte,
+	txsPool dataRetriever.ShardedDataCacherNotifier,
+	txsUnit dataRetriever.UnitType,
+) bool {
+	if !check.IfNil(txsPool) {
+		_, err := process.GetTransactionHandlerFromPool(mb.SenderShardID, mb.ReceiverShardID, txHash, txsPool, true)
+		if err == nil {
+			return true
+		}
+	}
+
+	return sp.store.Has(txsUnit, txHash) == nil
+}
+
+// isValidMiniBlockShardIds returns true if the miniblock sender is an existing shard or the metachain, as a miniblock
+// is always created by a single shard, and if the receiver is an existing shard, the metachain or all the shards, as
+// for the peer miniblocks
+func (sp *shardProcessor) isValidMiniBlockShardIds(miniBlock *block.MiniBlock) bool {
+	numShards := sp.shardCoordinator.NumberOfShards()
+	isValidSenderShardId := miniBlock.SenderShardID < numShards ||
+		miniBlock.SenderShardID == core.MetachainShardId
+	isValidReceiverShardId := miniBlock.ReceiverShardID < numShards ||
+		miniBlock.ReceiverShardID == core.MetachainShardId ||
+		miniBlock.ReceiverShardID == core.AllShardId
+
+	return isValidSenderShardId && isValidReceiverShardId
+}
+
+// checkBodyTxsAreUsed verifies that all the tx hashes referenced by the body miniblocks correspond to transactions
+// currently used by the transaction coordinator, so the created header does not claim transactions which do not exist
+func (sp *shardProcessor) checkBodyTxsAreUsed(body *block.Body) error {
+	usedTxsByType := make(map[block.Type]map[string]data.TransactionHandler)
+	for _, miniBlock := range body.MiniBlocks {
+		if miniBlock.Type == block.PeerBlock {
+			continue
+		}
+
+		usedTxs, ok := usedTxsByType[miniBlock.Type]
+		if !ok {
+			usedTxs = sp.txCoordinator.GetAllCurrentUsedTxs(miniBlock.Type)
+			usedTxsByType[miniBlock.Type] = usedTxs
+		}
+
+		for _, txHash := range miniBlock.TxHashes {
+			_, found := usedTxs[string(txHash)]
+			if !found {
+				return fmt.Errorf("%w, miniblock type: %s, tx hash: %s",
+					process.ErrHeaderReferencesMissingTxs, miniBlock.Type.String(), logger.DisplayByteSlice(txHash))
+			}
+		}
+	}
+
+	return nil
+}
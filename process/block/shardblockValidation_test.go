@@ -0,0 +1,283 @@
+package block_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/dataRetriever"
+	"github.com/ElrondNetwork/elrond-go/process"
+	blproc "github.com/ElrondNetwork/elrond-go/process/block"
+	"github.com/ElrondNetwork/elrond-go/process/mock"
+	"github.com/ElrondNetwork/elrond-go/testscommon"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardProcessor_CheckHeaderEpochAgainstAttestedMetaBlocks(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.HeaderEpochCheckEnableEpoch = 0
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.SetHdrForCurrentBlock([]byte("meta hash 1"), &block.MetaBlock{Nonce: 1, Epoch: 3}, true)
+	sp.SetHdrForCurrentBlock([]byte("meta hash 2"), &block.MetaBlock{Nonce: 2, Epoch: 4}, true)
+	sp.SetHdrForCurrentBlock([]byte("finality meta hash"), &block.MetaBlock{Nonce: 3, Epoch: 7}, false)
+
+	err := sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 6})
+	assert.True(t, errors.Is(err, process.ErrHeaderEpochMismatch))
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 6, EpochStartMetaHash: []byte("epoch start")})
+	assert.True(t, errors.Is(err, process.ErrHeaderEpochMismatch))
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 5})
+	assert.True(t, errors.Is(err, process.ErrHeaderEpochMismatch))
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 5, EpochStartMetaHash: []byte("epoch start")})
+	assert.Nil(t, err)
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 4})
+	assert.Nil(t, err)
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 3})
+	assert.Nil(t, err)
+}
+
+func TestShardProcessor_CheckHeaderEpochTwoBehindTheAttestedMetaBlocksShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.HeaderEpochCheckEnableEpoch = 0
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.SetHdrForCurrentBlock([]byte("meta hash 1"), &block.MetaBlock{Nonce: 1, Epoch: 3}, true)
+	sp.SetHdrForCurrentBlock([]byte("meta hash 2"), &block.MetaBlock{Nonce: 2, Epoch: 4}, true)
+
+	err := sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 2})
+	assert.True(t, errors.Is(err, process.ErrHeaderEpochMismatch))
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 2, EpochStartMetaHash: []byte("epoch start")})
+	assert.True(t, errors.Is(err, process.ErrHeaderEpochMismatch))
+}
+
+func TestShardProcessor_CheckHeaderEpochAgainstAttestedMetaBlocksBeforeEnableEpochShouldNotCheck(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.HeaderEpochCheckEnableEpoch = 7
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.SetHdrForCurrentBlock([]byte("meta hash 1"), &block.MetaBlock{Nonce: 1, Epoch: 3}, true)
+	sp.SetHdrForCurrentBlock([]byte("meta hash 2"), &block.MetaBlock{Nonce: 2, Epoch: 4}, true)
+
+	err := sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 6})
+	assert.Nil(t, err)
+
+	err = sp.CheckHeaderEpochAgainstAttestedMetaBlocks(&block.Header{Epoch: 7})
+	assert.True(t, errors.Is(err, process.ErrHeaderEpochMismatch))
+}
+
+func TestShardProcessor_CheckMiniBlocksReceiverShardIsSelfWithMiniBlockDestinedElsewhereShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.Marshalizer = &mock.MarshalizerMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+	selfShardID := arguments.ShardCoordinator.SelfId()
+	miniBlockDstMe := &block.MiniBlock{TxHashes: [][]byte{[]byte("tx 1")}, SenderShardID: 1, ReceiverShardID: selfShardID}
+	miniBlockDstOther := &block.MiniBlock{TxHashes: [][]byte{[]byte("tx 2")}, SenderShardID: 1, ReceiverShardID: selfShardID + 2}
+	miniBlockDstMeHash, _ := core.CalculateHash(arguments.Marshalizer, arguments.Hasher, miniBlockDstMe)
+	miniBlockDstOtherHash, _ := core.CalculateHash(arguments.Marshalizer, arguments.Hasher, miniBlockDstOther)
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{miniBlockDstMe, miniBlockDstOther}}
+
+	err := sp.CheckMiniBlocksReceiverShardIsSelf(body, map[string]uint32{string(miniBlockDstMeHash): 1})
+	assert.Nil(t, err)
+
+	miniBlockHashesDstMe := map[string]uint32{
+		string(miniBlockDstMeHash):    1,
+		string(miniBlockDstOtherHash): 1,
+	}
+	err = sp.CheckMiniBlocksReceiverShardIsSelf(body, miniBlockHashesDstMe)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockWrongReceiverShard))
+
+	err = sp.CheckMiniBlocksReceiverShardIsSelf(body, map[string]uint32{"mb missing from body": 1})
+	assert.True(t, errors.Is(err, process.ErrMiniBlockWrongReceiverShard))
+}
+
+func TestShardProcessor_PreSignValidate(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	miniBlock := &block.MiniBlock{
+		SenderShardID:   0,
+		ReceiverShardID: 1,
+		TxHashes:        [][]byte{[]byte("tx hash")},
+	}
+	mbHash, _ := core.CalculateHash(marshalizer, hasher, miniBlock)
+	body := block.Body{MiniBlocks: []*block.MiniBlock{miniBlock}}
+
+	metaBlock := &block.MetaBlock{Nonce: 1}
+	metaHash, _ := core.CalculateHash(marshalizer, hasher, metaBlock)
+	tdp := testscommon.NewPoolsHolderMock()
+	tdp.Headers().AddHeader(metaHash, metaBlock)
+
+	createHeader := func() *block.Header {
+		return &block.Header{
+			RootHash: hasher.Compute("root hash"),
+			TxCount:  1,
+			MiniBlockHeaders: []block.MiniBlockHeader{
+				{
+					Hash:            mbHash,
+					SenderShardID:   0,
+					ReceiverShardID: 1,
+					TxCount:         1,
+				},
+			},
+			MetaBlockHashes: [][]byte{metaHash},
+		}
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.Store = initStore()
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.PreSignValidate(createHeader(), body)
+	assert.Nil(t, err)
+
+	hdr := createHeader()
+	hdr.RootHash = []byte("short root hash")
+	err = sp.PreSignValidate(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrInvalidRootHashLength))
+
+	hdr = createHeader()
+	hdr.TxCount = 2
+	err = sp.PreSignValidate(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrHeaderTxCountMismatch))
+
+	hdr = createHeader()
+	hdr.MiniBlockHeaders[0].ReceiverShardID = 2
+	err = sp.PreSignValidate(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrHeaderBodyMismatch))
+
+	hdr = createHeader()
+	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, []byte("missing meta hash"))
+	err = sp.PreSignValidate(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrMissingHeader))
+}
+
+func TestShardProcessor_ValidateBodyShardIds(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	body := block.Body{MiniBlocks: []*block.MiniBlock{
+		{SenderShardID: 0, ReceiverShardID: 2},
+		{SenderShardID: 0, ReceiverShardID: core.MetachainShardId},
+		{SenderShardID: core.MetachainShardId, ReceiverShardID: 0},
+		{SenderShardID: core.MetachainShardId, ReceiverShardID: core.AllShardId, Type: block.PeerBlock},
+	}}
+	err := sp.ValidateBodyShardIds(body)
+	assert.Nil(t, err)
+
+	invalidSenderBody := block.Body{MiniBlocks: []*block.MiniBlock{{SenderShardID: core.AllShardId, ReceiverShardID: 0}}}
+	err = sp.ValidateBodyShardIds(invalidSenderBody)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
+
+	body.MiniBlocks = append(body.MiniBlocks, &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 3})
+	err = sp.ValidateBodyShardIds(body)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
+}
+
+func TestShardProcessor_ValidateMiniBlockNilMiniBlockShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	err := sp.ValidateMiniBlock(nil)
+	assert.Equal(t, process.ErrNilMiniBlock, err)
+}
+
+func TestShardProcessor_ValidateMiniBlockInvalidShardShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+	mb := &block.MiniBlock{
+		ReceiverShardID: 7,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{[]byte("tx_hash1")},
+	}
+
+	err := sp.ValidateMiniBlock(mb)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
+}
+
+func TestShardProcessor_ValidateMiniBlockAllShardsSenderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+	mb := &block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   core.AllShardId,
+		TxHashes:        [][]byte{[]byte("tx_hash1")},
+	}
+
+	err := sp.ValidateMiniBlock(mb)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
+
+	err = sp.ValidateBodyShardIds(block.Body{MiniBlocks: []*block.MiniBlock{mb}})
+	assert.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
+}
+
+func TestShardProcessor_ValidateMiniBlockWithUnresolvableTxHashShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tdp := testscommon.NewPoolsHolderMock()
+	tdp.Transactions().AddData([]byte("tx_hash1"), &transaction.Transaction{Nonce: 1}, 0, process.ShardCacherIdentifier(0, 1))
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Store = initStore()
+	sp, _ := blproc.NewShardProcessor(arguments)
+	mb := &block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{[]byte("tx_hash1"), []byte("missing tx hash")},
+	}
+
+	err := sp.ValidateMiniBlock(mb)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockReferencesMissingTxs))
+}
+
+func TestShardProcessor_ValidateMiniBlockShouldWork(t *testing.T) {
+	t.Parallel()
+
+	tdp := testscommon.NewPoolsHolderMock()
+	tdp.Transactions().AddData([]byte("tx_hash1"), &transaction.Transaction{Nonce: 1}, 0, process.ShardCacherIdentifier(0, 1))
+	store := initStore()
+	_ = store.Put(dataRetriever.TransactionUnit, []byte("tx_hash2"), []byte("tx"))
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Store = store
+	sp, _ := blproc.NewShardProcessor(arguments)
+	mb := &block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{[]byte("tx_hash1"), []byte("tx_hash2")},
+	}
+
+	err := sp.ValidateMiniBlock(mb)
+	assert.Nil(t, err)
+
+	mb.Type = block.SmartContractResultBlock
+	err = sp.ValidateMiniBlock(mb)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockReferencesMissingTxs))
+}
@@ -2,33 +2,46 @@ package block_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ElrondNetwork/elrond-go/core"
+	atomicCore "github.com/ElrondNetwork/elrond-go/core/atomic"
+	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/block"
 	"github.com/ElrondNetwork/elrond-go/data/blockchain"
+	"github.com/ElrondNetwork/elrond-go/data/rewardTx"
 	"github.com/ElrondNetwork/elrond-go/data/smartContractResult"
 	"github.com/ElrondNetwork/elrond-go/data/state"
+	stateFactory "github.com/ElrondNetwork/elrond-go/data/state/factory"
 	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/data/trie"
+	"github.com/ElrondNetwork/elrond-go/data/typeConverters/uint64ByteSlice"
 	"github.com/ElrondNetwork/elrond-go/dataRetriever"
 	"github.com/ElrondNetwork/elrond-go/hashing"
 	"github.com/ElrondNetwork/elrond-go/marshal"
 	"github.com/ElrondNetwork/elrond-go/process"
 	blproc "github.com/ElrondNetwork/elrond-go/process/block"
+	"github.com/ElrondNetwork/elrond-go/process/block/processedMb"
 	"github.com/ElrondNetwork/elrond-go/process/coordinator"
 	"github.com/ElrondNetwork/elrond-go/process/factory/shard"
 	"github.com/ElrondNetwork/elrond-go/process/mock"
 	"github.com/ElrondNetwork/elrond-go/storage"
+	"github.com/ElrondNetwork/elrond-go/storage/memorydb"
 	"github.com/ElrondNetwork/elrond-go/testscommon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -316,7 +329,52 @@ func TestShardProcessor_ProcessWithDirtyAccountShouldErr(t *testing.T) {
 	err := sp.ProcessBlock(&hdr, body, haveTime)
 
 	assert.NotNil(t, err)
-	assert.Equal(t, process.ErrAccountStateDirty, err)
+	assert.True(t, errors.Is(err, process.ErrAccountStateDirty))
+}
+
+func TestShardProcessor_ProcessBlockWithFirstBlockNotBuiltOnTheGenesisRandSeedShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hdr := &block.Header{
+		Nonce:         1,
+		Round:         1,
+		PrevHash:      []byte(""),
+		PrevRandSeed:  []byte("other rand seed"),
+		Signature:     []byte("signature"),
+		PubKeysBitmap: []byte("00110"),
+		RootHash:      []byte("rootHash"),
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	_ = arguments.BlockChain.SetGenesisHeader(&block.Header{Nonce: 0, RandSeed: []byte("rand seed")})
+	arguments.GenesisRandSeedCheckEnableEpoch = 0
+	sp, _ := blproc.NewShardProcessor(arguments)
+	err := sp.ProcessBlock(hdr, &block.Body{}, haveTime)
+	assert.Equal(t, process.ErrRandSeedDoesNotMatch, err)
+}
+
+func TestShardProcessor_ProcessBlockWithFirstBlockNotBuiltOnTheGenesisRandSeedBeforeEnableEpochShouldNotCheck(t *testing.T) {
+	t.Parallel()
+
+	hdr := &block.Header{
+		Nonce:           1,
+		Round:           1,
+		Epoch:           2,
+		PrevHash:        []byte(""),
+		PrevRandSeed:    []byte("other rand seed"),
+		Signature:       []byte("signature"),
+		PubKeysBitmap:   []byte("00110"),
+		RootHash:        []byte("rootHash"),
+		AccumulatedFees: big.NewInt(0),
+		DeveloperFees:   big.NewInt(0),
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	_ = arguments.BlockChain.SetGenesisHeader(&block.Header{Nonce: 0, RandSeed: []byte("rand seed")})
+	arguments.GenesisRandSeedCheckEnableEpoch = 3
+	sp, _ := blproc.NewShardProcessor(arguments)
+	err := sp.ProcessBlock(hdr, &block.Body{}, haveTime)
+	assert.NotEqual(t, process.ErrRandSeedDoesNotMatch, err)
 }
 
 func TestShardProcessor_ProcessBlockHeaderBodyMismatchShouldErr(t *testing.T) {
@@ -366,7 +424,7 @@ func TestShardProcessor_ProcessBlockHeaderBodyMismatchShouldErr(t *testing.T) {
 
 	// should return err
 	err := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+	assert.True(t, errors.Is(err, process.ErrHeaderBodyMismatch))
 }
 
 func TestShardProcessor_ProcessBlockWithInvalidTransactionShouldErr(t *testing.T) {
@@ -497,7 +555,7 @@ func TestShardProcessor_ProcessBlockWithInvalidTransactionShouldErr(t *testing.T
 
 	// should return err
 	err = sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrReceiptsHashMissmatch, err)
+	assert.True(t, errors.Is(err, process.ErrReceiptsHashMissmatch))
 }
 
 func TestShardProcessor_ProcessWithHeaderNotFirstShouldErr(t *testing.T) {
@@ -576,6 +634,106 @@ func TestShardProcessor_ProcessWithHeaderNotCorrectPrevHashShouldErr(t *testing.
 	assert.Equal(t, process.ErrBlockHashDoesNotMatch, err)
 }
 
+func createArgumentsForPrevHashMismatch(
+	gracePeriod time.Duration,
+	isPrevHeaderInPool *atomic.Value,
+	numRequests *uint32,
+) (blproc.ArgShardProcessor, *block.Header) {
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.PrevHeaderRequestGracePeriod = gracePeriod
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		ProbableHighestNonceCalled: func() uint64 {
+			return 0
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+	}
+	dataPool := initDataPool([]byte("tx_hash1"))
+	headersPool := dataPool.Headers().(*mock.HeadersCacherStub)
+	headersPool.GetHeaderByHashCalled = func(hash []byte) (data.HeaderHandler, error) {
+		if isPrevHeaderInPool.Load().(bool) {
+			return &block.Header{}, nil
+		}
+		return nil, process.ErrMissingHeader
+	}
+	dataPool.HeadersCalled = func() dataRetriever.HeadersPool {
+		return headersPool
+	}
+	arguments.DataPool = dataPool
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestShardHeaderCalled: func(shardID uint32, hash []byte) {
+			atomic.AddUint32(numRequests, 1)
+		},
+	}
+
+	randSeed := []byte("rand seed")
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetCurrentBlockHeader(
+		&block.Header{
+			Nonce:    0,
+			RandSeed: randSeed,
+		},
+	)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	arguments.BlockChain = blkc
+	hdr := &block.Header{
+		Nonce:         1,
+		Round:         1,
+		PubKeysBitmap: []byte("0100101"),
+		PrevHash:      []byte("zzz"),
+		PrevRandSeed:  randSeed,
+		Signature:     []byte("signature"),
+		RootHash:      []byte("root hash"),
+	}
+
+	return arguments, hdr
+}
+
+func TestShardProcessor_ProcessBlockWithNotCorrectPrevHashShouldNotRequestIfPrevHeaderArrivesDuringGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	isPrevHeaderInPool := &atomic.Value{}
+	isPrevHeaderInPool.Store(false)
+	numRequests := uint32(0)
+	gracePeriod := 100 * time.Millisecond
+	arguments, hdr := createArgumentsForPrevHashMismatch(gracePeriod, isPrevHeaderInPool, &numRequests)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, &block.Body{}, haveTime)
+	assert.Equal(t, process.ErrBlockHashDoesNotMatch, err)
+	err = sp.ProcessBlock(hdr, &block.Body{}, haveTime)
+	assert.Equal(t, process.ErrBlockHashDoesNotMatch, err)
+
+	isPrevHeaderInPool.Store(true)
+	time.Sleep(gracePeriod * 3)
+
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&numRequests))
+	_ = sp.Close()
+}
+
+func TestShardProcessor_ProcessBlockWithNotCorrectPrevHashShouldRequestOnceIfPrevHeaderIsStillMissing(t *testing.T) {
+	t.Parallel()
+
+	isPrevHeaderInPool := &atomic.Value{}
+	isPrevHeaderInPool.Store(false)
+	numRequests := uint32(0)
+	gracePeriod := 100 * time.Millisecond
+	arguments, hdr := createArgumentsForPrevHashMismatch(gracePeriod, isPrevHeaderInPool, &numRequests)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, &block.Body{}, haveTime)
+	assert.Equal(t, process.ErrBlockHashDoesNotMatch, err)
+	err = sp.ProcessBlock(hdr, &block.Body{}, haveTime)
+	assert.Equal(t, process.ErrBlockHashDoesNotMatch, err)
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&numRequests))
+
+	time.Sleep(gracePeriod * 3)
+
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&numRequests))
+	_ = sp.Close()
+}
+
 func TestShardProcessor_ProcessBlockWithErrOnProcessBlockTransactionsCallShouldRevertState(t *testing.T) {
 	t.Parallel()
 	tdp := initDataPool([]byte("tx_hash1"))
@@ -732,7 +890,7 @@ func TestShardProcessor_ProcessBlockWithErrOnProcessBlockTransactionsCallShouldR
 
 	// should return err
 	err2 := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrReceiptsHashMissmatch, err2)
+	assert.True(t, errors.Is(err2, process.ErrReceiptsHashMissmatch))
 	assert.True(t, wasCalled)
 }
 
@@ -818,7 +976,7 @@ func TestShardProcessor_ProcessBlockWithErrOnVerifyStateRootCallShouldRevertStat
 	sp, _ := blproc.NewShardProcessor(arguments)
 	// should return err
 	err := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrRootStateDoesNotMatch, err)
+	assert.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
 	assert.True(t, wasCalled)
 }
 
@@ -900,49 +1058,25 @@ func TestShardProcessor_ProcessBlockOnlyIntraShardShouldPass(t *testing.T) {
 	assert.False(t, wasCalled)
 }
 
-func TestShardProcessor_ProcessBlockCrossShardWithoutMetaShouldFail(t *testing.T) {
-	t.Parallel()
-
+func createIntraShardBlockForProcessing(rootHash []byte) (*block.Header, *block.Body) {
 	randSeed := []byte("rand seed")
-	tdp := initDataPool([]byte("tx_hash1"))
-	txHash := []byte("tx_hash1")
-	blkc := blockchain.NewBlockChain()
-	_ = blkc.SetCurrentBlockHeader(
-		&block.Header{
-			Nonce:    0,
-			RandSeed: randSeed,
-		},
-	)
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	rootHash := []byte("rootHash")
-	body := &block.Body{}
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
 	miniblock := block.MiniBlock{
 		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{[]byte("tx_hash1")},
 	}
-	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
-
-	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
-	tx := &transaction.Transaction{}
-	tdp.Transactions().AddData(txHash, tx, tx.Size(), shardCoordinator.CommunicationIdentifier(0))
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{&miniblock}}
 
-	hasher := &mock.HasherStub{}
-	marshalizer := &mock.MarshalizerMock{}
-
-	mbbytes, _ := marshalizer.Marshal(&miniblock)
-	mbHash := hasher.Compute(string(mbbytes))
+	mbbytes, _ := (&mock.MarshalizerMock{}).Marshal(&miniblock)
+	mbHash := (&mock.HasherStub{}).Compute(string(mbbytes))
 	mbHdr := block.MiniBlockHeader{
+		SenderShardID:   0,
 		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+		TxCount:         uint32(len(miniblock.TxHashes)),
+		Hash:            mbHash,
+	}
 
-	hdr := block.Header{
+	hdr := &block.Header{
 		Round:            1,
 		Nonce:            1,
 		PrevHash:         []byte(""),
@@ -951,1273 +1085,6027 @@ func TestShardProcessor_ProcessBlockCrossShardWithoutMetaShouldFail(t *testing.T
 		PubKeysBitmap:    []byte("00110"),
 		ShardID:          0,
 		RootHash:         rootHash,
-		MiniBlockHeaders: mbHdrs,
-	}
-	// set accounts not dirty
-	journalLen := func() int { return 0 }
-	wasCalled := false
-	revertToSnapshot := func(snapshot int) error {
-		wasCalled = true
-		return nil
-	}
-	rootHashCalled := func() ([]byte, error) {
-		return rootHash, nil
+		MiniBlockHeaders: []block.MiniBlockHeader{mbHdr},
+		AccumulatedFees:  big.NewInt(0),
+		DeveloperFees:    big.NewInt(0),
 	}
+
+	return hdr, body
+}
+
+func createArgumentsForIntraShardBlockProcessing(rootHash []byte) blproc.ArgShardProcessor {
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetCurrentBlockHeader(
+		&block.Header{
+			Nonce:    0,
+			RandSeed: []byte("rand seed"),
+		},
+	)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
+	arguments.DataPool = initDataPool([]byte("tx_hash1"))
 	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled:       journalLen,
-		RevertToSnapshotCalled: revertToSnapshot,
-		RootHashCalled:         rootHashCalled,
+		JournalLenCalled: func() int {
+			return 0
+		},
+		RevertToSnapshotCalled: func(snapshot int) error {
+			return nil
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
 	}
 	arguments.BlockChain = blkc
-	sp, _ := blproc.NewShardProcessor(arguments)
 
-	// should return err
-	err := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrCrossShardMBWithoutConfirmationFromMeta, err)
-	assert.False(t, wasCalled)
+	return arguments
 }
 
-func TestShardProcessor_ProcessBlockCrossShardWithMetaShouldPass(t *testing.T) {
-	t.Parallel()
+// setValidationAccounts wraps the user accounts of the given arguments in validation accounts and returns the scratch
+// accounts, which report the given root hash
+func setValidationAccounts(arguments *blproc.ArgShardProcessor, rootHash []byte) *mock.AccountsStub {
+	scratchAccounts := &mock.AccountsStub{
+		RecreateTrieCalled: func(rootHash []byte) error {
+			return nil
+		},
+		RevertToSnapshotCalled: func(snapshot int) error {
+			return nil
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+	}
+	arguments.AccountsDB[state.UserAccountsState], _ = state.NewValidationAccountsDB(
+		arguments.AccountsDB[state.UserAccountsState],
+		scratchAccounts,
+	)
 
-	tdp, blkc, rootHash, body, txHashes, hasher, marshalizer, _, mbHash := initBasicTestData()
-	mbHdr := block.MiniBlockHeader{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+	return scratchAccounts
+}
 
-	randSeed := []byte("rand seed")
-	lastHdr := blkc.GetCurrentBlockHeader()
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
-	blkc.SetCurrentBlockHeaderHash(prevHash)
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+func TestShardProcessor_ProcessBlockShouldNotifyTransactionsProcessed(t *testing.T) {
+	t.Parallel()
 
-	shardMiniBlock := block.MiniBlockHeader{
-		ReceiverShardID: mbHdr.ReceiverShardID,
-		SenderShardID:   mbHdr.SenderShardID,
-		TxCount:         mbHdr.TxCount,
-		Hash:            mbHdr.Hash,
-	}
-	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
-	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
-	shardHeader := block.ShardData{
-		ShardMiniBlockHeaders: shardMiniblockHdrs,
-	}
-	shardHdrs := make([]block.ShardData, 0)
-	shardHdrs = append(shardHdrs, shardHeader)
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
-	meta := block.MetaBlock{
-		Nonce:     1,
-		ShardInfo: shardHdrs,
-		RandSeed:  randSeed,
+	expectedResults := []*process.TransactionExecutionResult{
+		{TxHash: []byte("tx_hash1"), ReturnCode: vmcommon.Ok, GasUsed: 50000},
+		{TxHash: []byte("tx_hash2"), ReturnCode: vmcommon.UserError, GasUsed: 50000},
 	}
-	metaBytes, _ := marshalizer.Marshal(&meta)
-	metaHash := hasher.Compute(string(metaBytes))
-
-	tdp.Headers().AddHeader(metaHash, &meta)
+	var reportedResults []*process.TransactionExecutionResult
 
-	meta = block.MetaBlock{
-		Nonce:        2,
-		ShardInfo:    make([]block.ShardData, 0),
-		PrevRandSeed: randSeed,
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetTransactionsExecutionResultsCalled: func() []*process.TransactionExecutionResult {
+			return expectedResults
+		},
 	}
-	metaBytes, _ = marshalizer.Marshal(&meta)
-	metaHash = hasher.Compute(string(metaBytes))
+	arguments.OnTransactionsProcessed = func(results []*process.TransactionExecutionResult) {
+		reportedResults = results
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	tdp.Headers().AddHeader(metaHash, &meta)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedResults, reportedResults)
+}
 
-	// set accounts not dirty
-	journalLen := func() int { return 0 }
-	wasCalled := false
-	revertToSnapshot := func(snapshot int) error {
-		wasCalled = true
+func TestShardProcessor_ValidateBlockShouldPassAllStagesOnTheScratchAccounts(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	liveRevertCalled := false
+	var recreatedRootHashes [][]byte
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	liveAccounts := arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub)
+	liveAccounts.RevertToSnapshotCalled = func(snapshot int) error {
+		liveRevertCalled = true
 		return nil
 	}
-	rootHashCalled := func() ([]byte, error) {
-		return rootHash, nil
+	scratchAccounts := setValidationAccounts(&arguments, rootHash)
+	scratchAccounts.RecreateTrieCalled = func(rootHash []byte) error {
+		recreatedRootHashes = append(recreatedRootHashes, rootHash)
+		return nil
 	}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled:       journalLen,
-		RevertToSnapshotCalled: revertToSnapshot,
-		RootHashCalled:         rootHashCalled,
+	notifyCalled := false
+	arguments.OnTransactionsProcessed = func(results []*process.TransactionExecutionResult) {
+		notifyCalled = true
 	}
-	arguments.BlockChain = blkc
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	// should return err
-	err := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrCrossShardMBWithoutConfirmationFromMeta, err)
-	assert.False(t, wasCalled)
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.NotNil(t, result)
+	assert.True(t, result.IsValid())
+	assert.False(t, result.HasFailedStage)
+	expectedPassedStages := []process.ProcessBlockStage{
+		process.StageHeaderBodyCorrelation,
+		process.StageMetaHeadersValidity,
+		process.StageCrossShardMiniBlocks,
+		process.StageTxProcessing,
+		process.StageStateRoot,
+	}
+	assert.Equal(t, expectedPassedStages, result.PassedStages)
+	assert.Equal(t, [][]byte{rootHash}, recreatedRootHashes)
+	assert.False(t, liveRevertCalled)
+	assert.False(t, notifyCalled)
 }
 
-func TestShardProcessor_ProcessBlockHaveTimeLessThanZeroShouldErr(t *testing.T) {
+func TestShardProcessor_ValidateBlockShouldReportTheFailedStage(t *testing.T) {
 	t.Parallel()
-	txHash := []byte("tx_hash1")
-	tdp := initDataPool(txHash)
 
-	randSeed := []byte("rand seed")
-	blkc := blockchain.NewBlockChain()
-	_ = blkc.SetCurrentBlockHeader(
-		&block.Header{
-			Nonce:    1,
-			RandSeed: randSeed,
-		},
-	)
 	rootHash := []byte("rootHash")
-	body := &block.Body{}
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   0,
-		TxHashes:        txHashes,
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	expectedErr := errors.New("tx processing error")
+	liveRevertCalled := false
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	liveAccounts := arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub)
+	liveAccounts.RevertToSnapshotCalled = func(snapshot int) error {
+		liveRevertCalled = true
+		return nil
 	}
-	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			return expectedErr
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hasher := &mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.NotNil(t, result)
+	assert.False(t, result.IsValid())
+	assert.True(t, errors.Is(result.Err, expectedErr))
+	assert.True(t, result.HasFailedStage)
+	assert.Equal(t, process.StageTxProcessing, result.FailedStage)
+	expectedPassedStages := []process.ProcessBlockStage{
+		process.StageHeaderBodyCorrelation,
+		process.StageMetaHeadersValidity,
+		process.StageCrossShardMiniBlocks,
+	}
+	assert.Equal(t, expectedPassedStages, result.PassedStages)
+	assert.False(t, liveRevertCalled)
+}
 
-	mbbytes, _ := marshalizer.Marshal(&miniblock)
-	mbHash := hasher.Compute(string(mbbytes))
-	mbHdr := block.MiniBlockHeader{
-		SenderShardID:   0,
-		ReceiverShardID: 0,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+func TestShardProcessor_ValidateBlockWithDirtyStateShouldErrWithoutReverting(t *testing.T) {
+	t.Parallel()
 
-	currHdr := blkc.GetCurrentBlockHeader()
-	preHash, _ := core.CalculateHash(marshalizer, hasher, currHdr)
-	blkc.SetCurrentBlockHeaderHash(preHash)
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	hdr := block.Header{
-		Round:            2,
-		Nonce:            2,
-		PrevHash:         preHash,
-		PrevRandSeed:     randSeed,
-		Signature:        []byte("signature"),
-		PubKeysBitmap:    []byte("00110"),
-		ShardID:          0,
-		RootHash:         rootHash,
-		MiniBlockHeaders: mbHdrs,
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	revertCalled := false
+	recreateCalled := false
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	liveAccounts := arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub)
+	liveAccounts.JournalLenCalled = func() int {
+		return 1
 	}
-	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
-	sp, _ := blproc.NewShardProcessorEmptyWith3shards(tdp, genesisBlocks, blkc)
-	haveTimeLessThanZero := func() time.Duration {
-		return -1 * time.Millisecond
+	liveAccounts.RevertToSnapshotCalled = func(snapshot int) error {
+		revertCalled = true
+		return nil
 	}
+	scratchAccounts := setValidationAccounts(&arguments, rootHash)
+	scratchAccounts.RecreateTrieCalled = func(rootHash []byte) error {
+		recreateCalled = true
+		return nil
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	// should return err
-	err := sp.ProcessBlock(&hdr, body, haveTimeLessThanZero)
-	assert.Equal(t, process.ErrTimeIsOut, err)
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.NotNil(t, result)
+	assert.Equal(t, process.ErrAccountStateDirty, result.Err)
+	assert.False(t, result.HasFailedStage)
+	assert.Equal(t, 0, len(result.PassedStages))
+	assert.False(t, revertCalled)
+	assert.False(t, recreateCalled)
 }
 
-func TestShardProcessor_ProcessBlockWithMissingMetaHdrShouldErr(t *testing.T) {
+func TestShardProcessor_ValidateBlockWithoutValidationAccountsShouldErr(t *testing.T) {
 	t.Parallel()
 
-	tdp, blkc, rootHash, body, txHashes, hasher, marshalizer, _, mbHash := initBasicTestData()
-	mbHdr := block.MiniBlockHeader{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
-	randSeed := []byte("rand seed")
-	lastHdr := blkc.GetCurrentBlockHeader()
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
-	blkc.SetCurrentBlockHeaderHash(prevHash)
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+	numProcessBlockTransaction := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransaction++
+			return nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	shardMiniBlock := block.MiniBlockHeader{
-		ReceiverShardID: mbHdr.ReceiverShardID,
-		SenderShardID:   mbHdr.SenderShardID,
-		TxCount:         mbHdr.TxCount,
-		Hash:            mbHdr.Hash,
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.NotNil(t, result)
+	assert.Equal(t, process.ErrBlockValidationNotSupported, result.Err)
+	assert.Equal(t, 0, numProcessBlockTransaction)
+}
+
+func TestShardProcessor_ValidateBlockShouldNotChangeTheLiveStateWhileValidating(t *testing.T) {
+	t.Parallel()
+
+	address := make([]byte, 32)
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	storageManager, _ := trie.NewTrieStorageManagerWithoutPruning(memorydb.New())
+	liveTrie, _ := trie.NewTrie(storageManager, marshalizer, hasher, 5)
+	liveAccounts, _ := state.NewAccountsDB(liveTrie, hasher, marshalizer, stateFactory.NewAccountCreator())
+	scratchTrie, _ := liveTrie.Recreate(make([]byte, 0))
+	scratchAccounts, _ := state.NewAccountsDB(scratchTrie, hasher, marshalizer, stateFactory.NewAccountCreator())
+	validationAccounts, _ := state.NewValidationAccountsDB(liveAccounts, scratchAccounts)
+
+	addToBalance := func(accounts state.AccountsAdapter, value int64) {
+		acc, _ := accounts.LoadAccount(address)
+		_ = acc.(state.UserAccountHandler).AddToBalance(big.NewInt(value))
+		_ = accounts.SaveAccount(acc)
 	}
-	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
-	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
-	shardHeader := block.ShardData{
-		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	getBalance := func(accounts state.AccountsAdapter) *big.Int {
+		acc, _ := accounts.GetExistingAccount(address)
+		return acc.(state.UserAccountHandler).GetBalance()
 	}
-	shardHdrs := make([]block.ShardData, 0)
-	shardHdrs = append(shardHdrs, shardHeader)
 
-	meta := block.MetaBlock{
-		Nonce:     1,
-		ShardInfo: shardHdrs,
-		RandSeed:  randSeed,
-	}
-	metaBytes, _ := marshalizer.Marshal(&meta)
-	metaHash := hasher.Compute(string(metaBytes))
+	addToBalance(liveAccounts, 10)
+	committedRootHash, err := liveAccounts.Commit()
+	require.Nil(t, err)
 
-	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash)
-	tdp.Headers().AddHeader(metaHash, &meta)
+	// the state root of the validated block is the one after applying its transactions on the committed state
+	expectedTrie, _ := liveTrie.Recreate(committedRootHash)
+	expectedAccounts, _ := state.NewAccountsDB(expectedTrie, hasher, marshalizer, stateFactory.NewAccountCreator())
+	addToBalance(expectedAccounts, 5)
+	blockRootHash, _ := expectedAccounts.RootHash()
 
-	meta = block.MetaBlock{
-		Nonce:        2,
-		ShardInfo:    make([]block.ShardData, 0),
-		PrevRandSeed: randSeed,
+	hdr, body := createIntraShardBlockForProcessing(blockRootHash)
+	arguments := createArgumentsForIntraShardBlockProcessing(blockRootHash)
+	arguments.AccountsDB[state.UserAccountsState] = validationAccounts
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			// the block transactions are processed on the accounts of the blocks processing
+			addToBalance(validationAccounts, 5)
+			assert.Equal(t, big.NewInt(15), getBalance(validationAccounts))
+
+			// while the readers of the live accounts still see the committed state
+			liveRootHash, errRootHash := liveAccounts.RootHash()
+			assert.Nil(t, errRootHash)
+			assert.Equal(t, committedRootHash, liveRootHash)
+			assert.Equal(t, big.NewInt(10), getBalance(liveAccounts))
+			assert.Equal(t, 0, liveAccounts.JournalLen())
+
+			return nil
+		},
 	}
-	metaBytes, _ = marshalizer.Marshal(&meta)
-	metaHash = hasher.Compute(string(metaBytes))
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash)
-	tdp.Headers().AddHeader(metaHash, &meta)
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.NotNil(t, result)
+	assert.True(t, result.IsValid())
 
-	// set accounts not dirty
-	journalLen := func() int { return 0 }
-	revertToSnapshot := func(snapshot int) error {
-		return nil
+	rootHash, _ := validationAccounts.RootHash()
+	assert.Equal(t, committedRootHash, rootHash)
+	assert.Equal(t, big.NewInt(10), getBalance(validationAccounts))
+	assert.Equal(t, 0, validationAccounts.JournalLen())
+}
+
+func TestShardProcessor_ValidateBlockShouldRestoreTheEpochAndClearThePerBlockData(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.Epoch = 5
+
+	epochBeforeValidation := uint32(3)
+	checkedEpochs := make([]uint32, 0)
+	requestHandlerEpochs := make([]uint32, 0)
+	numCreateBlockStarted := 0
+	numRequestBlockTransactions := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.EpochNotifier = &mock.EpochNotifierStub{
+		CurrentEpochCalled: func() uint32 {
+			return epochBeforeValidation
+		},
+		CheckEpochCalled: func(epoch uint32) {
+			checkedEpochs = append(checkedEpochs, epoch)
+		},
 	}
-	rootHashCalled := func() ([]byte, error) {
-		return rootHash, nil
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		SetEpochCalled: func(epoch uint32) {
+			requestHandlerEpochs = append(requestHandlerEpochs, epoch)
+		},
 	}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled:       journalLen,
-		RevertToSnapshotCalled: revertToSnapshot,
-		RootHashCalled:         rootHashCalled,
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateBlockStartedCalled: func() {
+			numCreateBlockStarted++
+		},
+		RequestBlockTransactionsCalled: func(body *block.Body) {
+			numRequestBlockTransactions++
+		},
 	}
-	arguments.BlockChain = blkc
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	// should return err
-	err := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrTimeIsOut, err)
+	_ = sp.ValidateBlock(hdr, body, haveTime)
+	assert.Equal(t, []uint32{hdr.Epoch, epochBeforeValidation}, checkedEpochs)
+	assert.Equal(t, []uint32{hdr.Epoch, epochBeforeValidation}, requestHandlerEpochs)
+	// once when processing the block and once when clearing the per block data
+	assert.Equal(t, 2, numCreateBlockStarted)
+	// the requests for the block data are not undone
+	assert.Equal(t, 1, numRequestBlockTransactions)
 }
 
-func TestShardProcessor_ProcessBlockWithWrongMiniBlockHeaderShouldErr(t *testing.T) {
+func TestShardProcessor_ValidateBlockDuringBlockProcessingShouldErr(t *testing.T) {
 	t.Parallel()
 
-	txHash := []byte("tx_hash1")
-	tdp := initDataPool(txHash)
-	randSeed := []byte("rand seed")
-	blkc := blockchain.NewBlockChain()
-	_ = blkc.SetCurrentBlockHeader(
-		&block.Header{
-			Nonce:    1,
-			RandSeed: randSeed,
-		},
-	)
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
 	rootHash := []byte("rootHash")
-	body := &block.Body{}
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock := block.MiniBlock{
-		ReceiverShardID: 1,
-		SenderShardID:   0,
-		TxHashes:        txHashes,
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	numProcessBlockTransaction := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransaction++
+			return nil
+		},
 	}
-	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hasher := &mock.HasherStub{}
-	marshalizer := &mock.MarshalizerMock{}
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.Nil(t, err)
+	assert.Equal(t, 1, numProcessBlockTransaction)
 
-	mbbytes, _ := marshalizer.Marshal(&miniblock)
-	mbHash := hasher.Compute(string(mbbytes))
-	mbHdr := block.MiniBlockHeader{
-		SenderShardID:   1,
-		ReceiverShardID: 0,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash,
-	}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	assert.Equal(t, process.ErrBlockProcessingInProgress, result.Err)
+	assert.Equal(t, 1, numProcessBlockTransaction)
 
-	lastHdr := blkc.GetCurrentBlockHeader()
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
-	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+	// the processed block was not touched by the rejected validation
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	require.Nil(t, err)
+	assert.Equal(t, 1, numProcessBlockTransaction)
 
-	rootHashCalled := func() ([]byte, error) {
-		return rootHash, nil
-	}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		RootHashCalled: rootHashCalled,
-	}
-	arguments.BlockChain = blkc
+	sp.RevertAccountState(hdr)
+	result = sp.ValidateBlock(hdr, body, haveTime)
+	require.True(t, result.IsValid())
+	assert.Equal(t, 2, numProcessBlockTransaction)
+}
+
+func TestShardProcessor_ValidateBlockAfterCreateBlockShouldErr(t *testing.T) {
+	t.Parallel()
+
+	rootHash := make([]byte, (&mock.HasherMock{}).Size())
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.Hasher = &mock.HasherMock{}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	// should return err
-	err := sp.ProcessBlock(&hdr, body, haveTime)
-	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+	_, _, err := sp.CreateBlock(&block.Header{}, func() bool { return true })
+	require.Nil(t, err)
+
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	assert.Equal(t, process.ErrBlockProcessingInProgress, result.Err)
 }
 
-//------- checkAndRequestIfMetaHeadersMissing
-func TestShardProcessor_CheckAndRequestIfMetaHeadersMissingShouldErr(t *testing.T) {
+func TestShardProcessor_ValidateBlockAfterFailedCreateBlockShouldWork(t *testing.T) {
 	t.Parallel()
 
-	hdrNoncesRequestCalled := int32(0)
-	tdp, blkc, rootHash, body, txHashes, hasher, marshalizer, _, mbHash := initBasicTestData()
-	mbHdr := block.MiniBlockHeader{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash,
-	}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
-	lastHdr := blkc.GetCurrentBlockHeader()
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
-	blkc.SetCurrentBlockHeaderHash(prevHash)
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	randSeed := []byte("rand seed")
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: false})
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+	_, _, err := sp.CreateBlock(&block.Header{}, func() bool { return true })
+	require.Equal(t, process.ErrNoWorkToPropose, err)
 
-	shardMiniBlock := block.MiniBlockHeader{
-		ReceiverShardID: mbHdr.ReceiverShardID,
-		SenderShardID:   mbHdr.SenderShardID,
-		TxCount:         mbHdr.TxCount,
-		Hash:            mbHdr.Hash,
-	}
-	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
-	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
-	shardHeader := block.ShardData{
-		ShardMiniBlockHeaders: shardMiniblockHdrs,
-	}
-	shardHdrs := make([]block.ShardData, 0)
-	shardHdrs = append(shardHdrs, shardHeader)
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	assert.True(t, result.IsValid())
+}
 
-	meta := &block.MetaBlock{
-		Nonce:     1,
-		ShardInfo: shardHdrs,
-		Round:     1,
-		RandSeed:  randSeed,
-	}
-	metaBytes, _ := marshalizer.Marshal(meta)
-	metaHash := hasher.Compute(string(metaBytes))
-
-	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash)
-	tdp.Headers().AddHeader(metaHash, meta)
+func TestShardProcessor_ValidateBlockAfterEarlyFailedProcessBlockShouldWork(t *testing.T) {
+	t.Parallel()
 
-	// set accounts not dirty
-	journalLen := func() int { return 0 }
-	revertToSnapshot := func(snapshot int) error {
-		return nil
-	}
-	rootHashCalled := func() ([]byte, error) {
-		return rootHash, nil
-	}
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	invalidHdr, invalidBody := createIntraShardBlockForProcessing(rootHash)
+	invalidBody.MiniBlocks[0].ReceiverShardID = 7
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.RequestHandler = &mock.RequestHandlerStub{
-		RequestMetaHeaderByNonceCalled: func(nonce uint64) {
-			atomic.AddInt32(&hdrNoncesRequestCalled, 1)
-		},
-	}
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled:       journalLen,
-		RevertToSnapshotCalled: revertToSnapshot,
-		RootHashCalled:         rootHashCalled,
-	}
-	arguments.BlockChain = blkc
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.BodyShardIdsCheckEnableEpoch = 0
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.ProcessBlock(&hdr, body, haveTime)
+	err := sp.ProcessBlock(invalidHdr, invalidBody, haveTime)
+	require.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
 
-	meta = &block.MetaBlock{
-		Nonce:        2,
-		ShardInfo:    make([]block.ShardData, 0),
-		Round:        2,
-		PrevRandSeed: randSeed,
-	}
-	metaBytes, _ = marshalizer.Marshal(meta)
-	metaHash = hasher.Compute(string(metaBytes))
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	assert.True(t, result.IsValid())
 
-	tdp.Headers().AddHeader(metaHash, meta)
+	_, err = sp.ProcessBlockSkippingStateRootCheck(invalidHdr, invalidBody, haveTime)
+	require.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
 
-	sp.CheckAndRequestIfMetaHeadersMissing()
-	time.Sleep(100 * time.Millisecond)
-	assert.Equal(t, int32(1), atomic.LoadInt32(&hdrNoncesRequestCalled))
-	assert.Equal(t, err, process.ErrTimeIsOut)
+	result = sp.ValidateBlock(hdr, body, haveTime)
+	assert.True(t, result.IsValid())
 }
 
-//-------- requestMissingFinalityAttestingHeaders
-func TestShardProcessor_RequestMissingFinalityAttestingHeaders(t *testing.T) {
+func TestShardProcessor_ValidateBlockWithErrShouldNotReportTheProcessingError(t *testing.T) {
 	t.Parallel()
 
-	tdp := testscommon.NewPoolsHolderMock()
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, _ := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+	metaBlock.PrevHash = []byte("wrong prev hash")
+	metaHash, _ := core.CalculateHash(marshalizer, hasher, metaBlock)
+	attestingMetaBlock := &block.MetaBlock{
+		Nonce:     metaBlock.GetNonce() + 1,
+		ShardInfo: make([]block.ShardData, 0),
+		Round:     metaBlock.GetRound() + 1,
+		PrevHash:  metaHash,
+	}
+	attestingMetaHash, _ := core.CalculateHash(marshalizer, hasher, attestingMetaBlock)
+
+	headersPool := testscommon.NewPoolsHolderMock().Headers()
+	headersPool.AddHeader(metaHash, metaBlock)
+	headersPool.AddHeader(attestingMetaHash, attestingMetaBlock)
+
+	notifyCalled := false
+	arguments, hdr, body := createArgumentsAndBlockAttestingMetaBlock(metaHash, headersPool)
+	_ = setValidationAccounts(&arguments, hdr.RootHash)
+	arguments.OnBlockProcessingError = func(header data.HeaderHandler, err error) {
+		notifyCalled = true
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	sp.SetHighestHdrNonceForCurrentBlock(core.MetachainShardId, 1)
-	res := sp.RequestMissingFinalityAttestingHeaders()
-	assert.Equal(t, res > 0, true)
+	mut := &sync.Mutex{}
+	metrics := make(map[string]uint64)
+	_ = sp.SetAppStatusHandler(createMetaHeadersRequestsMetricsStub(mut, metrics))
+
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.True(t, errors.Is(result.Err, process.ErrBlockHashDoesNotMatch))
+	assert.Equal(t, process.StageMetaHeadersValidity, result.FailedStage)
+	assert.False(t, notifyCalled)
+	mut.Lock()
+	assert.Equal(t, uint64(0), metrics[core.MetricMetaHdrConstructionRejections])
+	mut.Unlock()
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.True(t, errors.Is(err, process.ErrBlockHashDoesNotMatch))
+	mut.Lock()
+	assert.Equal(t, uint64(1), metrics[core.MetricMetaHdrConstructionRejections])
+	mut.Unlock()
 }
 
-//--------- verifyIncludedMetaBlocksFinality
-func TestShardProcessor_CheckMetaHeadersValidityAndFinalityShouldPass(t *testing.T) {
+func TestShardProcessor_ValidateBlockFailingOnTxProcessingShouldNotCallOnBlockProcessingError(t *testing.T) {
 	t.Parallel()
 
-	tdp := testscommon.NewPoolsHolderMock()
-	txHash := []byte("tx_hash1")
-	tdp.Transactions().AddData(txHash, &transaction.Transaction{}, 0, process.ShardCacherIdentifier(1, 0))
 	rootHash := []byte("rootHash")
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
-	}
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
-	hasher := &mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
+	expectedErr := errors.New("tx processing error")
+	notifyCalled := false
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	_ = setValidationAccounts(&arguments, rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			return expectedErr
+		},
+	}
+	arguments.OnBlockProcessingError = func(header data.HeaderHandler, err error) {
+		notifyCalled = true
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	mbbytes, _ := marshalizer.Marshal(&miniblock)
-	mbHash := hasher.Compute(string(mbbytes))
-	mbHdr := block.MiniBlockHeader{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+	result := sp.ValidateBlock(hdr, body, haveTime)
+	require.True(t, errors.Is(result.Err, expectedErr))
+	assert.False(t, notifyCalled)
+}
 
-	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
-	lastHdr := genesisBlocks[0]
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
-	randSeed := []byte("rand seed")
-	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+func TestShardProcessor_ProcessBlockWithErrShouldCallOnBlockProcessingErrorBeforeReverting(t *testing.T) {
+	t.Parallel()
 
-	shardMiniBlock := block.MiniBlockHeader{
-		ReceiverShardID: mbHdr.ReceiverShardID,
-		SenderShardID:   mbHdr.SenderShardID,
-		TxCount:         mbHdr.TxCount,
-		Hash:            mbHdr.Hash,
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	expectedErr := errors.New("tx processing error")
+	calls := make([]string, 0)
+	var notifiedHeader data.HeaderHandler
+	var notifiedErr error
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	accounts := arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub)
+	accounts.RevertToSnapshotCalled = func(snapshot int) error {
+		calls = append(calls, "revert")
+		return nil
 	}
-	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
-	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
-	shardHeader := block.ShardData{
-		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			return expectedErr
+		},
 	}
-	shardHdrs := make([]block.ShardData, 0)
-	shardHdrs = append(shardHdrs, shardHeader)
-
-	prevMeta := genesisBlocks[core.MetachainShardId]
-	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMeta)
-	meta1 := &block.MetaBlock{
-		Nonce:        1,
-		ShardInfo:    shardHdrs,
-		Round:        1,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMeta.GetRandSeed(),
+	arguments.OnBlockProcessingError = func(header data.HeaderHandler, err error) {
+		calls = append(calls, "notify")
+		notifiedHeader = header
+		notifiedErr = err
 	}
-	metaBytes, _ := marshalizer.Marshal(meta1)
-	metaHash1 := hasher.Compute(string(metaBytes))
-	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash1)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	tdp.Headers().AddHeader(metaHash1, meta1)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, expectedErr))
+	require.True(t, len(calls) > 1)
+	assert.Equal(t, "notify", calls[0])
+	assert.Equal(t, "revert", calls[1])
+	assert.True(t, notifiedHeader == hdr)
+	assert.Equal(t, err, notifiedErr)
+}
 
-	prevHash, _ = core.CalculateHash(marshalizer, hasher, meta1)
-	meta2 := &block.MetaBlock{
-		Nonce:     2,
-		ShardInfo: make([]block.ShardData, 0),
-		Round:     2,
-		PrevHash:  prevHash,
-	}
-	metaBytes, _ = marshalizer.Marshal(meta2)
-	metaHash2 := hasher.Compute(string(metaBytes))
+func TestShardProcessor_ProcessBlockWithoutErrShouldNotCallOnBlockProcessingError(t *testing.T) {
+	t.Parallel()
 
-	tdp.Headers().AddHeader(metaHash2, meta2)
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	sp, _ := blproc.NewShardProcessor(arguments)
-	hdr.Round = 4
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
-	sp.SetHdrForCurrentBlock(metaHash1, meta1, true)
-	sp.SetHdrForCurrentBlock(metaHash2, meta2, false)
+	notifyCalled := false
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.OnBlockProcessingError = func(header data.HeaderHandler, err error) {
+		notifyCalled = true
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.CheckMetaHeadersValidityAndFinality()
+	err := sp.ProcessBlock(hdr, body, haveTime)
 	assert.Nil(t, err)
+	assert.False(t, notifyCalled)
 }
 
-func TestShardProcessor_CheckMetaHeadersValidityAndFinalityShouldReturnNilWhenNoMetaBlocksAreUsed(t *testing.T) {
+func TestShardProcessor_ProcessBlockShouldSkipCreatedBlockTransactionsVerificationOnlyForSelfProposedBody(t *testing.T) {
 	t.Parallel()
 
-	tdp := testscommon.NewPoolsHolderMock()
-	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
-	sp, _ := blproc.NewShardProcessorEmptyWith3shards(
-		tdp,
-		genesisBlocks,
-		&mock.BlockChainMock{
-			GetGenesisHeaderCalled: func() data.HeaderHandler {
-				return &block.Header{Nonce: 0}
+	rootHash := []byte("rootHash")
+	hasher := &mock.HasherMock{}
+	for _, isSelfProposed := range []bool{false, true} {
+		hdr, body := createIntraShardBlockForProcessing(rootHash)
+		hdr.MiniBlockHeaders[0].Hash, _ = core.CalculateHash(&mock.MarshalizerMock{}, hasher, body.MiniBlocks[0])
+
+		numVerifications := 0
+		arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+		arguments.Hasher = hasher
+		arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+			VerifyCreatedBlockTransactionsCalled: func(hdr data.HeaderHandler, body *block.Body) error {
+				numVerifications++
+				return nil
 			},
-		},
-	)
+		}
+		sp, _ := blproc.NewShardProcessor(arguments)
+		if isSelfProposed {
+			// the self proposed body is recognized by its hash, so a copy of it is used
+			_, selfProposedBody := createIntraShardBlockForProcessing(rootHash)
+			sp.SetSelfProposedBody(selfProposedBody, hdr.GetRound())
+		}
 
-	err := sp.CheckMetaHeadersValidityAndFinality()
-	assert.Nil(t, err)
+		err := sp.ProcessBlock(hdr, body, haveTime)
+		require.Nil(t, err)
+
+		expectedNumVerifications := 1
+		if isSelfProposed {
+			expectedNumVerifications = 0
+		}
+		assert.Equal(t, expectedNumVerifications, numVerifications, "self proposed: %v", isSelfProposed)
+	}
 }
 
-//------- CommitBlock
+func TestShardProcessor_IsSelfProposedBodyShouldCompareTheBodyHashAndTheRound(t *testing.T) {
+	t.Parallel()
 
-func TestShardProcessor_CommitBlockMarshalizerFailForHeaderShouldErr(t *testing.T) {
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr, body := createIntraShardBlockForProcessing([]byte("rootHash"))
+	sp.SetSelfProposedBody(body, hdr.GetRound())
+
+	_, sameBody := createIntraShardBlockForProcessing([]byte("rootHash"))
+	assert.True(t, sp.IsSelfProposedBody(hdr, sameBody))
+
+	otherBody := &block.Body{MiniBlocks: []*block.MiniBlock{{TxHashes: [][]byte{[]byte("other tx hash")}}}}
+	assert.False(t, sp.IsSelfProposedBody(hdr, otherBody))
+	assert.False(t, sp.IsSelfProposedBody(&block.Header{Round: hdr.GetRound() + 1}, body))
+}
+
+func TestShardProcessor_RevertAccountStateShouldForgetTheSelfProposedBody(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	rootHash := []byte("root hash to be tested")
-	accounts := &mock.AccountsStub{
-		RootHashCalled: func() ([]byte, error) {
-			return rootHash, nil
-		},
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
 		RevertToSnapshotCalled: func(snapshot int) error {
 			return nil
 		},
 	}
-	errMarshalizer := errors.New("failure")
-	hdr := &block.Header{
-		Nonce:         1,
-		Round:         1,
-		PubKeysBitmap: []byte("0100101"),
-		Signature:     []byte("signature"),
-		RootHash:      rootHash,
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr, body := createIntraShardBlockForProcessing([]byte("rootHash"))
+	sp.SetSelfProposedBody(body, hdr.GetRound())
+	sp.RevertAccountState(hdr)
+
+	assert.False(t, sp.IsSelfProposedBody(hdr, body))
+}
+
+func TestShardProcessor_RestoreBlockIntoPoolsShouldForgetTheSelfProposedBody(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr, body := createIntraShardBlockForProcessing([]byte("rootHash"))
+	sp.SetSelfProposedBody(body, hdr.GetRound())
+	require.True(t, sp.IsSelfProposedBody(hdr, body))
+
+	err := sp.RestoreBlockIntoPools(hdr, body)
+	require.Nil(t, err)
+	assert.False(t, sp.IsSelfProposedBody(hdr, body))
+}
+
+func TestShardProcessor_ProcessBlockWithErrorShouldForgetTheSelfProposedBody(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hasher := &mock.HasherMock{}
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.MiniBlockHeaders[0].Hash, _ = core.CalculateHash(&mock.MarshalizerMock{}, hasher, body.MiniBlocks[0])
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.Hasher = hasher
+	arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub).RootHashCalled = func() ([]byte, error) {
+		return []byte("other root hash"), nil
 	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.SetSelfProposedBody(body, hdr.GetRound())
+	require.True(t, sp.IsSelfProposedBody(hdr, body))
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
+	assert.False(t, sp.IsSelfProposedBody(hdr, body))
+}
+
+func TestShardProcessor_CommitBlockShouldForgetTheSelfProposedBody(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
 	body := &block.Body{}
-	marshalizer := &mock.MarshalizerStub{
-		MarshalCalled: func(obj interface{}) (i []byte, e error) {
-			if reflect.DeepEqual(obj, hdr) {
-				return nil, errMarshalizer
-			}
 
-			return []byte("obj"), nil
-		},
-	}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Marshalizer = marshalizer
-	arguments.AccountsDB[state.UserAccountsState] = accounts
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.Hasher = &mock.HasherMock{}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
+	sp.SetSelfProposedBody(body, hdr.GetRound())
+	require.True(t, sp.IsSelfProposedBody(hdr, body))
+
 	err := sp.CommitBlock(hdr, body)
-	assert.Equal(t, errMarshalizer, err)
+	require.Nil(t, err)
+	assert.False(t, sp.IsSelfProposedBody(hdr, body))
 }
 
-func TestShardProcessor_CommitBlockStorageFailsForHeaderShouldErr(t *testing.T) {
+func TestShardProcessor_CurrentUsedTxCountsAfterProcessingBlock(t *testing.T) {
 	t.Parallel()
 
-	tdp := initDataPool([]byte("tx_hash1"))
-	errPersister := errors.New("failure")
-	putCalledNr := uint32(0)
-	rootHash := []byte("root hash to be tested")
-	marshalizer := &mock.MarshalizerMock{}
-	accounts := &mock.AccountsStub{
-		CommitCalled: func() ([]byte, error) {
-			return nil, nil
-		},
-		RootHashCalled: func() ([]byte, error) {
-			return rootHash, nil
-		},
-		RevertToSnapshotCalled: func(snapshot int) error {
-			return nil
-		},
-	}
-	hdr := &block.Header{
-		Nonce:         1,
-		Round:         1,
-		PubKeysBitmap: []byte("0100101"),
-		Signature:     []byte("signature"),
-		RootHash:      rootHash,
-	}
-	body := &block.Body{}
-	wg := sync.WaitGroup{}
-	wg.Add(1)
-	hdrUnit := &mock.StorerStub{
-		GetCalled: func(key []byte) (i []byte, e error) {
-			return marshalizer.Marshal(&block.Header{})
-		},
-		PutCalled: func(key, data []byte) error {
-			atomic.AddUint32(&putCalledNr, 1)
-			wg.Done()
-			return errPersister
-		},
-		HasCalled: func(key []byte) error {
-			return nil
-		},
-	}
-	store := initStore()
-	store.AddStorer(dataRetriever.BlockHeaderUnit, hdrUnit)
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Store = store
-	arguments.AccountsDB[state.UserAccountsState] = accounts
-	arguments.ForkDetector = &mock.ForkDetectorMock{
-		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+	usedTxs := make(map[block.Type]map[string]data.TransactionHandler)
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			usedTxs[block.TxBlock] = map[string]data.TransactionHandler{
+				"tx_hash1": &transaction.Transaction{Nonce: 1},
+				"tx_hash2": &transaction.Transaction{Nonce: 2},
+			}
+			usedTxs[block.SmartContractResultBlock] = map[string]data.TransactionHandler{
+				"scr_hash1": &smartContractResult.SmartContractResult{Nonce: 1},
+			}
+			usedTxs[block.RewardsBlock] = map[string]data.TransactionHandler{
+				"reward_hash1": &rewardTx.RewardTx{Round: 1},
+				"reward_hash2": &rewardTx.RewardTx{Round: 1},
+				"reward_hash3": &rewardTx.RewardTx{Round: 1},
+			}
 			return nil
 		},
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return 0
-		},
-		GetHighestFinalBlockHashCalled: func() []byte {
-			return nil
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			return usedTxs[blockType]
 		},
 	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	blockTrackerMock := mock.NewBlockTrackerMock(mock.NewOneShardCoordinatorMock(), createGenesisBlocks(mock.NewOneShardCoordinatorMock()))
-	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
-		return &block.MetaBlock{}, []byte("hash"), nil
-	}
-	arguments.BlockTracker = blockTrackerMock
-	blkc := blockchain.NewBlockChain()
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	_ = blkc.SetAppStatusHandler(&mock.AppStatusHandlerStub{
-		SetUInt64ValueHandler: func(key string, value uint64) {},
-	})
-	arguments.BlockChain = blkc
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.Nil(t, err)
+
+	usedTxCounts := sp.CurrentUsedTxCounts()
+	assert.Equal(t, 2, usedTxCounts[block.TxBlock])
+	assert.Equal(t, 1, usedTxCounts[block.SmartContractResultBlock])
+	assert.Equal(t, 3, usedTxCounts[block.RewardsBlock])
+	assert.Equal(t, 0, usedTxCounts[block.InvalidBlock])
+	assert.Equal(t, 0, usedTxCounts[block.ReceiptBlock])
+}
 
+func TestShardProcessor_ProcessBlockFailingShouldNotNotifyTransactionsProcessed(t *testing.T) {
+	t.Parallel()
+
+	hdr, body := createIntraShardBlockForProcessing([]byte("rootHash"))
+
+	wasCalled := false
+	arguments := createArgumentsForIntraShardBlockProcessing([]byte("rootHashX"))
+	arguments.OnTransactionsProcessed = func(results []*process.TransactionExecutionResult) {
+		wasCalled = true
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.CommitBlock(hdr, body)
-	wg.Wait()
-	assert.True(t, atomic.LoadUint32(&putCalledNr) > 0)
-	assert.Nil(t, err)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
+	assert.False(t, wasCalled)
 }
 
-func TestShardProcessor_CommitBlockStorageFailsForBodyShouldWork(t *testing.T) {
+func TestShardProcessor_ProcessBlockSkippingStateRootCheckShouldWorkWithDifferentRootHash(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	putCalledNr := uint32(0)
-	errPersister := errors.New("failure")
-	rootHash := []byte("root hash to be tested")
-	accounts := &mock.AccountsStub{
-		RootHashCalled: func() ([]byte, error) {
-			return rootHash, nil
-		},
-		CommitCalled: func() (i []byte, e error) {
-			return nil, nil
-		},
-		RevertToSnapshotCalled: func(snapshot int) error {
-			return nil
-		},
-	}
-	hdr := &block.Header{
-		Nonce:         1,
-		Round:         1,
-		PubKeysBitmap: []byte("0100101"),
-		Signature:     []byte("signature"),
-		RootHash:      rootHash,
-	}
-	mb := block.MiniBlock{}
-	body := &block.Body{}
-	body.MiniBlocks = append(body.MiniBlocks, &mb)
 
-	wg := sync.WaitGroup{}
-	wg.Add(1)
-	miniBlockUnit := &mock.StorerStub{
-		PutCalled: func(key, data []byte) error {
-			atomic.AddUint32(&putCalledNr, 1)
-			wg.Done()
-			return errPersister
-		},
-	}
-	store := initStore()
-	store.AddStorer(dataRetriever.MiniBlockUnit, miniBlockUnit)
+	hdr, body := createIntraShardBlockForProcessing([]byte("rootHash"))
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Store = store
-	arguments.AccountsDB[state.UserAccountsState] = accounts
-	arguments.ForkDetector = &mock.ForkDetectorMock{
-		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
-			return nil
-		},
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return 0
-		},
-		GetHighestFinalBlockHashCalled: func() []byte {
-			return nil
-		},
-	}
-	blockTrackerMock := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
-	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
-		return &block.MetaBlock{}, []byte("hash"), nil
-	}
-	arguments.BlockTracker = blockTrackerMock
-	blkc := blockchain.NewBlockChain()
-	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
-	_ = blkc.SetAppStatusHandler(&mock.AppStatusHandlerStub{
-		SetUInt64ValueHandler: func(key string, value uint64) {},
-	})
-	arguments.BlockChain = blkc
-	sp, err := blproc.NewShardProcessor(arguments)
-	assert.Nil(t, err)
+	arguments := createArgumentsForIntraShardBlockProcessing([]byte("rootHashX"))
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err = sp.CommitBlock(hdr, body)
-	wg.Wait()
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
+
+	rootHashVerified, err := sp.ProcessBlockSkippingStateRootCheck(hdr, body, haveTime)
 	assert.Nil(t, err)
-	assert.True(t, atomic.LoadUint32(&putCalledNr) > 0)
+	assert.False(t, rootHashVerified)
 }
 
-func TestShardProcessor_CommitBlockOkValsShouldWork(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithTxCountMismatchShouldErr(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	txHash := []byte("tx_hash1")
-
-	rootHash := []byte("root hash")
-	hdrHash := []byte("header hash")
-	randSeed := []byte("rand seed")
 
-	prevHdr := &block.Header{
-		Nonce:         0,
-		Round:         0,
-		PubKeysBitmap: rootHash,
-		PrevHash:      hdrHash,
-		Signature:     rootHash,
-		RootHash:      rootHash,
-		RandSeed:      randSeed,
-	}
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.TxCount = 5
 
-	hdr := &block.Header{
-		Nonce:           1,
-		Round:           1,
-		PubKeysBitmap:   rootHash,
-		PrevHash:        hdrHash,
-		Signature:       rootHash,
-		RootHash:        rootHash,
-		PrevRandSeed:    randSeed,
-		AccumulatedFees: big.NewInt(0),
-		DeveloperFees:   big.NewInt(0),
-	}
-	mb := block.MiniBlock{
-		TxHashes: [][]byte{txHash},
-	}
-	body := &block.Body{MiniBlocks: []*block.MiniBlock{&mb}}
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.HeaderTxCountCheckEnableEpoch = 0
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	mbHdr := block.MiniBlockHeader{
-		TxCount: uint32(len(mb.TxHashes)),
-		Hash:    hdrHash,
-	}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
-	hdr.MiniBlockHeaders = mbHdrs
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrHeaderTxCountMismatch))
+}
 
-	accounts := &mock.AccountsStub{
-		CommitCalled: func() (i []byte, e error) {
-			return rootHash, nil
-		},
-		RootHashCalled: func() ([]byte, error) {
-			return rootHash, nil
-		},
-	}
-	forkDetectorAddCalled := false
-	fd := &mock.ForkDetectorMock{
-		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
-			if header == hdr {
-				forkDetectorAddCalled = true
-				return nil
-			}
+func TestShardProcessor_ProcessBlockWithTxCountMismatchBeforeEnableEpochShouldWork(t *testing.T) {
+	t.Parallel()
 
-			return errors.New("should have not got here")
-		},
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return 0
-		},
-		GetHighestFinalBlockHashCalled: func() []byte {
-			return nil
-		},
-	}
-	hasher := &mock.HasherStub{}
-	hasher.ComputeCalled = func(s string) []byte {
-		return hdrHash
-	}
-	store := initStore()
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.TxCount = 5
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Store = store
-	arguments.Hasher = hasher
-	arguments.AccountsDB[state.UserAccountsState] = accounts
-	arguments.ForkDetector = fd
-	blockTrackerMock := mock.NewBlockTrackerMock(mock.NewOneShardCoordinatorMock(), createGenesisBlocks(mock.NewOneShardCoordinatorMock()))
-	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
-		return &block.MetaBlock{}, []byte("hash"), nil
-	}
-	arguments.BlockTracker = blockTrackerMock
-	blkc := createTestBlockchain()
-	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
-		return prevHdr
-	}
-	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
-		return hdrHash
-	}
-	arguments.BlockChain = blkc
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.HeaderTxCountCheckEnableEpoch = hdr.Epoch + 1
 	sp, _ := blproc.NewShardProcessor(arguments)
 
 	err := sp.ProcessBlock(hdr, body, haveTime)
 	assert.Nil(t, err)
-	err = sp.CommitBlock(hdr, body)
-	assert.Nil(t, err)
-	assert.True(t, forkDetectorAddCalled)
-	assert.Equal(t, hdrHash, blkc.GetCurrentBlockHeaderHash())
-	//this should sleep as there is an async call to display current hdr and block in CommitBlock
-	time.Sleep(time.Second)
 }
 
-func TestShardProcessor_CommitBlockCallsIndexerMethods(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithTxCountMatchShouldWork(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	txHash := []byte("tx_hash1")
 
-	rootHash := []byte("root hash")
-	hdrHash := []byte("header hash")
-	randSeed := []byte("rand seed")
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.TxCount = 1
 
-	prevHdr := &block.Header{
-		Nonce:         0,
-		Round:         0,
-		PubKeysBitmap: rootHash,
-		PrevHash:      hdrHash,
-		Signature:     rootHash,
-		RootHash:      rootHash,
-		RandSeed:      randSeed,
-	}
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.HeaderTxCountCheckEnableEpoch = 0
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hdr := &block.Header{
-		Nonce:           1,
-		Round:           1,
-		PubKeysBitmap:   rootHash,
-		PrevHash:        hdrHash,
-		Signature:       rootHash,
-		RootHash:        rootHash,
-		PrevRandSeed:    randSeed,
-		AccumulatedFees: big.NewInt(0),
-		DeveloperFees:   big.NewInt(0),
-	}
-	mb := block.MiniBlock{
-		TxHashes: [][]byte{txHash},
-	}
-	body := &block.Body{MiniBlocks: []*block.MiniBlock{&mb}}
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+}
 
-	mbHdr := block.MiniBlockHeader{
-		TxCount: uint32(len(mb.TxHashes)),
-		Hash:    hdrHash,
-	}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
-	hdr.MiniBlockHeaders = mbHdrs
+func TestShardProcessor_ProcessBlockWithTooManyAttestedMetaBlocksShouldErr(t *testing.T) {
+	t.Parallel()
 
-	accounts := &mock.AccountsStub{
-		CommitCalled: func() (i []byte, e error) {
-			return rootHash, nil
-		},
-		RootHashCalled: func() ([]byte, error) {
-			return rootHash, nil
-		},
-	}
-	fd := &mock.ForkDetectorMock{
-		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
-			return nil
-		},
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return 0
-		},
-		GetHighestFinalBlockHashCalled: func() []byte {
-			return nil
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.MetaBlockHashes = [][]byte{[]byte("meta hash 1"), []byte("meta hash 2"), []byte("meta hash 3")}
+
+	requestedMetaHeaders := uint32(0)
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.MaxAttestedMetaBlocksPerBlock = 2
+	arguments.MetaBlocksCountCheckEnableEpoch = 0
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestMetaHeaderCalled: func(hash []byte) {
+			atomic.AddUint32(&requestedMetaHeaders, 1)
 		},
 	}
-	hasher := &mock.HasherStub{}
-	hasher.ComputeCalled = func(s string) []byte {
-		return hdrHash
-	}
-	store := initStore()
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	var saveBlockCalled map[string]data.TransactionHandler
-	saveBlockCalledMutex := sync.Mutex{}
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrTooManyAttestedMetaBlocks))
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.Indexer = &mock.IndexerMock{
-		SaveBlockCalled: func(body data.BodyHandler, header data.HeaderHandler, txPool map[string]data.TransactionHandler) {
-			saveBlockCalledMutex.Lock()
-			saveBlockCalled = txPool
-			saveBlockCalledMutex.Unlock()
-		},
-	}
-	arguments.DataPool = tdp
-	arguments.Store = store
-	arguments.Hasher = hasher
-	arguments.AccountsDB[state.UserAccountsState] = accounts
-	arguments.ForkDetector = fd
-	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
-		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
-			switch blockType {
-			case block.TxBlock:
-				return map[string]data.TransactionHandler{
-					"tx_1": &transaction.Transaction{Nonce: 1},
-					"tx_2": &transaction.Transaction{Nonce: 2},
-				}
-			case block.SmartContractResultBlock:
-				return map[string]data.TransactionHandler{
-					"utx_1": &smartContractResult.SmartContractResult{Nonce: 1},
-					"utx_2": &smartContractResult.SmartContractResult{Nonce: 2},
-				}
-			default:
-				return nil
-			}
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&requestedMetaHeaders))
+}
+
+func TestShardProcessor_ProcessBlockWithTooManyAttestedMetaBlocksBeforeEnableEpochShouldNotErr(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.MetaBlockHashes = [][]byte{[]byte("meta hash 1"), []byte("meta hash 2"), []byte("meta hash 3")}
+
+	requestedMetaHeaders := uint32(0)
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.MaxAttestedMetaBlocksPerBlock = 2
+	arguments.MetaBlocksCountCheckEnableEpoch = hdr.Epoch + 1
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestMetaHeaderCalled: func(hash []byte) {
+			atomic.AddUint32(&requestedMetaHeaders, 1)
 		},
 	}
-	blockTrackerMock := mock.NewBlockTrackerMock(mock.NewOneShardCoordinatorMock(), createGenesisBlocks(mock.NewOneShardCoordinatorMock()))
-	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
-		return &block.MetaBlock{}, []byte("hash"), nil
-	}
-	arguments.BlockTracker = blockTrackerMock
-	blkc := createTestBlockchain()
-	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
-		return prevHdr
-	}
-	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
-		return hdrHash
-	}
-	arguments.BlockChain = blkc
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.ProcessBlock(hdr, body, haveTime)
-	assert.Nil(t, err)
-	err = sp.CommitBlock(hdr, body)
-	assert.Nil(t, err)
+	err := sp.ProcessBlock(hdr, body, func() time.Duration { return 0 })
+	assert.False(t, errors.Is(err, process.ErrTooManyAttestedMetaBlocks))
 
-	// Wait for the index block go routine to start
-	time.Sleep(time.Second * 2)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint32(3), atomic.LoadUint32(&requestedMetaHeaders))
+}
 
-	saveBlockCalledMutex.Lock()
-	wasCalled := saveBlockCalled
-	saveBlockCalledMutex.Unlock()
+func TestShardProcessor_ProcessBlockWithRoundFarInTheFutureShouldErr(t *testing.T) {
+	t.Parallel()
 
-	assert.Equal(t, 4, len(wasCalled))
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	roundDuration := 6 * time.Second
+	hdr.Round = uint64(3 * time.Hour / roundDuration)
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.Rounder = &mock.RounderMock{RoundTimeDuration: roundDuration}
+	arguments.GenesisTime = time.Now()
+	arguments.MaxRoundClockSkew = time.Minute
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrHeaderRoundClockSkew))
 }
 
-func TestShardProcessor_CreateTxBlockBodyWithDirtyAccStateShouldReturnEmptyBody(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithRoundInThePastShouldNotCheckClockSkew(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	journalLen := func() int { return 3 }
-	revToSnapshot := func(snapshot int) error { return nil }
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled:       journalLen,
-		RevertToSnapshotCalled: revToSnapshot,
-	}
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
 
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.Rounder = &mock.RounderMock{RoundTimeDuration: 6 * time.Second}
+	arguments.GenesisTime = time.Now().Add(-3 * time.Hour)
+	arguments.MaxRoundClockSkew = time.Minute
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	bl, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	err := sp.ProcessBlock(hdr, body, haveTime)
 	assert.Nil(t, err)
-	assert.Equal(t, &block.Body{}, bl)
 }
 
-func TestShardProcessor_CreateTxBlockBodyWithNoTimeShouldReturnEmptyBody(t *testing.T) {
+func TestShardProcessor_NextExpectedRound(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	journalLen := func() int { return 0 }
-	rootHashfunc := func() ([]byte, error) {
-		return []byte("roothash"), nil
-	}
-	revToSnapshot := func(snapshot int) error { return nil }
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled:       journalLen,
-		RootHashCalled:         rootHashfunc,
-		RevertToSnapshotCalled: revToSnapshot,
-	}
 
+	genesisTime := time.Unix(1600000000, 0)
+	roundDuration := 6 * time.Second
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Rounder = &mock.RounderMock{RoundTimeDuration: roundDuration}
+	arguments.GenesisTime = genesisTime
+	blkc := createTestBlockchain()
+	arguments.BlockChain = blkc
 	sp, _ := blproc.NewShardProcessor(arguments)
-	haveTimeTrue := func() bool {
-		return false
+
+	// 100 rounds and a half passed since genesis, so the current round is 100
+	currentTime := genesisTime.Add(100*roundDuration + roundDuration/2)
+	assert.Equal(t, uint64(101), sp.NextExpectedRound(currentTime))
+	assert.Equal(t, uint64(1), sp.NextExpectedRound(genesisTime.Add(-time.Minute)))
+
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return &block.Header{Round: 200}
 	}
-	bl, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, haveTimeTrue)
-	assert.Nil(t, err)
-	assert.Equal(t, &block.Body{}, bl)
+	assert.Equal(t, uint64(201), sp.NextExpectedRound(currentTime))
 }
 
-func TestShardProcessor_CreateTxBlockBodyOK(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithNotEnoughTimeForTxProcessingShouldErr(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	journalLen := func() int { return 0 }
-	rootHashfunc := func() ([]byte, error) {
-		return []byte("roothash"), nil
-	}
-	haveTimeTrue := func() bool {
-		return true
-	}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		JournalLenCalled: journalLen,
-		RootHashCalled:   rootHashfunc,
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	processBlockTransactionCalled := false
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.MinTimeForTxProcessing = time.Second
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			processBlockTransactionCalled = true
+			return nil
+		},
 	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, func() time.Duration {
+		return time.Millisecond
+	})
+	assert.True(t, errors.Is(err, process.ErrTimeIsOut))
+	assert.False(t, processBlockTransactionCalled)
+}
+
+func TestShardProcessor_ProcessBlockWithEnoughTimeForTxProcessingShouldWork(t *testing.T) {
+	t.Parallel()
 
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.MinTimeForTxProcessing = time.Millisecond
 	sp, _ := blproc.NewShardProcessor(arguments)
-	blk, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, haveTimeTrue)
-	assert.NotNil(t, blk)
+
+	err := sp.ProcessBlock(hdr, body, func() time.Duration {
+		return time.Second
+	})
 	assert.Nil(t, err)
 }
 
-//------- ComputeNewNoncePrevHash
-
-func TestNode_ComputeNewNoncePrevHashShouldWork(t *testing.T) {
+func TestShardProcessor_ProcessBlockShouldReportTheFailingStage(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	marshalizer := &mock.MarshalizerStub{}
-	hasher := &mock.HasherStub{}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.Store = initStore()
-	arguments.DataPool = tdp
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
 
-	be, _ := blproc.NewShardProcessor(arguments)
-	hdr, txBlock := createTestHdrTxBlockBody()
-	marshalizer.MarshalCalled = func(obj interface{}) (bytes []byte, e error) {
-		if hdr == obj {
-			return []byte("hdrHeaderMarshalized"), nil
-		}
-		if reflect.DeepEqual(txBlock, obj) {
-			return []byte("txBlockBodyMarshalized"), nil
-		}
-		return nil, nil
+	rootHash := []byte("rootHash")
+
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	body.MiniBlocks = append(body.MiniBlocks, &block.MiniBlock{SenderShardID: 1})
+	sp, _ := blproc.NewShardProcessor(createArgumentsForIntraShardBlockProcessing(rootHash))
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	processBlockError := &process.ProcessBlockError{}
+	require.True(t, errors.As(err, &processBlockError))
+	assert.Equal(t, process.StageHeaderBodyCorrelation, processBlockError.Stage)
+	assert.True(t, errors.Is(err, process.ErrHeaderBodyMismatch))
+
+	expectedErr := errors.New("expected error")
+	hdr, body = createIntraShardBlockForProcessing(rootHash)
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			return expectedErr
+		},
 	}
-	hasher.ComputeCalled = func(s string) []byte {
-		if s == "hdrHeaderMarshalized" {
-			return []byte("header hash")
-		}
-		if s == "txBlockBodyMarshalized" {
-			return []byte("tx block body hash")
-		}
-		return nil
+	sp, _ = blproc.NewShardProcessor(arguments)
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	processBlockError = &process.ProcessBlockError{}
+	require.True(t, errors.As(err, &processBlockError))
+	assert.Equal(t, process.StageTxProcessing, processBlockError.Stage)
+	assert.True(t, errors.Is(err, expectedErr))
+
+	hdr, body = createIntraShardBlockForProcessing(rootHash)
+	sp, _ = blproc.NewShardProcessor(createArgumentsForIntraShardBlockProcessing([]byte("rootHashX")))
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	processBlockError = &process.ProcessBlockError{}
+	require.True(t, errors.As(err, &processBlockError))
+	assert.Equal(t, process.StageStateRoot, processBlockError.Stage)
+	assert.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
+
+	hdr, body = createIntraShardBlockForProcessing(rootHash)
+	sp, _ = blproc.NewShardProcessor(createArgumentsForIntraShardBlockProcessing(rootHash))
+
+	err = sp.ProcessBlock(hdr, body, func() time.Duration { return -1 })
+	processBlockError = &process.ProcessBlockError{}
+	require.True(t, errors.As(err, &processBlockError))
+	assert.Equal(t, process.StageMetaHeadersValidity, processBlockError.Stage)
+	assert.True(t, errors.Is(err, process.ErrTimeIsOut))
+
+	hdr, body = createIntraShardBlockForProcessing(rootHash)
+	arguments = createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		IsDataPreparedForProcessingCalled: func(haveTime func() time.Duration) error {
+			return expectedErr
+		},
 	}
-	_, err := be.ComputeHeaderHash(hdr)
-	assert.Nil(t, err)
+	sp, _ = blproc.NewShardProcessor(arguments)
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	processBlockError = &process.ProcessBlockError{}
+	require.True(t, errors.As(err, &processBlockError))
+	assert.Equal(t, process.StageTxProcessing, processBlockError.Stage)
+	assert.True(t, errors.Is(err, expectedErr))
 }
 
-func createTestHdrTxBlockBody() (*block.Header, *block.Body) {
-	hasher := mock.HasherMock{}
-	hdr := &block.Header{
-		Nonce:         1,
-		ShardID:       2,
-		Epoch:         3,
-		Round:         4,
-		TimeStamp:     uint64(11223344),
-		PrevHash:      hasher.Compute("prev hash"),
-		PubKeysBitmap: []byte{255, 0, 128},
-		Signature:     hasher.Compute("signature"),
-		RootHash:      hasher.Compute("root hash"),
-	}
-	txBlock := &block.Body{
-		MiniBlocks: []*block.MiniBlock{
-			{
-				ReceiverShardID: 0,
-				SenderShardID:   0,
-				TxHashes: [][]byte{
-					hasher.Compute("txHash_0_1"),
-					hasher.Compute("txHash_0_2"),
-				},
-			},
-			{
-				ReceiverShardID: 1,
-				SenderShardID:   0,
-				TxHashes: [][]byte{
-					hasher.Compute("txHash_1_1"),
-					hasher.Compute("txHash_1_2"),
-				},
-			},
-			{
-				ReceiverShardID: 2,
-				SenderShardID:   0,
-				TxHashes: [][]byte{
-					hasher.Compute("txHash_2_1"),
-				},
-			},
-			{
-				ReceiverShardID: 3,
-				SenderShardID:   0,
-				TxHashes:        make([][]byte, 0),
-			},
+func TestShardProcessor_ProcessBlockTwiceWithSameHeaderShouldNotProcessAgain(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	numProcessBlockTransactionCalls := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransactionCalls++
+			return nil
 		},
 	}
-	return hdr, txBlock
-}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-//------- ComputeNewNoncePrevHash
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numProcessBlockTransactionCalls)
 
-func TestShardProcessor_DisplayLogInfo(t *testing.T) {
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numProcessBlockTransactionCalls)
+
+	sp.RevertAccountState(hdr)
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numProcessBlockTransactionCalls)
+}
+
+func TestShardProcessor_ProcessBlockWithSameHeaderAndDifferentBodyShouldNotReturnThePreviousResult(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	hasher := mock.HasherMock{}
-	hdr, txBlock := createTestHdrTxBlockBody()
-	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
-	statusHandler := &mock.AppStatusHandlerStub{
-		SetUInt64ValueHandler: func(key string, value uint64) {
 
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	// the hashes are computed with a real hasher, so that the header and the body hashes differ between blocks
+	hasher := &mock.HasherMock{}
+	mbHash, _ := core.CalculateHash(&mock.MarshalizerMock{}, hasher, body.MiniBlocks[0])
+	hdr.MiniBlockHeaders[0].Hash = mbHash
+
+	numProcessBlockTransactionCalls := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransactionCalls++
+			return nil
 		},
 	}
+	arguments.Hasher = hasher
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numProcessBlockTransactionCalls)
 
-	sp, _ := blproc.NewShardProcessor(arguments)
-	assert.NotNil(t, sp)
-	hdr.PrevHash = hasher.Compute("prev hash")
-	sp.DisplayLogInfo(hdr, txBlock, []byte("tx_hash1"), shardCoordinator.NumberOfShards(), shardCoordinator.SelfId(), tdp, statusHandler, &mock.BlockTrackerMock{})
+	otherBody := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{ReceiverShardID: 0, SenderShardID: 0, TxHashes: [][]byte{[]byte("tx_hash2")}},
+		},
+	}
+	err = sp.ProcessBlock(hdr, otherBody, haveTime)
+	assert.True(t, errors.Is(err, process.ErrHeaderBodyMismatch))
 }
 
-func TestBlockProcessor_ApplyBodyToHeaderNilBodyError(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithErrorShouldNotBeRemembered(t *testing.T) {
 	t.Parallel()
-	arguments := CreateMockArgumentsMultiShard()
 
-	bp, _ := blproc.NewShardProcessor(arguments)
-	hdr := &block.Header{}
-	_, err := bp.ApplyBodyToHeader(hdr, nil)
-	assert.Equal(t, process.ErrNilBlockBody, err)
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	numProcessBlockTransactionCalls := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub).RootHashCalled = func() ([]byte, error) {
+		return []byte("other root hash"), nil
+	}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransactionCalls++
+			return nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrRootStateDoesNotMatch))
+	assert.Equal(t, 2, numProcessBlockTransactionCalls)
 }
 
-func TestBlockProcessor_ApplyBodyToHeaderShouldNotReturnNil(t *testing.T) {
+func TestShardProcessor_CreateBlockShouldForgetTheLastProcessedBlock(t *testing.T) {
 	t.Parallel()
-	arguments := CreateMockArgumentsMultiShard()
 
-	bp, _ := blproc.NewShardProcessor(arguments)
-	hdr := &block.Header{}
-	_, err := bp.ApplyBodyToHeader(hdr, &block.Body{})
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	numProcessBlockTransactionCalls := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransactionCalls++
+			return nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
 	assert.Nil(t, err)
-	assert.NotNil(t, hdr)
+
+	_, _, _ = sp.CreateBlock(&block.Header{Round: 2, Nonce: 2}, func() bool { return false })
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numProcessBlockTransactionCalls)
 }
 
-func TestShardProcessor_ApplyBodyToHeaderShouldErrWhenMarshalizerErrors(t *testing.T) {
+func TestShardProcessor_RevertAccountStateShouldForgetTheLastProcessedBlock(t *testing.T) {
 	t.Parallel()
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.Marshalizer = &mock.MarshalizerMock{Fail: true}
-	bp, _ := blproc.NewShardProcessor(arguments)
-	body := &block.Body{
-		MiniBlocks: []*block.MiniBlock{{
-			ReceiverShardID: 1,
-			SenderShardID:   0,
-			TxHashes:        make([][]byte, 0),
-		},
-			{
-				ReceiverShardID: 2,
-				SenderShardID:   0,
-				TxHashes:        make([][]byte, 0),
-			},
-			{
-				ReceiverShardID: 3,
-				SenderShardID:   0,
-				TxHashes:        make([][]byte, 0),
-			},
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	numProcessBlockTransactionCalls := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransactionCalls++
+			return nil
 		},
 	}
-	hdr := &block.Header{}
-	_, err := bp.ApplyBodyToHeader(hdr, body)
-	assert.NotNil(t, err)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+
+	sp.RevertAccountState(hdr)
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numProcessBlockTransactionCalls)
 }
 
-func TestShardProcessor_ApplyBodyToHeaderReturnsOK(t *testing.T) {
+func TestShardProcessor_RestoreBlockIntoPoolsShouldForgetTheLastProcessedBlock(t *testing.T) {
 	t.Parallel()
 
-	arguments := CreateMockArgumentsMultiShard()
-	bp, _ := blproc.NewShardProcessor(arguments)
-	body := &block.Body{
-		MiniBlocks: []*block.MiniBlock{
-			{
-				ReceiverShardID: 1,
-				SenderShardID:   0,
-				TxHashes:        make([][]byte, 0),
-			},
-			{
-				ReceiverShardID: 2,
-				SenderShardID:   0,
-				TxHashes:        make([][]byte, 0),
-			},
-			{
-				ReceiverShardID: 3,
-				SenderShardID:   0,
-				TxHashes:        make([][]byte, 0),
-			},
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	numProcessBlockTransactionCalls := 0
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+			numProcessBlockTransactionCalls++
+			return nil
 		},
 	}
-	hdr := &block.Header{}
-	_, err := bp.ApplyBodyToHeader(hdr, body)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
 	assert.Nil(t, err)
-	assert.Equal(t, len(body.MiniBlocks), len(hdr.MiniBlockHeaders))
+
+	err = sp.RestoreBlockIntoPools(hdr, body)
+	require.Nil(t, err)
+
+	err = sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numProcessBlockTransactionCalls)
 }
 
-func TestShardProcessor_CommitBlockShouldRevertAccountStateWhenErr(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithInvalidBodyCompositionShouldErr(t *testing.T) {
 	t.Parallel()
-	// set accounts dirty
-	journalEntries := 3
-	revToSnapshot := func(snapshot int) error {
-		journalEntries = 0
-		return nil
-	}
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		RevertToSnapshotCalled: revToSnapshot,
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.Reserved = []byte("invalid")
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrInvalidBodyComposition))
+}
+
+func TestShardProcessor_ProcessBlockWithBodyCompositionBeforeEnableEpochShouldErr(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.Reserved = []byte(process.FromMeFirst)
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.BodyCompositionEnableEpoch = hdr.Epoch + 1
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrInvalidBodyComposition))
+}
+
+func TestShardProcessor_ProcessBlockShouldProcessTheTransactionsInTheHeaderBodyComposition(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		reserved          []byte
+		isFromMeFirstCall bool
+	}{
+		{reserved: nil, isFromMeFirstCall: false},
+		{reserved: []byte(process.FromMeFirst), isFromMeFirstCall: true},
+	}
+
+	for _, tc := range testCases {
+		rootHash := []byte("rootHash")
+		hdr, body := createIntraShardBlockForProcessing(rootHash)
+		hdr.Reserved = tc.reserved
+
+		processBlockTransactionCalled := false
+		processBlockTransactionFromMeFirstCalled := false
+		arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+		arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+			ProcessBlockTransactionCalled: func(body *block.Body, haveTime func() time.Duration) error {
+				processBlockTransactionCalled = true
+				return nil
+			},
+			ProcessBlockTransactionFromMeFirstCalled: func(body *block.Body, haveTime func() time.Duration) error {
+				processBlockTransactionFromMeFirstCalled = true
+				return nil
+			},
+		}
+		sp, _ := blproc.NewShardProcessor(arguments)
+
+		err := sp.ProcessBlock(hdr, body, haveTime)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.isFromMeFirstCall, processBlockTransactionFromMeFirstCalled)
+		assert.Equal(t, !tc.isFromMeFirstCall, processBlockTransactionCalled)
 	}
-	bp, _ := blproc.NewShardProcessor(arguments)
-	err := bp.CommitBlock(nil, nil)
-	assert.NotNil(t, err)
-	assert.Equal(t, 0, journalEntries)
 }
 
-func TestShardProcessor_MarshalizedDataToBroadcastShouldWork(t *testing.T) {
+func TestShardProcessor_ProcessBlockCrossShardWithoutMetaShouldFail(t *testing.T) {
 	t.Parallel()
+
+	randSeed := []byte("rand seed")
 	tdp := initDataPool([]byte("tx_hash1"))
-	txHash0 := []byte("txHash0")
-	mb0 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   0,
-		TxHashes:        [][]byte{txHash0},
-	}
-	txHash1 := []byte("txHash1")
-	mb1 := block.MiniBlock{
-		ReceiverShardID: 1,
-		SenderShardID:   0,
-		TxHashes:        [][]byte{txHash1},
-	}
+	txHash := []byte("tx_hash1")
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetCurrentBlockHeader(
+		&block.Header{
+			Nonce:    0,
+			RandSeed: randSeed,
+		},
+	)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	rootHash := []byte("rootHash")
 	body := &block.Body{}
-	body.MiniBlocks = append(body.MiniBlocks, &mb0)
-	body.MiniBlocks = append(body.MiniBlocks, &mb1)
-	body.MiniBlocks = append(body.MiniBlocks, &mb0)
-	body.MiniBlocks = append(body.MiniBlocks, &mb1)
-	marshalizer := &mock.MarshalizerMock{
-		Fail: false,
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
+	}
+	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	tx := &transaction.Transaction{}
+	tdp.Transactions().AddData(txHash, tx, tx.Size(), shardCoordinator.CommunicationIdentifier(0))
+
+	hasher := &mock.HasherStub{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	mbbytes, _ := marshalizer.Marshal(&miniblock)
+	mbHash := hasher.Compute(string(mbbytes))
+	mbHdr := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	hdr := block.Header{
+		Round:            1,
+		Nonce:            1,
+		PrevHash:         []byte(""),
+		PrevRandSeed:     randSeed,
+		Signature:        []byte("signature"),
+		PubKeysBitmap:    []byte("00110"),
+		ShardID:          0,
+		RootHash:         rootHash,
+		MiniBlockHeaders: mbHdrs,
+	}
+	// set accounts not dirty
+	journalLen := func() int { return 0 }
+	wasCalled := false
+	revertToSnapshot := func(snapshot int) error {
+		wasCalled = true
+		return nil
+	}
+	rootHashCalled := func() ([]byte, error) {
+		return rootHash, nil
+	}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled:       journalLen,
+		RevertToSnapshotCalled: revertToSnapshot,
+		RootHashCalled:         rootHashCalled,
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	// should return err
+	err := sp.ProcessBlock(&hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrCrossShardMBWithoutConfirmationFromMeta))
+	assert.False(t, wasCalled)
+}
+
+func TestShardProcessor_ProcessBlockCrossShardWithMetaShouldPass(t *testing.T) {
+	t.Parallel()
+
+	tdp, blkc, rootHash, body, txHashes, hasher, marshalizer, _, mbHash := initBasicTestData()
+	mbHdr := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	randSeed := []byte("rand seed")
+	lastHdr := blkc.GetCurrentBlockHeader()
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
+	blkc.SetCurrentBlockHeaderHash(prevHash)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+
+	shardMiniBlock := block.MiniBlockHeader{
+		ReceiverShardID: mbHdr.ReceiverShardID,
+		SenderShardID:   mbHdr.SenderShardID,
+		TxCount:         mbHdr.TxCount,
+		Hash:            mbHdr.Hash,
+	}
+	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
+	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
+	shardHeader := block.ShardData{
+		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	}
+	shardHdrs := make([]block.ShardData, 0)
+	shardHdrs = append(shardHdrs, shardHeader)
+
+	meta := block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: shardHdrs,
+		RandSeed:  randSeed,
+	}
+	metaBytes, _ := marshalizer.Marshal(&meta)
+	metaHash := hasher.Compute(string(metaBytes))
+
+	tdp.Headers().AddHeader(metaHash, &meta)
+
+	meta = block.MetaBlock{
+		Nonce:        2,
+		ShardInfo:    make([]block.ShardData, 0),
+		PrevRandSeed: randSeed,
+	}
+	metaBytes, _ = marshalizer.Marshal(&meta)
+	metaHash = hasher.Compute(string(metaBytes))
+
+	tdp.Headers().AddHeader(metaHash, &meta)
+
+	// set accounts not dirty
+	journalLen := func() int { return 0 }
+	wasCalled := false
+	revertToSnapshot := func(snapshot int) error {
+		wasCalled = true
+		return nil
+	}
+	rootHashCalled := func() ([]byte, error) {
+		return rootHash, nil
+	}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled:       journalLen,
+		RevertToSnapshotCalled: revertToSnapshot,
+		RootHashCalled:         rootHashCalled,
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	// should return err
+	err := sp.ProcessBlock(&hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrCrossShardMBWithoutConfirmationFromMeta))
+	assert.False(t, wasCalled)
+}
+
+func TestShardProcessor_ProcessBlockHaveTimeLessThanZeroShouldErr(t *testing.T) {
+	t.Parallel()
+	txHash := []byte("tx_hash1")
+	tdp := initDataPool(txHash)
+
+	randSeed := []byte("rand seed")
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetCurrentBlockHeader(
+		&block.Header{
+			Nonce:    1,
+			RandSeed: randSeed,
+		},
+	)
+	rootHash := []byte("rootHash")
+	body := &block.Body{}
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   0,
+		TxHashes:        txHashes,
+	}
+	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	mbbytes, _ := marshalizer.Marshal(&miniblock)
+	mbHash := hasher.Compute(string(mbbytes))
+	mbHdr := block.MiniBlockHeader{
+		SenderShardID:   0,
+		ReceiverShardID: 0,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	currHdr := blkc.GetCurrentBlockHeader()
+	preHash, _ := core.CalculateHash(marshalizer, hasher, currHdr)
+	blkc.SetCurrentBlockHeaderHash(preHash)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	hdr := block.Header{
+		Round:            2,
+		Nonce:            2,
+		PrevHash:         preHash,
+		PrevRandSeed:     randSeed,
+		Signature:        []byte("signature"),
+		PubKeysBitmap:    []byte("00110"),
+		ShardID:          0,
+		RootHash:         rootHash,
+		MiniBlockHeaders: mbHdrs,
+	}
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
+	sp, _ := blproc.NewShardProcessorEmptyWith3shards(tdp, genesisBlocks, blkc)
+	haveTimeLessThanZero := func() time.Duration {
+		return -1 * time.Millisecond
+	}
+
+	// should return err
+	err := sp.ProcessBlock(&hdr, body, haveTimeLessThanZero)
+	assert.True(t, errors.Is(err, process.ErrTimeIsOut))
+}
+
+func TestShardProcessor_ProcessBlockWithMissingMetaHdrShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tdp, blkc, rootHash, body, txHashes, hasher, marshalizer, _, mbHash := initBasicTestData()
+	mbHdr := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	randSeed := []byte("rand seed")
+	lastHdr := blkc.GetCurrentBlockHeader()
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
+	blkc.SetCurrentBlockHeaderHash(prevHash)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+
+	shardMiniBlock := block.MiniBlockHeader{
+		ReceiverShardID: mbHdr.ReceiverShardID,
+		SenderShardID:   mbHdr.SenderShardID,
+		TxCount:         mbHdr.TxCount,
+		Hash:            mbHdr.Hash,
+	}
+	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
+	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
+	shardHeader := block.ShardData{
+		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	}
+	shardHdrs := make([]block.ShardData, 0)
+	shardHdrs = append(shardHdrs, shardHeader)
+
+	meta := block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: shardHdrs,
+		RandSeed:  randSeed,
+	}
+	metaBytes, _ := marshalizer.Marshal(&meta)
+	metaHash := hasher.Compute(string(metaBytes))
+
+	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash)
+	tdp.Headers().AddHeader(metaHash, &meta)
+
+	meta = block.MetaBlock{
+		Nonce:        2,
+		ShardInfo:    make([]block.ShardData, 0),
+		PrevRandSeed: randSeed,
+	}
+	metaBytes, _ = marshalizer.Marshal(&meta)
+	metaHash = hasher.Compute(string(metaBytes))
+
+	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash)
+	tdp.Headers().AddHeader(metaHash, &meta)
+
+	// set accounts not dirty
+	journalLen := func() int { return 0 }
+	revertToSnapshot := func(snapshot int) error {
+		return nil
+	}
+	rootHashCalled := func() ([]byte, error) {
+		return rootHash, nil
+	}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled:       journalLen,
+		RevertToSnapshotCalled: revertToSnapshot,
+		RootHashCalled:         rootHashCalled,
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	// should return err
+	err := sp.ProcessBlock(&hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrTimeIsOut))
+}
+
+func createMetaHeadersRequestsMetricsStub(mut *sync.Mutex, metrics map[string]uint64) *mock.AppStatusHandlerStub {
+	return &mock.AppStatusHandlerStub{
+		IncrementHandler: func(key string) {
+			mut.Lock()
+			metrics[key]++
+			mut.Unlock()
+		},
+		AddUint64Handler: func(key string, value uint64) {},
+		SetUInt64ValueHandler: func(key string, value uint64) {
+			mut.Lock()
+			metrics[key] = value
+			mut.Unlock()
+		},
+		SetInt64ValueHandler:  func(key string, value int64) {},
+		SetStringValueHandler: func(key string, value string) {},
+	}
+}
+
+func TestShardProcessor_ProcessBlockWithMissingMetaHdrShouldSetTheMetaHeadersRequestsMetrics(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.MetaBlockHashes = [][]byte{[]byte("missing meta hash")}
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	mut := sync.Mutex{}
+	metrics := make(map[string]uint64)
+	_ = sp.SetAppStatusHandler(createMetaHeadersRequestsMetricsStub(&mut, metrics))
+
+	haveTimeShort := func() time.Duration {
+		return time.Millisecond * 10
+	}
+	for i := 0; i < 2; i++ {
+		err := sp.ProcessBlock(hdr, body, haveTimeShort)
+		assert.True(t, errors.Is(err, process.ErrTimeIsOut))
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+
+	assert.Equal(t, uint64(1), metrics[core.MetricRequestedMetaHeaders])
+	assert.Equal(t, uint64(0), metrics[core.MetricRequestedFinalMetaHeaders])
+	assert.Equal(t, uint64(1), metrics[core.MetricMissingMetaHeaders])
+	assert.Equal(t, uint64(2), metrics[core.MetricNumBlocksWithMetaHeadersRequests])
+}
+
+func TestShardProcessor_ProcessBlockWithoutMetaHdrRequestsShouldNotCountTheBlock(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	mut := sync.Mutex{}
+	metrics := make(map[string]uint64)
+	_ = sp.SetAppStatusHandler(createMetaHeadersRequestsMetricsStub(&mut, metrics))
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+
+	mut.Lock()
+	defer mut.Unlock()
+
+	assert.Contains(t, metrics, core.MetricRequestedMetaHeaders)
+	assert.Equal(t, uint64(0), metrics[core.MetricRequestedMetaHeaders])
+	assert.Equal(t, uint64(0), metrics[core.MetricMissingMetaHeaders])
+	assert.NotContains(t, metrics, core.MetricNumBlocksWithMetaHeadersRequests)
+}
+
+func TestShardProcessor_ProcessBlockWithWrongMiniBlockHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	txHash := []byte("tx_hash1")
+	tdp := initDataPool(txHash)
+	randSeed := []byte("rand seed")
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetCurrentBlockHeader(
+		&block.Header{
+			Nonce:    1,
+			RandSeed: randSeed,
+		},
+	)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	rootHash := []byte("rootHash")
+	body := &block.Body{}
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        txHashes,
+	}
+	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+
+	hasher := &mock.HasherStub{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	mbbytes, _ := marshalizer.Marshal(&miniblock)
+	mbHash := hasher.Compute(string(mbbytes))
+	mbHdr := block.MiniBlockHeader{
+		SenderShardID:   1,
+		ReceiverShardID: 0,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash,
+	}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	lastHdr := blkc.GetCurrentBlockHeader()
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
+	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+
+	rootHashCalled := func() ([]byte, error) {
+		return rootHash, nil
+	}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		RootHashCalled: rootHashCalled,
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	// should return err
+	err := sp.ProcessBlock(&hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrHeaderBodyMismatch))
+}
+
+//------- checkAndRequestIfMetaHeadersMissing
+func TestShardProcessor_CheckAndRequestIfMetaHeadersMissingShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hdrNoncesRequestCalled := int32(0)
+	tdp, blkc, rootHash, body, txHashes, hasher, marshalizer, _, mbHash := initBasicTestData()
+	mbHdr := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash,
+	}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	lastHdr := blkc.GetCurrentBlockHeader()
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
+	blkc.SetCurrentBlockHeaderHash(prevHash)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	randSeed := []byte("rand seed")
+
+	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+
+	shardMiniBlock := block.MiniBlockHeader{
+		ReceiverShardID: mbHdr.ReceiverShardID,
+		SenderShardID:   mbHdr.SenderShardID,
+		TxCount:         mbHdr.TxCount,
+		Hash:            mbHdr.Hash,
+	}
+	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
+	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
+	shardHeader := block.ShardData{
+		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	}
+	shardHdrs := make([]block.ShardData, 0)
+	shardHdrs = append(shardHdrs, shardHeader)
+
+	meta := &block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: shardHdrs,
+		Round:     1,
+		RandSeed:  randSeed,
+	}
+	metaBytes, _ := marshalizer.Marshal(meta)
+	metaHash := hasher.Compute(string(metaBytes))
+
+	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash)
+	tdp.Headers().AddHeader(metaHash, meta)
+
+	// set accounts not dirty
+	journalLen := func() int { return 0 }
+	revertToSnapshot := func(snapshot int) error {
+		return nil
+	}
+	rootHashCalled := func() ([]byte, error) {
+		return rootHash, nil
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestMetaHeaderByNonceCalled: func(nonce uint64) {
+			atomic.AddInt32(&hdrNoncesRequestCalled, 1)
+		},
+	}
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled:       journalLen,
+		RevertToSnapshotCalled: revertToSnapshot,
+		RootHashCalled:         rootHashCalled,
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(&hdr, body, haveTime)
+
+	meta = &block.MetaBlock{
+		Nonce:        2,
+		ShardInfo:    make([]block.ShardData, 0),
+		Round:        2,
+		PrevRandSeed: randSeed,
+	}
+	metaBytes, _ = marshalizer.Marshal(meta)
+	metaHash = hasher.Compute(string(metaBytes))
+
+	tdp.Headers().AddHeader(metaHash, meta)
+
+	sp.CheckAndRequestIfMetaHeadersMissing()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hdrNoncesRequestCalled))
+	assert.True(t, errors.Is(err, process.ErrTimeIsOut))
+}
+
+//-------- requestMissingFinalityAttestingHeaders
+func TestShardProcessor_RequestMissingFinalityAttestingHeaders(t *testing.T) {
+	t.Parallel()
+
+	tdp := testscommon.NewPoolsHolderMock()
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.SetHighestHdrNonceForCurrentBlock(core.MetachainShardId, 1)
+	res := sp.RequestMissingFinalityAttestingHeaders()
+	assert.Equal(t, res > 0, true)
+}
+
+//--------- verifyIncludedMetaBlocksFinality
+func TestShardProcessor_SetTxsPoolsCleanerNilCleanerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	err := sp.SetTxsPoolsCleaner(nil)
+	assert.Equal(t, process.ErrNilTxsPoolsCleaner, err)
+}
+
+func TestShardProcessor_SetTxsPoolsCleanerShouldNotStartTheCleaner(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	cleanerStarted := false
+	cleanerClosed := false
+	cleaner := &mock.PoolsCleanerStub{
+		StartCleaningCalled: func() {
+			cleanerStarted = true
+		},
+		CloseCalled: func() error {
+			cleanerClosed = true
+			return nil
+		},
+	}
+	err := sp.SetTxsPoolsCleaner(cleaner)
+	require.Nil(t, err)
+	assert.False(t, cleanerStarted)
+
+	err = sp.SetTxsPoolsCleaner(cleaner)
+	require.Nil(t, err)
+	assert.False(t, cleanerClosed)
+
+	_ = sp.Close()
+	assert.False(t, cleanerClosed)
+}
+
+func TestShardProcessor_SetTxsPoolsCleanerShouldSwapTheCleaners(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	firstCleanerClosed := false
+	firstCleaner := &mock.PoolsCleanerStub{
+		CloseCalled: func() error {
+			firstCleanerClosed = true
+			return nil
+		},
+	}
+	err := sp.SetTxsPoolsCleaner(firstCleaner)
+	require.Nil(t, err)
+	assert.False(t, firstCleanerClosed)
+
+	secondCleanerStarted := false
+	secondCleanerClosed := false
+	secondCleaner := &mock.PoolsCleanerStub{
+		StartCleaningCalled: func() {
+			secondCleanerStarted = true
+		},
+		CloseCalled: func() error {
+			secondCleanerClosed = true
+			return nil
+		},
+	}
+	err = sp.SetTxsPoolsCleaner(secondCleaner)
+	require.Nil(t, err)
+	assert.True(t, firstCleanerClosed)
+	assert.False(t, secondCleanerStarted)
+	assert.False(t, secondCleanerClosed)
+}
+
+func TestShardProcessor_MissingFinalHeadersCountShouldReturnTheRequestedFinalityAttestingHeaders(t *testing.T) {
+	t.Parallel()
+
+	metaBlock := &block.MetaBlock{Nonce: 1, Round: 1}
+	metaBlockHash := []byte("meta block hash")
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = testscommon.NewPoolsHolderMock()
+	arguments.DataPool.Headers().AddHeader(metaBlockHash, metaBlock)
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.CreateBlockStarted()
+
+	assert.Equal(t, uint32(0), sp.MissingFinalHeadersCount())
+
+	missingHeaders, missingFinalHeaders := sp.RequestMetaHeaders(&block.Header{MetaBlockHashes: [][]byte{metaBlockHash}})
+	assert.Equal(t, uint32(0), missingHeaders)
+	assert.Equal(t, uint32(1), missingFinalHeaders)
+	assert.Equal(t, missingFinalHeaders, sp.MissingFinalHeadersCount())
+}
+
+func TestShardProcessor_CheckMetaHeadersValidityAndFinalityShouldPass(t *testing.T) {
+	t.Parallel()
+
+	tdp := testscommon.NewPoolsHolderMock()
+	txHash := []byte("tx_hash1")
+	tdp.Transactions().AddData(txHash, &transaction.Transaction{}, 0, process.ShardCacherIdentifier(1, 0))
+	rootHash := []byte("rootHash")
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
+	}
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	mbbytes, _ := marshalizer.Marshal(&miniblock)
+	mbHash := hasher.Compute(string(mbbytes))
+	mbHdr := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
+	lastHdr := genesisBlocks[0]
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, lastHdr)
+	randSeed := []byte("rand seed")
+	hdr := initBlockHeader(prevHash, randSeed, rootHash, mbHdrs)
+
+	shardMiniBlock := block.MiniBlockHeader{
+		ReceiverShardID: mbHdr.ReceiverShardID,
+		SenderShardID:   mbHdr.SenderShardID,
+		TxCount:         mbHdr.TxCount,
+		Hash:            mbHdr.Hash,
+	}
+	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
+	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
+	shardHeader := block.ShardData{
+		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	}
+	shardHdrs := make([]block.ShardData, 0)
+	shardHdrs = append(shardHdrs, shardHeader)
+
+	prevMeta := genesisBlocks[core.MetachainShardId]
+	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMeta)
+	meta1 := &block.MetaBlock{
+		Nonce:        1,
+		ShardInfo:    shardHdrs,
+		Round:        1,
+		PrevHash:     prevHash,
+		PrevRandSeed: prevMeta.GetRandSeed(),
+	}
+	metaBytes, _ := marshalizer.Marshal(meta1)
+	metaHash1 := hasher.Compute(string(metaBytes))
+	hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, metaHash1)
+
+	tdp.Headers().AddHeader(metaHash1, meta1)
+
+	prevHash, _ = core.CalculateHash(marshalizer, hasher, meta1)
+	meta2 := &block.MetaBlock{
+		Nonce:     2,
+		ShardInfo: make([]block.ShardData, 0),
+		Round:     2,
+		PrevHash:  prevHash,
+	}
+	metaBytes, _ = marshalizer.Marshal(meta2)
+	metaHash2 := hasher.Compute(string(metaBytes))
+
+	tdp.Headers().AddHeader(metaHash2, meta2)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	sp, _ := blproc.NewShardProcessor(arguments)
+	hdr.Round = 4
+
+	sp.SetHdrForCurrentBlock(metaHash1, meta1, true)
+	sp.SetHdrForCurrentBlock(metaHash2, meta2, false)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.Nil(t, err)
+}
+
+func createMetaBlockWithoutFinalityAttestingHeader(
+	marshalizer marshal.Marshalizer,
+	hasher hashing.Hasher,
+) (*block.MetaBlock, []byte) {
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
+	prevMeta := genesisBlocks[core.MetachainShardId]
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMeta)
+	metaBlock := &block.MetaBlock{
+		Nonce:        1,
+		ShardInfo:    make([]block.ShardData, 0),
+		Round:        1,
+		PrevHash:     prevHash,
+		PrevRandSeed: prevMeta.GetRandSeed(),
+	}
+	metaHash, _ := core.CalculateHash(marshalizer, hasher, metaBlock)
+
+	return metaBlock, metaHash
+}
+
+func TestShardProcessor_CheckMetaHeadersValidityAndFinalityWithoutAttestingHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.Equal(t, process.ErrHeaderNotFinal, err)
+}
+
+func TestShardProcessor_AssertMetaFinalityShouldMakeNotFinalMetaBlockAcceptable(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.Equal(t, process.ErrHeaderNotFinal, err)
+
+	sp.AssertMetaFinality(metaBlock.GetNonce())
+	err = sp.CheckMetaHeadersValidityAndFinality()
+	assert.Nil(t, err)
+
+	// asserted finality should never regress
+	sp.AssertMetaFinality(0)
+	err = sp.CheckMetaHeadersValidityAndFinality()
+	assert.Nil(t, err)
+}
+
+func TestShardProcessor_CheckMetaHeadersValidityAndFinalityWithCustomVerifierShouldWork(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+
+	numCalls := 0
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.MetaFinalityVerifier = &mock.MetaFinalityVerifierStub{
+		IsMetaHeaderFinalCalled: func(metaHdr data.HeaderHandler, getMetaHeadersWithNonce func(nonce uint64) []data.HeaderHandler) bool {
+			numCalls++
+			// final as soon as the meta header is seen, without any attesting headers
+			return metaHdr.GetNonce() == metaBlock.GetNonce()
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestShardProcessor_CheckMetaHeadersValidityAndFinalityShouldCountTheConstructionRejections(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, _ := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+	metaBlock.PrevHash = []byte("wrong prev hash")
+	metaHash, _ := core.CalculateHash(marshalizer, hasher, metaBlock)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	numRejections := 0
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		IncrementHandler: func(key string) {
+			if key == core.MetricMetaHdrConstructionRejections {
+				numRejections++
+			}
+		},
+	})
+	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.True(t, errors.Is(err, process.ErrBlockHashDoesNotMatch))
+	assert.Equal(t, 1, numRejections)
+
+	_ = sp.CheckMetaHeadersValidityAndFinality()
+	assert.Equal(t, 2, numRejections)
+}
+
+func TestShardProcessor_CheckMetaHeadersValidityAndFinalityShouldNotCountTheFinalityCheckRejections(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+	attestingMetaBlock := &block.MetaBlock{
+		Nonce:     metaBlock.GetNonce() + 1,
+		ShardInfo: make([]block.ShardData, 0),
+		Round:     metaBlock.GetRound() + 1,
+		PrevHash:  []byte("wrong prev hash"),
+	}
+	attestingMetaHash, _ := core.CalculateHash(marshalizer, hasher, attestingMetaBlock)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	numRejections := 0
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		IncrementHandler: func(key string) {
+			if key == core.MetricMetaHdrConstructionRejections {
+				numRejections++
+			}
+		},
+	})
+	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
+	sp.SetHdrForCurrentBlock(attestingMetaHash, attestingMetaBlock, false)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.Equal(t, process.ErrHeaderNotFinal, err)
+	assert.Equal(t, 0, numRejections)
+}
+
+func TestShardProcessor_CheckMetaHeadersValidityAndFinalityShouldReturnNilWhenNoMetaBlocksAreUsed(t *testing.T) {
+	t.Parallel()
+
+	tdp := testscommon.NewPoolsHolderMock()
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
+	sp, _ := blproc.NewShardProcessorEmptyWith3shards(
+		tdp,
+		genesisBlocks,
+		&mock.BlockChainMock{
+			GetGenesisHeaderCalled: func() data.HeaderHandler {
+				return &block.Header{Nonce: 0}
+			},
+		},
+	)
+
+	err := sp.CheckMetaHeadersValidityAndFinality()
+	assert.Nil(t, err)
+}
+
+//------- CommitBlock
+
+func createProcessedMiniBlocksWithEmptyMetaBlocks(metaBlocksHashes ...string) *processedMb.ProcessedMiniBlockTracker {
+	processedMiniBlocks := processedMb.NewProcessedMiniBlocks()
+	processedMiniBlocks.AddMiniBlockHash("meta", "mb")
+	for _, metaBlockHash := range metaBlocksHashes {
+		processedMiniBlocks.AddMiniBlockHash(metaBlockHash, "mb to remove")
+		delete(processedMiniBlocks.GetProcessedMiniBlocksHashes(metaBlockHash), "mb to remove")
+	}
+
+	return processedMiniBlocks
+}
+
+func TestShardProcessor_CommitBlockShouldCompactTheProcessedMiniBlocksWhenTheIntervalHasElapsed(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.ProcessedMbsCompactionInterval = time.Millisecond
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.ApplyProcessedMiniBlocks(createProcessedMiniBlocksWithEmptyMetaBlocks("meta1", "meta2"))
+
+	time.Sleep(arguments.ProcessedMbsCompactionInterval * 10)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(2), sp.NumRemovedEmptyProcessedMiniBlocks())
+	assert.Equal(t, 1, len(sp.GetProcessedMiniBlocksInfo()))
+}
+
+func TestShardProcessor_CommitBlockWithoutCompactionIntervalShouldNotCompactTheProcessedMiniBlocks(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.ApplyProcessedMiniBlocks(createProcessedMiniBlocksWithEmptyMetaBlocks("meta1", "meta2"))
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), sp.NumRemovedEmptyProcessedMiniBlocks())
+	assert.Equal(t, 3, len(sp.GetProcessedMiniBlocksInfo()))
+}
+
+func TestShardProcessor_CommitBlockMarshalizerFailForHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	rootHash := []byte("root hash to be tested")
+	accounts := &mock.AccountsStub{
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+		RevertToSnapshotCalled: func(snapshot int) error {
+			return nil
+		},
+	}
+	errMarshalizer := errors.New("failure")
+	hdr := &block.Header{
+		Nonce:         1,
+		Round:         1,
+		PubKeysBitmap: []byte("0100101"),
+		Signature:     []byte("signature"),
+		RootHash:      rootHash,
+	}
+	body := &block.Body{}
+	marshalizer := &mock.MarshalizerStub{
+		MarshalCalled: func(obj interface{}) (i []byte, e error) {
+			if reflect.DeepEqual(obj, hdr) {
+				return nil, errMarshalizer
+			}
+
+			return []byte("obj"), nil
+		},
+	}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizer
+	arguments.AccountsDB[state.UserAccountsState] = accounts
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, body)
+	assert.Equal(t, errMarshalizer, err)
+}
+
+func TestShardProcessor_CommitBlockStorageFailsForHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	tdp := initDataPool([]byte("tx_hash1"))
+	errPersister := errors.New("failure")
+	putCalledNr := uint32(0)
+	rootHash := []byte("root hash to be tested")
+	marshalizer := &mock.MarshalizerMock{}
+	accounts := &mock.AccountsStub{
+		CommitCalled: func() ([]byte, error) {
+			return nil, nil
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+		RevertToSnapshotCalled: func(snapshot int) error {
+			return nil
+		},
+	}
+	hdr := &block.Header{
+		Nonce:         1,
+		Round:         1,
+		PubKeysBitmap: []byte("0100101"),
+		Signature:     []byte("signature"),
+		RootHash:      rootHash,
+	}
+	body := &block.Body{}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	hdrUnit := &mock.StorerStub{
+		GetCalled: func(key []byte) (i []byte, e error) {
+			return marshalizer.Marshal(&block.Header{})
+		},
+		PutCalled: func(key, data []byte) error {
+			atomic.AddUint32(&putCalledNr, 1)
+			wg.Done()
+			return errPersister
+		},
+		HasCalled: func(key []byte) error {
+			return nil
+		},
+	}
+	store := initStore()
+	store.AddStorer(dataRetriever.BlockHeaderUnit, hdrUnit)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Store = store
+	arguments.AccountsDB[state.UserAccountsState] = accounts
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	blockTrackerMock := mock.NewBlockTrackerMock(shardCoordinator, createGenesisBlocks(shardCoordinator))
+	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
+		return &block.MetaBlock{}, []byte("hash"), nil
+	}
+	arguments.BlockTracker = blockTrackerMock
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	_ = blkc.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {},
+	})
+	arguments.BlockChain = blkc
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, body)
+	wg.Wait()
+	assert.True(t, atomic.LoadUint32(&putCalledNr) > 0)
+	assert.Nil(t, err)
+}
+
+func TestShardProcessor_CommitBlockStorageFailsForBodyShouldWork(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	putCalledNr := uint32(0)
+	errPersister := errors.New("failure")
+	rootHash := []byte("root hash to be tested")
+	accounts := &mock.AccountsStub{
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+		CommitCalled: func() (i []byte, e error) {
+			return nil, nil
+		},
+		RevertToSnapshotCalled: func(snapshot int) error {
+			return nil
+		},
+	}
+	hdr := &block.Header{
+		Nonce:         1,
+		Round:         1,
+		PubKeysBitmap: []byte("0100101"),
+		Signature:     []byte("signature"),
+		RootHash:      rootHash,
+	}
+	mb := block.MiniBlock{}
+	body := &block.Body{}
+	body.MiniBlocks = append(body.MiniBlocks, &mb)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	miniBlockUnit := &mock.StorerStub{
+		PutCalled: func(key, data []byte) error {
+			atomic.AddUint32(&putCalledNr, 1)
+			wg.Done()
+			return errPersister
+		},
+	}
+	store := initStore()
+	store.AddStorer(dataRetriever.MiniBlockUnit, miniBlockUnit)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Store = store
+	arguments.AccountsDB[state.UserAccountsState] = accounts
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+	blockTrackerMock := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
+		return &block.MetaBlock{}, []byte("hash"), nil
+	}
+	arguments.BlockTracker = blockTrackerMock
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	_ = blkc.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {},
+	})
+	arguments.BlockChain = blkc
+	sp, err := blproc.NewShardProcessor(arguments)
+	assert.Nil(t, err)
+
+	err = sp.CommitBlock(hdr, body)
+	wg.Wait()
+	assert.Nil(t, err)
+	assert.True(t, atomic.LoadUint32(&putCalledNr) > 0)
+}
+
+func TestShardProcessor_CommitBlockOkValsShouldWork(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	txHash := []byte("tx_hash1")
+
+	rootHash := []byte("root hash")
+	hdrHash := []byte("header hash")
+	randSeed := []byte("rand seed")
+
+	prevHdr := &block.Header{
+		Nonce:         0,
+		Round:         0,
+		PubKeysBitmap: rootHash,
+		PrevHash:      hdrHash,
+		Signature:     rootHash,
+		RootHash:      rootHash,
+		RandSeed:      randSeed,
+	}
+
+	hdr := &block.Header{
+		Nonce:           1,
+		Round:           1,
+		PubKeysBitmap:   rootHash,
+		PrevHash:        hdrHash,
+		Signature:       rootHash,
+		RootHash:        rootHash,
+		PrevRandSeed:    randSeed,
+		AccumulatedFees: big.NewInt(0),
+		DeveloperFees:   big.NewInt(0),
+	}
+	mb := block.MiniBlock{
+		TxHashes: [][]byte{txHash},
+	}
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{&mb}}
+
+	mbHdr := block.MiniBlockHeader{
+		TxCount: uint32(len(mb.TxHashes)),
+		Hash:    hdrHash,
+	}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+	hdr.MiniBlockHeaders = mbHdrs
+
+	accounts := &mock.AccountsStub{
+		CommitCalled: func() (i []byte, e error) {
+			return rootHash, nil
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+	}
+	forkDetectorAddCalled := false
+	fd := &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			if header == hdr {
+				forkDetectorAddCalled = true
+				return nil
+			}
+
+			return errors.New("should have not got here")
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+	hasher := &mock.HasherStub{}
+	hasher.ComputeCalled = func(s string) []byte {
+		return hdrHash
+	}
+	store := initStore()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Store = store
+	arguments.Hasher = hasher
+	arguments.AccountsDB[state.UserAccountsState] = accounts
+	arguments.ForkDetector = fd
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	blockTrackerMock := mock.NewBlockTrackerMock(shardCoordinator, createGenesisBlocks(shardCoordinator))
+	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
+		return &block.MetaBlock{}, []byte("hash"), nil
+	}
+	arguments.BlockTracker = blockTrackerMock
+	blkc := createTestBlockchain()
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return prevHdr
+	}
+	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
+		return hdrHash
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	err = sp.CommitBlock(hdr, body)
+	assert.Nil(t, err)
+	assert.True(t, forkDetectorAddCalled)
+	assert.Equal(t, hdrHash, blkc.GetCurrentBlockHeaderHash())
+	//this should sleep as there is an async call to display current hdr and block in CommitBlock
+	time.Sleep(time.Second)
+}
+
+func TestShardProcessor_IndexBlockIfNeededShouldApplyIndexedTxTransformer(t *testing.T) {
+	t.Parallel()
+
+	type enrichedTransaction struct {
+		*transaction.Transaction
+		SenderShard uint32
+	}
+
+	var indexedTxs map[string]data.TransactionHandler
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Indexer = &mock.IndexerMock{
+		SaveBlockCalled: func(body data.BodyHandler, header data.HeaderHandler, txPool map[string]data.TransactionHandler) {
+			indexedTxs = txPool
+		},
+	}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			if blockType != block.TxBlock {
+				return nil
+			}
+
+			return map[string]data.TransactionHandler{
+				"tx_1": &transaction.Transaction{Nonce: 1},
+				"tx_2": &transaction.Transaction{Nonce: 2},
+			}
+		},
+	}
+	arguments.IndexedTxTransformer = func(txPool map[string]data.TransactionHandler) map[string]interface{} {
+		transformedTxs := make(map[string]interface{}, len(txPool))
+		for hash, tx := range txPool {
+			transformedTxs[hash] = &enrichedTransaction{
+				Transaction: tx.(*transaction.Transaction),
+				SenderShard: 1,
+			}
+		}
+		transformedTxs["not a transaction"] = "payload"
+
+		return transformedTxs
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.IndexBlockIfNeeded(&block.Body{}, []byte("header hash"), &block.Header{Nonce: 1, Round: 1, PrevRandSeed: []byte("randSeed")}, &block.Header{})
+
+	require.Equal(t, 2, len(indexedTxs))
+	for _, hash := range []string{"tx_1", "tx_2"} {
+		enrichedTx, ok := indexedTxs[hash].(*enrichedTransaction)
+		require.True(t, ok)
+		assert.Equal(t, uint32(1), enrichedTx.SenderShard)
+	}
+}
+
+func TestShardProcessor_IndexBlockIfNeededWithUnusableTransformResultShouldIndexTheOriginalTx(t *testing.T) {
+	t.Parallel()
+
+	originalTx2 := &transaction.Transaction{Nonce: 2}
+	originalTx3 := &transaction.Transaction{Nonce: 3}
+	transformedTx1 := &transaction.Transaction{Nonce: 1, Data: []byte("enriched")}
+	var indexedTxs map[string]data.TransactionHandler
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Indexer = &mock.IndexerMock{
+		SaveBlockCalled: func(body data.BodyHandler, header data.HeaderHandler, txPool map[string]data.TransactionHandler) {
+			indexedTxs = txPool
+		},
+	}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			if blockType != block.TxBlock {
+				return nil
+			}
+
+			return map[string]data.TransactionHandler{
+				"tx_1": &transaction.Transaction{Nonce: 1},
+				"tx_2": originalTx2,
+				"tx_3": originalTx3,
+			}
+		},
+	}
+	arguments.IndexedTxTransformer = func(txPool map[string]data.TransactionHandler) map[string]interface{} {
+		var nilTx *transaction.Transaction
+		return map[string]interface{}{
+			"tx_1": transformedTx1,
+			"tx_2": "not a transaction",
+			"tx_3": nilTx,
+		}
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.IndexBlockIfNeeded(&block.Body{}, []byte("header hash"), &block.Header{Nonce: 1, Round: 1, PrevRandSeed: []byte("randSeed")}, &block.Header{})
+
+	expectedIndexedTxs := map[string]data.TransactionHandler{
+		"tx_1": transformedTx1,
+		"tx_2": originalTx2,
+		"tx_3": originalTx3,
+	}
+	assert.Equal(t, expectedIndexedTxs, indexedTxs)
+}
+
+func TestShardProcessor_IndexBlockIfNeededWithNilTransformResultShouldIndexTheOriginalTxs(t *testing.T) {
+	t.Parallel()
+
+	originalTxs := map[string]data.TransactionHandler{
+		"tx_1": &transaction.Transaction{Nonce: 1},
+		"tx_2": &transaction.Transaction{Nonce: 2},
+	}
+	var indexedTxs map[string]data.TransactionHandler
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Indexer = &mock.IndexerMock{
+		SaveBlockCalled: func(body data.BodyHandler, header data.HeaderHandler, txPool map[string]data.TransactionHandler) {
+			indexedTxs = txPool
+		},
+	}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			if blockType != block.TxBlock {
+				return nil
+			}
+
+			return originalTxs
+		},
+	}
+	arguments.IndexedTxTransformer = func(txPool map[string]data.TransactionHandler) map[string]interface{} {
+		return nil
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	sp.IndexBlockIfNeeded(&block.Body{}, []byte("header hash"), &block.Header{Nonce: 1, Round: 1, PrevRandSeed: []byte("randSeed")}, &block.Header{})
+
+	assert.Equal(t, originalTxs, indexedTxs)
+}
+
+func createFirstBlockHeaderAfterGenesis(rootHash []byte, genesisHash []byte) *block.Header {
+	return &block.Header{
+		Nonce:           1,
+		Round:           1,
+		PrevHash:        genesisHash,
+		PubKeysBitmap:   []byte("0100101"),
+		Signature:       []byte("signature"),
+		RootHash:        rootHash,
+		PrevRandSeed:    []byte("rand seed"),
+		AccumulatedFees: big.NewInt(0),
+		DeveloperFees:   big.NewInt(0),
+	}
+}
+
+func createArgumentsForCommittingFirstBlock(
+	rootHash []byte,
+	genesisHash []byte,
+	marshalizer marshal.Marshalizer,
+) blproc.ArgShardProcessor {
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = initDataPool([]byte("tx_hash1"))
+	arguments.Store = initStore()
+	arguments.Marshalizer = marshalizer
+	arguments.Uint64Converter = uint64ByteSlice.NewBigEndianConverter()
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		CommitCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+	}
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+	blockTrackerMock := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
+		return &block.MetaBlock{}, []byte("hash"), nil
+	}
+	arguments.BlockTracker = blockTrackerMock
+	blkc := createTestBlockchain()
+	blkc.GetGenesisHeaderHashCalled = func() []byte {
+		return genesisHash
+	}
+	arguments.BlockChain = blkc
+
+	return arguments
+}
+
+func TestShardProcessor_CommitBlockShouldNotifyFinalBlockAdvanceSubscribers(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+
+	highestFinalNonce := uint64(1)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return highestFinalNonce
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	firstSubscriber, _ := sp.SubscribeFinalBlockAdvance()
+	secondSubscriber, _ := sp.SubscribeFinalBlockAdvance()
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	err = sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	highestFinalNonce = 2
+	err = sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	for _, subscriber := range []<-chan uint64{firstSubscriber, secondSubscriber} {
+		require.Equal(t, 2, len(subscriber))
+		assert.Equal(t, uint64(1), <-subscriber)
+		assert.Equal(t, uint64(2), <-subscriber)
+	}
+}
+
+func TestShardProcessor_UnsubscribeFinalBlockAdvanceShouldCloseTheChannel(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 1
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	unsubscribedSubscriber, unsubscribe := sp.SubscribeFinalBlockAdvance()
+	subscriber, _ := sp.SubscribeFinalBlockAdvance()
+
+	unsubscribe()
+	unsubscribe()
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	_, isOpen := <-unsubscribedSubscriber
+	assert.False(t, isOpen)
+	require.Equal(t, 1, len(subscriber))
+	assert.Equal(t, uint64(1), <-subscriber)
+}
+
+func TestShardProcessor_CloseShouldCloseTheFinalBlockAdvanceChannels(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(createArgumentsForCommittingFirstBlock([]byte("root hash"), []byte("genesis hash"), &mock.MarshalizerMock{}))
+
+	subscriber, unsubscribe := sp.SubscribeFinalBlockAdvance()
+
+	err := sp.Close()
+	require.Nil(t, err)
+
+	_, isOpen := <-subscriber
+	assert.False(t, isOpen)
+	assert.NotPanics(t, unsubscribe)
+
+	subscriberAfterClose, _ := sp.SubscribeFinalBlockAdvance()
+	_, isOpen = <-subscriberAfterClose
+	assert.False(t, isOpen)
+}
+
+func TestShardProcessor_RegisterCommittedBlockSinkNilSinkShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(createArgumentsForCommittingFirstBlock([]byte("root hash"), []byte("genesis hash"), &mock.MarshalizerMock{}))
+
+	err := sp.RegisterCommittedBlockSink(nil)
+	assert.Equal(t, process.ErrNilCommittedBlockSink, err)
+}
+
+func TestShardProcessor_CommitBlockShouldStreamToCommittedBlockSinks(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{TxHashes: [][]byte{[]byte("tx_hash1")}, ReceiverShardID: 0, SenderShardID: 0},
+		},
+	}
+	headerHash := []byte("header hash")
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.Hasher = &mock.HasherStub{
+		ComputeCalled: func(s string) []byte {
+			return headerHash
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+	defer func() {
+		_ = sp.Close()
+	}()
+
+	type streamedBlock struct {
+		header data.HeaderHandler
+		body   block.Body
+		hash   []byte
+	}
+	firstSink := make(chan streamedBlock, 1)
+	secondSink := make(chan streamedBlock, 1)
+	for _, ch := range []chan streamedBlock{firstSink, secondSink} {
+		chSink := ch
+		err := sp.RegisterCommittedBlockSink(func(header data.HeaderHandler, body block.Body, hash []byte) {
+			chSink <- streamedBlock{header: header, body: body, hash: hash}
+		})
+		require.Nil(t, err)
+	}
+
+	err := sp.CommitBlock(hdr, body)
+	require.Nil(t, err)
+
+	for _, ch := range []chan streamedBlock{firstSink, secondSink} {
+		select {
+		case sb := <-ch:
+			assert.Equal(t, hdr, sb.header)
+			assert.Equal(t, *body, sb.body)
+			assert.Equal(t, headerHash, sb.hash)
+		case <-time.After(time.Second):
+			assert.Fail(t, "committed block was not streamed to the sink")
+		}
+	}
+	assert.Equal(t, uint64(0), sp.NumDroppedCommittedBlocks())
+}
+
+func TestShardProcessor_CommitBlockShouldCountPrunedNotarizedHeaders(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.ForkDetector.(*mock.ForkDetectorMock).GetHighestFinalBlockNonceCalled = func() uint64 {
+		return 4
+	}
+	dataPool := arguments.DataPool.(*testscommon.PoolsHolderStub)
+	headersPool := dataPool.Headers().(*mock.HeadersCacherStub)
+	headersPool.NoncesCalled = func(shardId uint32) []uint64 {
+		return []uint64{1, 2, 3, 4, 5}
+	}
+	headersPool.GetHeaderByNonceAndShardIdCalled = func(hdrNonce uint64, shardId uint32) ([]data.HeaderHandler, [][]byte, error) {
+		return []data.HeaderHandler{&block.Header{Nonce: hdrNonce}}, [][]byte{[]byte("hash")}, nil
+	}
+	dataPool.HeadersCalled = func() dataRetriever.HeadersPool {
+		return headersPool
+	}
+	numPrunedFromCallback := uint64(0)
+	arguments.OnNotarizedHeadersPruned = func(numPrunedHeaders uint64) {
+		numPrunedFromCallback = numPrunedHeaders
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	numPrunedFromMetric := uint64(0)
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		AddUint64Handler: func(key string, val uint64) {
+			if key == core.MetricNotarizedHeadersPruned {
+				numPrunedFromMetric += val
+			}
+		},
+		SetUInt64ValueHandler: func(key string, value uint64) {},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(3), numPrunedFromMetric)
+	assert.Equal(t, uint64(3), numPrunedFromCallback)
+}
+
+func TestShardProcessor_ComputeHeaderHashNilHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	hash, err := sp.ComputeHeaderHash(nil)
+	assert.Nil(t, hash)
+	assert.Equal(t, process.ErrNilBlockHeader, err)
+}
+
+func TestShardProcessor_ComputeHeaderHashShouldMatchTheHashStoredByCommitBlock(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.Hasher = &mock.HasherMock{}
+	var committedHash []byte
+	blkc := arguments.BlockChain.(*mock.BlockChainMock)
+	blkc.SetCurrentBlockHeaderHashCalled = func(hash []byte) {
+		committedHash = hash
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	hash, err := sp.ComputeHeaderHash(hdr)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, hash)
+	assert.Equal(t, committedHash, hash)
+}
+
+func TestShardProcessor_CommitBlockShouldDropCommittedBlocksWhenSinkIsSlow(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	sp, _ := blproc.NewShardProcessor(createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{}))
+	defer func() {
+		_ = sp.Close()
+	}()
+
+	chRelease := make(chan struct{})
+	defer close(chRelease)
+	chReceived := make(chan struct{}, 1)
+	err := sp.RegisterCommittedBlockSink(func(header data.HeaderHandler, body block.Body, hash []byte) {
+		chReceived <- struct{}{}
+		<-chRelease
+	})
+	require.Nil(t, err)
+
+	err = sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	select {
+	case <-chReceived:
+	case <-time.After(time.Second):
+		require.Fail(t, "committed block was not streamed to the sink")
+	}
+
+	numExtraBlocks := 5
+	for i := 0; i < blproc.CommittedBlockSinkChanSize+numExtraBlocks; i++ {
+		err = sp.CommitBlock(hdr, &block.Body{})
+		require.Nil(t, err)
+	}
+
+	assert.Equal(t, uint64(numExtraBlocks), sp.NumDroppedCommittedBlocks())
+}
+
+func TestShardProcessor_CommitBlockShouldExportTxsWhenTxExportDirIsSet(t *testing.T) {
+	t.Parallel()
+
+	exportDir, _ := ioutil.TempDir("", "txs_export_temp")
+	defer func() {
+		_ = os.RemoveAll(exportDir)
+	}()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	headerHash := []byte("header hash")
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.TxExportDir = exportDir
+	arguments.Hasher = &mock.HasherStub{
+		ComputeCalled: func(s string) []byte {
+			return headerHash
+		},
+	}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			switch blockType {
+			case block.TxBlock:
+				return map[string]data.TransactionHandler{"tx hash": &transaction.Transaction{Nonce: 1}}
+			case block.SmartContractResultBlock:
+				return map[string]data.TransactionHandler{"scr hash": &smartContractResult.SmartContractResult{Nonce: 2}}
+			default:
+				return nil
+			}
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(0), sp.NumTxExportErrors())
+
+	fileName := fmt.Sprintf("txs_shard_%d_nonce_%d_%s.jsonl", hdr.ShardID, hdr.Nonce, hex.EncodeToString(headerHash))
+	content, err := ioutil.ReadFile(filepath.Join(exportDir, fileName))
+	require.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[0], hex.EncodeToString([]byte("scr hash")))
+	assert.Contains(t, lines[1], hex.EncodeToString([]byte("tx hash")))
+}
+
+func TestShardProcessor_CommitBlockShouldCountTxsExportErrors(t *testing.T) {
+	t.Parallel()
+
+	exportDir, _ := ioutil.TempDir("", "txs_export_temp")
+	defer func() {
+		_ = os.RemoveAll(exportDir)
+	}()
+	notADirectory := filepath.Join(exportDir, "file")
+	err := ioutil.WriteFile(notADirectory, []byte("content"), 0600)
+	require.Nil(t, err)
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.TxExportDir = notADirectory
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err = sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	assert.Equal(t, uint64(1), sp.NumTxExportErrors())
+}
+
+func TestShardProcessor_GetStoredHeaderBytesByNonceShouldReturnCommittedHeader(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	marshalizer := &mock.MarshalizerMock{}
+	sp, _ := blproc.NewShardProcessor(createArgumentsForCommittingFirstBlock(rootHash, genesisHash, marshalizer))
+
+	_, err := sp.GetStoredHeaderBytesByNonce(1)
+	assert.True(t, errors.Is(err, process.ErrBlockNotInStorage))
+
+	err = sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	headerBytes, err := sp.GetStoredHeaderBytesByNonce(1)
+	require.Nil(t, err)
+
+	storedHeader := &block.Header{}
+	err = marshalizer.Unmarshal(storedHeader, headerBytes)
+	require.Nil(t, err)
+	assert.Equal(t, hdr.Nonce, storedHeader.Nonce)
+	assert.Equal(t, hdr.Round, storedHeader.Round)
+	assert.Equal(t, hdr.RootHash, storedHeader.RootHash)
+	assert.Equal(t, hdr.PrevHash, storedHeader.PrevHash)
+}
+
+func TestShardProcessor_FindOrphanedMiniBlocksShouldReportTheNotReferencedMiniBlocks(t *testing.T) {
+	t.Parallel()
+
+	marshalizer := &mock.MarshalizerMock{}
+	store := initStore()
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Store = store
+	arguments.Marshalizer = marshalizer
+	arguments.Uint64Converter = uint64ByteSlice.NewBigEndianConverter()
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	referencedMiniBlockHash := []byte("referenced miniblock hash")
+	orphanedMiniBlockHash := []byte("orphaned miniblock hash")
+	for nonce := uint64(1); nonce <= 2; nonce++ {
+		hdr := &block.Header{Nonce: nonce}
+		if nonce == 2 {
+			hdr.MiniBlockHeaders = []block.MiniBlockHeader{{Hash: referencedMiniBlockHash}}
+		}
+		hdrBytes, _ := marshalizer.Marshal(hdr)
+		hdrHash := []byte(fmt.Sprintf("header hash %d", nonce))
+		_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(nonce), hdrHash)
+		_ = store.Put(dataRetriever.BlockHeaderUnit, hdrHash, hdrBytes)
+	}
+	_ = store.Put(dataRetriever.MiniBlockUnit, referencedMiniBlockHash, []byte("referenced miniblock"))
+	_ = store.Put(dataRetriever.MiniBlockUnit, orphanedMiniBlockHash, []byte("orphaned miniblock"))
+
+	_, err := sp.FindOrphanedMiniBlocks(2, 1)
+	assert.True(t, errors.Is(err, process.ErrInvalidNonceRange))
+
+	_, err = sp.FindOrphanedMiniBlocks(1, 3)
+	assert.True(t, errors.Is(err, process.ErrBlockNotInStorage))
+
+	orphanedMiniBlocks, err := sp.FindOrphanedMiniBlocks(1, 2)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{orphanedMiniBlockHash}, orphanedMiniBlocks)
+
+	// the miniblock referenced by a header outside of the range is also reported
+	orphanedMiniBlocks, err = sp.FindOrphanedMiniBlocks(1, 1)
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(orphanedMiniBlocks))
+}
+
+func TestShardProcessor_CommitBlockShouldSetCommittedBlockSizeMetric(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{TxHashes: [][]byte{[]byte("tx_hash1")}, ReceiverShardID: 0, SenderShardID: 0},
+			{TxHashes: [][]byte{[]byte("tx_hash2"), []byte("tx_hash3")}, ReceiverShardID: 1, SenderShardID: 0},
+		},
+	}
+	marshalizer := &mock.MarshalizerMock{}
+
+	expectedSize := 0
+	marshalizedHeader, _ := marshalizer.Marshal(hdr)
+	expectedSize += len(marshalizedHeader)
+	for _, miniBlock := range body.MiniBlocks {
+		marshalizedMiniBlock, _ := marshalizer.Marshal(miniBlock)
+		expectedSize += len(marshalizedMiniBlock)
+	}
+
+	committedBlockSize := uint64(0)
+	sp, _ := blproc.NewShardProcessor(createArgumentsForCommittingFirstBlock(rootHash, genesisHash, marshalizer))
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {
+			if key == core.MetricCommittedBlockSizeBytes {
+				atomic.StoreUint64(&committedBlockSize, value)
+			}
+		},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	err := sp.CommitBlock(hdr, body)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(expectedSize), atomic.LoadUint64(&committedBlockSize))
+}
+
+func TestShardProcessor_CommitBlockShouldSaveTheMiniBlocksWithOneBatchedWrite(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{TxHashes: [][]byte{[]byte("tx_hash1")}, ReceiverShardID: 0, SenderShardID: 0},
+			{TxHashes: [][]byte{[]byte("tx_hash2"), []byte("tx_hash3")}, ReceiverShardID: 1, SenderShardID: 0},
+		},
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, marshalizer)
+
+	numPutBatchCalls := 0
+	numPutCalls := 0
+	var savedValues [][]byte
+	store := arguments.Store.(*dataRetriever.ChainStorer)
+	store.AddStorer(dataRetriever.MiniBlockUnit, &mock.StorerWithPutBatchStub{
+		StorerStub: mock.StorerStub{
+			PutCalled: func(key, data []byte) error {
+				numPutCalls++
+				return nil
+			},
+		},
+		PutBatchCalled: func(keys [][]byte, values [][]byte) error {
+			numPutBatchCalls++
+			savedValues = values
+			return nil
+		},
+	})
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	commitBlockDurationMetricSet := false
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {
+			if key == core.MetricCommitBlockDurationMs {
+				commitBlockDurationMetricSet = true
+			}
+		},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	err := sp.CommitBlock(hdr, body)
+	require.Nil(t, err)
+
+	expectedValues := make([][]byte, 0, len(body.MiniBlocks))
+	for _, miniBlock := range body.MiniBlocks {
+		marshalizedMiniBlock, _ := marshalizer.Marshal(miniBlock)
+		expectedValues = append(expectedValues, marshalizedMiniBlock)
+	}
+	assert.Equal(t, 1, numPutBatchCalls)
+	assert.Equal(t, 0, numPutCalls)
+	assert.Equal(t, expectedValues, savedValues)
+	assert.True(t, commitBlockDurationMetricSet)
+}
+
+func TestShardProcessor_CommitBlockShouldSetTheGasRefundedPerBlockMetric(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	body := &block.Body{}
+
+	totalGasRefunded := uint64(37000)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetTotalGasRefundedCalled: func() uint64 {
+			return totalGasRefunded
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	gasRefundedMetric := uint64(0)
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {
+			if key == core.MetricGasRefundedPerBlock {
+				gasRefundedMetric = value
+			}
+		},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	err := sp.CommitBlock(hdr, body)
+	require.Nil(t, err)
+	assert.Equal(t, totalGasRefunded, gasRefundedMetric)
+}
+
+func TestShardProcessor_CommitBlockShouldSetTheMetricValuesAtOnce(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	body := &block.Body{}
+
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	numSetValueCalls := 0
+	var setValues []map[string]interface{}
+	_ = sp.SetAppStatusHandler(&mock.AppStatusBatchHandlerStub{
+		AppStatusHandlerStub: mock.AppStatusHandlerStub{
+			SetUInt64ValueHandler: func(key string, value uint64) {
+				numSetValueCalls++
+			},
+			SetStringValueHandler: func(key string, value string) {
+				numSetValueCalls++
+			},
+		},
+		SetMultipleHandler: func(values map[string]interface{}) {
+			setValues = append(setValues, values)
+		},
+	})
+
+	err := sp.CommitBlock(hdr, body)
+	require.Nil(t, err)
+	assert.Equal(t, 0, numSetValueCalls)
+	require.Equal(t, 1, len(setValues))
+	assert.Contains(t, setValues[0], core.MetricHighestFinalBlock)
+	assert.Contains(t, setValues[0], core.MetricCommitBlockDurationMs)
+	assert.Contains(t, setValues[0], core.MetricCurrentBlockHash)
+}
+
+func TestShardProcessor_LastThrottleSuccessShouldReflectCommittedRound(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	hdr.Round = 7
+	currentMaxSize := uint32(1234)
+	succeededRound := uint64(0)
+
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.BlockSizeThrottler = &mock.BlockSizeThrottlerStub{
+		GetCurrentMaxSizeCalled: func() uint32 {
+			return currentMaxSize
+		},
+		SucceedCalled: func(round uint64) {
+			succeededRound = round
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	round, maxItems := sp.LastThrottleSuccess()
+	assert.Equal(t, uint64(0), round)
+	assert.Equal(t, uint32(0), maxItems)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+
+	round, maxItems = sp.LastThrottleSuccess()
+	assert.Equal(t, hdr.Round, succeededRound)
+	assert.Equal(t, hdr.Round, round)
+	assert.Equal(t, currentMaxSize, maxItems)
+}
+
+func TestShardProcessor_CommitBlockCallsIndexerMethods(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	txHash := []byte("tx_hash1")
+
+	rootHash := []byte("root hash")
+	hdrHash := []byte("header hash")
+	randSeed := []byte("rand seed")
+
+	prevHdr := &block.Header{
+		Nonce:         0,
+		Round:         0,
+		PubKeysBitmap: rootHash,
+		PrevHash:      hdrHash,
+		Signature:     rootHash,
+		RootHash:      rootHash,
+		RandSeed:      randSeed,
+	}
+
+	hdr := &block.Header{
+		Nonce:           1,
+		Round:           1,
+		PubKeysBitmap:   rootHash,
+		PrevHash:        hdrHash,
+		Signature:       rootHash,
+		RootHash:        rootHash,
+		PrevRandSeed:    randSeed,
+		AccumulatedFees: big.NewInt(0),
+		DeveloperFees:   big.NewInt(0),
+	}
+	mb := block.MiniBlock{
+		TxHashes: [][]byte{txHash},
+	}
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{&mb}}
+
+	mbHdr := block.MiniBlockHeader{
+		TxCount: uint32(len(mb.TxHashes)),
+		Hash:    hdrHash,
+	}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
+	hdr.MiniBlockHeaders = mbHdrs
+
+	accounts := &mock.AccountsStub{
+		CommitCalled: func() (i []byte, e error) {
+			return rootHash, nil
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+	}
+	fd := &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return nil
+		},
+	}
+	hasher := &mock.HasherStub{}
+	hasher.ComputeCalled = func(s string) []byte {
+		return hdrHash
+	}
+	store := initStore()
+
+	var saveBlockCalled map[string]data.TransactionHandler
+	saveBlockCalledMutex := sync.Mutex{}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Indexer = &mock.IndexerMock{
+		SaveBlockCalled: func(body data.BodyHandler, header data.HeaderHandler, txPool map[string]data.TransactionHandler) {
+			saveBlockCalledMutex.Lock()
+			saveBlockCalled = txPool
+			saveBlockCalledMutex.Unlock()
+		},
+	}
+	arguments.DataPool = tdp
+	arguments.Store = store
+	arguments.Hasher = hasher
+	arguments.AccountsDB[state.UserAccountsState] = accounts
+	arguments.ForkDetector = fd
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			switch blockType {
+			case block.TxBlock:
+				return map[string]data.TransactionHandler{
+					"tx_1": &transaction.Transaction{Nonce: 1},
+					"tx_2": &transaction.Transaction{Nonce: 2},
+				}
+			case block.SmartContractResultBlock:
+				return map[string]data.TransactionHandler{
+					"utx_1": &smartContractResult.SmartContractResult{Nonce: 1},
+					"utx_2": &smartContractResult.SmartContractResult{Nonce: 2},
+				}
+			default:
+				return nil
+			}
+		},
+	}
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	blockTrackerMock := mock.NewBlockTrackerMock(shardCoordinator, createGenesisBlocks(shardCoordinator))
+	blockTrackerMock.GetCrossNotarizedHeaderCalled = func(shardID uint32, offset uint64) (data.HeaderHandler, []byte, error) {
+		return &block.MetaBlock{}, []byte("hash"), nil
+	}
+	arguments.BlockTracker = blockTrackerMock
+	blkc := createTestBlockchain()
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return prevHdr
+	}
+	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
+		return hdrHash
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.Nil(t, err)
+	err = sp.CommitBlock(hdr, body)
+	assert.Nil(t, err)
+
+	// Wait for the index block go routine to start
+	time.Sleep(time.Second * 2)
+
+	saveBlockCalledMutex.Lock()
+	wasCalled := saveBlockCalled
+	saveBlockCalledMutex.Unlock()
+
+	assert.Equal(t, 4, len(wasCalled))
+}
+
+func TestShardProcessor_CreateTxBlockBodyWithDirtyAccStateShouldReturnEmptyBody(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	journalLen := func() int { return 3 }
+	revToSnapshot := func(snapshot int) error { return nil }
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled:       journalLen,
+		RevertToSnapshotCalled: revToSnapshot,
+	}
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	bl, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, &block.Body{}, bl)
+}
+
+func TestShardProcessor_CreateTxBlockBodyWithNoTimeShouldReturnEmptyBody(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	journalLen := func() int { return 0 }
+	rootHashfunc := func() ([]byte, error) {
+		return []byte("roothash"), nil
+	}
+	revToSnapshot := func(snapshot int) error { return nil }
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled:       journalLen,
+		RootHashCalled:         rootHashfunc,
+		RevertToSnapshotCalled: revToSnapshot,
+	}
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	haveTimeTrue := func() bool {
+		return false
+	}
+	bl, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, haveTimeTrue)
+	assert.Nil(t, err)
+	assert.Equal(t, &block.Body{}, bl)
+}
+
+func createBlockCreationPolicy(args blproc.ArgsBlockCreationPolicy) process.BlockCreationPolicyHandler {
+	blockCreationPolicy, _ := blproc.NewBlockCreationPolicy(args)
+	return blockCreationPolicy
+}
+
+func createArgumentsForEmptyPoolBlockBodyCreation(produceEmptyBlocks bool) blproc.ArgShardProcessor {
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = testscommon.NewPoolsHolderMock()
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled: func() int {
+			return 0
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return []byte("roothash"), nil
+		},
+	}
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: produceEmptyBlocks})
+
+	return arguments
+}
+
+func TestShardProcessor_CreateBlockBodyWithEmptyPoolAndProduceEmptyBlocksShouldReturnEmptyBody(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(createArgumentsForEmptyPoolBlockBodyCreation(true))
+
+	body, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, &block.Body{}, body)
+}
+
+func TestShardProcessor_CreateBlockBodyWithEmptyPoolAndNotProduceEmptyBlocksShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(createArgumentsForEmptyPoolBlockBodyCreation(false))
+
+	body, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Equal(t, process.ErrNoWorkToPropose, err)
+	assert.Nil(t, body)
+}
+
+func TestShardProcessor_BlockProductionSuccessRateShouldCountCommittedAttempts(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+
+	lastRatePercent := uint64(0)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: true})
+	sp, _ := blproc.NewShardProcessor(arguments)
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {
+			if key == core.MetricBlockProductionSuccessRatePercent {
+				atomic.StoreUint64(&lastRatePercent, value)
+			}
+		},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	assert.Equal(t, float64(0), sp.BlockProductionSuccessRate())
+
+	committedRounds := map[uint64]bool{1: true, 2: false, 3: true, 4: false}
+	for round := uint64(1); round <= 4; round++ {
+		_, err := sp.CreateBlockBody(&block.Header{Round: round, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+		require.Nil(t, err)
+
+		if !committedRounds[round] {
+			continue
+		}
+
+		hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+		hdr.Round = round
+		err = sp.CommitBlock(hdr, &block.Body{})
+		require.Nil(t, err)
+	}
+
+	assert.Equal(t, 0.5, sp.BlockProductionSuccessRate())
+	assert.Equal(t, uint64(50), atomic.LoadUint64(&lastRatePercent))
+
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	hdr.Round = 10
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	assert.Equal(t, 0.5, sp.BlockProductionSuccessRate())
+}
+
+func createArgumentsForPrecomputedMetaBlocks(
+	rounder *mock.RounderMock,
+	numComputeLongestMetaChainCalls *int,
+) blproc.ArgShardProcessor {
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.Rounder = rounder
+	arguments.BlockTracker = &mock.BlockTrackerMock{
+		GetLastCrossNotarizedHeaderCalled: func(shardID uint32) (data.HeaderHandler, []byte, error) {
+			return &block.MetaBlock{Nonce: 1}, []byte("last cross notarized hash"), nil
+		},
+		ComputeLongestMetaChainFromLastNotarizedCalled: func() ([]data.HeaderHandler, [][]byte, error) {
+			*numComputeLongestMetaChainCalls++
+			return make([]data.HeaderHandler, 0), make([][]byte, 0), nil
+		},
+	}
+
+	return arguments
+}
+
+func TestShardProcessor_CreateBlockBodyShouldUsePrecomputedMetaBlocksWhenValid(t *testing.T) {
+	t.Parallel()
+
+	numComputeLongestMetaChainCalls := 0
+	rounder := &mock.RounderMock{RoundIndex: 4}
+	sp, _ := blproc.NewShardProcessor(createArgumentsForPrecomputedMetaBlocks(rounder, &numComputeLongestMetaChainCalls))
+
+	sp.PrecomputeNextRoundMetaBlocks()
+	assert.Equal(t, 1, numComputeLongestMetaChainCalls)
+
+	rounder.RoundIndex = 5
+	_, err := sp.CreateBlockBody(&block.Header{Round: 5, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, 1, numComputeLongestMetaChainCalls)
+
+	_, err = sp.CreateBlockBody(&block.Header{Round: 5, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numComputeLongestMetaChainCalls)
+}
+
+func TestShardProcessor_CreateBlockBodyShouldRecomputeMetaBlocksWhenPrecomputedAreForAnotherRound(t *testing.T) {
+	t.Parallel()
+
+	numComputeLongestMetaChainCalls := 0
+	rounder := &mock.RounderMock{RoundIndex: 4}
+	sp, _ := blproc.NewShardProcessor(createArgumentsForPrecomputedMetaBlocks(rounder, &numComputeLongestMetaChainCalls))
+
+	sp.PrecomputeNextRoundMetaBlocks()
+	_, err := sp.CreateBlockBody(&block.Header{Round: 4, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numComputeLongestMetaChainCalls)
+}
+
+func TestShardProcessor_CreateBlockBodyShouldRecomputeMetaBlocksWhenNewMetaBlockIsReceived(t *testing.T) {
+	t.Parallel()
+
+	numComputeLongestMetaChainCalls := 0
+	rounder := &mock.RounderMock{RoundIndex: 4}
+	sp, _ := blproc.NewShardProcessor(createArgumentsForPrecomputedMetaBlocks(rounder, &numComputeLongestMetaChainCalls))
+
+	sp.PrecomputeNextRoundMetaBlocks()
+	rounder.RoundIndex = 5
+	sp.ReceivedMetaBlock(&block.MetaBlock{Nonce: 2, Round: 5}, []byte("new meta block hash"))
+
+	_, err := sp.CreateBlockBody(&block.Header{Round: 5, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, 2, numComputeLongestMetaChainCalls)
+}
+
+func TestShardProcessor_CreateBlockWithMalformedRootHashShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled: func() int {
+			return 0
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return []byte("short root"), nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr, body, err := sp.CreateBlock(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.True(t, errors.Is(err, process.ErrInvalidRootHashLength))
+	assert.Nil(t, hdr)
+	assert.Nil(t, body)
+}
+
+func TestShardProcessor_CreateBlockWithDuplicateMiniBlocksShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.Hasher = &mock.HasherMock{}
+	miniBlock := &block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{[]byte("tx_hash1")},
+	}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessTransactionsFromMeCalled: func(_ func() bool, _ uint64, _ uint64, _ uint32) block.MiniBlockSlice {
+			return block.MiniBlockSlice{miniBlock, miniBlock}
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr, body, err := sp.CreateBlock(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Equal(t, process.ErrDuplicateMiniBlockInBody, err)
+	assert.Nil(t, hdr)
+	assert.Nil(t, body)
+}
+
+func TestShardProcessor_CreateBlockWithValidRootHashShouldWork(t *testing.T) {
+	t.Parallel()
+
+	rootHash := make([]byte, (&mock.HasherMock{}).Size())
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled: func() int {
+			return 0
+		},
+		RootHashCalled: func() ([]byte, error) {
+			return rootHash, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr, _, err := sp.CreateBlock(&block.Header{PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	assert.Nil(t, err)
+	assert.Equal(t, rootHash, hdr.GetRootHash())
+}
+
+func TestShardProcessor_GetOrderedMetaBlocksShouldExcludeRoundInconsistentMetaBlocks(t *testing.T) {
+	t.Parallel()
+
+	lastCrossNotarizedMetaBlock := &block.MetaBlock{Nonce: 1, Round: 3}
+	metaBlock2 := &block.MetaBlock{Nonce: 2, Round: 5}
+	roundInconsistentMetaBlock3 := &block.MetaBlock{Nonce: 3, Round: 4}
+	metaBlock4 := &block.MetaBlock{Nonce: 4, Round: 6}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockTracker = &mock.BlockTrackerMock{
+		GetLastCrossNotarizedHeaderCalled: func(shardID uint32) (data.HeaderHandler, []byte, error) {
+			return lastCrossNotarizedMetaBlock, []byte("hash1"), nil
+		},
+		ComputeLongestMetaChainFromLastNotarizedCalled: func() ([]data.HeaderHandler, [][]byte, error) {
+			return []data.HeaderHandler{metaBlock2, roundInconsistentMetaBlock3, metaBlock4},
+				[][]byte{[]byte("hash2"), []byte("hash3"), []byte("hash4")},
+				nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	metaBlocks, metaBlocksHashes, err := sp.GetOrderedMetaBlocks()
+	require.Nil(t, err)
+	assert.Equal(t, []data.HeaderHandler{metaBlock2, metaBlock4}, metaBlocks)
+	assert.Equal(t, [][]byte{[]byte("hash2"), []byte("hash4")}, metaBlocksHashes)
+}
+
+func TestShardProcessor_CreateTxBlockBodyOK(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	journalLen := func() int { return 0 }
+	rootHashfunc := func() ([]byte, error) {
+		return []byte("roothash"), nil
+	}
+	haveTimeTrue := func() bool {
+		return true
+	}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		JournalLenCalled: journalLen,
+		RootHashCalled:   rootHashfunc,
+	}
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	blk, err := sp.CreateBlockBody(&block.Header{PrevRandSeed: []byte("randSeed")}, haveTimeTrue)
+	assert.NotNil(t, blk)
+	assert.Nil(t, err)
+}
+
+//------- ComputeNewNoncePrevHash
+
+func TestNode_ComputeNewNoncePrevHashShouldWork(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	marshalizer := &mock.MarshalizerStub{}
+	hasher := &mock.HasherStub{}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Store = initStore()
+	arguments.DataPool = tdp
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+
+	be, _ := blproc.NewShardProcessor(arguments)
+	hdr, txBlock := createTestHdrTxBlockBody()
+	marshalizer.MarshalCalled = func(obj interface{}) (bytes []byte, e error) {
+		if hdr == obj {
+			return []byte("hdrHeaderMarshalized"), nil
+		}
+		if reflect.DeepEqual(txBlock, obj) {
+			return []byte("txBlockBodyMarshalized"), nil
+		}
+		return nil, nil
+	}
+	hasher.ComputeCalled = func(s string) []byte {
+		if s == "hdrHeaderMarshalized" {
+			return []byte("header hash")
+		}
+		if s == "txBlockBodyMarshalized" {
+			return []byte("tx block body hash")
+		}
+		return nil
+	}
+	_, err := be.ComputeHeaderHash(hdr)
+	assert.Nil(t, err)
+}
+
+func createTestHdrTxBlockBody() (*block.Header, *block.Body) {
+	hasher := mock.HasherMock{}
+	hdr := &block.Header{
+		Nonce:         1,
+		ShardID:       2,
+		Epoch:         3,
+		Round:         4,
+		TimeStamp:     uint64(11223344),
+		PrevHash:      hasher.Compute("prev hash"),
+		PubKeysBitmap: []byte{255, 0, 128},
+		Signature:     hasher.Compute("signature"),
+		RootHash:      hasher.Compute("root hash"),
+	}
+	txBlock := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{
+				ReceiverShardID: 0,
+				SenderShardID:   0,
+				TxHashes: [][]byte{
+					hasher.Compute("txHash_0_1"),
+					hasher.Compute("txHash_0_2"),
+				},
+			},
+			{
+				ReceiverShardID: 1,
+				SenderShardID:   0,
+				TxHashes: [][]byte{
+					hasher.Compute("txHash_1_1"),
+					hasher.Compute("txHash_1_2"),
+				},
+			},
+			{
+				ReceiverShardID: 2,
+				SenderShardID:   0,
+				TxHashes: [][]byte{
+					hasher.Compute("txHash_2_1"),
+				},
+			},
+			{
+				ReceiverShardID: 3,
+				SenderShardID:   0,
+				TxHashes:        make([][]byte, 0),
+			},
+		},
+	}
+	return hdr, txBlock
+}
+
+//------- ComputeNewNoncePrevHash
+
+func TestShardProcessor_DisplayLogInfo(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	hasher := mock.HasherMock{}
+	hdr, txBlock := createTestHdrTxBlockBody()
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	statusHandler := &mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {
+
+		},
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	assert.NotNil(t, sp)
+	hdr.PrevHash = hasher.Compute("prev hash")
+	sp.DisplayLogInfo(hdr, txBlock, []byte("tx_hash1"), shardCoordinator.NumberOfShards(), shardCoordinator.SelfId(), tdp, statusHandler, &mock.BlockTrackerMock{})
+}
+
+func TestBlockProcessor_ApplyBodyToHeaderNilBodyError(t *testing.T) {
+	t.Parallel()
+	arguments := CreateMockArgumentsMultiShard()
+
+	bp, _ := blproc.NewShardProcessor(arguments)
+	hdr := &block.Header{}
+	_, err := bp.ApplyBodyToHeader(hdr, nil)
+	assert.Equal(t, process.ErrNilBlockBody, err)
+}
+
+func TestBlockProcessor_ApplyBodyToHeaderShouldNotReturnNil(t *testing.T) {
+	t.Parallel()
+	arguments := CreateMockArgumentsMultiShard()
+
+	bp, _ := blproc.NewShardProcessor(arguments)
+	hdr := &block.Header{}
+	_, err := bp.ApplyBodyToHeader(hdr, &block.Body{})
+	assert.Nil(t, err)
+	assert.NotNil(t, hdr)
+}
+
+func TestShardProcessor_ApplyBodyToHeaderShouldErrWhenMarshalizerErrors(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Marshalizer = &mock.MarshalizerMock{Fail: true}
+	bp, _ := blproc.NewShardProcessor(arguments)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{{
+			ReceiverShardID: 1,
+			SenderShardID:   0,
+			TxHashes:        make([][]byte, 0),
+		},
+			{
+				ReceiverShardID: 2,
+				SenderShardID:   0,
+				TxHashes:        make([][]byte, 0),
+			},
+			{
+				ReceiverShardID: 3,
+				SenderShardID:   0,
+				TxHashes:        make([][]byte, 0),
+			},
+		},
+	}
+	hdr := &block.Header{}
+	_, err := bp.ApplyBodyToHeader(hdr, body)
+	assert.NotNil(t, err)
+}
+
+func TestShardProcessor_ApplyBodyToHeaderReturnsOK(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	bp, _ := blproc.NewShardProcessor(arguments)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{
+				ReceiverShardID: 1,
+				SenderShardID:   0,
+				TxHashes:        make([][]byte, 0),
+			},
+			{
+				ReceiverShardID: 2,
+				SenderShardID:   0,
+				TxHashes:        make([][]byte, 0),
+			},
+			{
+				ReceiverShardID: 3,
+				SenderShardID:   0,
+				TxHashes:        make([][]byte, 0),
+			},
+		},
+	}
+	hdr := &block.Header{}
+	_, err := bp.ApplyBodyToHeader(hdr, body)
+	assert.Nil(t, err)
+	assert.Equal(t, len(body.MiniBlocks), len(hdr.MiniBlockHeaders))
+}
+
+func TestShardProcessor_ApplyBodyToHeaderWithRoundDifferentFromCreatedBodyRoundShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: true})
+	arguments.Hasher = &mock.HasherMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	bodyHandler, err := sp.CreateBlockBody(&block.Header{Round: 5, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	require.Nil(t, err)
+	body := bodyHandler.(*block.Body)
+
+	_, err = sp.ApplyBodyToHeader(&block.Header{Round: 6}, body)
+	assert.True(t, errors.Is(err, process.ErrRoundMismatchBetweenBodyAndHeader))
+
+	// the created body is forgotten after it has been checked
+	_, err = sp.ApplyBodyToHeader(&block.Header{Round: 6}, body)
+	assert.Nil(t, err)
+}
+
+func TestShardProcessor_ApplyBodyToHeaderWithCreatedBodyRoundShouldWork(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: true})
+	arguments.Hasher = &mock.HasherMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	bodyHandler, err := sp.CreateBlockBody(&block.Header{Round: 5, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	require.Nil(t, err)
+
+	_, err = sp.ApplyBodyToHeader(&block.Header{Round: 5}, bodyHandler.(*block.Body))
+	assert.Nil(t, err)
+}
+
+func TestShardProcessor_ApplyBodyToHeaderWithBodyNotCreatedByTheProcessorShouldNotCheckTheRound(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{ProduceEmptyBlocks: true})
+	arguments.Hasher = &mock.HasherMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, err := sp.CreateBlockBody(&block.Header{Round: 5, PrevRandSeed: []byte("randSeed")}, func() bool { return true })
+	require.Nil(t, err)
+
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{{SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("tx")}}}}
+	_, err = sp.ApplyBodyToHeader(&block.Header{Round: 6}, body)
+	assert.False(t, errors.Is(err, process.ErrRoundMismatchBetweenBodyAndHeader))
+}
+
+func TestShardProcessor_ApplyBodyToHeaderStrictHeaderValidationWithMissingTxShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.StrictHeaderValidation = true
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			return map[string]data.TransactionHandler{
+				"tx_hash1": &transaction.Transaction{Nonce: 1},
+			}
+		},
+	}
+	bp, _ := blproc.NewShardProcessor(arguments)
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{
+				ReceiverShardID: 1,
+				SenderShardID:   0,
+				TxHashes:        [][]byte{[]byte("tx_hash1"), []byte("missing tx hash")},
+			},
+		},
+	}
+	hdr := &block.Header{}
+	_, err := bp.ApplyBodyToHeader(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrHeaderReferencesMissingTxs))
+	assert.Equal(t, uint32(0), hdr.TxCount)
+
+	body.MiniBlocks[0].TxHashes = [][]byte{[]byte("tx_hash1")}
+	_, err = bp.ApplyBodyToHeader(hdr, body)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), hdr.TxCount)
+}
+
+func TestShardProcessor_AttestationCoverageMissingShardHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	attestations, err := sp.AttestationCoverage([]byte("missing header hash"))
+	assert.Nil(t, attestations)
+	assert.NotNil(t, err)
+}
+
+func createShardHeaderWithFinalityMetaBlocks() (*block.Header, []byte, map[string]*block.MetaBlock, [][]byte) {
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	shardHeader := &block.Header{Nonce: 1, Round: 1, ShardID: 0}
+	shardHeaderHash, _ := core.CalculateHash(marshalizer, hasher, shardHeader)
+	attestingMetaBlock := &block.MetaBlock{
+		Nonce:     1,
+		Round:     2,
+		RandSeed:  []byte("rand seed 1"),
+		ShardInfo: []block.ShardData{{ShardID: 0, HeaderHash: shardHeaderHash}},
+	}
+	attestingMetaHash, _ := core.CalculateHash(marshalizer, hasher, attestingMetaBlock)
+	finalityMetaBlock := &block.MetaBlock{
+		Nonce:        2,
+		Round:        3,
+		PrevHash:     attestingMetaHash,
+		PrevRandSeed: attestingMetaBlock.RandSeed,
+		RandSeed:     []byte("rand seed 2"),
+	}
+	finalityMetaHash, _ := core.CalculateHash(marshalizer, hasher, finalityMetaBlock)
+
+	metaBlocks := map[string]*block.MetaBlock{
+		string(attestingMetaHash): attestingMetaBlock,
+		string(finalityMetaHash):  finalityMetaBlock,
+	}
+
+	return shardHeader, shardHeaderHash, metaBlocks, [][]byte{attestingMetaHash, finalityMetaHash}
+}
+
+func createArgumentsForFinalityProof(
+	shardHeader *block.Header,
+	shardHeaderHash []byte,
+	metaBlocksInPool map[string]*block.MetaBlock,
+	lastCrossNotarizedMetaHash []byte,
+) blproc.ArgShardProcessor {
+	dataPool := testscommon.NewPoolsHolderMock()
+	dataPool.Headers().AddHeader(shardHeaderHash, shardHeader)
+	for hash, metaBlock := range metaBlocksInPool {
+		dataPool.Headers().AddHeader([]byte(hash), metaBlock)
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.Marshalizer = &mock.MarshalizerMock{}
+	arguments.DataPool = dataPool
+	arguments.BlockTracker = &mock.BlockTrackerMock{
+		GetLastCrossNotarizedHeaderCalled: func(shardID uint32) (data.HeaderHandler, []byte, error) {
+			return metaBlocksInPool[string(lastCrossNotarizedMetaHash)], lastCrossNotarizedMetaHash, nil
+		},
+	}
+
+	return arguments
+}
+
+func TestShardProcessor_FinalityProofWithoutFinalityMetaBlockShouldErr(t *testing.T) {
+	t.Parallel()
+
+	shardHeader, shardHeaderHash, metaBlocks, metaHashes := createShardHeaderWithFinalityMetaBlocks()
+	attestingMetaHash, finalityMetaHash := metaHashes[0], metaHashes[1]
+	delete(metaBlocks, string(finalityMetaHash))
+	arguments := createArgumentsForFinalityProof(shardHeader, shardHeaderHash, metaBlocks, attestingMetaHash)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	proof, err := sp.FinalityProof(shardHeaderHash)
+	assert.Nil(t, proof)
+	assert.True(t, errors.Is(err, process.ErrBlockNotFinal))
+}
+
+func TestShardProcessor_FinalityProofShouldWork(t *testing.T) {
+	t.Parallel()
+
+	shardHeader, shardHeaderHash, metaBlocks, metaHashes := createShardHeaderWithFinalityMetaBlocks()
+	attestingMetaHash, finalityMetaHash := metaHashes[0], metaHashes[1]
+	arguments := createArgumentsForFinalityProof(shardHeader, shardHeaderHash, metaBlocks, attestingMetaHash)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	proof, err := sp.FinalityProof(shardHeaderHash)
+	require.Nil(t, err)
+	assert.Equal(t, shardHeaderHash, proof.ShardHeaderHash)
+	assert.Equal(t, attestingMetaHash, proof.AttestingMetaHash)
+	assert.Equal(t, metaBlocks[string(attestingMetaHash)], proof.AttestingMetaBlock)
+	assert.Equal(t, [][]byte{finalityMetaHash}, proof.FinalityMetaHashes)
+	assert.Equal(t, []*block.MetaBlock{metaBlocks[string(finalityMetaHash)]}, proof.FinalityMetaBlocks)
+}
+
+func TestShardProcessor_CrossShardSettlementMapMissingShardHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	settlementMap, err := sp.CrossShardSettlementMap([]byte("missing header hash"))
+	assert.Nil(t, settlementMap)
+	assert.NotNil(t, err)
+}
+
+func createShardHeaderWithCrossShardMiniBlocks() (*block.Header, []byte, map[string]*block.MetaBlock, [][]byte) {
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	firstMetaBlock := &block.MetaBlock{
+		Nonce: 1,
+		ShardInfo: []block.ShardData{
+			{
+				ShardID: 1,
+				ShardMiniBlockHeaders: []block.MiniBlockHeader{
+					{Hash: []byte("mb from shard 1"), SenderShardID: 1, ReceiverShardID: 0},
+					{Hash: []byte("mb not processed"), SenderShardID: 1, ReceiverShardID: 0},
+				},
+			},
+		},
+	}
+	firstMetaHash, _ := core.CalculateHash(marshalizer, hasher, firstMetaBlock)
+	secondMetaBlock := &block.MetaBlock{
+		Nonce: 2,
+		ShardInfo: []block.ShardData{
+			{
+				ShardID: 1,
+				ShardMiniBlockHeaders: []block.MiniBlockHeader{
+					{Hash: []byte("mb from shard 1"), SenderShardID: 1, ReceiverShardID: 0},
+				},
+			},
+			{
+				ShardID: 2,
+				ShardMiniBlockHeaders: []block.MiniBlockHeader{
+					{Hash: []byte("mb from shard 2"), SenderShardID: 2, ReceiverShardID: 0},
+				},
+			},
+		},
+		MiniBlockHeaders: []block.MiniBlockHeader{
+			{Hash: []byte("mb from meta"), SenderShardID: core.MetachainShardId, ReceiverShardID: 0},
+		},
+	}
+	secondMetaHash, _ := core.CalculateHash(marshalizer, hasher, secondMetaBlock)
+
+	shardHeader := &block.Header{
+		Nonce:   1,
+		Round:   1,
+		ShardID: 0,
+		MiniBlockHeaders: []block.MiniBlockHeader{
+			{Hash: []byte("intra shard mb"), SenderShardID: 0, ReceiverShardID: 0},
+			{Hash: []byte("mb from shard 1"), SenderShardID: 1, ReceiverShardID: 0},
+			{Hash: []byte("mb from shard 2"), SenderShardID: 2, ReceiverShardID: 0},
+			{Hash: []byte("mb from meta"), SenderShardID: core.MetachainShardId, ReceiverShardID: 0},
+		},
+		MetaBlockHashes: [][]byte{firstMetaHash, secondMetaHash},
+	}
+	shardHeaderHash, _ := core.CalculateHash(marshalizer, hasher, shardHeader)
+
+	metaBlocks := map[string]*block.MetaBlock{
+		string(firstMetaHash):  firstMetaBlock,
+		string(secondMetaHash): secondMetaBlock,
+	}
+
+	return shardHeader, shardHeaderHash, metaBlocks, [][]byte{firstMetaHash, secondMetaHash}
+}
+
+func TestShardProcessor_CrossShardSettlementMapMissingMetaBlockShouldErr(t *testing.T) {
+	t.Parallel()
+
+	shardHeader, shardHeaderHash, metaBlocks, metaHashes := createShardHeaderWithCrossShardMiniBlocks()
+	delete(metaBlocks, string(metaHashes[1]))
+	arguments := createArgumentsForFinalityProof(shardHeader, shardHeaderHash, metaBlocks, metaHashes[0])
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	settlementMap, err := sp.CrossShardSettlementMap(shardHeaderHash)
+	assert.Nil(t, settlementMap)
+	assert.NotNil(t, err)
+}
+
+func TestShardProcessor_CrossShardSettlementMapShouldMapEachCrossMiniBlockToTheFirstConfirmingMetaBlock(t *testing.T) {
+	t.Parallel()
+
+	shardHeader, shardHeaderHash, metaBlocks, metaHashes := createShardHeaderWithCrossShardMiniBlocks()
+	firstMetaHash, secondMetaHash := metaHashes[0], metaHashes[1]
+	arguments := createArgumentsForFinalityProof(shardHeader, shardHeaderHash, metaBlocks, secondMetaHash)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	settlementMap, err := sp.CrossShardSettlementMap(shardHeaderHash)
+	require.Nil(t, err)
+
+	expectedSettlementMap := map[string]string{
+		"mb from shard 1": string(firstMetaHash),
+		"mb from shard 2": string(secondMetaHash),
+		"mb from meta":    string(secondMetaHash),
+	}
+	assert.Equal(t, expectedSettlementMap, settlementMap)
+}
+
+func TestShardProcessor_PendingCrossShardMiniBlocksShouldReturnFromMeCrossShardMiniBlocks(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	bp, _ := blproc.NewShardProcessor(arguments)
+
+	assert.Equal(t, 0, len(bp.PendingCrossShardMiniBlocks()))
+
+	mbToShard1 := &block.MiniBlock{ReceiverShardID: 1, SenderShardID: 0, TxHashes: [][]byte{[]byte("tx1")}}
+	mbToShard2 := &block.MiniBlock{ReceiverShardID: 2, SenderShardID: 0, TxHashes: [][]byte{[]byte("tx2")}}
+	mbToShard2Second := &block.MiniBlock{ReceiverShardID: 2, SenderShardID: 0, TxHashes: [][]byte{[]byte("tx3")}}
+	mbIntraShard := &block.MiniBlock{ReceiverShardID: 0, SenderShardID: 0, TxHashes: [][]byte{[]byte("tx4")}}
+	mbToMe := &block.MiniBlock{ReceiverShardID: 0, SenderShardID: 1, TxHashes: [][]byte{[]byte("tx5")}}
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{mbToMe, mbToShard1, mbIntraShard, mbToShard2, mbToShard2Second},
+	}
+
+	_, err := bp.ApplyBodyToHeader(&block.Header{}, body)
+	assert.Nil(t, err)
+
+	computeHash := func(mb *block.MiniBlock) []byte {
+		hash, _ := core.CalculateHash(arguments.Marshalizer, arguments.Hasher, mb)
+		return hash
+	}
+	expectedPendingMiniBlocks := map[uint32][][]byte{
+		1: {computeHash(mbToShard1)},
+		2: {computeHash(mbToShard2), computeHash(mbToShard2Second)},
+	}
+	assert.Equal(t, expectedPendingMiniBlocks, bp.PendingCrossShardMiniBlocks())
+}
+
+func TestShardProcessor_GetProcessedMiniBlocksInfoShouldReturnTheHexEncodedProcessedMiniBlocks(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+	assert.Equal(t, 0, len(sp.GetProcessedMiniBlocksInfo()))
+
+	processedMiniBlocks := processedMb.NewProcessedMiniBlocks()
+	processedMiniBlocks.AddMiniBlockHash("meta1", "mb1")
+	sp.ApplyProcessedMiniBlocks(processedMiniBlocks)
+
+	processedMiniBlocksInfo := sp.GetProcessedMiniBlocksInfo()
+	expectedProcessedMiniBlocksInfo := map[string][]string{
+		hex.EncodeToString([]byte("meta1")): {hex.EncodeToString([]byte("mb1"))},
+	}
+	assert.Equal(t, expectedProcessedMiniBlocksInfo, processedMiniBlocksInfo)
+
+	delete(processedMiniBlocksInfo, hex.EncodeToString([]byte("meta1")))
+	assert.True(t, processedMiniBlocks.IsMiniBlockProcessed("meta1", "mb1"))
+	assert.Equal(t, expectedProcessedMiniBlocksInfo, sp.GetProcessedMiniBlocksInfo())
+}
+
+func TestShardProcessor_TouchedAccountsNilBodyShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	touchedAccounts, err := sp.TouchedAccounts(nil)
+	assert.Equal(t, process.ErrNilBlockBody, err)
+	assert.Nil(t, touchedAccounts)
+}
+
+func TestShardProcessor_TouchedAccountsMissingTxShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			return make(map[string]data.TransactionHandler)
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{{Type: block.TxBlock, TxHashes: [][]byte{[]byte("tx hash")}}},
+	}
+	touchedAccounts, err := sp.TouchedAccounts(body)
+	assert.True(t, errors.Is(err, process.ErrMissingTransaction))
+	assert.Nil(t, touchedAccounts)
+}
+
+func TestShardProcessor_TouchedAccountsShouldReturnTheUniqueSendersAndReceivers(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		GetAllCurrentUsedTxsCalled: func(blockType block.Type) map[string]data.TransactionHandler {
+			switch blockType {
+			case block.TxBlock:
+				return map[string]data.TransactionHandler{
+					"tx1": &transaction.Transaction{SndAddr: []byte("alice"), RcvAddr: []byte("bob")},
+					"tx2": &transaction.Transaction{SndAddr: []byte("bob"), RcvAddr: []byte("carol")},
+				}
+			case block.RewardsBlock:
+				return map[string]data.TransactionHandler{
+					"reward": &rewardTx.RewardTx{RcvAddr: []byte("alice")},
+				}
+			default:
+				return nil
+			}
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{Type: block.TxBlock, TxHashes: [][]byte{[]byte("tx1"), []byte("tx2")}},
+			{Type: block.RewardsBlock, TxHashes: [][]byte{[]byte("reward")}},
+			{Type: block.PeerBlock, TxHashes: [][]byte{[]byte("peer change")}},
+		},
+	}
+	touchedAccounts, err := sp.TouchedAccounts(body)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("alice"), []byte("bob"), []byte("carol")}, touchedAccounts)
+}
+
+func TestShardProcessor_CommitBlockWithMissingParentHeaderShouldErr(t *testing.T) {
+	t.Parallel()
+
+	prevHash := []byte("missing parent hash")
+	randSeed := []byte("rand seed")
+	prevHdr := &block.Header{
+		Nonce:    1,
+		Round:    1,
+		RandSeed: randSeed,
+	}
+	hdr := &block.Header{
+		Nonce:        2,
+		Round:        2,
+		PrevHash:     prevHash,
+		PrevRandSeed: randSeed,
+	}
+
+	revertCalled := false
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Store = initStore()
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		RevertToSnapshotCalled: func(snapshot int) error {
+			revertCalled = true
+			return nil
+		},
+	}
+	blkc := createTestBlockchain()
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return prevHdr
+	}
+	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
+		return prevHash
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	assert.True(t, errors.Is(err, process.ErrMissingParentHeader))
+	assert.True(t, revertCalled)
+}
+
+func TestShardProcessor_CommitBlockWithParentHeaderOnlyInPoolShouldErr(t *testing.T) {
+	t.Parallel()
+
+	prevHash := []byte("parent hash in pool")
+	randSeed := []byte("rand seed")
+	prevHdr := &block.Header{
+		Nonce:    1,
+		Round:    1,
+		RandSeed: randSeed,
+	}
+	hdr := &block.Header{
+		Nonce:        2,
+		Round:        2,
+		PrevHash:     prevHash,
+		PrevRandSeed: randSeed,
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Store = initStore()
+	dataPool := initDataPool([]byte("tx_hash1"))
+	headersPool := dataPool.Headers().(*mock.HeadersCacherStub)
+	headersPool.GetHeaderByHashCalled = func(hash []byte) (data.HeaderHandler, error) {
+		if bytes.Equal(hash, prevHash) {
+			return prevHdr, nil
+		}
+		return nil, process.ErrMissingHeader
+	}
+	dataPool.HeadersCalled = func() dataRetriever.HeadersPool {
+		return headersPool
+	}
+	arguments.DataPool = dataPool
+	blkc := createTestBlockchain()
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return prevHdr
+	}
+	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
+		return prevHash
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	assert.True(t, errors.Is(err, process.ErrMissingParentHeader))
+}
+
+func TestShardProcessor_CommitBlockWithParentHeaderAsCurrentBlockHeaderShouldNotErrMissingParent(t *testing.T) {
+	t.Parallel()
+
+	randSeed := []byte("rand seed")
+	prevHdr := &block.Header{
+		Nonce:    1,
+		Round:    1,
+		RandSeed: randSeed,
+	}
+	prevHash, _ := core.CalculateHash(&mock.MarshalizerMock{}, &mock.HasherMock{}, prevHdr)
+	hdr := &block.Header{
+		Nonce:        2,
+		Round:        2,
+		PrevHash:     prevHash,
+		PrevRandSeed: randSeed,
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.Marshalizer = &mock.MarshalizerMock{}
+	arguments.Store = initStore()
+	blkc := createTestBlockchain()
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return prevHdr
+	}
+	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
+		return prevHash
+	}
+	arguments.BlockChain = blkc
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CommitBlock(hdr, &block.Body{})
+	assert.False(t, errors.Is(err, process.ErrMissingParentHeader))
+}
+
+func TestShardProcessor_CommitBlockShouldRevertAccountStateWhenErr(t *testing.T) {
+	t.Parallel()
+	// set accounts dirty
+	journalEntries := 3
+	revToSnapshot := func(snapshot int) error {
+		journalEntries = 0
+		return nil
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		RevertToSnapshotCalled: revToSnapshot,
+	}
+	bp, _ := blproc.NewShardProcessor(arguments)
+	err := bp.CommitBlock(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, journalEntries)
+}
+
+func TestShardProcessor_MarshalizedDataToBroadcastShouldWork(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	txHash0 := []byte("txHash0")
+	mb0 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{txHash0},
+	}
+	txHash1 := []byte("txHash1")
+	mb1 := block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{txHash1},
+	}
+	body := &block.Body{}
+	body.MiniBlocks = append(body.MiniBlocks, &mb0)
+	body.MiniBlocks = append(body.MiniBlocks, &mb1)
+	body.MiniBlocks = append(body.MiniBlocks, &mb0)
+	body.MiniBlocks = append(body.MiniBlocks, &mb1)
+	marshalizer := &mock.MarshalizerMock{
+		Fail: false,
+	}
+
+	factory, _ := shard.NewPreProcessorsContainerFactory(
+		mock.NewMultiShardsCoordinatorMock(3),
+		initStore(),
+		marshalizer,
+		&mock.HasherMock{},
+		tdp,
+		createMockPubkeyConverter(),
+		initAccountsMock(),
+		&mock.RequestHandlerStub{},
+		&mock.TxProcessorMock{},
+		&mock.SCProcessorMock{},
+		&mock.SmartContractResultsProcessorMock{},
+		&mock.RewardTxProcessorMock{},
+		&mock.FeeHandlerStub{},
+		&mock.GasHandlerMock{},
+		&mock.BlockTrackerMock{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+	)
+	container, _ := factory.Create()
+
+	tc, err := coordinator.NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		initAccountsMock(),
+		tdp.MiniBlocks(),
+		&mock.RequestHandlerStub{},
+		container,
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizer
+	arguments.TxCoordinator = tc
+	sp, _ := blproc.NewShardProcessor(arguments)
+	msh, mstx, err := sp.MarshalizedDataToBroadcast(&block.Header{}, body)
+	assert.Nil(t, err)
+	assert.NotNil(t, msh)
+	assert.NotNil(t, mstx)
+	_, found := msh[0]
+	assert.False(t, found)
+
+	bh := &block.Body{}
+	err = marshalizer.Unmarshal(bh, msh[1])
+	assert.Nil(t, err)
+	assert.Equal(t, len(bh.MiniBlocks), 2)
+	assert.Equal(t, &mb1, bh.MiniBlocks[0])
+	assert.Equal(t, &mb1, bh.MiniBlocks[1])
+}
+
+func TestShardProcessor_MarshalizedDataToBroadcastShouldSortTheMiniBlocksOfEachShard(t *testing.T) {
+	t.Parallel()
+
+	marshalizer := &mock.MarshalizerMock{}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Marshalizer = marshalizer
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	scrMbToShard1 := &block.MiniBlock{Type: block.SmartContractResultBlock, SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("scr")}}
+	txMbToShard1 := &block.MiniBlock{Type: block.TxBlock, SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("tx1")}}
+	secondTxMbToShard1 := &block.MiniBlock{Type: block.TxBlock, SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("tx2")}}
+	txMbToShard2 := &block.MiniBlock{Type: block.TxBlock, SenderShardID: 0, ReceiverShardID: 2, TxHashes: [][]byte{[]byte("tx3")}}
+
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{scrMbToShard1, secondTxMbToShard1, txMbToShard2, txMbToShard1}}
+	reversedBody := &block.Body{MiniBlocks: []*block.MiniBlock{txMbToShard1, txMbToShard2, secondTxMbToShard1, scrMbToShard1}}
+
+	mrsData, _, err := sp.MarshalizedDataToBroadcast(&block.Header{}, body)
+	assert.Nil(t, err)
+	mrsDataFromReversedBody, _, err := sp.MarshalizedDataToBroadcast(&block.Header{}, reversedBody)
+	assert.Nil(t, err)
+	assert.Equal(t, mrsData, mrsDataFromReversedBody)
+
+	bodyForShard1 := &block.Body{}
+	err = marshalizer.Unmarshal(bodyForShard1, mrsData[1])
+	assert.Nil(t, err)
+	require.Equal(t, 3, len(bodyForShard1.MiniBlocks))
+	assert.Equal(t, block.TxBlock, bodyForShard1.MiniBlocks[0].Type)
+	assert.Equal(t, block.TxBlock, bodyForShard1.MiniBlocks[1].Type)
+	assert.Equal(t, scrMbToShard1, bodyForShard1.MiniBlocks[2])
+
+	firstTxMbHash, _ := core.CalculateHash(marshalizer, arguments.Hasher, bodyForShard1.MiniBlocks[0])
+	secondTxMbHash, _ := core.CalculateHash(marshalizer, arguments.Hasher, bodyForShard1.MiniBlocks[1])
+	assert.True(t, bytes.Compare(firstTxMbHash, secondTxMbHash) < 0)
+
+	assert.Equal(t, []*block.MiniBlock{scrMbToShard1, secondTxMbToShard1, txMbToShard2, txMbToShard1}, body.MiniBlocks)
+}
+
+func TestShardProcessor_MarshalizedDataWrongType(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	marshalizer := &mock.MarshalizerMock{
+		Fail: false,
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizer
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	wr := &wrongBody{}
+	msh, mstx, err := sp.MarshalizedDataToBroadcast(&block.Header{}, wr)
+	assert.Equal(t, process.ErrWrongTypeAssertion, err)
+	assert.Nil(t, msh)
+	assert.Nil(t, mstx)
+}
+
+func TestShardProcessor_MarshalizedDataNilInput(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	marshalizer := &mock.MarshalizerMock{
+		Fail: false,
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizer
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	msh, mstx, err := sp.MarshalizedDataToBroadcast(nil, nil)
+	assert.Equal(t, process.ErrNilMiniBlocks, err)
+	assert.Nil(t, msh)
+	assert.Nil(t, mstx)
+}
+
+func TestShardProcessor_MarshalizedDataMarshalWithoutSuccess(t *testing.T) {
+	t.Parallel()
+	wasCalled := false
+	tdp := initDataPool([]byte("tx_hash1"))
+	txHash0 := []byte("txHash0")
+	mb0 := block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{txHash0},
+	}
+	body := &block.Body{}
+	body.MiniBlocks = append(body.MiniBlocks, &mb0)
+	marshalizer := &mock.MarshalizerStub{
+		MarshalCalled: func(obj interface{}) ([]byte, error) {
+			wasCalled = true
+			return nil, process.ErrMarshalWithoutSuccess
+		},
+	}
+
+	factory, _ := shard.NewPreProcessorsContainerFactory(
+		mock.NewMultiShardsCoordinatorMock(3),
+		initStore(),
+		&mock.MarshalizerMock{},
+		&mock.HasherMock{},
+		tdp,
+		createMockPubkeyConverter(),
+		initAccountsMock(),
+		&mock.RequestHandlerStub{},
+		&mock.TxProcessorMock{},
+		&mock.SCProcessorMock{},
+		&mock.SmartContractResultsProcessorMock{},
+		&mock.RewardTxProcessorMock{},
+		&mock.FeeHandlerStub{},
+		&mock.GasHandlerMock{},
+		&mock.BlockTrackerMock{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+	)
+	container, _ := factory.Create()
+
+	tc, err := coordinator.NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		initAccountsMock(),
+		tdp.MiniBlocks(),
+		&mock.RequestHandlerStub{},
+		container,
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizer
+	arguments.TxCoordinator = tc
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	msh, mstx, err := sp.MarshalizedDataToBroadcast(&block.Header{}, body)
+	assert.Nil(t, err)
+	assert.True(t, wasCalled)
+	assert.Equal(t, 0, len(msh))
+	assert.Equal(t, 0, len(mstx))
+}
+
+//------- receivedMetaBlock
+
+func TestShardProcessor_ReceivedMetaBlockShouldRequestMissingMiniBlocks(t *testing.T) {
+	t.Parallel()
+
+	hasher := mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	datapool := testscommon.NewPoolsHolderMock()
+
+	//we will have a metablock that will return 3 miniblock hashes
+	//1 miniblock hash will be in cache
+	//2 will be requested on network
+
+	miniBlockHash1 := []byte("miniblock hash 1 found in cache")
+	miniBlockHash2 := []byte("miniblock hash 2")
+	miniBlockHash3 := []byte("miniblock hash 3")
+
+	metaBlock := &block.MetaBlock{
+		Nonce: 1,
+		Round: 1,
+		ShardInfo: []block.ShardData{
+			{
+				ShardID: 1,
+				ShardMiniBlockHeaders: []block.MiniBlockHeader{
+					{Hash: miniBlockHash1, SenderShardID: 1, ReceiverShardID: 0},
+					{Hash: miniBlockHash2, SenderShardID: 1, ReceiverShardID: 0},
+					{Hash: miniBlockHash3, SenderShardID: 1, ReceiverShardID: 0},
+				}},
+		}}
+
+	//put this metaBlock inside datapool
+	metaBlockHash := []byte("metablock hash")
+	datapool.Headers().AddHeader(metaBlockHash, metaBlock)
+	//put the existing miniblock inside datapool
+	datapool.MiniBlocks().Put(miniBlockHash1, &block.MiniBlock{}, 0)
+
+	miniBlockHash1Requested := int32(0)
+	miniBlockHash2Requested := int32(0)
+	miniBlockHash3Requested := int32(0)
+
+	requestHandler := &mock.RequestHandlerStub{
+		RequestMiniBlockHandlerCalled: func(destShardID uint32, miniblockHash []byte) {
+			if bytes.Equal(miniBlockHash1, miniblockHash) {
+				atomic.AddInt32(&miniBlockHash1Requested, 1)
+			}
+			if bytes.Equal(miniBlockHash2, miniblockHash) {
+				atomic.AddInt32(&miniBlockHash2Requested, 1)
+			}
+			if bytes.Equal(miniBlockHash3, miniblockHash) {
+				atomic.AddInt32(&miniBlockHash3Requested, 1)
+			}
+		},
+	}
+
+	tc, _ := coordinator.NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		initAccountsMock(),
+		datapool.MiniBlocks(),
+		requestHandler,
+		&mock.PreProcessorContainerMock{},
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.RequestHandler = requestHandler
+	arguments.TxCoordinator = tc
+
+	bp, _ := blproc.NewShardProcessor(arguments)
+	bp.ReceivedMetaBlock(metaBlock, metaBlockHash)
+
+	//we have to wait to be sure txHash1Requested is not incremented by a late call
+	time.Sleep(core.ExtraDelayForRequestBlockInfo + time.Second)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&miniBlockHash1Requested))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&miniBlockHash2Requested))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&miniBlockHash2Requested))
+}
+
+//--------- receivedMetaBlockNoMissingMiniBlocks
+func TestShardProcessor_ReceivedMetaBlockNoMissingMiniBlocksShouldPass(t *testing.T) {
+	t.Parallel()
+
+	hasher := mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	datapool := testscommon.NewPoolsHolderMock()
+
+	//we will have a metablock that will return 3 miniblock hashes
+	//1 miniblock hash will be in cache
+	//2 will be requested on network
+
+	miniBlockHash1 := []byte("miniblock hash 1 found in cache")
+
+	metaBlock := &block.MetaBlock{
+		Nonce: 1,
+		Round: 1,
+		ShardInfo: []block.ShardData{
+			{
+				ShardID: 1,
+				ShardMiniBlockHeaders: []block.MiniBlockHeader{
+					{
+						Hash:            miniBlockHash1,
+						SenderShardID:   1,
+						ReceiverShardID: 0,
+					},
+				},
+			},
+		}}
+
+	//put this metaBlock inside datapool
+	metaBlockHash := []byte("metablock hash")
+	datapool.Headers().AddHeader(metaBlockHash, metaBlock)
+	//put the existing miniblock inside datapool
+	datapool.MiniBlocks().Put(miniBlockHash1, &block.MiniBlock{}, 0)
+
+	noOfMissingMiniBlocks := int32(0)
+
+	requestHandler := &mock.RequestHandlerStub{
+		RequestMiniBlockHandlerCalled: func(destShardID uint32, miniblockHash []byte) {
+			atomic.AddInt32(&noOfMissingMiniBlocks, 1)
+		},
+	}
+
+	tc, _ := coordinator.NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		initAccountsMock(),
+		datapool.MiniBlocks(),
+		requestHandler,
+		&mock.PreProcessorContainerMock{},
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.RequestHandler = requestHandler
+	arguments.TxCoordinator = tc
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	sp.ReceivedMetaBlock(metaBlock, metaBlockHash)
+
+	//we have to wait to be sure txHash1Requested is not incremented by a late call
+	time.Sleep(core.ExtraDelayForRequestBlockInfo + time.Second)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&noOfMissingMiniBlocks))
+}
+
+//--------- createAndProcessCrossMiniBlocksDstMe
+func TestShardProcessor_CreateAndProcessCrossMiniBlocksDstMe(t *testing.T) {
+	t.Parallel()
+
+	tdp := testscommon.NewPoolsHolderMock()
+	txHash := []byte("tx_hash1")
+	tdp.Transactions().AddData(txHash, &transaction.Transaction{}, 0, process.ShardCacherIdentifier(1, 0))
+
+	hasher := &mock.HasherStub{}
+	marshalizer := &mock.MarshalizerMock{}
+
+	meta := &block.MetaBlock{
+		Nonce:        1,
+		ShardInfo:    make([]block.ShardData, 0),
+		Round:        1,
+		PrevRandSeed: []byte("roothash"),
+	}
+	metaBytes, _ := marshalizer.Marshal(meta)
+	metaHash := hasher.Compute(string(metaBytes))
+
+	tdp.Headers().AddHeader(metaHash, meta)
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	sp, _ := blproc.NewShardProcessor(arguments)
+	miniBlockSlice, usedMetaHdrsHashes, noOfTxs, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Equal(t, err == nil, true)
+	assert.Equal(t, len(miniBlockSlice) == 0, true)
+	assert.Equal(t, usedMetaHdrsHashes, uint32(0))
+	assert.Equal(t, noOfTxs, uint32(0))
+}
+
+func TestShardProcessor_CreateAndProcessCrossMiniBlocksDstMeProcessPartOfMiniBlocksInMetaBlock(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+	tdp := testscommon.NewPoolsHolderMock()
+	destShardId := uint32(2)
+
+	hasher := &mock.HasherStub{}
+	marshalizer := &mock.MarshalizerMock{}
+	miniblocks := make([]*block.MiniBlock, 6)
+
+	txHash := []byte("txhash")
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+
+	miniblock1 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
+	}
+	miniblock2 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   2,
+		TxHashes:        txHashes,
+	}
+
+	miniBlocks := make([]block.MiniBlock, 0)
+	miniBlocks = append(miniBlocks, miniblock1, miniblock2)
+
+	destShards := []uint32{1, 3, 4}
+	for i := 0; i < 6; i++ {
+		miniblocks[i], _ = createDummyMiniBlock(fmt.Sprintf("tx hash %d", i), marshalizer, hasher, destShardId, destShards[i/2])
+	}
+
+	//put 2 metablocks in pool
+	meta := &block.MetaBlock{
+		Nonce:        1,
+		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks),
+		Round:        1,
+		PrevRandSeed: []byte("roothash"),
+	}
+
+	mb1Hash := []byte("meta block 1")
+	tdp.Headers().AddHeader(mb1Hash, meta)
+
+	meta = &block.MetaBlock{
+		Nonce:     2,
+		ShardInfo: createShardData(hasher, marshalizer, miniBlocks),
+		Round:     2,
+	}
+
+	mb2Hash := []byte("meta block 2")
+	tdp.Headers().AddHeader(mb2Hash, meta)
+
+	meta = &block.MetaBlock{
+		Nonce:        3,
+		ShardInfo:    make([]block.ShardData, 0),
+		Round:        3,
+		PrevRandSeed: []byte("roothash"),
+	}
+
+	mb3Hash := []byte("meta block 3")
+	tdp.Headers().AddHeader(mb3Hash, meta)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	miniBlocksReturned, usedMetaHdrsHashes, nrTxAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+
+	assert.Equal(t, 0, len(miniBlocksReturned))
+	assert.Equal(t, uint32(0), usedMetaHdrsHashes)
+	assert.Equal(t, uint32(0), nrTxAdded)
+	assert.Nil(t, err)
+}
+
+func createShardProcessorArgsWithOneEmptyMetaBlock() blproc.ArgShardProcessor {
+	emptyMetaBlock := &block.MetaBlock{
+		Nonce:     1,
+		Round:     1,
+		ShardInfo: make([]block.ShardData, 0),
+	}
+	emptyMetaBlockHash := []byte("empty meta block hash")
+
+	arguments := CreateMockArgumentsMultiShard()
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{emptyMetaBlock}, [][]byte{emptyMetaBlockHash}
+	}
+	arguments.BlockTracker = blockTracker
+
+	return arguments
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldIncludeEmptyMetaBlocks(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	arguments := createShardProcessorArgsWithOneEmptyMetaBlock()
+	arguments.IncludeEmptyAttestedMetaBlocks = true
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	miniBlocks, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(miniBlocks))
+	assert.Equal(t, uint32(1), hdrsAdded)
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldExcludeEmptyMetaBlocks(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	arguments := createShardProcessorArgsWithOneEmptyMetaBlock()
+	arguments.IncludeEmptyAttestedMetaBlocks = false
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	miniBlocks, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(miniBlocks))
+	assert.Equal(t, uint32(0), hdrsAdded)
+}
+
+func createMetaBlockWithOneMiniBlockDstMe(nonce uint64, miniBlockHash []byte, numTxs uint32) *block.MetaBlock {
+	return &block.MetaBlock{
+		Nonce: nonce,
+		Round: nonce,
+		ShardInfo: []block.ShardData{
+			{
+				ShardID: 1,
+				ShardMiniBlockHeaders: []block.MiniBlockHeader{
+					{
+						Hash:            miniBlockHash,
+						SenderShardID:   1,
+						ReceiverShardID: 0,
+						TxCount:         numTxs,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldSelectBestFillingMetaBlockUnderCapacityPressure(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	metaBlockLowFill := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb low fill"), 3)
+	metaBlockLowFillHash := []byte("meta block low fill")
+	metaBlockTooBig := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb too big"), 20)
+	metaBlockTooBigHash := []byte("meta block too big")
+	metaBlockHighFill := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb high fill"), 8)
+	metaBlockHighFillHash := []byte("meta block high fill")
+	nextMetaBlock := &block.MetaBlock{Nonce: 2, Round: 2}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.WeightedMetaBlockSelectionMaxTxs = 10
+	arguments.HeaderValidator = &mock.HeaderConstructionValidatorStub{}
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlockTooBig}, [][]byte{metaBlockTooBigHash}
+	}
+	blockTracker.GetTrackedHeadersWithNonceCalled = func(shardID uint32, nonce uint64) ([]data.HeaderHandler, [][]byte) {
+		if nonce == 1 {
+			return []data.HeaderHandler{metaBlockTooBig, metaBlockLowFill, metaBlockHighFill},
+				[][]byte{metaBlockTooBigHash, metaBlockLowFillHash, metaBlockHighFillHash}
+		}
+		if nonce == 2 {
+			return []data.HeaderHandler{nextMetaBlock}, [][]byte{[]byte("next meta block")}
+		}
+
+		return nil, nil
+	}
+	arguments.BlockTracker = blockTracker
+
+	var processedMetaBlock data.HeaderHandler
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			processedMetaBlock = header
+			return block.MiniBlockSlice{&block.MiniBlock{}}, header.(*block.MetaBlock).ShardInfo[0].ShardMiniBlockHeaders[0].TxCount, true, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, txsAdded, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Nil(t, err)
+	assert.True(t, processedMetaBlock == metaBlockHighFill)
+	assert.Equal(t, uint32(8), txsAdded)
+	assert.Equal(t, uint32(1), hdrsAdded)
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldKeepOrderWithoutCapacityPressure(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	metaBlock := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb"), 20)
+	metaBlockHash := []byte("meta block")
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.WeightedMetaBlockSelectionMaxTxs = 0
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock}, [][]byte{metaBlockHash}
+	}
+	blockTracker.GetTrackedHeadersWithNonceCalled = func(shardID uint32, nonce uint64) ([]data.HeaderHandler, [][]byte) {
+		assert.Fail(t, "should not search for other meta blocks")
+		return nil, nil
+	}
+	arguments.BlockTracker = blockTracker
+
+	var processedMetaBlock data.HeaderHandler
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			processedMetaBlock = header
+			return block.MiniBlockSlice{&block.MiniBlock{}}, 20, true, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Nil(t, err)
+	assert.True(t, processedMetaBlock == metaBlock)
+	assert.Equal(t, uint32(1), hdrsAdded)
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldStopWhenMaxAttestedMetaBlocksIsReached(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	metaBlock1 := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb 1"), 1)
+	metaBlock2 := createMetaBlockWithOneMiniBlockDstMe(2, []byte("mb 2"), 1)
+	metaBlock3 := createMetaBlockWithOneMiniBlockDstMe(3, []byte("mb 3"), 1)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MaxAttestedMetaBlocksPerBlock = 2
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock1, metaBlock2, metaBlock3},
+			[][]byte{[]byte("meta block 1"), []byte("meta block 2"), []byte("meta block 3")}
+	}
+	arguments.BlockTracker = blockTracker
+
+	processedMetaBlocks := make([]data.HeaderHandler, 0)
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			processedMetaBlocks = append(processedMetaBlocks, header)
+			return block.MiniBlockSlice{&block.MiniBlock{}}, 1, true, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), hdrsAdded)
+	assert.Equal(t, []data.HeaderHandler{metaBlock1, metaBlock2}, processedMetaBlocks)
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldSaveMetaBlockAttestationEfficiency(t *testing.T) {
+	t.Parallel()
+
+	haveTimeTrue := func() bool {
+		return true
+	}
+
+	numAvailableMetaBlocks := 5
+	metaBlocks := make([]data.HeaderHandler, 0, numAvailableMetaBlocks)
+	metaBlocksHashes := make([][]byte, 0, numAvailableMetaBlocks)
+	for i := 1; i <= numAvailableMetaBlocks; i++ {
+		metaBlocks = append(metaBlocks, createMetaBlockWithOneMiniBlockDstMe(uint64(i), []byte(fmt.Sprintf("mb %d", i)), 1))
+		metaBlocksHashes = append(metaBlocksHashes, []byte(fmt.Sprintf("meta block %d", i)))
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MaxAttestedMetaBlocksPerBlock = 2
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return metaBlocks, metaBlocksHashes
+	}
+	arguments.BlockTracker = blockTracker
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			return block.MiniBlockSlice{&block.MiniBlock{}}, 1, true, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	efficiency := ""
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		SetStringValueHandler: func(key string, value string) {
+			if key == core.MetricMetaBlockAttestationEfficiency {
+				efficiency = value
+			}
+		},
+	})
+
+	_, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), hdrsAdded)
+	assert.Equal(t, "0.40", efficiency)
+}
+
+func TestShardProcessor_LastAttestationDecisionsShouldRecordNotFinalMetaBlocks(t *testing.T) {
+	t.Parallel()
+
+	metaBlock1 := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb 1"), 1)
+	metaBlock2 := createMetaBlockWithOneMiniBlockDstMe(2, []byte("mb 2"), 1)
+
+	arguments := CreateMockArgumentsMultiShard()
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock1}, [][]byte{[]byte("meta block 1")}
+	}
+	blockTracker.GetTrackedHeadersCalled = func(shardID uint32) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock1, metaBlock2}, [][]byte{[]byte("meta block 1"), []byte("meta block 2")}
+	}
+	arguments.BlockTracker = blockTracker
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			return block.MiniBlockSlice{&block.MiniBlock{}}, 1, true, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(func() bool { return true })
+	require.Nil(t, err)
+	assert.Equal(t, uint32(1), hdrsAdded)
+
+	decisions := sp.LastAttestationDecisions()
+	assert.Equal(t, map[string]string{"meta block 2": "meta block is not final"}, decisions)
+}
+
+func TestShardProcessor_CreateAndProcessMiniBlocksDstMeShouldNotAttestMetaBlockWithMissingSelfShardHeader(t *testing.T) {
+	t.Parallel()
+
+	metaBlock := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb 1"), 1)
+	metaBlock.ShardInfo = append(metaBlock.ShardInfo, block.ShardData{
+		ShardID:    0,
+		HeaderHash: []byte("missing self shard header"),
+	})
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.CheckAttestedShardInfo = true
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock}, [][]byte{[]byte("meta block 1")}
+	}
+	arguments.BlockTracker = blockTracker
+	chRequested := make(chan []byte, 1)
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestShardHeaderCalled: func(shardID uint32, hash []byte) {
+			chRequested <- hash
+		},
+	}
+	processCalled := false
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			processCalled = true
+			return block.MiniBlockSlice{&block.MiniBlock{}}, 1, true, nil
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, _, hdrsAdded, err := sp.CreateAndProcessMiniBlocksDstMe(func() bool { return true })
+	require.Nil(t, err)
+	assert.Equal(t, uint32(0), hdrsAdded)
+	assert.False(t, processCalled)
+
+	select {
+	case hash := <-chRequested:
+		assert.Equal(t, []byte("missing self shard header"), hash)
+	case <-time.After(time.Second):
+		assert.Fail(t, "missing self shard header should have been requested")
+	}
+
+	decisions := sp.LastAttestationDecisions()
+	assert.Equal(t, "self shard headers referenced by meta block are not available", decisions["meta block 1"])
+}
+
+func TestShardProcessor_CreateMiniBlocksShouldStopWhenMaxBlockBodyBytesIsReached(t *testing.T) {
+	t.Parallel()
+
+	metaBlock1 := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb 1"), 100)
+	metaBlock2 := createMetaBlockWithOneMiniBlockDstMe(2, []byte("mb 2"), 100)
+
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{
+		MaxBlockBodyBytes:  1000,
+		ProduceEmptyBlocks: true,
+	})
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock1, metaBlock2}, [][]byte{[]byte("meta block 1"), []byte("meta block 2")}
+	}
+	arguments.BlockTracker = blockTracker
+
+	mbPostProcess := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1, Type: block.SmartContractResultBlock}
+	processedMetaBlocks := make([]data.HeaderHandler, 0)
+	createMbsFromMeCalled := false
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			processedMetaBlocks = append(processedMetaBlocks, header)
+
+			largeMiniBlock := &block.MiniBlock{SenderShardID: 1, ReceiverShardID: 0}
+			for i := 0; i < 100; i++ {
+				largeMiniBlock.TxHashes = append(largeMiniBlock.TxHashes, make([]byte, 32))
+			}
+			return block.MiniBlockSlice{largeMiniBlock}, uint32(len(largeMiniBlock.TxHashes)), true, nil
+		},
+		CreateMbsAndProcessTransactionsFromMeCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+			createMbsFromMeCalled = true
+			return make(block.MiniBlockSlice, 0)
+		},
+		CreatePostProcessMiniBlocksCalled: func() block.MiniBlockSlice {
+			return block.MiniBlockSlice{mbPostProcess}
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	body, err := sp.CreateMiniBlocks(func() bool { return true })
+	assert.Nil(t, err)
+	require.Equal(t, 2, len(body.MiniBlocks))
+	assert.Equal(t, mbPostProcess, body.MiniBlocks[1])
+	assert.Equal(t, []data.HeaderHandler{metaBlock1}, processedMetaBlocks)
+	assert.False(t, createMbsFromMeCalled)
+}
+
+func TestShardProcessor_CreateMiniBlocksFromMeFirstShouldStopWhenMaxBlockBodyBytesIsReached(t *testing.T) {
+	t.Parallel()
+
+	largeMiniBlock := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1}
+	for i := 0; i < 100; i++ {
+		largeMiniBlock.TxHashes = append(largeMiniBlock.TxHashes, make([]byte, 32))
+	}
+	mbPostProcess := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1, Type: block.SmartContractResultBlock}
+
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{
+		MaxBlockBodyBytes:  1000,
+		BodyComposition:    process.FromMeFirst,
+		ProduceEmptyBlocks: true,
+	})
+	createMbsToMeCalled := false
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			createMbsToMeCalled = true
+			return make(block.MiniBlockSlice, 0), 0, true, nil
+		},
+		CreateMbsAndProcessTransactionsFromMeWithoutPostProcessCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+			return block.MiniBlockSlice{largeMiniBlock}
+		},
+		CreatePostProcessMiniBlocksCalled: func() block.MiniBlockSlice {
+			return block.MiniBlockSlice{mbPostProcess}
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	body, bodyComposition, err := sp.CreateMiniBlocksWithBodyComposition(func() bool { return true }, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, process.FromMeFirst, bodyComposition)
+	assert.Equal(t, []*block.MiniBlock{largeMiniBlock, mbPostProcess}, body.MiniBlocks)
+	assert.False(t, createMbsToMeCalled)
+}
+
+func TestShardProcessor_NewShardProcessorWithNilBlockCreationPolicyShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockCreationPolicy = nil
+	sp, err := blproc.NewShardProcessor(arguments)
+	assert.Nil(t, sp)
+	assert.Equal(t, process.ErrNilBlockCreationPolicy, err)
+}
+
+func TestShardProcessor_CreateMiniBlocksShouldAddMiniBlocksInTheConfiguredBodyCompositionOrder(t *testing.T) {
+	t.Parallel()
+
+	mbToMe := &block.MiniBlock{SenderShardID: 1, ReceiverShardID: 0, TxHashes: [][]byte{[]byte("tx to me")}}
+	mbFromMe := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("tx from me")}}
+	mbPostProcess := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1, Type: block.SmartContractResultBlock}
+
+	testCases := []struct {
+		bodyComposition         process.BodyComposition
+		enableEpoch             uint32
+		expectedBodyComposition process.BodyComposition
+		expectedMiniBlocks      []*block.MiniBlock
+	}{
+		{
+			bodyComposition:         "",
+			expectedBodyComposition: process.CrossShardFirst,
+			expectedMiniBlocks:      []*block.MiniBlock{mbToMe, mbFromMe, mbPostProcess},
+		},
+		{
+			bodyComposition:         process.CrossShardFirst,
+			expectedBodyComposition: process.CrossShardFirst,
+			expectedMiniBlocks:      []*block.MiniBlock{mbToMe, mbFromMe, mbPostProcess},
+		},
+		{
+			bodyComposition:         process.FromMeFirst,
+			expectedBodyComposition: process.FromMeFirst,
+			expectedMiniBlocks:      []*block.MiniBlock{mbFromMe, mbToMe, mbPostProcess},
+		},
+		{
+			bodyComposition:         process.FromMeFirst,
+			enableEpoch:             1,
+			expectedBodyComposition: process.CrossShardFirst,
+			expectedMiniBlocks:      []*block.MiniBlock{mbToMe, mbFromMe, mbPostProcess},
+		},
+	}
+
+	for _, tc := range testCases {
+		metaBlock := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb 1"), 1)
+
+		arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+		arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{
+			BodyComposition:    tc.bodyComposition,
+			ProduceEmptyBlocks: true,
+		})
+		arguments.BodyCompositionEnableEpoch = tc.enableEpoch
+		blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+		blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+			return []data.HeaderHandler{metaBlock}, [][]byte{[]byte("meta block 1")}
+		}
+		arguments.BlockTracker = blockTracker
+		arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+			CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+				header data.HeaderHandler,
+				processedMiniBlocksHashes map[string]struct{},
+				haveTime func() bool,
+			) (block.MiniBlockSlice, uint32, bool, error) {
+				return block.MiniBlockSlice{mbToMe}, 1, true, nil
+			},
+			CreateMbsAndProcessTransactionsFromMeCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+				return block.MiniBlockSlice{mbFromMe, mbPostProcess}
+			},
+			CreateMbsAndProcessTransactionsFromMeWithoutPostProcessCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+				return block.MiniBlockSlice{mbFromMe}
+			},
+			CreatePostProcessMiniBlocksCalled: func() block.MiniBlockSlice {
+				return block.MiniBlockSlice{mbPostProcess}
+			},
+		}
+		sp, _ := blproc.NewShardProcessor(arguments)
+
+		body, bodyComposition, err := sp.CreateMiniBlocksWithBodyComposition(func() bool { return true }, 0)
+		require.Nil(t, err)
+		assert.Equal(t, tc.expectedMiniBlocks, body.MiniBlocks, "body composition: %s", tc.bodyComposition)
+		assert.Equal(t, tc.expectedBodyComposition, bodyComposition)
+	}
+}
+
+func TestShardProcessor_ReceivedMetaBlockWithPoolNearCapacityShouldPrioritizeTheMetaBlocksOnce(t *testing.T) {
+	t.Parallel()
+
+	mbToMe := &block.MiniBlock{SenderShardID: 1, ReceiverShardID: 0, TxHashes: [][]byte{[]byte("tx to me")}}
+	mbFromMe := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1, TxHashes: [][]byte{[]byte("tx from me")}}
+	mbPostProcess := &block.MiniBlock{SenderShardID: 0, ReceiverShardID: 1, Type: block.SmartContractResultBlock}
+	metaBlock := createMetaBlockWithOneMiniBlockDstMe(1, []byte("mb 1"), 1)
+
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{
+		BodyComposition:    process.FromMeFirst,
+		ProduceEmptyBlocks: true,
+	})
+	arguments.MetaBlocksPoolHighFillRatio = 0.005
+	blockTracker := mock.NewBlockTrackerMock(arguments.ShardCoordinator, createGenesisBlocks(arguments.ShardCoordinator))
+	blockTracker.ComputeLongestChainCalled = func(shardID uint32, header data.HeaderHandler) ([]data.HeaderHandler, [][]byte) {
+		return []data.HeaderHandler{metaBlock}, [][]byte{[]byte("meta block 1")}
+	}
+	arguments.BlockTracker = blockTracker
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessCrossShardTransactionsDstMeCalled: func(
+			header data.HeaderHandler,
+			processedMiniBlocksHashes map[string]struct{},
+			haveTime func() bool,
+		) (block.MiniBlockSlice, uint32, bool, error) {
+			return block.MiniBlockSlice{mbToMe}, 1, true, nil
+		},
+		CreateMbsAndProcessTransactionsFromMeCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+			return block.MiniBlockSlice{mbFromMe, mbPostProcess}
+		},
+		CreateMbsAndProcessTransactionsFromMeWithoutPostProcessCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+			return block.MiniBlockSlice{mbFromMe}
+		},
+		CreatePostProcessMiniBlocksCalled: func() block.MiniBlockSlice {
+			return block.MiniBlockSlice{mbPostProcess}
+		},
+	}
+
+	headersPool := arguments.DataPool.Headers()
+	numMetaBlocksNearCapacity := int(arguments.MetaBlocksPoolHighFillRatio * float64(headersPool.MaxSize()))
+	for i := 1; i <= numMetaBlocksNearCapacity; i++ {
+		hdr := &block.MetaBlock{Nonce: uint64(i), Round: uint64(i)}
+		headersPool.AddHeader([]byte(fmt.Sprintf("meta hash %d", i)), hdr)
+	}
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	numNearCapacityHints := 0
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		IncrementHandler: func(key string) {
+			if key == core.MetricMetaBlocksPoolNearCapacity {
+				numNearCapacityHints++
+			}
+		},
+		AddUint64Handler:      func(key string, value uint64) {},
+		SetUInt64ValueHandler: func(key string, value uint64) {},
+		SetInt64ValueHandler:  func(key string, value int64) {},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	lastMetaBlock, _ := headersPool.GetHeaderByHash([]byte(fmt.Sprintf("meta hash %d", numMetaBlocksNearCapacity)))
+	sp.ReceivedMetaBlock(lastMetaBlock, []byte(fmt.Sprintf("meta hash %d", numMetaBlocksNearCapacity)))
+	sp.ReceivedMetaBlock(lastMetaBlock, []byte(fmt.Sprintf("meta hash %d", numMetaBlocksNearCapacity)))
+	assert.Equal(t, 1, numNearCapacityHints)
+
+	body, err := sp.CreateMiniBlocks(func() bool { return true })
+	require.Nil(t, err)
+	assert.Equal(t, []*block.MiniBlock{mbToMe, mbFromMe, mbPostProcess}, body.MiniBlocks)
+
+	body, err = sp.CreateMiniBlocks(func() bool { return true })
+	require.Nil(t, err)
+	assert.Equal(t, []*block.MiniBlock{mbFromMe, mbToMe, mbPostProcess}, body.MiniBlocks)
+}
+
+func TestShardProcessor_ReceivedMetaBlockWithPoolNearCapacityShouldReportTheMetricForTheDefaultBodyComposition(t *testing.T) {
+	t.Parallel()
+
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.MetaBlocksPoolHighFillRatio = 0.005
+
+	headersPool := arguments.DataPool.Headers()
+	numMetaBlocksNearCapacity := int(arguments.MetaBlocksPoolHighFillRatio * float64(headersPool.MaxSize()))
+	for i := 1; i <= numMetaBlocksNearCapacity; i++ {
+		hdr := &block.MetaBlock{Nonce: uint64(i), Round: uint64(i)}
+		headersPool.AddHeader([]byte(fmt.Sprintf("meta hash %d", i)), hdr)
+	}
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+	numNearCapacityReports := 0
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		IncrementHandler: func(key string) {
+			if key == core.MetricMetaBlocksPoolNearCapacity {
+				numNearCapacityReports++
+			}
+		},
+		AddUint64Handler:      func(key string, value uint64) {},
+		SetUInt64ValueHandler: func(key string, value uint64) {},
+		SetInt64ValueHandler:  func(key string, value int64) {},
+		SetStringValueHandler: func(key string, value string) {},
+	})
+
+	lastMetaBlockHash := []byte(fmt.Sprintf("meta hash %d", numMetaBlocksNearCapacity))
+	lastMetaBlock, _ := headersPool.GetHeaderByHash(lastMetaBlockHash)
+	sp.ReceivedMetaBlock(lastMetaBlock, lastMetaBlockHash)
+	sp.ReceivedMetaBlock(lastMetaBlock, lastMetaBlockHash)
+	assert.Equal(t, 1, numNearCapacityReports)
+
+	// the fill ratio is crossed again after the pool was drained below it
+	headersPool.RemoveHeaderByHash(lastMetaBlockHash)
+	firstMetaBlockHash := []byte("meta hash 1")
+	firstMetaBlock, _ := headersPool.GetHeaderByHash(firstMetaBlockHash)
+	sp.ReceivedMetaBlock(firstMetaBlock, firstMetaBlockHash)
+	assert.Equal(t, 1, numNearCapacityReports)
+
+	headersPool.AddHeader(lastMetaBlockHash, lastMetaBlock)
+	sp.ReceivedMetaBlock(lastMetaBlock, lastMetaBlockHash)
+	assert.Equal(t, 2, numNearCapacityReports)
+}
+
+func TestShardProcessor_NewShardProcessorWithInvalidMetaBlocksPoolHighFillRatioShouldErr(t *testing.T) {
+	t.Parallel()
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MetaBlocksPoolHighFillRatio = 1.5
+
+	sp, err := blproc.NewShardProcessor(arguments)
+	assert.Nil(t, sp)
+	assert.True(t, errors.Is(err, process.ErrInvalidMetaBlocksPoolHighFillRatio))
+}
+
+func TestShardProcessor_CreateMiniBlocksShouldCreateTheMiniBlocksFromMeWithTheMaxTxsPerDestShard(t *testing.T) {
+	t.Parallel()
+
+	maxTxsPerDestShard := uint32(2)
+	receivedMaxTxsPerDestShard := uint32(0)
+	arguments := createArgumentsForEmptyPoolBlockBodyCreation(true)
+	arguments.BlockCreationPolicy = createBlockCreationPolicy(blproc.ArgsBlockCreationPolicy{
+		MaxTxsPerDestShard: maxTxsPerDestShard,
+		ProduceEmptyBlocks: true,
+	})
+	arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+		CreateMbsAndProcessTransactionsFromMeCalled: func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) block.MiniBlockSlice {
+			receivedMaxTxsPerDestShard = maxTxsPerDestShard
+			return make(block.MiniBlockSlice, 0)
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	_, err := sp.CreateMiniBlocks(func() bool { return true })
+	require.Nil(t, err)
+	assert.Equal(t, maxTxsPerDestShard, receivedMaxTxsPerDestShard)
+}
+
+//------- createMiniBlocks
+
+func TestShardProcessor_CreateMiniBlocksShouldWorkWithIntraShardTxs(t *testing.T) {
+	t.Parallel()
+
+	hasher := mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	datapool := testscommon.NewPoolsHolderMock()
+
+	//we will have a 3 txs in pool
+
+	txHash1 := []byte("tx hash 1")
+	txHash2 := []byte("tx hash 2")
+	txHash3 := []byte("tx hash 3")
+
+	senderShardId := uint32(0)
+	receiverShardId := uint32(0)
+
+	tx1Nonce := uint64(45)
+	tx2Nonce := uint64(46)
+	tx3Nonce := uint64(47)
+
+	//put the existing tx inside datapool
+	cacheId := process.ShardCacherIdentifier(senderShardId, receiverShardId)
+	datapool.Transactions().AddData(txHash1, &transaction.Transaction{
+		Nonce: tx1Nonce,
+		Data:  txHash1,
+	}, 0, cacheId)
+	datapool.Transactions().AddData(txHash2, &transaction.Transaction{
+		Nonce: tx2Nonce,
+		Data:  txHash2,
+	}, 0, cacheId)
+	datapool.Transactions().AddData(txHash3, &transaction.Transaction{
+		Nonce: tx3Nonce,
+		Data:  txHash3,
+	}, 0, cacheId)
+
+	tx1ExecutionResult := uint64(0)
+	tx2ExecutionResult := uint64(0)
+	tx3ExecutionResult := uint64(0)
+
+	txProcessorMock := &mock.TxProcessorMock{
+		ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			//execution, in this context, means moving the tx nonce to itx corresponding execution result variable
+			if bytes.Equal(transaction.Data, txHash1) {
+				tx1ExecutionResult = transaction.Nonce
+			}
+			if bytes.Equal(transaction.Data, txHash2) {
+				tx2ExecutionResult = transaction.Nonce
+			}
+			if bytes.Equal(transaction.Data, txHash3) {
+				tx3ExecutionResult = transaction.Nonce
+			}
+
+			return 0, nil
+		},
+	}
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	accntAdapter := &mock.AccountsStub{
+		RevertToSnapshotCalled: func(snapshot int) error {
+			assert.Fail(t, "revert should have not been called")
+			return nil
+		},
+		JournalLenCalled: func() int {
+			return 0
+		},
+	}
+
+	totalGasConsumed := uint64(0)
+	factory, _ := shard.NewPreProcessorsContainerFactory(
+		shardCoordinator,
+		initStore(),
+		marshalizer,
+		hasher,
+		datapool,
+		createMockPubkeyConverter(),
+		accntAdapter,
+		&mock.RequestHandlerStub{},
+		txProcessorMock,
+		&mock.SCProcessorMock{},
+		&mock.SmartContractResultsProcessorMock{},
+		&mock.RewardTxProcessorMock{},
+		&mock.FeeHandlerStub{
+			ComputeGasLimitCalled: func(tx process.TransactionWithFeeHandler) uint64 {
+				return 0
+			},
+			MaxGasLimitPerBlockCalled: func() uint64 {
+				return MaxGasLimitPerBlock
+			},
+		},
+		&mock.GasHandlerMock{
+			SetGasConsumedCalled: func(gasConsumed uint64, hash []byte) {
+				totalGasConsumed += gasConsumed
+			},
+			TotalGasConsumedCalled: func() uint64 {
+				return totalGasConsumed
+			},
+			ComputeGasConsumedByTxCalled: func(txSenderShardId uint32, txReceiverSharedId uint32, txHandler data.TransactionHandler) (uint64, uint64, error) {
+				return 0, 0, nil
+			},
+			SetGasRefundedCalled: func(gasRefunded uint64, hash []byte) {},
+			TotalGasRefundedCalled: func() uint64 {
+				return 0
+			},
+		},
+		&mock.BlockTrackerMock{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+	)
+	container, _ := factory.Create()
+
+	tc, err := coordinator.NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		accntAdapter,
+		datapool.MiniBlocks(),
+		&mock.RequestHandlerStub{},
+		container,
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.AccountsDB[state.UserAccountsState] = accntAdapter
+	arguments.TxCoordinator = tc
+	bp, _ := blproc.NewShardProcessor(arguments)
+
+	blockBody, err := bp.CreateMiniBlocks(func() bool { return true })
+
+	assert.Nil(t, err)
+	//testing execution
+	assert.Equal(t, tx1Nonce, tx1ExecutionResult)
+	assert.Equal(t, tx2Nonce, tx2ExecutionResult)
+	assert.Equal(t, tx3Nonce, tx3ExecutionResult)
+	//one miniblock output
+	assert.Equal(t, 1, len(blockBody.MiniBlocks))
+	//miniblock should have 3 txs
+	assert.Equal(t, 3, len(blockBody.MiniBlocks[0].TxHashes))
+	//testing all 3 hashes are present in block body
+	assert.True(t, isInTxHashes(txHash1, blockBody.MiniBlocks[0].TxHashes))
+	assert.True(t, isInTxHashes(txHash2, blockBody.MiniBlocks[0].TxHashes))
+	assert.True(t, isInTxHashes(txHash3, blockBody.MiniBlocks[0].TxHashes))
+}
+
+func TestShardProcessor_GetProcessedMetaBlockFromPoolShouldWork(t *testing.T) {
+	t.Parallel()
+
+	//we have 3 metablocks in pool each containing 2 miniblocks.
+	//blockbody will have 2 + 1 miniblocks from 2 out of the 3 metablocks
+	//The test should remove only one metablock
+
+	destShardId := uint32(2)
+
+	hasher := mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	datapool := testscommon.NewPoolsHolderMock()
+
+	miniblockHashes := make([][]byte, 6)
+
+	destShards := []uint32{1, 3, 4}
+	for i := 0; i < 6; i++ {
+		_, hash := createDummyMiniBlock(fmt.Sprintf("tx hash %d", i), marshalizer, hasher, destShardId, destShards[i/2])
+		miniblockHashes[i] = hash
+	}
+
+	//put 3 metablocks in pool
+	metaBlockHash1 := []byte("meta block 1")
+	metaBlock1 := createDummyMetaBlock(destShardId, destShards[0], miniblockHashes[0], miniblockHashes[1])
+	datapool.Headers().AddHeader(metaBlockHash1, metaBlock1)
+
+	metaBlockHash2 := []byte("meta block 2")
+	metaBlock2 := createDummyMetaBlock(destShardId, destShards[1], miniblockHashes[2], miniblockHashes[3])
+	datapool.Headers().AddHeader(metaBlockHash2, metaBlock2)
+
+	metaBlockHash3 := []byte("meta block 3")
+	metaBlock3 := createDummyMetaBlock(destShardId, destShards[2], miniblockHashes[4], miniblockHashes[5])
+	datapool.Headers().AddHeader(metaBlockHash3, metaBlock3)
+
+	shardCoordinator := mock.NewMultipleShardsCoordinatorMock()
+	shardCoordinator.CurrentShard = destShardId
+	shardCoordinator.SetNoShards(destShardId + 1)
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.ShardCoordinator = shardCoordinator
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+	}
+	bp, _ := blproc.NewShardProcessor(arguments)
+
+	bp.SetHdrForCurrentBlock(metaBlockHash1, metaBlock1, true)
+	bp.SetHdrForCurrentBlock(metaBlockHash2, metaBlock2, true)
+	bp.SetHdrForCurrentBlock(metaBlockHash3, metaBlock3, true)
+
+	//create mini block headers with first 3 miniblocks from miniblocks var
+	mbHeaders := []block.MiniBlockHeader{
+		{Hash: miniblockHashes[0]},
+		{Hash: miniblockHashes[1]},
+		{Hash: miniblockHashes[2]},
+	}
+
+	hashes := [][]byte{
+		metaBlockHash1,
+		metaBlockHash2,
+		metaBlockHash3,
+	}
+
+	blockHeader := &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
+
+	err := bp.AddProcessedCrossMiniBlocksFromHeader(blockHeader)
+
+	assert.Nil(t, err)
+}
+
+func TestBlockProcessor_RestoreBlockIntoPoolsShouldErrNilBlockHeader(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	be, _ := blproc.NewShardProcessor(arguments)
+	err := be.RestoreBlockIntoPools(nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, process.ErrNilBlockHeader, err)
+}
+
+func TestBlockProcessor_RestoreBlockIntoPoolsShouldWorkNilTxBlockBody(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.RestoreBlockIntoPools(&block.Header{}, nil)
+	assert.Nil(t, err)
+}
+
+func TestShardProcessor_RestoreBlockIntoPoolsShouldErrWhenMaxReorgDepthIsExceeded(t *testing.T) {
+	t.Parallel()
+
+	maxReorgDepth := uint32(3)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MaxReorgDepth = maxReorgDepth
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	for i := uint32(0); i < maxReorgDepth; i++ {
+		err := sp.RestoreBlockIntoPools(&block.Header{}, nil)
+		require.Nil(t, err)
+	}
+
+	err := sp.RestoreBlockIntoPools(&block.Header{}, nil)
+	assert.True(t, errors.Is(err, process.ErrReorgTooDeep))
+}
+
+func TestShardProcessor_RestoreBlockIntoPoolsShouldReturnTheMetaBlockHashesWhichWereNotRestored(t *testing.T) {
+	t.Parallel()
+
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock := &block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: make([]block.ShardData, 0),
+	}
+	metaBlockBytes, _ := marshalizer.Marshal(metaBlock)
+	restoredHash := []byte("restored meta hash")
+	missingHash := []byte("missing meta hash")
+	corruptedHash := []byte("corrupted meta hash")
+
+	poolFake := testscommon.NewPoolsHolderMock()
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = poolFake
+	arguments.Marshalizer = marshalizer
+	arguments.Store = &mock.ChainStorerMock{
+		GetStorerCalled: func(unitType dataRetriever.UnitType) storage.Storer {
+			return &mock.StorerStub{
+				RemoveCalled: func(key []byte) error {
+					return nil
+				},
+				GetCalled: func(key []byte) ([]byte, error) {
+					switch {
+					case bytes.Equal(key, restoredHash):
+						return metaBlockBytes, nil
+					case bytes.Equal(key, corruptedHash):
+						return []byte("corrupted bytes"), nil
+					default:
+						return nil, errors.New("key not found")
+					}
+				},
+			}
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	header := &block.Header{
+		MetaBlockHashes: [][]byte{missingHash, restoredHash, corruptedHash},
+	}
+	err := sp.RestoreBlockIntoPools(header, &block.Body{})
+	require.True(t, errors.Is(err, process.ErrPartialRestore))
+
+	partialRestoreErr := &process.PartialRestoreError{}
+	require.True(t, errors.As(err, &partialRestoreErr))
+	assert.Equal(t, [][]byte{missingHash, corruptedHash}, partialRestoreErr.FailedHashes)
+
+	metaBlockRestored, _ := poolFake.Headers().GetHeaderByHash(restoredHash)
+	assert.Equal(t, metaBlock, metaBlockRestored)
+}
+
+func TestShardProcessor_RestoreBlockIntoPoolsShouldWork(t *testing.T) {
+	t.Parallel()
+
+	txHash := []byte("tx hash 1")
+
+	datapool := testscommon.NewPoolsHolderMock()
+	marshalizerMock := &mock.MarshalizerMock{}
+	hasherMock := &mock.HasherStub{}
+
+	body := &block.Body{}
+	tx := &transaction.Transaction{
+		Nonce: 1,
+		Value: big.NewInt(0),
+	}
+	buffTx, _ := marshalizerMock.Marshal(tx)
+
+	store := &mock.ChainStorerMock{
+		GetAllCalled: func(unitType dataRetriever.UnitType, keys [][]byte) (map[string][]byte, error) {
+			m := make(map[string][]byte)
+			m[string(txHash)] = buffTx
+			return m, nil
+		},
 	}
 
 	factory, _ := shard.NewPreProcessorsContainerFactory(
 		mock.NewMultiShardsCoordinatorMock(3),
-		initStore(),
-		marshalizer,
-		&mock.HasherMock{},
-		tdp,
+		store,
+		marshalizerMock,
+		hasherMock,
+		datapool,
 		createMockPubkeyConverter(),
 		initAccountsMock(),
 		&mock.RequestHandlerStub{},
@@ -2238,7 +7126,7 @@ func TestShardProcessor_MarshalizedDataToBroadcastShouldWork(t *testing.T) {
 		&mock.MarshalizerMock{},
 		mock.NewMultiShardsCoordinatorMock(3),
 		initAccountsMock(),
-		tdp.MiniBlocks(),
+		datapool.MiniBlocks(),
 		&mock.RequestHandlerStub{},
 		container,
 		&mock.InterimProcessorContainerMock{},
@@ -2253,356 +7141,504 @@ func TestShardProcessor_MarshalizedDataToBroadcastShouldWork(t *testing.T) {
 	assert.Nil(t, err)
 
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Marshalizer = marshalizer
+	arguments.DataPool = datapool
+	arguments.Store = store
+	arguments.Hasher = hasherMock
+	arguments.Marshalizer = marshalizerMock
 	arguments.TxCoordinator = tc
 	sp, _ := blproc.NewShardProcessor(arguments)
-	msh, mstx, err := sp.MarshalizedDataToBroadcast(&block.Header{}, body)
+
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
+	}
+	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+
+	miniblockHash := []byte("mini block hash 1")
+	hasherMock.ComputeCalled = func(s string) []byte {
+		return miniblockHash
+	}
+
+	metablockHash := []byte("meta block hash 1")
+	metablockHeader := createDummyMetaBlock(0, 1, miniblockHash)
+	datapool.Headers().AddHeader(metablockHash, metablockHeader)
+
+	store.GetStorerCalled = func(unitType dataRetriever.UnitType) storage.Storer {
+		return &mock.StorerStub{
+			RemoveCalled: func(key []byte) error {
+				return nil
+			},
+			GetCalled: func(key []byte) ([]byte, error) {
+				return marshalizerMock.Marshal(metablockHeader)
+			},
+		}
+	}
+
+	miniBlockHeader := block.MiniBlockHeader{
+		Hash:            miniblockHash,
+		SenderShardID:   miniblock.SenderShardID,
+		ReceiverShardID: miniblock.ReceiverShardID,
+	}
+
+	err = sp.RestoreBlockIntoPools(&block.Header{MetaBlockHashes: [][]byte{metablockHash}, MiniBlockHeaders: []block.MiniBlockHeader{miniBlockHeader}}, body)
 	assert.Nil(t, err)
-	assert.NotNil(t, msh)
-	assert.NotNil(t, mstx)
-	_, found := msh[0]
-	assert.False(t, found)
 
-	bh := &block.Body{}
-	err = marshalizer.Unmarshal(bh, msh[1])
+	miniblockFromPool, _ := datapool.MiniBlocks().Get(miniblockHash)
+	txFromPool, _ := datapool.Transactions().SearchFirstData(txHash)
 	assert.Nil(t, err)
-	assert.Equal(t, len(bh.MiniBlocks), 2)
-	assert.Equal(t, &mb1, bh.MiniBlocks[0])
-	assert.Equal(t, &mb1, bh.MiniBlocks[1])
+	assert.Equal(t, &miniblock, miniblockFromPool)
+	assert.Equal(t, tx, txFromPool)
+}
+
+func TestShardProcessor_DecodeBlockBody(t *testing.T) {
+	t.Parallel()
+
+	tdp := initDataPool([]byte("tx_hash1"))
+	marshalizerMock := &mock.MarshalizerMock{}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizerMock
+	sp, _ := blproc.NewShardProcessor(arguments)
+	body := &block.Body{}
+	body.MiniBlocks = append(body.MiniBlocks, &block.MiniBlock{ReceiverShardID: 69})
+	message, err := marshalizerMock.Marshal(body)
+	assert.Nil(t, err)
+
+	bodyNil := &block.Body{}
+	dcdBlk := sp.DecodeBlockBody(nil)
+	assert.Equal(t, bodyNil, dcdBlk)
+
+	dcdBlk = sp.DecodeBlockBody(message)
+	assert.Equal(t, body, dcdBlk)
+	assert.Equal(t, uint32(69), body.MiniBlocks[0].ReceiverShardID)
+}
+
+func TestShardProcessor_DecodeBlockHeader(t *testing.T) {
+	t.Parallel()
+	tdp := initDataPool([]byte("tx_hash1"))
+	marshalizerMock := &mock.MarshalizerMock{}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = tdp
+	arguments.Marshalizer = marshalizerMock
+	sp, err := blproc.NewShardProcessor(arguments)
+	assert.Nil(t, err)
+	hdr := &block.Header{}
+	hdr.Nonce = 1
+	hdr.TimeStamp = uint64(0)
+	hdr.Signature = []byte("A")
+	hdr.AccumulatedFees = big.NewInt(0)
+	hdr.DeveloperFees = big.NewInt(0)
+	_, err = marshalizerMock.Marshal(hdr)
+	assert.Nil(t, err)
+
+	message, err := marshalizerMock.Marshal(hdr)
+	assert.Nil(t, err)
+
+	dcdHdr := sp.DecodeBlockHeader(nil)
+	assert.Nil(t, dcdHdr)
+
+	dcdHdr = sp.DecodeBlockHeader(message)
+	assert.Equal(t, hdr, dcdHdr)
+	assert.Equal(t, []byte("A"), dcdHdr.GetSignature())
+}
+
+func TestShardProcessor_DecodeBlockHeaderWithCacheShouldReturnIndependentCopies(t *testing.T) {
+	t.Parallel()
+
+	marshalizer := &mock.MarshalizerMock{}
+	numUnmarshalCalls := 0
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.Marshalizer = &mock.MarshalizerStub{
+		MarshalCalled: marshalizer.Marshal,
+		UnmarshalCalled: func(obj interface{}, buff []byte) error {
+			numUnmarshalCalls++
+			return marshalizer.Unmarshal(obj, buff)
+		},
+	}
+	arguments.DecodedHeadersCacheSize = 10
+	sp, err := blproc.NewShardProcessor(arguments)
+	require.Nil(t, err)
+
+	hdr := &block.Header{
+		Nonce:           1,
+		Signature:       []byte("signature"),
+		MetaBlockHashes: [][]byte{[]byte("meta block hash")},
+		AccumulatedFees: big.NewInt(10),
+		DeveloperFees:   big.NewInt(1),
+	}
+	message, _ := marshalizer.Marshal(hdr)
+
+	firstHdr := sp.DecodeBlockHeader(message).(*block.Header)
+	firstHdr.Signature[0] = 'S'
+	firstHdr.MetaBlockHashes[0] = []byte("changed")
+	firstHdr.AccumulatedFees.SetInt64(100)
+
+	secondHdr := sp.DecodeBlockHeader(message)
+	assert.Equal(t, hdr, secondHdr)
+	assert.Equal(t, 1, numUnmarshalCalls)
+
+	otherHdr := &block.Header{Nonce: 2}
+	otherMessage, _ := marshalizer.Marshal(otherHdr)
+	assert.Equal(t, uint64(2), sp.DecodeBlockHeader(otherMessage).GetNonce())
+	assert.Equal(t, 2, numUnmarshalCalls)
 }
 
-func TestShardProcessor_MarshalizedDataWrongType(t *testing.T) {
-	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	marshalizer := &mock.MarshalizerMock{
-		Fail: false,
+func benchmarkShardProcessorDecodeBlockHeader(b *testing.B, decodedHeadersCacheSize uint32) {
+	marshalizer := &marshal.GogoProtoMarshalizer{}
+	numUnmarshalCalls := 0
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Hasher = &mock.HasherMock{}
+	arguments.Marshalizer = &mock.MarshalizerStub{
+		MarshalCalled: marshalizer.Marshal,
+		UnmarshalCalled: func(obj interface{}, buff []byte) error {
+			numUnmarshalCalls++
+			return marshalizer.Unmarshal(obj, buff)
+		},
+	}
+	arguments.DecodedHeadersCacheSize = decodedHeadersCacheSize
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr := &block.Header{
+		Nonce:           1,
+		Round:           1,
+		PrevHash:        make([]byte, 32),
+		RootHash:        make([]byte, 32),
+		Signature:       make([]byte, 96),
+		AccumulatedFees: big.NewInt(0),
+		DeveloperFees:   big.NewInt(0),
+	}
+	for i := 0; i < 20; i++ {
+		hdr.MiniBlockHeaders = append(hdr.MiniBlockHeaders, block.MiniBlockHeader{Hash: make([]byte, 32), TxCount: 100})
+		hdr.MetaBlockHashes = append(hdr.MetaBlockHashes, make([]byte, 32))
+	}
+	message, _ := marshalizer.Marshal(hdr)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = sp.DecodeBlockHeader(message)
 	}
+	b.ReportMetric(float64(numUnmarshalCalls)/float64(b.N), "unmarshals/op")
+}
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Marshalizer = marshalizer
+func BenchmarkShardProcessor_DecodeBlockHeaderWithoutCache(b *testing.B) {
+	benchmarkShardProcessorDecodeBlockHeader(b, 0)
+}
 
-	sp, _ := blproc.NewShardProcessor(arguments)
-	wr := &wrongBody{}
-	msh, mstx, err := sp.MarshalizedDataToBroadcast(&block.Header{}, wr)
-	assert.Equal(t, process.ErrWrongTypeAssertion, err)
-	assert.Nil(t, msh)
-	assert.Nil(t, mstx)
+func BenchmarkShardProcessor_DecodeBlockHeaderWithCache(b *testing.B) {
+	benchmarkShardProcessorDecodeBlockHeader(b, 100)
 }
 
-func TestShardProcessor_MarshalizedDataNilInput(t *testing.T) {
+func TestShardProcessor_IsHdrConstructionValid(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	marshalizer := &mock.MarshalizerMock{
-		Fail: false,
-	}
 
+	hasher := mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	datapool := initDataPool([]byte("tx_hash1"))
+
+	shardNr := uint32(5)
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
+	arguments.DataPool = datapool
+	arguments.Hasher = hasher
 	arguments.Marshalizer = marshalizer
-
+	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
 	sp, _ := blproc.NewShardProcessor(arguments)
-	msh, mstx, err := sp.MarshalizedDataToBroadcast(nil, nil)
-	assert.Equal(t, process.ErrNilMiniBlocks, err)
-	assert.Nil(t, msh)
-	assert.Nil(t, mstx)
-}
 
-func TestShardProcessor_MarshalizedDataMarshalWithoutSuccess(t *testing.T) {
-	t.Parallel()
-	wasCalled := false
-	tdp := initDataPool([]byte("tx_hash1"))
-	txHash0 := []byte("txHash0")
-	mb0 := block.MiniBlock{
-		ReceiverShardID: 1,
-		SenderShardID:   0,
-		TxHashes:        [][]byte{txHash0},
-	}
-	body := &block.Body{}
-	body.MiniBlocks = append(body.MiniBlocks, &mb0)
-	marshalizer := &mock.MarshalizerStub{
-		MarshalCalled: func(obj interface{}) ([]byte, error) {
-			wasCalled = true
-			return nil, process.ErrMarshalWithoutSuccess
-		},
-	}
+	prevRandSeed := []byte("prevrand")
+	currRandSeed := []byte("currrand")
+	notarizedHdrs := sp.NotarizedHdrs()
+	lastHdr := &block.MetaBlock{Round: 9,
+		Nonce:    44,
+		RandSeed: prevRandSeed}
+	notarizedHdrs[core.MetachainShardId] = append(notarizedHdrs[core.MetachainShardId], lastHdr)
 
-	factory, _ := shard.NewPreProcessorsContainerFactory(
-		mock.NewMultiShardsCoordinatorMock(3),
-		initStore(),
-		&mock.MarshalizerMock{},
-		&mock.HasherMock{},
-		tdp,
-		createMockPubkeyConverter(),
-		initAccountsMock(),
-		&mock.RequestHandlerStub{},
-		&mock.TxProcessorMock{},
-		&mock.SCProcessorMock{},
-		&mock.SmartContractResultsProcessorMock{},
-		&mock.RewardTxProcessorMock{},
-		&mock.FeeHandlerStub{},
-		&mock.GasHandlerMock{},
-		&mock.BlockTrackerMock{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-	)
-	container, _ := factory.Create()
+	//put the existing headers inside datapool
 
-	tc, err := coordinator.NewTransactionCoordinator(
-		&mock.HasherMock{},
-		&mock.MarshalizerMock{},
-		mock.NewMultiShardsCoordinatorMock(3),
-		initAccountsMock(),
-		tdp.MiniBlocks(),
-		&mock.RequestHandlerStub{},
-		container,
-		&mock.InterimProcessorContainerMock{},
-		&mock.GasHandlerMock{},
-		&mock.FeeAccumulatorStub{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-		&mock.FeeHandlerStub{},
-		&mock.TxTypeHandlerMock{},
-		0,
-	)
-	assert.Nil(t, err)
+	//header shard 0
+	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
+	prevHdr := &block.MetaBlock{
+		Round:        10,
+		Nonce:        45,
+		PrevRandSeed: prevRandSeed,
+		RandSeed:     currRandSeed,
+		PrevHash:     prevHash,
+		RootHash:     []byte("prevRootHash")}
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Marshalizer = marshalizer
-	arguments.TxCoordinator = tc
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+	currHdr := &block.MetaBlock{
+		Round:        11,
+		Nonce:        46,
+		PrevRandSeed: currRandSeed,
+		RandSeed:     []byte("nextrand"),
+		PrevHash:     prevHash,
+		RootHash:     []byte("currRootHash")}
 
-	sp, _ := blproc.NewShardProcessor(arguments)
+	err := sp.IsHdrConstructionValid(nil, prevHdr)
+	assert.Equal(t, err, process.ErrNilBlockHeader)
 
-	msh, mstx, err := sp.MarshalizedDataToBroadcast(&block.Header{}, body)
+	err = sp.IsHdrConstructionValid(currHdr, nil)
+	assert.Equal(t, err, process.ErrNilBlockHeader)
+
+	currHdr.Nonce = 0
+	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
+	assert.Equal(t, err, process.ErrWrongNonceInBlock)
+
+	currHdr.Nonce = 46
+	prevHdr.Nonce = 45
+	prevHdr.Round = currHdr.Round + 1
+	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
+	assert.Equal(t, err, process.ErrLowerRoundInBlock)
+
+	prevHdr.Round = currHdr.Round - 1
+	currHdr.Nonce = prevHdr.Nonce + 2
+	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
+	assert.Equal(t, err, process.ErrWrongNonceInBlock)
+
+	currHdr.Nonce = prevHdr.Nonce + 1
+	currHdr.PrevHash = []byte("wronghash")
+	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
+	assert.Equal(t, err, process.ErrBlockHashDoesNotMatch)
+
+	prevHdr.RandSeed = []byte("randomwrong")
+	currHdr.PrevHash, _ = sp.ComputeHeaderHash(prevHdr)
+	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
+	assert.Equal(t, err, process.ErrRandSeedDoesNotMatch)
+
+	currHdr.PrevHash = prevHash
+	prevHdr.RandSeed = currRandSeed
+	prevHdr.RootHash = []byte("prevRootHash")
+	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
 	assert.Nil(t, err)
-	assert.True(t, wasCalled)
-	assert.Equal(t, 0, len(msh))
-	assert.Equal(t, 0, len(mstx))
 }
 
-//------- receivedMetaBlock
-
-func TestShardProcessor_ReceivedMetaBlockShouldRequestMissingMiniBlocks(t *testing.T) {
+func TestShardProcessor_RemoveAndSaveLastNotarizedMetaHdrNoDstMB(t *testing.T) {
 	t.Parallel()
 
 	hasher := mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
 	datapool := testscommon.NewPoolsHolderMock()
+	forkDetector := &mock.ForkDetectorMock{}
+	highNonce := uint64(500)
+	forkDetector.GetHighestFinalBlockNonceCalled = func() uint64 {
+		return highNonce
+	}
 
-	//we will have a metablock that will return 3 miniblock hashes
-	//1 miniblock hash will be in cache
-	//2 will be requested on network
+	wg := sync.WaitGroup{}
+	wg.Add(4)
+	putCalledNr := uint32(0)
+	store := &mock.ChainStorerMock{
+		PutCalled: func(unitType dataRetriever.UnitType, key []byte, value []byte) error {
+			atomic.AddUint32(&putCalledNr, 1)
+			wg.Done()
+			return nil
+		},
+	}
 
-	miniBlockHash1 := []byte("miniblock hash 1 found in cache")
-	miniBlockHash2 := []byte("miniblock hash 2")
-	miniBlockHash3 := []byte("miniblock hash 3")
+	shardNr := uint32(5)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Store = store
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
+	arguments.ForkDetector = forkDetector
+	startHeaders := createGenesisBlocks(arguments.ShardCoordinator)
+	arguments.BlockTracker = mock.NewBlockTrackerMock(arguments.ShardCoordinator, startHeaders)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	metaBlock := &block.MetaBlock{
-		Nonce: 1,
-		Round: 1,
-		ShardInfo: []block.ShardData{
-			{
-				ShardID: 1,
-				ShardMiniBlockHeaders: []block.MiniBlockHeader{
-					{Hash: miniBlockHash1, SenderShardID: 1, ReceiverShardID: 0},
-					{Hash: miniBlockHash2, SenderShardID: 1, ReceiverShardID: 0},
-					{Hash: miniBlockHash3, SenderShardID: 1, ReceiverShardID: 0},
-				}},
-		}}
+	prevRandSeed := []byte("prevrand")
+	currRandSeed := []byte("currrand")
+	firstNonce := uint64(44)
+
+	lastHdr := &block.MetaBlock{Round: 9,
+		Nonce:    firstNonce,
+		RandSeed: prevRandSeed}
+
+	arguments.BlockTracker.AddCrossNotarizedHeader(core.MetachainShardId, lastHdr, nil)
+
+	//header shard 0
+	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
+	prevHdr := &block.MetaBlock{
+		Round:        10,
+		Nonce:        45,
+		PrevRandSeed: prevRandSeed,
+		RandSeed:     currRandSeed,
+		PrevHash:     prevHash,
+		RootHash:     []byte("prevRootHash")}
+
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+	currHdr := &block.MetaBlock{
+		Round:        11,
+		Nonce:        46,
+		PrevRandSeed: currRandSeed,
+		RandSeed:     []byte("nextrand"),
+		PrevHash:     prevHash,
+		RootHash:     []byte("currRootHash")}
+	currHash, _ := sp.ComputeHeaderHash(currHdr)
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+
+	shardHdr := &block.Header{Round: 15}
+	mbHeaders := make([]block.MiniBlockHeader, 0)
+	blockHeader := &block.Header{}
+
+	// test header not in pool and defer called
+	processedMetaHdrs, err := sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
+	assert.Nil(t, err)
+
+	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
+	assert.Nil(t, err)
+
+	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&putCalledNr))
 
-	//put this metaBlock inside datapool
-	metaBlockHash := []byte("metablock hash")
-	datapool.Headers().AddHeader(metaBlockHash, metaBlock)
-	//put the existing miniblock inside datapool
-	datapool.MiniBlocks().Put(miniBlockHash1, &block.MiniBlock{}, 0)
+	assert.Equal(t, firstNonce, sp.LastNotarizedHdrForShard(core.MetachainShardId).GetNonce())
+	assert.Equal(t, 0, len(processedMetaHdrs))
 
-	miniBlockHash1Requested := int32(0)
-	miniBlockHash2Requested := int32(0)
-	miniBlockHash3Requested := int32(0)
+	// wrong header type in pool and defer called
+	datapool.Headers().AddHeader(currHash, shardHdr)
+	sp.SetHdrForCurrentBlock(currHash, shardHdr, true)
 
-	requestHandler := &mock.RequestHandlerStub{
-		RequestMiniBlockHandlerCalled: func(destShardID uint32, miniblockHash []byte) {
-			if bytes.Equal(miniBlockHash1, miniblockHash) {
-				atomic.AddInt32(&miniBlockHash1Requested, 1)
-			}
-			if bytes.Equal(miniBlockHash2, miniblockHash) {
-				atomic.AddInt32(&miniBlockHash2Requested, 1)
-			}
-			if bytes.Equal(miniBlockHash3, miniblockHash) {
-				atomic.AddInt32(&miniBlockHash3Requested, 1)
-			}
-		},
-	}
+	hashes := make([][]byte, 0)
+	hashes = append(hashes, currHash)
+	blockHeader = &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
 
-	tc, _ := coordinator.NewTransactionCoordinator(
-		&mock.HasherMock{},
-		&mock.MarshalizerMock{},
-		mock.NewMultiShardsCoordinatorMock(3),
-		initAccountsMock(),
-		datapool.MiniBlocks(),
-		requestHandler,
-		&mock.PreProcessorContainerMock{},
-		&mock.InterimProcessorContainerMock{},
-		&mock.GasHandlerMock{},
-		&mock.FeeAccumulatorStub{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-		&mock.FeeHandlerStub{},
-		&mock.TxTypeHandlerMock{},
-		0,
-	)
+	processedMetaHdrs, err = sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
+	assert.Equal(t, process.ErrWrongTypeAssertion, err)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.RequestHandler = requestHandler
-	arguments.TxCoordinator = tc
+	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
+	assert.Nil(t, err)
 
-	bp, _ := blproc.NewShardProcessor(arguments)
-	bp.ReceivedMetaBlock(metaBlock, metaBlockHash)
+	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&putCalledNr))
 
-	//we have to wait to be sure txHash1Requested is not incremented by a late call
-	time.Sleep(core.ExtraDelayForRequestBlockInfo + time.Second)
+	assert.Equal(t, firstNonce, sp.LastNotarizedHdrForShard(core.MetachainShardId).GetNonce())
 
-	assert.Equal(t, int32(0), atomic.LoadInt32(&miniBlockHash1Requested))
-	assert.Equal(t, int32(1), atomic.LoadInt32(&miniBlockHash2Requested))
-	assert.Equal(t, int32(1), atomic.LoadInt32(&miniBlockHash2Requested))
-}
+	// put headers in pool
+	datapool.Headers().AddHeader(currHash, currHdr)
+	datapool.Headers().AddHeader(prevHash, prevHdr)
 
-//--------- receivedMetaBlockNoMissingMiniBlocks
-func TestShardProcessor_ReceivedMetaBlockNoMissingMiniBlocksShouldPass(t *testing.T) {
-	t.Parallel()
+	sp.CreateBlockStarted()
+	sp.SetHdrForCurrentBlock(currHash, currHdr, true)
+	sp.SetHdrForCurrentBlock(prevHash, prevHdr, true)
 
-	hasher := mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
-	datapool := testscommon.NewPoolsHolderMock()
+	hashes = make([][]byte, 0)
+	hashes = append(hashes, currHash)
+	hashes = append(hashes, prevHash)
+	blockHeader = &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
 
-	//we will have a metablock that will return 3 miniblock hashes
-	//1 miniblock hash will be in cache
-	//2 will be requested on network
+	processedMetaHdrs, err = sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
+	assert.Nil(t, err)
 
-	miniBlockHash1 := []byte("miniblock hash 1 found in cache")
+	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
+	assert.Nil(t, err)
 
-	metaBlock := &block.MetaBlock{
-		Nonce: 1,
-		Round: 1,
-		ShardInfo: []block.ShardData{
-			{
-				ShardID: 1,
-				ShardMiniBlockHeaders: []block.MiniBlockHeader{
-					{
-						Hash:            miniBlockHash1,
-						SenderShardID:   1,
-						ReceiverShardID: 0,
-					},
-				},
-			},
-		}}
+	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
+	wg.Wait()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4), atomic.LoadUint32(&putCalledNr))
 
-	//put this metaBlock inside datapool
-	metaBlockHash := []byte("metablock hash")
-	datapool.Headers().AddHeader(metaBlockHash, metaBlock)
-	//put the existing miniblock inside datapool
-	datapool.MiniBlocks().Put(miniBlockHash1, &block.MiniBlock{}, 0)
+	assert.Equal(t, currHdr, sp.LastNotarizedHdrForShard(core.MetachainShardId))
+}
 
-	noOfMissingMiniBlocks := int32(0)
+func TestShardProcessor_GetOrderedProcessedMetaBlocksAndHashesFromHeaderShouldAlignTheHashes(t *testing.T) {
+	t.Parallel()
 
-	requestHandler := &mock.RequestHandlerStub{
-		RequestMiniBlockHandlerCalled: func(destShardID uint32, miniblockHash []byte) {
-			atomic.AddInt32(&noOfMissingMiniBlocks, 1)
-		},
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	metaBlocks := []*block.MetaBlock{{Nonce: 3, Round: 3}, {Nonce: 1, Round: 1}, {Nonce: 2, Round: 2}}
+	metaBlocksHashes := make([][]byte, 0, len(metaBlocks))
+	for _, metaBlock := range metaBlocks {
+		metaBlockHash := []byte(fmt.Sprintf("meta block hash %d", metaBlock.Nonce))
+		sp.SetHdrForCurrentBlock(metaBlockHash, metaBlock, true)
+		metaBlocksHashes = append(metaBlocksHashes, metaBlockHash)
 	}
+	blockHeader := &block.Header{MetaBlockHashes: metaBlocksHashes}
 
-	tc, _ := coordinator.NewTransactionCoordinator(
-		&mock.HasherMock{},
-		&mock.MarshalizerMock{},
-		mock.NewMultiShardsCoordinatorMock(3),
-		initAccountsMock(),
-		datapool.MiniBlocks(),
-		requestHandler,
-		&mock.PreProcessorContainerMock{},
-		&mock.InterimProcessorContainerMock{},
-		&mock.GasHandlerMock{},
-		&mock.FeeAccumulatorStub{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-		&mock.FeeHandlerStub{},
-		&mock.TxTypeHandlerMock{},
-		0,
-	)
+	processedMetaHdrs, processedMetaHdrsHashes, err := sp.GetOrderedProcessedMetaBlocksAndHashesFromHeader(blockHeader)
+	require.Nil(t, err)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.RequestHandler = requestHandler
-	arguments.TxCoordinator = tc
+	assert.Equal(t, []data.HeaderHandler{metaBlocks[1], metaBlocks[2], metaBlocks[0]}, processedMetaHdrs)
+	assert.Equal(t, [][]byte{metaBlocksHashes[1], metaBlocksHashes[2], metaBlocksHashes[0]}, processedMetaHdrsHashes)
+}
 
-	sp, _ := blproc.NewShardProcessor(arguments)
-	sp.ReceivedMetaBlock(metaBlock, metaBlockHash)
+func createShardData(hasher hashing.Hasher, marshalizer marshal.Marshalizer, miniBlocks []block.MiniBlock) []block.ShardData {
+	shardData := make([]block.ShardData, len(miniBlocks))
+	for i := 0; i < len(miniBlocks); i++ {
+		hashed, _ := core.CalculateHash(marshalizer, hasher, &miniBlocks[i])
 
-	//we have to wait to be sure txHash1Requested is not incremented by a late call
-	time.Sleep(core.ExtraDelayForRequestBlockInfo + time.Second)
+		shardMBHeader := block.MiniBlockHeader{
+			ReceiverShardID: miniBlocks[i].ReceiverShardID,
+			SenderShardID:   miniBlocks[i].SenderShardID,
+			TxCount:         uint32(len(miniBlocks[i].TxHashes)),
+			Hash:            hashed,
+		}
+		shardMBHeaders := make([]block.MiniBlockHeader, 0)
+		shardMBHeaders = append(shardMBHeaders, shardMBHeader)
 
-	assert.Equal(t, int32(0), atomic.LoadInt32(&noOfMissingMiniBlocks))
+		shardData[0].ShardID = miniBlocks[i].SenderShardID
+		shardData[0].TxCount = 10
+		shardData[0].HeaderHash = []byte("headerHash")
+		shardData[0].ShardMiniBlockHeaders = shardMBHeaders
+	}
+
+	return shardData
 }
 
-//--------- createAndProcessCrossMiniBlocksDstMe
-func TestShardProcessor_CreateAndProcessCrossMiniBlocksDstMe(t *testing.T) {
+func TestShardProcessor_RemoveAndSaveLastNotarizedMetaHdrNotAllMBFinished(t *testing.T) {
 	t.Parallel()
 
-	tdp := testscommon.NewPoolsHolderMock()
-	txHash := []byte("tx_hash1")
-	tdp.Transactions().AddData(txHash, &transaction.Transaction{}, 0, process.ShardCacherIdentifier(1, 0))
-
-	hasher := &mock.HasherStub{}
+	hasher := mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
-
-	meta := &block.MetaBlock{
-		Nonce:        1,
-		ShardInfo:    make([]block.ShardData, 0),
-		Round:        1,
-		PrevRandSeed: []byte("roothash"),
+	datapool := testscommon.NewPoolsHolderMock()
+	forkDetector := &mock.ForkDetectorMock{}
+	highNonce := uint64(500)
+	forkDetector.GetHighestFinalBlockNonceCalled = func() uint64 {
+		return highNonce
 	}
-	metaBytes, _ := marshalizer.Marshal(meta)
-	metaHash := hasher.Compute(string(metaBytes))
 
-	tdp.Headers().AddHeader(metaHash, meta)
-
-	haveTimeTrue := func() bool {
-		return true
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	putCalledNr := uint32(0)
+	store := &mock.ChainStorerMock{
+		PutCalled: func(unitType dataRetriever.UnitType, key []byte, value []byte) error {
+			atomic.AddUint32(&putCalledNr, 1)
+			wg.Done()
+			return nil
+		},
 	}
 
+	shardNr := uint32(5)
+
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
+	arguments.DataPool = datapool
+	arguments.Store = store
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
+	arguments.ForkDetector = forkDetector
+	startHeaders := createGenesisBlocks(arguments.ShardCoordinator)
+	arguments.BlockTracker = mock.NewBlockTrackerMock(arguments.ShardCoordinator, startHeaders)
 	sp, _ := blproc.NewShardProcessor(arguments)
-	miniBlockSlice, usedMetaHdrsHashes, noOfTxs, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
-	assert.Equal(t, err == nil, true)
-	assert.Equal(t, len(miniBlockSlice) == 0, true)
-	assert.Equal(t, usedMetaHdrsHashes, uint32(0))
-	assert.Equal(t, noOfTxs, uint32(0))
-}
-
-func TestShardProcessor_CreateAndProcessCrossMiniBlocksDstMeProcessPartOfMiniBlocksInMetaBlock(t *testing.T) {
-	t.Parallel()
 
-	haveTimeTrue := func() bool {
-		return true
-	}
-	tdp := testscommon.NewPoolsHolderMock()
-	destShardId := uint32(2)
+	prevRandSeed := []byte("prevrand")
+	currRandSeed := []byte("currrand")
+	notarizedHdrs := sp.NotarizedHdrs()
+	firstNonce := uint64(44)
 
-	hasher := &mock.HasherStub{}
-	marshalizer := &mock.MarshalizerMock{}
-	miniblocks := make([]*block.MiniBlock, 6)
+	lastHdr := &block.MetaBlock{Round: 9,
+		Nonce:    firstNonce,
+		RandSeed: prevRandSeed}
+	notarizedHdrs[core.MetachainShardId] = append(notarizedHdrs[core.MetachainShardId], lastHdr)
 
 	txHash := []byte("txhash")
 	txHashes := make([][]byte, 0)
 	txHashes = append(txHashes, txHash)
-
 	miniblock1 := block.MiniBlock{
 		ReceiverShardID: 0,
 		SenderShardID:   1,
@@ -2613,1915 +7649,1874 @@ func TestShardProcessor_CreateAndProcessCrossMiniBlocksDstMeProcessPartOfMiniBlo
 		SenderShardID:   2,
 		TxHashes:        txHashes,
 	}
+	miniblock3 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   3,
+		TxHashes:        txHashes,
+	}
+	miniblock4 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   4,
+		TxHashes:        txHashes,
+	}
+	mbHeaders := make([]block.MiniBlockHeader, 0)
 
-	miniBlocks := make([]block.MiniBlock, 0)
-	miniBlocks = append(miniBlocks, miniblock1, miniblock2)
+	hashed, _ := core.CalculateHash(marshalizer, hasher, &miniblock1)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-	destShards := []uint32{1, 3, 4}
-	for i := 0; i < 6; i++ {
-		miniblocks[i], _ = createDummyMiniBlock(fmt.Sprintf("tx hash %d", i), marshalizer, hasher, destShardId, destShards[i/2])
-	}
+	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock2)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-	//put 2 metablocks in pool
-	meta := &block.MetaBlock{
-		Nonce:        1,
-		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks),
-		Round:        1,
-		PrevRandSeed: []byte("roothash"),
-	}
+	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock3)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-	mb1Hash := []byte("meta block 1")
-	tdp.Headers().AddHeader(mb1Hash, meta)
+	miniBlocks := make([]block.MiniBlock, 0)
+	miniBlocks = append(miniBlocks, miniblock1, miniblock2)
+	//header shard 0
+	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
+	prevHdr := &block.MetaBlock{
+		Round:        10,
+		Nonce:        45,
+		PrevRandSeed: prevRandSeed,
+		RandSeed:     currRandSeed,
+		PrevHash:     prevHash,
+		RootHash:     []byte("prevRootHash"),
+		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
 
-	meta = &block.MetaBlock{
-		Nonce:     2,
-		ShardInfo: createShardData(hasher, marshalizer, miniBlocks),
-		Round:     2,
-	}
+	miniBlocks = make([]block.MiniBlock, 0)
+	miniBlocks = append(miniBlocks, miniblock3, miniblock4)
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+	currHdr := &block.MetaBlock{
+		Round:        11,
+		Nonce:        46,
+		PrevRandSeed: currRandSeed,
+		RandSeed:     []byte("nextrand"),
+		PrevHash:     prevHash,
+		RootHash:     []byte("currRootHash"),
+		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
+	currHash, _ := sp.ComputeHeaderHash(currHdr)
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
 
-	mb2Hash := []byte("meta block 2")
-	tdp.Headers().AddHeader(mb2Hash, meta)
+	// put headers in pool
+	datapool.Headers().AddHeader(currHash, currHdr)
+	datapool.Headers().AddHeader(prevHash, prevHdr)
 
-	meta = &block.MetaBlock{
-		Nonce:        3,
-		ShardInfo:    make([]block.ShardData, 0),
-		Round:        3,
-		PrevRandSeed: []byte("roothash"),
-	}
+	sp.SetHdrForCurrentBlock(currHash, currHdr, true)
+	sp.SetHdrForCurrentBlock(prevHash, prevHdr, true)
 
-	mb3Hash := []byte("meta block 3")
-	tdp.Headers().AddHeader(mb3Hash, meta)
+	hashes := make([][]byte, 0)
+	hashes = append(hashes, currHash)
+	hashes = append(hashes, prevHash)
+	blockHeader := &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	sp, _ := blproc.NewShardProcessor(arguments)
+	processedMetaHdrs, err := sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
+	assert.Nil(t, err)
 
-	miniBlocksReturned, usedMetaHdrsHashes, nrTxAdded, err := sp.CreateAndProcessMiniBlocksDstMe(haveTimeTrue)
+	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
+	assert.Nil(t, err)
 
-	assert.Equal(t, 0, len(miniBlocksReturned))
-	assert.Equal(t, uint32(0), usedMetaHdrsHashes)
-	assert.Equal(t, uint32(0), nrTxAdded)
+	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
+	wg.Wait()
 	assert.Nil(t, err)
-}
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&putCalledNr))
 
-//------- createMiniBlocks
+	assert.Equal(t, prevHdr, sp.LastNotarizedHdrForShard(core.MetachainShardId))
+}
 
-func TestShardProcessor_CreateMiniBlocksShouldWorkWithIntraShardTxs(t *testing.T) {
+func TestShardProcessor_RemoveAndSaveLastNotarizedMetaHdrAllMBFinished(t *testing.T) {
 	t.Parallel()
 
 	hasher := mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
 	datapool := testscommon.NewPoolsHolderMock()
-
-	//we will have a 3 txs in pool
-
-	txHash1 := []byte("tx hash 1")
-	txHash2 := []byte("tx hash 2")
-	txHash3 := []byte("tx hash 3")
-
-	senderShardId := uint32(0)
-	receiverShardId := uint32(0)
-
-	tx1Nonce := uint64(45)
-	tx2Nonce := uint64(46)
-	tx3Nonce := uint64(47)
-
-	//put the existing tx inside datapool
-	cacheId := process.ShardCacherIdentifier(senderShardId, receiverShardId)
-	datapool.Transactions().AddData(txHash1, &transaction.Transaction{
-		Nonce: tx1Nonce,
-		Data:  txHash1,
-	}, 0, cacheId)
-	datapool.Transactions().AddData(txHash2, &transaction.Transaction{
-		Nonce: tx2Nonce,
-		Data:  txHash2,
-	}, 0, cacheId)
-	datapool.Transactions().AddData(txHash3, &transaction.Transaction{
-		Nonce: tx3Nonce,
-		Data:  txHash3,
-	}, 0, cacheId)
-
-	tx1ExecutionResult := uint64(0)
-	tx2ExecutionResult := uint64(0)
-	tx3ExecutionResult := uint64(0)
-
-	txProcessorMock := &mock.TxProcessorMock{
-		ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
-			//execution, in this context, means moving the tx nonce to itx corresponding execution result variable
-			if bytes.Equal(transaction.Data, txHash1) {
-				tx1ExecutionResult = transaction.Nonce
-			}
-			if bytes.Equal(transaction.Data, txHash2) {
-				tx2ExecutionResult = transaction.Nonce
-			}
-			if bytes.Equal(transaction.Data, txHash3) {
-				tx3ExecutionResult = transaction.Nonce
-			}
-
-			return 0, nil
-		},
+	forkDetector := &mock.ForkDetectorMock{}
+	highNonce := uint64(500)
+	forkDetector.GetHighestFinalBlockNonceCalled = func() uint64 {
+		return highNonce
 	}
-	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
-	accntAdapter := &mock.AccountsStub{
-		RevertToSnapshotCalled: func(snapshot int) error {
-			assert.Fail(t, "revert should have not been called")
+
+	wg := sync.WaitGroup{}
+	wg.Add(4)
+	putCalledNr := uint32(0)
+	store := &mock.ChainStorerMock{
+		PutCalled: func(unitType dataRetriever.UnitType, key []byte, value []byte) error {
+			atomic.AddUint32(&putCalledNr, 1)
+			wg.Done()
 			return nil
 		},
-		JournalLenCalled: func() int {
-			return 0
-		},
 	}
 
-	totalGasConsumed := uint64(0)
-	factory, _ := shard.NewPreProcessorsContainerFactory(
-		shardCoordinator,
-		initStore(),
-		marshalizer,
-		hasher,
-		datapool,
-		createMockPubkeyConverter(),
-		accntAdapter,
-		&mock.RequestHandlerStub{},
-		txProcessorMock,
-		&mock.SCProcessorMock{},
-		&mock.SmartContractResultsProcessorMock{},
-		&mock.RewardTxProcessorMock{},
-		&mock.FeeHandlerStub{
-			ComputeGasLimitCalled: func(tx process.TransactionWithFeeHandler) uint64 {
-				return 0
-			},
-			MaxGasLimitPerBlockCalled: func() uint64 {
-				return MaxGasLimitPerBlock
-			},
-		},
-		&mock.GasHandlerMock{
-			SetGasConsumedCalled: func(gasConsumed uint64, hash []byte) {
-				totalGasConsumed += gasConsumed
-			},
-			TotalGasConsumedCalled: func() uint64 {
-				return totalGasConsumed
-			},
-			ComputeGasConsumedByTxCalled: func(txSenderShardId uint32, txReceiverSharedId uint32, txHandler data.TransactionHandler) (uint64, uint64, error) {
-				return 0, 0, nil
-			},
-			SetGasRefundedCalled: func(gasRefunded uint64, hash []byte) {},
-			TotalGasRefundedCalled: func() uint64 {
-				return 0
-			},
-		},
-		&mock.BlockTrackerMock{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-	)
-	container, _ := factory.Create()
-
-	tc, err := coordinator.NewTransactionCoordinator(
-		&mock.HasherMock{},
-		&mock.MarshalizerMock{},
-		mock.NewMultiShardsCoordinatorMock(3),
-		accntAdapter,
-		datapool.MiniBlocks(),
-		&mock.RequestHandlerStub{},
-		container,
-		&mock.InterimProcessorContainerMock{},
-		&mock.GasHandlerMock{},
-		&mock.FeeAccumulatorStub{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-		&mock.FeeHandlerStub{},
-		&mock.TxTypeHandlerMock{},
-		0,
-	)
-	assert.Nil(t, err)
+	shardNr := uint32(5)
 
 	arguments := CreateMockArgumentsMultiShard()
 	arguments.DataPool = datapool
+	arguments.Store = store
 	arguments.Hasher = hasher
 	arguments.Marshalizer = marshalizer
-	arguments.AccountsDB[state.UserAccountsState] = accntAdapter
-	arguments.TxCoordinator = tc
-	bp, _ := blproc.NewShardProcessor(arguments)
+	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
+	arguments.ForkDetector = forkDetector
+	startHeaders := createGenesisBlocks(arguments.ShardCoordinator)
+	arguments.BlockTracker = mock.NewBlockTrackerMock(arguments.ShardCoordinator, startHeaders)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	prevRandSeed := []byte("prevrand")
+	currRandSeed := []byte("currrand")
+	notarizedHdrs := sp.NotarizedHdrs()
+	firstNonce := uint64(44)
+
+	lastHdr := &block.MetaBlock{Round: 9,
+		Nonce:    firstNonce,
+		RandSeed: prevRandSeed}
+	notarizedHdrs[core.MetachainShardId] = append(notarizedHdrs[core.MetachainShardId], lastHdr)
+
+	txHash := []byte("txhash")
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock1 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
+	}
+	miniblock2 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   2,
+		TxHashes:        txHashes,
+	}
+	miniblock3 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   3,
+		TxHashes:        txHashes,
+	}
+	miniblock4 := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   4,
+		TxHashes:        txHashes,
+	}
 
-	blockBody, err := bp.CreateMiniBlocks(func() bool { return true })
+	mbHeaders := make([]block.MiniBlockHeader, 0, 4)
 
-	assert.Nil(t, err)
-	//testing execution
-	assert.Equal(t, tx1Nonce, tx1ExecutionResult)
-	assert.Equal(t, tx2Nonce, tx2ExecutionResult)
-	assert.Equal(t, tx3Nonce, tx3ExecutionResult)
-	//one miniblock output
-	assert.Equal(t, 1, len(blockBody.MiniBlocks))
-	//miniblock should have 3 txs
-	assert.Equal(t, 3, len(blockBody.MiniBlocks[0].TxHashes))
-	//testing all 3 hashes are present in block body
-	assert.True(t, isInTxHashes(txHash1, blockBody.MiniBlocks[0].TxHashes))
-	assert.True(t, isInTxHashes(txHash2, blockBody.MiniBlocks[0].TxHashes))
-	assert.True(t, isInTxHashes(txHash3, blockBody.MiniBlocks[0].TxHashes))
-}
+	hashed, _ := core.CalculateHash(marshalizer, hasher, &miniblock1)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-func TestShardProcessor_GetProcessedMetaBlockFromPoolShouldWork(t *testing.T) {
-	t.Parallel()
+	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock2)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-	//we have 3 metablocks in pool each containing 2 miniblocks.
-	//blockbody will have 2 + 1 miniblocks from 2 out of the 3 metablocks
-	//The test should remove only one metablock
+	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock3)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-	destShardId := uint32(2)
+	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock4)
+	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
 
-	hasher := mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
-	datapool := testscommon.NewPoolsHolderMock()
+	miniBlocks := make([]block.MiniBlock, 0)
+	miniBlocks = append(miniBlocks, miniblock1, miniblock2)
+	//header shard 0
+	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
+	prevHdr := &block.MetaBlock{
+		Round:        10,
+		Nonce:        45,
+		PrevRandSeed: prevRandSeed,
+		RandSeed:     currRandSeed,
+		PrevHash:     prevHash,
+		RootHash:     []byte("prevRootHash"),
+		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
 
-	miniblockHashes := make([][]byte, 6)
+	miniBlocks = make([]block.MiniBlock, 0)
+	miniBlocks = append(miniBlocks, miniblock3, miniblock4)
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+	currHdr := &block.MetaBlock{
+		Round:        11,
+		Nonce:        46,
+		PrevRandSeed: currRandSeed,
+		RandSeed:     []byte("nextrand"),
+		PrevHash:     prevHash,
+		RootHash:     []byte("currRootHash"),
+		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
+	currHash, _ := sp.ComputeHeaderHash(currHdr)
+	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
 
-	destShards := []uint32{1, 3, 4}
-	for i := 0; i < 6; i++ {
-		_, hash := createDummyMiniBlock(fmt.Sprintf("tx hash %d", i), marshalizer, hasher, destShardId, destShards[i/2])
-		miniblockHashes[i] = hash
-	}
+	// put headers in pool
+	datapool.Headers().AddHeader(currHash, currHdr)
+	datapool.Headers().AddHeader(prevHash, prevHdr)
+	datapool.Headers().AddHeader([]byte("shouldNotRemove"), &block.MetaBlock{
+		Round:        12,
+		PrevRandSeed: []byte("nextrand"),
+		PrevHash:     currHash,
+		Nonce:        47})
 
-	//put 3 metablocks in pool
-	metaBlockHash1 := []byte("meta block 1")
-	metaBlock1 := createDummyMetaBlock(destShardId, destShards[0], miniblockHashes[0], miniblockHashes[1])
-	datapool.Headers().AddHeader(metaBlockHash1, metaBlock1)
+	sp.SetHdrForCurrentBlock(currHash, currHdr, true)
+	sp.SetHdrForCurrentBlock(prevHash, prevHdr, true)
 
-	metaBlockHash2 := []byte("meta block 2")
-	metaBlock2 := createDummyMetaBlock(destShardId, destShards[1], miniblockHashes[2], miniblockHashes[3])
-	datapool.Headers().AddHeader(metaBlockHash2, metaBlock2)
+	hashes := make([][]byte, 0)
+	hashes = append(hashes, currHash)
+	hashes = append(hashes, prevHash)
+	blockHeader := &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
 
-	metaBlockHash3 := []byte("meta block 3")
-	metaBlock3 := createDummyMetaBlock(destShardId, destShards[2], miniblockHashes[4], miniblockHashes[5])
-	datapool.Headers().AddHeader(metaBlockHash3, metaBlock3)
+	processedMetaHdrs, err := sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(processedMetaHdrs))
 
-	shardCoordinator := mock.NewMultipleShardsCoordinatorMock()
-	shardCoordinator.CurrentShard = destShardId
-	shardCoordinator.SetNoShards(destShardId + 1)
+	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
+	assert.Nil(t, err)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.ShardCoordinator = shardCoordinator
-	arguments.ForkDetector = &mock.ForkDetectorMock{
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return 0
-		},
-	}
-	bp, _ := blproc.NewShardProcessor(arguments)
+	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
+	wg.Wait()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4), atomic.LoadUint32(&putCalledNr))
 
-	bp.SetHdrForCurrentBlock(metaBlockHash1, metaBlock1, true)
-	bp.SetHdrForCurrentBlock(metaBlockHash2, metaBlock2, true)
-	bp.SetHdrForCurrentBlock(metaBlockHash3, metaBlock3, true)
+	assert.Equal(t, currHdr, sp.LastNotarizedHdrForShard(core.MetachainShardId))
+}
 
-	//create mini block headers with first 3 miniblocks from miniblocks var
-	mbHeaders := []block.MiniBlockHeader{
-		{Hash: miniblockHashes[0]},
-		{Hash: miniblockHashes[1]},
-		{Hash: miniblockHashes[2]},
+func createOneHeaderOneBody() (*block.Header, *block.Body) {
+	txHash := []byte("tx_hash1")
+	rootHash := []byte("rootHash")
+	body := &block.Body{}
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
 	}
+	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
 
-	hashes := [][]byte{
-		metaBlockHash1,
-		metaBlockHash2,
-		metaBlockHash3,
-	}
+	hasher := &mock.HasherStub{}
+	marshalizer := &mock.MarshalizerMock{}
 
-	blockHeader := &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
+	mbbytes, _ := marshalizer.Marshal(&miniblock)
+	mbHash := hasher.Compute(string(mbbytes))
+	mbHdr := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash}
+	mbHdrs := make([]block.MiniBlockHeader, 0)
+	mbHdrs = append(mbHdrs, mbHdr)
 
-	err := bp.AddProcessedCrossMiniBlocksFromHeader(blockHeader)
+	hdr := &block.Header{
+		Nonce:            1,
+		PrevHash:         []byte(""),
+		Signature:        []byte("signature"),
+		PubKeysBitmap:    []byte("00110"),
+		ShardID:          0,
+		RootHash:         rootHash,
+		MiniBlockHeaders: mbHdrs,
+	}
 
-	assert.Nil(t, err)
+	return hdr, body
 }
 
-func TestBlockProcessor_RestoreBlockIntoPoolsShouldErrNilBlockHeader(t *testing.T) {
+func TestShardProcessor_CheckHeaderBodyCorrelationReceiverMissmatch(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
 
+	hdr, body := createOneHeaderOneBody()
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	be, _ := blproc.NewShardProcessor(arguments)
-	err := be.RestoreBlockIntoPools(nil, nil)
-	assert.NotNil(t, err)
-	assert.Equal(t, process.ErrNilBlockHeader, err)
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	hdr.MiniBlockHeaders[0].ReceiverShardID = body.MiniBlocks[0].ReceiverShardID + 1
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
 }
 
-func TestBlockProcessor_RestoreBlockIntoPoolsShouldWorkNilTxBlockBody(t *testing.T) {
+func TestShardProcessor_CheckHeaderBodyCorrelationSenderMissmatch(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
 
+	hdr, body := createOneHeaderOneBody()
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.RestoreBlockIntoPools(&block.Header{}, nil)
-	assert.Nil(t, err)
+	hdr.MiniBlockHeaders[0].SenderShardID = body.MiniBlocks[0].SenderShardID + 1
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
 }
 
-func TestShardProcessor_RestoreBlockIntoPoolsShouldWork(t *testing.T) {
-	t.Parallel()
-
-	txHash := []byte("tx hash 1")
-
-	datapool := testscommon.NewPoolsHolderMock()
-	marshalizerMock := &mock.MarshalizerMock{}
-	hasherMock := &mock.HasherStub{}
-
-	body := &block.Body{}
-	tx := &transaction.Transaction{
-		Nonce: 1,
-		Value: big.NewInt(0),
-	}
-	buffTx, _ := marshalizerMock.Marshal(tx)
-
-	store := &mock.ChainStorerMock{
-		GetAllCalled: func(unitType dataRetriever.UnitType, keys [][]byte) (map[string][]byte, error) {
-			m := make(map[string][]byte)
-			m[string(txHash)] = buffTx
-			return m, nil
-		},
-	}
-
-	factory, _ := shard.NewPreProcessorsContainerFactory(
-		mock.NewMultiShardsCoordinatorMock(3),
-		store,
-		marshalizerMock,
-		hasherMock,
-		datapool,
-		createMockPubkeyConverter(),
-		initAccountsMock(),
-		&mock.RequestHandlerStub{},
-		&mock.TxProcessorMock{},
-		&mock.SCProcessorMock{},
-		&mock.SmartContractResultsProcessorMock{},
-		&mock.RewardTxProcessorMock{},
-		&mock.FeeHandlerStub{},
-		&mock.GasHandlerMock{},
-		&mock.BlockTrackerMock{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-	)
-	container, _ := factory.Create()
-
-	tc, err := coordinator.NewTransactionCoordinator(
-		&mock.HasherMock{},
-		&mock.MarshalizerMock{},
-		mock.NewMultiShardsCoordinatorMock(3),
-		initAccountsMock(),
-		datapool.MiniBlocks(),
-		&mock.RequestHandlerStub{},
-		container,
-		&mock.InterimProcessorContainerMock{},
-		&mock.GasHandlerMock{},
-		&mock.FeeAccumulatorStub{},
-		&mock.BlockSizeComputationStub{},
-		&mock.BalanceComputationStub{},
-		&mock.FeeHandlerStub{},
-		&mock.TxTypeHandlerMock{},
-		0,
-	)
-	assert.Nil(t, err)
-
+func TestShardProcessor_CheckHeaderBodyCorrelationTxCountMissmatch(t *testing.T) {
+	t.Parallel()
+
+	hdr, body := createOneHeaderOneBody()
+
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Store = store
-	arguments.Hasher = hasherMock
-	arguments.Marshalizer = marshalizerMock
-	arguments.TxCoordinator = tc
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
-	}
-	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+	hdr.MiniBlockHeaders[0].TxCount = uint32(len(body.MiniBlocks[0].TxHashes) + 1)
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+}
 
-	miniblockHash := []byte("mini block hash 1")
-	hasherMock.ComputeCalled = func(s string) []byte {
-		return miniblockHash
-	}
+func TestShardProcessor_CheckHeaderBodyCorrelationHashMissmatch(t *testing.T) {
+	t.Parallel()
 
-	metablockHash := []byte("meta block hash 1")
-	metablockHeader := createDummyMetaBlock(0, 1, miniblockHash)
-	datapool.Headers().AddHeader(metablockHash, metablockHeader)
+	hdr, body := createOneHeaderOneBody()
+	arguments := CreateMockArgumentsMultiShard()
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	store.GetStorerCalled = func(unitType dataRetriever.UnitType) storage.Storer {
-		return &mock.StorerStub{
-			RemoveCalled: func(key []byte) error {
-				return nil
-			},
-			GetCalled: func(key []byte) ([]byte, error) {
-				return marshalizerMock.Marshal(metablockHeader)
-			},
-		}
-	}
+	hdr.MiniBlockHeaders[0].Hash = []byte("wrongHash")
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+}
 
-	miniBlockHeader := block.MiniBlockHeader{
-		Hash:            miniblockHash,
-		SenderShardID:   miniblock.SenderShardID,
-		ReceiverShardID: miniblock.ReceiverShardID,
-	}
+func TestShardProcessor_CheckHeaderBodyCorrelationReservedFieldMissmatch(t *testing.T) {
+	t.Parallel()
 
-	err = sp.RestoreBlockIntoPools(&block.Header{MetaBlockHashes: [][]byte{metablockHash}, MiniBlockHeaders: []block.MiniBlockHeader{miniBlockHeader}}, body)
-	assert.Nil(t, err)
+	hdr, body := createOneHeaderOneBody()
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MiniBlockReservedCheckEnableEpoch = 0
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	miniblockFromPool, _ := datapool.MiniBlocks().Get(miniblockHash)
-	txFromPool, _ := datapool.Transactions().SearchFirstData(txHash)
-	assert.Nil(t, err)
-	assert.Equal(t, &miniblock, miniblockFromPool)
-	assert.Equal(t, tx, txFromPool)
+	hdr.MiniBlockHeaders[0].Reserved = []byte("reserved")
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Equal(t, process.ErrMiniBlockReservedFieldMismatch, err)
 }
 
-func TestShardProcessor_DecodeBlockBody(t *testing.T) {
+func TestShardProcessor_CheckHeaderBodyCorrelationReservedFieldMissmatchBeforeEnableEpochShouldPass(t *testing.T) {
 	t.Parallel()
 
-	tdp := initDataPool([]byte("tx_hash1"))
-	marshalizerMock := &mock.MarshalizerMock{}
+	hdr, body := createOneHeaderOneBody()
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Marshalizer = marshalizerMock
+	arguments.MiniBlockReservedCheckEnableEpoch = hdr.Epoch + 1
 	sp, _ := blproc.NewShardProcessor(arguments)
-	body := &block.Body{}
-	body.MiniBlocks = append(body.MiniBlocks, &block.MiniBlock{ReceiverShardID: 69})
-	message, err := marshalizerMock.Marshal(body)
+
+	hdr.MiniBlockHeaders[0].Reserved = []byte("reserved")
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
 	assert.Nil(t, err)
+}
 
-	bodyNil := &block.Body{}
-	dcdBlk := sp.DecodeBlockBody(nil)
-	assert.Equal(t, bodyNil, dcdBlk)
+func TestShardProcessor_CheckHeaderBodyCorrelationShouldPass(t *testing.T) {
+	t.Parallel()
 
-	dcdBlk = sp.DecodeBlockBody(message)
-	assert.Equal(t, body, dcdBlk)
-	assert.Equal(t, uint32(69), body.MiniBlocks[0].ReceiverShardID)
+	hdr, body := createOneHeaderOneBody()
+	arguments := CreateMockArgumentsMultiShard()
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Nil(t, err)
 }
 
-func TestShardProcessor_DecodeBlockHeader(t *testing.T) {
+func TestShardProcessor_CheckHeaderBodyCorrelationDuplicateMiniBlocksShouldErr(t *testing.T) {
 	t.Parallel()
-	tdp := initDataPool([]byte("tx_hash1"))
-	marshalizerMock := &mock.MarshalizerMock{}
 
+	hdr, body := createOneHeaderOneBody()
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = tdp
-	arguments.Marshalizer = marshalizerMock
-	sp, err := blproc.NewShardProcessor(arguments)
-	assert.Nil(t, err)
-	hdr := &block.Header{}
-	hdr.Nonce = 1
-	hdr.TimeStamp = uint64(0)
-	hdr.Signature = []byte("A")
-	hdr.AccumulatedFees = big.NewInt(0)
-	hdr.DeveloperFees = big.NewInt(0)
-	_, err = marshalizerMock.Marshal(hdr)
-	assert.Nil(t, err)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	message, err := marshalizerMock.Marshal(hdr)
-	assert.Nil(t, err)
+	body.MiniBlocks = append(body.MiniBlocks, body.MiniBlocks[0])
+	hdr.MiniBlockHeaders = append(hdr.MiniBlockHeaders, hdr.MiniBlockHeaders[0])
 
-	dcdHdr := sp.DecodeBlockHeader(nil)
-	assert.Nil(t, dcdHdr)
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.Equal(t, process.ErrDuplicateMiniBlockInBody, err)
+}
 
-	dcdHdr = sp.DecodeBlockHeader(message)
-	assert.Equal(t, hdr, dcdHdr)
-	assert.Equal(t, []byte("A"), dcdHdr.GetSignature())
+func TestShardProcessor_CheckHeaderBodyCorrelationNilMiniBlock(t *testing.T) {
+	t.Parallel()
+
+	hdr, body := createOneHeaderOneBody()
+	arguments := CreateMockArgumentsMultiShard()
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	body.MiniBlocks[0] = nil
+
+	err := sp.CheckHeaderBodyCorrelation(hdr, body)
+	assert.NotNil(t, err)
+	assert.Equal(t, process.ErrNilMiniBlock, err)
 }
 
-func TestShardProcessor_IsHdrConstructionValid(t *testing.T) {
+func TestShardProcessor_RestoreMetaBlockIntoPoolShouldPass(t *testing.T) {
 	t.Parallel()
 
-	hasher := mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
-	datapool := initDataPool([]byte("tx_hash1"))
 
-	shardNr := uint32(5)
+	poolFake := testscommon.NewPoolsHolderMock()
+
+	metaBlock := block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: make([]block.ShardData, 0),
+	}
+
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
+	arguments.DataPool = poolFake
+	arguments.Store = &mock.ChainStorerMock{
+		GetStorerCalled: func(unitType dataRetriever.UnitType) storage.Storer {
+			return &mock.StorerStub{
+				RemoveCalled: func(key []byte) error {
+					return nil
+				},
+				GetCalled: func(key []byte) ([]byte, error) {
+					return marshalizer.Marshal(&metaBlock)
+				},
+			}
+		},
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	prevRandSeed := []byte("prevrand")
-	currRandSeed := []byte("currrand")
-	notarizedHdrs := sp.NotarizedHdrs()
-	lastHdr := &block.MetaBlock{Round: 9,
-		Nonce:    44,
-		RandSeed: prevRandSeed}
-	notarizedHdrs[core.MetachainShardId] = append(notarizedHdrs[core.MetachainShardId], lastHdr)
+	miniblockHashes := make(map[string]uint32)
 
-	//put the existing headers inside datapool
+	meta := &block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: make([]block.ShardData, 0),
+	}
+	hasher := &mock.HasherStub{}
 
-	//header shard 0
-	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
-	prevHdr := &block.MetaBlock{
-		Round:        10,
-		Nonce:        45,
-		PrevRandSeed: prevRandSeed,
-		RandSeed:     currRandSeed,
-		PrevHash:     prevHash,
-		RootHash:     []byte("prevRootHash")}
+	metaBytes, _ := marshalizer.Marshal(meta)
+	hasher.ComputeCalled = func(s string) []byte {
+		return []byte("cool")
+	}
+	metaHash := hasher.Compute(string(metaBytes))
+	metablockHashes := make([][]byte, 0)
+	metablockHashes = append(metablockHashes, metaHash)
 
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
-	currHdr := &block.MetaBlock{
-		Round:        11,
-		Nonce:        46,
-		PrevRandSeed: currRandSeed,
-		RandSeed:     []byte("nextrand"),
-		PrevHash:     prevHash,
-		RootHash:     []byte("currRootHash")}
+	metaBlockRestored, err := poolFake.Headers().GetHeaderByHash(metaHash)
 
-	err := sp.IsHdrConstructionValid(nil, prevHdr)
-	assert.Equal(t, err, process.ErrNilBlockHeader)
+	assert.Equal(t, nil, metaBlockRestored)
+	assert.Error(t, err)
 
-	err = sp.IsHdrConstructionValid(currHdr, nil)
-	assert.Equal(t, err, process.ErrNilBlockHeader)
+	failedHashes, err := sp.RestoreMetaBlockIntoPool(miniblockHashes, metablockHashes)
+
+	metaBlockRestored, _ = poolFake.Headers().GetHeaderByHash(metaHash)
+
+	assert.Equal(t, &metaBlock, metaBlockRestored)
+	assert.Nil(t, err)
+	assert.Empty(t, failedHashes)
+}
+
+func TestShardPreprocessor_getAllMiniBlockDstMeFromMetaShouldPass(t *testing.T) {
+	t.Parallel()
+
+	marshalizer := &mock.MarshalizerMock{}
+
+	txHash := []byte("tx_hash1")
+	txHashes := make([][]byte, 0)
+	txHashes = append(txHashes, txHash)
+	miniblock := block.MiniBlock{
+		ReceiverShardID: 0,
+		SenderShardID:   1,
+		TxHashes:        txHashes,
+	}
+	hasher := &mock.HasherStub{}
+
+	mbbytes, _ := marshalizer.Marshal(&miniblock)
+	mbHash := hasher.Compute(string(mbbytes))
+
+	shardMiniBlock := block.MiniBlockHeader{
+		ReceiverShardID: 0,
+		SenderShardID:   2,
+		TxCount:         uint32(len(txHashes)),
+		Hash:            mbHash,
+	}
+	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
+	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
+	shardHeader := block.ShardData{
+		ShardID:               1,
+		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	}
+	shardHdrs := make([]block.ShardData, 0)
+	shardHdrs = append(shardHdrs, shardHeader)
+	metaBlock := &block.MetaBlock{Nonce: 1, Round: 1, ShardInfo: shardHdrs}
+
+	idp := initDataPool([]byte("tx_hash1"))
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = idp
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	metaBytes, _ := marshalizer.Marshal(metaBlock)
+	hasher.ComputeCalled = func(s string) []byte {
+		return []byte("cool")
+	}
+	metaHash := hasher.Compute(string(metaBytes))
+	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
 
-	currHdr.Nonce = 0
-	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
-	assert.Equal(t, err, process.ErrWrongNonceInBlock)
+	metablockHashes := make([][]byte, 0)
+	metablockHashes = append(metablockHashes, metaHash)
+	header := &block.Header{Nonce: 1, Round: 1, MetaBlockHashes: metablockHashes}
 
-	currHdr.Nonce = 46
-	prevHdr.Nonce = 45
-	prevHdr.Round = currHdr.Round + 1
-	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
-	assert.Equal(t, err, process.ErrLowerRoundInBlock)
+	orderedMetaBlocks, err := sp.GetAllMiniBlockDstMeFromMeta(header)
 
-	prevHdr.Round = currHdr.Round - 1
-	currHdr.Nonce = prevHdr.Nonce + 2
-	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
-	assert.Equal(t, err, process.ErrWrongNonceInBlock)
+	assert.Equal(t, 1, len(orderedMetaBlocks))
+	assert.Equal(t, orderedMetaBlocks[""], metaHash)
+	assert.Nil(t, err)
+}
 
-	currHdr.Nonce = prevHdr.Nonce + 1
-	currHdr.PrevHash = []byte("wronghash")
-	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
-	assert.Equal(t, err, process.ErrBlockHashDoesNotMatch)
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachainNothingToProcess(t *testing.T) {
+	t.Parallel()
 
-	prevHdr.RandSeed = []byte("randomwrong")
-	currHdr.PrevHash, _ = sp.ComputeHeaderHash(prevHdr)
-	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
-	assert.Equal(t, err, process.ErrRandSeedDoesNotMatch)
+	arguments := CreateMockArgumentsMultiShard()
+	sp, _ := blproc.NewShardProcessor(arguments)
+	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(nil)
 
-	currHdr.PrevHash = prevHash
-	prevHdr.RandSeed = currRandSeed
-	prevHdr.RootHash = []byte("prevRootHash")
-	err = sp.IsHdrConstructionValid(currHdr, prevHdr)
-	assert.Nil(t, err)
+	assert.NotNil(t, hdrs)
+	assert.Equal(t, 0, len(hdrs))
 }
 
-func TestShardProcessor_RemoveAndSaveLastNotarizedMetaHdrNoDstMB(t *testing.T) {
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachaiMetaHdrsWithoutOwnHdr(t *testing.T) {
 	t.Parallel()
 
-	hasher := mock.HasherMock{}
+	processedHdrs := make([]data.HeaderHandler, 0)
+	datapool := testscommon.CreatePoolsHolder(1, 0)
+	store := initStore()
+	hasher := &mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
-	datapool := testscommon.NewPoolsHolderMock()
-	forkDetector := &mock.ForkDetectorMock{}
-	highNonce := uint64(500)
-	forkDetector.GetHighestFinalBlockNonceCalled = func() uint64 {
-		return highNonce
-	}
-
-	wg := sync.WaitGroup{}
-	wg.Add(4)
-	putCalledNr := uint32(0)
-	store := &mock.ChainStorerMock{
-		PutCalled: func(unitType dataRetriever.UnitType, key []byte, value []byte) error {
-			atomic.AddUint32(&putCalledNr, 1)
-			wg.Done()
-			return nil
-		},
-	}
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
 
-	shardNr := uint32(5)
 	arguments := CreateMockArgumentsMultiShard()
 	arguments.DataPool = datapool
 	arguments.Store = store
 	arguments.Hasher = hasher
 	arguments.Marshalizer = marshalizer
-	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
-	arguments.ForkDetector = forkDetector
-	startHeaders := createGenesisBlocks(arguments.ShardCoordinator)
-	arguments.BlockTracker = mock.NewBlockTrackerMock(arguments.ShardCoordinator, startHeaders)
-	sp, _ := blproc.NewShardProcessor(arguments)
-
-	prevRandSeed := []byte("prevrand")
-	currRandSeed := []byte("currrand")
-	firstNonce := uint64(44)
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
 
-	lastHdr := &block.MetaBlock{Round: 9,
-		Nonce:    firstNonce,
-		RandSeed: prevRandSeed}
+	sp, err := blproc.NewShardProcessor(arguments)
+	require.Nil(t, err)
 
-	arguments.BlockTracker.AddCrossNotarizedHeader(core.MetachainShardId, lastHdr, nil)
+	shardInfo := make([]block.ShardData, 0)
+	shardInfo = append(shardInfo, block.ShardData{HeaderHash: []byte("hash"), ShardID: 1})
+	datapool.Headers().AddHeader([]byte("hash"), &block.Header{ShardID: 0, Nonce: 1})
 
-	//header shard 0
-	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
-	prevHdr := &block.MetaBlock{
-		Round:        10,
-		Nonce:        45,
-		PrevRandSeed: prevRandSeed,
-		RandSeed:     currRandSeed,
+	prevMetaHdr := genesisBlocks[core.MetachainShardId]
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr := &block.MetaBlock{
+		Nonce:        1,
+		Epoch:        0,
+		Round:        1,
 		PrevHash:     prevHash,
-		RootHash:     []byte("prevRootHash")}
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+		ShardInfo:    shardInfo,
+	}
+	currHash, _ := core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	processedHdrs = append(processedHdrs, currMetaHdr)
 
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
-	currHdr := &block.MetaBlock{
-		Round:        11,
-		Nonce:        46,
-		PrevRandSeed: currRandSeed,
-		RandSeed:     []byte("nextrand"),
+	prevMetaHdr = currMetaHdr
+	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr = &block.MetaBlock{
+		Nonce:        2,
+		Epoch:        0,
+		Round:        2,
 		PrevHash:     prevHash,
-		RootHash:     []byte("currRootHash")}
-	currHash, _ := sp.ComputeHeaderHash(currHdr)
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
-
-	shardHdr := &block.Header{Round: 15}
-	mbHeaders := make([]block.MiniBlockHeader, 0)
-	blockHeader := &block.Header{}
-
-	// test header not in pool and defer called
-	processedMetaHdrs, err := sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
-	assert.Nil(t, err)
-
-	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
-	assert.Nil(t, err)
-
-	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
-	assert.Nil(t, err)
-	assert.Equal(t, uint32(0), atomic.LoadUint32(&putCalledNr))
-
-	assert.Equal(t, firstNonce, sp.LastNotarizedHdrForShard(core.MetachainShardId).GetNonce())
-	assert.Equal(t, 0, len(processedMetaHdrs))
-
-	// wrong header type in pool and defer called
-	datapool.Headers().AddHeader(currHash, shardHdr)
-	sp.SetHdrForCurrentBlock(currHash, shardHdr, true)
-
-	hashes := make([][]byte, 0)
-	hashes = append(hashes, currHash)
-	blockHeader = &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+		ShardInfo:    shardInfo,
+	}
+	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	processedHdrs = append(processedHdrs, currMetaHdr)
 
-	processedMetaHdrs, err = sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
-	assert.Equal(t, process.ErrWrongTypeAssertion, err)
+	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
 
-	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
-	assert.Nil(t, err)
+	assert.NotNil(t, hdrs)
+	assert.Equal(t, 0, len(hdrs))
+}
 
-	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
-	assert.Nil(t, err)
-	assert.Equal(t, uint32(0), atomic.LoadUint32(&putCalledNr))
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachaiMetaHdrsWithOwnHdrButNotStored(t *testing.T) {
+	t.Parallel()
 
-	assert.Equal(t, firstNonce, sp.LastNotarizedHdrForShard(core.MetachainShardId).GetNonce())
+	processedHdrs := make([]data.HeaderHandler, 0)
+	datapool := testscommon.CreatePoolsHolder(1, 0)
+	store := initStore()
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
 
-	// put headers in pool
-	datapool.Headers().AddHeader(currHash, currHdr)
-	datapool.Headers().AddHeader(prevHash, prevHdr)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Store = store
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
 
-	sp.CreateBlockStarted()
-	sp.SetHdrForCurrentBlock(currHash, currHdr, true)
-	sp.SetHdrForCurrentBlock(prevHash, prevHdr, true)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hashes = make([][]byte, 0)
-	hashes = append(hashes, currHash)
-	hashes = append(hashes, prevHash)
-	blockHeader = &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
+	shardInfo := make([]block.ShardData, 0)
+	shardInfo = append(shardInfo, block.ShardData{HeaderHash: []byte("hash"), ShardID: 0})
 
-	processedMetaHdrs, err = sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
-	assert.Nil(t, err)
+	prevMetaHdr := genesisBlocks[core.MetachainShardId]
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr := &block.MetaBlock{
+		Nonce:        1,
+		Epoch:        0,
+		Round:        1,
+		PrevHash:     prevHash,
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+		ShardInfo:    shardInfo,
+	}
+	currHash, _ := core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	processedHdrs = append(processedHdrs, currMetaHdr)
 
-	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
-	assert.Nil(t, err)
+	prevMetaHdr = currMetaHdr
+	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr = &block.MetaBlock{
+		Nonce:        2,
+		Epoch:        0,
+		Round:        2,
+		PrevHash:     prevHash,
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+		ShardInfo:    shardInfo,
+	}
+	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	processedHdrs = append(processedHdrs, currMetaHdr)
 
-	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
-	wg.Wait()
-	assert.Nil(t, err)
-	assert.Equal(t, uint32(4), atomic.LoadUint32(&putCalledNr))
+	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
 
-	assert.Equal(t, currHdr, sp.LastNotarizedHdrForShard(core.MetachainShardId))
+	assert.Equal(t, 0, len(hdrs))
 }
 
-func createShardData(hasher hashing.Hasher, marshalizer marshal.Marshalizer, miniBlocks []block.MiniBlock) []block.ShardData {
-	shardData := make([]block.ShardData, len(miniBlocks))
-	for i := 0; i < len(miniBlocks); i++ {
-		hashed, _ := core.CalculateHash(marshalizer, hasher, &miniBlocks[i])
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachainShouldBoundMissingHeadersRequests(t *testing.T) {
+	t.Parallel()
 
-		shardMBHeader := block.MiniBlockHeader{
-			ReceiverShardID: miniBlocks[i].ReceiverShardID,
-			SenderShardID:   miniBlocks[i].SenderShardID,
-			TxCount:         uint32(len(miniBlocks[i].TxHashes)),
-			Hash:            hashed,
-		}
-		shardMBHeaders := make([]block.MiniBlockHeader, 0)
-		shardMBHeaders = append(shardMBHeaders, shardMBHeader)
+	numMissingHeaders := 100
+	maxRequests := uint32(5)
+
+	numRequests := uint32(0)
+	requestedMetricValue := uint64(0)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = testscommon.CreatePoolsHolder(1, 0)
+	arguments.Store = initStore()
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
+	arguments.MaxShardHeaderRequestsPerMetaBlock = maxRequests
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestShardHeaderCalled: func(shardID uint32, hash []byte) {
+			atomic.AddUint32(&numRequests, 1)
+		},
+	}
+	sp, _ := blproc.NewShardProcessor(arguments)
+	_ = sp.SetAppStatusHandler(&mock.AppStatusHandlerStub{
+		AddUint64Handler: func(key string, value uint64) {
+			if key == core.MetricNumShardHeadersRequestedFromMeta {
+				atomic.AddUint64(&requestedMetricValue, value)
+			}
+		},
+	})
 
-		shardData[0].ShardID = miniBlocks[i].SenderShardID
-		shardData[0].TxCount = 10
-		shardData[0].HeaderHash = []byte("headerHash")
-		shardData[0].ShardMiniBlockHeaders = shardMBHeaders
+	shardInfo := make([]block.ShardData, 0, numMissingHeaders)
+	for i := 0; i < numMissingHeaders; i++ {
+		shardInfo = append(shardInfo, block.ShardData{HeaderHash: []byte(fmt.Sprintf("hash%d", i)), ShardID: 0})
+	}
+	metaHdr := &block.MetaBlock{
+		Nonce:     1,
+		Round:     1,
+		ShardInfo: shardInfo,
 	}
 
-	return shardData
+	hdrs, _, err := sp.GetHighestHdrForOwnShardFromMetachain([]data.HeaderHandler{metaHdr})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(hdrs))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, maxRequests, atomic.LoadUint32(&numRequests))
+	assert.Equal(t, uint64(maxRequests), atomic.LoadUint64(&requestedMetricValue))
 }
 
-func TestShardProcessor_RemoveAndSaveLastNotarizedMetaHdrNotAllMBFinished(t *testing.T) {
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachainShouldSkipEmptyHeaderHashes(t *testing.T) {
 	t.Parallel()
 
-	hasher := mock.HasherMock{}
+	datapool := testscommon.CreatePoolsHolder(1, 0)
+	hasher := &mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
-	datapool := testscommon.NewPoolsHolderMock()
-	forkDetector := &mock.ForkDetectorMock{}
-	highNonce := uint64(500)
-	forkDetector.GetHighestFinalBlockNonceCalled = func() uint64 {
-		return highNonce
-	}
-
-	wg := sync.WaitGroup{}
-	wg.Add(2)
-	putCalledNr := uint32(0)
-	store := &mock.ChainStorerMock{
-		PutCalled: func(unitType dataRetriever.UnitType, key []byte, value []byte) error {
-			atomic.AddUint32(&putCalledNr, 1)
-			wg.Done()
-			return nil
-		},
-	}
-
-	shardNr := uint32(5)
 
+	emptyHashRequested := uint32(0)
 	arguments := CreateMockArgumentsMultiShard()
 	arguments.DataPool = datapool
-	arguments.Store = store
+	arguments.Store = initStore()
 	arguments.Hasher = hasher
 	arguments.Marshalizer = marshalizer
-	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
-	arguments.ForkDetector = forkDetector
-	startHeaders := createGenesisBlocks(arguments.ShardCoordinator)
-	arguments.BlockTracker = mock.NewBlockTrackerMock(arguments.ShardCoordinator, startHeaders)
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
+	arguments.RequestHandler = &mock.RequestHandlerStub{
+		RequestShardHeaderCalled: func(shardID uint32, hash []byte) {
+			if len(hash) == 0 {
+				atomic.StoreUint32(&emptyHashRequested, 1)
+			}
+		},
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	prevRandSeed := []byte("prevrand")
-	currRandSeed := []byte("currrand")
-	notarizedHdrs := sp.NotarizedHdrs()
-	firstNonce := uint64(44)
-
-	lastHdr := &block.MetaBlock{Round: 9,
-		Nonce:    firstNonce,
-		RandSeed: prevRandSeed}
-	notarizedHdrs[core.MetachainShardId] = append(notarizedHdrs[core.MetachainShardId], lastHdr)
-
-	txHash := []byte("txhash")
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock1 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
-	}
-	miniblock2 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   2,
-		TxHashes:        txHashes,
-	}
-	miniblock3 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   3,
-		TxHashes:        txHashes,
-	}
-	miniblock4 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   4,
-		TxHashes:        txHashes,
+	ownHdr := &block.Header{
+		Nonce: 1,
+		Round: 1,
 	}
-	mbHeaders := make([]block.MiniBlockHeader, 0)
-
-	hashed, _ := core.CalculateHash(marshalizer, hasher, &miniblock1)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
-
-	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock2)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
+	ownHash, _ := core.CalculateHash(marshalizer, hasher, ownHdr)
+	datapool.Headers().AddHeader(ownHash, ownHdr)
 
-	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock3)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
+	metaHdr := &block.MetaBlock{
+		Nonce: 1,
+		Round: 1,
+		ShardInfo: []block.ShardData{
+			{HeaderHash: nil, ShardID: 0},
+			{HeaderHash: ownHash, ShardID: 0},
+			{HeaderHash: make([]byte, 0), ShardID: 0},
+		},
+	}
 
-	miniBlocks := make([]block.MiniBlock, 0)
-	miniBlocks = append(miniBlocks, miniblock1, miniblock2)
-	//header shard 0
-	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
-	prevHdr := &block.MetaBlock{
-		Round:        10,
-		Nonce:        45,
-		PrevRandSeed: prevRandSeed,
-		RandSeed:     currRandSeed,
-		PrevHash:     prevHash,
-		RootHash:     []byte("prevRootHash"),
-		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
+	hdrs, _, err := sp.GetHighestHdrForOwnShardFromMetachain([]data.HeaderHandler{metaHdr})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(hdrs))
+	assert.Equal(t, ownHdr, hdrs[0])
 
-	miniBlocks = make([]block.MiniBlock, 0)
-	miniBlocks = append(miniBlocks, miniblock3, miniblock4)
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
-	currHdr := &block.MetaBlock{
-		Round:        11,
-		Nonce:        46,
-		PrevRandSeed: currRandSeed,
-		RandSeed:     []byte("nextrand"),
-		PrevHash:     prevHash,
-		RootHash:     []byte("currRootHash"),
-		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
-	currHash, _ := sp.ComputeHeaderHash(currHdr)
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint32(0), atomic.LoadUint32(&emptyHashRequested))
+}
 
-	// put headers in pool
-	datapool.Headers().AddHeader(currHash, currHdr)
-	datapool.Headers().AddHeader(prevHash, prevHdr)
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachainShouldSkipNonMetaBlocks(t *testing.T) {
+	t.Parallel()
 
-	sp.SetHdrForCurrentBlock(currHash, currHdr, true)
-	sp.SetHdrForCurrentBlock(prevHash, prevHdr, true)
+	datapool := testscommon.CreatePoolsHolder(1, 0)
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
 
-	hashes := make([][]byte, 0)
-	hashes = append(hashes, currHash)
-	hashes = append(hashes, prevHash)
-	blockHeader := &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = datapool
+	arguments.Store = initStore()
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	processedMetaHdrs, err := sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
-	assert.Nil(t, err)
+	ownHdr1 := &block.Header{Nonce: 1, Round: 1}
+	ownHash1, _ := core.CalculateHash(marshalizer, hasher, ownHdr1)
+	datapool.Headers().AddHeader(ownHash1, ownHdr1)
 
-	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
-	assert.Nil(t, err)
+	ownHdr2 := &block.Header{Nonce: 2, Round: 2}
+	ownHash2, _ := core.CalculateHash(marshalizer, hasher, ownHdr2)
+	datapool.Headers().AddHeader(ownHash2, ownHdr2)
 
-	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
-	wg.Wait()
-	assert.Nil(t, err)
-	assert.Equal(t, uint32(2), atomic.LoadUint32(&putCalledNr))
+	metaHdr1 := &block.MetaBlock{
+		Nonce:     1,
+		Round:     1,
+		ShardInfo: []block.ShardData{{HeaderHash: ownHash1, ShardID: 0}},
+	}
+	metaHdr2 := &block.MetaBlock{
+		Nonce:     2,
+		Round:     2,
+		ShardInfo: []block.ShardData{{HeaderHash: ownHash2, ShardID: 0}},
+	}
+	processedHdrs := []data.HeaderHandler{metaHdr1, &block.Header{Nonce: 3}, metaHdr2}
 
-	assert.Equal(t, prevHdr, sp.LastNotarizedHdrForShard(core.MetachainShardId))
+	hdrs, hashes, err := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(hdrs))
+	assert.Equal(t, ownHdr1, hdrs[0])
+	assert.Equal(t, ownHdr2, hdrs[1])
+	assert.Equal(t, [][]byte{ownHash1, ownHash2}, hashes)
 }
 
-func TestShardProcessor_RemoveAndSaveLastNotarizedMetaHdrAllMBFinished(t *testing.T) {
+func TestShardProcessor_GetHighestHdrForOwnShardFromMetachaiMetaHdrsWithOwnHdrStored(t *testing.T) {
 	t.Parallel()
 
-	hasher := mock.HasherMock{}
+	processedHdrs := make([]data.HeaderHandler, 0)
+	datapool := testscommon.CreatePoolsHolder(1, 0)
+	store := initStore()
+	hasher := &mock.HasherMock{}
 	marshalizer := &mock.MarshalizerMock{}
-	datapool := testscommon.NewPoolsHolderMock()
-	forkDetector := &mock.ForkDetectorMock{}
-	highNonce := uint64(500)
-	forkDetector.GetHighestFinalBlockNonceCalled = func() uint64 {
-		return highNonce
-	}
-
-	wg := sync.WaitGroup{}
-	wg.Add(4)
-	putCalledNr := uint32(0)
-	store := &mock.ChainStorerMock{
-		PutCalled: func(unitType dataRetriever.UnitType, key []byte, value []byte) error {
-			atomic.AddUint32(&putCalledNr, 1)
-			wg.Done()
-			return nil
-		},
-	}
-
-	shardNr := uint32(5)
+	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
 
 	arguments := CreateMockArgumentsMultiShard()
 	arguments.DataPool = datapool
 	arguments.Store = store
 	arguments.Hasher = hasher
 	arguments.Marshalizer = marshalizer
-	arguments.ShardCoordinator = mock.NewMultiShardsCoordinatorMock(shardNr)
-	arguments.ForkDetector = forkDetector
-	startHeaders := createGenesisBlocks(arguments.ShardCoordinator)
-	arguments.BlockTracker = mock.NewBlockTrackerMock(arguments.ShardCoordinator, startHeaders)
-	sp, _ := blproc.NewShardProcessor(arguments)
-
-	prevRandSeed := []byte("prevrand")
-	currRandSeed := []byte("currrand")
-	notarizedHdrs := sp.NotarizedHdrs()
-	firstNonce := uint64(44)
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
 
-	lastHdr := &block.MetaBlock{Round: 9,
-		Nonce:    firstNonce,
-		RandSeed: prevRandSeed}
-	notarizedHdrs[core.MetachainShardId] = append(notarizedHdrs[core.MetachainShardId], lastHdr)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	txHash := []byte("txhash")
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock1 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
-	}
-	miniblock2 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   2,
-		TxHashes:        txHashes,
-	}
-	miniblock3 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   3,
-		TxHashes:        txHashes,
-	}
-	miniblock4 := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   4,
-		TxHashes:        txHashes,
+	ownHdr := &block.Header{
+		Nonce: 1,
+		Round: 1,
 	}
+	ownHash, _ := core.CalculateHash(marshalizer, hasher, ownHdr)
+	datapool.Headers().AddHeader(ownHash, ownHdr)
 
-	mbHeaders := make([]block.MiniBlockHeader, 0, 4)
-
-	hashed, _ := core.CalculateHash(marshalizer, hasher, &miniblock1)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
+	shardInfo := make([]block.ShardData, 0)
+	shardInfo = append(shardInfo, block.ShardData{HeaderHash: ownHash, ShardID: 0})
 
-	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock2)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
+	prevMetaHdr := genesisBlocks[core.MetachainShardId]
+	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr := &block.MetaBlock{
+		Nonce:        1,
+		Epoch:        0,
+		Round:        1,
+		PrevHash:     prevHash,
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+		ShardInfo:    shardInfo,
+	}
+	currHash, _ := core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
 
-	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock3)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
+	ownHdr = &block.Header{
+		Nonce: 2,
+		Round: 2,
+	}
+	ownHash, _ = core.CalculateHash(marshalizer, hasher, ownHdr)
+	mrsOwnHdr, _ := marshalizer.Marshal(ownHdr)
+	_ = store.Put(dataRetriever.BlockHeaderUnit, ownHash, mrsOwnHdr)
 
-	hashed, _ = core.CalculateHash(marshalizer, hasher, &miniblock4)
-	mbHeaders = append(mbHeaders, block.MiniBlockHeader{Hash: hashed})
+	shardInfo = make([]block.ShardData, 0)
+	shardInfo = append(shardInfo, block.ShardData{HeaderHash: ownHash, ShardID: 0})
 
-	miniBlocks := make([]block.MiniBlock, 0)
-	miniBlocks = append(miniBlocks, miniblock1, miniblock2)
-	//header shard 0
-	prevHash, _ := sp.ComputeHeaderHash(sp.LastNotarizedHdrForShard(core.MetachainShardId).(*block.MetaBlock))
-	prevHdr := &block.MetaBlock{
-		Round:        10,
-		Nonce:        45,
-		PrevRandSeed: prevRandSeed,
-		RandSeed:     currRandSeed,
+	prevMetaHdr = currMetaHdr
+	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr = &block.MetaBlock{
+		Nonce:        2,
+		Epoch:        0,
+		Round:        2,
 		PrevHash:     prevHash,
-		RootHash:     []byte("prevRootHash"),
-		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+		ShardInfo:    shardInfo,
+	}
+	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	processedHdrs = append(processedHdrs, currMetaHdr)
 
-	miniBlocks = make([]block.MiniBlock, 0)
-	miniBlocks = append(miniBlocks, miniblock3, miniblock4)
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
-	currHdr := &block.MetaBlock{
-		Round:        11,
-		Nonce:        46,
-		PrevRandSeed: currRandSeed,
-		RandSeed:     []byte("nextrand"),
+	prevMetaHdr = currMetaHdr
+	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
+	currMetaHdr = &block.MetaBlock{
+		Nonce:        3,
+		Epoch:        0,
+		Round:        3,
 		PrevHash:     prevHash,
-		RootHash:     []byte("currRootHash"),
-		ShardInfo:    createShardData(hasher, marshalizer, miniBlocks)}
-	currHash, _ := sp.ComputeHeaderHash(currHdr)
-	prevHash, _ = sp.ComputeHeaderHash(prevHdr)
+		PrevRandSeed: prevMetaHdr.GetRandSeed(),
+		RandSeed:     prevMetaHdr.GetRandSeed(),
+	}
+	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
+	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	processedHdrs = append(processedHdrs, currMetaHdr)
 
-	// put headers in pool
-	datapool.Headers().AddHeader(currHash, currHdr)
-	datapool.Headers().AddHeader(prevHash, prevHdr)
-	datapool.Headers().AddHeader([]byte("shouldNotRemove"), &block.MetaBlock{
-		Round:        12,
-		PrevRandSeed: []byte("nextrand"),
-		PrevHash:     currHash,
-		Nonce:        47})
+	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
 
-	sp.SetHdrForCurrentBlock(currHash, currHdr, true)
-	sp.SetHdrForCurrentBlock(prevHash, prevHdr, true)
+	assert.NotNil(t, hdrs)
+	assert.Equal(t, ownHdr.GetNonce(), hdrs[0].GetNonce())
+}
 
-	hashes := make([][]byte, 0)
-	hashes = append(hashes, currHash)
-	hashes = append(hashes, prevHash)
-	blockHeader := &block.Header{MetaBlockHashes: hashes, MiniBlockHeaders: mbHeaders}
+func TestShardProcessor_RestoreMetaBlockIntoPoolVerifyMiniblocks(t *testing.T) {
+	t.Parallel()
 
-	processedMetaHdrs, err := sp.GetOrderedProcessedMetaBlocksFromHeader(blockHeader)
-	assert.Nil(t, err)
-	assert.Equal(t, 2, len(processedMetaHdrs))
+	marshalizer := &mock.MarshalizerMock{}
+	poolMock := testscommon.CreatePoolsHolder(1, 0)
 
-	err = sp.SaveLastNotarizedHeader(core.MetachainShardId, processedMetaHdrs)
-	assert.Nil(t, err)
+	storer := &mock.ChainStorerMock{}
+	shardC := mock.NewMultiShardsCoordinatorMock(3)
 
-	err = sp.UpdateCrossShardInfo(processedMetaHdrs)
-	wg.Wait()
-	assert.Nil(t, err)
-	assert.Equal(t, uint32(4), atomic.LoadUint32(&putCalledNr))
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = poolMock
+	arguments.Store = storer
+	arguments.ShardCoordinator = shardC
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	assert.Equal(t, currHdr, sp.LastNotarizedHdrForShard(core.MetachainShardId))
-}
+	miniblockHashes := make(map[string]uint32)
 
-func createOneHeaderOneBody() (*block.Header, *block.Body) {
-	txHash := []byte("tx_hash1")
-	rootHash := []byte("rootHash")
-	body := &block.Body{}
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
+	testMBHash := []byte("hash")
+	shardMBHdr := block.MiniBlockHeader{
+		Hash:            testMBHash,
+		SenderShardID:   shardC.SelfId() + 1,
+		ReceiverShardID: shardC.SelfId(),
 	}
-	body.MiniBlocks = append(body.MiniBlocks, &miniblock)
+	shardMBHeaders := make([]block.MiniBlockHeader, 0)
+	shardMBHeaders = append(shardMBHeaders, shardMBHdr)
 
-	hasher := &mock.HasherStub{}
-	marshalizer := &mock.MarshalizerMock{}
+	shardHdr := block.ShardData{ShardMiniBlockHeaders: shardMBHeaders, ShardID: shardC.SelfId() + 1}
 
-	mbbytes, _ := marshalizer.Marshal(&miniblock)
-	mbHash := hasher.Compute(string(mbbytes))
-	mbHdr := block.MiniBlockHeader{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash}
-	mbHdrs := make([]block.MiniBlockHeader, 0)
-	mbHdrs = append(mbHdrs, mbHdr)
+	shardInfos := make([]block.ShardData, 0)
+	shardInfos = append(shardInfos, shardHdr)
 
-	hdr := &block.Header{
-		Nonce:            1,
-		PrevHash:         []byte(""),
-		Signature:        []byte("signature"),
-		PubKeysBitmap:    []byte("00110"),
-		ShardID:          0,
-		RootHash:         rootHash,
-		MiniBlockHeaders: mbHdrs,
+	meta := &block.MetaBlock{
+		Nonce:     1,
+		ShardInfo: shardInfos,
 	}
 
-	return hdr, body
-}
+	hasher := &mock.HasherStub{}
 
-func TestShardProcessor_CheckHeaderBodyCorrelationReceiverMissmatch(t *testing.T) {
-	t.Parallel()
+	metaBytes, _ := marshalizer.Marshal(meta)
+	hasher.ComputeCalled = func(s string) []byte {
+		return []byte("cool")
+	}
+	metaHash := hasher.Compute(string(metaBytes))
+	metablockHashes := make([][]byte, 0)
+	metablockHashes = append(metablockHashes, metaHash)
 
-	hdr, body := createOneHeaderOneBody()
-	arguments := CreateMockArgumentsMultiShard()
-	sp, _ := blproc.NewShardProcessor(arguments)
+	metaBlockRestored, err := poolMock.Headers().GetHeaderByHash(metaHash)
 
-	hdr.MiniBlockHeaders[0].ReceiverShardID = body.MiniBlocks[0].ReceiverShardID + 1
-	err := sp.CheckHeaderBodyCorrelation(hdr, body)
-	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
-}
+	assert.Equal(t, nil, metaBlockRestored)
+	assert.Error(t, err)
 
-func TestShardProcessor_CheckHeaderBodyCorrelationSenderMissmatch(t *testing.T) {
-	t.Parallel()
+	storer.GetCalled = func(unitType dataRetriever.UnitType, key []byte) ([]byte, error) {
+		return metaBytes, nil
+	}
+	storer.GetStorerCalled = func(unitType dataRetriever.UnitType) storage.Storer {
+		return &mock.StorerStub{
+			RemoveCalled: func(key []byte) error {
+				return nil
+			},
+			GetCalled: func(key []byte) ([]byte, error) {
+				return metaBytes, nil
+			},
+		}
+	}
 
-	hdr, body := createOneHeaderOneBody()
-	arguments := CreateMockArgumentsMultiShard()
-	sp, _ := blproc.NewShardProcessor(arguments)
+	failedHashes, err := sp.RestoreMetaBlockIntoPool(miniblockHashes, metablockHashes)
 
-	hdr.MiniBlockHeaders[0].SenderShardID = body.MiniBlocks[0].SenderShardID + 1
-	err := sp.CheckHeaderBodyCorrelation(hdr, body)
-	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
+	metaBlockRestored, _ = poolMock.Headers().GetHeaderByHash(metaHash)
+
+	assert.Equal(t, meta, metaBlockRestored)
+	assert.Nil(t, err)
+	assert.Empty(t, failedHashes)
 }
 
-func TestShardProcessor_CheckHeaderBodyCorrelationTxCountMissmatch(t *testing.T) {
+//------- updateStateStorage
+
+func TestShardProcessor_updateStateStorage(t *testing.T) {
 	t.Parallel()
 
-	hdr, body := createOneHeaderOneBody()
+	pruneTrieWasCalled := false
+	cancelPruneWasCalled := false
+	rootHash := []byte("root-hash")
+	poolMock := testscommon.NewPoolsHolderMock()
 
-	arguments := CreateMockArgumentsMultiShard()
-	sp, _ := blproc.NewShardProcessor(arguments)
+	hdrStore := &mock.StorerStub{
+		GetCalled: func(key []byte) ([]byte, error) {
+			hdr := block.Header{Nonce: 7, RootHash: rootHash}
+			return json.Marshal(hdr)
+		},
+	}
 
-	hdr.MiniBlockHeaders[0].TxCount = uint32(len(body.MiniBlocks[0].TxHashes) + 1)
-	err := sp.CheckHeaderBodyCorrelation(hdr, body)
-	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
-}
+	storer := &mock.ChainStorerMock{
+		GetStorerCalled: func(unitType dataRetriever.UnitType) storage.Storer {
+			return hdrStore
+		},
+	}
 
-func TestShardProcessor_CheckHeaderBodyCorrelationHashMissmatch(t *testing.T) {
-	t.Parallel()
+	shardC := mock.NewMultiShardsCoordinatorMock(3)
 
-	hdr, body := createOneHeaderOneBody()
 	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = poolMock
+	arguments.Store = storer
+	arguments.ShardCoordinator = shardC
+	arguments.BlockTracker = &mock.BlockTrackerMock{}
+	arguments.StateCheckpointModulus = 2
+	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
+		IsPruningEnabledCalled: func() bool {
+			return true
+		},
+		PruneTrieCalled: func(rootHashParam []byte, identifier data.TriePruningIdentifier) {
+			pruneTrieWasCalled = true
+			assert.Equal(t, rootHash, rootHashParam)
+		},
+		CancelPruneCalled: func(rootHash []byte, identifier data.TriePruningIdentifier) {
+			cancelPruneWasCalled = true
+		},
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hdr.MiniBlockHeaders[0].Hash = []byte("wrongHash")
-	err := sp.CheckHeaderBodyCorrelation(hdr, body)
-	assert.Equal(t, process.ErrHeaderBodyMismatch, err)
-}
-
-func TestShardProcessor_CheckHeaderBodyCorrelationShouldPass(t *testing.T) {
-	t.Parallel()
-
-	hdr, body := createOneHeaderOneBody()
-	arguments := CreateMockArgumentsMultiShard()
-	sp, _ := blproc.NewShardProcessor(arguments)
+	finalHeaders := make([]data.HeaderHandler, 0)
+	hdr1 := &block.Header{Nonce: 0, Round: 0}
+	hdr2 := &block.Header{Nonce: 1, Round: 1}
+	finalHeaders = append(finalHeaders, hdr1, hdr2)
+	sp.UpdateStateStorage(finalHeaders, &block.Header{})
 
-	err := sp.CheckHeaderBodyCorrelation(hdr, body)
-	assert.Nil(t, err)
+	assert.True(t, pruneTrieWasCalled)
+	assert.True(t, cancelPruneWasCalled)
 }
 
-func TestShardProcessor_CheckHeaderBodyCorrelationNilMiniBlock(t *testing.T) {
+func TestShardProcessor_checkEpochCorrectnessCrossChainNilCurrentBlock(t *testing.T) {
 	t.Parallel()
 
-	hdr, body := createOneHeaderOneBody()
 	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockChain = &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return nil
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Nonce: 0}
+		},
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	body.MiniBlocks[0] = nil
-
-	err := sp.CheckHeaderBodyCorrelation(hdr, body)
-	assert.NotNil(t, err)
-	assert.Equal(t, process.ErrNilMiniBlock, err)
+	err := sp.CheckEpochCorrectnessCrossChain()
+	assert.Equal(t, nil, err)
 }
 
-func TestShardProcessor_RestoreMetaBlockIntoPoolShouldPass(t *testing.T) {
+func TestShardProcessor_checkEpochCorrectnessCrossChainCorrectEpoch(t *testing.T) {
 	t.Parallel()
 
-	marshalizer := &mock.MarshalizerMock{}
-
-	poolFake := testscommon.NewPoolsHolderMock()
-
-	metaBlock := block.MetaBlock{
-		Nonce:     1,
-		ShardInfo: make([]block.ShardData, 0),
+	epochStartTrigger := &mock.EpochStartTriggerStub{
+		EpochFinalityAttestingRoundCalled: func() uint64 {
+			return 10
+		},
+		EpochCalled: func() uint32 {
+			return 1
+		},
 	}
 
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = poolFake
-	arguments.Store = &mock.ChainStorerMock{
-		GetStorerCalled: func(unitType dataRetriever.UnitType) storage.Storer {
-			return &mock.StorerStub{
-				RemoveCalled: func(key []byte) error {
-					return nil
-				},
-				GetCalled: func(key []byte) ([]byte, error) {
-					return marshalizer.Marshal(&metaBlock)
-				},
-			}
+	arguments.EpochStartTrigger = epochStartTrigger
+	blockChain := &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Epoch: 1}
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Nonce: 0}
 		},
 	}
+	arguments.BlockChain = blockChain
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	miniblockHashes := make(map[string]uint32)
+	err := sp.CheckEpochCorrectnessCrossChain()
+	assert.Equal(t, nil, err)
 
-	meta := &block.MetaBlock{
-		Nonce:     1,
-		ShardInfo: make([]block.ShardData, 0),
+	blockChain = &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Epoch: epochStartTrigger.Epoch() - 1, Round: epochStartTrigger.EpochFinalityAttestingRound()}
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Nonce: 0}
+		},
 	}
-	hasher := &mock.HasherStub{}
+	arguments.BlockChain = blockChain
+	sp, _ = blproc.NewShardProcessor(arguments)
 
-	metaBytes, _ := marshalizer.Marshal(meta)
-	hasher.ComputeCalled = func(s string) []byte {
-		return []byte("cool")
-	}
-	metaHash := hasher.Compute(string(metaBytes))
-	metablockHashes := make([][]byte, 0)
-	metablockHashes = append(metablockHashes, metaHash)
+	err = sp.CheckEpochCorrectnessCrossChain()
+	assert.Equal(t, nil, err)
+}
 
-	metaBlockRestored, err := poolFake.Headers().GetHeaderByHash(metaHash)
+func TestShardProcessor_checkEpochCorrectnessCrossChainInCorrectEpochStorageError(t *testing.T) {
+	t.Parallel()
 
-	assert.Equal(t, nil, metaBlockRestored)
-	assert.Error(t, err)
+	epochStartTrigger := &mock.EpochStartTriggerStub{
+		EpochFinalityAttestingRoundCalled: func() uint64 {
+			return 10
+		},
+		EpochCalled: func() uint32 {
+			return 1
+		},
+		MetaEpochCalled: func() uint32 {
+			return 1
+		},
+	}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.EpochStartTrigger = epochStartTrigger
+	header := &block.Header{Epoch: epochStartTrigger.Epoch() - 1, Round: epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 1}
+	blockChain := &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return header
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Nonce: 0}
+		},
+	}
+	arguments.BlockChain = blockChain
 
-	err = sp.RestoreMetaBlockIntoPool(miniblockHashes, metablockHashes)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	metaBlockRestored, _ = poolFake.Headers().GetHeaderByHash(metaHash)
+	store := arguments.Store
+	val, _ := arguments.Marshalizer.Marshal(header)
+	key := arguments.Hasher.Compute(string(val))
+	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(header.Nonce), key)
 
-	assert.Equal(t, &metaBlock, metaBlockRestored)
-	assert.Nil(t, err)
+	err := sp.CheckEpochCorrectnessCrossChain()
+	assert.True(t, errors.Is(err, process.ErrMissingHeader))
 }
 
-func TestShardPreprocessor_getAllMiniBlockDstMeFromMetaShouldPass(t *testing.T) {
+func TestShardProcessor_checkEpochCorrectnessCrossChainInCorrectEpochRollback1Block(t *testing.T) {
 	t.Parallel()
 
-	marshalizer := &mock.MarshalizerMock{}
-
-	txHash := []byte("tx_hash1")
-	txHashes := make([][]byte, 0)
-	txHashes = append(txHashes, txHash)
-	miniblock := block.MiniBlock{
-		ReceiverShardID: 0,
-		SenderShardID:   1,
-		TxHashes:        txHashes,
+	epochStartTrigger := &mock.EpochStartTriggerStub{
+		EpochFinalityAttestingRoundCalled: func() uint64 {
+			return 10
+		},
+		EpochCalled: func() uint32 {
+			return 1
+		},
+		MetaEpochCalled: func() uint32 {
+			return 1
+		},
 	}
-	hasher := &mock.HasherStub{}
+	store := initStore()
+	nonceCalled := uint64(444444)
+	forkDetector := &mock.ForkDetectorMock{SetRollBackNonceCalled: func(nonce uint64) {
+		nonceCalled = nonce
+	}}
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.EpochStartTrigger = epochStartTrigger
+	arguments.Store = store
+	arguments.ForkDetector = forkDetector
 
-	mbbytes, _ := marshalizer.Marshal(&miniblock)
-	mbHash := hasher.Compute(string(mbbytes))
+	prevHash := []byte("prevHash")
+	currHeader := &block.Header{
+		Nonce:    10,
+		Epoch:    epochStartTrigger.Epoch() - 1,
+		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 1,
+		PrevHash: prevHash}
 
-	shardMiniBlock := block.MiniBlockHeader{
-		ReceiverShardID: 0,
-		SenderShardID:   2,
-		TxCount:         uint32(len(txHashes)),
-		Hash:            mbHash,
+	blockChain := &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return currHeader
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Nonce: 0}
+		},
 	}
-	shardMiniblockHdrs := make([]block.MiniBlockHeader, 0)
-	shardMiniblockHdrs = append(shardMiniblockHdrs, shardMiniBlock)
-	shardHeader := block.ShardData{
-		ShardID:               1,
-		ShardMiniBlockHeaders: shardMiniblockHdrs,
+	arguments.BlockChain = blockChain
+
+	sp, _ := blproc.NewShardProcessor(arguments)
+
+	prevHeader := &block.Header{
+		Nonce: 8,
+		Epoch: epochStartTrigger.Epoch() - 1,
+		Round: epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod,
 	}
-	shardHdrs := make([]block.ShardData, 0)
-	shardHdrs = append(shardHdrs, shardHeader)
-	metaBlock := &block.MetaBlock{Nonce: 1, Round: 1, ShardInfo: shardHdrs}
 
-	idp := initDataPool([]byte("tx_hash1"))
+	prevHeaderData, _ := arguments.Marshalizer.Marshal(prevHeader)
+	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(prevHeader.Nonce), prevHash)
+	_ = store.Put(dataRetriever.BlockHeaderUnit, prevHash, prevHeaderData)
+
+	err := sp.CheckEpochCorrectnessCrossChain()
+	assert.Equal(t, process.ErrEpochDoesNotMatch, err)
+	assert.Equal(t, nonceCalled, currHeader.Nonce)
+}
+
+func TestShardProcessor_checkEpochCorrectnessCrossChainInCorrectEpochRollback2Blocks(t *testing.T) {
+	t.Parallel()
 
+	epochStartTrigger := &mock.EpochStartTriggerStub{
+		EpochFinalityAttestingRoundCalled: func() uint64 {
+			return 10
+		},
+		EpochCalled: func() uint32 {
+			return 1
+		},
+		MetaEpochCalled: func() uint32 {
+			return 1
+		},
+	}
+	store := initStore()
+	nonceCalled := uint64(444444)
+	forkDetector := &mock.ForkDetectorMock{SetRollBackNonceCalled: func(nonce uint64) {
+		nonceCalled = nonce
+	}}
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = idp
-	sp, _ := blproc.NewShardProcessor(arguments)
+	arguments.EpochStartTrigger = epochStartTrigger
+	arguments.Store = store
+	arguments.ForkDetector = forkDetector
+	prevHash := []byte("prevHash")
+	header := &block.Header{
+		Nonce:    10,
+		Epoch:    epochStartTrigger.Epoch() - 1,
+		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 2,
+		PrevHash: prevHash}
 
-	metaBytes, _ := marshalizer.Marshal(metaBlock)
-	hasher.ComputeCalled = func(s string) []byte {
-		return []byte("cool")
+	blockChain := &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return header
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{Nonce: 0}
+		},
 	}
-	metaHash := hasher.Compute(string(metaBytes))
-	sp.SetHdrForCurrentBlock(metaHash, metaBlock, true)
+	arguments.BlockChain = blockChain
 
-	metablockHashes := make([][]byte, 0)
-	metablockHashes = append(metablockHashes, metaHash)
-	header := &block.Header{Nonce: 1, Round: 1, MetaBlockHashes: metablockHashes}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	orderedMetaBlocks, err := sp.GetAllMiniBlockDstMeFromMeta(header)
+	prevPrevHash := []byte("prevPrevHash")
+	prevHeader := &block.Header{
+		Nonce:    8,
+		Epoch:    epochStartTrigger.Epoch() - 1,
+		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 1,
+		PrevHash: prevPrevHash,
+	}
+	prevHeaderData, _ := arguments.Marshalizer.Marshal(prevHeader)
+	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(prevHeader.Nonce), prevHash)
+	_ = store.Put(dataRetriever.BlockHeaderUnit, prevHash, prevHeaderData)
+
+	prevPrevHeader := &block.Header{
+		Nonce:    7,
+		Epoch:    epochStartTrigger.Epoch() - 1,
+		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod,
+		PrevHash: prevPrevHash,
+	}
+	prevPrevHeaderData, _ := arguments.Marshalizer.Marshal(prevPrevHeader)
+	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(prevPrevHeader.Nonce), prevPrevHash)
+	_ = store.Put(dataRetriever.BlockHeaderUnit, prevPrevHash, prevPrevHeaderData)
 
-	assert.Equal(t, 1, len(orderedMetaBlocks))
-	assert.Equal(t, orderedMetaBlocks[""], metaHash)
-	assert.Nil(t, err)
+	err := sp.CheckEpochCorrectnessCrossChain()
+	assert.Equal(t, process.ErrEpochDoesNotMatch, err)
+	assert.Equal(t, nonceCalled, prevHeader.Nonce)
 }
 
-func TestShardProcessor_GetHighestHdrForOwnShardFromMetachainNothingToProcess(t *testing.T) {
+func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnNilWhenNoSelfNotarizedHeadersExists(t *testing.T) {
 	t.Parallel()
 
 	arguments := CreateMockArgumentsMultiShard()
 	sp, _ := blproc.NewShardProcessor(arguments)
-	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(nil)
 
-	assert.NotNil(t, hdrs)
-	assert.Equal(t, 0, len(hdrs))
+	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(nil, nil)
+
+	assert.Nil(t, bootstrapHeaderInfos)
 }
 
-func TestShardProcessor_GetHighestHdrForOwnShardFromMetachaiMetaHdrsWithoutOwnHdr(t *testing.T) {
+func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnOneItemWhenFinalNonceIsHigherThanGenesis(t *testing.T) {
 	t.Parallel()
 
-	processedHdrs := make([]data.HeaderHandler, 0)
-	datapool := testscommon.CreatePoolsHolder(1, 0)
-	store := initStore()
-	hasher := &mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
-	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
+	finalNonce := uint64(1)
+	finalHash := []byte("final hash")
 
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Store = store
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.BlockTracker = &mock.BlockTrackerMock{}
-
-	sp, err := blproc.NewShardProcessor(arguments)
-	require.Nil(t, err)
-
-	shardInfo := make([]block.ShardData, 0)
-	shardInfo = append(shardInfo, block.ShardData{HeaderHash: []byte("hash"), ShardID: 1})
-	datapool.Headers().AddHeader([]byte("hash"), &block.Header{ShardID: 0, Nonce: 1})
-
-	prevMetaHdr := genesisBlocks[core.MetachainShardId]
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr := &block.MetaBlock{
-		Nonce:        1,
-		Epoch:        0,
-		Round:        1,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
-		ShardInfo:    shardInfo,
-	}
-	currHash, _ := core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
-	processedHdrs = append(processedHdrs, currMetaHdr)
-
-	prevMetaHdr = currMetaHdr
-	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr = &block.MetaBlock{
-		Nonce:        2,
-		Epoch:        0,
-		Round:        2,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
-		ShardInfo:    shardInfo,
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return finalNonce
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return finalHash
+		},
 	}
-	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
-	processedHdrs = append(processedHdrs, currMetaHdr)
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
+	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(nil, nil)
 
-	assert.NotNil(t, hdrs)
-	assert.Equal(t, 0, len(hdrs))
+	require.Equal(t, 1, len(bootstrapHeaderInfos))
+	assert.Equal(t, finalHash, bootstrapHeaderInfos[0].Hash)
 }
 
-func TestShardProcessor_GetHighestHdrForOwnShardFromMetachaiMetaHdrsWithOwnHdrButNotStored(t *testing.T) {
+func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnOneItemWhenFinalNonceIsNotHigherThanSelfNotarizedNonce(t *testing.T) {
 	t.Parallel()
 
-	processedHdrs := make([]data.HeaderHandler, 0)
-	datapool := testscommon.CreatePoolsHolder(1, 0)
-	store := initStore()
-	hasher := &mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
-	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
-
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Store = store
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.BlockTracker = &mock.BlockTrackerMock{}
-
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return 0
+		},
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	shardInfo := make([]block.ShardData, 0)
-	shardInfo = append(shardInfo, block.ShardData{HeaderHash: []byte("hash"), ShardID: 0})
+	hash := []byte("hash")
+	header := &block.Header{}
 
-	prevMetaHdr := genesisBlocks[core.MetachainShardId]
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr := &block.MetaBlock{
-		Nonce:        1,
-		Epoch:        0,
-		Round:        1,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
-		ShardInfo:    shardInfo,
-	}
-	currHash, _ := core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
-	processedHdrs = append(processedHdrs, currMetaHdr)
+	selfNotarizedHeaders := make([]data.HeaderHandler, 0)
+	selfNotarizedHeadersHashes := make([][]byte, 0)
 
-	prevMetaHdr = currMetaHdr
-	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr = &block.MetaBlock{
-		Nonce:        2,
-		Epoch:        0,
-		Round:        2,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
-		ShardInfo:    shardInfo,
-	}
-	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
-	processedHdrs = append(processedHdrs, currMetaHdr)
+	selfNotarizedHeaders = append(selfNotarizedHeaders, header)
+	selfNotarizedHeadersHashes = append(selfNotarizedHeadersHashes, hash)
 
-	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
+	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(selfNotarizedHeaders, selfNotarizedHeadersHashes)
 
-	assert.Equal(t, 0, len(hdrs))
+	require.Equal(t, 1, len(bootstrapHeaderInfos))
+	assert.Equal(t, hash, bootstrapHeaderInfos[0].Hash)
 }
 
-func TestShardProcessor_GetHighestHdrForOwnShardFromMetachaiMetaHdrsWithOwnHdrStored(t *testing.T) {
+func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnTwoItemsWhenFinalNonceIsHigherThanSelfNotarizedNonce(t *testing.T) {
 	t.Parallel()
 
-	processedHdrs := make([]data.HeaderHandler, 0)
-	datapool := testscommon.CreatePoolsHolder(1, 0)
-	store := initStore()
-	hasher := &mock.HasherMock{}
-	marshalizer := &mock.MarshalizerMock{}
-	genesisBlocks := createGenesisBlocks(mock.NewMultiShardsCoordinatorMock(3))
+	finalNonce := uint64(2)
+	finalHash := []byte("final hash")
 
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = datapool
-	arguments.Store = store
-	arguments.Hasher = hasher
-	arguments.Marshalizer = marshalizer
-	arguments.BlockTracker = &mock.BlockTrackerMock{}
-
+	arguments.ForkDetector = &mock.ForkDetectorMock{
+		GetHighestFinalBlockNonceCalled: func() uint64 {
+			return finalNonce
+		},
+		GetHighestFinalBlockHashCalled: func() []byte {
+			return finalHash
+		},
+	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	ownHdr := &block.Header{
-		Nonce: 1,
-		Round: 1,
-	}
-	ownHash, _ := core.CalculateHash(marshalizer, hasher, ownHdr)
-	datapool.Headers().AddHeader(ownHash, ownHdr)
+	hash := []byte("hash")
+	header := &block.Header{Nonce: 1}
 
-	shardInfo := make([]block.ShardData, 0)
-	shardInfo = append(shardInfo, block.ShardData{HeaderHash: ownHash, ShardID: 0})
+	selfNotarizedHeaders := make([]data.HeaderHandler, 0)
+	selfNotarizedHeadersHashes := make([][]byte, 0)
 
-	prevMetaHdr := genesisBlocks[core.MetachainShardId]
-	prevHash, _ := core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr := &block.MetaBlock{
-		Nonce:        1,
-		Epoch:        0,
-		Round:        1,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
-		ShardInfo:    shardInfo,
-	}
-	currHash, _ := core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
+	selfNotarizedHeaders = append(selfNotarizedHeaders, header)
+	selfNotarizedHeadersHashes = append(selfNotarizedHeadersHashes, hash)
 
-	ownHdr = &block.Header{
-		Nonce: 2,
-		Round: 2,
-	}
-	ownHash, _ = core.CalculateHash(marshalizer, hasher, ownHdr)
-	mrsOwnHdr, _ := marshalizer.Marshal(ownHdr)
-	_ = store.Put(dataRetriever.BlockHeaderUnit, ownHash, mrsOwnHdr)
+	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(selfNotarizedHeaders, selfNotarizedHeadersHashes)
 
-	shardInfo = make([]block.ShardData, 0)
-	shardInfo = append(shardInfo, block.ShardData{HeaderHash: ownHash, ShardID: 0})
+	require.Equal(t, 2, len(bootstrapHeaderInfos))
+	assert.Equal(t, hash, bootstrapHeaderInfos[0].Hash)
+	assert.Equal(t, finalHash, bootstrapHeaderInfos[1].Hash)
+}
 
-	prevMetaHdr = currMetaHdr
-	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr = &block.MetaBlock{
-		Nonce:        2,
-		Epoch:        0,
-		Round:        2,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
-		ShardInfo:    shardInfo,
-	}
-	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
-	processedHdrs = append(processedHdrs, currMetaHdr)
+func TestShardProcessor_RequestMetaHeadersIfNeededShouldAddHeaderIntoTrackerPool(t *testing.T) {
+	t.Parallel()
 
-	prevMetaHdr = currMetaHdr
-	prevHash, _ = core.CalculateHash(marshalizer, hasher, prevMetaHdr)
-	currMetaHdr = &block.MetaBlock{
-		Nonce:        3,
-		Epoch:        0,
-		Round:        3,
-		PrevHash:     prevHash,
-		PrevRandSeed: prevMetaHdr.GetRandSeed(),
-		RandSeed:     prevMetaHdr.GetRandSeed(),
+	var addedNonces []uint64
+
+	rounderMock := &mock.RounderMock{}
+
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.Rounder = rounderMock
+
+	poolsHolderStub := initDataPool([]byte(""))
+	poolsHolderStub.HeadersCalled = func() dataRetriever.HeadersPool {
+		return &mock.HeadersCacherStub{
+			GetHeaderByNonceAndShardIdCalled: func(hdrNonce uint64, shardId uint32) ([]data.HeaderHandler, [][]byte, error) {
+				addedNonces = append(addedNonces, hdrNonce)
+				return []data.HeaderHandler{&block.MetaBlock{Nonce: 1}}, [][]byte{[]byte("hash")}, nil
+			},
+		}
 	}
-	currHash, _ = core.CalculateHash(marshalizer, hasher, currMetaHdr)
-	datapool.Headers().AddHeader(currHash, currMetaHdr)
-	processedHdrs = append(processedHdrs, currMetaHdr)
+	arguments.DataPool = poolsHolderStub
+
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hdrs, _, _ := sp.GetHighestHdrForOwnShardFromMetachain(processedHdrs)
+	rounderMock.RoundIndex = 20
+	metaBlock := &block.MetaBlock{
+		Round: 9,
+		Nonce: 5,
+	}
+	sp.RequestMetaHeadersIfNeeded(0, metaBlock)
 
-	assert.NotNil(t, hdrs)
-	assert.Equal(t, ownHdr.GetNonce(), hdrs[0].GetNonce())
+	expectedAddedNonces := []uint64{6, 7}
+	assert.Equal(t, expectedAddedNonces, addedNonces)
 }
 
-func TestShardProcessor_RestoreMetaBlockIntoPoolVerifyMiniblocks(t *testing.T) {
+func TestShardProcessor_CloseShouldWaitForBackgroundRoutines(t *testing.T) {
 	t.Parallel()
 
-	marshalizer := &mock.MarshalizerMock{}
-	poolMock := testscommon.CreatePoolsHolder(1, 0)
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
 
-	storer := &mock.ChainStorerMock{}
-	shardC := mock.NewMultiShardsCoordinatorMock(3)
+	routineFinished := int32(0)
+	chRelease := make(chan struct{})
+	sp.StartBackgroundRoutine(func() {
+		<-chRelease
+		atomic.StoreInt32(&routineFinished, 1)
+	})
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = poolMock
-	arguments.Store = storer
-	arguments.ShardCoordinator = shardC
-	arguments.BlockTracker = &mock.BlockTrackerMock{}
-	sp, _ := blproc.NewShardProcessor(arguments)
+	chCloseErr := make(chan error, 1)
+	go func() {
+		chCloseErr <- sp.Close()
+	}()
 
-	miniblockHashes := make(map[string]uint32)
+	select {
+	case <-chCloseErr:
+		assert.Fail(t, "Close should have waited for the background routine to finish")
+	case <-time.After(time.Millisecond * 100):
+	}
 
-	testMBHash := []byte("hash")
-	shardMBHdr := block.MiniBlockHeader{
-		Hash:            testMBHash,
-		SenderShardID:   shardC.SelfId() + 1,
-		ReceiverShardID: shardC.SelfId(),
+	close(chRelease)
+
+	select {
+	case err := <-chCloseErr:
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&routineFinished))
+	case <-time.After(time.Second):
+		assert.Fail(t, "Close should have returned after the background routine finished")
 	}
-	shardMBHeaders := make([]block.MiniBlockHeader, 0)
-	shardMBHeaders = append(shardMBHeaders, shardMBHdr)
+}
 
-	shardHdr := block.ShardData{ShardMiniBlockHeaders: shardMBHeaders, ShardID: shardC.SelfId() + 1}
+func TestShardProcessor_ReceivedMetaBlockShouldFireOnceWhenPreviousProcessorOnSamePoolWasClosed(t *testing.T) {
+	t.Parallel()
 
-	shardInfos := make([]block.ShardData, 0)
-	shardInfos = append(shardInfos, shardHdr)
+	numRequestMiniBlocksCalls := int32(0)
+	datapool := testscommon.NewPoolsHolderMock()
+	createArguments := func() blproc.ArgShardProcessor {
+		arguments := CreateMockArgumentsMultiShard()
+		arguments.DataPool = datapool
+		arguments.Rounder = &mock.RounderMock{RoundIndex: 10}
+		arguments.TxCoordinator = &mock.TransactionCoordinatorMock{
+			RequestMiniBlocksCalled: func(header data.HeaderHandler) {
+				atomic.AddInt32(&numRequestMiniBlocksCalls, 1)
+			},
+		}
 
-	meta := &block.MetaBlock{
-		Nonce:     1,
-		ShardInfo: shardInfos,
+		return arguments
 	}
 
-	hasher := &mock.HasherStub{}
+	firstProcessor, _ := blproc.NewShardProcessor(createArguments())
+	_, _ = blproc.NewShardProcessor(createArguments())
 
-	metaBytes, _ := marshalizer.Marshal(meta)
-	hasher.ComputeCalled = func(s string) []byte {
-		return []byte("cool")
-	}
-	metaHash := hasher.Compute(string(metaBytes))
-	metablockHashes := make([][]byte, 0)
-	metablockHashes = append(metablockHashes, metaHash)
+	err := firstProcessor.Close()
+	assert.Nil(t, err)
 
-	metaBlockRestored, err := poolMock.Headers().GetHeaderByHash(metaHash)
+	datapool.Headers().AddHeader([]byte("meta hash"), &block.MetaBlock{Nonce: 1, Round: 1})
+	time.Sleep(time.Millisecond * 500)
 
-	assert.Equal(t, nil, metaBlockRestored)
-	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&numRequestMiniBlocksCalls))
+}
 
-	storer.GetCalled = func(unitType dataRetriever.UnitType, key []byte) ([]byte, error) {
-		return metaBytes, nil
-	}
-	storer.GetStorerCalled = func(unitType dataRetriever.UnitType) storage.Storer {
-		return &mock.StorerStub{
-			RemoveCalled: func(key []byte) error {
-				return nil
-			},
-			GetCalled: func(key []byte) ([]byte, error) {
-				return metaBytes, nil
-			},
-		}
+func TestShardProcessor_CallbacksAfterCloseShouldBeNoOp(t *testing.T) {
+	t.Parallel()
+
+	numCalls := int32(0)
+	shardCoordinator := mock.NewMultiShardsCoordinatorMock(3)
+	blockTrackerMock := mock.NewBlockTrackerMock(shardCoordinator, createGenesisBlocks(shardCoordinator))
+	blockTrackerMock.GetLastCrossNotarizedHeaderCalled = func(shardID uint32) (data.HeaderHandler, []byte, error) {
+		atomic.AddInt32(&numCalls, 1)
+		return &block.MetaBlock{}, []byte("hash"), nil
 	}
 
-	err = sp.RestoreMetaBlockIntoPool(miniblockHashes, metablockHashes)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.BlockTracker = blockTrackerMock
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	metaBlockRestored, _ = poolMock.Headers().GetHeaderByHash(metaHash)
+	err := sp.Close()
+	assert.Nil(t, err)
 
-	assert.Equal(t, meta, metaBlockRestored)
+	sp.ReceivedMetaBlock(&block.MetaBlock{Nonce: 1, Round: 1}, []byte("meta hash"))
+	sp.StartBackgroundRoutine(func() {
+		atomic.AddInt32(&numCalls, 1)
+	})
+
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&numCalls))
+
+	err = sp.Close()
 	assert.Nil(t, err)
 }
 
-//------- updateStateStorage
-
-func TestShardProcessor_updateStateStorage(t *testing.T) {
+func TestShardProcessor_WaitForMetaHdrHashesShouldReturnEarlyWhenClosing(t *testing.T) {
 	t.Parallel()
 
-	pruneTrieWasCalled := false
-	cancelPruneWasCalled := false
-	rootHash := []byte("root-hash")
-	poolMock := testscommon.NewPoolsHolderMock()
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
 
-	hdrStore := &mock.StorerStub{
-		GetCalled: func(key []byte) ([]byte, error) {
-			hdr := block.Header{Nonce: 7, RootHash: rootHash}
-			return json.Marshal(hdr)
-		},
-	}
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- sp.WaitForMetaHdrHashes(time.Minute)
+	}()
 
-	storer := &mock.ChainStorerMock{
-		GetStorerCalled: func(unitType dataRetriever.UnitType) storage.Storer {
-			return hdrStore
-		},
+	time.Sleep(time.Millisecond * 50)
+	_ = sp.Close()
+
+	select {
+	case err := <-chErr:
+		assert.Equal(t, process.ErrProcessClosing, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "waiting for the meta headers should have returned on close")
 	}
+}
 
-	shardC := mock.NewMultiShardsCoordinatorMock(3)
+func TestShardProcessor_WaitForMetaHdrHashesShouldErrOnTimeout(t *testing.T) {
+	t.Parallel()
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.DataPool = poolMock
-	arguments.Store = storer
-	arguments.ShardCoordinator = shardC
-	arguments.BlockTracker = &mock.BlockTrackerMock{}
-	arguments.StateCheckpointModulus = 2
-	arguments.AccountsDB[state.UserAccountsState] = &mock.AccountsStub{
-		IsPruningEnabledCalled: func() bool {
-			return true
-		},
-		PruneTrieCalled: func(rootHashParam []byte, identifier data.TriePruningIdentifier) {
-			pruneTrieWasCalled = true
-			assert.Equal(t, rootHash, rootHashParam)
-		},
-		CancelPruneCalled: func(rootHash []byte, identifier data.TriePruningIdentifier) {
-			cancelPruneWasCalled = true
-		},
-	}
-	sp, _ := blproc.NewShardProcessor(arguments)
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
 
-	finalHeaders := make([]data.HeaderHandler, 0)
-	hdr1 := &block.Header{Nonce: 0, Round: 0}
-	hdr2 := &block.Header{Nonce: 1, Round: 1}
-	finalHeaders = append(finalHeaders, hdr1, hdr2)
-	sp.UpdateStateStorage(finalHeaders, &block.Header{})
+	err := sp.WaitForMetaHdrHashes(time.Millisecond)
+	assert.Equal(t, process.ErrTimeIsOut, err)
+	_ = sp.Close()
+}
 
-	assert.True(t, pruneTrieWasCalled)
-	assert.True(t, cancelPruneWasCalled)
+func TestShardProcessor_MetaBlockPoolAgeNotSeenMetaBlockShouldErr(t *testing.T) {
+	t.Parallel()
+
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+
+	age, err := sp.MetaBlockPoolAge([]byte("meta hash"), 10)
+	assert.Equal(t, uint64(0), age)
+	assert.True(t, errors.Is(err, process.ErrMissingHeader))
 }
 
-func TestShardProcessor_checkEpochCorrectnessCrossChainNilCurrentBlock(t *testing.T) {
+func TestShardProcessor_MetaBlockPoolAgeShouldReturnTheRoundsSinceFirstSeen(t *testing.T) {
 	t.Parallel()
 
+	firstSeenRound := uint64(7)
+	rounder := &mock.RounderMock{RoundIndex: int64(firstSeenRound)}
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.BlockChain = &mock.BlockChainMock{
-		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
-			return nil
-		},
-		GetGenesisHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Nonce: 0}
-		},
-	}
+	arguments.Rounder = rounder
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.CheckEpochCorrectnessCrossChain()
-	assert.Equal(t, nil, err)
+	metaBlockHash := []byte("meta hash")
+	sp.ReceivedMetaBlock(&block.MetaBlock{Nonce: 1, Round: 1}, metaBlockHash)
+
+	// receiving the same meta block again, in a later round, should not change the round in which it was first seen
+	rounder.RoundIndex = int64(firstSeenRound + 2)
+	sp.ReceivedMetaBlock(&block.MetaBlock{Nonce: 1, Round: 1}, metaBlockHash)
+
+	age, err := sp.MetaBlockPoolAge(metaBlockHash, firstSeenRound+5)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5), age)
 }
 
-func TestShardProcessor_checkEpochCorrectnessCrossChainCorrectEpoch(t *testing.T) {
+func TestShardProcessor_SetMetaBlockProcessingPausedShouldProcessTheBufferedMetaBlocksOnResume(t *testing.T) {
 	t.Parallel()
 
-	epochStartTrigger := &mock.EpochStartTriggerStub{
-		EpochFinalityAttestingRoundCalled: func() uint64 {
-			return 10
-		},
-		EpochCalled: func() uint32 {
-			return 1
-		},
-	}
+	sp, _ := blproc.NewShardProcessor(CreateMockArgumentsMultiShard())
+	sp.SetMetaBlockProcessingPaused(true)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.EpochStartTrigger = epochStartTrigger
-	blockChain := &mock.BlockChainMock{
-		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Epoch: 1}
-		},
-		GetGenesisHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Nonce: 0}
-		},
+	numMetaBlocks := blproc.MaxPausedMetaBlocks + 1
+	metaBlocksHashes := make([][]byte, 0, numMetaBlocks)
+	for i := 0; i < numMetaBlocks; i++ {
+		metaBlockHash := []byte(fmt.Sprintf("meta hash %d", i))
+		metaBlocksHashes = append(metaBlocksHashes, metaBlockHash)
+		sp.ReceivedMetaBlock(&block.MetaBlock{Nonce: uint64(i + 1), Round: uint64(i + 1)}, metaBlockHash)
 	}
-	arguments.BlockChain = blockChain
-	sp, _ := blproc.NewShardProcessor(arguments)
 
-	err := sp.CheckEpochCorrectnessCrossChain()
-	assert.Equal(t, nil, err)
+	_, err := sp.MetaBlockPoolAge(metaBlocksHashes[0], 0)
+	assert.True(t, errors.Is(err, process.ErrMissingHeader))
+	assert.Equal(t, uint64(1), sp.NumDroppedPausedMetaBlocks())
 
-	blockChain = &mock.BlockChainMock{
-		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Epoch: epochStartTrigger.Epoch() - 1, Round: epochStartTrigger.EpochFinalityAttestingRound()}
-		},
-		GetGenesisHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Nonce: 0}
-		},
+	sp.SetMetaBlockProcessingPaused(false)
+
+	for i := 0; i < blproc.MaxPausedMetaBlocks; i++ {
+		_, err = sp.MetaBlockPoolAge(metaBlocksHashes[i], 0)
+		assert.Nil(t, err)
 	}
-	arguments.BlockChain = blockChain
-	sp, _ = blproc.NewShardProcessor(arguments)
+	_, err = sp.MetaBlockPoolAge(metaBlocksHashes[numMetaBlocks-1], 0)
+	assert.True(t, errors.Is(err, process.ErrMissingHeader))
 
-	err = sp.CheckEpochCorrectnessCrossChain()
-	assert.Equal(t, nil, err)
+	// once resumed, the meta blocks are processed as they are received
+	newMetaBlockHash := []byte("new meta hash")
+	sp.ReceivedMetaBlock(&block.MetaBlock{Nonce: uint64(numMetaBlocks + 1)}, newMetaBlockHash)
+	_, err = sp.MetaBlockPoolAge(newMetaBlockHash, 0)
+	assert.Nil(t, err)
 }
 
-func TestShardProcessor_checkEpochCorrectnessCrossChainInCorrectEpochStorageError(t *testing.T) {
+func TestShardProcessor_ReaderMethodsShouldReturnConsistentValues(t *testing.T) {
 	t.Parallel()
 
-	epochStartTrigger := &mock.EpochStartTriggerStub{
-		EpochFinalityAttestingRoundCalled: func() uint64 {
-			return 10
-		},
-		EpochCalled: func() uint32 {
-			return 1
-		},
-		MetaEpochCalled: func() uint32 {
-			return 1
-		},
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.Hasher = &mock.HasherMock{}
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	blkc.SetGenesisHeaderHash(genesisHash)
+	arguments.BlockChain = blkc
+	lastCrossNotarizedMetaBlock := &block.MetaBlock{Nonce: 3}
+	blockTracker := arguments.BlockTracker.(*mock.BlockTrackerMock)
+	blockTracker.GetLastCrossNotarizedHeaderCalled = func(shardID uint32) (data.HeaderHandler, []byte, error) {
+		return lastCrossNotarizedMetaBlock, []byte("meta hash"), nil
 	}
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.EpochStartTrigger = epochStartTrigger
-	header := &block.Header{Epoch: epochStartTrigger.Epoch() - 1, Round: epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 1}
-	blockChain := &mock.BlockChainMock{
-		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
-			return header
-		},
-		GetGenesisHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Nonce: 0}
-		},
-	}
-	arguments.BlockChain = blockChain
+	var reader process.ShardProcessorReader = sp
+	require.False(t, check.IfNil(reader))
 
-	sp, _ := blproc.NewShardProcessor(arguments)
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
 
-	store := arguments.Store
-	val, _ := arguments.Marshalizer.Marshal(header)
-	key := arguments.Hasher.Compute(string(val))
-	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(header.Nonce), key)
+	committedHeader, committedHash := reader.LastCommittedHeader()
+	assert.Equal(t, hdr, committedHeader)
+	expectedHash, err := reader.ComputeHeaderHash(hdr)
+	assert.Nil(t, err)
+	assert.Equal(t, expectedHash, committedHash)
 
-	err := sp.CheckEpochCorrectnessCrossChain()
-	assert.True(t, errors.Is(err, process.ErrMissingHeader))
+	metaBlock, metaBlockHash, err := reader.LastCrossNotarizedMetaBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, lastCrossNotarizedMetaBlock, metaBlock)
+	assert.Equal(t, []byte("meta hash"), metaBlockHash)
+	assert.Equal(t, uint32(0), reader.MissingFinalHeadersCount())
 }
 
-func TestShardProcessor_checkEpochCorrectnessCrossChainInCorrectEpochRollback1Block(t *testing.T) {
+func TestShardProcessor_GetBlockBackgroundErrorsShouldReturnTheErrorsOfTheBlockBackgroundRoutines(t *testing.T) {
 	t.Parallel()
 
-	epochStartTrigger := &mock.EpochStartTriggerStub{
-		EpochFinalityAttestingRoundCalled: func() uint64 {
-			return 10
-		},
-		EpochCalled: func() uint32 {
-			return 1
-		},
-		MetaEpochCalled: func() uint32 {
-			return 1
+	txHash := []byte("tx_hash1")
+	randSeed := []byte("rand seed")
+	blkc := blockchain.NewBlockChain()
+	_ = blkc.SetCurrentBlockHeader(
+		&block.Header{
+			Nonce:    1,
+			RandSeed: randSeed,
 		},
-	}
-	store := initStore()
-	nonceCalled := uint64(444444)
-	forkDetector := &mock.ForkDetectorMock{SetRollBackNonceCalled: func(nonce uint64) {
-		nonceCalled = nonce
-	}}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.EpochStartTrigger = epochStartTrigger
-	arguments.Store = store
-	arguments.ForkDetector = forkDetector
+	)
+	_ = blkc.SetGenesisHeader(&block.Header{Nonce: 0})
+	miniblock := &block.MiniBlock{
+		ReceiverShardID: 1,
+		SenderShardID:   0,
+		TxHashes:        [][]byte{txHash},
+	}
+	body := &block.Body{MiniBlocks: []*block.MiniBlock{miniblock}}
 
-	prevHash := []byte("prevHash")
-	currHeader := &block.Header{
-		Nonce:    10,
-		Epoch:    epochStartTrigger.Epoch() - 1,
-		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 1,
-		PrevHash: prevHash}
+	hasher := &mock.HasherStub{}
+	marshalizerMock := &mock.MarshalizerMock{}
+	lastHdr := blkc.GetCurrentBlockHeader()
+	prevHash, _ := core.CalculateHash(marshalizerMock, hasher, lastHdr)
+	hdr := initBlockHeader(prevHash, randSeed, []byte("rootHash"), make([]block.MiniBlockHeader, 0))
 
-	blockChain := &mock.BlockChainMock{
-		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
-			return currHeader
-		},
-		GetGenesisHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Nonce: 0}
+	errMarshalMiniBlock := errors.New("miniblock marshal error")
+	marshalizer := &mock.MarshalizerStub{
+		MarshalCalled: func(obj interface{}) ([]byte, error) {
+			if _, ok := obj.(*block.MiniBlock); ok {
+				return nil, errMarshalMiniBlock
+			}
+
+			return marshalizerMock.Marshal(obj)
 		},
+		UnmarshalCalled: marshalizerMock.Unmarshal,
 	}
-	arguments.BlockChain = blockChain
 
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.DataPool = initDataPool(txHash)
+	arguments.BlockChain = blkc
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	prevHeader := &block.Header{
-		Nonce: 8,
-		Epoch: epochStartTrigger.Epoch() - 1,
-		Round: epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod,
-	}
+	err := sp.ProcessBlock(&hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrHeaderBodyMismatch))
 
-	prevHeaderData, _ := arguments.Marshalizer.Marshal(prevHeader)
-	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(prevHeader.Nonce), prevHash)
-	_ = store.Put(dataRetriever.BlockHeaderUnit, prevHash, prevHeaderData)
+	// closing the processor waits for the in-flight background go routines
+	err = sp.Close()
+	require.Nil(t, err)
 
-	err := sp.CheckEpochCorrectnessCrossChain()
-	assert.Equal(t, process.ErrEpochDoesNotMatch, err)
-	assert.Equal(t, nonceCalled, currHeader.Nonce)
+	hdrHash, _ := sp.ComputeHeaderHash(&hdr)
+	backgroundErrors := sp.GetBlockBackgroundErrors(hdrHash)
+	require.Equal(t, 1, len(backgroundErrors))
+	assert.True(t, errors.Is(backgroundErrors[0], errMarshalMiniBlock))
+	assert.Equal(t, 0, len(sp.GetBlockBackgroundErrors([]byte("other hash"))))
 }
 
-func TestShardProcessor_checkEpochCorrectnessCrossChainInCorrectEpochRollback2Blocks(t *testing.T) {
-	t.Parallel()
+func createArgumentsAndBlockAttestingMetaBlock(
+	metaHash []byte,
+	headersPool dataRetriever.HeadersPool,
+) (blproc.ArgShardProcessor, *block.Header, *block.Body) {
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
 
-	epochStartTrigger := &mock.EpochStartTriggerStub{
-		EpochFinalityAttestingRoundCalled: func() uint64 {
-			return 10
-		},
-		EpochCalled: func() uint32 {
-			return 1
-		},
-		MetaEpochCalled: func() uint32 {
-			return 1
-		},
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	hdr.MiniBlockHeaders[0].Hash, _ = core.CalculateHash(marshalizer, hasher, body.MiniBlocks[0])
+	hdr.MetaBlockHashes = [][]byte{metaHash}
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.Hasher = hasher
+	arguments.Marshalizer = marshalizer
+	dataPool := initDataPool([]byte("tx_hash1"))
+	dataPool.HeadersCalled = func() dataRetriever.HeadersPool {
+		return headersPool
 	}
-	store := initStore()
-	nonceCalled := uint64(444444)
-	forkDetector := &mock.ForkDetectorMock{SetRollBackNonceCalled: func(nonce uint64) {
-		nonceCalled = nonce
-	}}
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.EpochStartTrigger = epochStartTrigger
-	arguments.Store = store
-	arguments.ForkDetector = forkDetector
-	prevHash := []byte("prevHash")
-	header := &block.Header{
-		Nonce:    10,
-		Epoch:    epochStartTrigger.Epoch() - 1,
-		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 2,
-		PrevHash: prevHash}
+	arguments.DataPool = dataPool
 
-	blockChain := &mock.BlockChainMock{
-		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
-			return header
-		},
-		GetGenesisHeaderCalled: func() data.HeaderHandler {
-			return &block.Header{Nonce: 0}
-		},
+	return arguments, hdr, body
+}
+
+func TestShardProcessor_CommitBlockShouldErrWhenFinalityChangedInPoolAfterProcessing(t *testing.T) {
+	t.Parallel()
+
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+	attestingMetaBlock := &block.MetaBlock{
+		Nonce:     metaBlock.GetNonce() + 1,
+		ShardInfo: make([]block.ShardData, 0),
+		Round:     metaBlock.GetRound() + 1,
+		PrevHash:  metaHash,
 	}
-	arguments.BlockChain = blockChain
+	attestingMetaHash, _ := core.CalculateHash(marshalizer, hasher, attestingMetaBlock)
 
+	headersPool := testscommon.NewPoolsHolderMock().Headers()
+	headersPool.AddHeader(metaHash, metaBlock)
+	headersPool.AddHeader(attestingMetaHash, attestingMetaBlock)
+
+	arguments, hdr, body := createArgumentsAndBlockAttestingMetaBlock(metaHash, headersPool)
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	prevPrevHash := []byte("prevPrevHash")
-	prevHeader := &block.Header{
-		Nonce:    8,
-		Epoch:    epochStartTrigger.Epoch() - 1,
-		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod + 1,
-		PrevHash: prevPrevHash,
-	}
-	prevHeaderData, _ := arguments.Marshalizer.Marshal(prevHeader)
-	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(prevHeader.Nonce), prevHash)
-	_ = store.Put(dataRetriever.BlockHeaderUnit, prevHash, prevHeaderData)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.Nil(t, err)
 
-	prevPrevHeader := &block.Header{
-		Nonce:    7,
-		Epoch:    epochStartTrigger.Epoch() - 1,
-		Round:    epochStartTrigger.EpochFinalityAttestingRound() + process.EpochChangeGracePeriod,
-		PrevHash: prevPrevHash,
-	}
-	prevPrevHeaderData, _ := arguments.Marshalizer.Marshal(prevPrevHeader)
-	_ = store.Put(dataRetriever.ShardHdrNonceHashDataUnit, arguments.Uint64Converter.ToByteSlice(prevPrevHeader.Nonce), prevPrevHash)
-	_ = store.Put(dataRetriever.BlockHeaderUnit, prevPrevHash, prevPrevHeaderData)
+	// the finality attesting meta block is evicted from pool between processing and commit
+	headersPool.RemoveHeaderByHash(attestingMetaHash)
 
-	err := sp.CheckEpochCorrectnessCrossChain()
-	assert.Equal(t, process.ErrEpochDoesNotMatch, err)
-	assert.Equal(t, nonceCalled, prevHeader.Nonce)
+	err = sp.CommitBlock(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrFinalityChangedBeforeCommit))
 }
 
-func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnNilWhenNoSelfNotarizedHeadersExists(t *testing.T) {
+func TestShardProcessor_CommitBlockShouldErrWhenTopAttestedMetaBlockIsMissingFromPool(t *testing.T) {
 	t.Parallel()
 
-	arguments := CreateMockArgumentsMultiShard()
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+	attestingMetaBlock := &block.MetaBlock{
+		Nonce:     metaBlock.GetNonce() + 1,
+		ShardInfo: make([]block.ShardData, 0),
+		Round:     metaBlock.GetRound() + 1,
+		PrevHash:  metaHash,
+	}
+	attestingMetaHash, _ := core.CalculateHash(marshalizer, hasher, attestingMetaBlock)
+
+	headersPool := testscommon.NewPoolsHolderMock().Headers()
+	headersPool.AddHeader(metaHash, metaBlock)
+	headersPool.AddHeader(attestingMetaHash, attestingMetaBlock)
+
+	arguments, hdr, body := createArgumentsAndBlockAttestingMetaBlock(metaHash, headersPool)
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(nil, nil)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.Nil(t, err)
 
-	assert.Nil(t, bootstrapHeaderInfos)
+	headersPool.RemoveHeaderByHash(metaHash)
+
+	err = sp.CommitBlock(hdr, body)
+	assert.True(t, errors.Is(err, process.ErrFinalityChangedBeforeCommit))
 }
 
-func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnOneItemWhenFinalNonceIsHigherThanGenesis(t *testing.T) {
+func TestShardProcessor_CommitBlockShouldNotErrWhenFinalityIsUnchangedInPool(t *testing.T) {
 	t.Parallel()
 
-	finalNonce := uint64(1)
-	finalHash := []byte("final hash")
+	hasher := &mock.HasherMock{}
+	marshalizer := &mock.MarshalizerMock{}
+	metaBlock, metaHash := createMetaBlockWithoutFinalityAttestingHeader(marshalizer, hasher)
+	attestingMetaBlock := &block.MetaBlock{
+		Nonce:     metaBlock.GetNonce() + 1,
+		ShardInfo: make([]block.ShardData, 0),
+		Round:     metaBlock.GetRound() + 1,
+		PrevHash:  metaHash,
+	}
+	attestingMetaHash, _ := core.CalculateHash(marshalizer, hasher, attestingMetaBlock)
 
-	arguments := CreateMockArgumentsMultiShard()
+	headersPool := testscommon.NewPoolsHolderMock().Headers()
+	headersPool.AddHeader(metaHash, metaBlock)
+	headersPool.AddHeader(attestingMetaHash, attestingMetaBlock)
+
+	arguments, hdr, body := createArgumentsAndBlockAttestingMetaBlock(metaHash, headersPool)
+	arguments.AccountsDB[state.UserAccountsState].(*mock.AccountsStub).CommitCalled = func() ([]byte, error) {
+		return []byte("rootHash"), nil
+	}
+	arguments.Store = initStore()
+	arguments.Uint64Converter = uint64ByteSlice.NewBigEndianConverter()
 	arguments.ForkDetector = &mock.ForkDetectorMock{
+		AddHeaderCalled: func(header data.HeaderHandler, hash []byte, state process.BlockHeaderState, selfNotarizedHeaders []data.HeaderHandler, selfNotarizedHeadersHashes [][]byte) error {
+			return nil
+		},
 		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return finalNonce
+			return 0
 		},
 		GetHighestFinalBlockHashCalled: func() []byte {
-			return finalHash
+			return nil
 		},
 	}
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(nil, nil)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	require.Nil(t, err)
 
-	require.Equal(t, 1, len(bootstrapHeaderInfos))
-	assert.Equal(t, finalHash, bootstrapHeaderInfos[0].Hash)
+	err = sp.CommitBlock(hdr, body)
+	assert.Nil(t, err)
 }
 
-func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnOneItemWhenFinalNonceIsNotHigherThanSelfNotarizedNonce(t *testing.T) {
+func TestShardProcessor_ProcessBlockWithMiniBlockToInvalidShardShouldErr(t *testing.T) {
 	t.Parallel()
 
-	arguments := CreateMockArgumentsMultiShard()
-	arguments.ForkDetector = &mock.ForkDetectorMock{
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return 0
-		},
-	}
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	body.MiniBlocks[0].ReceiverShardID = 7
+
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.BodyShardIdsCheckEnableEpoch = 0
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	hash := []byte("hash")
-	header := &block.Header{}
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.True(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
+}
 
-	selfNotarizedHeaders := make([]data.HeaderHandler, 0)
-	selfNotarizedHeadersHashes := make([][]byte, 0)
+func TestShardProcessor_ProcessBlockWithMiniBlockToInvalidShardBeforeEnableEpochShouldNotCheckTheShardIds(t *testing.T) {
+	t.Parallel()
 
-	selfNotarizedHeaders = append(selfNotarizedHeaders, header)
-	selfNotarizedHeadersHashes = append(selfNotarizedHeadersHashes, hash)
+	rootHash := []byte("rootHash")
+	hdr, body := createIntraShardBlockForProcessing(rootHash)
+	body.MiniBlocks[0].ReceiverShardID = 7
 
-	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(selfNotarizedHeaders, selfNotarizedHeadersHashes)
+	arguments := createArgumentsForIntraShardBlockProcessing(rootHash)
+	arguments.BodyShardIdsCheckEnableEpoch = hdr.Epoch + 1
+	sp, _ := blproc.NewShardProcessor(arguments)
 
-	require.Equal(t, 1, len(bootstrapHeaderInfos))
-	assert.Equal(t, hash, bootstrapHeaderInfos[0].Hash)
+	err := sp.ProcessBlock(hdr, body, haveTime)
+	assert.False(t, errors.Is(err, process.ErrMiniBlockInvalidShardId))
 }
 
-func TestShardProcessor_GetBootstrapHeadersInfoShouldReturnTwoItemsWhenFinalNonceIsHigherThanSelfNotarizedNonce(t *testing.T) {
+func TestNewShardProcessor_NegativeMetaBlockFinalityShouldErr(t *testing.T) {
 	t.Parallel()
 
-	finalNonce := uint64(2)
-	finalHash := []byte("final hash")
-
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.ForkDetector = &mock.ForkDetectorMock{
-		GetHighestFinalBlockNonceCalled: func() uint64 {
-			return finalNonce
-		},
-		GetHighestFinalBlockHashCalled: func() []byte {
-			return finalHash
-		},
-	}
-	sp, _ := blproc.NewShardProcessor(arguments)
-
-	hash := []byte("hash")
-	header := &block.Header{Nonce: 1}
+	arguments.MetaBlockFinality = -1
 
-	selfNotarizedHeaders := make([]data.HeaderHandler, 0)
-	selfNotarizedHeadersHashes := make([][]byte, 0)
+	sp, err := blproc.NewShardProcessor(arguments)
+	assert.Nil(t, sp)
+	assert.True(t, errors.Is(err, process.ErrInvalidMetaBlockFinality))
+}
 
-	selfNotarizedHeaders = append(selfNotarizedHeaders, header)
-	selfNotarizedHeadersHashes = append(selfNotarizedHeadersHashes, hash)
+func TestNewShardProcessor_ZeroMetaBlockFinalityShouldUseTheDefault(t *testing.T) {
+	t.Parallel()
 
-	bootstrapHeaderInfos := sp.GetBootstrapHeadersInfo(selfNotarizedHeaders, selfNotarizedHeadersHashes)
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MetaBlockFinality = 0
 
-	require.Equal(t, 2, len(bootstrapHeaderInfos))
-	assert.Equal(t, hash, bootstrapHeaderInfos[0].Hash)
-	assert.Equal(t, finalHash, bootstrapHeaderInfos[1].Hash)
+	sp, err := blproc.NewShardProcessor(arguments)
+	require.Nil(t, err)
+	assert.Equal(t, uint32(process.BlockFinality), sp.MetaBlockFinality())
 }
 
-func TestShardProcessor_RequestMetaHeadersIfNeededShouldAddHeaderIntoTrackerPool(t *testing.T) {
+func TestNewShardProcessor_MetaBlockFinalityShouldBeSet(t *testing.T) {
 	t.Parallel()
 
-	var addedNonces []uint64
+	arguments := CreateMockArgumentsMultiShard()
+	arguments.MetaBlockFinality = 3
 
-	rounderMock := &mock.RounderMock{}
+	sp, err := blproc.NewShardProcessor(arguments)
+	require.Nil(t, err)
+	assert.Equal(t, uint32(3), sp.MetaBlockFinality())
+}
+
+func TestNewShardProcessor_InvalidStorageWriteModeShouldErr(t *testing.T) {
+	t.Parallel()
 
 	arguments := CreateMockArgumentsMultiShard()
-	arguments.Rounder = rounderMock
+	arguments.StorageUnitsWriteMode = map[string]string{"ShardHdrNonceHashDataUnit": "invalid"}
+	sp, err := blproc.NewShardProcessor(arguments)
+	assert.Nil(t, sp)
+	assert.True(t, errors.Is(err, process.ErrInvalidStorageWriteMode))
 
-	poolsHolderStub := initDataPool([]byte(""))
-	poolsHolderStub.HeadersCalled = func() dataRetriever.HeadersPool {
-		return &mock.HeadersCacherStub{
-			GetHeaderByNonceAndShardIdCalled: func(hdrNonce uint64, shardId uint32) ([]data.HeaderHandler, [][]byte, error) {
-				addedNonces = append(addedNonces, hdrNonce)
-				return []data.HeaderHandler{&block.MetaBlock{Nonce: 1}}, [][]byte{[]byte("hash")}, nil
-			},
+	arguments.StorageUnitsWriteMode = map[string]string{"UnknownUnit": string(process.AsyncWrite)}
+	sp, err = blproc.NewShardProcessor(arguments)
+	assert.Nil(t, sp)
+	assert.True(t, errors.Is(err, process.ErrInvalidStorageWriteMode))
+}
+
+func TestShardProcessor_CommitBlockShouldWriteTheAsyncStorageUnitsOnTheAsyncPath(t *testing.T) {
+	t.Parallel()
+
+	rootHash := []byte("root hash")
+	genesisHash := []byte("genesis hash")
+	hdr := createFirstBlockHeaderAfterGenesis(rootHash, genesisHash)
+	arguments := createArgumentsForCommittingFirstBlock(rootHash, genesisHash, &mock.MarshalizerMock{})
+	arguments.StorageUnitsWriteMode = map[string]string{
+		"ShardHdrNonceHashDataUnit": string(process.AsyncWrite),
+		"BlockHeaderUnit":           string(process.AsyncWrite),
+	}
+
+	chCommitDone := make(chan struct{})
+	var nonceHashWrittenAfterCommit, headerWrittenBeforeCommit atomicCore.Flag
+	isCommitDone := func() bool {
+		select {
+		case <-chCommitDone:
+			return true
+		case <-time.After(time.Second):
+			return false
 		}
 	}
-	arguments.DataPool = poolsHolderStub
-
+	store := arguments.Store.(*dataRetriever.ChainStorer)
+	store.AddStorer(dataRetriever.ShardHdrNonceHashDataUnit, &mock.StorerStub{
+		PutCalled: func(key, data []byte) error {
+			// an async write is able to wait for the commit to finish
+			nonceHashWrittenAfterCommit.Toggle(isCommitDone())
+			return nil
+		},
+	})
+	store.AddStorer(dataRetriever.BlockHeaderUnit, &mock.StorerStub{
+		PutCalled: func(key, data []byte) error {
+			select {
+			case <-chCommitDone:
+			default:
+				_ = headerWrittenBeforeCommit.Set()
+			}
+			return nil
+		},
+	})
 	sp, _ := blproc.NewShardProcessor(arguments)
 
-	rounderMock.RoundIndex = 20
-	metaBlock := &block.MetaBlock{
-		Round: 9,
-		Nonce: 5,
-	}
-	sp.RequestMetaHeadersIfNeeded(0, metaBlock)
+	err := sp.CommitBlock(hdr, &block.Body{})
+	require.Nil(t, err)
+	close(chCommitDone)
 
-	expectedAddedNonces := []uint64{6, 7}
-	assert.Equal(t, expectedAddedNonces, addedNonces)
+	// closing the processor waits for the in-flight async writes
+	err = sp.Close()
+	require.Nil(t, err)
+
+	assert.True(t, nonceHashWrittenAfterCommit.IsSet())
+	assert.True(t, headerWrittenBeforeCommit.IsSet())
 }
@@ -0,0 +1,67 @@
+package block
+
+import "github.com/ElrondNetwork/elrond-go/core"
+
+// statusMetricsBatch is a status handler which collects the metric values set while processing or committing a block,
+// so that they are passed at once to the underlying status handler when flushed. The increments and decrements are
+// forwarded directly, as they depend on the values held by the underlying handler. It is not concurrent safe
+type statusMetricsBatch struct {
+	handler core.AppStatusHandler
+	values  map[string]interface{}
+}
+
+func newStatusMetricsBatch(handler core.AppStatusHandler) *statusMetricsBatch {
+	return &statusMetricsBatch{
+		handler: handler,
+		values:  make(map[string]interface{}),
+	}
+}
+
+// Increment forwards the increment of the given metric to the underlying status handler
+func (smb *statusMetricsBatch) Increment(key string) {
+	smb.handler.Increment(key)
+}
+
+// AddUint64 forwards the increase of the given metric to the underlying status handler
+func (smb *statusMetricsBatch) AddUint64(key string, val uint64) {
+	smb.handler.AddUint64(key, val)
+}
+
+// Decrement forwards the decrement of the given metric to the underlying status handler
+func (smb *statusMetricsBatch) Decrement(key string) {
+	smb.handler.Decrement(key)
+}
+
+// SetInt64Value collects the int64 value of the given metric
+func (smb *statusMetricsBatch) SetInt64Value(key string, value int64) {
+	smb.values[key] = value
+}
+
+// SetUInt64Value collects the uint64 value of the given metric
+func (smb *statusMetricsBatch) SetUInt64Value(key string, value uint64) {
+	smb.values[key] = value
+}
+
+// SetStringValue collects the string value of the given metric
+func (smb *statusMetricsBatch) SetStringValue(key string, value string) {
+	smb.values[key] = value
+}
+
+// Close does nothing, as the underlying status handler is not owned by the batch
+func (smb *statusMetricsBatch) Close() {
+}
+
+// flush passes the collected values to the underlying status handler and starts a new batch
+func (smb *statusMetricsBatch) flush() {
+	if len(smb.values) == 0 {
+		return
+	}
+
+	core.SetMultipleStatusValues(smb.handler, smb.values)
+	smb.values = make(map[string]interface{})
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (smb *statusMetricsBatch) IsInterfaceNil() bool {
+	return smb == nil
+}
@@ -0,0 +1,60 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/ElrondNetwork/elrond-go/dataRetriever"
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+const shardHdrNonceHashDataUnitName = "ShardHdrNonceHashDataUnit"
+
+// consensusCriticalStorageUnits holds the storage units which are always written synchronously on commit, regardless
+// of their configured write mode, as the committed block could not be recovered without them
+var consensusCriticalStorageUnits = map[dataRetriever.UnitType]struct{}{
+	dataRetriever.BlockHeaderUnit: {},
+	dataRetriever.MiniBlockUnit:   {},
+	dataRetriever.MetaBlockUnit:   {},
+}
+
+// createAsyncStorageUnits returns the storage units written on commit which were configured to be written
+// asynchronously. The shard header nonce-hash unit name refers to the unit of the given self shard
+func createAsyncStorageUnits(
+	storageUnitsWriteMode map[string]string,
+	selfShardID uint32,
+) (map[dataRetriever.UnitType]struct{}, error) {
+	unitsByName := map[string]dataRetriever.UnitType{
+		shardHdrNonceHashDataUnitName:          dataRetriever.ShardHdrNonceHashDataUnit + dataRetriever.UnitType(selfShardID),
+		dataRetriever.ReceiptsUnit.String():    dataRetriever.ReceiptsUnit,
+		dataRetriever.BlockHeaderUnit.String(): dataRetriever.BlockHeaderUnit,
+		dataRetriever.MiniBlockUnit.String():   dataRetriever.MiniBlockUnit,
+		dataRetriever.MetaBlockUnit.String():   dataRetriever.MetaBlockUnit,
+	}
+
+	asyncStorageUnits := make(map[dataRetriever.UnitType]struct{})
+	for unitName, writeMode := range storageUnitsWriteMode {
+		unit, ok := unitsByName[unitName]
+		if !ok {
+			return nil, fmt.Errorf("%w, unknown storage unit: %s", process.ErrInvalidStorageWriteMode, unitName)
+		}
+
+		switch process.StorageWriteMode(writeMode) {
+		case process.SyncWrite:
+			continue
+		case process.AsyncWrite:
+		default:
+			return nil, fmt.Errorf("%w, storage unit: %s, write mode: %s",
+				process.ErrInvalidStorageWriteMode, unitName, writeMode)
+		}
+
+		_, isConsensusCritical := consensusCriticalStorageUnits[unit]
+		if isConsensusCritical {
+			log.Warn("consensus critical storage unit is always written synchronously", "unit", unitName)
+			continue
+		}
+
+		asyncStorageUnits[unit] = struct{}{}
+	}
+
+	return asyncStorageUnits, nil
+}
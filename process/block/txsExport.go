@@ -0,0 +1,69 @@
+package block
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ElrondNetwork/elrond-go/core"
+	"github.com/ElrondNetwork/elrond-go/data"
+)
+
+// exportedTx is the line written in the txs export file for each transaction of a committed block
+type exportedTx struct {
+	Hash string                  `json:"hash"`
+	Tx   data.TransactionHandler `json:"tx"`
+}
+
+// createTxsExportFileName returns the name of the file in which the transactions of the given block are exported
+func createTxsExportFileName(header data.HeaderHandler, headerHash []byte) string {
+	return fmt.Sprintf("txs_shard_%d_nonce_%d_%s.jsonl", header.GetShardID(), header.GetNonce(), hex.EncodeToString(headerHash))
+}
+
+// exportTxsToFile writes the given transactions in the provided directory, in a JSON lines file, ordered by hash
+func exportTxsToFile(directory string, fileName string, txPool map[string]data.TransactionHandler) error {
+	err := os.MkdirAll(directory, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(
+		filepath.Join(directory, fileName),
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		core.FileModeUserReadWrite,
+	)
+	if err != nil {
+		return err
+	}
+
+	hashes := make([]string, 0, len(txPool))
+	for hash := range txPool {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, hash := range hashes {
+		err = encoder.Encode(&exportedTx{
+			Hash: hex.EncodeToString([]byte(hash)),
+			Tx:   txPool[hash],
+		})
+		if err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	err = writer.Flush()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
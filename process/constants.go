@@ -1,5 +1,7 @@
 package process
 
+import "fmt"
+
 // BlockHeaderState specifies which is the state of the block header received
 type BlockHeaderState int
 
@@ -36,6 +38,63 @@ const (
 	InvalidTransaction
 )
 
+// BodyComposition specifies the order in which the miniblocks are added in a created shard block body
+type BodyComposition string
+
+const (
+	// CrossShardFirst defines the body composition in which the cross shard miniblocks with destination in self shard
+	// are added before the miniblocks from self shard
+	CrossShardFirst BodyComposition = "CrossShardFirst"
+	// FromMeFirst defines the body composition in which the miniblocks from self shard are added before the cross
+	// shard miniblocks with destination in self shard
+	FromMeFirst BodyComposition = "FromMeFirst"
+)
+
+// StorageWriteMode specifies how a storage unit is written when a block is committed
+type StorageWriteMode string
+
+const (
+	// SyncWrite defines the write mode in which the commit waits for the storage unit write to finish
+	SyncWrite StorageWriteMode = "Sync"
+	// AsyncWrite defines the write mode in which the storage unit is written on a background go routine, without
+	// delaying the commit
+	AsyncWrite StorageWriteMode = "Async"
+)
+
+// ProcessBlockStage specifies the stage of the block processing in which an error occurred
+type ProcessBlockStage int
+
+const (
+	// StageHeaderBodyCorrelation defines the stage in which the header is checked against the body
+	StageHeaderBodyCorrelation ProcessBlockStage = iota
+	// StageMetaHeadersValidity defines the stage in which the validity and finality of the meta headers are checked
+	StageMetaHeadersValidity
+	// StageCrossShardMiniBlocks defines the stage in which the cross shard miniblocks with destination me are verified
+	StageCrossShardMiniBlocks
+	// StageTxProcessing defines the stage in which the block transactions are processed and verified
+	StageTxProcessing
+	// StageStateRoot defines the stage in which the resulted state root is checked against the header one
+	StageStateRoot
+)
+
+// String returns the human readable name of the process block stage
+func (stage ProcessBlockStage) String() string {
+	switch stage {
+	case StageHeaderBodyCorrelation:
+		return "header body correlation"
+	case StageMetaHeadersValidity:
+		return "meta headers validity"
+	case StageCrossShardMiniBlocks:
+		return "cross shard miniblocks"
+	case StageTxProcessing:
+		return "tx processing"
+	case StageStateRoot:
+		return "state root"
+	default:
+		return fmt.Sprintf("unknown stage %d", int(stage))
+	}
+}
+
 // BlockFinality defines the block finality which is used in meta-chain/shards (the real finality in shards is given
 // by meta-chain)
 const BlockFinality = 1
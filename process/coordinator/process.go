@@ -447,10 +447,29 @@ func (tc *transactionCoordinator) RemoveTxsFromPool(body *block.Body) error {
 	return errFound
 }
 
-// ProcessBlockTransaction processes transactions and updates state tries
+// ProcessBlockTransaction processes transactions and updates state tries. The cross shard miniblocks with destination
+// in self shard are expected to be placed, in the given body, before the miniblocks from self shard
 func (tc *transactionCoordinator) ProcessBlockTransaction(
 	body *block.Body,
 	timeRemaining func() time.Duration,
+) error {
+	return tc.processBlockTransaction(body, timeRemaining, process.CrossShardFirst)
+}
+
+// ProcessBlockTransactionFromMeFirst processes transactions and updates state tries. The miniblocks from self shard
+// are expected to be placed, in the given body, before the cross shard miniblocks with destination in self shard,
+// which are followed only by the post process miniblocks
+func (tc *transactionCoordinator) ProcessBlockTransactionFromMeFirst(
+	body *block.Body,
+	timeRemaining func() time.Duration,
+) error {
+	return tc.processBlockTransaction(body, timeRemaining, process.FromMeFirst)
+}
+
+func (tc *transactionCoordinator) processBlockTransaction(
+	body *block.Body,
+	timeRemaining func() time.Duration,
+	bodyComposition process.BodyComposition,
 ) error {
 	if check.IfNil(body) {
 		return process.ErrNilBlockBody
@@ -472,6 +491,22 @@ func (tc *transactionCoordinator) ProcessBlockTransaction(
 			"num txs", len(miniBlock.TxHashes))
 	}
 
+	if bodyComposition == process.FromMeFirst {
+		numMiniBlocksFromMe := tc.computeNumLeadingMiniBlocksFromMe(body)
+
+		startTime := time.Now()
+		err := tc.processMiniBlocksFromMe(&block.Body{MiniBlocks: body.MiniBlocks[:numMiniBlocksFromMe]}, haveTime)
+		elapsedTime := time.Since(startTime)
+		log.Debug("elapsed time to processMiniBlocksFromMe",
+			"time [s]", elapsedTime,
+		)
+		if err != nil {
+			return err
+		}
+
+		body = &block.Body{MiniBlocks: body.MiniBlocks[numMiniBlocksFromMe:]}
+	}
+
 	startTime := time.Now()
 	mbIndex, err := tc.processMiniBlocksToMe(body, haveTime)
 	elapsedTime := time.Since(startTime)
@@ -500,6 +535,18 @@ func (tc *transactionCoordinator) ProcessBlockTransaction(
 	return nil
 }
 
+// computeNumLeadingMiniBlocksFromMe returns the number of miniblocks from self shard placed, in the given body, before
+// the first miniblock from another shard
+func (tc *transactionCoordinator) computeNumLeadingMiniBlocksFromMe(body *block.Body) int {
+	selfShardID := tc.shardCoordinator.SelfId()
+	numMiniBlocksFromMe := 0
+	for numMiniBlocksFromMe < len(body.MiniBlocks) && body.MiniBlocks[numMiniBlocksFromMe].SenderShardID == selfShardID {
+		numMiniBlocksFromMe++
+	}
+
+	return numMiniBlocksFromMe
+}
+
 func (tc *transactionCoordinator) processMiniBlocksFromMe(
 	body *block.Body,
 	haveTime func() bool,
@@ -672,9 +719,40 @@ func (tc *transactionCoordinator) CreateMbsAndProcessCrossShardTransactionsDstMe
 	return miniBlocks, nrTxAdded, allMBsProcessed, nil
 }
 
-// CreateMbsAndProcessTransactionsFromMe creates miniblocks and processes transactions from pool
+// CreateMbsAndProcessTransactionsFromMe creates miniblocks and processes transactions from pool, followed by the post
+// process miniblocks. The transactions are selected as in CreateMbsAndProcessTransactionsFromMeWithoutPostProcess
 func (tc *transactionCoordinator) CreateMbsAndProcessTransactionsFromMe(
 	haveTime func() bool,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
+) block.MiniBlockSlice {
+
+	miniBlocks := tc.CreateMbsAndProcessTransactionsFromMeWithoutPostProcess(
+		haveTime,
+		minGasPriceForInclusion,
+		maxTxDataSize,
+		maxTxsPerDestShard,
+	)
+
+	interMBs := tc.CreatePostProcessMiniBlocks()
+	if len(interMBs) > 0 {
+		miniBlocks = append(miniBlocks, interMBs...)
+	}
+
+	return miniBlocks
+}
+
+// CreateMbsAndProcessTransactionsFromMeWithoutPostProcess creates miniblocks and processes transactions from pool.
+// The transactions with a gas price lower than the given min gas price for inclusion or with a data field larger than
+// the given max tx data size are skipped, as well as the cross shard ones over the given max txs per dest shard, if
+// set. The post process miniblocks are not included, so that they could be created with CreatePostProcessMiniBlocks
+// after other transactions are processed in the same block
+func (tc *transactionCoordinator) CreateMbsAndProcessTransactionsFromMeWithoutPostProcess(
+	haveTime func() bool,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
 ) block.MiniBlockSlice {
 
 	miniBlocks := make(block.MiniBlockSlice, 0)
@@ -684,7 +762,7 @@ func (tc *transactionCoordinator) CreateMbsAndProcessTransactionsFromMe(
 			return nil
 		}
 
-		mbs, err := txPreProc.CreateAndProcessMiniBlocks(haveTime)
+		mbs, err := txPreProc.CreateAndProcessMiniBlocks(haveTime, minGasPriceForInclusion, maxTxDataSize, maxTxsPerDestShard)
 		if err != nil {
 			log.Debug("CreateAndProcessMiniBlocks", "error", err.Error())
 		}
@@ -694,11 +772,6 @@ func (tc *transactionCoordinator) CreateMbsAndProcessTransactionsFromMe(
 		}
 	}
 
-	interMBs := tc.CreatePostProcessMiniBlocks()
-	if len(interMBs) > 0 {
-		miniBlocks = append(miniBlocks, interMBs...)
-	}
-
 	return miniBlocks
 }
 
@@ -1334,6 +1407,31 @@ func (tc *transactionCoordinator) getMaxAccumulatedAndDeveloperFees(
 	return maxAccumulatedFeesFromMiniBlock, maxDeveloperFeesFromMiniBlock, nil
 }
 
+// GetTransactionsExecutionResults returns the execution results of the transactions processed by the pre processors
+func (tc *transactionCoordinator) GetTransactionsExecutionResults() []*process.TransactionExecutionResult {
+	results := make([]*process.TransactionExecutionResult, 0)
+	for _, blockType := range tc.keysTxPreProcs {
+		preProc := tc.getPreProcessor(blockType)
+		if check.IfNil(preProc) {
+			continue
+		}
+
+		resultsHandler, ok := preProc.(process.TransactionsExecutionResultsHandler)
+		if !ok {
+			continue
+		}
+
+		results = append(results, resultsHandler.GetTransactionsExecutionResults()...)
+	}
+
+	return results
+}
+
+// GetTotalGasRefunded returns the total gas refunded by the transactions processed in the current block
+func (tc *transactionCoordinator) GetTotalGasRefunded() uint64 {
+	return tc.gasHandler.TotalGasRefunded()
+}
+
 // IsInterfaceNil returns true if there is no value under the interface
 func (tc *transactionCoordinator) IsInterfaceNil() bool {
 	return tc == nil
@@ -1369,7 +1369,7 @@ func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeNothingToPr
 	haveTime := func() bool {
 		return true
 	}
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, 0, len(mbs))
 }
@@ -1400,7 +1400,7 @@ func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeNoTime(t *t
 	haveTime := func() bool {
 		return false
 	}
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, 0, len(mbs))
 }
@@ -1436,7 +1436,7 @@ func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeNoSpace(t *
 	haveTime := func() bool {
 		return true
 	}
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, 0, len(mbs))
 }
@@ -1486,11 +1486,61 @@ func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMe(t *testing
 	}
 
 	// we have one tx per shard.
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, int(nrShards), len(mbs))
 }
 
+func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeShouldPassTheMaxTxsPerDestShard(t *testing.T) {
+	t.Parallel()
+
+	maxTxsPerDestShard := uint32(5)
+	receivedMaxTxsPerDestShard := make([]uint32, 0)
+	preProcessor := &mock.PreProcessorMock{
+		CreateAndProcessMiniBlocksCalled: func(
+			haveTime func() bool,
+			minGasPriceForInclusion uint64,
+			maxTxDataSize uint64,
+			maxTxsPerDestShard uint32,
+		) (block.MiniBlockSlice, error) {
+			receivedMaxTxsPerDestShard = append(receivedMaxTxsPerDestShard, maxTxsPerDestShard)
+			return make(block.MiniBlockSlice, 0), nil
+		},
+	}
+	preProcessors := &mock.PreProcessorContainerMock{
+		GetCalled: func(key block.Type) (process.PreProcessor, error) {
+			return preProcessor, nil
+		},
+		KeysCalled: func() []block.Type {
+			return []block.Type{block.SmartContractResultBlock, block.TxBlock}
+		},
+	}
+	tc, _ := NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		&mock.AccountsStub{},
+		initDataPool([]byte("tx_hash1")).MiniBlocks(),
+		&mock.RequestHandlerStub{},
+		preProcessors,
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+
+	haveTime := func() bool {
+		return true
+	}
+	_ = tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, maxTxsPerDestShard)
+
+	assert.Equal(t, []uint32{maxTxsPerDestShard, maxTxsPerDestShard}, receivedMaxTxsPerDestShard)
+}
+
 func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeMultipleMiniblocks(t *testing.T) {
 	t.Parallel()
 
@@ -1547,7 +1597,7 @@ func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeMultipleMin
 	}
 
 	// we have one tx per shard.
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, 1, len(mbs))
 }
@@ -1617,7 +1667,7 @@ func TestTransactionCoordinator_CreateMbsAndProcessTransactionsFromMeMultipleMin
 	}
 
 	// we have one tx per shard.
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, 1, len(mbs))
 }
@@ -1691,7 +1741,7 @@ func TestTransactionCoordinator_CompactAndExpandMiniblocksShouldWork(t *testing.
 		}
 	}
 
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 
 	assert.Equal(t, 1, len(mbs))
 }
@@ -1748,7 +1798,7 @@ func TestTransactionCoordinator_GetAllCurrentUsedTxs(t *testing.T) {
 		txPool.AddData(txHash, newTx, newTx.Size(), strCache)
 	}
 
-	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime)
+	mbs := tc.CreateMbsAndProcessTransactionsFromMe(haveTime, 0, 0, 0)
 	require.Equal(t, 5, len(mbs))
 
 	usedTxs = tc.GetAllCurrentUsedTxs(block.TxBlock)
@@ -2137,6 +2187,52 @@ func TestTransactionCoordinator_ProcessBlockTransaction(t *testing.T) {
 	assert.Equal(t, process.ErrMissingTransaction, err)
 }
 
+func TestTransactionCoordinator_ProcessBlockTransactionWithMiniBlocksFromMeFirst(t *testing.T) {
+	t.Parallel()
+
+	txHash := []byte("tx_hash1")
+	dataPool := initDataPool(txHash)
+	tc, err := NewTransactionCoordinator(
+		&mock.HasherMock{},
+		&mock.MarshalizerMock{},
+		mock.NewMultiShardsCoordinatorMock(3),
+		initAccountsMock(),
+		dataPool.MiniBlocks(),
+		&mock.RequestHandlerStub{},
+		createPreProcessorContainerWithDataPool(dataPool, FeeHandlerMock()),
+		&mock.InterimProcessorContainerMock{},
+		&mock.GasHandlerMock{},
+		&mock.FeeAccumulatorStub{},
+		&mock.BlockSizeComputationStub{},
+		&mock.BalanceComputationStub{},
+		&mock.FeeHandlerStub{},
+		&mock.TxTypeHandlerMock{},
+		0,
+	)
+	require.Nil(t, err)
+
+	haveTime := func() time.Duration {
+		return time.Second
+	}
+	body := &block.Body{
+		MiniBlocks: []*block.MiniBlock{
+			{SenderShardID: 0, ReceiverShardID: 0, Type: block.TxBlock, TxHashes: [][]byte{txHash}},
+			{SenderShardID: 1, ReceiverShardID: 0, Type: block.TxBlock, TxHashes: [][]byte{txHash}},
+		},
+	}
+
+	tc.RequestBlockTransactions(body)
+	err = tc.ProcessBlockTransaction(body, haveTime)
+	assert.Equal(t, process.ErrMiniBlocksInWrongOrder, err)
+
+	err = tc.ProcessBlockTransactionFromMeFirst(body, haveTime)
+	assert.Nil(t, err)
+
+	body.MiniBlocks = append(body.MiniBlocks, body.MiniBlocks[0], body.MiniBlocks[1])
+	err = tc.ProcessBlockTransactionFromMeFirst(body, haveTime)
+	assert.Equal(t, process.ErrMiniBlocksInWrongOrder, err)
+}
+
 func TestTransactionCoordinator_RequestMiniblocks(t *testing.T) {
 	t.Parallel()
 
@@ -311,6 +311,12 @@ var ErrCrossShardMBWithoutConfirmationFromMeta = errors.New("cross shard miniblo
 // ErrHeaderBodyMismatch signals that the header does not attest all data from the block
 var ErrHeaderBodyMismatch = errors.New("body cannot be validated from header data")
 
+// ErrHeaderRoundClockSkew signals that the timestamp implied by the header round is too far from the local time
+var ErrHeaderRoundClockSkew = errors.New("header round implies a timestamp too far from the local time")
+
+// ErrHeaderTxCountMismatch signals that the header tx count does not match the number of transactions from the body
+var ErrHeaderTxCountMismatch = errors.New("header tx count does not match the number of transactions in body")
+
 // ErrNilSmartContractProcessor signals that smart contract call executor is nil
 var ErrNilSmartContractProcessor = errors.New("smart contract processor is nil")
 
@@ -875,6 +881,9 @@ var ErrRelayedTxGasLimitMissmatch = errors.New("relayed tx gas limit higher then
 // ErrRelayedGasPriceMissmatch signals that relayed gas price is not equal with user tx
 var ErrRelayedGasPriceMissmatch = errors.New("relayed gas price missmatch")
 
+// ErrInvalidInnerTxSignature signals that the inner transaction of a relayed transaction is not signed by its sender
+var ErrInvalidInnerTxSignature = errors.New("invalid inner transaction signature")
+
 // ErrNilUserAccount signals that nil user account was provided
 var ErrNilUserAccount = errors.New("nil user account")
 
@@ -946,3 +955,117 @@ var ErrMaxAccumulatedFeesExceeded = errors.New("max accumulated fees has been ex
 
 // ErrMaxDeveloperFeesExceeded signals that max developer fees has been exceeded
 var ErrMaxDeveloperFeesExceeded = errors.New("max developer fees has been exceeded")
+
+// ErrDuplicateMiniBlockInBody signals that the same miniblock hash was found more than once in a block body
+var ErrDuplicateMiniBlockInBody = errors.New("duplicate miniblock hash in block body")
+
+// ErrMissingParentHeader signals that the previous header of the block to be committed was not found in storage
+var ErrMissingParentHeader = errors.New("parent header is missing from storage")
+
+// ErrNoWorkToPropose signals that the created block body is empty and the empty blocks production is disabled
+var ErrNoWorkToPropose = errors.New("no work to propose")
+
+// ErrBlockNotInStorage signals that the requested block was not found in storage
+var ErrBlockNotInStorage = errors.New("block not found in storage")
+
+// ErrMiniBlockWrongReceiverShard signals that a miniblock considered as having the destination in self shard is
+// destined to another shard
+var ErrMiniBlockWrongReceiverShard = errors.New("miniblock has a wrong receiver shard")
+
+// ErrInvalidRootHashLength signals that the root hash length does not match the hasher output size
+var ErrInvalidRootHashLength = errors.New("invalid root hash length")
+
+// ErrMissingMiniBlockInStorage signals that a miniblock could not be found in the miniblocks storage unit
+var ErrMissingMiniBlockInStorage = errors.New("missing miniblock in storage")
+
+// ErrNilTxsPoolsCleaner signals that a nil txs pools cleaner has been provided
+var ErrNilTxsPoolsCleaner = errors.New("nil txs pools cleaner")
+
+// ErrTooManyAttestedMetaBlocks signals that a shard block attests more metablocks than allowed
+var ErrTooManyAttestedMetaBlocks = errors.New("too many attested metablocks")
+
+// ErrHeaderEpochMismatch signals that the header epoch is not consistent with the epochs of the attested metablocks
+var ErrHeaderEpochMismatch = errors.New("header epoch mismatch")
+
+// ErrNilCommittedBlockSink signals that a nil committed block sink has been provided
+var ErrNilCommittedBlockSink = errors.New("nil committed block sink")
+
+// ErrHeaderReferencesMissingTxs signals that a created header references transactions which are not used by the
+// transaction coordinator
+var ErrHeaderReferencesMissingTxs = errors.New("header references missing transactions")
+
+// ErrMiniBlockReferencesMissingTxs signals that a miniblock references transactions which can not be resolved by the
+// transaction coordinator
+var ErrMiniBlockReferencesMissingTxs = errors.New("miniblock references missing transactions")
+
+// ErrInvalidBodyComposition signals that an invalid body composition has been provided
+var ErrInvalidBodyComposition = errors.New("invalid body composition")
+
+// ErrNilBlockCreationPolicy signals that a nil block creation policy has been provided
+var ErrNilBlockCreationPolicy = errors.New("nil block creation policy")
+
+// ErrZeroBlockFinality signals that a zero block finality has been provided
+var ErrZeroBlockFinality = errors.New("zero block finality")
+
+// ErrRoundMismatchBetweenBodyAndHeader signals that the header round is different from the round for which the block
+// body was created
+var ErrRoundMismatchBetweenBodyAndHeader = errors.New("round mismatch between body and header")
+
+// ErrMiniBlockReservedFieldMismatch signals that the reserved field of a miniblock from body is different from the one
+// of its miniblock header
+var ErrMiniBlockReservedFieldMismatch = errors.New("miniblock reserved field mismatch")
+
+// ErrFinalityChangedBeforeCommit signals that the highest meta block attested by the block is no longer final, against
+// the meta blocks currently found in pool, when the block is about to be committed
+var ErrFinalityChangedBeforeCommit = errors.New("meta block finality changed before commit")
+
+// ErrInvalidStorageWriteMode signals that an invalid storage unit write mode configuration has been provided
+var ErrInvalidStorageWriteMode = errors.New("invalid storage write mode")
+
+// ErrInvalidNonceRange signals that the start nonce of a nonce range is higher than its end nonce
+var ErrInvalidNonceRange = errors.New("invalid nonce range")
+
+// ErrReorgTooDeep signals that the number of consecutive blocks restored into pools, without any block being committed
+// in between, reached the configured max reorg depth
+var ErrReorgTooDeep = errors.New("reorg too deep")
+
+// ErrMiniBlockInvalidShardId signals that a miniblock from body has a sender or a receiver shard id which is neither
+// an existing shard nor the metachain
+var ErrMiniBlockInvalidShardId = errors.New("miniblock with invalid shard id")
+
+// ErrBlockNotFinal signals that the finality of a block is not yet established, either because it was not notarized
+// by metachain or because not enough metablocks were constructed on top of the one which notarized it
+var ErrBlockNotFinal = errors.New("block is not final")
+
+// ErrBlockProcessingInProgress signals that a block is being processed or created and was not yet committed or reverted
+var ErrBlockProcessingInProgress = errors.New("block processing in progress")
+
+// ErrBlockValidationNotSupported signals that the accounts adapter used by the blocks processing can not be switched to
+// scratch accounts, so a block can not be validated without being applied
+var ErrBlockValidationNotSupported = errors.New("block validation not supported by the accounts adapter")
+
+// ErrInvalidMetaBlocksPoolHighFillRatio signals that the provided meta blocks pool high fill ratio is not between 0 and 1
+var ErrInvalidMetaBlocksPoolHighFillRatio = errors.New("invalid meta blocks pool high fill ratio")
+
+// ErrInvalidMetaBlockFinality signals that the provided meta block finality is negative
+var ErrInvalidMetaBlockFinality = errors.New("invalid meta block finality")
+
+// ErrRelayedGasPriceTooLow signals that the relayed tx gas price is lower than the one declared by the user tx
+var ErrRelayedGasPriceTooLow = errors.New("relayed gas price is lower than user tx gas price")
+
+// ErrNotRelayedTransaction signals that the provided transaction is not a relayed transaction
+var ErrNotRelayedTransaction = errors.New("not a relayed transaction")
+
+// ErrRelayerInsufficientFunds signals that the relayer can not cover the value forwarded to the user tx and the relayed tx fee.
+// Such a relayed tx is rejected without any state change and without a receipt, so it is never included in a block: it
+// stays in pool until evicted, and a received block containing it is rejected as a whole
+var ErrRelayerInsufficientFunds = errors.New("relayer has insufficient funds")
+
+// ErrProcessClosing signals that the processing was interrupted because the component is closing
+var ErrProcessClosing = errors.New("process closing")
+
+// ErrInvalidTxsPoolsCleanInterval signals that the provided txs pools clean interval is not positive
+var ErrInvalidTxsPoolsCleanInterval = errors.New("invalid txs pools clean interval")
+
+// ErrPartialRestore signals that some of the meta blocks attested by a restored block could not be put back into pool
+var ErrPartialRestore = errors.New("partial restore")
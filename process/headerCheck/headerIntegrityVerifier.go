@@ -19,10 +19,11 @@ const wildcard = "*"
 const keySize = 4
 
 type headerIntegrityVerifier struct {
-	referenceChainID []byte
-	versions         []config.VersionByEpochs
-	defaultVersion   string
-	versionCache     storage.Cacher
+	referenceChainID           []byte
+	versions                   []config.VersionByEpochs
+	defaultVersion             string
+	versionCache               storage.Cacher
+	bodyCompositionEnableEpoch uint32
 }
 
 // NewHeaderIntegrityVerifier returns a new instance of a structure capable of verifying the integrity of a provided header
@@ -31,6 +32,7 @@ func NewHeaderIntegrityVerifier(
 	versionsByEpochs []config.VersionByEpochs,
 	defaultVersion string,
 	versionCache storage.Cacher,
+	bodyCompositionEnableEpoch uint32,
 ) (*headerIntegrityVerifier, error) {
 
 	if len(referenceChainID) == 0 {
@@ -41,9 +43,10 @@ func NewHeaderIntegrityVerifier(
 	}
 
 	hdrIntVer := &headerIntegrityVerifier{
-		referenceChainID: referenceChainID,
-		defaultVersion:   defaultVersion,
-		versionCache:     versionCache,
+		referenceChainID:           referenceChainID,
+		defaultVersion:             defaultVersion,
+		versionCache:               versionCache,
+		bodyCompositionEnableEpoch: bodyCompositionEnableEpoch,
 	}
 	var err error
 	hdrIntVer.versions, err = hdrIntVer.prepareVersions(versionsByEpochs)
@@ -145,7 +148,7 @@ func (hdrIntVer *headerIntegrityVerifier) setInCache(epoch uint32, version strin
 
 // Verify will check the header's fields such as the chain ID or the software version
 func (hdrIntVer *headerIntegrityVerifier) Verify(hdr data.HeaderHandler) error {
-	if len(hdr.GetReserved()) > 0 {
+	if len(hdr.GetReserved()) > 0 && !hdrIntVer.isBodyCompositionMarker(hdr) {
 		return process.ErrReservedFieldNotSupportedYet
 	}
 
@@ -157,6 +160,17 @@ func (hdrIntVer *headerIntegrityVerifier) Verify(hdr data.HeaderHandler) error {
 	return hdrIntVer.checkChainID(hdr)
 }
 
+// isBodyCompositionMarker returns true if the reserved field of the given header holds the marker used by the shard
+// headers whose body was created with the from me first composition. The marker is accepted only starting with the
+// body composition enable epoch
+func (hdrIntVer *headerIntegrityVerifier) isBodyCompositionMarker(hdr data.HeaderHandler) bool {
+	if hdr.GetShardID() == core.MetachainShardId || !bytes.Equal(hdr.GetReserved(), []byte(process.FromMeFirst)) {
+		return false
+	}
+
+	return hdr.GetEpoch() >= hdrIntVer.bodyCompositionEnableEpoch
+}
+
 func (hdrIntVer *headerIntegrityVerifier) checkVersionLength(version []byte) error {
 	if len(version) == 0 || len(version) > core.MaxSoftwareVersionLengthInBytes {
 		return fmt.Errorf("%w when checking lenghts", ErrInvalidSoftwareVersion)
@@ -43,6 +43,7 @@ func TestNewHeaderIntegrityVerifier_InvalidReferenceChainIDShouldErr(t *testing.
 		make([]config.VersionByEpochs, 0),
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.Equal(t, ErrInvalidReferenceChainID, err)
@@ -65,6 +66,7 @@ func TestNewHeaderIntegrityVerifierr_InvalidVersionElementOnEpochValuesEqualShou
 		},
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.True(t, errors.Is(err, ErrInvalidVersionOnEpochValues))
@@ -83,6 +85,7 @@ func TestNewHeaderIntegrityVerifier_InvalidVersionElementOnStringTooLongShouldEr
 		},
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.True(t, errors.Is(err, ErrInvalidVersionStringTooLong))
@@ -96,6 +99,7 @@ func TestNewHeaderIntegrityVerifierr_InvalidDefaultVersionShouldErr(t *testing.T
 		versionsCorrectlyConstructed,
 		defaultVersion,
 		nil,
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.True(t, errors.Is(err, ErrNilCacher))
@@ -109,6 +113,7 @@ func TestNewHeaderIntegrityVerifier_NilCacherShouldErr(t *testing.T) {
 		versionsCorrectlyConstructed,
 		"",
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.True(t, errors.Is(err, ErrInvalidSoftwareVersion))
@@ -122,6 +127,7 @@ func TestNewHeaderIntegrityVerifier_EmptyListShouldErr(t *testing.T) {
 		make([]config.VersionByEpochs, 0),
 		"",
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.True(t, errors.Is(err, ErrEmptyVersionsByEpochsList))
@@ -140,6 +146,7 @@ func TestNewHeaderIntegrityVerifier_ZerothElementIsNotOnEpochZeroShouldErr(t *te
 		},
 		"",
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.True(t, check.IfNil(hdrIntVer))
 	require.True(t, errors.Is(err, ErrInvalidVersionOnEpochValues))
@@ -153,6 +160,7 @@ func TestNewHeaderIntegrityVerifier_ShouldWork(t *testing.T) {
 		versionsCorrectlyConstructed,
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	require.False(t, check.IfNil(hdrIntVer))
 	require.NoError(t, err)
@@ -169,11 +177,74 @@ func TestHeaderIntegrityVerifier_PopulatedReservedShouldErr(t *testing.T) {
 		make([]config.VersionByEpochs, 0),
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	err := hdrIntVer.Verify(hdr)
 	require.Equal(t, process.ErrReservedFieldNotSupportedYet, err)
 }
 
+func TestHeaderIntegrityVerifier_ShardHeaderWithBodyCompositionMarkerShouldNotErr(t *testing.T) {
+	t.Parallel()
+
+	expectedChainID := []byte("#chainID")
+	hdrIntVer, _ := NewHeaderIntegrityVerifier(
+		expectedChainID,
+		versionsCorrectlyConstructed,
+		"software",
+		&testscommon.CacherStub{},
+		0,
+	)
+	hdr := &block.Header{
+		SoftwareVersion: []byte("software"),
+		ChainID:         expectedChainID,
+		Reserved:        []byte(process.FromMeFirst),
+	}
+	err := hdrIntVer.Verify(hdr)
+	require.NoError(t, err)
+}
+
+func TestHeaderIntegrityVerifier_MetaBlockWithBodyCompositionMarkerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	hdr := &block.MetaBlock{
+		Reserved: []byte(process.FromMeFirst),
+	}
+	hdrIntVer, _ := NewHeaderIntegrityVerifier(
+		[]byte("chainID"),
+		versionsCorrectlyConstructed,
+		defaultVersion,
+		&testscommon.CacherStub{},
+		0,
+	)
+	err := hdrIntVer.Verify(hdr)
+	require.Equal(t, process.ErrReservedFieldNotSupportedYet, err)
+}
+
+func TestHeaderIntegrityVerifier_ShardHeaderWithBodyCompositionMarkerBeforeEnableEpochShouldErr(t *testing.T) {
+	t.Parallel()
+
+	expectedChainID := []byte("#chainID")
+	hdrIntVer, _ := NewHeaderIntegrityVerifier(
+		expectedChainID,
+		versionsCorrectlyConstructed,
+		"software",
+		&testscommon.CacherStub{},
+		6,
+	)
+	hdr := &block.Header{
+		Epoch:           5,
+		SoftwareVersion: []byte("v2"),
+		ChainID:         expectedChainID,
+		Reserved:        []byte(process.FromMeFirst),
+	}
+	err := hdrIntVer.Verify(hdr)
+	require.Equal(t, process.ErrReservedFieldNotSupportedYet, err)
+
+	hdr.Epoch = 6
+	err = hdrIntVer.Verify(hdr)
+	require.NoError(t, err)
+}
+
 func TestHeaderIntegrityVerifier_VerifySoftwareVersionEmptyVersionInHeaderShouldErr(t *testing.T) {
 	t.Parallel()
 
@@ -182,6 +253,7 @@ func TestHeaderIntegrityVerifier_VerifySoftwareVersionEmptyVersionInHeaderShould
 		make([]config.VersionByEpochs, 0),
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	err := hdrIntVer.Verify(&block.MetaBlock{})
 	require.True(t, errors.Is(err, ErrInvalidSoftwareVersion))
@@ -204,6 +276,7 @@ func TestHeaderIntegrityVerifierr_VerifySoftwareVersionWrongVersionShouldErr(t *
 		},
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	err := hdrIntVer.Verify(
 		&block.MetaBlock{
@@ -232,6 +305,7 @@ func TestHeaderIntegrityVerifier_VerifySoftwareVersionWildcardShouldWork(t *test
 		},
 		defaultVersion,
 		&testscommon.CacherStub{},
+		0,
 	)
 	err := hdrIntVer.Verify(
 		&block.MetaBlock{
@@ -252,6 +326,7 @@ func TestHeaderIntegrityVerifier_VerifyHdrChainIDAndReferenceChainIDMismatchShou
 		versionsCorrectlyConstructed,
 		"software",
 		&testscommon.CacherStub{},
+		0,
 	)
 	mb := &block.MetaBlock{
 		SoftwareVersion: []byte("software"),
@@ -270,6 +345,7 @@ func TestHeaderIntegrityVerifier_VerifyShouldWork(t *testing.T) {
 		versionsCorrectlyConstructed,
 		"software",
 		&testscommon.CacherStub{},
+		0,
 	)
 	mb := &block.MetaBlock{
 		SoftwareVersion: []byte("software"),
@@ -288,6 +364,7 @@ func TestHeaderIntegrityVerifier_VerifyNotWildcardShouldWork(t *testing.T) {
 		versionsCorrectlyConstructed,
 		"software",
 		&testscommon.CacherStub{},
+		0,
 	)
 	mb := &block.MetaBlock{
 		SoftwareVersion: []byte("v1"),
@@ -336,6 +413,7 @@ func TestHeaderIntegrityVerifier_GetVersionShouldWork(t *testing.T) {
 				return false
 			},
 		},
+		0,
 	)
 
 	assert.Equal(t, defaultVersion, hdrIntVer.GetVersion(0))
@@ -363,6 +441,7 @@ func TestHeaderIntegrityVerifier_ExistsInInternalCacheShouldReturn(t *testing.T)
 				return cachedVersion, true
 			},
 		},
+		0,
 	)
 
 	assert.Equal(t, cachedVersion, hdrIntVer.GetVersion(0))
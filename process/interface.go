@@ -131,6 +131,7 @@ type TransactionCoordinator interface {
 	RemoveTxsFromPool(body *block.Body) error
 
 	ProcessBlockTransaction(body *block.Body, haveTime func() time.Duration) error
+	ProcessBlockTransactionFromMeFirst(body *block.Body, haveTime func() time.Duration) error
 
 	CreateBlockStarted()
 	CreateMbsAndProcessCrossShardTransactionsDstMe(
@@ -139,7 +140,18 @@ type TransactionCoordinator interface {
 
 		haveTime func() bool,
 	) (block.MiniBlockSlice, uint32, bool, error)
-	CreateMbsAndProcessTransactionsFromMe(haveTime func() bool) block.MiniBlockSlice
+	CreateMbsAndProcessTransactionsFromMe(
+		haveTime func() bool,
+		minGasPriceForInclusion uint64,
+		maxTxDataSize uint64,
+		maxTxsPerDestShard uint32,
+	) block.MiniBlockSlice
+	CreateMbsAndProcessTransactionsFromMeWithoutPostProcess(
+		haveTime func() bool,
+		minGasPriceForInclusion uint64,
+		maxTxDataSize uint64,
+		maxTxsPerDestShard uint32,
+	) block.MiniBlockSlice
 	CreatePostProcessMiniBlocks() block.MiniBlockSlice
 	CreateMarshalizedData(body *block.Body) map[string][][]byte
 	GetAllCurrentUsedTxs(blockType block.Type) map[string]data.TransactionHandler
@@ -148,9 +160,127 @@ type TransactionCoordinator interface {
 	VerifyCreatedBlockTransactions(hdr data.HeaderHandler, body *block.Body) error
 	CreateMarshalizedReceipts() ([]byte, error)
 	VerifyCreatedMiniBlocks(hdr data.HeaderHandler, body *block.Body) error
+	GetTransactionsExecutionResults() []*TransactionExecutionResult
+	GetTotalGasRefunded() uint64
+	IsInterfaceNil() bool
+}
+
+// MetaFinalityVerifier defines the rule used by a shard to decide if a meta header is final
+type MetaFinalityVerifier interface {
+	IsMetaHeaderFinal(metaHdr data.HeaderHandler, getMetaHeadersWithNonce func(nonce uint64) []data.HeaderHandler) bool
+	IsInterfaceNil() bool
+}
+
+// BlockCreationPolicyHandler defines the policies applied only by the proposer, when a shard block is created
+type BlockCreationPolicyHandler interface {
+	MinGasPriceForInclusion() uint64
+	MaxTxDataSize() uint64
+	MaxTxsPerDestShard() uint32
+	MaxBlockBodyBytes() uint32
+	BodyComposition() BodyComposition
+	ProduceEmptyBlocks() bool
+	IsMaxBlockBodyBytesReached(size int) bool
+	IsInterfaceNil() bool
+}
+
+// TransactionExecutionResult holds the outcome of a transaction executed while processing a block
+type TransactionExecutionResult struct {
+	TxHash     []byte
+	ReturnCode vmcommon.ReturnCode
+	GasUsed    uint64
+}
+
+// MetaAttestation holds the hash and the nonce of a metablock which notarized a shard header
+type MetaAttestation struct {
+	MetaHash  []byte
+	MetaNonce uint64
+}
+
+// AttestationCoverageHandler defines a component able to report the metablocks which notarized a shard header
+type AttestationCoverageHandler interface {
+	AttestationCoverage(headerHash []byte) ([]MetaAttestation, error)
+	IsInterfaceNil() bool
+}
+
+// FinalityProofData holds the proof that a shard header is final: the metablock which notarized it and the metablocks
+// correctly constructed on top of it, which establish its finality
+type FinalityProofData struct {
+	ShardHeaderHash    []byte
+	AttestingMetaBlock *block.MetaBlock
+	AttestingMetaHash  []byte
+	FinalityMetaBlocks []*block.MetaBlock
+	FinalityMetaHashes [][]byte
+}
+
+// FinalityProofHandler defines a component able to build the finality proof of a committed shard header
+type FinalityProofHandler interface {
+	FinalityProof(headerHash []byte) (*FinalityProofData, error)
 	IsInterfaceNil() bool
 }
 
+// CrossShardSettlementHandler defines a component able to report, for a committed shard header, the metablock which
+// confirmed each of the cross shard miniblocks processed in it
+type CrossShardSettlementHandler interface {
+	CrossShardSettlementMap(headerHash []byte) (map[string]string, error)
+	IsInterfaceNil() bool
+}
+
+// RelayedSimResult holds the outcome of a simulated relayed transaction: the fees charged upfront to the relayer for
+// the relayed transaction and for the inner transaction, the return code and the processing error, if any
+type RelayedSimResult struct {
+	ReturnCode      vmcommon.ReturnCode
+	ProcessingError error
+	RelayerFee      *big.Int
+	InnerTxFee      *big.Int
+}
+
+// RelayedTxSimulator defines a component able to simulate the processing of a relayed transaction without
+// keeping any of its effects
+type RelayedTxSimulator interface {
+	SimulateRelayedTransaction(rtx *transaction.Transaction) (RelayedSimResult, error)
+	IsInterfaceNil() bool
+}
+
+// ShardProcessorReader defines the read only view of a shard processor, which exposes only its query methods
+type ShardProcessorReader interface {
+	LastCrossNotarizedMetaBlock() (data.HeaderHandler, []byte, error)
+	LastCommittedHeader() (data.HeaderHandler, []byte)
+	MissingFinalHeadersCount() uint32
+	BlockProductionSuccessRate() float64
+	LastThrottleSuccess() (round uint64, maxItems uint32)
+	NumDroppedCommittedBlocks() uint64
+	NumDroppedPausedMetaBlocks() uint64
+	NumTxExportErrors() uint64
+	NumRemovedEmptyProcessedMiniBlocks() uint64
+	LastAttestationDecisions() map[string]string
+	PendingCrossShardMiniBlocks() map[uint32][][]byte
+	GetProcessedMiniBlocksInfo() map[string][]string
+	TouchedAccounts(body *block.Body) ([][]byte, error)
+	AttestationCoverage(headerHash []byte) ([]MetaAttestation, error)
+	MetaBlockPoolAge(hash []byte, currentRound uint64) (uint64, error)
+	GetBlockBackgroundErrors(headerHash []byte) []error
+	GetStoredHeaderBytesByNonce(nonce uint64) ([]byte, error)
+	ComputeHeaderHash(header data.HeaderHandler) ([]byte, error)
+	NextExpectedRound(currentTime time.Time) uint64
+	IsInterfaceNil() bool
+}
+
+// TxsPoolsCleanerSetter defines a component which uses a txs pools cleaner that can be replaced at runtime
+type TxsPoolsCleanerSetter interface {
+	SetTxsPoolsCleaner(cleaner PoolsCleaner) error
+	IsInterfaceNil() bool
+}
+
+// IndexedTxTransformer defines a function able to normalize or enrich the transactions before they are indexed.
+// Only the resulted entries which still implement data.TransactionHandler are handed to the indexer. A transaction
+// whose resulted entry is missing or does not implement data.TransactionHandler is indexed as it was
+type IndexedTxTransformer func(txPool map[string]data.TransactionHandler) map[string]interface{}
+
+// TransactionsExecutionResultsHandler defines a component able to provide the execution results of the processed transactions
+type TransactionsExecutionResultsHandler interface {
+	GetTransactionsExecutionResults() []*TransactionExecutionResult
+}
+
 // SmartContractProcessor is the main interface for the smart contract caller engine
 type SmartContractProcessor interface {
 	ExecuteSmartContractTransaction(tx data.TransactionHandler, acntSrc, acntDst state.UserAccountHandler) (vmcommon.ReturnCode, error)
@@ -210,7 +340,12 @@ type PreProcessor interface {
 
 	RequestTransactionsForMiniBlock(miniBlock *block.MiniBlock) int
 	ProcessMiniBlock(miniBlock *block.MiniBlock, haveTime func() bool, getNumOfCrossInterMbsAndTxs func() (int, int)) ([][]byte, int, error)
-	CreateAndProcessMiniBlocks(haveTime func() bool) (block.MiniBlockSlice, error)
+	CreateAndProcessMiniBlocks(
+		haveTime func() bool,
+		minGasPriceForInclusion uint64,
+		maxTxDataSize uint64,
+		maxTxsPerDestShard uint32,
+	) (block.MiniBlockSlice, error)
 
 	GetAllCurrentUsedTxs() map[string]data.TransactionHandler
 	IsInterfaceNil() bool
@@ -0,0 +1,14 @@
+package mock
+
+// AppStatusBatchHandlerStub is a stub implementation of an AppStatusHandler able to set several values at once
+type AppStatusBatchHandlerStub struct {
+	AppStatusHandlerStub
+	SetMultipleHandler func(values map[string]interface{})
+}
+
+// SetMultiple will call the handler of the stub for setting several values at once
+func (asbhs *AppStatusBatchHandlerStub) SetMultiple(values map[string]interface{}) {
+	if asbhs.SetMultipleHandler != nil {
+		asbhs.SetMultipleHandler(values)
+	}
+}
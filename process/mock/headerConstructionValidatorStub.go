@@ -0,0 +1,21 @@
+package mock
+
+import "github.com/ElrondNetwork/elrond-go/data"
+
+// HeaderConstructionValidatorStub -
+type HeaderConstructionValidatorStub struct {
+	IsHeaderConstructionValidCalled func(currHdr, prevHdr data.HeaderHandler) error
+}
+
+// IsHeaderConstructionValid -
+func (hcvs *HeaderConstructionValidatorStub) IsHeaderConstructionValid(currHdr, prevHdr data.HeaderHandler) error {
+	if hcvs.IsHeaderConstructionValidCalled != nil {
+		return hcvs.IsHeaderConstructionValidCalled(currHdr, prevHdr)
+	}
+	return nil
+}
+
+// IsInterfaceNil -
+func (hcvs *HeaderConstructionValidatorStub) IsInterfaceNil() bool {
+	return hcvs == nil
+}
@@ -0,0 +1,25 @@
+package mock
+
+import "github.com/ElrondNetwork/elrond-go/data"
+
+// MetaFinalityVerifierStub -
+type MetaFinalityVerifierStub struct {
+	IsMetaHeaderFinalCalled func(metaHdr data.HeaderHandler, getMetaHeadersWithNonce func(nonce uint64) []data.HeaderHandler) bool
+}
+
+// IsMetaHeaderFinal -
+func (mfvs *MetaFinalityVerifierStub) IsMetaHeaderFinal(
+	metaHdr data.HeaderHandler,
+	getMetaHeadersWithNonce func(nonce uint64) []data.HeaderHandler,
+) bool {
+	if mfvs.IsMetaHeaderFinalCalled != nil {
+		return mfvs.IsMetaHeaderFinalCalled(metaHdr, getMetaHeadersWithNonce)
+	}
+
+	return true
+}
+
+// IsInterfaceNil -
+func (mfvs *MetaFinalityVerifierStub) IsInterfaceNil() bool {
+	return mfvs == nil
+}
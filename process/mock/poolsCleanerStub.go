@@ -0,0 +1,28 @@
+package mock
+
+// PoolsCleanerStub -
+type PoolsCleanerStub struct {
+	CloseCalled         func() error
+	StartCleaningCalled func()
+}
+
+// Close -
+func (pcs *PoolsCleanerStub) Close() error {
+	if pcs.CloseCalled != nil {
+		return pcs.CloseCalled()
+	}
+
+	return nil
+}
+
+// StartCleaning -
+func (pcs *PoolsCleanerStub) StartCleaning() {
+	if pcs.StartCleaningCalled != nil {
+		pcs.StartCleaningCalled()
+	}
+}
+
+// IsInterfaceNil -
+func (pcs *PoolsCleanerStub) IsInterfaceNil() bool {
+	return pcs == nil
+}
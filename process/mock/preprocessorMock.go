@@ -21,7 +21,7 @@ type PreProcessorMock struct {
 	CreateMarshalizedDataCalled           func(txHashes [][]byte) ([][]byte, error)
 	RequestTransactionsForMiniBlockCalled func(miniBlock *block.MiniBlock) int
 	ProcessMiniBlockCalled                func(miniBlock *block.MiniBlock, haveTime func() bool, getNumOfCrossInterMbsAndTxs func() (int, int)) ([][]byte, int, error)
-	CreateAndProcessMiniBlocksCalled      func(haveTime func() bool) (block.MiniBlockSlice, error)
+	CreateAndProcessMiniBlocksCalled      func(haveTime func() bool, minGasPriceForInclusion uint64, maxTxDataSize uint64, maxTxsPerDestShard uint32) (block.MiniBlockSlice, error)
 	GetAllCurrentUsedTxsCalled            func() map[string]data.TransactionHandler
 }
 
@@ -115,11 +115,16 @@ func (ppm *PreProcessorMock) ProcessMiniBlock(miniBlock *block.MiniBlock, haveTi
 
 // CreateAndProcessMiniBlocks creates miniblocks from storage and processes the reward transactions added into the miniblocks
 // as long as it has time
-func (ppm *PreProcessorMock) CreateAndProcessMiniBlocks(haveTime func() bool) (block.MiniBlockSlice, error) {
+func (ppm *PreProcessorMock) CreateAndProcessMiniBlocks(
+	haveTime func() bool,
+	minGasPriceForInclusion uint64,
+	maxTxDataSize uint64,
+	maxTxsPerDestShard uint32,
+) (block.MiniBlockSlice, error) {
 	if ppm.CreateAndProcessMiniBlocksCalled == nil {
 		return nil, nil
 	}
-	return ppm.CreateAndProcessMiniBlocksCalled(haveTime)
+	return ppm.CreateAndProcessMiniBlocksCalled(haveTime, minGasPriceForInclusion, maxTxDataSize, maxTxsPerDestShard)
 }
 
 // GetAllCurrentUsedTxs -
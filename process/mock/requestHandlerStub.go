@@ -17,6 +17,7 @@ type RequestHandlerStub struct {
 	RequestStartOfEpochMetaBlockCalled func(epoch uint32)
 	SetNumPeersToQueryCalled           func(key string, intra int, cross int) error
 	GetNumPeersToQueryCalled           func(key string) (int, int, error)
+	SetEpochCalled                     func(epoch uint32)
 }
 
 // SetNumPeersToQuery -
@@ -51,7 +52,10 @@ func (rhs *RequestHandlerStub) RequestStartOfEpochMetaBlock(epoch uint32) {
 }
 
 // SetEpoch -
-func (rhs *RequestHandlerStub) SetEpoch(_ uint32) {
+func (rhs *RequestHandlerStub) SetEpoch(epoch uint32) {
+	if rhs.SetEpochCalled != nil {
+		rhs.SetEpochCalled(epoch)
+	}
 }
 
 // RequestShardHeader -
@@ -0,0 +1,21 @@
+package mock
+
+// StorerWithPutBatchStub -
+type StorerWithPutBatchStub struct {
+	StorerStub
+	PutBatchCalled func(keys [][]byte, values [][]byte) error
+}
+
+// PutBatch -
+func (swpbs *StorerWithPutBatchStub) PutBatch(keys [][]byte, values [][]byte) error {
+	if swpbs.PutBatchCalled != nil {
+		return swpbs.PutBatchCalled(keys, values)
+	}
+
+	return nil
+}
+
+// IsInterfaceNil -
+func (swpbs *StorerWithPutBatchStub) IsInterfaceNil() bool {
+	return swpbs == nil
+}
@@ -0,0 +1,37 @@
+package process
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PartialRestoreError is the error returned when a block was restored into pools, but some of the meta blocks
+// attested by it could not be put back into the headers pool. It can be checked with errors.Is against
+// ErrPartialRestore, while the hashes which were not restored can be fetched through errors.As
+type PartialRestoreError struct {
+	FailedHashes [][]byte
+}
+
+// NewPartialRestoreError creates a PartialRestoreError holding the provided hashes which failed to be restored
+func NewPartialRestoreError(failedHashes [][]byte) *PartialRestoreError {
+	return &PartialRestoreError{
+		FailedHashes: failedHashes,
+	}
+}
+
+// Error returns the error message containing the hashes which failed to be restored
+func (pre *PartialRestoreError) Error() string {
+	hexHashes := make([]string, 0, len(pre.FailedHashes))
+	for _, hash := range pre.FailedHashes {
+		hexHashes = append(hexHashes, hex.EncodeToString(hash))
+	}
+
+	return fmt.Sprintf("%v, num failed hashes: %d, failed hashes: %s",
+		ErrPartialRestore, len(pre.FailedHashes), strings.Join(hexHashes, ", "))
+}
+
+// Unwrap returns ErrPartialRestore
+func (pre *PartialRestoreError) Unwrap() error {
+	return ErrPartialRestore
+}
@@ -0,0 +1,62 @@
+package process
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProcessBlockError is the error returned when a block fails to be processed, carrying the stage in which
+// the processing failed. The underlying error can be still checked with errors.Is
+type ProcessBlockError struct {
+	Stage ProcessBlockStage
+	Err   error
+}
+
+// NewProcessBlockError wraps the provided error into a ProcessBlockError for the given stage
+func NewProcessBlockError(stage ProcessBlockStage, err error) *ProcessBlockError {
+	return &ProcessBlockError{
+		Stage: stage,
+		Err:   err,
+	}
+}
+
+// Error returns the error message containing the failing stage
+func (pbe *ProcessBlockError) Error() string {
+	return fmt.Sprintf("process block failed at stage %s: %v", pbe.Stage.String(), pbe.Err)
+}
+
+// Unwrap returns the underlying error
+func (pbe *ProcessBlockError) Unwrap() error {
+	return pbe.Err
+}
+
+// BlockValidationResult holds the outcome of validating a block without applying it. PassedStages contains, in order,
+// the process block stages which were passed, while FailedStage is set only if HasFailedStage is true, as a block
+// could also fail outside of a stage, for example when the needed meta headers could not be received in time
+type BlockValidationResult struct {
+	Err            error
+	PassedStages   []ProcessBlockStage
+	HasFailedStage bool
+	FailedStage    ProcessBlockStage
+}
+
+// NewBlockValidationResult creates the validation result for the given process block error and passed stages
+func NewBlockValidationResult(err error, passedStages []ProcessBlockStage) *BlockValidationResult {
+	result := &BlockValidationResult{
+		Err:          err,
+		PassedStages: passedStages,
+	}
+
+	processBlockError := &ProcessBlockError{}
+	if errors.As(err, &processBlockError) {
+		result.HasFailedStage = true
+		result.FailedStage = processBlockError.Stage
+	}
+
+	return result
+}
+
+// IsValid returns true if the block passed all the verifications
+func (bvr *BlockValidationResult) IsValid() bool {
+	return bvr.Err == nil
+}
@@ -3,6 +3,7 @@ package sync
 import (
 	"bytes"
 	"context"
+	"errors"
 	"math"
 	"sync"
 	"time"
@@ -763,6 +764,12 @@ func (boot *baseBootstrap) rollBackOneBlock(
 	}
 
 	err = boot.blockProcessor.RestoreBlockIntoPools(currHeader, currBlockBody)
+	if errors.Is(err, process.ErrPartialRestore) {
+		// the block itself was restored, so the roll back continues, but the meta blocks which were not put back
+		// into pool should be known, as the node could fork on them
+		log.Warn("rollBackOneBlock.RestoreBlockIntoPools", "error", err.Error())
+		err = nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1441,6 +1441,101 @@ func TestBootstrap_RollBackIsEmptyCallRollBackOneBlockOkValsShouldWork(t *testin
 	assert.Equal(t, blkc.GetCurrentBlockHeaderHash(), prevHdrHash)
 }
 
+func TestBootstrap_RollBackWithPartialRestoreShouldContinueTheRollBack(t *testing.T) {
+	t.Parallel()
+
+	args := CreateShardBootstrapMockArguments()
+
+	remFlags := &removedFlags{}
+	currentHdrNonce := uint64(8)
+	currentHdrHash := []byte("current header hash")
+
+	prevHdrHash := []byte("prev header hash")
+	prevHdr := &block.Header{
+		Signature: []byte("sig of the prev header as to be unique in this context"),
+		RootHash:  []byte("prev header root hash"),
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	prevHdrBytes, _ := marshalizer.Marshal(prevHdr)
+
+	pools := createMockPools()
+	pools.HeadersCalled = func() dataRetriever.HeadersPool {
+		return &mock.HeadersCacherStub{
+			RemoveHeaderByHashCalled: func(key []byte) {
+				if bytes.Equal(key, currentHdrHash) {
+					remFlags.flagHdrRemovedFromHeaders = true
+				}
+			},
+		}
+	}
+	args.PoolsHolder = pools
+
+	blkc := &mock.BlockChainMock{}
+	hdr := &block.Header{
+		Nonce:    currentHdrNonce,
+		PrevHash: prevHdrHash,
+	}
+	blkc.GetCurrentBlockHeaderCalled = func() data.HeaderHandler {
+		return hdr
+	}
+	blkc.SetCurrentBlockHeaderCalled = func(handler data.HeaderHandler) error {
+		hdr = prevHdr
+		return nil
+	}
+	hdrHash := make([]byte, 0)
+	blkc.GetCurrentBlockHeaderHashCalled = func() []byte {
+		return hdrHash
+	}
+	blkc.SetCurrentBlockHeaderHashCalled = func(i []byte) {
+		hdrHash = i
+	}
+	args.ChainHandler = blkc
+	args.Store = &mock.ChainStorerMock{
+		GetStorerCalled: func(unitType dataRetriever.UnitType) storage.Storer {
+			return &mock.StorerStub{
+				GetCalled: func(key []byte) ([]byte, error) {
+					return prevHdrBytes, nil
+				},
+				RemoveCalled: func(key []byte) error {
+					remFlags.flagHdrRemovedFromStorage = true
+					return nil
+				},
+			}
+		},
+	}
+	restoreCalled := false
+	args.BlockProcessor = &mock.BlockProcessorMock{
+		RestoreBlockIntoPoolsCalled: func(header data.HeaderHandler, body data.BodyHandler) error {
+			restoreCalled = true
+			return process.NewPartialRestoreError([][]byte{[]byte("meta block hash")})
+		},
+	}
+	args.Hasher = &mock.HasherStub{
+		ComputeCalled: func(s string) []byte {
+			return currentHdrHash
+		},
+	}
+	args.Marshalizer = marshalizer
+	args.ForkDetector = createForkDetector(currentHdrNonce, remFlags)
+	args.Accounts = &mock.AccountsStub{
+		RecreateTrieCalled: func(rootHash []byte) error {
+			return nil
+		},
+	}
+
+	bs, _ := sync.NewShardBootstrap(args)
+	bs.SetForkNonce(currentHdrNonce)
+	err := bs.RollBack(true)
+
+	assert.Nil(t, err)
+	assert.True(t, restoreCalled)
+	assert.True(t, remFlags.flagHdrRemovedFromHeaders)
+	assert.True(t, remFlags.flagHdrRemovedFromStorage)
+	assert.True(t, remFlags.flagHdrRemovedFromForkDetector)
+	assert.Equal(t, prevHdr, blkc.GetCurrentBlockHeader())
+	assert.Equal(t, prevHdrHash, blkc.GetCurrentBlockHeaderHash())
+}
+
 func TestBootstrap_RollbackIsEmptyCallRollBackOneBlockToGenesisShouldWork(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,138 @@
+package transaction
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/state"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/process"
+)
+
+var _ process.RelayedTxSimulator = (*relayedTxSimulator)(nil)
+
+// ArgsRelayedTxSimulator holds the arguments needed to create a relayed transactions simulator. The transaction
+// processor should be dedicated to the simulation: it, and the smart contract processor it uses, should work on the
+// given scratch accounts, which are writable but never committed, and should add their results and fees to the given
+// throwaway intermediate processors and fee handler, as these are cleared after each simulation. The scratch accounts
+// are recreated, before each simulation, from the root hash of the current block header of the given blockchain
+type ArgsRelayedTxSimulator struct {
+	TxProcessor               process.TransactionProcessor
+	Accounts                  state.AccountsAdapter
+	BlockChain                data.ChainHandler
+	IntermediateProcContainer process.IntermediateProcessorContainer
+	TxFeeHandler              process.TransactionFeeHandler
+	TxTypeHandler             process.TxTypeHandler
+	EconomicsFee              process.FeeHandler
+}
+
+type relayedTxSimulator struct {
+	txProcessor         process.TransactionProcessor
+	accounts            state.AccountsAdapter
+	blockChain          data.ChainHandler
+	intermProcContainer process.IntermediateProcessorContainer
+	txFeeHandler        process.TransactionFeeHandler
+	txTypeHandler       process.TxTypeHandler
+	economicsFee        process.FeeHandler
+	mutSimulation       sync.Mutex
+}
+
+// NewRelayedTxSimulator creates a new relayed transactions simulator
+func NewRelayedTxSimulator(args ArgsRelayedTxSimulator) (*relayedTxSimulator, error) {
+	if check.IfNil(args.TxProcessor) {
+		return nil, process.ErrNilTxProcessor
+	}
+	if check.IfNil(args.Accounts) {
+		return nil, process.ErrNilAccountsAdapter
+	}
+	if check.IfNil(args.BlockChain) {
+		return nil, process.ErrNilBlockChain
+	}
+	if check.IfNil(args.IntermediateProcContainer) {
+		return nil, process.ErrNilIntermediateProcessorContainer
+	}
+	if check.IfNil(args.TxFeeHandler) {
+		return nil, process.ErrNilUnsignedTxHandler
+	}
+	if check.IfNil(args.TxTypeHandler) {
+		return nil, process.ErrNilTxTypeHandler
+	}
+	if check.IfNil(args.EconomicsFee) {
+		return nil, process.ErrNilEconomicsFeeHandler
+	}
+
+	return &relayedTxSimulator{
+		txProcessor:         args.TxProcessor,
+		accounts:            args.Accounts,
+		blockChain:          args.BlockChain,
+		intermProcContainer: args.IntermediateProcContainer,
+		txFeeHandler:        args.TxFeeHandler,
+		txTypeHandler:       args.TxTypeHandler,
+		economicsFee:        args.EconomicsFee,
+	}, nil
+}
+
+// SimulateRelayedTransaction recreates the scratch accounts from the last committed state, processes the given relayed
+// transaction on the simulation processor and then clears the intermediate processors and the fee handler, so nothing
+// is kept between simulations. The accounts used by the block processing are never touched
+func (rts *relayedTxSimulator) SimulateRelayedTransaction(rtx *transaction.Transaction) (process.RelayedSimResult, error) {
+	if rtx == nil {
+		return process.RelayedSimResult{}, process.ErrNilTransaction
+	}
+
+	txType, _ := rts.txTypeHandler.ComputeTransactionType(rtx)
+	if txType != process.RelayedTx {
+		return process.RelayedSimResult{}, process.ErrNotRelayedTransaction
+	}
+
+	relayerFee := rts.economicsFee.ComputeMoveBalanceFee(rtx)
+	result := process.RelayedSimResult{
+		RelayerFee: relayerFee,
+		InnerTxFee: big.NewInt(0).Sub(rts.economicsFee.ComputeTxFee(rtx), relayerFee),
+	}
+
+	rts.mutSimulation.Lock()
+	defer rts.mutSimulation.Unlock()
+
+	err := rts.recreateAccounts()
+	if err != nil {
+		return process.RelayedSimResult{}, err
+	}
+
+	result.ReturnCode, result.ProcessingError = rts.txProcessor.ProcessTransaction(rtx)
+	rts.clearResults()
+
+	return result, nil
+}
+
+func (rts *relayedTxSimulator) recreateAccounts() error {
+	header := rts.blockChain.GetCurrentBlockHeader()
+	if check.IfNil(header) {
+		header = rts.blockChain.GetGenesisHeader()
+	}
+	if check.IfNil(header) {
+		return process.ErrNilBlockHeader
+	}
+
+	return rts.accounts.RecreateTrie(header.GetRootHash())
+}
+
+func (rts *relayedTxSimulator) clearResults() {
+	for _, key := range rts.intermProcContainer.Keys() {
+		interProc, err := rts.intermProcContainer.Get(key)
+		if err != nil {
+			continue
+		}
+
+		interProc.CreateBlockStarted()
+	}
+
+	rts.txFeeHandler.CreateBlockStarted()
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (rts *relayedTxSimulator) IsInterfaceNil() bool {
+	return rts == nil
+}
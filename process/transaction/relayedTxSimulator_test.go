@@ -0,0 +1,273 @@
+package transaction_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ElrondNetwork/elrond-go/core/check"
+	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
+	"github.com/ElrondNetwork/elrond-go/data"
+	"github.com/ElrondNetwork/elrond-go/data/block"
+	"github.com/ElrondNetwork/elrond-go/data/transaction"
+	"github.com/ElrondNetwork/elrond-go/process"
+	"github.com/ElrondNetwork/elrond-go/process/mock"
+	txproc "github.com/ElrondNetwork/elrond-go/process/transaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func createArgsForRelayedTxSimulator() txproc.ArgsRelayedTxSimulator {
+	return txproc.ArgsRelayedTxSimulator{
+		TxProcessor:               &mock.TxProcessorMock{},
+		Accounts:                  &mock.AccountsStub{},
+		BlockChain:                &mock.BlockChainMock{},
+		IntermediateProcContainer: &mock.InterimProcessorContainerMock{},
+		TxFeeHandler:              &mock.FeeAccumulatorStub{},
+		TxTypeHandler: &mock.TxTypeHandlerMock{
+			ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (process.TransactionType, process.TransactionType) {
+				return process.RelayedTx, process.RelayedTx
+			},
+		},
+		EconomicsFee: feeHandlerMock(),
+	}
+}
+
+func TestNewRelayedTxSimulator_NilTxProcessorShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.TxProcessor = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilTxProcessor, err)
+}
+
+func TestNewRelayedTxSimulator_NilAccountsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.Accounts = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilAccountsAdapter, err)
+}
+
+func TestNewRelayedTxSimulator_NilBlockChainShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.BlockChain = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilBlockChain, err)
+}
+
+func TestNewRelayedTxSimulator_NilIntermediateProcContainerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.IntermediateProcContainer = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilIntermediateProcessorContainer, err)
+}
+
+func TestNewRelayedTxSimulator_NilTxFeeHandlerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.TxFeeHandler = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilUnsignedTxHandler, err)
+}
+
+func TestNewRelayedTxSimulator_NilTxTypeHandlerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.TxTypeHandler = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilTxTypeHandler, err)
+}
+
+func TestNewRelayedTxSimulator_NilEconomicsFeeShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForRelayedTxSimulator()
+	args.EconomicsFee = nil
+	rts, err := txproc.NewRelayedTxSimulator(args)
+
+	assert.True(t, check.IfNil(rts))
+	assert.Equal(t, process.ErrNilEconomicsFeeHandler, err)
+}
+
+func TestNewRelayedTxSimulator_ShouldWork(t *testing.T) {
+	t.Parallel()
+
+	rts, err := txproc.NewRelayedTxSimulator(createArgsForRelayedTxSimulator())
+
+	assert.False(t, check.IfNil(rts))
+	assert.Nil(t, err)
+}
+
+func TestRelayedTxSimulator_SimulateRelayedTransactionNilTransactionShouldErr(t *testing.T) {
+	t.Parallel()
+
+	rts, _ := txproc.NewRelayedTxSimulator(createArgsForRelayedTxSimulator())
+
+	_, err := rts.SimulateRelayedTransaction(nil)
+	assert.Equal(t, process.ErrNilTransaction, err)
+}
+
+func TestRelayedTxSimulator_SimulateRelayedTransactionNotRelayedShouldErr(t *testing.T) {
+	t.Parallel()
+
+	processCalled := false
+	args := createArgsForRelayedTxSimulator()
+	args.TxProcessor = &mock.TxProcessorMock{
+		ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			processCalled = true
+			return vmcommon.Ok, nil
+		},
+	}
+	args.TxTypeHandler = &mock.TxTypeHandlerMock{
+		ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (process.TransactionType, process.TransactionType) {
+			return process.MoveBalance, process.MoveBalance
+		},
+	}
+	rts, _ := txproc.NewRelayedTxSimulator(args)
+
+	_, err := rts.SimulateRelayedTransaction(&transaction.Transaction{})
+	assert.Equal(t, process.ErrNotRelayedTransaction, err)
+	assert.False(t, processCalled)
+}
+
+func TestRelayedTxSimulator_SimulateRelayedTransactionShouldRecreateTheAccountsAndClearTheResults(t *testing.T) {
+	t.Parallel()
+
+	processingErr := errors.New("processing error")
+	args := createArgsForRelayedTxSimulator()
+	economicsFee := feeHandlerMock()
+	economicsFee.ComputeTxFeeCalled = func(tx process.TransactionWithFeeHandler) *big.Int {
+		return big.NewInt(5)
+	}
+	economicsFee.ComputeMoveBalanceFeeCalled = func(tx process.TransactionWithFeeHandler) *big.Int {
+		return big.NewInt(2)
+	}
+	args.EconomicsFee = economicsFee
+
+	committedRootHash := []byte("committed root hash")
+	args.BlockChain = &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{RootHash: committedRootHash}
+		},
+	}
+	var recreatedRootHash []byte
+	args.Accounts = &mock.AccountsStub{
+		RecreateTrieCalled: func(rootHash []byte) error {
+			recreatedRootHash = rootHash
+			return nil
+		},
+	}
+	args.TxProcessor = &mock.TxProcessorMock{
+		ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			assert.Equal(t, committedRootHash, recreatedRootHash)
+			return vmcommon.UserError, processingErr
+		},
+	}
+	clearedResults := make([]block.Type, 0)
+	args.IntermediateProcContainer = &mock.InterimProcessorContainerMock{
+		KeysCalled: func() []block.Type {
+			return []block.Type{block.SmartContractResultBlock, block.ReceiptBlock, block.InvalidBlock}
+		},
+		GetCalled: func(key block.Type) (process.IntermediateTransactionHandler, error) {
+			return &mock.IntermediateTransactionHandlerMock{
+				CreateBlockStartedCalled: func() {
+					clearedResults = append(clearedResults, key)
+				},
+			}, nil
+		},
+	}
+	feesCleared := false
+	args.TxFeeHandler = &mock.FeeAccumulatorStub{
+		CreateBlockStartedCalled: func() {
+			feesCleared = true
+		},
+	}
+	rts, _ := txproc.NewRelayedTxSimulator(args)
+
+	result, err := rts.SimulateRelayedTransaction(&transaction.Transaction{})
+	assert.Nil(t, err)
+	assert.Equal(t, vmcommon.UserError, result.ReturnCode)
+	assert.Equal(t, processingErr, result.ProcessingError)
+	assert.Equal(t, big.NewInt(2), result.RelayerFee)
+	assert.Equal(t, big.NewInt(3), result.InnerTxFee)
+
+	assert.Equal(t, committedRootHash, recreatedRootHash)
+	assert.Equal(t, []block.Type{block.SmartContractResultBlock, block.ReceiptBlock, block.InvalidBlock}, clearedResults)
+	assert.True(t, feesCleared)
+}
+
+func TestRelayedTxSimulator_SimulateRelayedTransactionWithoutCurrentBlockShouldUseTheGenesisState(t *testing.T) {
+	t.Parallel()
+
+	genesisRootHash := []byte("genesis root hash")
+	args := createArgsForRelayedTxSimulator()
+	args.BlockChain = &mock.BlockChainMock{
+		GetCurrentBlockHeaderCalled: func() data.HeaderHandler {
+			return nil
+		},
+		GetGenesisHeaderCalled: func() data.HeaderHandler {
+			return &block.Header{RootHash: genesisRootHash}
+		},
+	}
+	var recreatedRootHash []byte
+	args.Accounts = &mock.AccountsStub{
+		RecreateTrieCalled: func(rootHash []byte) error {
+			recreatedRootHash = rootHash
+			return nil
+		},
+	}
+	args.TxProcessor = &mock.TxProcessorMock{
+		ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			return vmcommon.Ok, nil
+		},
+	}
+	rts, _ := txproc.NewRelayedTxSimulator(args)
+
+	_, err := rts.SimulateRelayedTransaction(&transaction.Transaction{})
+	assert.Nil(t, err)
+	assert.Equal(t, genesisRootHash, recreatedRootHash)
+}
+
+func TestRelayedTxSimulator_SimulateRelayedTransactionRecreateErrorShouldErr(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("recreate error")
+	processCalled := false
+	args := createArgsForRelayedTxSimulator()
+	args.Accounts = &mock.AccountsStub{
+		RecreateTrieCalled: func(rootHash []byte) error {
+			return expectedErr
+		},
+	}
+	args.TxProcessor = &mock.TxProcessorMock{
+		ProcessTransactionCalled: func(transaction *transaction.Transaction) (vmcommon.ReturnCode, error) {
+			processCalled = true
+			return vmcommon.Ok, nil
+		},
+	}
+	rts, _ := txproc.NewRelayedTxSimulator(args)
+
+	_, err := rts.SimulateRelayedTransaction(&transaction.Transaction{})
+	assert.Equal(t, expectedErr, err)
+	assert.False(t, processCalled)
+}
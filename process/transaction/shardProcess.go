@@ -11,6 +11,7 @@ import (
 	"github.com/ElrondNetwork/elrond-go/core/atomic"
 	"github.com/ElrondNetwork/elrond-go/core/check"
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
+	"github.com/ElrondNetwork/elrond-go/crypto"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/receipt"
 	"github.com/ElrondNetwork/elrond-go/data/smartContractResult"
@@ -32,40 +33,57 @@ const RefundGasMessage = "refundedGas"
 // txProcessor implements TransactionProcessor interface and can modify account states according to a transaction
 type txProcessor struct {
 	*baseTxProcessor
-	txFeeHandler                   process.TransactionFeeHandler
-	txTypeHandler                  process.TxTypeHandler
-	receiptForwarder               process.IntermediateTransactionHandler
-	badTxForwarder                 process.IntermediateTransactionHandler
-	argsParser                     process.ArgumentsParser
-	scrForwarder                   process.IntermediateTransactionHandler
-	signMarshalizer                marshal.Marshalizer
-	flagRelayedTx                  atomic.Flag
-	flagMetaProtection             atomic.Flag
-	relayedTxEnableEpoch           uint32
-	penalizedTooMuchGasEnableEpoch uint32
-	metaProtectionEnableEpoch      uint32
+	txFeeHandler                     process.TransactionFeeHandler
+	txTypeHandler                    process.TxTypeHandler
+	receiptForwarder                 process.IntermediateTransactionHandler
+	badTxForwarder                   process.IntermediateTransactionHandler
+	argsParser                       process.ArgumentsParser
+	scrForwarder                     process.IntermediateTransactionHandler
+	signMarshalizer                  marshal.Marshalizer
+	keyGen                           crypto.KeyGenerator
+	singleSigner                     crypto.SingleSigner
+	txSignHasher                     hashing.Hasher
+	txVersionChecker                 process.TxVersionCheckerHandler
+	flagRelayedTx                    atomic.Flag
+	flagMetaProtection               atomic.Flag
+	flagInnerTxSignatureCheck        atomic.Flag
+	flagRelayedGasPriceCheck         atomic.Flag
+	flagRelayerFundsCheck            atomic.Flag
+	relayedTxEnableEpoch             uint32
+	penalizedTooMuchGasEnableEpoch   uint32
+	metaProtectionEnableEpoch        uint32
+	innerTxSignatureCheckEnableEpoch uint32
+	relayedGasPriceCheckEnableEpoch  uint32
+	relayerFundsCheckEnableEpoch     uint32
 }
 
 // ArgsNewTxProcessor defines the arguments needed for new tx processor
 type ArgsNewTxProcessor struct {
-	Accounts                       state.AccountsAdapter
-	Hasher                         hashing.Hasher
-	PubkeyConv                     core.PubkeyConverter
-	Marshalizer                    marshal.Marshalizer
-	SignMarshalizer                marshal.Marshalizer
-	ShardCoordinator               sharding.Coordinator
-	ScProcessor                    process.SmartContractProcessor
-	TxFeeHandler                   process.TransactionFeeHandler
-	TxTypeHandler                  process.TxTypeHandler
-	EconomicsFee                   process.FeeHandler
-	ReceiptForwarder               process.IntermediateTransactionHandler
-	BadTxForwarder                 process.IntermediateTransactionHandler
-	ArgsParser                     process.ArgumentsParser
-	ScrForwarder                   process.IntermediateTransactionHandler
-	RelayedTxEnableEpoch           uint32
-	PenalizedTooMuchGasEnableEpoch uint32
-	MetaProtectionEnableEpoch      uint32
-	EpochNotifier                  process.EpochNotifier
+	Accounts                         state.AccountsAdapter
+	Hasher                           hashing.Hasher
+	PubkeyConv                       core.PubkeyConverter
+	Marshalizer                      marshal.Marshalizer
+	SignMarshalizer                  marshal.Marshalizer
+	ShardCoordinator                 sharding.Coordinator
+	ScProcessor                      process.SmartContractProcessor
+	TxFeeHandler                     process.TransactionFeeHandler
+	TxTypeHandler                    process.TxTypeHandler
+	EconomicsFee                     process.FeeHandler
+	ReceiptForwarder                 process.IntermediateTransactionHandler
+	BadTxForwarder                   process.IntermediateTransactionHandler
+	ArgsParser                       process.ArgumentsParser
+	ScrForwarder                     process.IntermediateTransactionHandler
+	KeyGen                           crypto.KeyGenerator
+	SingleSigner                     crypto.SingleSigner
+	TxSignHasher                     hashing.Hasher
+	TxVersionChecker                 process.TxVersionCheckerHandler
+	RelayedTxEnableEpoch             uint32
+	PenalizedTooMuchGasEnableEpoch   uint32
+	MetaProtectionEnableEpoch        uint32
+	InnerTxSignatureCheckEnableEpoch uint32
+	RelayedGasPriceCheckEnableEpoch  uint32
+	RelayerFundsCheckEnableEpoch     uint32
+	EpochNotifier                    process.EpochNotifier
 }
 
 // NewTxProcessor creates a new txProcessor engine
@@ -115,6 +133,18 @@ func NewTxProcessor(args ArgsNewTxProcessor) (*txProcessor, error) {
 	if check.IfNil(args.EpochNotifier) {
 		return nil, process.ErrNilEpochNotifier
 	}
+	if check.IfNil(args.KeyGen) {
+		return nil, process.ErrNilKeyGen
+	}
+	if check.IfNil(args.SingleSigner) {
+		return nil, process.ErrNilSingleSigner
+	}
+	if check.IfNil(args.TxSignHasher) {
+		return nil, process.ErrNilHasher
+	}
+	if check.IfNil(args.TxVersionChecker) {
+		return nil, process.ErrNilTransactionVersionChecker
+	}
 
 	baseTxProcess := &baseTxProcessor{
 		accounts:         args.Accounts,
@@ -127,17 +157,24 @@ func NewTxProcessor(args ArgsNewTxProcessor) (*txProcessor, error) {
 	}
 
 	txProc := &txProcessor{
-		baseTxProcessor:                baseTxProcess,
-		txFeeHandler:                   args.TxFeeHandler,
-		txTypeHandler:                  args.TxTypeHandler,
-		receiptForwarder:               args.ReceiptForwarder,
-		badTxForwarder:                 args.BadTxForwarder,
-		argsParser:                     args.ArgsParser,
-		scrForwarder:                   args.ScrForwarder,
-		signMarshalizer:                args.SignMarshalizer,
-		relayedTxEnableEpoch:           args.RelayedTxEnableEpoch,
-		penalizedTooMuchGasEnableEpoch: args.PenalizedTooMuchGasEnableEpoch,
-		metaProtectionEnableEpoch:      args.MetaProtectionEnableEpoch,
+		baseTxProcessor:                  baseTxProcess,
+		txFeeHandler:                     args.TxFeeHandler,
+		txTypeHandler:                    args.TxTypeHandler,
+		receiptForwarder:                 args.ReceiptForwarder,
+		badTxForwarder:                   args.BadTxForwarder,
+		argsParser:                       args.ArgsParser,
+		scrForwarder:                     args.ScrForwarder,
+		signMarshalizer:                  args.SignMarshalizer,
+		keyGen:                           args.KeyGen,
+		singleSigner:                     args.SingleSigner,
+		txSignHasher:                     args.TxSignHasher,
+		txVersionChecker:                 args.TxVersionChecker,
+		relayedTxEnableEpoch:             args.RelayedTxEnableEpoch,
+		penalizedTooMuchGasEnableEpoch:   args.PenalizedTooMuchGasEnableEpoch,
+		metaProtectionEnableEpoch:        args.MetaProtectionEnableEpoch,
+		innerTxSignatureCheckEnableEpoch: args.InnerTxSignatureCheckEnableEpoch,
+		relayedGasPriceCheckEnableEpoch:  args.RelayedGasPriceCheckEnableEpoch,
+		relayerFundsCheckEnableEpoch:     args.RelayerFundsCheckEnableEpoch,
 	}
 
 	args.EpochNotifier.RegisterNotifyHandler(txProc)
@@ -164,6 +201,13 @@ func (txProc *txProcessor) ProcessTransaction(tx *transaction.Transaction) (vmco
 	)
 
 	txType, dstShardTxType := txProc.txTypeHandler.ComputeTransactionType(tx)
+	if txType == process.RelayedTx && txProc.flagRelayerFundsCheck.IsSet() {
+		err = txProc.checkRelayerFunds(tx, acntSnd)
+		if err != nil {
+			return vmcommon.UserError, err
+		}
+	}
+
 	err = txProc.checkTxValues(tx, acntSnd, acntDst, false)
 	if err != nil {
 		if errors.Is(err, process.ErrInsufficientFunds) {
@@ -529,6 +573,9 @@ func (txProc *txProcessor) processRelayedTx(
 	if userTx.Value.Cmp(tx.Value) < 0 {
 		return vmcommon.UserError, txProc.executingFailedTransaction(tx, relayerAcnt, process.ErrRelayedTxValueHigherThenUserTxValue)
 	}
+	if txProc.flagRelayedGasPriceCheck.IsSet() && tx.GasPrice < userTx.GasPrice {
+		return vmcommon.UserError, txProc.executingFailedTransaction(tx, relayerAcnt, process.ErrRelayedGasPriceTooLow)
+	}
 	if userTx.GasPrice != tx.GasPrice {
 		return vmcommon.UserError, txProc.executingFailedTransaction(tx, relayerAcnt, process.ErrRelayedGasPriceMissmatch)
 	}
@@ -537,6 +584,13 @@ func (txProc *txProcessor) processRelayedTx(
 	if userTx.GasLimit != remainingGasLimit {
 		return vmcommon.UserError, txProc.executingFailedTransaction(tx, relayerAcnt, process.ErrRelayedTxGasLimitMissmatch)
 	}
+	if txProc.flagInnerTxSignatureCheck.IsSet() {
+		err = txProc.verifyUserTxSignature(userTx)
+		if err != nil {
+			log.Trace("processRelayedTx.verifyUserTxSignature", "error", err.Error())
+			return vmcommon.UserError, txProc.executingFailedTransaction(tx, relayerAcnt, process.ErrInvalidInnerTxSignature)
+		}
+	}
 
 	txHash, err := core.CalculateHash(txProc.marshalizer, txProc.hasher, tx)
 	if err != nil {
@@ -585,6 +639,47 @@ func (txProc *txProcessor) processRelayedTx(
 	return txProc.processUserTx(tx, userTx, tx.Value, tx.Nonce, txHash)
 }
 
+// verifyUserTxSignature checks that the inner transaction of a relayed transaction was signed by its sender
+func (txProc *txProcessor) verifyUserTxSignature(userTx *transaction.Transaction) error {
+	buffCopiedTx, err := userTx.GetDataForSigning(txProc.pubkeyConv, txProc.signMarshalizer)
+	if err != nil {
+		return err
+	}
+
+	senderPubKey, err := txProc.keyGen.PublicKeyFromByteArray(userTx.SndAddr)
+	if err != nil {
+		return err
+	}
+
+	if !txProc.txVersionChecker.IsSignedWithHash(userTx) {
+		return txProc.singleSigner.Verify(senderPubKey, buffCopiedTx, userTx.Signature)
+	}
+
+	txHash := txProc.txSignHasher.Compute(string(buffCopiedTx))
+
+	return txProc.singleSigner.Verify(senderPubKey, txHash, userTx.Signature)
+}
+
+// checkRelayerFunds verifies, before anything is processed, that the relayer can cover both the value forwarded to the
+// user tx and the whole relayed tx fee
+func (txProc *txProcessor) checkRelayerFunds(tx *transaction.Transaction, relayerAcnt state.UserAccountHandler) error {
+	if check.IfNil(relayerAcnt) {
+		return nil
+	}
+
+	totalFee, _, _, _ := txProc.computeRelayedTxFees(tx)
+	cost := big.NewInt(0).Add(tx.GetValue(), totalFee)
+	if relayerAcnt.GetBalance().Cmp(cost) < 0 {
+		return fmt.Errorf("%w, has: %s, wanted: %s",
+			process.ErrRelayerInsufficientFunds,
+			relayerAcnt.GetBalance().String(),
+			cost.String(),
+		)
+	}
+
+	return nil
+}
+
 func (txProc *txProcessor) computeRelayedTxFees(tx *transaction.Transaction) (*big.Int, *big.Int, *big.Int, uint64) {
 	relayerGasLimit := txProc.economicsFee.ComputeGasLimit(tx)
 	relayerFee := txProc.economicsFee.ComputeMoveBalanceFee(tx)
@@ -866,6 +961,15 @@ func (txProc *txProcessor) EpochConfirmed(epoch uint32) {
 
 	txProc.flagMetaProtection.Toggle(epoch >= txProc.metaProtectionEnableEpoch)
 	log.Debug("txProcessor: meta protection", "enabled", txProc.flagMetaProtection.IsSet())
+
+	txProc.flagInnerTxSignatureCheck.Toggle(epoch >= txProc.innerTxSignatureCheckEnableEpoch)
+	log.Debug("txProcessor: inner transaction signature check", "enabled", txProc.flagInnerTxSignatureCheck.IsSet())
+
+	txProc.flagRelayedGasPriceCheck.Toggle(epoch >= txProc.relayedGasPriceCheckEnableEpoch)
+	log.Debug("txProcessor: relayed gas price check", "enabled", txProc.flagRelayedGasPriceCheck.IsSet())
+
+	txProc.flagRelayerFundsCheck.Toggle(epoch >= txProc.relayerFundsCheckEnableEpoch)
+	log.Debug("txProcessor: relayer funds check", "enabled", txProc.flagRelayerFundsCheck.IsSet())
 }
 
 // IsInterfaceNil returns true if there is no value under the interface
@@ -11,7 +11,9 @@ import (
 
 	"github.com/ElrondNetwork/elrond-go/core"
 	"github.com/ElrondNetwork/elrond-go/core/parsers"
+	"github.com/ElrondNetwork/elrond-go/core/versioning"
 	"github.com/ElrondNetwork/elrond-go/core/vmcommon"
+	"github.com/ElrondNetwork/elrond-go/crypto"
 	"github.com/ElrondNetwork/elrond-go/data"
 	"github.com/ElrondNetwork/elrond-go/data/smartContractResult"
 	"github.com/ElrondNetwork/elrond-go/data/state"
@@ -83,6 +85,18 @@ func createArgsForTxProcessor() txproc.ArgsNewTxProcessor {
 		ArgsParser:       &mock.ArgumentParserMock{},
 		ScrForwarder:     &mock.IntermediateTransactionHandlerMock{},
 		EpochNotifier:    &mock.EpochNotifierStub{},
+		KeyGen: &mock.SingleSignKeyGenMock{
+			PublicKeyFromByteArrayCalled: func(b []byte) (crypto.PublicKey, error) {
+				return &mock.SingleSignPublicKey{}, nil
+			},
+		},
+		SingleSigner: &mock.SignerMock{
+			VerifyStub: func(public crypto.PublicKey, msg []byte, sig []byte) error {
+				return nil
+			},
+		},
+		TxSignHasher:     mock.HasherMock{},
+		TxVersionChecker: versioning.NewTxVersionChecker(0),
 	}
 	return args
 }
@@ -259,6 +273,50 @@ func TestNewTxProcessor_NilEpochNotifierShouldErr(t *testing.T) {
 	assert.Nil(t, txProc)
 }
 
+func TestNewTxProcessor_NilKeyGenShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForTxProcessor()
+	args.KeyGen = nil
+	txProc, err := txproc.NewTxProcessor(args)
+
+	assert.Equal(t, process.ErrNilKeyGen, err)
+	assert.Nil(t, txProc)
+}
+
+func TestNewTxProcessor_NilSingleSignerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForTxProcessor()
+	args.SingleSigner = nil
+	txProc, err := txproc.NewTxProcessor(args)
+
+	assert.Equal(t, process.ErrNilSingleSigner, err)
+	assert.Nil(t, txProc)
+}
+
+func TestNewTxProcessor_NilTxSignHasherShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForTxProcessor()
+	args.TxSignHasher = nil
+	txProc, err := txproc.NewTxProcessor(args)
+
+	assert.Equal(t, process.ErrNilHasher, err)
+	assert.Nil(t, txProc)
+}
+
+func TestNewTxProcessor_NilTxVersionCheckerShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := createArgsForTxProcessor()
+	args.TxVersionChecker = nil
+	txProc, err := txproc.NewTxProcessor(args)
+
+	assert.Equal(t, process.ErrNilTransactionVersionChecker, err)
+	assert.Nil(t, txProc)
+}
+
 func TestNewTxProcessor_OkValsShouldWork(t *testing.T) {
 	t.Parallel()
 
@@ -2068,6 +2126,317 @@ func TestTxProcessor_ProcessRelayedTransactionGasPriceMismatchShouldError(t *tes
 	assert.Equal(t, vmcommon.UserError, returnCode)
 }
 
+func TestTxProcessor_ProcessRelayedTransactionGasPriceTooLowShouldErrAndConsumeGas(t *testing.T) {
+	t.Parallel()
+
+	userAddr := []byte("user")
+	tx := transaction.Transaction{}
+	tx.Nonce = 0
+	tx.SndAddr = []byte("sSRC")
+	tx.RcvAddr = userAddr
+	tx.Value = big.NewInt(50)
+	tx.GasPrice = 1
+	tx.GasLimit = 1
+
+	userTx := transaction.Transaction{
+		Nonce:    0,
+		Value:    big.NewInt(50),
+		RcvAddr:  []byte("sDST"),
+		SndAddr:  userAddr,
+		GasPrice: 2,
+		GasLimit: 1,
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	userTxMarshalled, _ := marshalizer.Marshal(userTx)
+	tx.Data = []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxMarshalled))
+
+	args := createArgsForTxProcessor()
+	args.ArgsParser = &mock.ArgumentParserMock{
+		ParseCallDataCalled: func(data string) (string, [][]byte, error) {
+			return core.RelayedTransaction, [][]byte{userTxMarshalled}, nil
+		}}
+	txFee := big.NewInt(5)
+	economicsFee := feeHandlerMock()
+	economicsFee.ComputeTxFeeCalled = func(tx process.TransactionWithFeeHandler) *big.Int {
+		return txFee
+	}
+	args.EconomicsFee = economicsFee
+	var failReason []byte
+	args.ReceiptForwarder = &mock.IntermediateTransactionHandlerMock{
+		AddIntermediateTransactionsCalled: func(txs []data.TransactionHandler) error {
+			failReason = txs[0].GetData()
+			return nil
+		},
+	}
+
+	acntSrc, _ := state.NewUserAccount(tx.SndAddr)
+	acntSrc.Balance = big.NewInt(100)
+	acntDst, _ := state.NewUserAccount(tx.RcvAddr)
+	acntDst.Balance = big.NewInt(10)
+
+	adb := &mock.AccountsStub{}
+	adb.LoadAccountCalled = func(address []byte) (state.AccountHandler, error) {
+		if bytes.Equal(address, tx.SndAddr) {
+			return acntSrc, nil
+		}
+		if bytes.Equal(address, tx.RcvAddr) {
+			return acntDst, nil
+		}
+
+		return nil, errors.New("failure")
+	}
+	args.Accounts = adb
+	args.TxTypeHandler = &mock.TxTypeHandlerMock{ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (transactionType, destinationTransactionType process.TransactionType) {
+		return process.RelayedTx, process.RelayedTx
+	}}
+
+	execTx, _ := txproc.NewTxProcessor(args)
+
+	returnCode, err := execTx.ProcessTransaction(&tx)
+	assert.Equal(t, process.ErrFailedTransaction, err)
+	assert.Equal(t, vmcommon.UserError, returnCode)
+	assert.Equal(t, process.ErrRelayedGasPriceTooLow.Error(), string(failReason))
+	assert.Equal(t, uint64(1), acntSrc.GetNonce())
+	assert.Equal(t, big.NewInt(0).Sub(big.NewInt(100), txFee), acntSrc.GetBalance())
+	assert.Equal(t, big.NewInt(10), acntDst.GetBalance())
+}
+
+func TestTxProcessor_ProcessRelayedTransactionGasPriceTooLowWithCheckDisabledShouldErrMismatch(t *testing.T) {
+	t.Parallel()
+
+	userAddr := []byte("user")
+	tx := transaction.Transaction{}
+	tx.Nonce = 0
+	tx.SndAddr = []byte("sSRC")
+	tx.RcvAddr = userAddr
+	tx.Value = big.NewInt(50)
+	tx.GasPrice = 1
+	tx.GasLimit = 1
+
+	userTx := transaction.Transaction{
+		Nonce:    0,
+		Value:    big.NewInt(50),
+		RcvAddr:  []byte("sDST"),
+		SndAddr:  userAddr,
+		GasPrice: 2,
+		GasLimit: 1,
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	userTxMarshalled, _ := marshalizer.Marshal(userTx)
+	tx.Data = []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxMarshalled))
+
+	args := createArgsForTxProcessor()
+	args.RelayedGasPriceCheckEnableEpoch = 1
+	args.ArgsParser = &mock.ArgumentParserMock{
+		ParseCallDataCalled: func(data string) (string, [][]byte, error) {
+			return core.RelayedTransaction, [][]byte{userTxMarshalled}, nil
+		}}
+	txFee := big.NewInt(5)
+	economicsFee := feeHandlerMock()
+	economicsFee.ComputeTxFeeCalled = func(tx process.TransactionWithFeeHandler) *big.Int {
+		return txFee
+	}
+	args.EconomicsFee = economicsFee
+	var failReason []byte
+	args.ReceiptForwarder = &mock.IntermediateTransactionHandlerMock{
+		AddIntermediateTransactionsCalled: func(txs []data.TransactionHandler) error {
+			failReason = txs[0].GetData()
+			return nil
+		},
+	}
+
+	acntSrc, _ := state.NewUserAccount(tx.SndAddr)
+	acntSrc.Balance = big.NewInt(100)
+	acntDst, _ := state.NewUserAccount(tx.RcvAddr)
+	acntDst.Balance = big.NewInt(10)
+
+	adb := &mock.AccountsStub{}
+	adb.LoadAccountCalled = func(address []byte) (state.AccountHandler, error) {
+		if bytes.Equal(address, tx.SndAddr) {
+			return acntSrc, nil
+		}
+		if bytes.Equal(address, tx.RcvAddr) {
+			return acntDst, nil
+		}
+
+		return nil, errors.New("failure")
+	}
+	args.Accounts = adb
+	args.TxTypeHandler = &mock.TxTypeHandlerMock{ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (transactionType, destinationTransactionType process.TransactionType) {
+		return process.RelayedTx, process.RelayedTx
+	}}
+
+	execTx, _ := txproc.NewTxProcessor(args)
+
+	returnCode, err := execTx.ProcessTransaction(&tx)
+	assert.Equal(t, process.ErrFailedTransaction, err)
+	assert.Equal(t, vmcommon.UserError, returnCode)
+	assert.Equal(t, process.ErrRelayedGasPriceMissmatch.Error(), string(failReason))
+	assert.Equal(t, uint64(1), acntSrc.GetNonce())
+	assert.Equal(t, big.NewInt(0).Sub(big.NewInt(100), txFee), acntSrc.GetBalance())
+	assert.Equal(t, big.NewInt(10), acntDst.GetBalance())
+}
+
+func TestTxProcessor_ProcessRelayedTransactionRelayerInsufficientFundsShouldErrWithoutChangingTheState(t *testing.T) {
+	t.Parallel()
+
+	userAddr := []byte("user")
+	tx := transaction.Transaction{}
+	tx.Nonce = 0
+	tx.SndAddr = []byte("sSRC")
+	tx.RcvAddr = userAddr
+	tx.Value = big.NewInt(50)
+	tx.GasPrice = 1
+	tx.GasLimit = 1
+
+	userTx := transaction.Transaction{
+		Nonce:    0,
+		Value:    big.NewInt(50),
+		RcvAddr:  []byte("sDST"),
+		SndAddr:  userAddr,
+		GasPrice: 1,
+		GasLimit: 1,
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	userTxMarshalled, _ := marshalizer.Marshal(userTx)
+	tx.Data = []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxMarshalled))
+
+	args := createArgsForTxProcessor()
+	args.ArgsParser = &mock.ArgumentParserMock{
+		ParseCallDataCalled: func(data string) (string, [][]byte, error) {
+			return core.RelayedTransaction, [][]byte{userTxMarshalled}, nil
+		}}
+	economicsFee := feeHandlerMock()
+	economicsFee.ComputeTxFeeCalled = func(tx process.TransactionWithFeeHandler) *big.Int {
+		return big.NewInt(5)
+	}
+	args.EconomicsFee = economicsFee
+	receiptCreated := false
+	args.ReceiptForwarder = &mock.IntermediateTransactionHandlerMock{
+		AddIntermediateTransactionsCalled: func(txs []data.TransactionHandler) error {
+			receiptCreated = true
+			return nil
+		},
+	}
+
+	acntSrc, _ := state.NewUserAccount(tx.SndAddr)
+	acntSrc.Balance = big.NewInt(54)
+	acntDst, _ := state.NewUserAccount(tx.RcvAddr)
+	acntDst.Balance = big.NewInt(10)
+
+	saveAccountCalled := false
+	adb := &mock.AccountsStub{}
+	adb.LoadAccountCalled = func(address []byte) (state.AccountHandler, error) {
+		if bytes.Equal(address, tx.SndAddr) {
+			return acntSrc, nil
+		}
+		if bytes.Equal(address, tx.RcvAddr) {
+			return acntDst, nil
+		}
+
+		return nil, errors.New("failure")
+	}
+	adb.SaveAccountCalled = func(account state.AccountHandler) error {
+		saveAccountCalled = true
+		return nil
+	}
+	args.Accounts = adb
+	args.TxTypeHandler = &mock.TxTypeHandlerMock{ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (transactionType, destinationTransactionType process.TransactionType) {
+		return process.RelayedTx, process.RelayedTx
+	}}
+
+	execTx, _ := txproc.NewTxProcessor(args)
+
+	returnCode, err := execTx.ProcessTransaction(&tx)
+	assert.True(t, errors.Is(err, process.ErrRelayerInsufficientFunds))
+	assert.Equal(t, vmcommon.UserError, returnCode)
+	assert.False(t, receiptCreated)
+	assert.False(t, saveAccountCalled)
+	assert.Equal(t, uint64(0), acntSrc.GetNonce())
+	assert.Equal(t, big.NewInt(54), acntSrc.GetBalance())
+	assert.Equal(t, big.NewInt(10), acntDst.GetBalance())
+}
+
+func TestTxProcessor_ProcessRelayedTransactionRelayerInsufficientFundsWithCheckDisabledShouldConsumeTheFee(t *testing.T) {
+	t.Parallel()
+
+	userAddr := []byte("user")
+	tx := transaction.Transaction{}
+	tx.Nonce = 0
+	tx.SndAddr = []byte("sSRC")
+	tx.RcvAddr = userAddr
+	tx.Value = big.NewInt(50)
+	tx.GasPrice = 1
+	tx.GasLimit = 1
+
+	userTx := transaction.Transaction{
+		Nonce:    0,
+		Value:    big.NewInt(50),
+		RcvAddr:  []byte("sDST"),
+		SndAddr:  userAddr,
+		GasPrice: 1,
+		GasLimit: 1,
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	userTxMarshalled, _ := marshalizer.Marshal(userTx)
+	tx.Data = []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxMarshalled))
+
+	args := createArgsForTxProcessor()
+	args.RelayerFundsCheckEnableEpoch = 1
+	args.ArgsParser = &mock.ArgumentParserMock{
+		ParseCallDataCalled: func(data string) (string, [][]byte, error) {
+			return core.RelayedTransaction, [][]byte{userTxMarshalled}, nil
+		}}
+	economicsFee := feeHandlerMock()
+	economicsFee.ComputeTxFeeCalled = func(tx process.TransactionWithFeeHandler) *big.Int {
+		return big.NewInt(5)
+	}
+	args.EconomicsFee = economicsFee
+	receiptCreated := false
+	args.ReceiptForwarder = &mock.IntermediateTransactionHandlerMock{
+		AddIntermediateTransactionsCalled: func(txs []data.TransactionHandler) error {
+			receiptCreated = true
+			return nil
+		},
+	}
+
+	acntSrc, _ := state.NewUserAccount(tx.SndAddr)
+	acntSrc.Balance = big.NewInt(54)
+	acntDst, _ := state.NewUserAccount(tx.RcvAddr)
+	acntDst.Balance = big.NewInt(10)
+
+	saveAccountCalled := false
+	adb := &mock.AccountsStub{}
+	adb.LoadAccountCalled = func(address []byte) (state.AccountHandler, error) {
+		if bytes.Equal(address, tx.SndAddr) {
+			return acntSrc, nil
+		}
+		if bytes.Equal(address, tx.RcvAddr) {
+			return acntDst, nil
+		}
+
+		return nil, errors.New("failure")
+	}
+	adb.SaveAccountCalled = func(account state.AccountHandler) error {
+		saveAccountCalled = true
+		return nil
+	}
+	args.Accounts = adb
+	args.TxTypeHandler = &mock.TxTypeHandlerMock{ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (transactionType, destinationTransactionType process.TransactionType) {
+		return process.RelayedTx, process.RelayedTx
+	}}
+
+	execTx, _ := txproc.NewTxProcessor(args)
+
+	_, err := execTx.ProcessTransaction(&tx)
+	assert.True(t, errors.Is(err, process.ErrFailedTransaction))
+	assert.True(t, receiptCreated)
+	assert.True(t, saveAccountCalled)
+	assert.Equal(t, uint64(1), acntSrc.GetNonce())
+	assert.Equal(t, big.NewInt(49), acntSrc.GetBalance())
+	assert.Equal(t, big.NewInt(10), acntDst.GetBalance())
+}
+
 func TestTxProcessor_ProcessRelayedTransactionGasLimitMismatchShouldError(t *testing.T) {
 	t.Parallel()
 
@@ -2131,6 +2500,81 @@ func TestTxProcessor_ProcessRelayedTransactionGasLimitMismatchShouldError(t *tes
 	assert.Equal(t, vmcommon.UserError, returnCode)
 }
 
+func TestTxProcessor_ProcessRelayedTransactionInvalidInnerTxSignatureShouldError(t *testing.T) {
+	t.Parallel()
+
+	userAddr := []byte("user")
+	tx := transaction.Transaction{}
+	tx.Nonce = 0
+	tx.SndAddr = []byte("sSRC")
+	tx.RcvAddr = userAddr
+	tx.Value = big.NewInt(50)
+	tx.GasPrice = 1
+	tx.GasLimit = 6
+
+	userTx := transaction.Transaction{
+		Nonce:     0,
+		Value:     big.NewInt(50),
+		RcvAddr:   []byte("sDST"),
+		SndAddr:   userAddr,
+		GasPrice:  1,
+		GasLimit:  6,
+		Signature: []byte("tampered signature"),
+	}
+	marshalizer := &mock.MarshalizerMock{}
+	userTxMarshalled, _ := marshalizer.Marshal(userTx)
+	tx.Data = []byte(core.RelayedTransaction + "@" + hex.EncodeToString(userTxMarshalled))
+
+	args := createArgsForTxProcessor()
+	args.ArgsParser = &mock.ArgumentParserMock{
+		ParseCallDataCalled: func(data string) (string, [][]byte, error) {
+			return core.RelayedTransaction, [][]byte{userTxMarshalled}, nil
+		}}
+	args.SingleSigner = &mock.SignerMock{
+		VerifyStub: func(public crypto.PublicKey, msg []byte, sig []byte) error {
+			return errors.New("invalid signature")
+		},
+	}
+
+	acntSrc, _ := state.NewUserAccount(tx.SndAddr)
+	acntSrc.Balance = big.NewInt(100)
+	acntDst, _ := state.NewUserAccount(tx.RcvAddr)
+	acntDst.Balance = big.NewInt(10)
+
+	adb := &mock.AccountsStub{}
+	adb.LoadAccountCalled = func(address []byte) (state.AccountHandler, error) {
+		if bytes.Equal(address, tx.SndAddr) {
+			return acntSrc, nil
+		}
+		if bytes.Equal(address, tx.RcvAddr) {
+			return acntDst, nil
+		}
+
+		return nil, errors.New("failure")
+	}
+	args.Accounts = adb
+	args.TxTypeHandler = &mock.TxTypeHandlerMock{ComputeTransactionTypeCalled: func(tx data.TransactionHandler) (transactionType, destinationTransactionType process.TransactionType) {
+		return process.RelayedTx, process.RelayedTx
+	}}
+
+	var receiptData []byte
+	args.ReceiptForwarder = &mock.IntermediateTransactionHandlerMock{
+		AddIntermediateTransactionsCalled: func(txs []data.TransactionHandler) error {
+			receiptData = txs[0].GetData()
+			return nil
+		},
+	}
+
+	execTx, _ := txproc.NewTxProcessor(args)
+
+	returnCode, err := execTx.ProcessTransaction(&tx)
+	assert.Equal(t, process.ErrFailedTransaction, err)
+	assert.Equal(t, vmcommon.UserError, returnCode)
+	assert.Equal(t, []byte(process.ErrInvalidInnerTxSignature.Error()), receiptData)
+	assert.Equal(t, uint64(1), acntSrc.GetNonce())
+	assert.Equal(t, big.NewInt(10), acntDst.GetBalance())
+}
+
 func TestTxProcessor_ProcessRelayedTransactionDisabled(t *testing.T) {
 	t.Parallel()
 
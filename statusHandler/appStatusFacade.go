@@ -83,6 +83,15 @@ func (asf *AppStatusFacade) SetStringValue(key string, value string) {
 	}()
 }
 
+// SetMultiple method - will update the values for the given keys for every handler
+func (asf *AppStatusFacade) SetMultiple(values map[string]interface{}) {
+	go func() {
+		for _, ash := range asf.handlers {
+			core.SetMultipleStatusValues(ash, values)
+		}
+	}()
+}
+
 // Close method will close all the handlers
 func (asf *AppStatusFacade) Close() {
 	go func() {
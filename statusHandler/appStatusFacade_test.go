@@ -1,6 +1,7 @@
 package statusHandler_test
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -186,3 +187,78 @@ func TestAppStatusFacade_SetStringValueShouldPass(t *testing.T) {
 		assert.Fail(t, "Timeout - function not called")
 	}
 }
+
+func TestAppStatusFacade_SetMultipleShouldSetEachValueOnHandlersWithoutBatching(t *testing.T) {
+	t.Parallel()
+
+	chanDone := make(chan bool, 2)
+	uint64Key := core.MetricNonce
+	stringKey := core.MetricNodeDisplayName
+
+	// we create a new facade which contains a stub handler in order to test
+	appStatusHandlerStub := mock.AppStatusHandlerStub{
+		SetUInt64ValueHandler: func(key string, value uint64) {
+			if key == uint64Key && value == 7 {
+				chanDone <- true
+			}
+		},
+		SetStringValueHandler: func(key string, value string) {
+			if key == stringKey && value == "value" {
+				chanDone <- true
+			}
+		},
+	}
+
+	asf, err := statusHandler.NewAppStatusFacadeWithHandlers(&appStatusHandlerStub)
+	assert.Nil(t, err)
+
+	asf.SetMultiple(map[string]interface{}{
+		uint64Key: uint64(7),
+		stringKey: "value",
+	})
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-chanDone:
+		case <-time.After(1 * time.Second):
+			assert.Fail(t, "Timeout - function not called")
+		}
+	}
+}
+
+func createBenchmarkMetricsValues() map[string]interface{} {
+	values := make(map[string]interface{})
+	for i := 0; i < 10; i++ {
+		values[fmt.Sprintf("uint64 metric %d", i)] = uint64(i)
+		values[fmt.Sprintf("string metric %d", i)] = fmt.Sprintf("value %d", i)
+	}
+
+	return values
+}
+
+func BenchmarkAppStatusFacade_SetValuesOneByOne(b *testing.B) {
+	asf, _ := statusHandler.NewAppStatusFacadeWithHandlers(statusHandler.NewStatusMetrics())
+	values := createBenchmarkMetricsValues()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for key, value := range values {
+			switch v := value.(type) {
+			case uint64:
+				asf.SetUInt64Value(key, v)
+			case string:
+				asf.SetStringValue(key, v)
+			}
+		}
+	}
+}
+
+func BenchmarkAppStatusFacade_SetMultiple(b *testing.B) {
+	asf, _ := statusHandler.NewAppStatusFacadeWithHandlers(statusHandler.NewStatusMetrics())
+	values := createBenchmarkMetricsValues()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		asf.SetMultiple(values)
+	}
+}
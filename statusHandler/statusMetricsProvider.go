@@ -91,6 +91,13 @@ func (sm *statusMetrics) SetStringValue(key string, value string) {
 	sm.nodeMetrics.Store(key, value)
 }
 
+// SetMultiple method - sets the given values, each of them being an uint64, an int64 or a string
+func (sm *statusMetrics) SetMultiple(values map[string]interface{}) {
+	for key, value := range values {
+		sm.nodeMetrics.Store(key, value)
+	}
+}
+
 // Close method - won't do anything
 func (sm *statusMetrics) Close() {
 }
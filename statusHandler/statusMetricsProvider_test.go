@@ -111,6 +111,23 @@ func TestStatusMetricsProvider_AddUint64Value(t *testing.T) {
 	assert.Equal(t, value+value, retMap[key])
 }
 
+func TestStatusMetricsProvider_SetMultiple(t *testing.T) {
+	t.Parallel()
+
+	sm := statusHandler.NewStatusMetrics()
+	values := map[string]interface{}{
+		"test-key7": uint64(7),
+		"test-key8": int64(-8),
+		"test-key9": "value",
+	}
+	sm.SetMultiple(values)
+
+	retMap := sm.StatusMetricsMap()
+	for key, value := range values {
+		assert.Equal(t, value, retMap[key])
+	}
+}
+
 func TestStatusMetrics_StatusMetricsWithoutP2PPrometheusStringShouldPutDefaultShardIDLabel(t *testing.T) {
 	t.Parallel()
 
@@ -114,6 +114,12 @@ type StorerWithPutInEpoch interface {
 	SetEpochForPutOperation(epoch uint32)
 }
 
+// StorerWithPutBatch is an extended storer with the ability to put multiple (key, value) pairs in one go
+type StorerWithPutBatch interface {
+	Storer
+	PutBatch(keys [][]byte, values [][]byte) error
+}
+
 // EpochStartNotifier defines which actions should be done for handling new epoch's events
 type EpochStartNotifier interface {
 	RegisterHandler(handler epochStart.ActionHandler)
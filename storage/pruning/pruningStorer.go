@@ -243,7 +243,34 @@ func initPersistersInEpoch(
 func (ps *PruningStorer) Put(key, data []byte) error {
 	ps.cacher.Put(key, data, len(data))
 
+	persisterToUse := ps.getPersisterForPut()
+	return ps.doPutInPersister(key, data, persisterToUse.persister)
+}
+
+// PutBatch adds the provided (key, value) pairs to the persister of the epoch set for put operations, choosing the
+// persister only once for all of them. It stops at the first pair which could not be persisted
+func (ps *PruningStorer) PutBatch(keys [][]byte, values [][]byte) error {
+	if len(keys) != len(values) {
+		return storage.ErrInvalidBatch
+	}
+
+	persisterToUse := ps.getPersisterForPut()
+	for i := range keys {
+		ps.cacher.Put(keys[i], values[i], len(values[i]))
+
+		err := ps.doPutInPersister(keys[i], values[i], persisterToUse.persister)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ps *PruningStorer) getPersisterForPut() *persisterData {
 	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
 	persisterToUse := ps.activePersisters[0]
 	if ps.pruningEnabled {
 		persisterInSetEpoch, ok := ps.persistersMapByEpoch[ps.epochForPutOperation]
@@ -255,8 +282,8 @@ func (ps *PruningStorer) Put(key, data []byte) error {
 				"used", persisterToUse.epoch)
 		}
 	}
-	ps.lock.RUnlock()
-	return ps.doPutInPersister(key, data, persisterToUse.persister)
+
+	return persisterToUse
 }
 
 func (ps *PruningStorer) doPutInPersister(key, data []byte, persister storage.Persister) error {
@@ -209,6 +209,35 @@ func TestPruningStorer_PutAndGetShouldWork(t *testing.T) {
 	assert.Equal(t, testVal, res)
 }
 
+func TestPruningStorer_PutBatchAndGetShouldWork(t *testing.T) {
+	t.Parallel()
+
+	args := getDefaultArgs()
+	ps, _ := pruning.NewPruningStorer(args)
+
+	keys := [][]byte{[]byte("key0"), []byte("key1")}
+	values := [][]byte{[]byte("value0"), []byte("value1")}
+	err := ps.PutBatch(keys, values)
+	assert.Nil(t, err)
+
+	ps.ClearCache()
+	for i := range keys {
+		res, errGet := ps.Get(keys[i])
+		assert.Nil(t, errGet)
+		assert.Equal(t, values[i], res)
+	}
+}
+
+func TestPruningStorer_PutBatchWithDifferentNumberOfKeysAndValuesShouldErr(t *testing.T) {
+	t.Parallel()
+
+	args := getDefaultArgs()
+	ps, _ := pruning.NewPruningStorer(args)
+
+	err := ps.PutBatch([][]byte{[]byte("key0"), []byte("key1")}, [][]byte{[]byte("value0")})
+	assert.Equal(t, storage.ErrInvalidBatch, err)
+}
+
 func TestPruningStorer_Put_EpochWhichWasSetDoesNotExistShouldNotFind(t *testing.T) {
 	t.Parallel()
 
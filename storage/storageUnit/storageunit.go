@@ -134,6 +134,33 @@ func (u *Unit) Put(key, data []byte) error {
 	return err
 }
 
+// PutBatch adds the provided (key, value) pairs to the cache and then to the persistence medium, holding the unit
+// lock only once for all of them. It stops at the first pair which could not be persisted
+func (u *Unit) PutBatch(keys [][]byte, values [][]byte) error {
+	if len(keys) != len(values) {
+		return storage.ErrInvalidBatch
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	for i := range keys {
+		u.cacher.Put(keys[i], values[i], len(values[i]))
+
+		err := u.persister.Put(keys[i], values[i])
+		if err != nil {
+			u.cacher.Remove(keys[i])
+			return err
+		}
+
+		if u.bloomFilter != nil {
+			u.bloomFilter.Add(keys[i])
+		}
+	}
+
+	return nil
+}
+
 // PutInEpoch will call the Put method as this storer doesn't handle epochs
 func (u *Unit) PutInEpoch(key, data []byte, _ uint32) error {
 	return u.Put(key, data)
@@ -149,6 +149,30 @@ func TestPutNotPresentCacheWithNilBloomFilter(t *testing.T) {
 	assert.Nil(t, err, "expected to find key %s, but not found", key)
 }
 
+func TestPutBatchNotPresentCache(t *testing.T) {
+	keys := [][]byte{[]byte("key0"), []byte("key1")}
+	values := [][]byte{[]byte("value0"), []byte("value1")}
+	s := initStorageUnitWithBloomFilter(t, 10)
+	err := s.PutBatch(keys, values)
+
+	assert.Nil(t, err, "no error expected but got %s", err)
+
+	s.ClearCache()
+
+	for i := range keys {
+		v, errGet := s.Get(keys[i])
+		assert.Nil(t, errGet, "expected to find key %s, but not found", keys[i])
+		assert.Equal(t, values[i], v)
+	}
+}
+
+func TestPutBatchWithDifferentNumberOfKeysAndValuesShouldErr(t *testing.T) {
+	s := initStorageUnitWithBloomFilter(t, 10)
+	err := s.PutBatch([][]byte{[]byte("key0")}, [][]byte{})
+
+	assert.Equal(t, storage.ErrInvalidBatch, err)
+}
+
 func TestPutPresentShouldOverwriteValue(t *testing.T) {
 	key, val := []byte("key2"), []byte("value2")
 	s := initStorageUnitWithBloomFilter(t, 10)
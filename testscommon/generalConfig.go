@@ -11,6 +11,7 @@ func GetGeneralConfig() config.Config {
 		GeneralSettings: config.GeneralSettingsConfig{
 			StartInEpochEnabled:      true,
 			GenesisMaxNumberOfShards: 100,
+			ProduceEmptyBlocks:       true,
 		},
 		EpochStartConfig: config.EpochStartConfig{
 			MinRoundsBetweenEpochs:            5,